@@ -19,10 +19,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrderService_CreateOrder_FullMethodName       = "/api.OrderService/CreateOrder"
-	OrderService_UpdateOrder_FullMethodName       = "/api.OrderService/UpdateOrder"
-	OrderService_GetOrder_FullMethodName          = "/api.OrderService/GetOrder"
-	OrderService_GetOrdersByStatus_FullMethodName = "/api.OrderService/GetOrdersByStatus"
+	OrderService_CreateOrder_FullMethodName                = "/api.OrderService/CreateOrder"
+	OrderService_UpdateOrder_FullMethodName                = "/api.OrderService/UpdateOrder"
+	OrderService_GetOrder_FullMethodName                   = "/api.OrderService/GetOrder"
+	OrderService_GetOrdersByStatus_FullMethodName          = "/api.OrderService/GetOrdersByStatus"
+	OrderService_AdjustOrderItems_FullMethodName           = "/api.OrderService/AdjustOrderItems"
+	OrderService_GetOrdersBySession_FullMethodName         = "/api.OrderService/GetOrdersBySession"
+	OrderService_GetOrdersByCustomer_FullMethodName        = "/api.OrderService/GetOrdersByCustomer"
+	OrderService_GetStuckOrders_FullMethodName             = "/api.OrderService/GetStuckOrders"
+	OrderService_CancelOrder_FullMethodName                = "/api.OrderService/CancelOrder"
+	OrderService_CancelCustomerUnpaidOrders_FullMethodName = "/api.OrderService/CancelCustomerUnpaidOrders"
+	OrderService_DeleteOrder_FullMethodName                = "/api.OrderService/DeleteOrder"
+	OrderService_RestoreOrder_FullMethodName               = "/api.OrderService/RestoreOrder"
 )
 
 // OrderServiceClient is the client API for OrderService service.
@@ -43,6 +51,22 @@ type OrderServiceClient interface {
 	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
 	// Gateway → Orders: Alle Orders mit bestimmtem Status (Kitchen Display)
 	GetOrdersByStatus(ctx context.Context, in *GetOrdersByStatusRequest, opts ...grpc.CallOption) (*GetOrdersByStatusResponse, error)
+	// Admin → Orders: Mengen einer bezahlten Order reduzieren (Restock + Teil-Rückerstattung)
+	AdjustOrderItems(ctx context.Context, in *AdjustOrderItemsRequest, opts ...grpc.CallOption) (*Order, error)
+	// Gateway → Orders: Alle Orders eines Tisches/einer Session (gemeinsame Bezahlung)
+	GetOrdersBySession(ctx context.Context, in *GetOrdersBySessionRequest, opts ...grpc.CallOption) (*GetOrdersBySessionResponse, error)
+	// Gateway → Orders: Bestellhistorie eines Kunden ("Meine Bestellungen", neueste zuerst)
+	GetOrdersByCustomer(ctx context.Context, in *GetOrdersByCustomerRequest, opts ...grpc.CallOption) (*GetOrdersByCustomerResponse, error)
+	// Admin → Orders: Orders die zu lange unbezahlt hängen (Support / Diagnose)
+	GetStuckOrders(ctx context.Context, in *GetStuckOrdersRequest, opts ...grpc.CallOption) (*GetStuckOrdersResponse, error)
+	// Gateway → Orders: Order stornieren (idempotent, bezahlt → Payments erstattet)
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// Admin → Orders: Alle unbezahlten Orders eines Kunden stornieren (Support)
+	CancelCustomerUnpaidOrders(ctx context.Context, in *CancelCustomerUnpaidOrdersRequest, opts ...grpc.CallOption) (*CancelCustomerUnpaidOrdersResponse, error)
+	// Admin → Orders: Order soft-deleten (nur Endzustände, idempotent)
+	DeleteOrder(ctx context.Context, in *DeleteOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// Admin → Orders: Soft-Delete rückgängig machen (idempotent)
+	RestoreOrder(ctx context.Context, in *RestoreOrderRequest, opts ...grpc.CallOption) (*Order, error)
 }
 
 type orderServiceClient struct {
@@ -93,6 +117,86 @@ func (c *orderServiceClient) GetOrdersByStatus(ctx context.Context, in *GetOrder
 	return out, nil
 }
 
+func (c *orderServiceClient) AdjustOrderItems(ctx context.Context, in *AdjustOrderItemsRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, OrderService_AdjustOrderItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetOrdersBySession(ctx context.Context, in *GetOrdersBySessionRequest, opts ...grpc.CallOption) (*GetOrdersBySessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrdersBySessionResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetOrdersBySession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetOrdersByCustomer(ctx context.Context, in *GetOrdersByCustomerRequest, opts ...grpc.CallOption) (*GetOrdersByCustomerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrdersByCustomerResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetOrdersByCustomer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetStuckOrders(ctx context.Context, in *GetStuckOrdersRequest, opts ...grpc.CallOption) (*GetStuckOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStuckOrdersResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetStuckOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, OrderService_CancelOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CancelCustomerUnpaidOrders(ctx context.Context, in *CancelCustomerUnpaidOrdersRequest, opts ...grpc.CallOption) (*CancelCustomerUnpaidOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelCustomerUnpaidOrdersResponse)
+	err := c.cc.Invoke(ctx, OrderService_CancelCustomerUnpaidOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) DeleteOrder(ctx context.Context, in *DeleteOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, OrderService_DeleteOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) RestoreOrder(ctx context.Context, in *RestoreOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, OrderService_RestoreOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility.
@@ -111,6 +215,22 @@ type OrderServiceServer interface {
 	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
 	// Gateway → Orders: Alle Orders mit bestimmtem Status (Kitchen Display)
 	GetOrdersByStatus(context.Context, *GetOrdersByStatusRequest) (*GetOrdersByStatusResponse, error)
+	// Admin → Orders: Mengen einer bezahlten Order reduzieren (Restock + Teil-Rückerstattung)
+	AdjustOrderItems(context.Context, *AdjustOrderItemsRequest) (*Order, error)
+	// Gateway → Orders: Alle Orders eines Tisches/einer Session (gemeinsame Bezahlung)
+	GetOrdersBySession(context.Context, *GetOrdersBySessionRequest) (*GetOrdersBySessionResponse, error)
+	// Gateway → Orders: Bestellhistorie eines Kunden ("Meine Bestellungen", neueste zuerst)
+	GetOrdersByCustomer(context.Context, *GetOrdersByCustomerRequest) (*GetOrdersByCustomerResponse, error)
+	// Admin → Orders: Orders die zu lange unbezahlt hängen (Support / Diagnose)
+	GetStuckOrders(context.Context, *GetStuckOrdersRequest) (*GetStuckOrdersResponse, error)
+	// Gateway → Orders: Order stornieren (idempotent, bezahlt → Payments erstattet)
+	CancelOrder(context.Context, *CancelOrderRequest) (*Order, error)
+	// Admin → Orders: Alle unbezahlten Orders eines Kunden stornieren (Support)
+	CancelCustomerUnpaidOrders(context.Context, *CancelCustomerUnpaidOrdersRequest) (*CancelCustomerUnpaidOrdersResponse, error)
+	// Admin → Orders: Order soft-deleten (nur Endzustände, idempotent)
+	DeleteOrder(context.Context, *DeleteOrderRequest) (*Order, error)
+	// Admin → Orders: Soft-Delete rückgängig machen (idempotent)
+	RestoreOrder(context.Context, *RestoreOrderRequest) (*Order, error)
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -133,6 +253,30 @@ func (UnimplementedOrderServiceServer) GetOrder(context.Context, *GetOrderReques
 func (UnimplementedOrderServiceServer) GetOrdersByStatus(context.Context, *GetOrdersByStatusRequest) (*GetOrdersByStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOrdersByStatus not implemented")
 }
+func (UnimplementedOrderServiceServer) AdjustOrderItems(context.Context, *AdjustOrderItemsRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustOrderItems not implemented")
+}
+func (UnimplementedOrderServiceServer) GetOrdersBySession(context.Context, *GetOrdersBySessionRequest) (*GetOrdersBySessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrdersBySession not implemented")
+}
+func (UnimplementedOrderServiceServer) GetOrdersByCustomer(context.Context, *GetOrdersByCustomerRequest) (*GetOrdersByCustomerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrdersByCustomer not implemented")
+}
+func (UnimplementedOrderServiceServer) GetStuckOrders(context.Context, *GetStuckOrdersRequest) (*GetStuckOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStuckOrders not implemented")
+}
+func (UnimplementedOrderServiceServer) CancelOrder(context.Context, *CancelOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) CancelCustomerUnpaidOrders(context.Context, *CancelCustomerUnpaidOrdersRequest) (*CancelCustomerUnpaidOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelCustomerUnpaidOrders not implemented")
+}
+func (UnimplementedOrderServiceServer) DeleteOrder(context.Context, *DeleteOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) RestoreOrder(context.Context, *RestoreOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreOrder not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 func (UnimplementedOrderServiceServer) testEmbeddedByValue()                      {}
 
@@ -226,6 +370,150 @@ func _OrderService_GetOrdersByStatus_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_AdjustOrderItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustOrderItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AdjustOrderItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_AdjustOrderItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AdjustOrderItems(ctx, req.(*AdjustOrderItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrdersBySession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrdersBySessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrdersBySession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetOrdersBySession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrdersBySession(ctx, req.(*GetOrdersBySessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrdersByCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrdersByCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrdersByCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetOrdersByCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrdersByCustomer(ctx, req.(*GetOrdersByCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetStuckOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStuckOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetStuckOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetStuckOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetStuckOrders(ctx, req.(*GetStuckOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CancelCustomerUnpaidOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelCustomerUnpaidOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CancelCustomerUnpaidOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_CancelCustomerUnpaidOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CancelCustomerUnpaidOrders(ctx, req.(*CancelCustomerUnpaidOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_DeleteOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).DeleteOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_DeleteOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).DeleteOrder(ctx, req.(*DeleteOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RestoreOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RestoreOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_RestoreOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RestoreOrder(ctx, req.(*RestoreOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -249,15 +537,56 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOrdersByStatus",
 			Handler:    _OrderService_GetOrdersByStatus_Handler,
 		},
+		{
+			MethodName: "AdjustOrderItems",
+			Handler:    _OrderService_AdjustOrderItems_Handler,
+		},
+		{
+			MethodName: "GetOrdersBySession",
+			Handler:    _OrderService_GetOrdersBySession_Handler,
+		},
+		{
+			MethodName: "GetOrdersByCustomer",
+			Handler:    _OrderService_GetOrdersByCustomer_Handler,
+		},
+		{
+			MethodName: "GetStuckOrders",
+			Handler:    _OrderService_GetStuckOrders_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _OrderService_CancelOrder_Handler,
+		},
+		{
+			MethodName: "CancelCustomerUnpaidOrders",
+			Handler:    _OrderService_CancelCustomerUnpaidOrders_Handler,
+		},
+		{
+			MethodName: "DeleteOrder",
+			Handler:    _OrderService_DeleteOrder_Handler,
+		},
+		{
+			MethodName: "RestoreOrder",
+			Handler:    _OrderService_RestoreOrder_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "oms.proto",
 }
 
 const (
-	StockService_CheckIfItemIsInStock_FullMethodName = "/api.StockService/CheckIfItemIsInStock"
-	StockService_GetItems_FullMethodName             = "/api.StockService/GetItems"
-	StockService_ReserveStock_FullMethodName         = "/api.StockService/ReserveStock"
+	StockService_CheckIfItemIsInStock_FullMethodName       = "/api.StockService/CheckIfItemIsInStock"
+	StockService_GetItems_FullMethodName                   = "/api.StockService/GetItems"
+	StockService_ReserveStock_FullMethodName               = "/api.StockService/ReserveStock"
+	StockService_RenewReservation_FullMethodName           = "/api.StockService/RenewReservation"
+	StockService_RestockItems_FullMethodName               = "/api.StockService/RestockItems"
+	StockService_ToggleItemAvailability_FullMethodName     = "/api.StockService/ToggleItemAvailability"
+	StockService_ReleaseReservation_FullMethodName         = "/api.StockService/ReleaseReservation"
+	StockService_ConfirmReservation_FullMethodName         = "/api.StockService/ConfirmReservation"
+	StockService_CleanupExpiredReservations_FullMethodName = "/api.StockService/CleanupExpiredReservations"
+	StockService_GetReservation_FullMethodName             = "/api.StockService/GetReservation"
+	StockService_GetInventorySummary_FullMethodName        = "/api.StockService/GetInventorySummary"
+	StockService_BulkCreateItems_FullMethodName            = "/api.StockService/BulkCreateItems"
 )
 
 // StockServiceClient is the client API for StockService service.
@@ -275,6 +604,24 @@ type StockServiceClient interface {
 	GetItems(ctx context.Context, in *GetItemsRequest, opts ...grpc.CallOption) (*GetItemsResponse, error)
 	// Orders → Stock: Stock reservieren (15 min hold vor Payment)
 	ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*ReserveStockResponse, error)
+	// Gateway → Stock: Reservation verlängern/erneuern (neuer Payment Link)
+	RenewReservation(ctx context.Context, in *RenewReservationRequest, opts ...grpc.CallOption) (*ReserveStockResponse, error)
+	// Orders → Stock: Stock zurückbuchen (Order nachträglich reduziert)
+	RestockItems(ctx context.Context, in *RestockItemsRequest, opts ...grpc.CallOption) (*RestockItemsResponse, error)
+	// Gateway (Admin) → Stock: Item vorübergehend aus dem Verkauf nehmen / wieder freigeben
+	ToggleItemAvailability(ctx context.Context, in *ToggleItemAvailabilityRequest, opts ...grpc.CallOption) (*Item, error)
+	// Orders → Stock: Reservation freigeben (Order storniert)
+	ReleaseReservation(ctx context.Context, in *ReleaseReservationRequest, opts ...grpc.CallOption) (*ReleaseReservationResponse, error)
+	// Payments → Stock: Reservation bestätigen (Order bezahlt, idempotent)
+	ConfirmReservation(ctx context.Context, in *ConfirmReservationRequest, opts ...grpc.CallOption) (*ConfirmReservationResponse, error)
+	// Gateway (Admin) → Stock: Abgelaufene Reservierungen SOFORT freigeben statt auf den Timer zu warten
+	CleanupExpiredReservations(ctx context.Context, in *CleanupExpiredReservationsRequest, opts ...grpc.CallOption) (*CleanupExpiredReservationsResponse, error)
+	// Support/Gateway → Stock: Reservierungsstatus einer Order (Debugging hängender Orders)
+	GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*GetReservationResponse, error)
+	// Gateway (Admin) → Stock: Bestand, Reservierungen + Verfügbarkeit pro Item
+	GetInventorySummary(ctx context.Context, in *GetInventorySummaryRequest, opts ...grpc.CallOption) (*GetInventorySummaryResponse, error)
+	// Gateway (Admin) → Stock: Items importieren (eine Transaktion, Fehler pro Zeile)
+	BulkCreateItems(ctx context.Context, in *BulkCreateItemsRequest, opts ...grpc.CallOption) (*BulkCreateItemsResponse, error)
 }
 
 type stockServiceClient struct {
@@ -315,6 +662,96 @@ func (c *stockServiceClient) ReserveStock(ctx context.Context, in *ReserveStockR
 	return out, nil
 }
 
+func (c *stockServiceClient) RenewReservation(ctx context.Context, in *RenewReservationRequest, opts ...grpc.CallOption) (*ReserveStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReserveStockResponse)
+	err := c.cc.Invoke(ctx, StockService_RenewReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) RestockItems(ctx context.Context, in *RestockItemsRequest, opts ...grpc.CallOption) (*RestockItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestockItemsResponse)
+	err := c.cc.Invoke(ctx, StockService_RestockItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) ToggleItemAvailability(ctx context.Context, in *ToggleItemAvailabilityRequest, opts ...grpc.CallOption) (*Item, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Item)
+	err := c.cc.Invoke(ctx, StockService_ToggleItemAvailability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) ReleaseReservation(ctx context.Context, in *ReleaseReservationRequest, opts ...grpc.CallOption) (*ReleaseReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseReservationResponse)
+	err := c.cc.Invoke(ctx, StockService_ReleaseReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) ConfirmReservation(ctx context.Context, in *ConfirmReservationRequest, opts ...grpc.CallOption) (*ConfirmReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmReservationResponse)
+	err := c.cc.Invoke(ctx, StockService_ConfirmReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) CleanupExpiredReservations(ctx context.Context, in *CleanupExpiredReservationsRequest, opts ...grpc.CallOption) (*CleanupExpiredReservationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CleanupExpiredReservationsResponse)
+	err := c.cc.Invoke(ctx, StockService_CleanupExpiredReservations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*GetReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetReservationResponse)
+	err := c.cc.Invoke(ctx, StockService_GetReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) GetInventorySummary(ctx context.Context, in *GetInventorySummaryRequest, opts ...grpc.CallOption) (*GetInventorySummaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInventorySummaryResponse)
+	err := c.cc.Invoke(ctx, StockService_GetInventorySummary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stockServiceClient) BulkCreateItems(ctx context.Context, in *BulkCreateItemsRequest, opts ...grpc.CallOption) (*BulkCreateItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkCreateItemsResponse)
+	err := c.cc.Invoke(ctx, StockService_BulkCreateItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StockServiceServer is the server API for StockService service.
 // All implementations must embed UnimplementedStockServiceServer
 // for forward compatibility.
@@ -330,6 +767,24 @@ type StockServiceServer interface {
 	GetItems(context.Context, *GetItemsRequest) (*GetItemsResponse, error)
 	// Orders → Stock: Stock reservieren (15 min hold vor Payment)
 	ReserveStock(context.Context, *ReserveStockRequest) (*ReserveStockResponse, error)
+	// Gateway → Stock: Reservation verlängern/erneuern (neuer Payment Link)
+	RenewReservation(context.Context, *RenewReservationRequest) (*ReserveStockResponse, error)
+	// Orders → Stock: Stock zurückbuchen (Order nachträglich reduziert)
+	RestockItems(context.Context, *RestockItemsRequest) (*RestockItemsResponse, error)
+	// Gateway (Admin) → Stock: Item vorübergehend aus dem Verkauf nehmen / wieder freigeben
+	ToggleItemAvailability(context.Context, *ToggleItemAvailabilityRequest) (*Item, error)
+	// Orders → Stock: Reservation freigeben (Order storniert)
+	ReleaseReservation(context.Context, *ReleaseReservationRequest) (*ReleaseReservationResponse, error)
+	// Payments → Stock: Reservation bestätigen (Order bezahlt, idempotent)
+	ConfirmReservation(context.Context, *ConfirmReservationRequest) (*ConfirmReservationResponse, error)
+	// Gateway (Admin) → Stock: Abgelaufene Reservierungen SOFORT freigeben statt auf den Timer zu warten
+	CleanupExpiredReservations(context.Context, *CleanupExpiredReservationsRequest) (*CleanupExpiredReservationsResponse, error)
+	// Support/Gateway → Stock: Reservierungsstatus einer Order (Debugging hängender Orders)
+	GetReservation(context.Context, *GetReservationRequest) (*GetReservationResponse, error)
+	// Gateway (Admin) → Stock: Bestand, Reservierungen + Verfügbarkeit pro Item
+	GetInventorySummary(context.Context, *GetInventorySummaryRequest) (*GetInventorySummaryResponse, error)
+	// Gateway (Admin) → Stock: Items importieren (eine Transaktion, Fehler pro Zeile)
+	BulkCreateItems(context.Context, *BulkCreateItemsRequest) (*BulkCreateItemsResponse, error)
 	mustEmbedUnimplementedStockServiceServer()
 }
 
@@ -349,6 +804,33 @@ func (UnimplementedStockServiceServer) GetItems(context.Context, *GetItemsReques
 func (UnimplementedStockServiceServer) ReserveStock(context.Context, *ReserveStockRequest) (*ReserveStockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReserveStock not implemented")
 }
+func (UnimplementedStockServiceServer) RenewReservation(context.Context, *RenewReservationRequest) (*ReserveStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewReservation not implemented")
+}
+func (UnimplementedStockServiceServer) RestockItems(context.Context, *RestockItemsRequest) (*RestockItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestockItems not implemented")
+}
+func (UnimplementedStockServiceServer) ToggleItemAvailability(context.Context, *ToggleItemAvailabilityRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ToggleItemAvailability not implemented")
+}
+func (UnimplementedStockServiceServer) ReleaseReservation(context.Context, *ReleaseReservationRequest) (*ReleaseReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseReservation not implemented")
+}
+func (UnimplementedStockServiceServer) ConfirmReservation(context.Context, *ConfirmReservationRequest) (*ConfirmReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmReservation not implemented")
+}
+func (UnimplementedStockServiceServer) CleanupExpiredReservations(context.Context, *CleanupExpiredReservationsRequest) (*CleanupExpiredReservationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CleanupExpiredReservations not implemented")
+}
+func (UnimplementedStockServiceServer) GetReservation(context.Context, *GetReservationRequest) (*GetReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReservation not implemented")
+}
+func (UnimplementedStockServiceServer) GetInventorySummary(context.Context, *GetInventorySummaryRequest) (*GetInventorySummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInventorySummary not implemented")
+}
+func (UnimplementedStockServiceServer) BulkCreateItems(context.Context, *BulkCreateItemsRequest) (*BulkCreateItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkCreateItems not implemented")
+}
 func (UnimplementedStockServiceServer) mustEmbedUnimplementedStockServiceServer() {}
 func (UnimplementedStockServiceServer) testEmbeddedByValue()                      {}
 
@@ -424,6 +906,168 @@ func _StockService_ReserveStock_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StockService_RenewReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).RenewReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_RenewReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).RenewReservation(ctx, req.(*RenewReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_RestockItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestockItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).RestockItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_RestockItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).RestockItems(ctx, req.(*RestockItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_ToggleItemAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ToggleItemAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).ToggleItemAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_ToggleItemAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).ToggleItemAvailability(ctx, req.(*ToggleItemAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_ReleaseReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).ReleaseReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_ReleaseReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).ReleaseReservation(ctx, req.(*ReleaseReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_ConfirmReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).ConfirmReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_ConfirmReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).ConfirmReservation(ctx, req.(*ConfirmReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_CleanupExpiredReservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CleanupExpiredReservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).CleanupExpiredReservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_CleanupExpiredReservations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).CleanupExpiredReservations(ctx, req.(*CleanupExpiredReservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_GetReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).GetReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_GetReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).GetReservation(ctx, req.(*GetReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_GetInventorySummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInventorySummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).GetInventorySummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_GetInventorySummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).GetInventorySummary(ctx, req.(*GetInventorySummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StockService_BulkCreateItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkCreateItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).BulkCreateItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_BulkCreateItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).BulkCreateItems(ctx, req.(*BulkCreateItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StockService_ServiceDesc is the grpc.ServiceDesc for StockService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -443,6 +1087,42 @@ var StockService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReserveStock",
 			Handler:    _StockService_ReserveStock_Handler,
 		},
+		{
+			MethodName: "RenewReservation",
+			Handler:    _StockService_RenewReservation_Handler,
+		},
+		{
+			MethodName: "RestockItems",
+			Handler:    _StockService_RestockItems_Handler,
+		},
+		{
+			MethodName: "ToggleItemAvailability",
+			Handler:    _StockService_ToggleItemAvailability_Handler,
+		},
+		{
+			MethodName: "ReleaseReservation",
+			Handler:    _StockService_ReleaseReservation_Handler,
+		},
+		{
+			MethodName: "ConfirmReservation",
+			Handler:    _StockService_ConfirmReservation_Handler,
+		},
+		{
+			MethodName: "CleanupExpiredReservations",
+			Handler:    _StockService_CleanupExpiredReservations_Handler,
+		},
+		{
+			MethodName: "GetReservation",
+			Handler:    _StockService_GetReservation_Handler,
+		},
+		{
+			MethodName: "GetInventorySummary",
+			Handler:    _StockService_GetInventorySummary_Handler,
+		},
+		{
+			MethodName: "BulkCreateItems",
+			Handler:    _StockService_BulkCreateItems_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "oms.proto",
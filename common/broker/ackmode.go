@@ -0,0 +1,59 @@
+package broker
+
+import (
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AckMode: Wann RabbitMQ eine Message als erledigt betrachtet
+type AckMode int
+
+const (
+	// AckManual: Consumer ackt/nackt selbst (Default für alles was Geld/Stock/Status betrifft)
+	// → Crash während der Verarbeitung → Message wird erneut zugestellt, Retry + DLQ greifen
+	AckManual AckMode = iota
+
+	// AckAuto: RabbitMQ ackt beim Zustellen
+	// → Mehr Durchsatz, aber Crash = Message weg, KEIN Retry, KEINE DLQ
+	// → Nur für Low-Value Consumer (z.B. Telemetrie/Logging) bei denen Verlust OK ist
+	AckAuto
+)
+
+func (m AckMode) String() string {
+	if m == AckAuto {
+		return "auto"
+	}
+	return "manual"
+}
+
+// Consume: ch.Consume mit explizitem AckMode statt nacktem autoAck bool
+// → Am Aufruf sieht man sofort welche Garantie der Consumer hat
+func Consume(ch Channel, queue string, mode AckMode) (<-chan amqp.Delivery, error) {
+	return ch.Consume(
+		queue,
+		"",              // consumer tag: auto-generiert
+		mode == AckAuto, // auto-ack
+		false,           // exclusive
+		false,           // no-local
+		false,           // no-wait
+		nil,             // args
+	)
+}
+
+// Ack: Bestätigt die Delivery, außer im AckAuto Mode
+// Warum nicht einfach d.Ack?
+// → Ack auf eine auto-ge-ackte Delivery = PRECONDITION_FAILED → RabbitMQ schließt den Channel
+// → Handler Code bleibt gleich, egal in welchem Mode der Consumer läuft
+func Ack(d *amqp.Delivery, mode AckMode) error {
+	if mode == AckAuto {
+		return nil
+	}
+	return d.Ack(false)
+}
+
+// Nack: Wie Ack → im AckAuto Mode gibt es nichts zurückzuweisen (Message ist schon weg)
+func Nack(d *amqp.Delivery, mode AckMode, requeue bool) error {
+	if mode == AckAuto {
+		return nil
+	}
+	return d.Nack(false, requeue)
+}
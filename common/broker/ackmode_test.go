@@ -0,0 +1,75 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+// consumeOne: Queue deklarieren, im gegebenen Mode konsumieren und EINE Message zustellen
+func consumeOne(t *testing.T, b *brokertest.Broker, mode broker.AckMode) amqp.Delivery {
+	t.Helper()
+
+	const queue = "demand.log"
+	if _, err := b.QueueDeclare(queue, false, false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := broker.Consume(b, queue, mode)
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if err := b.PublishWithContext(context.Background(), "", queue, false, false, amqp.Publishing{Body: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	return <-msgs
+}
+
+func TestAckAutoNeedsNoManualAck(t *testing.T) {
+	b := brokertest.New()
+	d := consumeOne(t, b, broker.AckAuto)
+
+	// Beim Zustellen schon erledigt → nichts pending, obwohl niemand ackt
+	if n := b.Pending(); n != 0 {
+		t.Fatalf("pending = %d; want 0 in auto-ack mode", n)
+	}
+
+	// Handler Code ruft trotzdem broker.Ack/Nack → No-Op statt PRECONDITION_FAILED
+	if err := broker.Ack(&d, broker.AckAuto); err != nil {
+		t.Fatalf("Ack in auto mode: %v", err)
+	}
+	if err := broker.Nack(&d, broker.AckAuto, false); err != nil {
+		t.Fatalf("Nack in auto mode: %v", err)
+	}
+	if n := len(b.Acked()) + len(b.Nacked()); n != 0 {
+		t.Fatalf("settled %d deliveries on the channel; want 0", n)
+	}
+
+	// Direktes d.Ack wäre der Fehler, den broker.Ack verhindert
+	if err := d.Ack(false); err == nil {
+		t.Fatal("manual Ack of an auto-acked delivery succeeded; want error")
+	}
+}
+
+func TestAckManualRequiresAck(t *testing.T) {
+	b := brokertest.New()
+	d := consumeOne(t, b, broker.AckManual)
+
+	if n := b.Pending(); n != 1 {
+		t.Fatalf("pending = %d; want 1 until acked", n)
+	}
+	if err := broker.Ack(&d, broker.AckManual); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if n := b.Pending(); n != 0 {
+		t.Fatalf("pending after Ack = %d; want 0", n)
+	}
+}
+
+func TestAckModeString(t *testing.T) {
+	if broker.AckManual.String() != "manual" || broker.AckAuto.String() != "auto" {
+		t.Fatalf("String() = %q/%q; want manual/auto", broker.AckManual, broker.AckAuto)
+	}
+}
@@ -0,0 +1,390 @@
+// Package brokertest: In-Memory Broker für Consumer/Publisher Tests ohne RabbitMQ
+//
+// Usage:
+//
+//	b := brokertest.New()
+//	go consumer.Listen(b)
+//	b.PublishWithContext(ctx, "", broker.OrderPaidEvent, false, false, amqp.Publishing{Body: body})
+//	b.WaitForAcks(1, time.Second)
+package brokertest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+)
+
+// queueBuffer: Wie viele unkonsumierte Messages eine Queue halten kann
+const queueBuffer = 1024
+
+// Message: Eine über den Broker publizierte Message (für Assertions)
+type Message struct {
+	Exchange   string
+	RoutingKey string
+	Publishing amqp.Publishing
+}
+
+type queue struct {
+	name       string
+	args       amqp.Table
+	deliveries chan amqp.Delivery
+	autoAck    bool // Consume(autoAck=true) → Deliveries sind sofort erledigt
+}
+
+// Broker: Minimaler RabbitMQ Ersatz
+// → Default Exchange ("") routed direkt zur Queue mit Namen = Routing Key
+// → Andere Exchanges verhalten sich wie "direct" (Binding Key "" = alles)
+// → Nack ohne Requeue → x-dead-letter-exchange der Queue (wie echtes RabbitMQ)
+type Broker struct {
+	mu        sync.Mutex
+	queues    map[string]*queue
+	bindings  map[string][]binding // exchange → bindings
+	published []Message
+	pending   map[uint64]amqp.Delivery // Delivery Tag → noch nicht ge-ackt
+	source    map[uint64]string        // Delivery Tag → Queue aus der sie kam (für Requeue)
+	consumers map[string]string        // Consumer Tag → Queue (für Cancel)
+	acked     []uint64
+	nacked    []uint64
+	nextTag   uint64
+	closed    bool
+}
+
+type binding struct {
+	queue string
+	key   string
+}
+
+var _ broker.Channel = (*Broker)(nil)
+
+func New() *Broker {
+	return &Broker{
+		queues:    make(map[string]*queue),
+		bindings:  make(map[string][]binding),
+		pending:   make(map[uint64]amqp.Delivery),
+		source:    make(map[uint64]string),
+		consumers: make(map[string]string),
+	}
+}
+
+func (b *Broker) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queues[name]; !ok {
+		b.queues[name] = &queue{
+			name:       name,
+			args:       args,
+			deliveries: make(chan amqp.Delivery, queueBuffer),
+		}
+	}
+
+	return amqp.Queue{Name: name, Messages: len(b.queues[name].deliveries)}, nil
+}
+
+func (b *Broker) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queues[name]; !ok {
+		return fmt.Errorf("queue %s not declared", name)
+	}
+
+	b.bindings[exchange] = append(b.bindings[exchange], binding{queue: name, key: key})
+	return nil
+}
+
+func (b *Broker) Consume(queueName, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil, fmt.Errorf("queue %s not declared", queueName)
+	}
+
+	q.autoAck = autoAck
+	if consumer != "" {
+		b.consumers[consumer] = queueName
+	}
+	return q.deliveries, nil
+}
+
+// Cancel: basic.cancel → Delivery Channel des Consumers wird geschlossen (wie amqp091 nach Cancel-Ok)
+// → Schon zugestellte Messages bleiben im alten Channel (Consumer kann sie noch nacken)
+// → Neue Publishes landen in einem frischen Channel → warten auf den nächsten Consumer
+func (b *Broker) Cancel(consumer string, noWait bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Broker schon zu (Test Cleanup) → wie amqp091 auf einem geschlossenen Channel
+	if b.closed {
+		return amqp.ErrClosed
+	}
+	name, ok := b.consumers[consumer]
+	if !ok {
+		return fmt.Errorf("unknown consumer %s", consumer)
+	}
+	delete(b.consumers, consumer)
+
+	q := b.queues[name]
+	close(q.deliveries)
+	q.deliveries = make(chan amqp.Delivery, queueBuffer)
+	return nil
+}
+
+// Get: basic.get → nächste Message der Queue oder ok=false wenn leer
+func (b *Broker) Get(queueName string, autoAck bool) (amqp.Delivery, bool, error) {
+	b.mu.Lock()
+	q, ok := b.queues[queueName]
+	b.mu.Unlock()
+	if !ok {
+		return amqp.Delivery{}, false, fmt.Errorf("queue %s not declared", queueName)
+	}
+
+	select {
+	case d := <-q.deliveries:
+		if autoAck {
+			return d, true, b.Ack(d.DeliveryTag, false)
+		}
+		return d, true, nil
+	default:
+		return amqp.Delivery{}, false, nil
+	}
+}
+
+func (b *Broker) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return amqp.ErrClosed
+	}
+
+	b.published = append(b.published, Message{Exchange: exchange, RoutingKey: key, Publishing: msg})
+	return b.route(exchange, key, msg)
+}
+
+func (b *Broker) Qos(prefetchCount, prefetchSize int, global bool) error {
+	return nil
+}
+
+// route: Verteilt eine Message an alle passenden Queues (mu muss gehalten werden)
+// → Keine passende Queue = Message verworfen (wie RabbitMQ mit mandatory=false)
+func (b *Broker) route(exchange, key string, msg amqp.Publishing) error {
+	var targets []string
+	if exchange == "" {
+		targets = []string{key}
+	} else {
+		for _, bind := range b.bindings[exchange] {
+			if bind.key == "" || bind.key == key {
+				targets = append(targets, bind.queue)
+			}
+		}
+	}
+
+	for _, name := range targets {
+		q, ok := b.queues[name]
+		if !ok {
+			continue
+		}
+
+		b.nextTag++
+		d := amqp.Delivery{
+			Acknowledger: b,
+			DeliveryTag:  b.nextTag,
+			Exchange:     exchange,
+			RoutingKey:   key,
+			Headers:      msg.Headers,
+			ContentType:  msg.ContentType,
+			DeliveryMode: msg.DeliveryMode,
+			MessageId:    msg.MessageId,
+			Timestamp:    msg.Timestamp,
+			Body:         msg.Body,
+			ConsumerTag:  "brokertest",
+			Redelivered:  false,
+		}
+
+		select {
+		case q.deliveries <- d:
+			// Auto-Ack → nicht pending, ein manuelles Ack schlägt fehl (wie bei RabbitMQ)
+			if !q.autoAck {
+				b.pending[d.DeliveryTag] = d
+				b.source[d.DeliveryTag] = name
+			}
+		default:
+			return fmt.Errorf("queue %s full (%d messages)", name, queueBuffer)
+		}
+	}
+
+	return nil
+}
+
+// =====================================================
+// amqp.Acknowledger → d.Ack / d.Nack / d.Reject landen hier
+// =====================================================
+
+func (b *Broker) Ack(tag uint64, multiple bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.pending[tag]; !ok {
+		return fmt.Errorf("unknown or already acknowledged delivery tag %d", tag)
+	}
+	delete(b.pending, tag)
+	delete(b.source, tag)
+	b.acked = append(b.acked, tag)
+	return nil
+}
+
+func (b *Broker) Nack(tag uint64, multiple, requeue bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	d, ok := b.pending[tag]
+	if !ok {
+		return fmt.Errorf("unknown or already acknowledged delivery tag %d", tag)
+	}
+	source := b.source[tag]
+	delete(b.pending, tag)
+	delete(b.source, tag)
+	b.nacked = append(b.nacked, tag)
+
+	msg := amqp.Publishing{
+		Headers:      d.Headers,
+		ContentType:  d.ContentType,
+		DeliveryMode: d.DeliveryMode,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Body:         d.Body,
+	}
+
+	if requeue {
+		// Zurück in DIE Queue aus der die Message kam (z.B. DLQ), nicht an den Routing Key
+		return b.route("", source, msg)
+	}
+
+	// Dead Lettering: Queue mit x-dead-letter-exchange → DLX, Routing Key = Queue Name
+	// Source Queue statt d.RoutingKey: über Exchanges kann der Routing Key leer/anders sein
+	if q, ok := b.queues[source]; ok {
+		if dlx, ok := q.args["x-dead-letter-exchange"].(string); ok && dlx != "" {
+			return b.route(dlx, source, msg)
+		}
+	}
+	return nil
+}
+
+func (b *Broker) Reject(tag uint64, requeue bool) error {
+	return b.Nack(tag, false, requeue)
+}
+
+// =====================================================
+// Assertions
+// =====================================================
+
+// Published: Alle publizierten Messages (auch die ohne passende Queue)
+func (b *Broker) Published() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.published...)
+}
+
+// QueueArgs: Argumente mit denen die Queue deklariert wurde (nil wenn unbekannt)
+func (b *Broker) QueueArgs(name string) amqp.Table {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if q, ok := b.queues[name]; ok {
+		return q.args
+	}
+	return nil
+}
+
+// Acked / Nacked: Delivery Tags in Reihenfolge der Bestätigung
+func (b *Broker) Acked() []uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]uint64(nil), b.acked...)
+}
+
+func (b *Broker) Nacked() []uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]uint64(nil), b.nacked...)
+}
+
+// Pending: Zugestellte aber noch nicht bestätigte Deliveries (Auto-Ack Queues zählen nie mit)
+func (b *Broker) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// WaitForAcks: Wartet bis mindestens n Deliveries ge-ackt wurden (Consumer läuft async)
+func (b *Broker) WaitForAcks(n int, timeout time.Duration) error {
+	return b.waitFor(func() bool { return len(b.acked) >= n }, timeout, fmt.Sprintf("%d acks", n))
+}
+
+// WaitForNacks: Wie WaitForAcks, nur für Nack/Reject
+func (b *Broker) WaitForNacks(n int, timeout time.Duration) error {
+	return b.waitFor(func() bool { return len(b.nacked) >= n }, timeout, fmt.Sprintf("%d nacks", n))
+}
+
+// WaitForBinding: Wartet bis queue an exchange gebunden ist (Consumer deklariert async in Listen)
+// → Vorher publiziert = Message verworfen, genau wie bei RabbitMQ
+func (b *Broker) WaitForBinding(queue, exchange string, timeout time.Duration) error {
+	return b.waitFor(func() bool {
+		for _, bind := range b.bindings[exchange] {
+			if bind.queue == queue {
+				return true
+			}
+		}
+		return false
+	}, timeout, fmt.Sprintf("binding %s → %s", exchange, queue))
+}
+
+// WaitForQueue: Wartet bis der Consumer seine Queue deklariert hat (Default Exchange braucht kein Binding)
+func (b *Broker) WaitForQueue(name string, timeout time.Duration) error {
+	return b.waitFor(func() bool {
+		_, ok := b.queues[name]
+		return ok
+	}, timeout, fmt.Sprintf("queue %s", name))
+}
+
+func (b *Broker) waitFor(cond func() bool, timeout time.Duration, what string) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		b.mu.Lock()
+		ok := cond()
+		b.mu.Unlock()
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout after %s waiting for %s", timeout, what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Close: Schließt alle Delivery Channels → "for d := range msgs" in Consumern endet
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for _, q := range b.queues {
+		close(q.deliveries)
+	}
+	return nil
+}
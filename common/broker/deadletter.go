@@ -0,0 +1,90 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DefaultMaxBodySize: Größte Message die ein Consumer verarbeitet (1 MiB)
+// → Unsere Events (Order JSON) sind wenige KB groß
+const DefaultMaxBodySize = 1 << 20
+
+// HeaderDeadLetterReason: Warum eine Message in der DLQ gelandet ist (lesbar in der RabbitMQ UI)
+const HeaderDeadLetterReason = "x-dead-letter-reason"
+
+// Retry Header (HandleRetry)
+const (
+	HeaderDeathReason   = "x-death-reason"    // Fehler des letzten Versuchs
+	HeaderFirstFailedAt = "x-first-failed-at" // RFC3339 (UTC), erster fehlgeschlagener Versuch
+)
+
+// MaxBodySize: Konfigurierbar via AMQP_MAX_BODY_BYTES (einmal gelesen)
+var MaxBodySize = sync.OnceValue(func() int {
+	if v := os.Getenv("AMQP_MAX_BODY_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("Invalid AMQP_MAX_BODY_BYTES %q, using default %d", v, DefaultMaxBodySize)
+	}
+	return DefaultMaxBodySize
+})
+
+// RejectOversized: Zu große Deliveries direkt in die DLQ, OHNE json.Unmarshal
+// Warum?
+// → Kaputte/bösartige Multi-MB Message → Unmarshal allokiert ein Vielfaches davon
+// → Retry bringt nichts: Die Message wird nicht kleiner
+//
+// queue: Logischer Queue Name (z.B. broker.OrderPaidEvent) → landet in "<queue>.dlq"
+// Returns: true = Delivery wurde dead-lettered (Caller macht "continue")
+func RejectOversized(ctx context.Context, ch Channel, d *amqp.Delivery, queue string) bool {
+	limit := MaxBodySize()
+	if len(d.Body) <= limit {
+		return false
+	}
+
+	reason := fmt.Sprintf("body size %d exceeds limit of %d bytes", len(d.Body), limit)
+	log.Printf("Rejecting oversized message on %s: %s", queue, reason)
+
+	if err := DeadLetter(ctx, ch, d, queue, reason); err != nil {
+		log.Printf("Failed to dead-letter oversized message on %s: %v", queue, err)
+	}
+	return true
+}
+
+// DeadLetter: Publiziert die Delivery mit Grund-Header an DLX → "<queue>.dlq" und ackt das Original
+// Warum nicht einfach Nack(requeue=false)?
+// → Nack kann keinen Grund mitgeben
+// → RabbitMQ dead-lettert mit dem ORIGINAL Routing Key → bei Exchange-Publishes ("" Key) findet die DLX keine passende DLQ
+func DeadLetter(ctx context.Context, ch Channel, d *amqp.Delivery, queue, reason string) error {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[HeaderDeadLetterReason] = reason
+
+	err := ch.PublishWithContext(ctx,
+		DLX,   // exchange: "dlx"
+		queue, // routing key = Queue Name → "<queue>.dlq"
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  d.ContentType,
+			Headers:      headers,
+			Body:         d.Body,
+			DeliveryMode: amqp.Persistent,
+		},
+	)
+	if err != nil {
+		// Publish fehlgeschlagen → klassisch Nack, damit die Message nicht verloren geht
+		d.Nack(false, false)
+		return fmt.Errorf("failed to publish to %s: %w", DLX, err)
+	}
+
+	return d.Ack(false)
+}
@@ -0,0 +1,97 @@
+package broker_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+// deliver: Publiziert body über den Default Exchange an queue und liefert die Delivery zurück
+func deliver(t *testing.T, b *brokertest.Broker, queue string, body []byte) amqp.Delivery {
+	t.Helper()
+
+	if _, err := b.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := b.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.PublishWithContext(context.Background(), "", queue, false, false, amqp.Publishing{Body: body}); err != nil {
+		t.Fatal(err)
+	}
+	return <-msgs
+}
+
+func TestRejectOversizedDeadLettersWithReason(t *testing.T) {
+	b := brokertest.New()
+	d := deliver(t, b, broker.OrderPaidEvent, bytes.Repeat([]byte("x"), broker.MaxBodySize()+1))
+
+	if !broker.RejectOversized(context.Background(), b, &d, broker.OrderPaidEvent) {
+		t.Fatal("oversized delivery was not rejected")
+	}
+
+	// Original ge-ackt (nicht nochmal zugestellt), Kopie mit Grund an die DLX → "<queue>.dlq"
+	if acked := b.Acked(); len(acked) != 1 || acked[0] != d.DeliveryTag {
+		t.Fatalf("acked = %v; want [%d]", acked, d.DeliveryTag)
+	}
+	published := b.Published()
+	last := published[len(published)-1]
+	if last.Exchange != broker.DLX || last.RoutingKey != broker.OrderPaidEvent {
+		t.Fatalf("dead letter published to %q/%q; want %q/%q", last.Exchange, last.RoutingKey, broker.DLX, broker.OrderPaidEvent)
+	}
+	if reason, _ := last.Publishing.Headers[broker.HeaderDeadLetterReason].(string); reason == "" {
+		t.Error("dead letter has no reason header")
+	}
+}
+
+func TestRejectOversizedPassesSmallBody(t *testing.T) {
+	b := brokertest.New()
+	d := deliver(t, b, broker.OrderPaidEvent, []byte(`{"id":"o1"}`))
+
+	if broker.RejectOversized(context.Background(), b, &d, broker.OrderPaidEvent) {
+		t.Fatal("small delivery was rejected")
+	}
+	if n := len(b.Acked()) + len(b.Nacked()); n != 0 {
+		t.Fatalf("delivery was settled %d times; want untouched for the handler", n)
+	}
+}
+
+func TestFakeNackDeadLettersBySourceQueue(t *testing.T) {
+	b := brokertest.New()
+	ctx := context.Background()
+
+	// Queue an einem Exchange mit leerem Routing Key → Dead Letter muss trotzdem "<queue>.dlq" erreichen
+	if _, err := b.QueueDeclare("orders.q", true, false, false, false, amqp.Table{"x-dead-letter-exchange": broker.DLX}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.QueueBind("orders.q", "", "orders.fanout", false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.QueueDeclare("orders.q.dlq", true, false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.QueueBind("orders.q.dlq", "orders.q", broker.DLX, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := b.Consume("orders.q", "", false, false, false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.PublishWithContext(ctx, "orders.fanout", "", false, false, amqp.Publishing{Body: []byte(`{"id":"o1"}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := <-msgs
+	if err := d.Nack(false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := b.Get("orders.q.dlq", true); err != nil || !ok {
+		t.Fatalf("dead letter not in orders.q.dlq (ok=%v, err=%v)", ok, err)
+	}
+}
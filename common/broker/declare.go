@@ -0,0 +1,139 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// maxConflictRecreates: Obergrenze für Delete+Recreate Runden in Connect
+// → Jede Runde behebt EINEN Konflikt (der Server schließt den Channel beim ersten 406)
+const maxConflictRecreates = 10
+
+// DeclareConflictError: Queue/Exchange existiert schon mit ANDEREN Argumenten (AMQP 406 PRECONDITION_FAILED)
+// Warum eigener Fehler?
+// → Vorher: "failed to create DLQ: Exception (406) ..." → unklar WAS kollidiert und was zu tun ist
+// → Jetzt: Kind + Name → Caller kann gezielt löschen (Dev) oder klar loggen (Prod)
+type DeclareConflictError struct {
+	Kind string // "queue" oder "exchange"
+	Name string
+	Err  error
+}
+
+func (e *DeclareConflictError) Error() string {
+	return fmt.Sprintf("%s %q already exists with different arguments: %v", e.Kind, e.Name, e.Err)
+}
+
+func (e *DeclareConflictError) Unwrap() error {
+	return e.Err
+}
+
+// IsPreconditionFailed: true bei AMQP 406 (z.B. Queue mit anderen x-arguments/durable Flag redeklariert)
+func IsPreconditionFailed(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed
+}
+
+// declareError: 406 → *DeclareConflictError, alles andere unverändert
+func declareError(kind, name string, err error) error {
+	if err == nil || !IsPreconditionFailed(err) {
+		return err
+	}
+	return &DeclareConflictError{Kind: kind, Name: name, Err: err}
+}
+
+// RecreateOnConflict: AMQP_RECREATE_ON_CONFLICT=true → kollidierende Queues/Exchanges löschen und neu anlegen
+// ⚠️ NUR für Dev: Löschen einer Queue verwirft alle Messages darin (z.B. eine volle DLQ)
+func RecreateOnConflict() bool {
+	v := os.Getenv("AMQP_RECREATE_ON_CONFLICT")
+	if v == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("Invalid AMQP_RECREATE_ON_CONFLICT %q, conflict recreation disabled", v)
+		return false
+	}
+	return enabled
+}
+
+// declarer: Die Operationen die für Delete+Recreate gebraucht werden (*amqp.Channel erfüllt es)
+type declarer interface {
+	QueueDelete(name string, ifUnused, ifEmpty, noWait bool) (int, error)
+	ExchangeDelete(name string, ifUnused, noWait bool) error
+}
+
+// deleteConflicting: Entfernt die kollidierende Queue/Exchange → nächster Declare legt sie mit UNSEREN Argumenten an
+func deleteConflicting(ch declarer, conflict *DeclareConflictError) error {
+	switch conflict.Kind {
+	case "queue":
+		purged, err := ch.QueueDelete(conflict.Name, false, false, false)
+		if err != nil {
+			return err
+		}
+		log.Printf("Deleted conflicting queue %s (%d messages dropped), recreating", conflict.Name, purged)
+	case "exchange":
+		if err := ch.ExchangeDelete(conflict.Name, false, false); err != nil {
+			return err
+		}
+		log.Printf("Deleted conflicting exchange %s, recreating", conflict.Name)
+	default:
+		return fmt.Errorf("unknown conflict kind %q", conflict.Kind)
+	}
+	return nil
+}
+
+// logConflict: Klare Meldung statt nackter 406 → Operator weiß sofort was zu tun ist
+func logConflict(conflict *DeclareConflictError) {
+	log.Printf("RabbitMQ %s %q exists with different arguments (406 PRECONDITION_FAILED). "+
+		"Delete it manually (rabbitmqadmin delete %s name=%s) or set AMQP_RECREATE_ON_CONFLICT=true in dev",
+		conflict.Kind, conflict.Name, conflict.Kind, conflict.Name)
+}
+
+// setupTopology: DLX/DLQs + Exchanges deklarieren, bei 406 optional Delete+Recreate
+// Warum neuer Channel pro Runde?
+// → Der Server schließt den Channel bei einem 406 → weiter deklarieren geht nur auf einem frischen
+// → Gibt den (offenen) Channel zurück den der Caller weiter nutzt
+func setupTopology(conn *amqp.Connection, ch *amqp.Channel, recreate bool) (*amqp.Channel, error) {
+	for attempt := 0; ; attempt++ {
+		err := declareTopology(ch)
+
+		var conflict *DeclareConflictError
+		if err == nil || !errors.As(err, &conflict) {
+			return ch, err
+		}
+
+		logConflict(conflict)
+		if !recreate || attempt >= maxConflictRecreates {
+			return ch, err
+		}
+
+		if ch, err = conn.Channel(); err != nil {
+			return nil, fmt.Errorf("failed to reopen channel after conflict: %w", err)
+		}
+		if err := deleteConflicting(ch, conflict); err != nil {
+			return ch, fmt.Errorf("failed to delete conflicting %s %s: %w", conflict.Kind, conflict.Name, err)
+		}
+	}
+}
+
+// declareTopology: Alles was vor dem ersten Publish/Consume existieren muss
+func declareTopology(ch *amqp.Channel) error {
+	// Warum DLQ/DLX Setup hier?
+	// → Wird einmal beim Connect aufgerufen
+	// → Alle Services nutzen gleiche DLQ Infrastruktur
+	if err := createDLQAndDLX(ch); err != nil {
+		return fmt.Errorf("failed to create DLQ: %w", err)
+	}
+
+	// Warum Exchanges hier deklarieren?
+	// → Exchanges müssen existieren BEVOR Services daran binden
+	if err := createExchanges(ch); err != nil {
+		return fmt.Errorf("failed to create exchanges: %w", err)
+	}
+	return nil
+}
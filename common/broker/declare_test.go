@@ -0,0 +1,112 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// conflictErr: So meldet RabbitMQ einen Redeclare mit anderen x-arguments
+func conflictErr() *amqp.Error {
+	return &amqp.Error{
+		Code:   amqp.PreconditionFailed,
+		Reason: "PRECONDITION_FAILED - inequivalent arg 'x-message-ttl' for queue 'order.paid.dlq'",
+	}
+}
+
+func TestDeclareErrorDetectsArgConflict(t *testing.T) {
+	err := fmt.Errorf("failed to declare DLQ order.paid.dlq: %w", declareError("queue", "order.paid.dlq", conflictErr()))
+	// So kommt er aus declareTopology → noch eine Schicht Wrapping
+	err = fmt.Errorf("failed to create DLQ: %w", err)
+
+	var conflict *DeclareConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("errors.As(%v) = false; want *DeclareConflictError", err)
+	}
+	if conflict.Kind != "queue" || conflict.Name != "order.paid.dlq" {
+		t.Fatalf("conflict = %s %q; want queue %q", conflict.Kind, conflict.Name, "order.paid.dlq")
+	}
+	if !IsPreconditionFailed(err) {
+		t.Fatal("IsPreconditionFailed = false; want true through the wrapped chain")
+	}
+}
+
+func TestDeclareErrorPassesOtherErrorsThrough(t *testing.T) {
+	if err := declareError("queue", "q", nil); err != nil {
+		t.Fatalf("declareError(nil) = %v; want nil", err)
+	}
+
+	notFound := &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND"}
+	err := declareError("exchange", "order.paid", notFound)
+	if err != notFound {
+		t.Fatalf("declareError(404) = %v; want the original error", err)
+	}
+	var conflict *DeclareConflictError
+	if errors.As(err, &conflict) {
+		t.Fatal("404 reported as arg conflict")
+	}
+}
+
+// fakeDeclarer: Merkt sich was gelöscht wurde
+type fakeDeclarer struct {
+	deletedQueues    []string
+	deletedExchanges []string
+	err              error
+}
+
+func (f *fakeDeclarer) QueueDelete(name string, _, _, _ bool) (int, error) {
+	f.deletedQueues = append(f.deletedQueues, name)
+	return 3, f.err
+}
+
+func (f *fakeDeclarer) ExchangeDelete(name string, _, _ bool) error {
+	f.deletedExchanges = append(f.deletedExchanges, name)
+	return f.err
+}
+
+func TestDeleteConflicting(t *testing.T) {
+	ch := &fakeDeclarer{}
+
+	if err := deleteConflicting(ch, &DeclareConflictError{Kind: "queue", Name: "order.paid.dlq"}); err != nil {
+		t.Fatalf("delete queue: %v", err)
+	}
+	if err := deleteConflicting(ch, &DeclareConflictError{Kind: "exchange", Name: "order.paid"}); err != nil {
+		t.Fatalf("delete exchange: %v", err)
+	}
+	if len(ch.deletedQueues) != 1 || ch.deletedQueues[0] != "order.paid.dlq" {
+		t.Errorf("deleted queues = %v; want [order.paid.dlq]", ch.deletedQueues)
+	}
+	if len(ch.deletedExchanges) != 1 || ch.deletedExchanges[0] != "order.paid" {
+		t.Errorf("deleted exchanges = %v; want [order.paid]", ch.deletedExchanges)
+	}
+
+	if err := deleteConflicting(ch, &DeclareConflictError{Kind: "binding", Name: "x"}); err == nil {
+		t.Error("unknown kind deleted without error")
+	}
+
+	ch.err = errors.New("channel closed")
+	if err := deleteConflicting(ch, &DeclareConflictError{Kind: "queue", Name: "q"}); !errors.Is(err, ch.err) {
+		t.Errorf("delete error = %v; want %v", err, ch.err)
+	}
+}
+
+func TestRecreateOnConflict(t *testing.T) {
+	tests := []struct {
+		env  string
+		want bool
+	}{
+		{"", false},
+		{"true", true},
+		{"1", true},
+		{"false", false},
+		{"yes please", false}, // Ungültig → sicherer Default: nichts löschen
+	}
+	for _, tt := range tests {
+		t.Setenv("AMQP_RECREATE_ON_CONFLICT", tt.env)
+		if got := RecreateOnConflict(); got != tt.want {
+			t.Errorf("RecreateOnConflict(%q) = %v; want %v", tt.env, got, tt.want)
+		}
+	}
+}
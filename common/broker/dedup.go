@@ -0,0 +1,229 @@
+package broker
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DefaultDedupSize: Wie viele verarbeitete MessageIds ein Consumer sich merkt
+const DefaultDedupSize = 10000
+
+// DefaultDedupTTL: Wie lange eine MessageId als "schon verarbeitet" gilt
+// → Redeliveries kommen i.d.R. innerhalb von Sekunden (Reconnect, Nack) → 10m reicht großzügig
+const DefaultDedupTTL = 10 * time.Minute
+
+// MessageID: Deterministische MessageId für ein Order Event (z.B. "order.paid:65f1...")
+// Warum deterministisch statt UUID?
+// → Stripe retried den Webhook → payments publiziert order.paid ZWEIMAL → gleiche ID → Consumer erkennt es
+func MessageID(event, orderID string) string {
+	return event + ":" + orderID
+}
+
+// DedupStore: Wo verarbeitete MessageIds gemerkt werden (austauschbar)
+// → MemoryDedupStore: pro Prozess, Default
+// → RedisDedupStore: geteilt zwischen Instanzen → Redelivery an eine ANDERE Instanz wird auch erkannt
+type DedupStore interface {
+	// AlreadyProcessed: true wenn die ID innerhalb der TTL schon erfolgreich verarbeitet wurde
+	AlreadyProcessed(ctx context.Context, id string) bool
+	// MarkProcessed: ID als verarbeitet merken
+	MarkProcessed(ctx context.Context, id string)
+}
+
+type dedupEntry struct {
+	id     string
+	seenAt time.Time
+}
+
+// MemoryDedupStore: LRU der zuletzt verarbeiteten MessageIds EINES Prozesses
+// Nur ein Cache (nicht persistent) → fängt Redeliveries ab, ersetzt aber keine idempotenten Handler
+type MemoryDedupStore struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // Front = zuletzt verarbeitet
+	entries map[string]*list.Element
+
+	now func() time.Time // Injizierbar → Tests mit Fake Clock
+}
+
+// NewMemoryDedupStore: size = max. gemerkte IDs, ttl <= 0 → DefaultDedupTTL
+func NewMemoryDedupStore(size int, ttl time.Duration) *MemoryDedupStore {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return &MemoryDedupStore{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryDedupStore) AlreadyProcessed(_ context.Context, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[id]
+	if !ok {
+		return false
+	}
+	if s.now().Sub(el.Value.(dedupEntry).seenAt) >= s.ttl {
+		s.order.Remove(el)
+		delete(s.entries, id)
+		return false
+	}
+	return true
+}
+
+// MarkProcessed: Verdrängt bei vollem Cache den ältesten Eintrag
+func (s *MemoryDedupStore) MarkProcessed(_ context.Context, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := dedupEntry{id: id, seenAt: s.now()}
+	if el, ok := s.entries[id]; ok {
+		el.Value = entry
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.entries[id] = s.order.PushFront(entry)
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(dedupEntry).id)
+	}
+}
+
+// Deduplicator: Überspringt schon verarbeitete Deliveries eines Consumers
+// Warum?
+// → RabbitMQ garantiert at-least-once → Redelivery nach Reconnect/Crash vor dem Ack
+// → Ohne Dedup: Order doppelt "paid", zweite Stripe Session, Kitchen Ticket doppelt, ...
+//
+// → nil Deduplicator = deaktiviert, alle Methoden sind dann No-Ops
+type Deduplicator struct {
+	store DedupStore
+}
+
+// NewDeduplicator: In-Memory Dedup, size <= 0 → nil (Dedup aus), ttl <= 0 → DefaultDedupTTL
+func NewDeduplicator(size int, ttl time.Duration) *Deduplicator {
+	if size <= 0 {
+		return nil
+	}
+	return NewDeduplicatorWithStore(NewMemoryDedupStore(size, ttl))
+}
+
+// NewDeduplicatorWithStore: Dedup über einen beliebigen Store (z.B. Redis oder ein Fake in Tests), nil → aus
+func NewDeduplicatorWithStore(store DedupStore) *Deduplicator {
+	if store == nil {
+		return nil
+	}
+	return &Deduplicator{store: store}
+}
+
+// NewDeduplicatorFromEnv: Konfigurierbar via AMQP_DEDUP_SIZE (0 = aus) und AMQP_DEDUP_TTL (z.B. "10m")
+// → AMQP_DEDUP_REDIS_ADDR gesetzt → IDs in Redis (geteilt zwischen allen Instanzen von consumer)
+// → consumer (z.B. "payments") trennt die Keys: order.paid verarbeitet in Stock ≠ verarbeitet in Kitchen
+// → Redis nicht erreichbar → Fallback auf In-Memory (Dedup pro Prozess ist besser als keiner)
+func NewDeduplicatorFromEnv(consumer string) *Deduplicator {
+	size := DefaultDedupSize
+	if v := os.Getenv("AMQP_DEDUP_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			size = n
+		} else {
+			log.Printf("Invalid AMQP_DEDUP_SIZE %q, using default %d", v, DefaultDedupSize)
+		}
+	}
+
+	ttl := DefaultDedupTTL
+	if v := os.Getenv("AMQP_DEDUP_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			ttl = d
+		} else {
+			log.Printf("Invalid AMQP_DEDUP_TTL %q, using default %s", v, DefaultDedupTTL)
+		}
+	}
+
+	if size == 0 {
+		return nil
+	}
+
+	if addr := os.Getenv("AMQP_DEDUP_REDIS_ADDR"); addr != "" {
+		store, err := NewRedisDedupStore(addr, consumer, ttl)
+		if err == nil {
+			log.Printf("Message dedup for %s backed by Redis at %s (ttl %s)", consumer, addr, ttl)
+			return NewDeduplicatorWithStore(store)
+		}
+		log.Printf("Redis dedup store at %s unavailable, falling back to in-memory dedup: %v", addr, err)
+	}
+
+	return NewDeduplicator(size, ttl)
+}
+
+// AlreadyProcessed: true wenn die ID innerhalb der TTL schon erfolgreich verarbeitet wurde
+func (c *Deduplicator) AlreadyProcessed(ctx context.Context, id string) bool {
+	if c == nil || id == "" {
+		return false
+	}
+	return c.store.AlreadyProcessed(ctx, id)
+}
+
+// MarkProcessed: ID als verarbeitet merken
+func (c *Deduplicator) MarkProcessed(ctx context.Context, id string) {
+	if c == nil || id == "" {
+		return
+	}
+	c.store.MarkProcessed(ctx, id)
+}
+
+// DeliveryID: Dedup Key einer Delivery
+// → MessageId (deterministisch gesetzt vom Publisher, siehe MessageID)
+// → Fehlt sie (z.B. fremder Publisher): SHA-256 des Bodys → gleiche Message = gleicher Key
+func DeliveryID(d *amqp.Delivery) string {
+	if d.MessageId != "" {
+		return d.MessageId
+	}
+	if len(d.Body) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(d.Body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Duplicate: Delivery schon verarbeitet → Ack + true (Caller macht "continue" OHNE Handler)
+// Warum Ack statt Nack?
+// → Die Arbeit ist erledigt, die Kopie soll weder retried noch in die DLQ
+func (c *Deduplicator) Duplicate(d *amqp.Delivery) bool {
+	if c == nil {
+		return false
+	}
+	id := DeliveryID(d)
+	if !c.AlreadyProcessed(context.Background(), id) {
+		return false
+	}
+	log.Printf("Skipping duplicate message %s on %s", id, d.RoutingKey)
+	d.Ack(false)
+	return true
+}
+
+// Done: Nach ERFOLGREICHER Verarbeitung aufrufen
+// Warum nicht schon beim Empfang merken?
+// → Handler schlägt fehl → HandleRetry publiziert die Message erneut (gleiche MessageId)
+// → Die Retry Kopie darf NICHT als Duplikat verworfen werden
+func (c *Deduplicator) Done(d *amqp.Delivery) {
+	if c == nil {
+		return
+	}
+	c.MarkProcessed(context.Background(), DeliveryID(d))
+}
@@ -0,0 +1,76 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisDedupKeyPrefix: Namespace der Dedup Keys in Redis ("amqp:processed:stock:order.paid:65f1...")
+const redisDedupKeyPrefix = "amqp:processed:"
+
+// redisDedupTimeout: Obergrenze pro Redis Call → langsames Redis hält den Consumer nicht auf
+const redisDedupTimeout = 500 * time.Millisecond
+
+// RedisDedupStore: Verarbeitete MessageIds in Redis (SET mit TTL)
+// Warum Redis statt nur In-Memory?
+// → Redelivery landet oft bei einer ANDEREN Instanz (Crash, Rebalancing) → deren LRU kennt die ID nicht
+// → Zweite Stripe Session für dieselbe Order
+// Fehler sind fail-open: Redis down → "nicht verarbeitet" → lieber einmal zu oft verarbeiten als eine Message verlieren
+type RedisDedupStore struct {
+	client *redis.Client
+	prefix string // redisDedupKeyPrefix + consumer + ":"
+	ttl    time.Duration
+}
+
+// NewRedisDedupStore: Verbindet zu addr und prüft die Verbindung (PING), ttl <= 0 → DefaultDedupTTL
+// → consumer: Eigener Key-Namespace pro Service → dasselbe Event wird von JEDEM Service einmal verarbeitet
+func NewRedisDedupStore(addr, consumer string, ttl time.Duration) (*RedisDedupStore, error) {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisDedupStore{
+		client: client,
+		prefix: redisDedupKeyPrefix + consumer + ":",
+		ttl:    ttl,
+	}, nil
+}
+
+func (s *RedisDedupStore) AlreadyProcessed(ctx context.Context, id string) bool {
+	ctx, cancel := context.WithTimeout(ctx, redisDedupTimeout)
+	defer cancel()
+
+	n, err := s.client.Exists(ctx, s.prefix+id).Result()
+	if err != nil {
+		log.Printf("Dedup lookup for %s failed, processing anyway: %v", id, err)
+		return false
+	}
+	return n > 0
+}
+
+func (s *RedisDedupStore) MarkProcessed(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(ctx, redisDedupTimeout)
+	defer cancel()
+
+	if err := s.client.Set(ctx, s.prefix+id, 1, s.ttl).Err(); err != nil {
+		log.Printf("Failed to mark message %s as processed: %v", id, err)
+	}
+}
+
+// Close: Redis Verbindung schließen
+func (s *RedisDedupStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,203 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// newTestDedupStore: MemoryDedupStore mit Fake Clock
+func newTestDedupStore(size int, ttl time.Duration) (*MemoryDedupStore, *time.Time) {
+	s := NewMemoryDedupStore(size, ttl)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+// Voller Cache → älteste (am längsten nicht gesehene) ID fliegt raus
+func TestMemoryDedupStoreEvictsLeastRecent(t *testing.T) {
+	s, _ := newTestDedupStore(2, time.Hour)
+	ctx := context.Background()
+
+	s.MarkProcessed(ctx, "a")
+	s.MarkProcessed(ctx, "b")
+	s.MarkProcessed(ctx, "a") // a wieder vorne → b ist jetzt die älteste
+	s.MarkProcessed(ctx, "c")
+
+	if s.AlreadyProcessed(ctx, "b") {
+		t.Error("b still cached; want evicted as least recent")
+	}
+	for _, id := range []string{"a", "c"} {
+		if !s.AlreadyProcessed(ctx, id) {
+			t.Errorf("%s not cached; want kept", id)
+		}
+	}
+}
+
+func TestMemoryDedupStoreExpiresAfterTTL(t *testing.T) {
+	s, now := newTestDedupStore(10, time.Minute)
+	ctx := context.Background()
+
+	s.MarkProcessed(ctx, "a")
+	*now = now.Add(59 * time.Second)
+	if !s.AlreadyProcessed(ctx, "a") {
+		t.Fatal("a forgotten before TTL")
+	}
+	*now = now.Add(time.Second)
+	if s.AlreadyProcessed(ctx, "a") {
+		t.Fatal("a still processed after TTL")
+	}
+}
+
+// Ohne MessageId → Hash des Bodys, gleicher Body = gleicher Key
+func TestDeliveryID(t *testing.T) {
+	if got := DeliveryID(&amqp.Delivery{MessageId: "order.paid:o1", Body: []byte("x")}); got != "order.paid:o1" {
+		t.Errorf("DeliveryID with MessageId = %q; want order.paid:o1", got)
+	}
+
+	a := DeliveryID(&amqp.Delivery{Body: []byte(`{"id":"o1"}`)})
+	b := DeliveryID(&amqp.Delivery{Body: []byte(`{"id":"o1"}`)})
+	c := DeliveryID(&amqp.Delivery{Body: []byte(`{"id":"o2"}`)})
+	if a == "" || a != b || a == c {
+		t.Errorf("body hash ids = %q, %q, %q; want equal for equal bodies, different otherwise", a, b, c)
+	}
+
+	if got := DeliveryID(&amqp.Delivery{}); got != "" {
+		t.Errorf("DeliveryID of empty delivery = %q; want empty", got)
+	}
+}
+
+func TestDeduplicatorDisabled(t *testing.T) {
+	if d := NewDeduplicator(0, time.Minute); d != nil {
+		t.Fatal("size 0 should disable dedup")
+	}
+	var d *Deduplicator
+	d.Done(&amqp.Delivery{MessageId: "a"})
+	if d.Duplicate(&amqp.Delivery{MessageId: "a"}) {
+		t.Fatal("nil deduplicator reported a duplicate")
+	}
+}
+
+// mapDedupStore: Austauschbarer Store für Tests → steht für einen geteilten Store (Redis) mehrerer Instanzen
+type mapDedupStore struct {
+	mu        sync.Mutex
+	processed map[string]bool
+}
+
+func (s *mapDedupStore) AlreadyProcessed(_ context.Context, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processed[id]
+}
+
+func (s *mapDedupStore) MarkProcessed(_ context.Context, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processed[id] = true
+}
+
+// countingAcker: amqp.Acknowledger der nur zählt
+type countingAcker struct{ acks int }
+
+func (a *countingAcker) Ack(uint64, bool) error        { a.acks++; return nil }
+func (a *countingAcker) Nack(uint64, bool, bool) error { return nil }
+func (a *countingAcker) Reject(uint64, bool) error     { return nil }
+
+// Redelivery an eine ANDERE Instanz → geteilter Store erkennt sie trotzdem, Duplikat wird ge-ackt
+func TestDeduplicatorSharedStore(t *testing.T) {
+	shared := &mapDedupStore{processed: make(map[string]bool)}
+	instanceA := NewDeduplicatorWithStore(shared)
+	instanceB := NewDeduplicatorWithStore(shared)
+
+	acker := &countingAcker{}
+	d := &amqp.Delivery{Acknowledger: acker, MessageId: MessageID(OrderCreatedEvent, "o1")}
+
+	if instanceA.Duplicate(d) {
+		t.Fatal("first delivery reported as duplicate")
+	}
+	// Noch nicht Done (z.B. Handler fehlgeschlagen → Retry) → Kopie ist KEIN Duplikat
+	if instanceB.Duplicate(d) {
+		t.Fatal("delivery reported as duplicate before it was processed")
+	}
+
+	instanceA.Done(d)
+	if !instanceB.Duplicate(d) {
+		t.Fatal("redelivery to instance B not detected")
+	}
+	if acker.acks != 1 {
+		t.Fatalf("acks = %d; want 1 (duplicate acked)", acker.acks)
+	}
+}
+
+func TestDeduplicatorAlreadyProcessed(t *testing.T) {
+	d := NewDeduplicatorWithStore(&mapDedupStore{processed: make(map[string]bool)})
+	ctx := context.Background()
+
+	if d.AlreadyProcessed(ctx, "order.paid:o1") {
+		t.Fatal("unseen id reported as processed")
+	}
+	d.MarkProcessed(ctx, "order.paid:o1")
+	if !d.AlreadyProcessed(ctx, "order.paid:o1") {
+		t.Fatal("marked id not reported as processed")
+	}
+	// Leere ID (kein MessageId, leerer Body) → nie dedupliziert
+	d.MarkProcessed(ctx, "")
+	if d.AlreadyProcessed(ctx, "") {
+		t.Fatal("empty id reported as processed")
+	}
+
+	if NewDeduplicatorWithStore(nil) != nil {
+		t.Fatal("nil store should disable dedup")
+	}
+}
+
+// Redis nicht erreichbar → In-Memory statt gar keinem Dedup
+func TestNewDeduplicatorFromEnvFallsBackToMemory(t *testing.T) {
+	t.Setenv("AMQP_DEDUP_SIZE", "")
+	t.Setenv("AMQP_DEDUP_TTL", "")
+	t.Setenv("AMQP_DEDUP_REDIS_ADDR", "127.0.0.1:1") // Port 1 → Connection refused
+
+	d := NewDeduplicatorFromEnv("payments")
+	if d == nil {
+		t.Fatal("dedup disabled; want in-memory fallback")
+	}
+	if _, ok := d.store.(*MemoryDedupStore); !ok {
+		t.Fatalf("store = %T; want *MemoryDedupStore", d.store)
+	}
+}
+
+// Echtes Redis (AMQP_DEDUP_TEST_REDIS_ADDR), sonst Skip → Keys pro Consumer getrennt
+func TestRedisDedupStore(t *testing.T) {
+	addr := os.Getenv("AMQP_DEDUP_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("AMQP_DEDUP_TEST_REDIS_ADDR not set")
+	}
+
+	consumer := fmt.Sprintf("dedup-test-%d", time.Now().UnixNano())
+	stock, err := NewRedisDedupStore(addr, consumer+"-stock", time.Minute)
+	if err != nil {
+		t.Fatalf("NewRedisDedupStore: %v", err)
+	}
+	defer stock.Close()
+	kitchen, err := NewRedisDedupStore(addr, consumer+"-kitchen", time.Minute)
+	if err != nil {
+		t.Fatalf("NewRedisDedupStore: %v", err)
+	}
+	defer kitchen.Close()
+
+	ctx := context.Background()
+	id := MessageID(OrderPaidEvent, "o1")
+	stock.MarkProcessed(ctx, id)
+
+	if !stock.AlreadyProcessed(ctx, id) {
+		t.Fatal("stock: marked id not found")
+	}
+	if kitchen.AlreadyProcessed(ctx, id) {
+		t.Fatal("kitchen: id processed by stock reported as processed")
+	}
+}
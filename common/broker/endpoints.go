@@ -0,0 +1,94 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Dialer: Öffnet eine AMQP Connection zu einer URL
+// → Default amqp.Dial, in Tests ein Fake (erster Node down → zweiter wird genutzt)
+type Dialer func(url string) (*amqp.Connection, error)
+
+// Endpoints: RabbitMQ Cluster Nodes ("host:port"), werden der Reihe nach probiert
+// Warum mehrere?
+// → Geclustertes RabbitMQ: Ein Node down (Update, Crash) → Service verbindet sich zum nächsten
+// → Vorher: EIN Host → Node weg = Service startet nicht
+// Reconnect: Dial beginnt beim Node NACH dem zuletzt verbundenen → toter Node wird nicht zuerst probiert
+type Endpoints struct {
+	addrs []string
+	dial  Dialer
+
+	mu   sync.Mutex
+	next int // Index des ersten Nodes für den nächsten Dial
+}
+
+// ParseEndpoints: "rabbit-1,rabbit-2:5673" → ["rabbit-1:<defaultPort>", "rabbit-2:5673"]
+// → Einträge ohne Port bekommen defaultPort (AMQP_PORT) → AMQP_HOST mit einem Host funktioniert wie bisher
+func ParseEndpoints(hosts, defaultPort string) []string {
+	var endpoints []string
+	for _, h := range strings.Split(hosts, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(h); err != nil {
+			h = net.JoinHostPort(h, defaultPort)
+		}
+		endpoints = append(endpoints, h)
+	}
+	return endpoints
+}
+
+// NewEndpoints: hosts komma-separiert (AMQP_HOST), dial nil → amqp.Dial
+func NewEndpoints(hosts, defaultPort string, dial Dialer) *Endpoints {
+	if dial == nil {
+		dial = amqp.Dial
+	}
+	return &Endpoints{
+		addrs: ParseEndpoints(hosts, defaultPort),
+		dial:  dial,
+	}
+}
+
+// Dial: Probiert alle Nodes einmal, beginnend bei next → erste erfolgreiche Connection gewinnt
+// Returns: Connection + verbundener Endpoint | alle Fehler gesammelt (errors.Join)
+func (e *Endpoints) Dial(user, pass string) (*amqp.Connection, string, error) {
+	if len(e.addrs) == 0 {
+		return nil, "", fmt.Errorf("no rabbitmq endpoints configured")
+	}
+
+	e.mu.Lock()
+	start := e.next
+	e.mu.Unlock()
+
+	var errs []error
+	for i := range e.addrs {
+		idx := (start + i) % len(e.addrs)
+		addr := e.addrs[idx]
+
+		conn, err := e.dial(fmt.Sprintf("amqp://%s:%s@%s/", user, pass, addr))
+		if err != nil {
+			// Nur host:port loggen → Passwort steht in der URL
+			log.Printf("RabbitMQ endpoint %s unavailable: %v", addr, err)
+			errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+			continue
+		}
+
+		e.mu.Lock()
+		e.next = (idx + 1) % len(e.addrs)
+		e.mu.Unlock()
+
+		if len(e.addrs) > 1 {
+			log.Printf("Connected to RabbitMQ endpoint %s", addr)
+		}
+		return conn, addr, nil
+	}
+
+	return nil, "", errors.Join(errs...)
+}
@@ -0,0 +1,100 @@
+package broker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// IdleMonitor: Warnt wenn ein Consumer zu lange keine Message bekommen hat
+// Warum?
+// → Binding gelöscht / Exchange umbenannt → Consumer läuft weiter, bekommt aber NIE wieder etwas
+// → Kein Fehler, kein Log → fällt erst auf wenn Kunden sich beschweren
+//
+// Nur für Queues die regelmäßig Traffic haben (z.B. order.paid während der Öffnungszeiten)
+// → nil Monitor = deaktiviert, Touch/Run sind dann No-Ops
+type IdleMonitor struct {
+	queue     string
+	threshold time.Duration
+	metrics   *metrics.ConsumerMetrics // darf nil sein
+
+	mu     sync.Mutex
+	last   time.Time
+	warned bool // Pro Idle-Phase nur EINE Warnung, Reset bei der nächsten Message
+
+	now func() time.Time // Injizierbar → Tests mit Fake Clock
+}
+
+// NewIdleMonitor: threshold <= 0 → nil (Monitoring aus)
+func NewIdleMonitor(queue string, threshold time.Duration, m *metrics.ConsumerMetrics) *IdleMonitor {
+	if threshold <= 0 {
+		return nil
+	}
+	return &IdleMonitor{
+		queue:     queue,
+		threshold: threshold,
+		metrics:   m,
+		last:      time.Now(), // Start zählt als "letzte Aktivität" → keine Warnung direkt nach dem Boot
+		now:       time.Now,
+	}
+}
+
+// Touch: Bei JEDER Delivery aufrufen
+func (m *IdleMonitor) Touch() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last = m.now()
+	m.warned = false
+}
+
+// Check: Aktualisiert die Idle Metric, warnt beim Überschreiten des Thresholds
+// Returns: true wenn in DIESEM Check gewarnt wurde
+func (m *IdleMonitor) Check() bool {
+	if m == nil {
+		return false
+	}
+
+	m.mu.Lock()
+	idle := m.now().Sub(m.last)
+	fire := idle >= m.threshold && !m.warned
+	if fire {
+		m.warned = true
+	}
+	m.mu.Unlock()
+
+	if m.metrics != nil {
+		m.metrics.RecordConsumerIdle(m.queue, idle)
+	}
+	if fire {
+		log.Printf("Consumer on %s idle for %s (threshold %s) - check bindings/publishers", m.queue, idle.Round(time.Second), m.threshold)
+		if m.metrics != nil {
+			m.metrics.RecordConsumerIdleWarning(m.queue)
+		}
+	}
+	return fire
+}
+
+// Run: Prüft alle interval bis ctx beendet ist
+func (m *IdleMonitor) Run(ctx context.Context, interval time.Duration) {
+	if m == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package broker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// newTestIdleMonitor: IdleMonitor mit Fake Clock → Idle-Zeit ohne Sleep vorspulen
+func newTestIdleMonitor(t *testing.T, threshold time.Duration) (*IdleMonitor, *time.Time, *metrics.ConsumerMetrics) {
+	t.Helper()
+
+	// promauto registriert global → eindeutiger Name pro Test
+	m := metrics.NewConsumerMetrics(fmt.Sprintf("idle_test_%d", time.Now().UnixNano()))
+	mon := NewIdleMonitor("order.paid", threshold, m)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	mon.now = func() time.Time { return now }
+	mon.last = now
+	return mon, &now, m
+}
+
+func TestIdleMonitorWarnsAfterThreshold(t *testing.T) {
+	mon, now, m := newTestIdleMonitor(t, 10*time.Minute)
+
+	*now = now.Add(9 * time.Minute)
+	if mon.Check() {
+		t.Fatal("warned before threshold")
+	}
+
+	*now = now.Add(time.Minute)
+	if !mon.Check() {
+		t.Fatal("no warning at threshold")
+	}
+	if got := testutil.ToFloat64(m.IdleWarnings.WithLabelValues("order.paid")); got != 1 {
+		t.Fatalf("idle warnings = %v; want 1", got)
+	}
+	if got := testutil.ToFloat64(m.IdleSeconds.WithLabelValues("order.paid")); got != 600 {
+		t.Fatalf("idle seconds = %v; want 600", got)
+	}
+
+	// Gleiche Idle-Phase → keine zweite Warnung
+	*now = now.Add(time.Hour)
+	if mon.Check() {
+		t.Fatal("warned twice in one idle phase")
+	}
+	if got := testutil.ToFloat64(m.IdleWarnings.WithLabelValues("order.paid")); got != 1 {
+		t.Fatalf("idle warnings = %v; want still 1", got)
+	}
+}
+
+func TestIdleMonitorTouchResets(t *testing.T) {
+	mon, now, _ := newTestIdleMonitor(t, 10*time.Minute)
+
+	*now = now.Add(15 * time.Minute)
+	if !mon.Check() {
+		t.Fatal("no warning after threshold")
+	}
+
+	// Neue Message → Idle-Zeit startet neu, nächste Idle-Phase warnt wieder
+	mon.Touch()
+	*now = now.Add(5 * time.Minute)
+	if mon.Check() {
+		t.Fatal("warned 5m after the last message")
+	}
+	*now = now.Add(5 * time.Minute)
+	if !mon.Check() {
+		t.Fatal("no warning in the next idle phase")
+	}
+}
+
+func TestIdleMonitorDisabled(t *testing.T) {
+	mon := NewIdleMonitor("order.paid", 0, nil)
+	if mon != nil {
+		t.Fatal("threshold 0 should disable the monitor")
+	}
+	// nil Monitor = No-Op
+	mon.Touch()
+	if mon.Check() {
+		t.Fatal("nil monitor warned")
+	}
+}
@@ -0,0 +1,66 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// maxPanicLogBody: So viel vom Body landet bei einem Panic im Log (Rest abgeschnitten)
+const maxPanicLogBody = 1024
+
+// Outcome: Wie die Verarbeitung EINER Delivery geendet hat
+// → Label "outcome" von message_processing_duration_seconds
+type Outcome string
+
+const (
+	OutcomeSuccess    Outcome = "success"     // Ack (auch: bewusst übersprungen, z.B. Session Order)
+	OutcomeRetry      Outcome = "retry"       // HandleRetry → Retry Queue, nach MaxRetries → DLQ
+	OutcomeDeadLetter Outcome = "dead_letter" // Direkt in die DLQ: zu groß, kaputtes JSON, permanenter Fehler
+	OutcomeDuplicate  Outcome = "duplicate"   // Dedup: schon verarbeitet → Ack ohne Handler
+)
+
+// Process: Consumer Wrapper um EINEN Handler Aufruf → misst Dauer + Outcome, fängt Panics ab
+// Warum Wrapper statt Timer in jedem Handler?
+// → Handler haben viele Exit-Pfade (Retry, DLQ, Duplikat, ...) → jeder gibt nur sein Outcome zurück
+// → Dauer wird an genau EINER Stelle gemessen, kein Pfad kann vergessen werden
+// m darf nil sein (Metrics aus)
+// queue: Logischer Queue Name (z.B. OrderPaidEvent) → Panic Message landet in "<queue>.dlq"
+func Process(m *metrics.ConsumerMetrics, ch Channel, d *amqp.Delivery, queue string, handle func() Outcome) Outcome {
+	start := time.Now()
+	outcome := recoverHandler(ch, d, queue, handle)
+	m.ObserveMessageProcessing(queue, string(outcome), time.Since(start))
+	return outcome
+}
+
+// recoverHandler: Panic im Handler → Log (mit Body + Stack) → DLQ → OutcomeDeadLetter
+// Warum?
+// → Panic (z.B. nil Pointer bei einer kaputten Order) beendete die Consumer Goroutine
+// → Service lief weiter (<-forever), verarbeitete aber still KEINE Messages mehr
+// → Retry bringt nichts: Dieselbe Message panict wieder → direkt in die DLQ, nächste Message
+func recoverHandler(ch Channel, d *amqp.Delivery, queue string, handle func() Outcome) (outcome Outcome) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		body := d.Body
+		if len(body) > maxPanicLogBody {
+			body = body[:maxPanicLogBody]
+		}
+		log.Printf("Recovered panic while handling %s message %q: %v\nbody: %s\n%s", queue, d.MessageId, r, body, debug.Stack())
+
+		if err := DeadLetter(context.Background(), ch, d, queue, fmt.Sprintf("handler panic: %v", r)); err != nil {
+			log.Printf("Failed to dead-letter panicking message on %s: %v", queue, err)
+		}
+		outcome = OutcomeDeadLetter
+	}()
+
+	return handle()
+}
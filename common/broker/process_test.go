@@ -0,0 +1,94 @@
+package broker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// processed: Anzahl Beobachtungen von message_processing_duration_seconds{queue,outcome}
+func processed(t *testing.T, m *metrics.ConsumerMetrics, queue string, outcome broker.Outcome) uint64 {
+	t.Helper()
+	var out dto.Metric
+	if err := m.ProcessingDuration.WithLabelValues(queue, string(outcome)).(prometheus.Metric).Write(&out); err != nil {
+		t.Fatalf("write histogram: %v", err)
+	}
+	return out.GetHistogram().GetSampleCount()
+}
+
+// Erfolg und Fehler landen mit ihrem Outcome Label im Histogramm, inkl. der Handler Dauer
+func TestProcessObservesOutcome(t *testing.T) {
+	m := metrics.NewConsumerMetrics("process_outcome_test")
+	b := brokertest.New()
+
+	d := deliver(t, b, broker.OrderPaidEvent, []byte(`{}`))
+	broker.Process(m, b, &d, broker.OrderPaidEvent, func() broker.Outcome {
+		time.Sleep(10 * time.Millisecond)
+		return broker.OutcomeSuccess
+	})
+	d = deliver(t, b, broker.OrderPaidEvent, []byte(`{}`))
+	broker.Process(m, b, &d, broker.OrderPaidEvent, func() broker.Outcome {
+		_ = broker.HandleRetry(b, &d, broker.OrderPaidEvent, errors.New("stripe down"))
+		return broker.OutcomeRetry
+	})
+
+	if n := processed(t, m, broker.OrderPaidEvent, broker.OutcomeSuccess); n != 1 {
+		t.Errorf("success observations = %d; want 1", n)
+	}
+	if n := processed(t, m, broker.OrderPaidEvent, broker.OutcomeRetry); n != 1 {
+		t.Errorf("retry observations = %d; want 1", n)
+	}
+	if n := processed(t, m, broker.OrderPaidEvent, broker.OutcomeDeadLetter); n != 0 {
+		t.Errorf("dead_letter observations = %d; want 0", n)
+	}
+
+	var out dto.Metric
+	m.ProcessingDuration.WithLabelValues(broker.OrderPaidEvent, string(broker.OutcomeSuccess)).(prometheus.Metric).Write(&out)
+	if sum := out.GetHistogram().GetSampleSum(); sum < 0.01 {
+		t.Errorf("success duration = %vs; want at least the 10ms the handler took", sum)
+	}
+}
+
+// Panic im Handler → DLQ + Outcome dead_letter, Consumer läuft weiter
+func TestProcessObservesPanicAsDeadLetter(t *testing.T) {
+	m := metrics.NewConsumerMetrics("process_panic_test")
+	b := brokertest.New()
+
+	d := deliver(t, b, broker.OrderPaidEvent, []byte(`{}`))
+	outcome := broker.Process(m, b, &d, broker.OrderPaidEvent, func() broker.Outcome {
+		panic("nil order")
+	})
+	if outcome != broker.OutcomeDeadLetter {
+		t.Fatalf("outcome = %q; want dead_letter", outcome)
+	}
+	if n := processed(t, m, broker.OrderPaidEvent, broker.OutcomeDeadLetter); n != 1 {
+		t.Errorf("dead_letter observations = %d; want 1", n)
+	}
+
+	var deadLettered bool
+	for _, msg := range b.Published() {
+		if msg.Exchange == broker.DLX && msg.RoutingKey == broker.OrderPaidEvent {
+			deadLettered = true
+		}
+	}
+	if !deadLettered {
+		t.Error("panicking message was not dead-lettered")
+	}
+}
+
+// nil Metrics (Tests, Service ohne Metrics) → Handler läuft trotzdem, Outcome wird durchgereicht
+func TestProcessNilMetrics(t *testing.T) {
+	b := brokertest.New()
+	d := deliver(t, b, broker.OrderPaidEvent, []byte(`{}`))
+
+	if outcome := broker.Process(nil, b, &d, broker.OrderPaidEvent, func() broker.Outcome { return broker.OutcomeDuplicate }); outcome != broker.OutcomeDuplicate {
+		t.Fatalf("outcome = %q; want duplicate", outcome)
+	}
+}
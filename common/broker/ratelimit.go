@@ -0,0 +1,76 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Rate Limit Retries (z.B. Stripe 429)
+// Warum getrennt von MaxRetryCount?
+// → 429 heißt "zu viele Requests", nicht "diese Message ist kaputt"
+// → 3 schnelle Retries (1s, 2s, 4s) feuern genau in das Limit hinein → landen in der DLQ, obwohl die Message OK ist
+// → Eigener Zähler: Rate Limits verbrauchen NICHT das Retry Budget für echte Fehler
+const (
+	MaxRateLimitRetries = 8
+	RateLimitHeader     = "x-rate-limit-count"
+
+	rateLimitBaseDelay = 2 * time.Second
+	rateLimitMaxDelay  = time.Minute
+)
+
+// RateLimitBackoff: Exponentiell (2s, 4s, 8s, ... max 1min) mit Jitter, mindestens retryAfter
+// Warum Jitter?
+// → Alle Instanzen bekommen das 429 gleichzeitig → ohne Jitter kommen sie auch gleichzeitig wieder
+// → Wartezeit zufällig zwischen 50% und 100% des Backoffs → Retries verteilen sich
+func RateLimitBackoff(attempt int64, retryAfter time.Duration) time.Duration {
+	backoff := rateLimitMaxDelay
+	if attempt >= 1 && attempt <= 6 { // 2s << 5 = 64s > max → ab da gedeckelt
+		backoff = min(rateLimitBaseDelay<<(attempt-1), rateLimitMaxDelay)
+	}
+	delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	return max(delay, retryAfter) // Retry-After vom Server hat Vorrang, wenn länger
+}
+
+// RateLimitQueueName: TTL Queue für Rate Limit Retry Nummer attempt → "order.created.ratelimit.3"
+// → Eigene Queues statt "<queue>.retry.<n>": Rate Limit Delays (bis 1min) sollen keine kurzen Retries blockieren
+func RateLimitQueueName(queue string, attempt int64) string {
+	return fmt.Sprintf("%s.ratelimit.%d", queue, attempt)
+}
+
+// HandleRateLimit: Delivery nach Rate Limit Backoff erneut zustellen (ohne x-retry-count anzufassen)
+// Returns: false wenn MaxRateLimitRetries erreicht → Caller fällt auf HandleRetry zurück (normales Budget → DLQ)
+// Warum TTL Queue statt time.Sleep?
+// → Sleep (bis zu 1min) blockierte die Consumer Goroutine und damit alle anderen Messages des Channels
+// → Gleicher Mechanismus wie HandleRetry (publishDelayed): RabbitMQ hält die Message zurück, zurück NUR in queue
+// → Drosselung Richtung Stripe übernimmt der geteilte Limiter, nicht der blockierte Consumer
+// Ack/Nack passiert hier drin (Original wird nach dem Publish geackt)
+func HandleRateLimit(ch Channel, d *amqp.Delivery, queue string, retryAfter time.Duration) (bool, error) {
+	if d.Headers == nil {
+		d.Headers = amqp.Table{}
+	}
+
+	count, _ := d.Headers[RateLimitHeader].(int64)
+	if count >= MaxRateLimitRetries {
+		log.Printf("Rate limit retries exhausted (%d) for %s, falling back to regular retry", count, queue)
+		return false, nil
+	}
+	count++
+	d.Headers[RateLimitHeader] = count
+
+	delay := RateLimitBackoff(count, retryAfter)
+	log.Printf("Rate limited, retrying %s in %s (rate limit retry %d/%d)", queue, delay, count, MaxRateLimitRetries)
+
+	// x-retry-count unverändert, nur x-rate-limit-count hochgezählt
+	err := publishDelayed(context.Background(), ch, d, queue, RateLimitQueueName(queue, count), delay)
+	if err != nil {
+		// Republish fehlgeschlagen → Original zurück in die Queue statt es zu verlieren
+		d.Nack(false, true)
+		return true, err
+	}
+	return true, d.Ack(false)
+}
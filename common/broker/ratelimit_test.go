@@ -0,0 +1,77 @@
+package broker_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+func TestRateLimitBackoff(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := broker.RateLimitBackoff(1, 0); d < time.Second || d > 2*time.Second {
+			t.Fatalf("RateLimitBackoff(1, 0) = %s; want within [1s, 2s]", d)
+		}
+		if d := broker.RateLimitBackoff(20, 0); d < 30*time.Second || d > time.Minute {
+			t.Fatalf("RateLimitBackoff(20, 0) = %s; want within [30s, 1m]", d)
+		}
+	}
+	if d := broker.RateLimitBackoff(1, 90*time.Second); d != 90*time.Second {
+		t.Fatalf("RateLimitBackoff with Retry-After 90s = %s; want 90s", d)
+	}
+}
+
+func TestHandleRateLimitUsesTTLQueue(t *testing.T) {
+	b := brokertest.New()
+	d := newDelivery(t, b, broker.OrderCreatedEvent, "payments.order.created", amqp.Table{"x-retry-count": int64(1)})
+
+	start := time.Now()
+	handled, err := broker.HandleRateLimit(b, &d, "payments.order.created", 30*time.Second)
+	if err != nil || !handled {
+		t.Fatalf("HandleRateLimit = %v, %v; want true, nil", handled, err)
+	}
+	// Kein Sleep im Consumer → kehrt sofort zurück, obwohl Retry-After 30s ist
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("HandleRateLimit blocked for %s", elapsed)
+	}
+
+	queue := broker.RateLimitQueueName("payments.order.created", 1)
+	args := b.QueueArgs(queue)
+	if args["x-dead-letter-exchange"] != "" || args["x-dead-letter-routing-key"] != "payments.order.created" {
+		t.Fatalf("rate limit queue args = %v", args)
+	}
+
+	published := b.Published()
+	last := published[len(published)-1]
+	if last.Exchange != "" || last.RoutingKey != queue {
+		t.Fatalf("published to %q/%q; want default exchange/%s", last.Exchange, last.RoutingKey, queue)
+	}
+	if ttl, _ := strconv.ParseInt(last.Publishing.Expiration, 10, 64); time.Duration(ttl)*time.Millisecond != 30*time.Second {
+		t.Fatalf("Expiration = %q; want Retry-After (30000)", last.Publishing.Expiration)
+	}
+
+	h := last.Publishing.Headers
+	if h[broker.RateLimitHeader] != int64(1) || h["x-retry-count"] != int64(1) {
+		t.Fatalf("headers = %v; want rate limit count 1, retry count untouched", h)
+	}
+	if len(b.Acked()) != 1 {
+		t.Fatalf("original delivery not acked")
+	}
+}
+
+func TestHandleRateLimitExhausted(t *testing.T) {
+	b := brokertest.New()
+	d := newDelivery(t, b, "", broker.OrderCreatedEvent, amqp.Table{broker.RateLimitHeader: int64(broker.MaxRateLimitRetries)})
+	before := len(b.Published())
+
+	handled, err := broker.HandleRateLimit(b, &d, broker.OrderCreatedEvent, time.Second)
+	if err != nil || handled {
+		t.Fatalf("HandleRateLimit = %v, %v; want false, nil", handled, err)
+	}
+	if len(b.Published()) != before || len(b.Acked()) != 0 {
+		t.Fatalf("exhausted delivery must be left to HandleRetry")
+	}
+}
@@ -0,0 +1,182 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrDLQMessageNotFound: Keine Message in der DLQ gehört zur gesuchten Order
+var ErrDLQMessageNotFound = errors.New("no dead-lettered message found for order")
+
+// DLQChannel: Channel + basic.get (Messages einzeln aus einer Queue holen, ohne Consumer)
+// → *amqp.Channel und brokertest.Broker erfüllen das Interface
+type DLQChannel interface {
+	Channel
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+}
+
+var _ DLQChannel = (*amqp.Channel)(nil)
+
+// ReplayDLQMessage: Verarbeitet EINE hängende Order erneut, statt die ganze DLQ zu replayen
+// Flow:
+// 1. basic.get bis die DLQ leer ist (ohne Ack → Messages bleiben reserviert, kein Doppel-Get)
+// 2. Message mit passender Order ID → an die Original Queue ("order.paid.dlq" → "order.paid")
+// 3. Treffer aus der DLQ acken, alle anderen zurück in die DLQ (Nack mit requeue)
+//
+// Warum Body parsen statt strings.Contains?
+// → Order ID "ord_1" würde sonst auch "ord_12" treffen
+func ReplayDLQMessage(ctx context.Context, ch DLQChannel, dlqName, orderID string) error {
+	queue, ok := strings.CutSuffix(dlqName, ".dlq")
+	if !ok {
+		return fmt.Errorf("%s is not a dead letter queue (expected .dlq suffix)", dlqName)
+	}
+
+	var skipped []amqp.Delivery
+	// Nicht passende Messages IMMER zurückgeben → auch bei Fehlern geht nichts verloren
+	defer func() {
+		for _, d := range skipped {
+			if err := d.Nack(false, true); err != nil {
+				log.Printf("Failed to requeue message to %s: %v", dlqName, err)
+			}
+		}
+	}()
+
+	for {
+		d, ok, err := ch.Get(dlqName, false)
+		if err != nil {
+			return fmt.Errorf("failed to get message from %s: %w", dlqName, err)
+		}
+		if !ok {
+			return fmt.Errorf("%w %s in %s", ErrDLQMessageNotFound, orderID, dlqName)
+		}
+
+		if !messageForOrder(d.Body, orderID) {
+			skipped = append(skipped, d)
+			continue
+		}
+
+		if err := republish(ctx, ch, queue, d); err != nil {
+			skipped = append(skipped, d) // Zurück in die DLQ, nächster Versuch möglich
+			return fmt.Errorf("failed to replay order %s to %s: %w", orderID, queue, err)
+		}
+
+		log.Printf("Replayed order %s from %s to %s", orderID, dlqName, queue)
+		return d.Ack(false)
+	}
+}
+
+// messageForOrder: Unsere Events sind entweder pb.Order ("id") oder Events mit "order_id"
+func messageForOrder(body []byte, orderID string) bool {
+	return orderID != "" && messageOrderID(body) == orderID
+}
+
+// messageOrderID: Order ID aus dem Body ("" = kein JSON / keine Order)
+func messageOrderID(body []byte) string {
+	var msg struct {
+		ID      string `json:"id"`
+		OrderID string `json:"order_id"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return ""
+	}
+	if msg.OrderID != "" {
+		return msg.OrderID
+	}
+	return msg.ID
+}
+
+// DLQMessage: Eine dead-lettered Message zur Ansicht (PeekDLQ)
+// → OrderID ist der Schlüssel für ReplayDLQMessage
+type DLQMessage struct {
+	OrderID       string `json:"order_id,omitempty"`
+	MessageID     string `json:"message_id,omitempty"`
+	ContentType   string `json:"content_type,omitempty"`
+	RetryCount    int64  `json:"retry_count"`
+	Reason        string `json:"reason,omitempty"`          // HeaderDeadLetterReason
+	DeathReason   string `json:"death_reason,omitempty"`    // HeaderDeathReason (letzter Fehler)
+	FirstFailedAt string `json:"first_failed_at,omitempty"` // HeaderFirstFailedAt
+	Body          string `json:"body"`
+}
+
+// PeekDLQ: Die ersten limit Messages einer DLQ ansehen, OHNE sie zu entfernen
+// Warum basic.get + Nack(requeue) statt Consumer?
+// → Kein Ack → jede Message geht nach dem Peek zurück in die DLQ
+// → Gets ohne Ack bleiben bis zum Nack reserviert → dieselbe Message kommt nicht doppelt
+func PeekDLQ(ch DLQChannel, dlqName string, limit int) ([]DLQMessage, error) {
+	if !strings.HasSuffix(dlqName, ".dlq") {
+		return nil, fmt.Errorf("%s is not a dead letter queue (expected .dlq suffix)", dlqName)
+	}
+
+	var fetched []amqp.Delivery
+	defer func() {
+		for _, d := range fetched {
+			if err := d.Nack(false, true); err != nil {
+				log.Printf("Failed to requeue message to %s: %v", dlqName, err)
+			}
+		}
+	}()
+
+	messages := []DLQMessage{}
+	for len(messages) < limit {
+		d, ok, err := ch.Get(dlqName, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get message from %s: %w", dlqName, err)
+		}
+		if !ok {
+			break
+		}
+		fetched = append(fetched, d)
+		messages = append(messages, newDLQMessage(d))
+	}
+
+	return messages, nil
+}
+
+// newDLQMessage: Delivery → DLQMessage (Header sind optional, alte Messages haben sie nicht)
+func newDLQMessage(d amqp.Delivery) DLQMessage {
+	msg := DLQMessage{
+		OrderID:     messageOrderID(d.Body),
+		MessageID:   d.MessageId,
+		ContentType: d.ContentType,
+		Body:        string(d.Body),
+	}
+	msg.RetryCount, _ = d.Headers["x-retry-count"].(int64)
+	msg.Reason, _ = d.Headers[HeaderDeadLetterReason].(string)
+	msg.DeathReason, _ = d.Headers[HeaderDeathReason].(string)
+	msg.FirstFailedAt, _ = d.Headers[HeaderFirstFailedAt].(string)
+	return msg
+}
+
+// republish: Über den Default Exchange direkt in die Original Queue
+// Warum nicht über den Event Exchange?
+// → Fanout würde die Message an ALLE Subscriber schicken, nicht nur an den der gescheitert ist
+func republish(ctx context.Context, ch Channel, queue string, d amqp.Delivery) error {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		switch k {
+		case "x-retry-count", "x-death", HeaderDeadLetterReason, HeaderDeathReason, HeaderFirstFailedAt:
+			// Frischer Start → HandleRetry zählt wieder von 0
+		default:
+			headers[k] = v
+		}
+	}
+
+	return ch.PublishWithContext(ctx,
+		"",    // default exchange
+		queue, // routing key = Queue Name
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  d.ContentType,
+			Headers:      headers,
+			Body:         d.Body,
+			DeliveryMode: amqp.Persistent,
+		},
+	)
+}
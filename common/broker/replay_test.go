@@ -0,0 +1,109 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+const testDLQ = broker.OrderPaidEvent + ".dlq"
+
+// seedDLQ: Dead-lettered Messages in "order.paid.dlq", Original Queue "order.paid" existiert
+func seedDLQ(t *testing.T, bodies ...string) *brokertest.Broker {
+	t.Helper()
+
+	b := brokertest.New()
+	for _, q := range []string{broker.OrderPaidEvent, testDLQ} {
+		if _, err := b.QueueDeclare(q, true, false, false, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, body := range bodies {
+		err := b.PublishWithContext(context.Background(), "", testDLQ, false, false, amqp.Publishing{
+			Headers: amqp.Table{"x-retry-count": int64(3), broker.HeaderDeathReason: "stripe down"},
+			Body:    []byte(body),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b
+}
+
+// drain: Alle Messages einer Queue holen (Auto-Ack), Bodies sortiert
+func drain(t *testing.T, b *brokertest.Broker, queue string) ([]string, []amqp.Delivery) {
+	t.Helper()
+
+	var bodies []string
+	var deliveries []amqp.Delivery
+	for {
+		d, ok, err := b.Get(queue, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		bodies = append(bodies, string(d.Body))
+		deliveries = append(deliveries, d)
+	}
+	slices.Sort(bodies)
+	return bodies, deliveries
+}
+
+func TestReplayDLQMessageReplaysOnlyTargetedOrder(t *testing.T) {
+	b := seedDLQ(t,
+		`{"id":"o1"}`,
+		`{"order_id":"o12"}`, // Präfix-Treffer für "o1" → darf NICHT replayed werden
+		`{"id":"o2"}`,
+	)
+
+	if err := broker.ReplayDLQMessage(context.Background(), b, testDLQ, "o1"); err != nil {
+		t.Fatalf("ReplayDLQMessage: %v", err)
+	}
+
+	replayed, deliveries := drain(t, b, broker.OrderPaidEvent)
+	if !slices.Equal(replayed, []string{`{"id":"o1"}`}) {
+		t.Fatalf("replayed = %v; want only o1", replayed)
+	}
+	// Frischer Start → Retry Zähler und Fehlergrund sind weg
+	if _, ok := deliveries[0].Headers["x-retry-count"]; ok {
+		t.Error("replayed message still carries x-retry-count")
+	}
+	if _, ok := deliveries[0].Headers[broker.HeaderDeathReason]; ok {
+		t.Errorf("replayed message still carries %s", broker.HeaderDeathReason)
+	}
+
+	remaining, _ := drain(t, b, testDLQ)
+	if !slices.Equal(remaining, []string{`{"id":"o2"}`, `{"order_id":"o12"}`}) {
+		t.Fatalf("DLQ after replay = %v; want o2 and o12 untouched", remaining)
+	}
+}
+
+func TestReplayDLQMessageNotFoundKeepsDLQ(t *testing.T) {
+	b := seedDLQ(t, `{"id":"o1"}`, `{"id":"o2"}`)
+
+	err := broker.ReplayDLQMessage(context.Background(), b, testDLQ, "o9")
+	if !errors.Is(err, broker.ErrDLQMessageNotFound) {
+		t.Fatalf("ReplayDLQMessage = %v; want ErrDLQMessageNotFound", err)
+	}
+
+	if replayed, _ := drain(t, b, broker.OrderPaidEvent); len(replayed) != 0 {
+		t.Fatalf("replayed = %v; want nothing", replayed)
+	}
+	if remaining, _ := drain(t, b, testDLQ); len(remaining) != 2 {
+		t.Fatalf("DLQ after failed replay = %v; want both messages back", remaining)
+	}
+}
+
+func TestReplayDLQMessageRejectsNonDLQ(t *testing.T) {
+	b := seedDLQ(t)
+	if err := broker.ReplayDLQMessage(context.Background(), b, broker.OrderPaidEvent, "o1"); err == nil {
+		t.Fatal("ReplayDLQMessage accepted a queue without .dlq suffix")
+	}
+}
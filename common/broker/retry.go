@@ -0,0 +1,85 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Retry Backoff (HandleRetry)
+// → Delay = min(RetryBaseDelay * RetryBackoffFactor^(n-1), RetryMaxDelay) ± RetryJitter
+// → 1s, 2s, 4s, ... max 30s (jeweils ±20%)
+const (
+	RetryBaseDelay     = time.Second
+	RetryMaxDelay      = 30 * time.Second
+	RetryBackoffFactor = 2
+	RetryJitter        = 0.2 // ±20%
+)
+
+// RetryDelay: Wartezeit vor Retry Nummer retryCount (1-basiert)
+// Warum Jitter?
+// → Fällt ein Downstream Service aus, scheitern viele Messages gleichzeitig → ohne Jitter kommen alle gleichzeitig wieder
+func RetryDelay(retryCount int64) time.Duration {
+	backoff := float64(RetryBaseDelay)
+	for i := int64(1); i < retryCount && backoff < float64(RetryMaxDelay); i++ {
+		backoff *= RetryBackoffFactor
+	}
+	backoff = min(backoff, float64(RetryMaxDelay))
+
+	jitter := backoff * RetryJitter * (2*rand.Float64() - 1)
+	return time.Duration(backoff + jitter)
+}
+
+// RetryQueueName: Warteschlange für Retry Nummer retryCount der Consumer Queue queue
+// → "order.created.retry.2", "order.paid.retry.2"
+// Warum eine Queue pro Retry Nummer?
+// → RabbitMQ lässt Messages nur am Kopf der Queue ablaufen → 30s Message vor einer 1s Message würde diese blockieren
+// → Gleiche Retry Nummer = ähnlicher Delay (nur Jitter) → kaum Head-of-Line Blocking
+func RetryQueueName(queue string, retryCount int64) string {
+	return fmt.Sprintf("%s.retry.%d", queue, retryCount)
+}
+
+// publishRetry: Delivery über eine TTL Retry Queue verzögert zurückschicken
+// Flow:
+// 1. Publish in "<queue>.retry.<n>" mit Expiration = delay (niemand konsumiert diese Queue)
+// 2. TTL abgelaufen → RabbitMQ dead-lettert über den Default Exchange direkt in queue
+// 3. NUR dieser Consumer bekommt die Message mit den aktualisierten Headern wieder
+// Warum nicht zurück an den Original Exchange?
+// → order.paid & Co. verteilen an ALLE gebundenen Queues → Consumer die schon fertig sind bekämen ein Duplikat
+// Warum nicht time.Sleep?
+// → Sleep blockiert die Consumer Goroutine → eine kaputte Message hält alle anderen auf
+func publishRetry(ctx context.Context, ch Channel, d *amqp.Delivery, queue string, retryCount int64, delay time.Duration) error {
+	return publishDelayed(ctx, ch, d, queue, RetryQueueName(queue, retryCount), delay)
+}
+
+// publishDelayed: Delivery über die TTL Queue retryQueue verzögert zurück in queue schicken
+// → Headers werden unverändert übernommen (Caller zählt seine Header vorher hoch)
+func publishDelayed(ctx context.Context, ch Channel, d *amqp.Delivery, queue, retryQueue string, delay time.Duration) error {
+	// Idempotent: Argumente hängen nur an queue → gleicher Name = gleiche Argumente
+	_, err := ch.QueueDeclare(retryQueue, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",    // Default Exchange → routed direkt zur Queue mit Namen = Routing Key
+		"x-dead-letter-routing-key": queue, // Sonst würde mit dem Retry Queue Namen zurückgeroutet
+	})
+	if err != nil {
+		return fmt.Errorf("failed to declare retry queue %s: %w", retryQueue, err)
+	}
+
+	return ch.PublishWithContext(ctx,
+		"",         // default exchange
+		retryQueue, // routing key = Retry Queue
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Headers:      d.Headers,   // Updated retry count!
+			MessageId:    d.MessageId, // MessageId behalten → Dedup greift auch für die Retry Kopie
+			Body:         d.Body,
+			DeliveryMode: amqp.Persistent,
+			Expiration:   strconv.FormatInt(max(delay.Milliseconds(), 0), 10), // Per-Message TTL in ms
+		},
+	)
+}
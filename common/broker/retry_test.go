@@ -0,0 +1,177 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		retry int64
+		base  time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 16 * time.Second},
+		{6, broker.RetryMaxDelay}, // 32s → gedeckelt
+		{50, broker.RetryMaxDelay},
+	}
+
+	for _, tt := range tests {
+		lo := time.Duration(float64(tt.base) * (1 - broker.RetryJitter))
+		hi := time.Duration(float64(tt.base) * (1 + broker.RetryJitter))
+		for i := 0; i < 100; i++ {
+			if d := broker.RetryDelay(tt.retry); d < lo || d > hi {
+				t.Fatalf("RetryDelay(%d) = %s; want within [%s, %s]", tt.retry, d, lo, hi)
+			}
+		}
+	}
+}
+
+// newDelivery: Delivery wie sie ein Consumer der Queue "queue" von exchange bekommt
+func newDelivery(t *testing.T, b *brokertest.Broker, exchange, queue string, headers amqp.Table) amqp.Delivery {
+	t.Helper()
+
+	if _, err := b.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if exchange != "" {
+		if err := b.QueueBind(queue, "", exchange, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs, err := b.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := ""
+	if exchange == "" {
+		key = queue
+	}
+	if err := b.PublishWithContext(context.Background(), exchange, key, false, false, amqp.Publishing{Headers: headers, MessageId: "msg-1", Body: []byte(`{"id":"o1"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	return <-msgs
+}
+
+func TestHandleRetryRoutesOnlyToFailingConsumer(t *testing.T) {
+	b := brokertest.New()
+	d := newDelivery(t, b, broker.OrderPaidEvent, "kitchen.order.paid", nil)
+
+	if err := broker.HandleRetry(b, &d, "kitchen.order.paid", errors.New("orders unavailable")); err != nil {
+		t.Fatalf("HandleRetry: %v", err)
+	}
+
+	retryQueue := broker.RetryQueueName("kitchen.order.paid", 1)
+	if retryQueue != "kitchen.order.paid.retry.1" {
+		t.Fatalf("RetryQueueName = %q", retryQueue)
+	}
+
+	// Retry Queue dead-lettert über den Default Exchange zurück in DIE Consumer Queue (nicht an den Fanout Exchange)
+	args := b.QueueArgs(retryQueue)
+	if args["x-dead-letter-exchange"] != "" || args["x-dead-letter-routing-key"] != "kitchen.order.paid" {
+		t.Fatalf("retry queue args = %v", args)
+	}
+
+	published := b.Published()
+	last := published[len(published)-1]
+	if last.Exchange != "" || last.RoutingKey != retryQueue {
+		t.Fatalf("retry published to %q/%q; want default exchange/%s", last.Exchange, last.RoutingKey, retryQueue)
+	}
+
+	ttl, err := strconv.ParseInt(last.Publishing.Expiration, 10, 64)
+	if err != nil {
+		t.Fatalf("Expiration %q: %v", last.Publishing.Expiration, err)
+	}
+	if d := time.Duration(ttl) * time.Millisecond; d < 800*time.Millisecond || d > 1200*time.Millisecond {
+		t.Fatalf("first retry TTL = %s; want ~1s", d)
+	}
+
+	h := last.Publishing.Headers
+	if h["x-retry-count"] != int64(1) || h[broker.HeaderDeathReason] != "orders unavailable" || h[broker.HeaderFirstFailedAt] == nil {
+		t.Fatalf("retry headers = %v", h)
+	}
+	if last.Publishing.MessageId != "msg-1" {
+		t.Fatalf("MessageId = %q; want it preserved for dedup", last.Publishing.MessageId)
+	}
+}
+
+// Nicht-finaler Retry: Original geackt (nicht genackt) → die Queue DLX legt keine zweite Kopie in "<queue>.dlq"
+func TestHandleRetrySettlesWithoutDeadLettering(t *testing.T) {
+	b := brokertest.New()
+	queue := broker.OrderCreatedEvent
+	if _, err := b.QueueDeclare(queue, true, false, false, false, amqp.Table{"x-dead-letter-exchange": broker.DLX}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.QueueDeclare(queue+".dlq", true, false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.QueueBind(queue+".dlq", queue, broker.DLX, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	d := newDelivery(t, b, "", queue, nil)
+
+	if err := broker.HandleRetry(b, &d, queue, errors.New("stripe down")); err != nil {
+		t.Fatalf("HandleRetry: %v", err)
+	}
+
+	if acked := b.Acked(); len(acked) != 1 || acked[0] != d.DeliveryTag {
+		t.Fatalf("acked = %v; want [%d]", acked, d.DeliveryTag)
+	}
+	if nacked := b.Nacked(); len(nacked) != 0 {
+		t.Fatalf("nacked = %v; want none", nacked)
+	}
+	if _, ok, _ := b.Get(queue+".dlq", true); ok {
+		t.Fatal("non-final retry left a copy in the DLQ")
+	}
+}
+
+func TestHandleRetryKeepsFirstFailedAt(t *testing.T) {
+	b := brokertest.New()
+	first := "2026-01-01T00:00:00Z"
+	d := newDelivery(t, b, "", broker.OrderCreatedEvent, amqp.Table{
+		"x-retry-count":            int64(1),
+		broker.HeaderFirstFailedAt: first,
+	})
+
+	if err := broker.HandleRetry(b, &d, broker.OrderCreatedEvent, nil); err != nil {
+		t.Fatalf("HandleRetry: %v", err)
+	}
+
+	published := b.Published()
+	h := published[len(published)-1].Publishing.Headers
+	if h["x-retry-count"] != int64(2) || h[broker.HeaderFirstFailedAt] != first || h[broker.HeaderDeathReason] != "unknown" {
+		t.Fatalf("retry headers = %v", h)
+	}
+}
+
+func TestHandleRetryDeadLettersToConsumerDLQ(t *testing.T) {
+	b := brokertest.New()
+	d := newDelivery(t, b, broker.OrderPaidEvent, broker.OrderPaidEvent, amqp.Table{"x-retry-count": int64(broker.MaxRetryCount - 1)})
+
+	if err := broker.HandleRetry(b, &d, broker.OrderPaidEvent, errors.New("boom")); err != nil {
+		t.Fatalf("HandleRetry: %v", err)
+	}
+
+	published := b.Published()
+	last := published[len(published)-1]
+	// Exchange-Publish hat Routing Key "" → DLQ Routing muss über den Queue Namen laufen
+	if last.Exchange != broker.DLX || last.RoutingKey != broker.OrderPaidEvent {
+		t.Fatalf("dead-lettered to %q/%q; want %s/%s", last.Exchange, last.RoutingKey, broker.DLX, broker.OrderPaidEvent)
+	}
+	if last.Publishing.Headers[broker.HeaderDeadLetterReason] != "boom" {
+		t.Fatalf("dead letter reason = %v", last.Publishing.Headers[broker.HeaderDeadLetterReason])
+	}
+	if len(b.Acked()) != 1 {
+		t.Fatalf("original delivery not acked after dead-lettering")
+	}
+}
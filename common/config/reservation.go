@@ -0,0 +1,29 @@
+package config
+
+import "fmt"
+
+// ReservationMode: WANN Stock für eine Order reserviert wird
+type ReservationMode string
+
+const (
+	// ReservationOnCreate: Orders reserviert direkt bei CreateOrder (Default)
+	// → Kunde hat Stock sicher sobald die Order existiert
+	ReservationOnCreate ReservationMode = "on_create"
+
+	// ReservationOnPay: Payments reserviert erst direkt vor der Checkout Session
+	// → Browser die nie zahlen blockieren keinen Stock, dafür kann er bis zum Checkout weg sein
+	ReservationOnPay ReservationMode = "on_pay"
+)
+
+// ParseReservationMode: "" → ReservationOnCreate
+// ⚠️ Orders UND Payments müssen denselben Mode haben (RESERVATION_MODE) → sonst reserviert keiner oder beide
+func ParseReservationMode(s string) (ReservationMode, error) {
+	switch ReservationMode(s) {
+	case "", ReservationOnCreate:
+		return ReservationOnCreate, nil
+	case ReservationOnPay:
+		return ReservationOnPay, nil
+	default:
+		return "", fmt.Errorf("unknown reservation mode %q (want %q or %q)", s, ReservationOnCreate, ReservationOnPay)
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import "testing"
+
+func TestParseReservationMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ReservationMode
+		wantErr bool
+	}{
+		{"", ReservationOnCreate, false},
+		{"on_create", ReservationOnCreate, false},
+		{"on_pay", ReservationOnPay, false},
+		{"on_checkout", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReservationMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseReservationMode(%q) = %q, %v; want %q (err: %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
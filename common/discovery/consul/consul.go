@@ -7,12 +7,30 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 )
 
+// discoverCacheTTL: Wie lange die letzten erfolgreichen Discover Ergebnisse als Fallback gelten
+// Warum?
+// → Consul Agent kurz weg (Restart, Netzwerk-Blip) → JEDER CreateOrder würde fehlschlagen
+// → Obwohl Stock/Orders selbst gesund sind!
+// → Kurzer TTL: Nach 30s ohne Consul wissen wir nicht mehr welche Instanzen leben
+const discoverCacheTTL = 30 * time.Second
+
+type cachedInstances struct {
+	addrs     []string
+	fetchedAt time.Time
+}
+
 type Registry struct {
 	client *consul.Client
+
+	mu        sync.Mutex
+	lastKnown map[string]cachedInstances // serviceName → letzte gesunde Instanzen
+	now       func() time.Time           // Injizierbar → Tests ohne Sleep
 }
 
 func NewRegistry(addr, serviceName string) (*Registry, error) {
@@ -24,7 +42,11 @@ func NewRegistry(addr, serviceName string) (*Registry, error) {
 		return nil, err
 	}
 
-	return &Registry{client}, nil
+	return &Registry{
+		client:    client,
+		lastKnown: make(map[string]cachedInstances),
+		now:       time.Now,
+	}, nil
 }
 
 func (r *Registry) Register(ctx context.Context, instanceID, serviceName, hostPort string) error {
@@ -62,7 +84,23 @@ func (r *Registry) HealthCheck(instanceID string, serviceName string) error {
 }
 
 func (r *Registry) Discover(ctx context.Context, serviceName string) ([]string, error) {
-	entries, _, err := r.client.Health().Service(serviceName, "", true, nil)
+	instances, err := r.ServiceAddresses(ctx, serviceName)
+	if err != nil {
+		if cached, ok := r.cachedDiscover(serviceName); ok {
+			log.Printf("Consul unreachable, using cached instances of %s: %v", serviceName, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	return instances, nil
+}
+
+// ServiceAddresses returns only instances whose TTL check is passing in Consul.
+// Unlike Discover it never falls back to cached results, matching the inmem
+// registry's lastActive semantics. Successful answers still refresh the cache
+// so Discover can bridge a later Consul outage.
+func (r *Registry) ServiceAddresses(ctx context.Context, serviceName string) ([]string, error) {
+	entries, _, err := r.client.Health().Service(serviceName, "", true, (&consul.QueryOptions{}).WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -72,5 +110,30 @@ func (r *Registry) Discover(ctx context.Context, serviceName string) ([]string,
 		instances = append(instances, fmt.Sprintf("%s:%d", entry.Service.Address, entry.Service.Port))
 	}
 
+	r.storeDiscover(serviceName, instances)
 	return instances, nil
 }
+
+// cachedDiscover: Letztes erfolgreiches Discover Ergebnis, falls jünger als discoverCacheTTL
+func (r *Registry) cachedDiscover(serviceName string) ([]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached, ok := r.lastKnown[serviceName]
+	if !ok || r.now().Sub(cached.fetchedAt) > discoverCacheTTL {
+		return nil, false
+	}
+	return cached.addrs, true
+}
+
+// storeDiscover: Nur nicht-leere Ergebnisse merken
+// → "Keine gesunde Instanz" ist eine echte Antwort, kein Fallback-Kandidat
+func (r *Registry) storeDiscover(serviceName string, addrs []string) {
+	if len(addrs) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastKnown[serviceName] = cachedInstances{addrs: addrs, fetchedAt: r.now()}
+}
@@ -0,0 +1,118 @@
+package consul
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+// newTestRegistry: Registry gegen einen Fake Consul Agent (httptest)
+// down=true → Health Endpoint antwortet 500 (Consul nicht erreichbar)
+func newTestRegistry(t *testing.T, down *atomic.Bool) *Registry {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "consul down", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Service":{"Address":"10.0.0.1","Port":9000}},{"Service":{"Address":"10.0.0.2","Port":9000}}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := consul.DefaultConfig()
+	cfg.Address = srv.Listener.Addr().String()
+	client, err := consul.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("consul client: %v", err)
+	}
+
+	return &Registry{
+		client:    client,
+		lastKnown: make(map[string]cachedInstances),
+		now:       time.Now,
+	}
+}
+
+func TestServiceAddressesFillsDiscoverCache(t *testing.T) {
+	var down atomic.Bool
+	r := newTestRegistry(t, &down)
+	ctx := context.Background()
+
+	addrs, err := r.ServiceAddresses(ctx, "orders")
+	if err != nil || len(addrs) != 2 {
+		t.Fatalf("ServiceAddresses = %v, %v; want 2 instances", addrs, err)
+	}
+
+	down.Store(true)
+
+	// ServiceAddresses selbst liefert NIE veraltete Daten
+	if _, err := r.ServiceAddresses(ctx, "orders"); err == nil {
+		t.Fatal("ServiceAddresses succeeded while consul is down")
+	}
+
+	// Discover überbrückt den Ausfall mit dem Ergebnis des letzten ServiceAddresses Calls
+	addrs, err = r.Discover(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Discover during outage: %v", err)
+	}
+	if len(addrs) != 2 || addrs[0] != "10.0.0.1:9000" {
+		t.Fatalf("Discover during outage = %v; want cached instances", addrs)
+	}
+}
+
+// Consul fällt nach einem erfolgreichen Discover aus → innerhalb der TTL kommen die gecachten Instanzen
+func TestDiscoverUsesCacheWhileConsulDown(t *testing.T) {
+	var down atomic.Bool
+	r := newTestRegistry(t, &down)
+	ctx := context.Background()
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	if _, err := r.Discover(ctx, "stock"); err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+
+	down.Store(true)
+	now = now.Add(discoverCacheTTL - time.Second)
+
+	addrs, err := r.Discover(ctx, "stock")
+	if err != nil {
+		t.Fatalf("Discover during outage: %v", err)
+	}
+	if len(addrs) != 2 || addrs[0] != "10.0.0.1:9000" || addrs[1] != "10.0.0.2:9000" {
+		t.Fatalf("Discover during outage = %v; want cached instances", addrs)
+	}
+
+	// Nie erfolgreich entdeckt → kein Cache → Fehler
+	if addrs, err := r.Discover(ctx, "payments"); err == nil {
+		t.Fatalf("Discover of uncached service = %v; want error", addrs)
+	}
+}
+
+func TestDiscoverCacheExpires(t *testing.T) {
+	var down atomic.Bool
+	r := newTestRegistry(t, &down)
+	ctx := context.Background()
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	if _, err := r.Discover(ctx, "orders"); err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+
+	down.Store(true)
+	now = now.Add(discoverCacheTTL + time.Second)
+
+	if addrs, err := r.Discover(ctx, "orders"); err == nil {
+		t.Fatalf("Discover after cache TTL = %v; want error", addrs)
+	}
+}
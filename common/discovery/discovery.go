@@ -11,6 +11,9 @@ type Registry interface {
 	Register(ctx context.Context, instanceID, serverName, hostPort string) error
 	Deregister(ctx context.Context, instanceID, serviceName string) error
 	Discover(ctx context.Context, serviceName string) ([]string, error)
+	// ServiceAddresses is like Discover but returns only instances whose health
+	// check is currently passing, never cached or stale results
+	ServiceAddresses(ctx context.Context, serviceName string) ([]string, error)
 	HealthCheck(instanceID, serviceName string) error
 }
 
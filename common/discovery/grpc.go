@@ -2,27 +2,145 @@ package discovery
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"math/rand"
+	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func ServiceConnection(ctx context.Context, serviceName string, registry Registry) (*grpc.ClientConn, error) {
-	addrs, err := registry.Discover(ctx, serviceName)
+// dialTimeout: How long to wait for a single instance before trying the next one
+const dialTimeout = 2 * time.Second
+
+// ServiceConnection returns a client connection that balances RPCs across all
+// discovered instances of serviceName (round_robin), re-resolving them every
+// DefaultResolveInterval. Registries implementing SinglePicker keep the
+// previous behaviour: one random instance per connection with failover.
+//
+// opts: Additional dial options of the caller (e.g. the upstream metrics interceptor)
+func ServiceConnection(ctx context.Context, serviceName string, registry Registry, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	addrs, err := healthyAddresses(ctx, registry, serviceName)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no healthy instances found for service %s", serviceName)
+	}
+
 	log.Printf("Discovered %d instances of %s", len(addrs), serviceName)
 
-	// Randomly select an instance
-	return grpc.NewClient(
-		addrs[rand.Intn(len(addrs))],
+	if sp, ok := registry.(SinglePicker); ok && sp.SinglePick() {
+		return dialRandomInstance(ctx, serviceName, addrs, opts...)
+	}
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithResolvers(&registryResolverBuilder{
+			registry: registry,
+			initial:  addrs,
+			interval: DefaultResolveInterval,
+		}),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+	}, opts...)
+	conn, err := grpc.NewClient(registryScheme+":///"+serviceName, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// round_robin is READY as soon as one instance is; if none is, fail now
+	// instead of on the first RPC
+	if err := waitReady(ctx, conn); err != nil {
+		return nil, fmt.Errorf("all %d instances of %s unreachable: %w", len(addrs), serviceName, err)
+	}
+	return conn, nil
+}
+
+// healthyAddresses prefers registry.ServiceAddresses (passing health checks only).
+// If that lookup fails, Discover is used so a registry outage can still be
+// bridged by its cached instances; an empty result is a real answer and is
+// returned as is.
+func healthyAddresses(ctx context.Context, registry Registry, serviceName string) ([]string, error) {
+	addrs, err := registry.ServiceAddresses(ctx, serviceName)
+	if err == nil {
+		return addrs, nil
+	}
+
+	log.Printf("ServiceAddresses for %s failed, falling back to Discover: %v", serviceName, err)
+	return registry.Discover(ctx, serviceName)
+}
+
+// SinglePicker is optionally implemented by a Registry. SinglePick() == true
+// disables the round_robin balancer in favour of a random instance per
+// connection with failover (used by the inmem registry in tests).
+type SinglePicker interface {
+	SinglePick() bool
+}
+
+// dialRandomInstance starts at a random instance, then tries the others in sequence
+func dialRandomInstance(ctx context.Context, serviceName string, addrs []string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	start := rand.Intn(len(addrs))
+
+	var lastErr error
+	for i := range addrs {
+		addr := addrs[(start+i)%len(addrs)]
+
+		conn, err := dialInstance(ctx, addr, opts...)
+		if err == nil {
+			return conn, nil
+		}
+
+		log.Printf("Failed to connect to %s instance %s: %v", serviceName, addr, err)
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("all %d instances of %s unreachable: %w", len(addrs), serviceName, lastErr)
+}
+
+// dialInstance creates a client for addr and waits until it is READY.
+// grpc.NewClient is lazy, so without waiting an unreachable instance would
+// only fail on the first RPC - too late to fall back to another instance.
+func dialInstance(ctx context.Context, addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	dialOpts := append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		// Add OpenTelemetry interceptors
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
-	)
+	}, opts...)
+	conn, err := grpc.NewClient(addr, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitReady(ctx, conn); err != nil {
+		return nil, fmt.Errorf("instance %s %w", addr, err)
+	}
+	return conn, nil
+}
+
+// waitReady waits up to dialTimeout for conn to become READY and closes it on failure
+func waitReady(ctx context.Context, conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			conn.Close()
+			return fmt.Errorf("is %s", state)
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			conn.Close()
+			return fmt.Errorf("not ready: %w", ctx.Err())
+		}
+	}
 }
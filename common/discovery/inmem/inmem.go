@@ -98,3 +98,10 @@ func (r *Registry) ServiceAddresses(ctx context.Context, serviceName string) ([]
 
 	return res, nil
 }
+
+// SinglePick opts out of the round_robin balancer (see discovery.SinglePicker):
+// tests often register fake addresses, and a random pick with failover is
+// more predictable than a balancer re-resolving in the background.
+func (r *Registry) SinglePick() bool {
+	return true
+}
@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// registryScheme resolves targets like "registry:///orders" via healthyAddresses
+const registryScheme = "registry"
+
+// DefaultResolveInterval: How often the resolver re-discovers instances, so new
+// instances receive traffic and deregistered ones drop out without reconnecting
+const DefaultResolveInterval = 30 * time.Second
+
+// resolveTimeout bounds a single background registry lookup
+const resolveTimeout = 5 * time.Second
+
+// registryResolverBuilder builds resolvers backed by one Registry. It is passed
+// per connection via grpc.WithResolvers rather than registered globally, since
+// each service owns its own registry instance.
+type registryResolverBuilder struct {
+	registry Registry
+	initial  []string // Already discovered by ServiceConnection, avoids a second lookup on dial
+	interval time.Duration
+}
+
+func (b *registryResolverBuilder) Scheme() string { return registryScheme }
+
+func (b *registryResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &registryResolver{
+		registry:    b.registry,
+		serviceName: target.Endpoint(),
+		cc:          cc,
+		cancel:      cancel,
+		resolveNow:  make(chan struct{}, 1),
+	}
+
+	if err := r.update(b.initial); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	r.wg.Add(1)
+	go r.watch(ctx, b.interval)
+	return r, nil
+}
+
+// registryResolver keeps the round_robin balancer's address list up to date
+type registryResolver struct {
+	registry    Registry
+	serviceName string
+	cc          resolver.ClientConn
+	cancel      context.CancelFunc
+	resolveNow  chan struct{}
+	wg          sync.WaitGroup
+}
+
+// watch re-resolves every interval (or on ResolveNow) until Close
+func (r *registryResolver) watch(ctx context.Context, interval time.Duration) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+		r.resolve(ctx)
+	}
+}
+
+// resolve pushes the current instances to the balancer. On a failed lookup
+// the previous list is kept: a registry hiccup does not mean every instance
+// is gone, and existing subchannels stay usable.
+func (r *registryResolver) resolve(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	addrs, err := healthyAddresses(ctx, r.registry, r.serviceName)
+	if err != nil || len(addrs) == 0 {
+		log.Printf("Failed to re-resolve %s, keeping previous instances: %v", r.serviceName, err)
+		return
+	}
+	if err := r.update(addrs); err != nil {
+		log.Printf("Failed to update %s instances: %v", r.serviceName, err)
+	}
+}
+
+func (r *registryResolver) update(addrs []string) error {
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(addrs))}
+	for _, addr := range addrs {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+	return r.cc.UpdateState(state)
+}
+
+// ResolveNow is called by gRPC when a connection breaks; it must not block
+func (r *registryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *registryResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor records every outgoing unary call to service
+// → Registrieren via discovery.ServiceConnection(ctx, "orders", registry, grpc.WithUnaryInterceptor(...))
+// → method = nur der RPC Name ("/api.OrderService/GetOrder" → "GetOrder"), Service steckt schon im Label
+// → code = gRPC Status ("OK", "Unavailable", ...) → Fehlerrate pro Dependency
+// → nil Metrics → Interceptor reicht nur durch
+func (m *UpstreamMetrics) UnaryClientInterceptor(service string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if m == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.RecordUpstreamCall(service, path.Base(method), status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// dialWithInterceptor: Echter gRPC Server (nur Health Service) + Client mit dem Upstream Interceptor
+func dialWithInterceptor(t *testing.T, m *UpstreamMetrics, service string) healthpb.HealthClient {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("known", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(m.UnaryClientInterceptor(service)),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+// Fehlerhafter Call → Counter mit dem gRPC Code als Label, erfolgreicher Call → "OK"
+func TestUnaryClientInterceptorRecordsCode(t *testing.T) {
+	m := NewUpstreamMetrics("upstream_interceptor_test")
+	client := dialWithInterceptor(t, m, "stock")
+	ctx := context.Background()
+
+	// Unbekannter Service → Health Server antwortet NotFound
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Fatal("Check(unknown) succeeded; want NotFound")
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "known"}); err != nil {
+		t.Fatalf("Check(known): %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.Requests.WithLabelValues("stock", "Check", "NotFound")); got != 1 {
+		t.Errorf("requests{stock,Check,NotFound} = %v; want 1", got)
+	}
+	if got := testutil.ToFloat64(m.Requests.WithLabelValues("stock", "Check", "OK")); got != 1 {
+		t.Errorf("requests{stock,Check,OK} = %v; want 1", got)
+	}
+	if n := testutil.CollectAndCount(m.Requests); n != 2 {
+		t.Errorf("request series = %d; want 2", n)
+	}
+}
+
+// nil Metrics → Interceptor reicht nur durch (Services ohne Metrics)
+func TestUnaryClientInterceptorNilMetrics(t *testing.T) {
+	var m *UpstreamMetrics
+	client := dialWithInterceptor(t, m, "stock")
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "known"}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+}
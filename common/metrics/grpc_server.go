@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor records every incoming unary call (Gegenstück zu UnaryClientInterceptor)
+// → Als ERSTER Interceptor in der Chain → auch vom Validation Interceptor abgelehnte Calls (InvalidArgument) zählen
+// → method = nur der RPC Name ("/api.StockService/ReserveStock" → "ReserveStock")
+// → status = gRPC Code ("OK", "NotFound", ...) → Fehlerrate pro Methode
+// → nil Metrics → Interceptor reicht nur durch
+func (m *GRPCMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if m == nil {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.RecordGRPCRequest(path.Base(info.FullMethod), status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
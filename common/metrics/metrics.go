@@ -1,12 +1,21 @@
 package metrics
 
 import (
+	"os"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// Namespace: Gemeinsamer Prefix aller Metrics, konfigurierbar via METRICS_NAMESPACE (z.B. "oms")
+// → gesetzt: <namespace>_<service>_<metric> (oms_orders_grpc_requests_total) → EIN Schema für Cross-Service Dashboards
+// → leer (Default): Legacy Namen <service>_<metric> → bestehende Dashboards/Alerts bleiben gültig
+// Warum Namespace/Subsystem statt String-Verkettung?
+// → prometheus.BuildFQName überspringt leere Teile → Legacy Name fällt ohne Sonderfall raus
+var Namespace = os.Getenv("METRICS_NAMESPACE")
+
 // HTTPMetrics contains HTTP-related Prometheus metrics
 type HTTPMetrics struct {
 	RequestsTotal   *prometheus.CounterVec
@@ -25,6 +34,43 @@ type BusinessMetrics struct {
 	OrdersPaid         prometheus.Counter
 	PaymentLinksCreated prometheus.Counter
 	StripeAPIDuration  prometheus.Histogram
+
+	// OrdersRejectedStockout: Abgelehnte Orders pro fehlendem Item → Nachfrage vs. Verfügbarkeit (verlorene Verkäufe)
+	OrdersRejectedStockout *prometheus.CounterVec
+}
+
+// StockMetrics contains inventory/reservation metrics of the stock service
+type StockMetrics struct {
+	ReservationConfirmLatency prometheus.Histogram
+	AllItemsCache             *prometheus.CounterVec
+}
+
+// MenuMetrics contains the gateway's Stripe menu data cache metrics
+type MenuMetrics struct {
+	PriceCache *prometheus.CounterVec
+}
+
+// RevenueMetrics contains paid amounts per currency
+type RevenueMetrics struct {
+	Revenue *prometheus.CounterVec
+}
+
+// ConsumerMetrics contains AMQP consumer health and processing metrics
+type ConsumerMetrics struct {
+	IdleSeconds        *prometheus.GaugeVec
+	IdleWarnings       *prometheus.CounterVec
+	ProcessingDuration *prometheus.HistogramVec
+}
+
+// UpstreamMetrics contains client-side latency and outcome of calls to downstream gRPC services
+type UpstreamMetrics struct {
+	Duration *prometheus.HistogramVec
+	Requests *prometheus.CounterVec
+}
+
+// SLAMetrics contains order SLA metrics (time-in-status breaches)
+type SLAMetrics struct {
+	Breaches prometheus.Counter
 }
 
 // NewHTTPMetrics creates HTTP metrics for a service
@@ -32,16 +78,20 @@ func NewHTTPMetrics(serviceName string) *HTTPMetrics {
 	return &HTTPMetrics{
 		RequestsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: serviceName + "_http_requests_total",
-				Help: "Total number of HTTP requests",
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "http_requests_total",
+				Help:      "Total number of HTTP requests",
 			},
 			[]string{"method", "path", "status"},
 		),
 		RequestDuration: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
-				Name:    serviceName + "_http_request_duration_seconds",
-				Help:    "HTTP request duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "http_request_duration_seconds",
+				Help:      "HTTP request duration in seconds",
+				Buckets:   prometheus.DefBuckets,
 			},
 			[]string{"method", "path"},
 		),
@@ -53,16 +103,20 @@ func NewGRPCMetrics(serviceName string) *GRPCMetrics {
 	return &GRPCMetrics{
 		RequestsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: serviceName + "_grpc_requests_total",
-				Help: "Total number of gRPC requests",
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "grpc_requests_total",
+				Help:      "Total number of gRPC requests",
 			},
 			[]string{"method", "status"},
 		),
 		RequestDuration: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
-				Name:    serviceName + "_grpc_request_duration_seconds",
-				Help:    "gRPC request duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "grpc_request_duration_seconds",
+				Help:      "gRPC request duration in seconds",
+				Buckets:   prometheus.DefBuckets,
 			},
 			[]string{"method"},
 		),
@@ -74,28 +128,192 @@ func NewBusinessMetrics(serviceName string) *BusinessMetrics {
 	return &BusinessMetrics{
 		OrdersCreated: promauto.NewCounter(
 			prometheus.CounterOpts{
-				Name: serviceName + "_orders_created_total",
-				Help: "Total number of orders created",
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "orders_created_total",
+				Help:      "Total number of orders created",
 			},
 		),
 		OrdersPaid: promauto.NewCounter(
 			prometheus.CounterOpts{
-				Name: serviceName + "_orders_paid_total",
-				Help: "Total number of orders paid",
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "orders_paid_total",
+				Help:      "Total number of orders paid",
 			},
 		),
 		PaymentLinksCreated: promauto.NewCounter(
 			prometheus.CounterOpts{
-				Name: serviceName + "_payment_links_created_total",
-				Help: "Total number of payment links created",
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "payment_links_created_total",
+				Help:      "Total number of payment links created",
 			},
 		),
 		StripeAPIDuration: promauto.NewHistogram(
 			prometheus.HistogramOpts{
-				Name:    serviceName + "_stripe_api_duration_seconds",
-				Help:    "Stripe API call duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "stripe_api_duration_seconds",
+				Help:      "Stripe API call duration in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+		),
+		OrdersRejectedStockout: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "orders_rejected_stockout_total",
+				Help:      "Total number of orders rejected at creation because an item was out of stock, by item",
+			},
+			[]string{"item_id"},
+		),
+	}
+}
+
+// NewStockMetrics creates reservation metrics for the stock service
+func NewStockMetrics(serviceName string) *StockMetrics {
+	return &StockMetrics{
+		ReservationConfirmLatency: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "reservation_confirm_latency_seconds",
+				Help:      "Time between reserving stock (order created) and confirming it (payment completed)",
+				// Checkout dauert Sekunden bis Minuten → max. ReservationTTL (15m)
+				Buckets: []float64{5, 15, 30, 60, 120, 300, 600, 900},
+			},
+		),
+		// Warum eigene Metrik?
+		// → GetItems ohne IDs (Menu!) ist der häufigste Read → Hit Rate dieses Pfads separat sehen
+		// → result=bypass: all_items Cache deaktiviert → JEDER Menu Call geht auf PostgreSQL
+		AllItemsCache: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "all_items_cache_total",
+				Help:      "GetItems calls without ids by cache result (hit, miss, bypass)",
+			},
+			[]string{"result"},
+		),
+	}
+}
+
+// NewMenuMetrics creates the menu cache metrics of the gateway
+// Warum eigene Metrik?
+// → Hit Rate zeigt wie viele Stripe Calls der Cache spart → zu niedrig = TTL zu kurz
+func NewMenuMetrics(serviceName string) *MenuMetrics {
+	return &MenuMetrics{
+		PriceCache: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "menu_price_cache_total",
+				Help:      "Stripe price/product lookups for the menu by cache result (hit, miss)",
+			},
+			[]string{"result"},
+		),
+	}
+}
+
+// NewSLAMetrics creates the order SLA metrics
+// Warum kein serviceName Prefix?
+// → Das SLA gehört zur Order, nicht zum Service der es misst
+// → Dashboards/Alerts bleiben gleich, egal ob Kitchen oder Orders trackt
+func NewSLAMetrics() *SLAMetrics {
+	return &SLAMetrics{
+		Breaches: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Name:      "orders_sla_breach_total",
+				Help:      "Total number of orders that stayed in preparing longer than the prep-time SLA",
+			},
+		),
+	}
+}
+
+// NewConsumerMetrics creates consumer idle and processing metrics (labels: queue, outcome)
+// Warum?
+// → Consumer ohne Messages sieht aus wie ein gesunder Consumer (kein Fehler, kein Log)
+// → Gelöschtes Binding / falscher Exchange fällt nur über "seit X Minuten nichts" auf
+// → Processing Duration: order.created wird langsam (Stripe hängt) → sichtbar BEVOR die Queue voll läuft
+func NewConsumerMetrics(serviceName string) *ConsumerMetrics {
+	return &ConsumerMetrics{
+		IdleSeconds: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "consumer_idle_seconds",
+				Help:      "Seconds since the consumer last received a message",
+			},
+			[]string{"queue"},
+		),
+		IdleWarnings: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "consumer_idle_warnings_total",
+				Help:      "Number of times a consumer exceeded its idle threshold",
+			},
+			[]string{"queue"},
+		),
+		ProcessingDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "message_processing_duration_seconds",
+				Help:      "Time spent handling one AMQP message, by queue and outcome (success, retry, dead_letter, duplicate)",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"queue", "outcome"},
+		),
+	}
+}
+
+// NewRevenueMetrics creates the revenue metrics
+// Warum currency Label?
+// → 10 EUR + 10 USD ≠ 20 von irgendwas → Summen nur pro Währung sinnvoll
+// → Dashboards: sum by (currency) (rate(payment_revenue_minor_units_total[1h]))
+func NewRevenueMetrics(serviceName string) *RevenueMetrics {
+	return &RevenueMetrics{
+		Revenue: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "revenue_minor_units_total",
+				Help:      "Paid amount in the smallest currency unit (e.g. cents), by currency",
+			},
+			[]string{"currency"},
+		),
+	}
+}
+
+// NewUpstreamMetrics creates the upstream (gRPC client) latency metrics
+// Warum getrennt von <svc>_http_request_duration_seconds?
+// → HTTP Dauer = eigener Overhead + Downstream → langsamer Request sagt nicht WER langsam ist
+// → upstream_duration nur um den gRPC Call → Differenz = eigener Overhead
+// Warum upstream_requests_total mit code?
+// → Fehlerrate PRO Dependency fürs SLO Dashboard: rate(...{service="stock",code!="OK"}) / rate(...{service="stock"})
+func NewUpstreamMetrics(serviceName string) *UpstreamMetrics {
+	return &UpstreamMetrics{
+		Duration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "upstream_duration_seconds",
+				Help:      "Duration of outgoing gRPC calls to downstream services in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"service", "method"},
+		),
+		Requests: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: serviceName,
+				Name:      "upstream_requests_total",
+				Help:      "Total number of outgoing gRPC calls to downstream services by status code",
 			},
+			[]string{"service", "method", "code"},
 		),
 	}
 }
@@ -111,3 +329,115 @@ func (m *GRPCMetrics) RecordGRPCRequest(method, status string, duration time.Dur
 	m.RequestsTotal.WithLabelValues(method, status).Inc()
 	m.RequestDuration.WithLabelValues(method).Observe(duration.Seconds())
 }
+
+// RecordOrderCreated records one newly stored order (idempotent replays don't count)
+func (m *BusinessMetrics) RecordOrderCreated() {
+	if m == nil {
+		return
+	}
+	m.OrdersCreated.Inc()
+}
+
+// RecordOrderPaid records one order updated to "paid"
+func (m *BusinessMetrics) RecordOrderPaid() {
+	if m == nil {
+		return
+	}
+	m.OrdersPaid.Inc()
+}
+
+// RecordPaymentLinkCreated records one checkout link handed to a customer
+func (m *BusinessMetrics) RecordPaymentLinkCreated() {
+	if m == nil {
+		return
+	}
+	m.PaymentLinksCreated.Inc()
+}
+
+// ObserveStripeAPI records the duration of one Stripe API call (successful or not)
+func (m *BusinessMetrics) ObserveStripeAPI(duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.StripeAPIDuration.Observe(duration.Seconds())
+}
+
+// RecordStockoutRejection records one order rejected at creation, once per out-of-stock item
+// Warum pro Item?
+// → "Welches Item kostet uns Verkäufe?" → Item IDs sind durch das Menü begrenzt (keine Label-Explosion)
+// → Keine Item Details (alter Stock Service) → "unknown", die Ablehnung zählt trotzdem
+func (m *BusinessMetrics) RecordStockoutRejection(itemIDs ...string) {
+	if m == nil {
+		return
+	}
+	if len(itemIDs) == 0 {
+		m.OrdersRejectedStockout.WithLabelValues("unknown").Inc()
+		return
+	}
+	for _, id := range itemIDs {
+		m.OrdersRejectedStockout.WithLabelValues(id).Inc()
+	}
+}
+
+// RecordSLABreach records an order exceeding its prep-time SLA
+func (m *SLAMetrics) RecordSLABreach() {
+	if m == nil {
+		return
+	}
+	m.Breaches.Inc()
+}
+
+// RecordConsumerIdle records the current idle time of a consumer
+func (m *ConsumerMetrics) RecordConsumerIdle(queue string, idle time.Duration) {
+	m.IdleSeconds.WithLabelValues(queue).Set(idle.Seconds())
+}
+
+// RecordConsumerIdleWarning records a consumer exceeding its idle threshold
+func (m *ConsumerMetrics) RecordConsumerIdleWarning(queue string) {
+	m.IdleWarnings.WithLabelValues(queue).Inc()
+}
+
+// ObserveMessageProcessing records how long handling one message took and how it ended
+// nil-safe → Consumer ohne Metrics (Tests, Tools) brauchen keinen Sonderfall
+func (m *ConsumerMetrics) ObserveMessageProcessing(queue, outcome string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.ProcessingDuration.WithLabelValues(queue, outcome).Observe(duration.Seconds())
+}
+
+// RecordRevenue records a paid amount (smallest currency unit) for a currency
+// Stripe liefert Währungen klein ("eur") → normalisieren damit "EUR" und "eur" EINE Serie sind
+func (m *RevenueMetrics) RecordRevenue(currency string, amount int64) {
+	if m == nil || amount <= 0 {
+		return
+	}
+	m.Revenue.WithLabelValues(strings.ToLower(currency)).Add(float64(amount))
+}
+
+// RecordUpstreamCall records the duration and gRPC status code of one outgoing gRPC call
+func (m *UpstreamMetrics) RecordUpstreamCall(service, method, code string, duration time.Duration) {
+	m.Duration.WithLabelValues(service, method).Observe(duration.Seconds())
+	m.Requests.WithLabelValues(service, method, code).Inc()
+}
+
+// RecordAllItemsCache records one "get all items" lookup (result: hit, miss, bypass)
+func (m *StockMetrics) RecordAllItemsCache(result string) {
+	if m == nil {
+		return
+	}
+	m.AllItemsCache.WithLabelValues(result).Inc()
+}
+
+// RecordPriceCache records one Stripe menu data lookup (result: hit, miss)
+func (m *MenuMetrics) RecordPriceCache(result string) {
+	if m == nil {
+		return
+	}
+	m.PriceCache.WithLabelValues(result).Inc()
+}
+
+// RecordReservationConfirmed records the latency from reservation to confirmation
+func (m *StockMetrics) RecordReservationConfirmed(latency time.Duration) {
+	m.ReservationConfirmLatency.Observe(latency.Seconds())
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Umsatz wird NIE über Währungen summiert → eine Serie pro (normalisierter) Währung
+func TestRecordRevenueGroupsByCurrency(t *testing.T) {
+	m := NewRevenueMetrics("revenue_test")
+
+	m.RecordRevenue("eur", 1000)
+	m.RecordRevenue("EUR", 500) // Stripe liefert klein, andere Quellen evtl. groß → dieselbe Serie
+	m.RecordRevenue("usd", 700)
+	m.RecordRevenue("usd", 0) // Gratis Order → kein Umsatz, aber auch kein Fehler
+
+	if n := testutil.CollectAndCount(m.Revenue); n != 2 {
+		t.Fatalf("series = %d; want 2 (eur, usd)", n)
+	}
+	if got := testutil.ToFloat64(m.Revenue.WithLabelValues("eur")); got != 1500 {
+		t.Errorf("eur = %v; want 1500", got)
+	}
+	if got := testutil.ToFloat64(m.Revenue.WithLabelValues("usd")); got != 700 {
+		t.Errorf("usd = %v; want 700", got)
+	}
+}
+
+func TestRecordRevenueNilSafe(t *testing.T) {
+	var m *RevenueMetrics
+	m.RecordRevenue("eur", 1000) // Handler ohne Metrics (Tests) → kein Panic
+}
+
+// Keine Item Details vom Stock Service → Ablehnung zählt als "unknown"; nil Metrics → kein Panic
+func TestRecordStockoutRejectionUnknownItem(t *testing.T) {
+	m := NewBusinessMetrics("stockout_unknown_test")
+	m.RecordStockoutRejection()
+	if got := testutil.ToFloat64(m.OrdersRejectedStockout.WithLabelValues("unknown")); got != 1 {
+		t.Errorf("unknown = %v; want 1", got)
+	}
+
+	var nilMetrics *BusinessMetrics
+	nilMetrics.RecordStockoutRejection("1")
+}
+
+// setNamespace: Namespace für EINEN Test setzen (wird sonst beim Start aus METRICS_NAMESPACE gelesen)
+func setNamespace(t *testing.T, ns string) {
+	t.Helper()
+	old := Namespace
+	Namespace = ns
+	t.Cleanup(func() { Namespace = old })
+}
+
+func TestMetricNamespace(t *testing.T) {
+	tests := []struct {
+		namespace string
+		service   string
+		want      string
+	}{
+		{"oms", "ns_orders", "oms_ns_orders_grpc_requests_total"},
+		{"", "ns_legacy_orders", "ns_legacy_orders_grpc_requests_total"}, // Legacy: <service>_<metric>
+	}
+	for _, tt := range tests {
+		setNamespace(t, tt.namespace)
+
+		m := NewGRPCMetrics(tt.service)
+		m.RequestsTotal.WithLabelValues("GetOrder", "OK").Inc()
+
+		if n := testutil.CollectAndCount(m.RequestsTotal, tt.want); n != 1 {
+			t.Errorf("namespace %q: series named %s = %d; want 1", tt.namespace, tt.want, n)
+		}
+	}
+}
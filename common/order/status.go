@@ -0,0 +1,81 @@
+// Package order: Gemeinsame Order Status Werte + erlaubte Übergänge für alle Services
+// Warum ein eigenes Package?
+// → Status waren String Literale in Orders, Kitchen, Payments und Gateway
+// → Tippfehler ("preparng") kompiliert, bricht aber still einen Übergang
+// → Konstante falsch geschrieben = Compile Fehler
+package order
+
+const (
+	StatusPending        = "pending"         // Order angelegt, noch kein Payment Link
+	StatusWaitingPayment = "waiting_payment" // Payment Link erstellt, Kunde hat noch nicht bezahlt
+	StatusPaid           = "paid"            // Stripe Webhook: Zahlung eingegangen
+	StatusPreparing      = "preparing"       // Kitchen hat die Order übernommen (order.paid Consumer)
+	StatusReady          = "ready"           // Chef: Essen ist fertig
+	StatusCompleted      = "completed"       // Kunde hat abgeholt → Endzustand
+	StatusCancelled      = "cancelled"       // Storniert (bezahlt → Payments erstattet) → Endzustand
+	StatusExpired        = "expired"         // Nie bezahlt, Payment Fenster abgelaufen → Endzustand
+)
+
+// Statuses: Alle bekannten Status in Lifecycle Reihenfolge
+var Statuses = []string{
+	StatusPending,
+	StatusWaitingPayment,
+	StatusPaid,
+	StatusPreparing,
+	StatusReady,
+	StatusCompleted,
+	StatusCancelled,
+	StatusExpired,
+}
+
+// Transitions: Von welchem Status aus welche Folgestatus erlaubt sind
+// Warum Allowlist pro Status?
+// → Neuer Status ohne Eintrag hat KEINE Übergänge → muss bewusst freigeschaltet werden
+// → Gleicher Status (z.B. paid → paid bei Webhook Retry) ist immer erlaubt, siehe CanTransition
+var Transitions = map[string][]string{
+	// → expired: Nur ohne Zahlung möglich → ab "paid" läuft der Storno Weg (Refund)
+	StatusPending:        {StatusWaitingPayment, StatusPaid, StatusCancelled, StatusExpired},
+	StatusWaitingPayment: {StatusPaid, StatusCancelled, StatusExpired},
+	// paid → ready: Chef ist schneller als der order.paid Consumer → dessen "preparing" wird abgelehnt
+	// paid → cancelled: Payments erstattet über den order.cancelled Consumer (Stripe Refund)
+	StatusPaid:      {StatusPreparing, StatusReady, StatusCancelled},
+	StatusPreparing: {StatusReady},
+	StatusReady:     {StatusCompleted},
+	StatusCompleted: {},
+	StatusCancelled: {},
+	StatusExpired:   {},
+}
+
+// IsValid: Ist status ein bekannter Order Status?
+func IsValid(status string) bool {
+	_, ok := Transitions[status]
+	return ok
+}
+
+// CanTransition: Ist der Wechsel from → to erlaubt?
+// → from == to ist ein No-Op (Redelivery, Webhook Retry) → erlaubt, solange der Status bekannt ist
+func CanTransition(from, to string) bool {
+	if from == to {
+		return IsValid(to)
+	}
+	for _, next := range Transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCancellable: Orders bis einschließlich "paid" dürfen storniert werden
+// → Ab "preparing" kocht die Küche schon → kein Storno mehr
+// → "paid" storniert → Payments erstattet den vollen Betrag (order.cancelled)
+func IsCancellable(status string) bool {
+	return CanTransition(status, StatusCancelled)
+}
+
+// IsFinal: Endzustand (completed, cancelled, expired) → keine Übergänge mehr
+// → Nur solche Orders dürfen soft-deleted werden (Kitchen Display / Payments arbeiten sonst noch damit)
+func IsFinal(status string) bool {
+	next, ok := Transitions[status]
+	return ok && len(next) == 0
+}
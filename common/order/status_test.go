@@ -0,0 +1,24 @@
+package order
+
+import "testing"
+
+// ready → completed ist der EINZIGE Weg in den Endzustand "completed"
+func TestPickupTransition(t *testing.T) {
+	if !CanTransition(StatusReady, StatusCompleted) {
+		t.Fatal("ready → completed must be allowed")
+	}
+	for _, from := range []string{StatusPending, StatusWaitingPayment, StatusPaid, StatusPreparing} {
+		if CanTransition(from, StatusCompleted) {
+			t.Errorf("%s → completed must be rejected (order not picked up from the counter)", from)
+		}
+	}
+
+	if !IsFinal(StatusCompleted) {
+		t.Error("completed must be final")
+	}
+	for _, to := range Statuses {
+		if to != StatusCompleted && CanTransition(StatusCompleted, to) {
+			t.Errorf("completed → %s must be rejected", to)
+		}
+	}
+}
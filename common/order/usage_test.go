@@ -0,0 +1,154 @@
+package order
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Jeder Status hat einen Eintrag in Transitions, jedes Ziel ist ein bekannter Status
+// → Neuer Status nur in Statuses (oder nur in Transitions) eingetragen fällt hier auf
+func TestTransitionsComplete(t *testing.T) {
+	known := make(map[string]bool, len(Statuses))
+	for _, s := range Statuses {
+		if known[s] {
+			t.Errorf("status %q listed twice in Statuses", s)
+		}
+		known[s] = true
+		if _, ok := Transitions[s]; !ok {
+			t.Errorf("status %q has no Transitions entry", s)
+		}
+	}
+	for from, next := range Transitions {
+		if !known[from] {
+			t.Errorf("Transitions has unknown source status %q", from)
+		}
+		for _, to := range next {
+			if !known[to] {
+				t.Errorf("Transitions[%q] contains unknown status %q", from, to)
+			}
+			if to == from {
+				t.Errorf("Transitions[%q] lists itself, same-status is handled by CanTransition", from)
+			}
+		}
+	}
+
+	// Jeder Nicht-Endzustand muss irgendwann in einen Endzustand führen (kein Sackgassen Status)
+	for _, s := range Statuses {
+		if !reachesFinal(s, map[string]bool{}) {
+			t.Errorf("status %q can never reach a final status", s)
+		}
+	}
+}
+
+func reachesFinal(s string, seen map[string]bool) bool {
+	if IsFinal(s) {
+		return true
+	}
+	if seen[s] {
+		return false
+	}
+	seen[s] = true
+	for _, next := range Transitions[s] {
+		if reachesFinal(next, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// Services vergleichen/setzen Status nur über die Konstanten, nie als String Literal
+// Geprüft werden die Stellen an denen ein Tippfehler still einen Übergang bricht:
+// → Vergleiche (==, !=), switch case, Status: "..." / "status": "..." und x.Status = "..."
+func TestServicesUseStatusConstants(t *testing.T) {
+	statuses := make(map[string]bool, len(Statuses))
+	for _, s := range Statuses {
+		statuses[s] = true
+	}
+
+	// Stock fehlt bewusst: Reservierungen haben eigene Status (reserved/confirmed/expired)
+	root := filepath.Join("..", "..")
+	for _, service := range []string{"orders", "kitchen", "gateway", "payments"} {
+		dir := filepath.Join(root, service)
+		if _, err := os.Stat(dir); err != nil {
+			t.Fatalf("service dir %s: %v", dir, err)
+		}
+		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") ||
+				strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, ".pb.go") {
+				return err
+			}
+			for _, pos := range statusLiterals(t, path, statuses) {
+				t.Errorf("%s: order status string literal, use the common/order constant", pos)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// statusLiterals: Positionen von Status Literalen in Vergleichen und Status Zuweisungen
+func statusLiterals(t *testing.T, path string, statuses map[string]bool) []token.Position {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		t.Fatalf("parse %s: %v", path, err)
+	}
+
+	var found []token.Position
+	check := func(e ast.Expr) {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		if v, err := strconv.Unquote(lit.Value); err == nil && statuses[v] {
+			found = append(found, fset.Position(lit.Pos()))
+		}
+	}
+	isStatusKey := func(e ast.Expr) bool {
+		switch k := e.(type) {
+		case *ast.Ident:
+			return k.Name == "Status"
+		case *ast.SelectorExpr:
+			return k.Sel.Name == "Status"
+		case *ast.BasicLit:
+			return k.Value == `"status"`
+		}
+		return false
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			if n.Op == token.EQL || n.Op == token.NEQ {
+				check(n.X)
+				check(n.Y)
+			}
+		case *ast.CaseClause:
+			for _, e := range n.List {
+				check(e)
+			}
+		case *ast.KeyValueExpr:
+			if isStatusKey(n.Key) {
+				check(n.Value)
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if i < len(n.Rhs) && isStatusKey(lhs) {
+					check(n.Rhs[i])
+				}
+			}
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,132 @@
+package startup
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dependency: Ergebnis EINER Abhängigkeit beim Startup (Consul, RabbitMQ, DB, Redis, Tracer)
+type Dependency struct {
+	Name     string        `json:"name"`
+	Required bool          `json:"required"`
+	Ready    bool          `json:"ready"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Report: Sammelt die Startup-Ergebnisse aller Dependencies eines Service
+// Warum ein Report statt einzelner "connected to X" Logs?
+// → EINE Log-Zeile am Ende von init zeigt ob ALLES hochgekommen ist
+// → Halb initialisierter Service (z.B. Redis down) ist sofort sichtbar
+type Report struct {
+	mu      sync.Mutex
+	service string
+	started time.Time
+	deps    []Dependency
+}
+
+// NewReport: Erstellt einen leeren Report für serviceName
+func NewReport(serviceName string) *Report {
+	return &Report{
+		service: serviceName,
+		started: time.Now(),
+	}
+}
+
+// Check: Führt fn aus, misst die Dauer und trägt das Ergebnis ein
+// Warum gibt Check den Fehler zurück?
+// → Caller entscheidet selbst: required → abbrechen, optional → weitermachen
+func (r *Report) Check(name string, required bool, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	r.record(name, required, err, time.Since(start))
+	return err
+}
+
+// Record: Trägt ein bereits bekanntes Ergebnis ein (wenn der Connect-Code schon gelaufen ist)
+func (r *Report) Record(name string, required bool, err error) {
+	r.record(name, required, err, 0)
+}
+
+func (r *Report) record(name string, required bool, err error, d time.Duration) {
+	dep := Dependency{
+		Name:     name,
+		Required: required,
+		Ready:    err == nil,
+		Duration: d,
+	}
+	if err != nil {
+		dep.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deps = append(r.deps, dep)
+}
+
+// Dependencies: Kopie aller eingetragenen Ergebnisse (in Reihenfolge der Checks)
+func (r *Report) Dependencies() []Dependency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Dependency(nil), r.deps...)
+}
+
+// Err: Fehler wenn mindestens eine REQUIRED Dependency nicht ready ist
+// → Optionale Dependencies (z.B. Cache) machen den Service nur "degraded"
+func (r *Report) Err() error {
+	var failed []string
+	for _, dep := range r.Dependencies() {
+		if dep.Required && !dep.Ready {
+			failed = append(failed, fmt.Sprintf("%s: %s", dep.Name, dep.Error))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("required dependencies not ready: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Log: Schreibt EINE strukturierte Zusammenfassung und gibt Err() zurück
+// Warum Rückgabe?
+// → main() kann direkt fail-fast machen: if err := report.Log(log); err != nil { os.Exit(1) }
+func (r *Report) Log(log *slog.Logger) error {
+	deps := r.Dependencies()
+
+	attrs := make([]any, 0, len(deps)+2)
+	degraded := false
+	for _, dep := range deps {
+		status := "ready"
+		if !dep.Ready {
+			status = "failed"
+			degraded = true
+		}
+
+		group := []any{
+			slog.String("status", status),
+			slog.Bool("required", dep.Required),
+		}
+		if dep.Duration > 0 {
+			group = append(group, slog.Duration("duration", dep.Duration))
+		}
+		if dep.Error != "" {
+			group = append(group, slog.String("error", dep.Error))
+		}
+		attrs = append(attrs, slog.Group(dep.Name, group...))
+	}
+	attrs = append(attrs, slog.Duration("startup_duration", time.Since(r.started)))
+
+	err := r.Err()
+	switch {
+	case err != nil:
+		log.Error("startup failed", append(attrs, slog.Any("error", err))...)
+	case degraded:
+		log.Warn("startup complete (degraded)", attrs...)
+	default:
+		log.Info("startup complete", attrs...)
+	}
+	return err
+}
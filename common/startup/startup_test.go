@@ -0,0 +1,96 @@
+package startup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+// logSummary: Report.Log in einen JSON Handler → die eine Zusammenfassungs-Zeile als Map
+func logSummary(t *testing.T, r *Report) (map[string]any, error) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := r.Log(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	var line map[string]any
+	if jerr := json.Unmarshal(buf.Bytes(), &line); jerr != nil {
+		t.Fatalf("summary is not one JSON line: %v\n%s", jerr, buf.String())
+	}
+	return line, err
+}
+
+func depStatus(t *testing.T, line map[string]any, name string) map[string]any {
+	t.Helper()
+	group, ok := line[name].(map[string]any)
+	if !ok {
+		t.Fatalf("summary has no group %q: %v", name, line)
+	}
+	return group
+}
+
+func TestReportAllReady(t *testing.T) {
+	r := NewReport("orders")
+	r.Check("mongodb", true, func() error { return nil })
+	r.Record("tracer", true, nil)
+
+	line, err := logSummary(t, r)
+	if err != nil {
+		t.Fatalf("Log = %v; want nil", err)
+	}
+	if line["level"] != "INFO" || line["msg"] != "startup complete" {
+		t.Fatalf("summary = %v", line)
+	}
+	for _, name := range []string{"mongodb", "tracer"} {
+		if s := depStatus(t, line, name); s["status"] != "ready" || s["required"] != true {
+			t.Errorf("%s = %v; want ready + required", name, s)
+		}
+	}
+}
+
+func TestReportOptionalFailureIsDegraded(t *testing.T) {
+	r := NewReport("stock")
+	r.Record("postgres", true, nil)
+	r.Record("redis", false, errors.New("connection refused"))
+
+	line, err := logSummary(t, r)
+	if err != nil {
+		t.Fatalf("Log = %v; optional dependency must not fail startup", err)
+	}
+	if line["level"] != "WARN" || line["msg"] != "startup complete (degraded)" {
+		t.Fatalf("summary = %v", line)
+	}
+	if s := depStatus(t, line, "redis"); s["status"] != "failed" || s["required"] != false || s["error"] != "connection refused" {
+		t.Fatalf("redis = %v", s)
+	}
+	if s := depStatus(t, line, "postgres"); s["status"] != "ready" {
+		t.Fatalf("postgres = %v", s)
+	}
+}
+
+func TestReportRequiredFailureFailsFast(t *testing.T) {
+	r := NewReport("payments")
+	r.Record("consul", true, nil)
+	checkErr := r.Check("rabbitmq", true, func() error { return errors.New("dial tcp: timeout") })
+	if checkErr == nil {
+		t.Fatal("Check must return the dependency error")
+	}
+
+	line, err := logSummary(t, r)
+	if err == nil {
+		t.Fatal("Log = nil; want error for failed required dependency")
+	}
+	if line["level"] != "ERROR" || line["msg"] != "startup failed" {
+		t.Fatalf("summary = %v", line)
+	}
+	if s := depStatus(t, line, "rabbitmq"); s["status"] != "failed" || s["error"] != "dial tcp: timeout" {
+		t.Fatalf("rabbitmq = %v", s)
+	}
+
+	deps := r.Dependencies()
+	if len(deps) != 2 || deps[0].Name != "consul" || deps[1].Name != "rabbitmq" || deps[1].Ready {
+		t.Fatalf("Dependencies = %+v; want consul, rabbitmq (failed) in check order", deps)
+	}
+}
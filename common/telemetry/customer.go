@@ -0,0 +1,68 @@
+// Package telemetry: Customer ID als OpenTelemetry Baggage durch den ganzen Trace
+//
+// Warum Baggage statt nur Span Attribute im Gateway?
+// → Attribute hängen an EINEM Span → in Jaeger nur der Gateway Span per customer.id filterbar
+// → Baggage reist mit dem Trace Context mit (gRPC Metadata + AMQP Headers)
+// → Jeder Service liest die ID und taggt SEINE Spans → "Alle Spans von Kunde X"
+//
+// Voraussetzung: Propagator enthält propagation.Baggage{} (tracing.InitTracer / common.SetGlobalTracer)
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// CustomerIDKey: Baggage Key UND Span Attribute Name
+const CustomerIDKey = "customer.id"
+
+// WithCustomerID: Legt die Customer ID in die Baggage des Contexts und taggt den aktuellen Span
+// → Gateway ruft das auf, sobald die Customer ID aus dem Request bekannt ist
+func WithCustomerID(ctx context.Context, customerID string) context.Context {
+	if customerID == "" {
+		return ctx
+	}
+
+	member, err := baggage.NewMember(CustomerIDKey, customerID)
+	if err != nil {
+		// Ungültiger Baggage Value → Trace ohne Customer ID ist besser als gar kein Request
+		return ctx
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+
+	ctx = baggage.ContextWithBaggage(ctx, bag)
+	TagSpan(ctx)
+	return ctx
+}
+
+// CustomerID: Customer ID aus der Baggage ("" = nicht gesetzt)
+func CustomerID(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(CustomerIDKey).Value()
+}
+
+// TagSpan: Setzt customer.id auf den aktuellen Span, falls die Baggage eine Customer ID trägt
+// → Consumer rufen das NACH tracer.Start auf (sonst landet das Attribute am Parent)
+func TagSpan(ctx context.Context) {
+	customerID := CustomerID(ctx)
+	if customerID == "" {
+		return
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String(CustomerIDKey, customerID))
+}
+
+// UnaryServerInterceptor: Taggt den gRPC Server Span jedes Requests mit customer.id
+// Usage: grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()), grpc.ChainUnaryInterceptor(telemetry.UnaryServerInterceptor()))
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		TagSpan(ctx)
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,139 @@
+package telemetry_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/telemetry"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// setupTracing: Wie tracing.InitTracer (TraceContext + Baggage), Spans landen im Recorder statt bei Jaeger
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+// Gateway (HTTP) → Orders (gRPC) → Payments (AMQP): Customer ID kommt hinten noch an
+func TestCustomerIDSurvivesHTTPGRPCAMQPHop(t *testing.T) {
+	recorder := setupTracing(t)
+
+	// gRPC Server wie Orders: otelgrpc Server Handler + telemetry Interceptor
+	// Zweiter Interceptor spielt den Publisher: Trace Context in AMQP Header
+	published := make(chan map[string]any, 1)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			telemetry.UnaryServerInterceptor(),
+			func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				published <- broker.InjectTraceContext(ctx)
+				return handler(ctx, req)
+			},
+		),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	// HTTP Handler wie das Gateway: Customer ID aus dem Pfad → Baggage → gRPC Call
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/customers/{customerID}/orders", func(w http.ResponseWriter, r *http.Request) {
+		ctx := telemetry.WithCustomerID(r.Context(), r.PathValue("customerID"))
+		if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	gateway := httptest.NewServer(mux)
+	t.Cleanup(gateway.Close)
+
+	resp, err := http.Post(gateway.URL+"/api/customers/c42/orders", "application/json", nil)
+	if err != nil {
+		t.Fatalf("http: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d; want 200", resp.StatusCode)
+	}
+
+	// AMQP Consumer Seite: Extract aus den Headern
+	headers := <-published
+	ctx := broker.ExtractTraceContext(context.Background(), headers)
+	if got := telemetry.CustomerID(ctx); got != "c42" {
+		t.Fatalf("customer id after AMQP hop = %q; want c42", got)
+	}
+
+	// gRPC Server Span ist mit customer.id getaggt
+	// → Server beendet den Span evtl. erst NACH der Antwort an den Client → kurz warten
+	deadline := time.Now().Add(time.Second)
+	for !serverSpanTagged(recorder, "c42") {
+		if time.Now().After(deadline) {
+			t.Fatal("gRPC server span has no customer.id attribute")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func serverSpanTagged(recorder *tracetest.SpanRecorder, customerID string) bool {
+	for _, span := range recorder.Ended() {
+		if span.SpanKind() != trace.SpanKindServer {
+			continue
+		}
+		for _, attr := range span.Attributes() {
+			if string(attr.Key) == telemetry.CustomerIDKey && attr.Value.AsString() == customerID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestWithCustomerIDEmptyLeavesContext(t *testing.T) {
+	ctx := context.Background()
+	if got := telemetry.WithCustomerID(ctx, ""); got != ctx {
+		t.Fatal("empty customer id changed the context")
+	}
+	if got := telemetry.CustomerID(ctx); got != "" {
+		t.Fatalf("CustomerID = %q; want empty", got)
+	}
+}
@@ -33,6 +33,10 @@ func SetGlobalTracer(ctx context.Context, serviceName, exporterEndpoint string)
 	)
 
 	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.TraceContext{})
+	// TraceContext + Baggage → Customer ID kommt auch im Stock Service an (siehe common/telemetry)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
 	return nil
 }
@@ -88,7 +88,14 @@ func InitTracer(serviceName string) (func(), error) {
 	// → HTTP: W3C Trace Context Header
 	// → gRPC: Metadata
 	// → Flow: Gateway (Trace ID 123) → Orders (Trace ID 123) → Payment (Trace ID 123)
-	otel.SetTextMapPropagator(propagation.TraceContext{})
+	//
+	// Warum zusätzlich propagation.Baggage?
+	// → Gateway packt die Customer ID in die Baggage → reist mit dem Trace durch alle Services
+	// → Siehe common/telemetry
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
 
 	log.Printf("OpenTelemetry tracer initialized successfully for service=%s", serviceName)
 
@@ -0,0 +1,200 @@
+// Package validation: Zentrale Request Validierung für gRPC Server
+//
+// Warum Interceptor statt Checks in jedem Handler?
+// → Handler haben bisher nur manche Felder geprüft (z.B. CreateOrder ohne customer_id kam durch)
+// → Eine Stelle für alle Regeln → InvalidArgument BEVOR Handler/DB/Stripe etwas tun
+package validation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor: Lehnt ungültige Requests mit codes.InvalidArgument ab
+// Usage: grpc.NewServer(grpc.ChainUnaryInterceptor(validation.UnaryServerInterceptor()))
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := Validate(req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Validate: Prüft Pflichtfelder bekannter Request Typen
+// → Unbekannte Typen werden durchgelassen (nil)
+func Validate(req any) error {
+	switch r := req.(type) {
+	// Orders Service
+	case *api.CreateOrderRequest:
+		if r.CustomerId == "" {
+			return fmt.Errorf("customer_id is required")
+		}
+		return validateItemsWithQuantity(r.Items, false)
+	case *api.GetOrderRequest:
+		if r.OrderId == "" {
+			return fmt.Errorf("order_id is required")
+		}
+	case *api.GetOrdersBySessionRequest:
+		if r.SessionId == "" {
+			return fmt.Errorf("session_id is required")
+		}
+	case *api.GetOrdersByCustomerRequest:
+		if r.CustomerId == "" {
+			return fmt.Errorf("customer_id is required")
+		}
+		if r.Status != "" && !orderstatus.IsValid(r.Status) {
+			return fmt.Errorf("unknown status %q", r.Status)
+		}
+		_, _, err := ParseTimeRange(r.Since, r.Until)
+		return err
+	case *api.Order:
+		// UpdateOrder: Kitchen sendet nur ID + Status (ohne customer_id)
+		if r.Id == "" {
+			return fmt.Errorf("id is required")
+		}
+	case *api.GetOrdersByStatusRequest:
+		if r.Limit < 0 {
+			return fmt.Errorf("limit must not be negative, got %d", r.Limit)
+		}
+	case *api.GetStuckOrdersRequest:
+		if r.OlderThanSeconds < 0 {
+			return fmt.Errorf("older_than_seconds must not be negative, got %d", r.OlderThanSeconds)
+		}
+	case *api.CancelOrderRequest:
+		if r.OrderId == "" {
+			return fmt.Errorf("order_id is required")
+		}
+	case *api.CancelCustomerUnpaidOrdersRequest:
+		if r.CustomerId == "" {
+			return fmt.Errorf("customer_id is required")
+		}
+	case *api.AdjustOrderItemsRequest:
+		if r.OrderId == "" {
+			return fmt.Errorf("order_id is required")
+		}
+		// 0 = Item entfernen → erlaubt
+		return validateItemsWithQuantity(r.Items, true)
+
+	// Stock Service
+	case *api.CheckIfItemIsInStockRequest:
+		return validateItemsWithQuantity(r.Items, false)
+	case *api.ReserveStockRequest:
+		if r.OrderID == "" {
+			return fmt.Errorf("OrderID is required")
+		}
+		return validateItems(r.Items)
+	case *api.RenewReservationRequest:
+		if r.OrderID == "" {
+			return fmt.Errorf("OrderID is required")
+		}
+		return validateItems(r.Items)
+	case *api.GetInventorySummaryRequest:
+		if r.LowStockThreshold < 0 {
+			return fmt.Errorf("LowStockThreshold must not be negative, got %d", r.LowStockThreshold)
+		}
+		if !InventorySortOptions[r.SortBy] {
+			return fmt.Errorf("unknown SortBy %q", r.SortBy)
+		}
+	case *api.BulkCreateItemsRequest:
+		// Zeilen selbst prüft der Stock Service (Fehler pro Zeile statt EINEM InvalidArgument)
+		if len(r.Items) == 0 {
+			return fmt.Errorf("items must not be empty")
+		}
+	case *api.RestockItemsRequest:
+		if r.OrderID == "" {
+			return fmt.Errorf("OrderID is required")
+		}
+		return validateItemsWithQuantity(r.Items, false)
+	case *api.ToggleItemAvailabilityRequest:
+		if r.ID == "" {
+			return fmt.Errorf("ID is required")
+		}
+	case *api.ReleaseReservationRequest:
+		if r.OrderID == "" {
+			return fmt.Errorf("OrderID is required")
+		}
+	case *api.ConfirmReservationRequest:
+		if r.OrderID == "" {
+			return fmt.Errorf("OrderID is required")
+		}
+	case *api.GetReservationRequest:
+		if r.OrderID == "" {
+			return fmt.Errorf("OrderID is required")
+		}
+	}
+
+	return nil
+}
+
+func validateItemsWithQuantity(items []*api.ItemsWithQuantity, allowZero bool) error {
+	if len(items) == 0 {
+		return fmt.Errorf("items must not be empty")
+	}
+	for i, item := range items {
+		if item.ID == "" {
+			return fmt.Errorf("items[%d]: ID is required", i)
+		}
+		if item.Quantity < 0 || (item.Quantity == 0 && !allowZero) {
+			return fmt.Errorf("items[%d]: quantity must be positive, got %d", i, item.Quantity)
+		}
+	}
+	return nil
+}
+
+func validateItems(items []*api.Item) error {
+	if len(items) == 0 {
+		return fmt.Errorf("items must not be empty")
+	}
+	for i, item := range items {
+		if item.ID == "" {
+			return fmt.Errorf("items[%d]: ID is required", i)
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("items[%d]: quantity must be positive, got %d", i, item.Quantity)
+		}
+	}
+	return nil
+}
+
+// ParseTimeRange: Optionales RFC3339 Zeitfenster [since, until) → Zero Time = offen
+// → Exportiert: Handler braucht die geparsten Werte, Interceptor nur den Fehler
+func ParseTimeRange(since, until string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	if since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("since must be RFC3339, got %q", since)
+		}
+		from = t
+	}
+	if until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("until must be RFC3339, got %q", until)
+		}
+		to = t
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since must be before until")
+	}
+	return from, to, nil
+}
+
+// InventorySortOptions: Erlaubte Werte für GetInventorySummaryRequest.SortBy ("" = Default "id")
+// Warum Whitelist?
+// → Stock baut daraus das ORDER BY → nie Client-Strings in SQL
+var InventorySortOptions = map[string]bool{
+	"":               true,
+	"id":             true,
+	"name":           true,
+	"available":      true,
+	"available_desc": true,
+}
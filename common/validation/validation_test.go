@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/timour/order-microservices/common/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidate(t *testing.T) {
+	items := []*api.ItemsWithQuantity{{ID: "1", Quantity: 2}}
+
+	tests := []struct {
+		name    string
+		req     any
+		wantErr bool
+	}{
+		{"create order ok", &api.CreateOrderRequest{CustomerId: "c1", Items: items}, false},
+		{"create order without customer", &api.CreateOrderRequest{Items: items}, true},
+		{"create order without items", &api.CreateOrderRequest{CustomerId: "c1"}, true},
+		{"create order zero quantity", &api.CreateOrderRequest{CustomerId: "c1", Items: []*api.ItemsWithQuantity{{ID: "1"}}}, true},
+		{"create order negative quantity", &api.CreateOrderRequest{CustomerId: "c1", Items: []*api.ItemsWithQuantity{{ID: "1", Quantity: -1}}}, true},
+		{"create order item without id", &api.CreateOrderRequest{CustomerId: "c1", Items: []*api.ItemsWithQuantity{{Quantity: 1}}}, true},
+		{"adjust allows removing an item", &api.AdjustOrderItemsRequest{OrderId: "o1", Items: []*api.ItemsWithQuantity{{ID: "1"}}}, false},
+		{"update order without id", &api.Order{Status: "ready"}, true},
+		{"customer orders unknown status", &api.GetOrdersByCustomerRequest{CustomerId: "c1", Status: "shipped"}, true},
+		{"customer orders inverted range", &api.GetOrdersByCustomerRequest{CustomerId: "c1", Since: "2026-02-01T00:00:00Z", Until: "2026-01-01T00:00:00Z"}, true},
+		{"reserve stock ok", &api.ReserveStockRequest{OrderID: "o1", Items: []*api.Item{{ID: "1", Quantity: 1}}}, false},
+		{"reserve stock without order", &api.ReserveStockRequest{Items: []*api.Item{{ID: "1", Quantity: 1}}}, true},
+		{"reserve stock zero quantity", &api.ReserveStockRequest{OrderID: "o1", Items: []*api.Item{{ID: "1"}}}, true},
+		{"inventory unknown sort", &api.GetInventorySummaryRequest{SortBy: "price; DROP TABLE items"}, true},
+		{"unknown request type passes", &api.GetItemsRequest{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Ungültiger Request → InvalidArgument, Handler läuft NICHT
+func TestUnaryServerInterceptorRejectsInvalidRequest(t *testing.T) {
+	called := false
+	handler := func(context.Context, any) (any, error) {
+		called = true
+		return nil, nil
+	}
+
+	_, err := UnaryServerInterceptor()(context.Background(), &api.CreateOrderRequest{}, &grpc.UnaryServerInfo{}, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("code = %v; want InvalidArgument", status.Code(err))
+	}
+	if called {
+		t.Fatal("handler ran for an invalid request")
+	}
+}
+
+func TestUnaryServerInterceptorPassesValidRequest(t *testing.T) {
+	req := &api.CreateOrderRequest{CustomerId: "c1", Items: []*api.ItemsWithQuantity{{ID: "1", Quantity: 1}}}
+	handler := func(_ context.Context, got any) (any, error) {
+		return got, nil
+	}
+
+	resp, err := UnaryServerInterceptor()(context.Background(), req, &grpc.UnaryServerInfo{}, handler)
+	if err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+	if resp != req {
+		t.Fatal("handler did not receive the original request")
+	}
+}
@@ -2,17 +2,37 @@ package consul
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 	"github.com/timour/order-microservices/discovery"
 )
 
+// discoverCacheTTL: Wie lange die letzten erfolgreichen Discover Ergebnisse als Fallback gelten
+// Warum?
+// → Consul Agent kurz weg (Restart, Netzwerk-Blip) → JEDER CreateOrder würde fehlschlagen
+// → Obwohl Stock/Orders selbst gesund sind!
+// → Kurzer TTL: Nach 30s ohne Consul wissen wir nicht mehr welche Instanzen leben
+const discoverCacheTTL = 30 * time.Second
+
+type cachedInstances struct {
+	addrs     []string
+	fetchedAt time.Time
+}
+
 type Registry struct {
 	client *consul.Client
+
+	mu        sync.Mutex
+	lastKnown map[string]cachedInstances // serviceName → letzte gesunde Instanzen
+	now       func() time.Time           // Injizierbar → Tests ohne Sleep
 }
 
 func NewRegistry(addr string) (*Registry, error) {
@@ -24,7 +44,11 @@ func NewRegistry(addr string) (*Registry, error) {
 		return nil, err
 	}
 
-	return &Registry{client: client}, nil
+	return &Registry{
+		client:    client,
+		lastKnown: make(map[string]cachedInstances),
+		now:       time.Now,
+	}, nil
 }
 
 func (r *Registry) Register(ctx context.Context, instanceID, serviceName, hostPort string) error {
@@ -58,7 +82,23 @@ func (r *Registry) Deregister(ctx context.Context, instanceID, serviceName strin
 }
 
 func (r *Registry) Discover(ctx context.Context, serviceName string) ([]string, error) {
-	services, _, err := r.client.Health().Service(serviceName, "", true, nil)
+	addresses, err := r.ServiceAddresses(ctx, serviceName)
+	if err != nil {
+		if cached, ok := r.cachedDiscover(serviceName); ok {
+			log.Printf("Consul unreachable, using cached instances of %s: %v", serviceName, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// ServiceAddresses: Nur Instances deren TTL Check in Consul gerade "passing" ist
+// Warum ohne Cache Fallback (im Gegensatz zu Discover)?
+// → Gleiche Semantik wie inmem.ServiceAddresses: Antwort = JETZT gesund, nie veraltet
+// → Erfolgreiche Antwort füllt aber den Cache → Discover hat bei einem Consul Ausfall etwas zum Zurückfallen
+func (r *Registry) ServiceAddresses(ctx context.Context, serviceName string) ([]string, error) {
+	services, _, err := r.client.Health().Service(serviceName, "", true, (&consul.QueryOptions{}).WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -69,11 +109,54 @@ func (r *Registry) Discover(ctx context.Context, serviceName string) ([]string,
 			service.Service.Address, service.Service.Port))
 	}
 
+	r.storeDiscover(serviceName, addresses)
 	return addresses, nil
 }
 
+// cachedDiscover: Letztes erfolgreiches Discover Ergebnis, falls jünger als discoverCacheTTL
+func (r *Registry) cachedDiscover(serviceName string) ([]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached, ok := r.lastKnown[serviceName]
+	if !ok || r.now().Sub(cached.fetchedAt) > discoverCacheTTL {
+		return nil, false
+	}
+	return cached.addrs, true
+}
+
+// storeDiscover: Nur nicht-leere Ergebnisse merken
+// → "Keine gesunde Instanz" ist eine echte Antwort, kein Fallback-Kandidat
+func (r *Registry) storeDiscover(serviceName string, addrs []string) {
+	if len(addrs) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastKnown[serviceName] = cachedInstances{addrs: addrs, fetchedAt: r.now()}
+}
+
 func (r *Registry) HealthCheck(instanceID, serviceName string) error {
-	return r.client.Agent().UpdateTTL(instanceID, "online", consul.HealthPassing)
+	err := r.client.Agent().UpdateTTL(instanceID, "online", consul.HealthPassing)
+	if isUnknownCheck(err) {
+		return fmt.Errorf("%w: %s (%v)", discovery.ErrNotRegistered, instanceID, err)
+	}
+	return err
+}
+
+// isUnknownCheck: Erkennt "Check existiert nicht" Antworten vom Consul Agent
+// → Neuere Consul Versionen: 404 "Unknown check ID"
+// → Ältere Versionen: 500 "CheckID ... does not have associated TTL"
+func isUnknownCheck(err error) bool {
+	var statusErr consul.StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	return statusErr.Code == http.StatusNotFound ||
+		strings.Contains(statusErr.Body, "Unknown check") ||
+		strings.Contains(statusErr.Body, "does not have associated TTL")
 }
 
 var _ discovery.Registry = (*Registry)(nil)
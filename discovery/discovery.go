@@ -2,15 +2,25 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"time"
 )
 
+// ErrNotRegistered: Registry kennt die Instance nicht (mehr)
+// Warum ein Sentinel Error?
+// → Nach einem Consul Restart ist die Registration weg, HealthCheck schlägt fehl
+// → Caller kann mit errors.Is() unterscheiden: "Consul down" vs. "neu registrieren!"
+var ErrNotRegistered = errors.New("instance not registered")
+
 type Registry interface {
 	Register(ctx context.Context, instanceID, serviceName, hostPort string) error
 	Deregister(ctx context.Context, instanceID, serviceName string) error
 	Discover(ctx context.Context, serviceName string) ([]string, error)
+	// ServiceAddresses: Wie Discover, aber NUR Instances deren Health Check JETZT gesund ist
+	// → Kein Fallback auf gecachte/veraltete Ergebnisse (inmem: lastActive TTL, Consul: passing Checks)
+	ServiceAddresses(ctx context.Context, serviceName string) ([]string, error)
 	HealthCheck(instanceID, serviceName string) error
 }
 
@@ -5,58 +5,170 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// dialTimeout: Wie lange wir auf EINE Instance warten bevor wir die nächste probieren
+const dialTimeout = 2 * time.Second
+
 // ServiceConnection: Zentrale gRPC Connection Helper mit OpenTelemetry
 // Warum dieser Helper?
 // → DRY: Alle Services nutzen gleichen Code (keine Duplication!)
 // → OpenTelemetry: Middleware ist ZENTRAL implementiert
-// → Load Balancing: Random selection wenn mehrere Instances
-// → Service Discovery: Nutzt Registry.Discover()
+// → Load Balancing: round_robin über ALLE Instances (Client-side, pro RPC)
+// → Service Discovery: Nutzt Registry.ServiceAddresses() (nur gesunde Instances) + periodisches Re-Resolve (registryResolver)
 //
 // Usage:
 // conn, err := discovery.ServiceConnection(ctx, "orders", registry)
 // if err != nil { ... }
 // defer conn.Close()
 // client := api.NewOrderServiceClient(conn)
-func ServiceConnection(ctx context.Context, serviceName string, registry Registry) (*grpc.ClientConn, error) {
-	// Warum registry.Discover?
-	// → Findet alle verfügbaren Instances des Services
+//
+// opts: Zusätzliche Dial Options des Aufrufers (z.B. Metrics Interceptor im Gateway)
+func ServiceConnection(ctx context.Context, serviceName string, registry Registry, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	// Findet alle GESUNDEN Instances des Services
 	// → z.B. ["localhost:9000", "localhost:9001"] (wenn 2 Instances)
-	addrs, err := registry.Discover(ctx, serviceName)
+	addrs, err := healthyAddresses(ctx, registry, serviceName)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(addrs) == 0 {
-		return nil, fmt.Errorf("no instances found for service %s", serviceName)
+		return nil, fmt.Errorf("no healthy instances found for service %s", serviceName)
 	}
 
 	log.Printf("Discovered %d instances of %s", len(addrs), serviceName)
 
+	if sp, ok := registry.(SinglePicker); ok && sp.SinglePick() {
+		return dialRandomInstance(ctx, serviceName, addrs, opts...)
+	}
+
+	// ⭐ Client-side Load Balancing:
+	// → Vorher: rand.Intn EINMAL beim Dial → alle RPCs dieser Connection auf EINE Instance
+	// → Jetzt: EINE ClientConn mit Subchannels zu allen Instances, round_robin verteilt jeden RPC
+	// → Resolver holt die Instances alle DefaultResolveInterval neu (Scale-Out ohne Reconnect)
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithResolvers(&registryResolverBuilder{
+			registry: registry,
+			initial:  addrs,
+			interval: DefaultResolveInterval,
+		}),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+	}, opts...)
+	conn, err := grpc.NewClient(registryScheme+":///"+serviceName, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Warum trotzdem auf READY warten?
+	// → round_robin ist READY sobald EINE Instance erreichbar ist
+	// → Sind ALLE tot, soll der Aufrufer das jetzt merken und nicht erst beim ersten RPC
+	if err := waitReady(ctx, conn); err != nil {
+		return nil, fmt.Errorf("all %d instances of %s unreachable: %w", len(addrs), serviceName, err)
+	}
+	return conn, nil
+}
+
+// healthyAddresses: registry.ServiceAddresses bevorzugt (nur Instances mit gesundem Health Check)
+// Warum Fallback auf Discover bei Fehler?
+// → Consul kurz nicht erreichbar → Discover liefert die zuletzt bekannten Instances (Cache)
+// → Leere Liste ist dagegen eine echte Antwort ("keine gesunde Instance") → KEIN Fallback
+func healthyAddresses(ctx context.Context, registry Registry, serviceName string) ([]string, error) {
+	addrs, err := registry.ServiceAddresses(ctx, serviceName)
+	if err == nil {
+		return addrs, nil
+	}
+
+	log.Printf("ServiceAddresses for %s failed, falling back to Discover: %v", serviceName, err)
+	return registry.Discover(ctx, serviceName)
+}
+
+// SinglePicker: Optional von einer Registry implementiert
+// SinglePick() == true → KEIN round_robin, sondern der alte Modus:
+// Random Instance beim Dial + Failover auf die nächste (inmem Registry in Tests)
+type SinglePicker interface {
+	SinglePick() bool
+}
+
+// dialRandomInstance: Fallback Modus (SinglePicker) - EINE Instance pro Connection
+func dialRandomInstance(ctx context.Context, serviceName string, addrs []string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	// Warum rand.Intn?
-	// → Simple Load Balancing: Random Instance auswählen
-	// → Production: Könnte Round-Robin, Least-Connections, etc. sein
-	selectedAddr := addrs[rand.Intn(len(addrs))]
-
-	// Warum grpc.Dial (deprecated) statt grpc.NewClient?
-	// → NewClient ist non-blocking (wartet nicht auf Connection)
-	// → Dial ist blocking (wartet bis connected oder timeout)
-	// → Für Service Discovery besser: Sofort wissen ob Service erreichbar!
-	//
+	// → Simple Load Balancing: Random Start-Instance auswählen
+	start := rand.Intn(len(addrs))
+
+	// Warum ALLE Instances der Reihe nach probieren?
+	// → Eine tote Instance (Consul hat sie noch nicht entfernt) soll nicht
+	//   den ganzen Call killen, wenn die anderen Instances gesund sind!
+	var lastErr error
+	for i := range addrs {
+		addr := addrs[(start+i)%len(addrs)]
+
+		conn, err := dialInstance(ctx, addr, opts...)
+		if err == nil {
+			return conn, nil
+		}
+
+		log.Printf("Failed to connect to %s instance %s: %v", serviceName, addr, err)
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("all %d instances of %s unreachable: %w", len(addrs), serviceName, lastErr)
+}
+
+// dialInstance: Verbindet zu EINER Instance und wartet bis sie READY ist
+// Warum warten?
+// → DialContext ohne WithBlock ist non-blocking (wartet nicht auf Connection)
+// → Ohne Warten merken wir eine tote Instance erst beim ersten RPC - zu spät für Fallback!
+// → dialTimeout begrenzt wie lange EINE Instance uns aufhalten darf
+func dialInstance(ctx context.Context, addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	// ⭐ OpenTelemetry Middleware:
 	// → UnaryClientInterceptor: Für normale RPC Calls (CreateOrder, UpdateOrder, etc.)
 	// → StreamClientInterceptor: Für Streaming RPCs (falls wir später haben)
 	// → Automatisches Tracing: Span wird automatisch erstellt für jeden RPC!
-	return grpc.DialContext(
-		ctx,
-		selectedAddr,
+	dialOpts := append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		// ⭐ OpenTelemetry Interceptors - DAS IST DER GAME CHANGER!
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
-	)
+	}, opts...)
+	conn, err := grpc.DialContext(ctx, addr, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitReady(ctx, conn); err != nil {
+		return nil, fmt.Errorf("instance %s %w", addr, err)
+	}
+	return conn, nil
+}
+
+// waitReady: Wartet bis conn READY ist (max dialTimeout), schließt conn bei Fehler
+func waitReady(ctx context.Context, conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			// Warum sofort aufgeben?
+			// → Connection refused o.ä. → nächste Instance statt Timeout abwarten
+			conn.Close()
+			return fmt.Errorf("is %s", state)
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			conn.Close()
+			return fmt.Errorf("not ready: %w", ctx.Err())
+		}
+	}
 }
@@ -0,0 +1,158 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// staticRegistry: Feste Adressliste, optional mit SinglePick (wie inmem)
+type staticRegistry struct {
+	addrs  []string
+	single bool
+}
+
+func (r *staticRegistry) Register(context.Context, string, string, string) error { return nil }
+func (r *staticRegistry) Deregister(context.Context, string, string) error       { return nil }
+func (r *staticRegistry) HealthCheck(string, string) error                       { return nil }
+func (r *staticRegistry) Discover(context.Context, string) ([]string, error) {
+	return r.addrs, nil
+}
+func (r *staticRegistry) ServiceAddresses(context.Context, string) ([]string, error) {
+	return r.addrs, nil
+}
+func (r *staticRegistry) SinglePick() bool { return r.single }
+
+// startInstance: gRPC Server mit Health Service, zählt eingehende RPCs
+func startInstance(t *testing.T) (string, *atomic.Int64) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	var calls atomic.Int64
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		calls.Add(1)
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), &calls
+}
+
+func callN(t *testing.T, conn *grpc.ClientConn, n int) {
+	t.Helper()
+	client := healthpb.NewHealthClient(conn)
+	for i := 0; i < n; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+	}
+}
+
+func TestServiceConnectionRoundRobin(t *testing.T) {
+	addrA, callsA := startInstance(t)
+	addrB, callsB := startInstance(t)
+
+	conn, err := ServiceConnection(context.Background(), "orders", &staticRegistry{addrs: []string{addrA, addrB}})
+	if err != nil {
+		t.Fatalf("ServiceConnection: %v", err)
+	}
+	defer conn.Close()
+
+	callN(t, conn, 20)
+
+	// round_robin: Beide Instances bekommen Traffic (nicht alle RPCs auf EINER)
+	if callsA.Load() == 0 || callsB.Load() == 0 {
+		t.Fatalf("calls not balanced: a=%d b=%d", callsA.Load(), callsB.Load())
+	}
+}
+
+func TestServiceConnectionSinglePick(t *testing.T) {
+	addrA, callsA := startInstance(t)
+	addrB, callsB := startInstance(t)
+
+	conn, err := ServiceConnection(context.Background(), "orders", &staticRegistry{addrs: []string{addrA, addrB}, single: true})
+	if err != nil {
+		t.Fatalf("ServiceConnection: %v", err)
+	}
+	defer conn.Close()
+
+	callN(t, conn, 10)
+
+	// SinglePick: Alle RPCs einer Connection landen auf derselben Instance
+	if a, b := callsA.Load(), callsB.Load(); a != 0 && b != 0 {
+		t.Fatalf("single pick spread calls: a=%d b=%d", a, b)
+	}
+}
+
+func TestServiceConnectionFailsWhenAllInstancesDown(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	dead := lis.Addr().String()
+	lis.Close()
+
+	if conn, err := ServiceConnection(context.Background(), "orders", &staticRegistry{addrs: []string{dead}}); err == nil {
+		conn.Close()
+		t.Fatal("ServiceConnection succeeded without a reachable instance")
+	}
+}
+
+// deadAddr: Adresse auf der garantiert niemand lauscht (Dial schlägt sofort fehl)
+func deadAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+// Erste Instance tot → die zweite wird genutzt, egal bei welcher Instance rand.Intn startet
+func TestDialRandomInstanceFallsBackToNextInstance(t *testing.T) {
+	dead := deadAddr(t)
+	live, calls := startInstance(t)
+
+	for i := 0; i < 10; i++ {
+		conn, err := dialRandomInstance(context.Background(), "orders", []string{dead, live})
+		if err != nil {
+			t.Fatalf("dialRandomInstance: %v", err)
+		}
+		if target := conn.Target(); target != live {
+			t.Fatalf("connected to %s; want %s", target, live)
+		}
+		callN(t, conn, 1)
+		conn.Close()
+	}
+	if calls.Load() != 10 {
+		t.Fatalf("live instance got %d calls; want 10", calls.Load())
+	}
+}
+
+func TestServiceConnectionSkipsDeadInstance(t *testing.T) {
+	live, calls := startInstance(t)
+
+	conn, err := ServiceConnection(context.Background(), "orders", &staticRegistry{addrs: []string{deadAddr(t), live}})
+	if err != nil {
+		t.Fatalf("ServiceConnection: %v", err)
+	}
+	defer conn.Close()
+
+	callN(t, conn, 5)
+	if calls.Load() != 5 {
+		t.Fatalf("live instance got %d calls; want 5", calls.Load())
+	}
+}
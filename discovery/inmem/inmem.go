@@ -133,5 +133,14 @@ func (r *Registry) ServiceAddresses(ctx context.Context, serviceName string) ([]
 	return res, nil
 }
 
+// SinglePick: Kein round_robin Balancer für inmem (siehe discovery.SinglePicker)
+// Warum?
+// → Tests registrieren oft Fake Adressen → Random Pick + Failover ist deterministischer
+// → Ein Balancer baut im Hintergrund Subchannels auf und re-resolved
+func (r *Registry) SinglePick() bool {
+	return true
+}
+
 // Compile-time check: Verify Registry implements discovery.Registry
 var _ discovery.Registry = (*Registry)(nil)
+var _ discovery.SinglePicker = (*Registry)(nil)
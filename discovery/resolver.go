@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// registryScheme: Target "registry:///orders" → Adressen kommen aus der Registry (healthyAddresses)
+const registryScheme = "registry"
+
+// DefaultResolveInterval: Wie oft der Resolver die Instances neu aus der Registry holt
+// → Neue Instances bekommen Traffic ohne Reconnect, abgemeldete fallen raus
+const DefaultResolveInterval = 30 * time.Second
+
+// resolveTimeout: Budget für EINEN Registry Lookup im Hintergrund
+const resolveTimeout = 5 * time.Second
+
+// registryResolverBuilder: gRPC Resolver Builder für EINE Registry
+// Warum pro Connection statt global registriert (resolver.Register)?
+// → Jeder Service hat seine eigene Registry Instanz (Consul, inmem) → grpc.WithResolvers
+type registryResolverBuilder struct {
+	registry Registry
+	initial  []string // Schon aus ServiceConnection bekannt → kein zweiter Discover beim Dial
+	interval time.Duration
+}
+
+func (b *registryResolverBuilder) Scheme() string { return registryScheme }
+
+func (b *registryResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &registryResolver{
+		registry:    b.registry,
+		serviceName: target.Endpoint(),
+		cc:          cc,
+		cancel:      cancel,
+		resolveNow:  make(chan struct{}, 1),
+	}
+
+	if err := r.update(b.initial); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	r.wg.Add(1)
+	go r.watch(ctx, b.interval)
+	return r, nil
+}
+
+// registryResolver: Hält die Adressliste des round_robin Balancers aktuell
+type registryResolver struct {
+	registry    Registry
+	serviceName string
+	cc          resolver.ClientConn
+	cancel      context.CancelFunc
+	resolveNow  chan struct{}
+	wg          sync.WaitGroup
+}
+
+// watch: Alle interval (oder bei ResolveNow) neu auflösen, bis Close
+func (r *registryResolver) watch(ctx context.Context, interval time.Duration) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+		r.resolve(ctx)
+	}
+}
+
+// resolve: healthyAddresses → neue Adressliste
+// Warum bei Fehlern die alte Liste behalten?
+// → Consul kurz nicht erreichbar ≠ alle Instances tot → laufende Subchannels weiter nutzen
+func (r *registryResolver) resolve(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	addrs, err := healthyAddresses(ctx, r.registry, r.serviceName)
+	if err != nil || len(addrs) == 0 {
+		log.Printf("Failed to re-resolve %s, keeping previous instances: %v", r.serviceName, err)
+		return
+	}
+	if err := r.update(addrs); err != nil {
+		log.Printf("Failed to update %s instances: %v", r.serviceName, err)
+	}
+}
+
+func (r *registryResolver) update(addrs []string) error {
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(addrs))}
+	for _, addr := range addrs {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+	return r.cc.UpdateState(state)
+}
+
+// ResolveNow: gRPC meldet eine kaputte Verbindung → sofort neu auflösen (nicht blockierend)
+func (r *registryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *registryResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// requireAdmin: Admin Endpoints nur mit "Authorization: Bearer <ADMIN_TOKEN>"
+// Warum Fail-Closed?
+// → ADMIN_TOKEN nicht gesetzt → Endpoint ist aus (403) statt offen für jeden
+// Warum subtle.ConstantTimeCompare?
+// → Normaler Stringvergleich bricht beim ersten falschen Zeichen ab → Token per Timing erratbar
+func (h *handler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.adminToken == "" {
+			http.Error(w, "admin endpoints are disabled (ADMIN_TOKEN not set)", http.StatusForbidden)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			h.logger.Warn("unauthorized admin request",
+				slog.String("path", r.URL.Path),
+				slog.String("client_ip", h.trustedProxies.ClientIP(r)),
+			)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
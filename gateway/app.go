@@ -9,8 +9,11 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
 	"github.com/timour/order-microservices/common/logger"
 	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/startup"
 	"github.com/timour/order-microservices/discovery"
 	"github.com/timour/order-microservices/discovery/consul"
 )
@@ -20,8 +23,13 @@ type App struct {
 	httpServer   *http.Server
 	registration *ServiceRegistration
 	config       Config
+	handler      *handler // Hält die geteilten gRPC Connections → Shutdown schließt sie
 	logger       *slog.Logger
 	metrics      *metrics.HTTPMetrics
+
+	// channel: RabbitMQ für Live Updates (nil = nicht verbunden → SSE Endpoint antwortet 503)
+	channel       *amqp.Channel
+	closeRabbitMQ func() error
 }
 
 type Config struct {
@@ -29,20 +37,63 @@ type Config struct {
 	InstanceID  string
 	HTTPAddr    string
 	ConsulAddr  string
+	// PaymentsAddr: Payments HTTP Server für Payment Link Re-Issue
+	PaymentsAddr string
+	// PaymentsToken: Muss zu PAYMENTS_INTERNAL_TOKEN im Payments Service passen (sonst 401/403)
+	PaymentsToken string
+	// RegisterBackoff: Retry-Verhalten für die Consul Registration beim Start
+	RegisterBackoff RegisterBackoff
+	// MenuEnrichmentTimeout: Budget für Stripe Daten im Menu (0 = DefaultMenuEnrichmentTimeout)
+	MenuEnrichmentTimeout time.Duration
+
+	// MenuCacheTTL: Wie lange Stripe Daten eines Items ohne neuen Stripe Call genutzt werden (0 = kein Cache, nur Fallback)
+	MenuCacheTTL time.Duration
+	// AdminToken: Bearer Token für geschützte Admin Endpoints ("" = gesperrt)
+	AdminToken string
+
+	// RabbitMQ: order.preparing/order.ready → SSE Stream des Kunden
+	AMQPUser string
+	AMQPPass string
+	AMQPHost string
+	AMQPPort string
+	// DLQReplayEnabled: POST /api/admin/dlq/{queue}/replay erlaubt (Default aus → nur Peek)
+	DLQReplayEnabled bool
+	// TrustedProxies: Nur hinter diesen Proxies zählt X-Forwarded-For / X-Real-IP als Client IP (leer = RemoteAddr)
+	TrustedProxies TrustedProxies
+
+	// ConnectAccounts: Restaurant → Stripe Connect Account (?restaurant= beim Bestellen, leer = nur Plattform)
+	ConnectAccounts ConnectAccounts
+	// RateLimitPerMinute: Requests pro Client IP und Minute (0 = kein Limit)
+	RateLimitPerMinute int
 }
 
-func NewApp(config Config) (*App, error) {
+func NewApp(config Config, report *startup.Report) (*App, error) {
 	log := logger.NewLogger(config.ServiceName)
 
 	registry, err := createRegistry(config.ConsulAddr, log)
+	report.Record("consul", true, err)
 	if err != nil {
 		return nil, err
 	}
 
+	// Warum optional?
+	// → Ohne RabbitMQ fehlen nur die Live Updates, Bestellen/Bezahlen läuft weiter über gRPC
+	var ch *amqp.Channel
+	var closeRabbitMQ func() error
+	err = report.Check("rabbitmq", false, func() (err error) {
+		ch, closeRabbitMQ, err = broker.Connect(config.AMQPUser, config.AMQPPass, config.AMQPHost, config.AMQPPort)
+		return err
+	})
+	if err != nil {
+		log.Warn("rabbitmq unavailable, live order updates disabled", slog.Any("error", err))
+	}
+
 	return &App{
-		registry: registry,
-		config:   config,
-		logger:   log,
+		registry:      registry,
+		config:        config,
+		logger:        log,
+		channel:       ch,
+		closeRabbitMQ: closeRabbitMQ,
 	}, nil
 }
 
@@ -60,6 +111,7 @@ func (a *App) Start(ctx context.Context) error {
 			a.config.InstanceID,
 			a.config.ServiceName,
 			a.config.HTTPAddr,
+			a.config.RegisterBackoff,
 		)
 		if err != nil {
 			return err
@@ -72,14 +124,27 @@ func (a *App) Start(ctx context.Context) error {
 
 	// 4. Setup HTTP Server
 	mux := http.NewServeMux()
-	handler := NewHandler(a.registry, a.logger)
+	handler := NewHandler(a.registry, a.logger, a.config.PaymentsAddr, metrics.NewUpstreamMetrics(a.config.ServiceName), metrics.NewMenuMetrics(a.config.ServiceName), a.config.MenuEnrichmentTimeout, a.config.MenuCacheTTL, a.config.AdminToken)
+	handler.trustedProxies = a.config.TrustedProxies
+	handler.paymentsToken = a.config.PaymentsToken
+	handler.connectAccounts = a.config.ConnectAccounts
 	handler.registerRoute(mux)
+	a.handler = handler
+
+	// 5. Live Order Updates (SSE): order.preparing / order.ready → Stream des Kunden
+	mux.HandleFunc("GET /api/customers/{customerID}/events", a.liveUpdates())
+
+	// 6. DLQ Admin: Peek + Replay (Admin Token, Replay zusätzlich hinter DLQ_REPLAY_ENABLED)
+	dlq := newDLQAdmin(a.dlqConnect, a.config.DLQReplayEnabled, a.config.TrustedProxies, a.logger)
+	mux.HandleFunc("GET /api/admin/dlq/{queue}", handler.requireAdmin(dlq.handlePeek))
+	mux.HandleFunc("POST /api/admin/dlq/{queue}/replay", handler.requireAdmin(dlq.handleReplay))
 
 	// Add /metrics endpoint for Prometheus scraping
 	mux.Handle("GET /metrics", promhttp.Handler())
 
-	// Wrap mux with CORS + metrics middleware
-	metricsHandler := a.metricsMiddleware(mux)
+	// Wrap mux with CORS + metrics + per-client rate limit middleware
+	limiter := newClientRateLimiter(a.config.RateLimitPerMinute, a.config.TrustedProxies, a.logger)
+	metricsHandler := a.metricsMiddleware(limiter.middleware(mux))
 	corsHandler := a.corsMiddleware(metricsHandler)
 
 	a.httpServer = &http.Server{
@@ -100,12 +165,58 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if a.handler != nil {
+		if err := a.handler.Close(); err != nil {
+			a.logger.Error("error closing service connections", slog.Any("error", err))
+		}
+	}
+
+	if a.closeRabbitMQ != nil {
+		if err := a.closeRabbitMQ(); err != nil {
+			a.logger.Error("error closing rabbitmq", slog.Any("error", err))
+		}
+	}
+
 	if a.registration != nil {
 		return a.registration.Deregister(ctx)
 	}
 	return nil
 }
 
+// liveUpdates: SSE Handler + Consumer für liveOrderEvents
+// → Kein RabbitMQ (oder Consumer Setup fehlgeschlagen) → 503 statt eines Streams, der nie ein Event bekommt
+func (a *App) liveUpdates() http.HandlerFunc {
+	unavailable := func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "live order updates unavailable", http.StatusServiceUnavailable)
+	}
+	if a.channel == nil {
+		return unavailable
+	}
+
+	hub := newOrderEventHub()
+	consumer := NewOrderEventConsumer(hub, a.logger)
+	for _, event := range liveOrderEvents {
+		if err := consumer.Listen(a.channel, event); err != nil {
+			a.logger.Error("failed to start live order events consumer",
+				slog.String("event", event),
+				slog.Any("error", err),
+			)
+			return unavailable
+		}
+	}
+
+	return hub.handleStream
+}
+
+// dlqConnect: Frische RabbitMQ Verbindung für den DLQ Admin (siehe dlqConnector)
+func (a *App) dlqConnect() (broker.DLQChannel, func() error, error) {
+	ch, closeConn, err := broker.Connect(a.config.AMQPUser, a.config.AMQPPass, a.config.AMQPHost, a.config.AMQPPort)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, closeConn, nil
+}
+
 func createRegistry(addr string, log *slog.Logger) (discovery.Registry, error) {
 	if addr == "" {
 		log.Info("consul address not provided, service discovery disabled")
@@ -152,6 +263,11 @@ func (rec *responseRecorder) WriteHeader(code int) {
 	rec.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap: http.ResponseController erreicht den echten Writer → Flush für SSE
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
 // corsMiddleware adds CORS headers for frontend communication
 func (a *App) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -164,6 +280,8 @@ func (a *App) corsMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Access-Control-Max-Age", "3600")
+		// Kitchen Display (andere Origin) muss X-Partial-Result lesen können
+		w.Header().Set("Access-Control-Expose-Headers", "X-Partial-Result")
 
 		// Handle preflight OPTIONS request
 		if r.Method == "OPTIONS" {
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen: Breaker ist offen → Call wurde gar nicht erst ausgeführt
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type circuitState int
+
+const (
+	circuitClosed   circuitState = iota // Normalbetrieb: Calls gehen durch
+	circuitOpen                         // Zu viele Fehler: Calls werden sofort abgelehnt
+	circuitHalfOpen                     // Cooldown vorbei: EIN Probe-Call darf durch
+)
+
+// CircuitBreaker: Schützt vor einem ausgefallenen Upstream (z.B. Stripe)
+// Warum?
+// → Stripe down = jeder Menu-Request wartet pro Item auf einen Timeout
+// → Nach failureThreshold Fehlern in Folge: Breaker öffnet → sofort Fallback
+// → Nach openTimeout: Ein Probe-Call testet ob Stripe wieder da ist
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            circuitState
+	failures         int
+	openedAt         time.Time
+	probing          bool
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time // Injizierbar → Tests ohne Sleep
+}
+
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+// Execute: Führt fn aus wenn der Breaker es erlaubt, sonst ErrCircuitOpen
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	if !cb.allow() {
+		return ErrCircuitOpen
+	}
+
+	err := fn()
+	cb.record(err)
+	return err
+}
+
+// Open: true solange Calls abgelehnt werden (für Logging/Fallback-Entscheidung)
+func (cb *CircuitBreaker) Open() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state == circuitOpen && cb.now().Sub(cb.openedAt) < cb.openTimeout
+}
+
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.openTimeout {
+			return false
+		}
+		cb.state = circuitHalfOpen
+		cb.probing = true
+		return true
+	case circuitHalfOpen:
+		// Nur EIN Probe-Call gleichzeitig
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+
+	if err == nil {
+		cb.state = circuitClosed
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.state = circuitOpen
+		cb.openedAt = cb.now()
+	}
+}
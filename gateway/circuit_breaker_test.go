@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+var errStripeDown = errors.New("stripe: 503 service unavailable")
+
+func newTestBreaker(threshold int, timeout time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker(threshold, timeout)
+	cb.now = func() time.Time { return now }
+	return cb, &now
+}
+
+func TestCircuitBreakerOpensAfterRepeatedFailures(t *testing.T) {
+	cb, now := newTestBreaker(3, 30*time.Second)
+	failing := func() error { return errStripeDown }
+
+	for i := 0; i < 3; i++ {
+		if err := cb.Execute(failing); !errors.Is(err, errStripeDown) {
+			t.Fatalf("call %d = %v; want stripe error", i+1, err)
+		}
+	}
+	if !cb.Open() {
+		t.Fatal("breaker still closed after 3 failures in a row")
+	}
+
+	// Offen → Stripe wird gar nicht erst gefragt
+	called := false
+	if err := cb.Execute(func() error { called = true; return nil }); !errors.Is(err, ErrCircuitOpen) || called {
+		t.Fatalf("open breaker: err = %v, called = %v; want ErrCircuitOpen without call", err, called)
+	}
+
+	// Cooldown vorbei → EIN Probe-Call, Erfolg schließt den Breaker
+	*now = now.Add(30 * time.Second)
+	if err := cb.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("probe = %v; want nil", err)
+	}
+	if cb.Open() {
+		t.Fatal("breaker still open after successful probe")
+	}
+}
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	cb, now := newTestBreaker(1, 30*time.Second)
+
+	cb.Execute(func() error { return errStripeDown })
+	*now = now.Add(30 * time.Second)
+
+	if err := cb.Execute(func() error { return errStripeDown }); !errors.Is(err, errStripeDown) {
+		t.Fatalf("probe = %v; want stripe error", err)
+	}
+	if !cb.Open() {
+		t.Fatal("breaker closed after failed probe")
+	}
+}
+
+func TestCircuitBreakerResetsOnSuccess(t *testing.T) {
+	cb, _ := newTestBreaker(3, 30*time.Second)
+
+	cb.Execute(func() error { return errStripeDown })
+	cb.Execute(func() error { return errStripeDown })
+	cb.Execute(func() error { return nil })
+	cb.Execute(func() error { return errStripeDown })
+
+	// Nur Fehler IN FOLGE zählen
+	if cb.Open() {
+		t.Fatal("breaker opened although failures were not consecutive")
+	}
+}
+
+func TestFallbackMenuItem(t *testing.T) {
+	h := NewHandler(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil, 0, time.Minute, "")
+	h.priceCache.Set("price_burger", MenuItem{ID: "old", Name: "Burger", PriceID: "price_burger", Price: 899, Quantity: 1})
+
+	// Last-Known-Good: Stripe Daten aus dem Cache, Stock live, als Stale markiert
+	cached := h.fallbackMenuItem(&api.Item{ID: "1", Name: "Burger", PriceID: "price_burger", Quantity: 20})
+	if cached.Price != 899 || cached.ID != "1" || cached.Quantity != 20 || !cached.Stale || cached.PriceUnavailable {
+		t.Fatalf("cached fallback = %+v", cached)
+	}
+
+	// Nie geladen → klar markiert "price unavailable", KEIN erfundener Preis
+	missing := h.fallbackMenuItem(&api.Item{ID: "2", Name: "Pommes", PriceID: "price_pommes", Quantity: 15})
+	if !missing.PriceUnavailable || missing.Price != 0 || missing.Stale {
+		t.Fatalf("uncached fallback = %+v", missing)
+	}
+}
+
+// Stripe mehrfach ausgefallen → Breaker offen → Menu kommt OHNE Stripe Call aus Cache bzw. als "price unavailable"
+func TestEnrichMenuServesFallbackWhileBreakerOpen(t *testing.T) {
+	t.Setenv("STRIPE_SECRET_KEY", "sk_test_breaker") // Key gesetzt → nur der Breaker verhindert den Call
+	h := NewHandler(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil, time.Second, 0, "")
+	h.priceCache.Set("price_burger", MenuItem{Name: "Burger", PriceID: "price_burger", Price: 899})
+
+	for i := 0; i < stripeFailureThreshold; i++ {
+		h.stripeBreaker.Execute(func() error { return errStripeDown })
+	}
+	if !h.stripeBreaker.Open() {
+		t.Fatal("breaker not open after repeated stripe failures")
+	}
+
+	menu, pending := h.enrichMenu(context.Background(), []*api.Item{
+		{ID: "1", Name: "Burger", PriceID: "price_burger", Quantity: 20},
+		{ID: "2", Name: "Pommes", PriceID: "price_pommes", Quantity: 15},
+	})
+	if pending != 0 || len(menu) != 2 {
+		t.Fatalf("menu = %+v, pending = %d", menu, pending)
+	}
+	if menu[0].Price != 899 || !menu[0].Stale {
+		t.Errorf("burger = %+v; want cached price marked stale", menu[0])
+	}
+	if !menu[1].PriceUnavailable || menu[1].Price != 0 {
+		t.Errorf("pommes = %+v; want price unavailable", menu[1])
+	}
+}
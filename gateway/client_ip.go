@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies: Load Balancer / Reverse Proxies vor dem Gateway (TRUSTED_PROXIES)
+// Warum nicht einfach immer X-Forwarded-For lesen?
+// → Header kann jeder Client selbst setzen → ohne Proxy davor wäre die "Client IP" frei wählbar
+// → Nur wenn r.RemoteAddr ein bekannter Proxy ist, glauben wir dessen Headern
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies: "10.0.0.0/8,192.168.1.5" → TrustedProxies ("" = keinem Proxy vertrauen)
+// Einzelne IPs ohne /Maske gelten als /32 bzw. /128
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) trusts(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP: Echte Client IP eines Requests
+// Flow:
+// 1. RemoteAddr kein Trusted Proxy → RemoteAddr (Header werden ignoriert!)
+// 2. X-Forwarded-For von RECHTS nach links: erste IP die kein Trusted Proxy ist
+// 3. Kein (brauchbarer) X-Forwarded-For → X-Real-IP
+// 4. Sonst RemoteAddr
+// Warum von rechts?
+// → Links steht was der Client selbst mitgeschickt hat, nur die rechten Einträge haben unsere Proxies angehängt
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if !t.trusts(remote) {
+		return remote
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if _, err := netip.ParseAddr(hop); err != nil {
+				break // Kaputter Eintrag → alles links davon ist nicht vertrauenswürdig
+			}
+			if !t.trusts(hop) {
+				return hop
+			}
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		if _, err := netip.ParseAddr(realIP); err == nil {
+			return realIP
+		}
+	}
+
+	return remote
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies(" 10.0.0.0/8, 192.168.1.5 ,,::1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	if len(proxies) != 3 {
+		t.Fatalf("got %d proxies; want 3", len(proxies))
+	}
+	for _, ip := range []string{"10.1.2.3", "192.168.1.5", "::1", "::ffff:10.0.0.1"} {
+		if !proxies.trusts(ip) {
+			t.Errorf("expected %s to be trusted", ip)
+		}
+	}
+	if proxies.trusts("192.168.1.6") {
+		t.Errorf("192.168.1.6 must not be trusted")
+	}
+
+	if _, err := ParseTrustedProxies("10.0.0.0/99"); err == nil {
+		t.Errorf("expected error for invalid prefix")
+	}
+	if _, err := ParseTrustedProxies("not-an-ip"); err == nil {
+		t.Errorf("expected error for invalid ip")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		proxies    TrustedProxies
+		remoteAddr string
+		xff        []string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "no trusted proxies ignores headers",
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"203.0.113.7"},
+			realIP:     "203.0.113.8",
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "untrusted peer spoofing X-Forwarded-For",
+			proxies:    proxies,
+			remoteAddr: "198.51.100.9:1234",
+			xff:        []string{"203.0.113.7"},
+			want:       "198.51.100.9",
+		},
+		{
+			name:       "untrusted peer spoofing X-Real-IP",
+			proxies:    proxies,
+			remoteAddr: "198.51.100.9:1234",
+			realIP:     "203.0.113.8",
+			want:       "198.51.100.9",
+		},
+		{
+			name:       "trusted proxy",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "client spoofs leftmost entry behind trusted proxy",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"1.2.3.4, 203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "chain of trusted proxies across multiple headers",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"203.0.113.7, 10.0.0.3", "10.0.0.2"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "garbage entry stops the walk",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"203.0.113.7, garbage, 10.0.0.2"},
+			realIP:     "203.0.113.8",
+			want:       "203.0.113.8",
+		},
+		{
+			name:       "X-Real-IP behind trusted proxy",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			realIP:     "203.0.113.8",
+			want:       "203.0.113.8",
+		},
+		{
+			name:       "only trusted hops falls back to RemoteAddr",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			xff:        []string{"10.0.0.2"},
+			want:       "10.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/menu", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := tt.proxies.ClientIP(r); got != tt.want {
+				t.Fatalf("ClientIP = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConnectAccounts: Restaurant ID → Stripe Connect Account (STRIPE_CONNECT_ACCOUNTS)
+// Warum serverseitig statt ?stripe_account=acct_... vom Client?
+// → Der Kunde könnte sonst jeden Connect Account der Plattform wählen → Geld landet beim falschen Restaurant
+// → Der Client nennt nur das Restaurant, welcher Account dazugehört entscheidet die Config
+type ConnectAccounts map[string]string
+
+// ParseConnectAccounts: "pizza_roma=acct_1Nv...,sushi_bar=acct_1Pq..." → ConnectAccounts ("" = keine)
+// → Jeder Account muss eine Connect Account ID sein (acct_...), jedes Restaurant nur einmal
+func ParseConnectAccounts(s string) (ConnectAccounts, error) {
+	accounts := make(ConnectAccounts)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		restaurant, account, ok := strings.Cut(entry, "=")
+		restaurant, account = strings.TrimSpace(restaurant), strings.TrimSpace(account)
+		if !ok || restaurant == "" {
+			return nil, fmt.Errorf("invalid connect account %q: want restaurant=acct_...", entry)
+		}
+		if !strings.HasPrefix(account, "acct_") {
+			return nil, fmt.Errorf("invalid connect account for %q: %q is not a connected account id (acct_...)", restaurant, account)
+		}
+		if _, dup := accounts[restaurant]; dup {
+			return nil, fmt.Errorf("restaurant %q has more than one connect account", restaurant)
+		}
+		accounts[restaurant] = account
+	}
+	return accounts, nil
+}
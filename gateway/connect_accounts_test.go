@@ -0,0 +1,64 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseConnectAccounts(t *testing.T) {
+	accounts, err := ParseConnectAccounts(" pizza_roma=acct_1, sushi_bar = acct_2 ,")
+	if err != nil {
+		t.Fatalf("ParseConnectAccounts: %v", err)
+	}
+	want := ConnectAccounts{"pizza_roma": "acct_1", "sushi_bar": "acct_2"}
+	if !maps.Equal(accounts, want) {
+		t.Errorf("accounts = %v; want %v", accounts, want)
+	}
+
+	for _, invalid := range []string{"acct_1", "=acct_1", "pizza_roma=cus_1", "pizza_roma=acct_1,pizza_roma=acct_2"} {
+		if _, err := ParseConnectAccounts(invalid); err == nil {
+			t.Errorf("ParseConnectAccounts(%q) = nil error; want error", invalid)
+		}
+	}
+}
+
+// Restaurant → Account aus der Config, unbekanntes Restaurant → 400 ohne Order
+func TestCreateOrderUsesConfiguredConnectAccount(t *testing.T) {
+	orders := &fakeOrders{}
+	h, _, _ := newPaymentLinkTestHandler(t, orders)
+	h.connectAccounts = ConnectAccounts{"pizza_roma": "acct_roma"}
+
+	mux := http.NewServeMux()
+	h.registerRoute(mux)
+	create := func(query string) int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/customers/c1/orders"+query, strings.NewReader(`[{"id":"1","quantity":1}]`)))
+		return w.Code
+	}
+
+	if code := create("?restaurant=pizza_roma"); code != http.StatusCreated {
+		t.Fatalf("known restaurant: status = %d; want 201", code)
+	}
+	if code := create("?restaurant=evil&stripe_account=acct_evil"); code != http.StatusBadRequest {
+		t.Errorf("unknown restaurant: status = %d; want 400", code)
+	}
+	if code := create("?stripe_account=acct_evil"); code != http.StatusCreated {
+		t.Fatalf("platform order: status = %d; want 201", code)
+	}
+
+	orders.mu.Lock()
+	defer orders.mu.Unlock()
+	if len(orders.created) != 2 {
+		t.Fatalf("orders created = %d; want 2", len(orders.created))
+	}
+	if got := orders.created[0].StripeAccount; got != "acct_roma" {
+		t.Errorf("restaurant order account = %q; want acct_roma", got)
+	}
+	// ?stripe_account wird ignoriert → Plattform Account
+	if got := orders.created[1].StripeAccount; got != "" {
+		t.Errorf("platform order account = %q; want empty", got)
+	}
+}
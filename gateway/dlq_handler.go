@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+)
+
+// DLQ Peek Limits (?limit=N)
+const (
+	defaultDLQPeekLimit = 10
+	maxDLQPeekLimit     = 100 // Alle Messages bleiben bis zum Ende des Peeks reserviert
+)
+
+// dlqConnector: Eigene Verbindung pro Request (broker.Connect)
+// Warum nicht der Channel der Live Updates?
+// → basic.get auf eine unbekannte Queue → RabbitMQ schließt den Channel (404) → SSE Consumer wären tot
+type dlqConnector func() (broker.DLQChannel, func() error, error)
+
+// dlqAdmin: DLQs ansehen (peek) und einzelne Orders erneut verarbeiten (replay)
+// → DLQs waren vorher ein schwarzes Loch, nur über die RabbitMQ UI erreichbar
+type dlqAdmin struct {
+	connect       dlqConnector
+	replayEnabled bool // DLQ_REPLAY_ENABLED: Replay muss bewusst eingeschaltet werden (Poison Messages!)
+	logger        *slog.Logger
+
+	trustedProxies TrustedProxies // Client IP im Replay Audit Log
+}
+
+func newDLQAdmin(connect dlqConnector, replayEnabled bool, trustedProxies TrustedProxies, logger *slog.Logger) *dlqAdmin {
+	return &dlqAdmin{
+		connect:        connect,
+		replayEnabled:  replayEnabled,
+		logger:         logger,
+		trustedProxies: trustedProxies,
+	}
+}
+
+// handlePeek: GET /api/admin/dlq/{queue}?limit=N → die ersten N Messages, ohne sie zu entfernen
+func (a *dlqAdmin) handlePeek(w http.ResponseWriter, r *http.Request) {
+	queue := r.PathValue("queue")
+	if !strings.HasSuffix(queue, ".dlq") {
+		http.Error(w, "queue must be a dead letter queue (*.dlq)", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultDLQPeekLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxDLQPeekLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxDLQPeekLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	ch, closeConn, err := a.connect()
+	if err != nil {
+		a.logger.Error("failed to connect to rabbitmq", slog.Any("error", err))
+		http.Error(w, "RabbitMQ unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer closeConn()
+
+	messages, err := broker.PeekDLQ(ch, queue, limit)
+	if err != nil {
+		a.logger.Error("failed to peek dlq",
+			slog.String("queue", queue),
+			slog.Any("error", err),
+		)
+		writeDLQError(w, err, "Failed to read dead letter queue")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"queue":    queue,
+		"count":    len(messages),
+		"messages": messages,
+	})
+}
+
+// dlqReplayRequest: Body von POST /api/admin/dlq/{queue}/replay
+type dlqReplayRequest struct {
+	OrderID string `json:"order_id"`
+}
+
+// handleReplay: POST /api/admin/dlq/{queue}/replay → Message der Order zurück in die Original Queue
+// → x-retry-count wird zurückgesetzt (broker.ReplayDLQMessage), der Consumer hat wieder alle Versuche
+// Warum zusätzlich DLQ_REPLAY_ENABLED?
+// → Poison Message replayen = derselbe Fehler nochmal (und evtl. Seiteneffekte wie Refunds)
+// → Admin Token allein reicht nicht, Replay wird pro Umgebung bewusst freigeschaltet
+func (a *dlqAdmin) handleReplay(w http.ResponseWriter, r *http.Request) {
+	if !a.replayEnabled {
+		http.Error(w, "DLQ replay is disabled (DLQ_REPLAY_ENABLED not set)", http.StatusForbidden)
+		return
+	}
+
+	queue := r.PathValue("queue")
+	if !strings.HasSuffix(queue, ".dlq") {
+		http.Error(w, "queue must be a dead letter queue (*.dlq)", http.StatusBadRequest)
+		return
+	}
+
+	var req dlqReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderID == "" {
+		http.Error(w, "order_id is required", http.StatusBadRequest)
+		return
+	}
+
+	ch, closeConn, err := a.connect()
+	if err != nil {
+		a.logger.Error("failed to connect to rabbitmq", slog.Any("error", err))
+		http.Error(w, "RabbitMQ unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer closeConn()
+
+	if err := broker.ReplayDLQMessage(r.Context(), ch, queue, req.OrderID); err != nil {
+		a.logger.Error("failed to replay dlq message",
+			slog.String("queue", queue),
+			slog.String("order_id", req.OrderID),
+			slog.Any("error", err),
+		)
+		writeDLQError(w, err, "Failed to replay message")
+		return
+	}
+
+	a.logger.Info("dlq message replayed",
+		slog.String("queue", queue),
+		slog.String("order_id", req.OrderID),
+		slog.String("client_ip", a.trustedProxies.ClientIP(r)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"queue":    queue,
+		"order_id": req.OrderID,
+		"status":   "replayed",
+	})
+}
+
+// writeDLQError: Keine Message / Queue existiert nicht → 404, sonst 502 (RabbitMQ Fehler)
+func writeDLQError(w http.ResponseWriter, err error, msg string) {
+	var amqpErr *amqp.Error
+	switch {
+	case errors.Is(err, broker.ErrDLQMessageNotFound):
+		http.Error(w, "No dead-lettered message found for this order", http.StatusNotFound)
+	case errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound:
+		http.Error(w, "Queue not found", http.StatusNotFound)
+	default:
+		http.Error(w, msg, http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/timour/order-microservices/common/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpStatusFromGRPC: gRPC Code eines Upstream Calls → HTTP Status für den Client
+// Warum zentral?
+// → Jeder Handler hatte seinen eigenen switch (oder pauschal 500)
+// → Orders/Stock liefern jetzt typisierte Codes → gleicher Code = gleicher HTTP Status überall
+func httpStatusFromGRPC(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest // z.B. unbekanntes Item, kaputter page_token
+	case codes.NotFound:
+		return http.StatusNotFound // Order/Item existiert nicht
+	case codes.FailedPrecondition, codes.Aborted:
+		return http.StatusConflict // z.B. nicht genug Bestand, schon bezahlt, gleicher Idempotency-Key läuft noch
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return http.StatusServiceUnavailable // Upstream down/überlastet → Client darf später retryen
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeGRPCError: Fehler eines gRPC Calls als HTTP Antwort
+// → Fachliche Codes: Message des Upstreams (maschinenlesbar, z.B. "order 42 not found")
+// → 500: fallback statt interner Details (MongoDB/Postgres Fehlertexte gehören ins Log, nicht zum Client)
+func writeGRPCError(w http.ResponseWriter, err error, fallback string) {
+	code := httpStatusFromGRPC(status.Code(err))
+	if code == http.StatusInternalServerError {
+		http.Error(w, fallback, code)
+		return
+	}
+	http.Error(w, status.Convert(err).Message(), code)
+}
+
+// OutOfStockItem: Ein Item aus dem 409 Body von POST /api/customers/{customerID}/orders
+type OutOfStockItem struct {
+	ID        string `json:"id"`
+	Requested int32  `json:"requested"`
+	Available int32  `json:"available"` // 0 = ausverkauft/deaktiviert → App zeigt "Nur noch N" wenn > 0
+}
+
+// OutOfStockResponse: 409 Body wenn Items nicht in der Menge verfügbar sind
+type OutOfStockResponse struct {
+	Error           string           `json:"error"`
+	OutOfStockItems []OutOfStockItem `json:"out_of_stock_items"`
+}
+
+// writeOutOfStock: FailedPrecondition mit OutOfStockItem Details (Orders) → 409 JSON
+// Returns: false wenn err keine solchen Details trägt → Caller fällt auf writeGRPCError zurück
+func writeOutOfStock(w http.ResponseWriter, err error) bool {
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		return false
+	}
+
+	var items []OutOfStockItem
+	for _, detail := range st.Details() {
+		if item, ok := detail.(*api.OutOfStockItem); ok {
+			items = append(items, OutOfStockItem{ID: item.ItemID, Requested: item.Requested, Available: item.Available})
+		}
+	}
+	if len(items) == 0 {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(OutOfStockResponse{Error: st.Message(), OutOfStockItems: items})
+	return true
+}
@@ -4,33 +4,112 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/common/telemetry"
 	"github.com/timour/order-microservices/discovery"
+	"google.golang.org/grpc"
 )
 
 type handler struct {
-	ordersClient api.OrderServiceClient
-	registry     discovery.Registry
-	logger       *slog.Logger
+	ordersClient  api.OrderServiceClient
+	registry      discovery.Registry
+	logger        *slog.Logger
+	stripeBreaker *CircuitBreaker // Schützt Menu vor Stripe Ausfällen
+	priceCache    *PriceCache     // Stripe Daten pro PriceID (frisch → kein Stripe Call, sonst Fallback)
+	paymentsAddr  string          // Payments HTTP Server (interner Payment Link Endpoint)
+	paymentsToken string          // Bearer Token für die internen Payments Endpoints
+
+	stockCheckCache *StockCheckCache         // Warenkorb Availability (POST /api/stock/check)
+	upstream        *metrics.UpstreamMetrics // gRPC Latenz zu Orders/Stock (getrennt von HTTP Latenz)
+	menuMetrics     *metrics.MenuMetrics     // PriceCache Hit/Miss (nil = aus)
+
+	menuEnrichmentTimeout time.Duration   // Gesamtbudget für Stripe Calls im Menu (danach PricePending)
+	adminToken            string          // Bearer Token für geschützte Admin Endpoints ("" = gesperrt)
+	trustedProxies        TrustedProxies  // Client IP für Admin Audit Logs (leer = RemoteAddr)
+	connectAccounts       ConnectAccounts // Restaurant → Stripe Connect Account (leer = nur Plattform Account)
+
+	connsMu sync.Mutex
+	conns   map[string]*grpc.ClientConn // EINE langlebige Connection pro Service (siehe serviceConnection)
 }
 
-func NewHandler(registry discovery.Registry, logger *slog.Logger) *handler {
+func NewHandler(registry discovery.Registry, logger *slog.Logger, paymentsAddr string, upstream *metrics.UpstreamMetrics, menuMetrics *metrics.MenuMetrics, menuEnrichmentTimeout, menuCacheTTL time.Duration, adminToken string) *handler {
+	if menuEnrichmentTimeout <= 0 {
+		menuEnrichmentTimeout = DefaultMenuEnrichmentTimeout
+	}
 	return &handler{
-		registry: registry,
-		logger:   logger,
+		registry:      registry,
+		logger:        logger,
+		paymentsAddr:  paymentsAddr,
+		stripeBreaker: NewCircuitBreaker(stripeFailureThreshold, stripeOpenTimeout),
+		priceCache:    NewPriceCache(menuCacheTTL),
+
+		stockCheckCache: NewStockCheckCache(stockCheckCacheTTL),
+		upstream:        upstream,
+		menuMetrics:     menuMetrics,
+
+		menuEnrichmentTimeout: menuEnrichmentTimeout,
+		adminToken:            adminToken,
+		conns:                 make(map[string]*grpc.ClientConn),
+	}
+}
+
+// serviceConnection: Geteilte Connection zu service (discovery.ServiceConnection + Upstream Latenz Metrik pro RPC)
+// Warum gecacht statt pro Request?
+// → Jede ClientConn startet einen Resolver (Re-Resolve gegen Consul) + Subchannels zu ALLEN Instances
+// → Pro Request gedialt und nie geschlossen = unbegrenzt Goroutines, Connections und Consul Last
+// → round_robin + Resolver halten die eine Connection aktuell (neue/tote Instances)
+// Warum lazy statt beim Start?
+// → Gateway soll auch starten wenn Orders/Stock noch nicht laufen → erster Request dialt, Fehler wird NICHT gecacht
+// Aufrufer dürfen die Connection NICHT schließen → handler.Close beim Shutdown
+func (h *handler) serviceConnection(ctx context.Context, service string) (*grpc.ClientConn, error) {
+	h.connsMu.Lock()
+	defer h.connsMu.Unlock()
+
+	if conn, ok := h.conns[service]; ok {
+		return conn, nil
+	}
+
+	conn, err := discovery.ServiceConnection(ctx, service, h.registry,
+		grpc.WithUnaryInterceptor(h.upstream.UnaryClientInterceptor(service)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	h.conns[service] = conn
+	return conn, nil
+}
+
+// Close: Alle geteilten Service Connections schließen (Shutdown)
+func (h *handler) Close() error {
+	h.connsMu.Lock()
+	defer h.connsMu.Unlock()
+
+	var errs []error
+	for service, conn := range h.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s connection: %w", service, err))
+		}
+		delete(h.conns, service)
 	}
+	return errors.Join(errs...)
 }
 
 func (h *handler) getOrdersClient(ctx context.Context) (api.OrderServiceClient, error) {
 	// ⭐ NEW: ServiceConnection() Helper mit OpenTelemetry!
-	// Warum discovery.ServiceConnection?
+	// Warum discovery.ServiceConnection (via h.serviceConnection)?
 	// → Service Discovery + gRPC Dial + OpenTelemetry in EINER Funktion!
 	// → Automatisches Tracing für HTTP → gRPC Calls
-	// → Load Balancing (random) eingebaut
-	conn, err := discovery.ServiceConnection(ctx, "orders", h.registry)
+	// → Load Balancing (round_robin) eingebaut, Connection wird geteilt
+	conn, err := h.serviceConnection(ctx, "orders")
 	if err != nil {
 		return nil, err
 	}
@@ -40,10 +119,27 @@ func (h *handler) getOrdersClient(ctx context.Context) (api.OrderServiceClient,
 
 func (h *handler) registerRoute(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/customers/{customerID}/orders", h.handleCreateOrder)
+	mux.HandleFunc("GET /api/customers/{customerID}/orders", h.handleGetCustomerOrders)       // Bestellhistorie, neueste zuerst
+	mux.HandleFunc("POST /api/customers/{customerID}/orders/validate", h.handleValidateOrder) // Dry-Run: nichts wird gespeichert/reserviert
 	mux.HandleFunc("GET /api/customers/{customerID}/orders/{orderID}", h.handleGetOrder)
-	mux.HandleFunc("PUT /api/customers/{customerID}/orders/{orderID}", h.handleUpdateOrder)
+	mux.HandleFunc("PUT /api/customers/{customerID}/orders/{orderID}", h.handleUpdateOrder) // Nur "cancelled"
+	mux.HandleFunc("POST /api/customers/{customerID}/orders/{orderID}/payment-link", h.handleReissuePaymentLink)
+	mux.HandleFunc("GET /api/customers/{customerID}/orders/{orderID}/payment-status", h.handleGetPaymentStatus) // Countdown bis Reservation/Link ablaufen
+	mux.HandleFunc("POST /api/customers/{customerID}/orders/{orderID}/cancel", h.handleCancelOrder)
+	mux.HandleFunc("GET /api/sessions/{sessionID}/orders", h.handleGetSessionOrders)
+	mux.HandleFunc("POST /api/sessions/{sessionID}/payment-link", h.handleSessionPaymentLink)
 	mux.HandleFunc("GET /api/menu", h.handleGetMenu) // ⭐ NEW: Menu endpoint with Stripe Product data
 	mux.HandleFunc("GET /api/orders", h.handleGetOrders)
+	mux.HandleFunc("GET /api/admin/inventory", h.requireAdmin(h.handleGetInventory)) // Stock + Reservierungen → nur Admins
+	mux.HandleFunc("POST /api/admin/items", h.requireAdmin(h.handleBulkCreateItems))
+	mux.HandleFunc("PUT /api/admin/items/{itemID}/availability", h.requireAdmin(h.handleSetItemAvailability))
+	mux.HandleFunc("GET /api/admin/orders/stuck", h.requireAdmin(h.handleGetStuckOrders))               // Enthält Customer IDs → nur Admins
+	mux.HandleFunc("POST /api/admin/reservations/cleanup", h.requireAdmin(h.handleCleanupReservations)) // Schreibt → Admin Token Pflicht
+	mux.HandleFunc("POST /api/admin/customers/{customerID}/orders/cancel-unpaid", h.requireAdmin(h.handleCancelUnpaidOrders))
+	mux.HandleFunc("DELETE /api/admin/orders/{orderID}", h.requireAdmin(h.handleDeleteOrder)) // Soft-Delete, nur Endzustände
+	mux.HandleFunc("POST /api/admin/orders/{orderID}/restore", h.requireAdmin(h.handleRestoreOrder))
+	mux.HandleFunc("PUT /api/admin/customers/{customerID}/orders/{orderID}", h.requireAdmin(h.handleAdminUpdateOrder)) // Kitchen Display: preparing → ready
+	mux.HandleFunc("POST /api/stock/check", h.handleStockCheck)
 
 	// Serve static files from public directory
 	fs := http.FileServer(http.Dir("./public"))
@@ -59,15 +155,18 @@ func (h *handler) handleGetOrder(w http.ResponseWriter, r *http.Request) {
 		slog.String("order_id", orderID),
 	)
 
+	// ⭐ Customer ID als Baggage → alle Spans downstream tragen customer.id
+	ctx := telemetry.WithCustomerID(r.Context(), customerID)
+
 	// Call Orders Service via gRPC
-	ordersClient, err := h.getOrdersClient(context.Background())
+	ordersClient, err := h.getOrdersClient(ctx)
 	if err != nil {
 		h.logger.Error("failed to discover orders service", slog.Any("error", err))
 		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
 		return
 	}
 
-	order, err := ordersClient.GetOrder(context.Background(), &api.GetOrderRequest{
+	order, err := ordersClient.GetOrder(ctx, &api.GetOrderRequest{
 		OrderId:    orderID,
 		CustomerId: customerID,
 	})
@@ -76,7 +175,7 @@ func (h *handler) handleGetOrder(w http.ResponseWriter, r *http.Request) {
 			slog.String("order_id", orderID),
 			slog.Any("error", err),
 		)
-		http.Error(w, "Failed to get order", http.StatusInternalServerError)
+		writeGRPCError(w, err, "Failed to get order")
 		return
 	}
 
@@ -91,9 +190,59 @@ func (h *handler) handleGetOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+// handleCancelOrder: POST /api/customers/{customerID}/orders/{orderID}/cancel
+// Storniert eine Order vor der Zubereitung (bezahlt → Refund über Payments) (idempotent → zweiter Klick liefert wieder 200)
+func (h *handler) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("customerID")
+	orderID := r.PathValue("orderID")
+
+	ctx := telemetry.WithCustomerID(r.Context(), customerID)
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	order, err := ordersClient.CancelOrder(ctx, &api.CancelOrderRequest{
+		OrderId:    orderID,
+		CustomerId: customerID,
+	})
+	if err != nil {
+		h.logger.Error("failed to cancel order",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to cancel order") // z.B. schon bezahlt → 409
+		return
+	}
+
+	h.logger.Info("order cancelled",
+		slog.String("order_id", order.Id),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
 // handleUpdateOrder: PUT /api/customers/{customerID}/orders/{orderID}
-// Updates order status (used by Kitchen Display to mark orders as ready)
+// Kunde darf NUR stornieren
+// Warum?
+// → Route ist ohne Auth → sonst setzt jeder seine Order per {"status":"paid"} auf bezahlt, ohne Stripe
 func (h *handler) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
+	h.updateOrderStatus(w, r, false)
+}
+
+// handleAdminUpdateOrder: PUT /api/admin/customers/{customerID}/orders/{orderID}
+// Jeder gültige Status (used by Kitchen Display to mark orders as ready)
+func (h *handler) handleAdminUpdateOrder(w http.ResponseWriter, r *http.Request) {
+	h.updateOrderStatus(w, r, true)
+}
+
+// updateOrderStatus: Order laden, Status setzen, UpdateOrder → Übergänge prüft der Orders Service
+func (h *handler) updateOrderStatus(w http.ResponseWriter, r *http.Request, admin bool) {
 	customerID := r.PathValue("customerID")
 	orderID := r.PathValue("orderID")
 
@@ -112,8 +261,20 @@ func (h *handler) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Tippfehler ("preparng") sofort als 400 statt erst im Orders Service
+	if !orderstatus.IsValid(updateRequest.Status) {
+		http.Error(w, fmt.Sprintf("unknown order status %q", updateRequest.Status), http.StatusBadRequest)
+		return
+	}
+	if !admin && updateRequest.Status != orderstatus.StatusCancelled {
+		http.Error(w, fmt.Sprintf("customers can only cancel orders, status %q requires the admin endpoint", updateRequest.Status), http.StatusForbidden)
+		return
+	}
+
+	ctx := telemetry.WithCustomerID(r.Context(), customerID)
+
 	// Get Orders Client via service discovery
-	ordersClient, err := h.getOrdersClient(context.Background())
+	ordersClient, err := h.getOrdersClient(ctx)
 	if err != nil {
 		h.logger.Error("failed to discover orders service", slog.Any("error", err))
 		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
@@ -121,7 +282,7 @@ func (h *handler) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// First get the existing order to get all fields
-	existingOrder, err := ordersClient.GetOrder(context.Background(), &api.GetOrderRequest{
+	existingOrder, err := ordersClient.GetOrder(ctx, &api.GetOrderRequest{
 		OrderId:    orderID,
 		CustomerId: customerID,
 	})
@@ -130,7 +291,7 @@ func (h *handler) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
 			slog.String("order_id", orderID),
 			slog.Any("error", err),
 		)
-		http.Error(w, "Failed to get order", http.StatusInternalServerError)
+		writeGRPCError(w, err, "Failed to get order")
 		return
 	}
 
@@ -138,14 +299,14 @@ func (h *handler) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
 	existingOrder.Status = updateRequest.Status
 
 	// Call UpdateOrder gRPC method
-	updatedOrder, err := ordersClient.UpdateOrder(context.Background(), existingOrder)
+	updatedOrder, err := ordersClient.UpdateOrder(ctx, existingOrder)
 	if err != nil {
 		h.logger.Error("failed to update order",
 			slog.String("order_id", orderID),
 			slog.String("new_status", updateRequest.Status),
 			slog.Any("error", err),
 		)
-		http.Error(w, "Failed to update order", http.StatusInternalServerError)
+		writeGRPCError(w, err, "Failed to update order") // z.B. preparing → completed → 409
 		return
 	}
 
@@ -187,6 +348,21 @@ func (h *handler) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Optional: Restaurant mit eigenem Stripe Connect Account (?restaurant=pizza_roma)
+	// → Account kommt aus STRIPE_CONNECT_ACCOUNTS, nie vom Client
+	stripeAccount, err := h.connectAccount(r.URL.Query().Get("restaurant"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Optional: Idempotency-Key Header → Retry nach Timeout liefert dieselbe Order statt einer zweiten
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		http.Error(w, "Idempotency-Key too long", http.StatusBadRequest)
+		return
+	}
+
 	h.logger.Info("order request received",
 		slog.String("customer_id", customerID),
 		slog.Int("items_count", len(items)),
@@ -201,24 +377,34 @@ func (h *handler) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	ctx := telemetry.WithCustomerID(r.Context(), customerID)
+
 	// Call Orders Service via gRPC
-	ordersClient, err := h.getOrdersClient(context.Background())
+	ordersClient, err := h.getOrdersClient(ctx)
 	if err != nil {
 		h.logger.Error("failed to discover orders service", slog.Any("error", err))
 		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
 		return
 	}
 
-	order, err := ordersClient.CreateOrder(context.Background(), &api.CreateOrderRequest{
-		CustomerId: customerID,
-		Items:      protoItems,
+	order, err := ordersClient.CreateOrder(ctx, &api.CreateOrderRequest{
+		CustomerId:     customerID,
+		Items:          protoItems,
+		SessionId:      r.URL.Query().Get("session_id"), // Optional: Dine-In Tisch (?session_id=table_7)
+		StripeAccount:  stripeAccount,
+		IdempotencyKey: idempotencyKey,
 	})
 	if err != nil {
 		h.logger.Error("failed to create order",
 			slog.String("customer_id", customerID),
 			slog.Any("error", err),
 		)
-		http.Error(w, "Failed to create order", http.StatusInternalServerError)
+		// Stock/Validation Fehler bis zum Client durchreichen statt pauschal 500
+		// → Zu wenig Bestand: WELCHE Items als JSON ("Nur noch 2 Cheeseburger")
+		if writeOutOfStock(w, err) {
+			return
+		}
+		writeGRPCError(w, err, "Failed to create order")
 		return
 	}
 
@@ -233,6 +419,9 @@ func (h *handler) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+// maxIdempotencyKeyLength: UUIDs haben 36 Zeichen → 255 lässt Platz für eigene Formate
+const maxIdempotencyKeyLength = 255
+
 // validateItems: Prüft ob Items gültig sind
 func validateItems(items []CreateOrderItem) error {
 	if len(items) == 0 {
@@ -252,16 +441,41 @@ func validateItems(items []CreateOrderItem) error {
 	return nil
 }
 
-// handleGetOrders: GET /api/orders?status={status}
+// connectAccount: Leer = Plattform Account, sonst der konfigurierte Connect Account des Restaurants
+// Warum Fehler statt Plattform Account bei unbekanntem Restaurant?
+// → Zahlung würde still bei der Plattform landen statt beim Restaurant
+func (h *handler) connectAccount(restaurant string) (string, error) {
+	if restaurant == "" {
+		return "", nil
+	}
+	account, ok := h.connectAccounts[restaurant]
+	if !ok {
+		return "", fmt.Errorf("unknown restaurant %q", restaurant)
+	}
+	return account, nil
+}
+
+// handleGetOrders: GET /api/orders?status={status}&limit=50&page_token=...
 // Fetches orders filtered by status from Orders Service
+// → Seitenweise: Nächste Seite über den X-Next-Page-Token Header (fehlt = letzte Seite)
 func (h *handler) handleGetOrders(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Get status from query parameter
-	status := r.URL.Query().Get("status")
+	orderStatus := r.URL.Query().Get("status")
+
+	var limit int32
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = int32(n)
+	}
 
 	h.logger.Info("get orders request",
-		slog.String("status", status),
+		slog.String("status", orderStatus),
 	)
 
 	// Get Orders Client via service discovery
@@ -274,23 +488,90 @@ func (h *handler) handleGetOrders(w http.ResponseWriter, r *http.Request) {
 
 	// Call GetOrdersByStatus gRPC method
 	response, err := ordersClient.GetOrdersByStatus(ctx, &api.GetOrdersByStatusRequest{
-		Status: status,
+		Status:    orderStatus,
+		Limit:     limit,
+		PageToken: r.URL.Query().Get("page_token"),
 	})
 	if err != nil {
 		h.logger.Error("failed to get orders by status",
-			slog.String("status", status),
+			slog.String("status", orderStatus),
 			slog.Any("error", err),
 		)
-		http.Error(w, "Failed to get orders", http.StatusInternalServerError)
+		writeGRPCError(w, err, "Failed to get orders") // z.B. kaputter page_token → 400
 		return
 	}
 
 	h.logger.Info("orders retrieved successfully",
-		slog.String("status", status),
+		slog.String("status", orderStatus),
 		slog.Int("orders_count", len(response.Orders)),
+		slog.Bool("partial", response.Partial),
 	)
 
 	// Return JSON array of orders
+	w.Header().Set("Content-Type", "application/json")
+	if response.Partial {
+		// Body bleibt ein Array (Kitchen Display unverändert) → Teilergebnis per Header markiert
+		w.Header().Set("X-Partial-Result", "true")
+	}
+	if response.NextPageToken != "" {
+		// Body bleibt ein Array → Token per Header (wie X-Partial-Result)
+		w.Header().Set("X-Next-Page-Token", response.NextPageToken)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response.Orders)
+}
+
+// handleGetCustomerOrders: GET /api/customers/{customerID}/orders?status=&since=&until=&limit=
+// "Meine Bestellungen": since/until als RFC3339, alle Filter optional
+// → Ungültiger Status/Zeitraum → Orders antwortet InvalidArgument → 400
+func (h *handler) handleGetCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerID := r.PathValue("customerID")
+	query := r.URL.Query()
+
+	var limit int32
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = int32(n)
+	}
+
+	h.logger.Info("get customer orders request",
+		slog.String("customer_id", customerID),
+		slog.String("status", query.Get("status")),
+	)
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	response, err := ordersClient.GetOrdersByCustomer(ctx, &api.GetOrdersByCustomerRequest{
+		CustomerId: customerID,
+		Status:     query.Get("status"),
+		Since:      query.Get("since"),
+		Until:      query.Get("until"),
+		Limit:      limit,
+	})
+	if err != nil {
+		h.logger.Error("failed to get customer orders",
+			slog.String("customer_id", customerID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to get orders")
+		return
+	}
+
+	h.logger.Info("customer orders retrieved successfully",
+		slog.String("customer_id", customerID),
+		slog.Int("orders_count", len(response.Orders)),
+	)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response.Orders)
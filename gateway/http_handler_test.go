@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/discovery/inmem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newTestHandler(t *testing.T) *handler {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	registry := inmem.NewRegistry()
+	if err := registry.Register(context.Background(), "orders-1", "orders", lis.Addr().String()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	return NewHandler(registry, slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil, 0, 0, "")
+}
+
+// Pro Service EINE Connection → kein Resolver/Subchannel Leak pro Request
+func TestServiceConnectionIsShared(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	first, err := h.serviceConnection(ctx, "orders")
+	if err != nil {
+		t.Fatalf("serviceConnection: %v", err)
+	}
+	second, err := h.serviceConnection(ctx, "orders")
+	if err != nil {
+		t.Fatalf("serviceConnection: %v", err)
+	}
+	if first != second {
+		t.Fatal("serviceConnection dialed a second connection for the same service")
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if state := first.GetState(); state != connectivity.Shutdown {
+		t.Fatalf("connection state after Close = %v; want Shutdown", state)
+	}
+}
+
+// Fehlgeschlagener Dial wird nicht gecacht → nächster Request versucht es erneut
+func TestServiceConnectionDoesNotCacheFailures(t *testing.T) {
+	h := newTestHandler(t)
+
+	if _, err := h.serviceConnection(context.Background(), "stock"); err == nil {
+		t.Fatal("serviceConnection succeeded for an unregistered service")
+	}
+	if _, ok := h.conns["stock"]; ok {
+		t.Fatal("failed dial was cached")
+	}
+}
+
+// GetOrder über die geteilte Connection → upstream Metrik mit service="orders", method="GetOrder"
+func TestGetOrderRecordsUpstreamMetric(t *testing.T) {
+	h, _, _ := newPaymentLinkTestHandler(t, &fakeOrders{order: &api.Order{Id: "o1", CustomerId: "c1"}})
+	upstream := metrics.NewUpstreamMetrics("gateway_upstream_test")
+	h.upstream = upstream // vor dem ersten Call → Interceptor wird beim Dial gesetzt
+
+	w := serveRoute(h, "GET", "/api/customers/c1/orders/o1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+
+	if n := testutil.CollectAndCount(upstream.Duration); n != 1 {
+		t.Fatalf("upstream duration series = %d; want 1", n)
+	}
+	if got := testutil.ToFloat64(upstream.Requests.WithLabelValues("orders", "GetOrder", "OK")); got != 1 {
+		t.Fatalf("upstream requests{orders,GetOrder,OK} = %v; want 1", got)
+	}
+}
+
+// Kunde darf über die offene Route NUR stornieren → "paid" ohne Stripe wäre sonst ein Request entfernt
+// Andere Status (Kitchen Display: ready) nur über die Admin Route mit Token
+func TestUpdateOrderStatusRequiresAdminExceptCancel(t *testing.T) {
+	orders := &fakeOrders{order: &api.Order{Id: "o1", CustomerId: "c1", Status: "preparing"}}
+	h, _, _ := newPaymentLinkTestHandler(t, orders)
+	h.adminToken = "secret"
+	mux := http.NewServeMux()
+	h.registerRoute(mux)
+
+	put := func(path, status, authorization string) int {
+		r := httptest.NewRequest("PUT", path, strings.NewReader(`{"status":"`+status+`"}`))
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		path, status, authorization string
+		want                        int
+	}{
+		{"/api/customers/c1/orders/o1", "paid", "", http.StatusForbidden},
+		{"/api/customers/c1/orders/o1", "ready", "", http.StatusForbidden},
+		{"/api/admin/customers/c1/orders/o1", "ready", "", http.StatusUnauthorized},
+		{"/api/admin/customers/c1/orders/o1", "ready", "Bearer secret", http.StatusOK},
+		{"/api/customers/c1/orders/o1", "cancelled", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := put(tt.path, tt.status, tt.authorization); got != tt.want {
+			t.Errorf("PUT %s %q = %d; want %d", tt.path, tt.status, got, tt.want)
+		}
+	}
+
+	orders.mu.Lock()
+	defer orders.mu.Unlock()
+	if !slices.Equal(orders.updated, []string{"ready", "cancelled"}) {
+		t.Fatalf("updated = %v; want [ready cancelled]", orders.updated)
+	}
+}
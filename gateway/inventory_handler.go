@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/timour/order-microservices/common/api"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// handleGetInventory: GET /api/admin/inventory?low_stock_threshold=5&sort=available&low_stock_only=true (Admin Token)
+// Inventory Dashboard: Bestand, Reservierungen, Verfügbarkeit + Low-Stock Flag pro Item
+// → Stock berechnet alles in EINER Query (kein N+1 über GetItems)
+func (h *handler) handleGetInventory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var threshold int64
+	if v := r.URL.Query().Get("low_stock_threshold"); v != "" {
+		var err error
+		threshold, err = strconv.ParseInt(v, 10, 32)
+		if err != nil || threshold < 0 {
+			http.Error(w, "low_stock_threshold must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// ?sort=id|name|available|available_desc → Whitelist prüft der Stock Service (InvalidArgument → 400)
+	sortBy := r.URL.Query().Get("sort")
+
+	var lowStockOnly bool
+	if v := r.URL.Query().Get("low_stock_only"); v != "" {
+		var err error
+		lowStockOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "low_stock_only must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.logger.Info("get inventory summary request",
+		slog.Int64("low_stock_threshold", threshold),
+		slog.String("sort", sortBy),
+		slog.Bool("low_stock_only", lowStockOnly),
+	)
+
+	stockClient, err := h.getStockClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover stock service", slog.Any("error", err))
+		http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	summary, err := stockClient.GetInventorySummary(ctx, &api.GetInventorySummaryRequest{
+		LowStockThreshold: int32(threshold),
+		SortBy:            sortBy,
+		LowStockOnly:      lowStockOnly,
+	})
+	if err != nil {
+		h.logger.Error("failed to get inventory summary", slog.Any("error", err))
+		writeGRPCError(w, err, "Failed to get inventory summary")
+		return
+	}
+
+	// Warum protojson mit EmitUnpopulated?
+	// → encoding/json lässt Nullwerte weg (omitempty) → "Available": 0 und "LowStock": false fehlen
+	// → Genau die Werte die im Dashboard wichtig sind!
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(summary)
+	if err != nil {
+		h.logger.Error("failed to encode inventory summary", slog.Any("error", err))
+		http.Error(w, "Failed to encode inventory summary", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store") // Reservierungen ändern sich ständig
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// handleBulkCreateItems: POST /api/admin/items (Admin Token)
+// Body: [{"ID": "3", "Name": "Cola", "PriceID": "price_...", "Quantity": 200}, ...]
+// → 201: alle angelegt | 400: Fehler pro Zeile, NICHTS angelegt (eine Transaktion)
+func (h *handler) handleBulkCreateItems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var items []*api.Item
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(items) == 0 {
+		http.Error(w, "import must contain at least one item", http.StatusBadRequest)
+		return
+	}
+
+	h.logger.Info("bulk create items request",
+		slog.Int("items_count", len(items)),
+	)
+
+	stockClient, err := h.getStockClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover stock service", slog.Any("error", err))
+		http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	resp, err := stockClient.BulkCreateItems(ctx, &api.BulkCreateItemsRequest{Items: items})
+	if err != nil {
+		h.logger.Error("failed to import items", slog.Any("error", err))
+		writeGRPCError(w, err, "Failed to import items")
+		return
+	}
+
+	statusCode := http.StatusCreated
+	if len(resp.Errors) > 0 {
+		h.logger.Warn("item import rejected",
+			slog.Int("items_count", len(items)),
+			slog.Int("invalid_rows", len(resp.Errors)),
+		)
+		statusCode = http.StatusBadRequest
+	}
+
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(resp)
+	if err != nil {
+		h.logger.Error("failed to encode import result", slog.Any("error", err))
+		http.Error(w, "Failed to encode import result", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// ItemAvailabilityRequest: Body von PUT /api/admin/items/{itemID}/availability
+type ItemAvailabilityRequest struct {
+	Available *bool `json:"available"` // Pointer → fehlendes Feld ist ein Fehler statt stillem false
+}
+
+// handleSetItemAvailability: PUT /api/admin/items/{itemID}/availability (Admin Token)
+// Body: {"available": false} → Item "heute aus", Bestand + Datensatz bleiben erhalten
+func (h *handler) handleSetItemAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	itemID := r.PathValue("itemID")
+
+	var req ItemAvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Available == nil {
+		http.Error(w, `Invalid request body, expected {"available": true|false}`, http.StatusBadRequest)
+		return
+	}
+
+	stockClient, err := h.getStockClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover stock service", slog.Any("error", err))
+		http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	item, err := stockClient.ToggleItemAvailability(ctx, &api.ToggleItemAvailabilityRequest{
+		ID:        itemID,
+		Available: *req.Available,
+	})
+	if err != nil {
+		h.logger.Error("failed to set item availability",
+			slog.String("item_id", itemID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to set item availability")
+		return
+	}
+
+	h.logger.Info("item availability changed",
+		slog.String("item_id", item.ID),
+		slog.Bool("available", !item.Unavailable),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(item)
+}
+
+// ReservationCleanupResponse: Antwort von POST /api/admin/reservations/cleanup
+type ReservationCleanupResponse struct {
+	Released int32 `json:"released"` // Freigegebene Reservierungszeilen (0 = nichts abgelaufen)
+}
+
+// handleCleanupReservations: POST /api/admin/reservations/cleanup (Admin Token)
+// Incident: Abgelaufene Reservierungen blockieren Stock → nicht auf den Minuten-Job warten
+func (h *handler) handleCleanupReservations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	stockClient, err := h.getStockClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover stock service", slog.Any("error", err))
+		http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	resp, err := stockClient.CleanupExpiredReservations(ctx, &api.CleanupExpiredReservationsRequest{})
+	if err != nil {
+		h.logger.Error("failed to cleanup expired reservations", slog.Any("error", err))
+		writeGRPCError(w, err, "Failed to cleanup expired reservations")
+		return
+	}
+
+	h.logger.Info("expired reservations cleaned up on demand",
+		slog.Int("released", int(resp.Released)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ReservationCleanupResponse{Released: resp.Released})
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/discovery/inmem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeCleanupStock: Sweep gibt immer released zurück, zählt Calls
+type fakeCleanupStock struct {
+	api.UnimplementedStockServiceServer
+	released int32
+	sweeps   atomic.Int32
+}
+
+func (f *fakeCleanupStock) CleanupExpiredReservations(context.Context, *api.CleanupExpiredReservationsRequest) (*api.CleanupExpiredReservationsResponse, error) {
+	f.sweeps.Add(1)
+	return &api.CleanupExpiredReservationsResponse{Released: f.released}, nil
+}
+
+// newCleanupTestMux: Echte Routen (inkl. requireAdmin) gegen einen Stock Fake
+func newCleanupTestMux(t *testing.T, adminToken string) (*http.ServeMux, *fakeCleanupStock) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stock := &fakeCleanupStock{released: 3}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	api.RegisterStockServiceServer(srv, stock)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	registry := inmem.NewRegistry()
+	if err := registry.Register(context.Background(), "stock-1", "stock", lis.Addr().String()); err != nil {
+		t.Fatalf("register stock: %v", err)
+	}
+	h := NewHandler(registry, slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil, 0, 0, adminToken)
+	t.Cleanup(func() { h.Close() })
+
+	mux := http.NewServeMux()
+	h.registerRoute(mux)
+	return mux, stock
+}
+
+func cleanupReservations(mux *http.ServeMux, authorization string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/api/admin/reservations/cleanup", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+// Gültiger Admin Token → EIN Sweep, Antwort enthält die Anzahl freigegebener Reservierungen
+func TestCleanupReservationsReturnsReleasedCount(t *testing.T) {
+	mux, stock := newCleanupTestMux(t, "secret")
+
+	w := cleanupReservations(mux, "Bearer secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200 (body %q)", w.Code, w.Body.String())
+	}
+	var resp ReservationCleanupResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Released != 3 {
+		t.Errorf("released = %d; want 3", resp.Released)
+	}
+	if n := stock.sweeps.Load(); n != 1 {
+		t.Errorf("sweeps = %d; want 1", n)
+	}
+}
+
+// Ohne/mit falschem Token → 401, ohne ADMIN_TOKEN → 403; Stock wird nie angefasst
+func TestCleanupReservationsRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name          string
+		adminToken    string
+		authorization string
+		want          int
+	}{
+		{"missing token", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer nope", http.StatusUnauthorized},
+		{"admin disabled", "", "Bearer secret", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, stock := newCleanupTestMux(t, tt.adminToken)
+
+			if w := cleanupReservations(mux, tt.authorization); w.Code != tt.want {
+				t.Errorf("status = %d; want %d", w.Code, tt.want)
+			}
+			if n := stock.sweeps.Load(); n != 0 {
+				t.Errorf("sweeps = %d; want 0", n)
+			}
+		})
+	}
+}
+
+// Admin Routen ohne Token → 401, der Handler (und damit Stock/Orders) wird nie erreicht
+func TestAdminRoutesRequireAdmin(t *testing.T) {
+	routes := []struct {
+		method, path string
+	}{
+		{"GET", "/api/admin/inventory"},
+		{"POST", "/api/admin/items"},
+		{"GET", "/api/admin/orders/stuck"},
+		{"PUT", "/api/admin/items/1/availability"},
+	}
+	mux, _ := newCleanupTestMux(t, "secret")
+	for _, rt := range routes {
+		t.Run(rt.method+" "+rt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(rt.method, rt.path, nil))
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d; want 401", w.Code)
+			}
+		})
+	}
+}
@@ -5,42 +5,88 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/timour/order-microservices/common/config"
 	"github.com/timour/order-microservices/common/logger"
+	"github.com/timour/order-microservices/common/startup"
 	"github.com/timour/order-microservices/common/tracing"
 )
 
 func main() {
 	cfg := Config{
-		ServiceName: config.GetEnv("SERVICE_NAME", "gateway"),
-		InstanceID:  config.GetEnv("INSTANCE_ID", "gateway-1"),
-		HTTPAddr:    config.GetEnv("HTTP_ADDR", "localhost:8081"),
-		ConsulAddr:  config.GetEnv("CONSUL_ADDR", "localhost:8500"),
+		ServiceName:   config.GetEnv("SERVICE_NAME", "gateway"),
+		InstanceID:    config.GetEnv("INSTANCE_ID", "gateway-1"),
+		HTTPAddr:      config.GetEnv("HTTP_ADDR", "localhost:8081"),
+		ConsulAddr:    config.GetEnv("CONSUL_ADDR", "localhost:8500"),
+		PaymentsAddr:  config.GetEnv("PAYMENTS_ADDR", "localhost:8082"),
+		PaymentsToken: config.GetEnv("PAYMENTS_INTERNAL_TOKEN", ""),
+		RegisterBackoff: RegisterBackoff{
+			MaxAttempts: envInt("CONSUL_REGISTER_ATTEMPTS", DefaultRegisterBackoff.MaxAttempts),
+			Initial:     envDuration("CONSUL_REGISTER_BACKOFF", DefaultRegisterBackoff.Initial),
+			Max:         envDuration("CONSUL_REGISTER_BACKOFF_MAX", DefaultRegisterBackoff.Max),
+		},
+		MenuEnrichmentTimeout: envDuration("MENU_ENRICHMENT_TIMEOUT", DefaultMenuEnrichmentTimeout),
+		MenuCacheTTL:          envDuration("MENU_CACHE_TTL", DefaultMenuCacheTTL),
+		AdminToken:            config.GetEnv("ADMIN_TOKEN", ""),
+		AMQPUser:              config.GetEnv("AMQP_USER", "guest"),
+		AMQPPass:              config.GetEnv("AMQP_PASS", "guest"),
+		AMQPHost:              config.GetEnv("AMQP_HOST", "localhost"), // Komma-separiert für Cluster Nodes
+		AMQPPort:              config.GetEnv("AMQP_PORT", "5672"),
+		DLQReplayEnabled:      envBool("DLQ_REPLAY_ENABLED", false),
+		RateLimitPerMinute:    envInt("RATE_LIMIT_PER_MINUTE", 0),
 	}
 
 	log := logger.NewLogger(cfg.ServiceName)
+
+	// Warum hier abbrechen statt Fallback?
+	// → Tippfehler in TRUSTED_PROXIES würde still allen Proxies misstrauen (oder umgekehrt)
+	trustedProxies, err := ParseTrustedProxies(config.GetEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Error("invalid TRUSTED_PROXIES", slog.Any("error", err))
+		os.Exit(1)
+	}
+	cfg.TrustedProxies = trustedProxies
+
+	// Gleiche Regel: Kaputte Zuordnung → Zahlungen gingen an die Plattform statt ans Restaurant
+	connectAccounts, err := ParseConnectAccounts(config.GetEnv("STRIPE_CONNECT_ACCOUNTS", ""))
+	if err != nil {
+		log.Error("invalid STRIPE_CONNECT_ACCOUNTS", slog.Any("error", err))
+		os.Exit(1)
+	}
+	cfg.ConnectAccounts = connectAccounts
 	log.Info("starting service",
 		slog.String("instance_id", cfg.InstanceID),
 		slog.String("http_addr", cfg.HTTPAddr),
 	)
 
+	// ⭐ Startup Report: Tracer + Consul → EINE Zusammenfassung am Ende von init
+	report := startup.NewReport(cfg.ServiceName)
+
 	// ⭐ Initialize OpenTelemetry Tracing
 	// Warum hier?
 	// → Vor app.Start(): Traces verfügbar wenn HTTP Server startet
 	// → Service Name: "gateway" (für Jaeger UI)
 	// → Shutdown: defer cleanup() flusht pending spans
-	shutdown, err := tracing.InitTracer(cfg.ServiceName)
-	if err != nil {
-		log.Error("failed to initialize tracer", slog.Any("error", err))
+	var shutdown func()
+	if err := report.Check("tracer", true, func() (err error) {
+		shutdown, err = tracing.InitTracer(cfg.ServiceName)
+		return err
+	}); err != nil {
+		report.Log(log)
 		os.Exit(1)
 	}
 	defer shutdown()
 
-	app, err := NewApp(cfg)
+	app, err := NewApp(cfg, report)
 	if err != nil {
-		log.Error("failed to create app", slog.Any("error", err))
+		report.Log(log)
+		os.Exit(1)
+	}
+
+	if err := report.Log(log); err != nil {
 		os.Exit(1)
 	}
 
@@ -64,3 +110,30 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// envInt: Liest eine Zahl aus der Umgebung, fallback bei fehlendem/ungültigem Wert
+func envInt(key string, fallback int) int {
+	n, err := strconv.Atoi(config.GetEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// envBool: Liest "true"/"1"/"false"... aus der Umgebung, fallback bei fehlendem/ungültigem Wert
+func envBool(key string, fallback bool) bool {
+	b, err := strconv.ParseBool(config.GetEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// envDuration: Liest eine Duration ("500ms", "2s") aus der Umgebung
+func envDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(config.GetEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
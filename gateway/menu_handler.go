@@ -3,16 +3,18 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/stripe/stripe-go/v81"
 	"github.com/stripe/stripe-go/v81/price"
 	"github.com/stripe/stripe-go/v81/product"
 	"github.com/timour/order-microservices/common/api"
-	"github.com/timour/order-microservices/discovery"
+	"golang.org/x/sync/errgroup"
 )
 
 // MenuItem represents a menu item with Stripe data
@@ -24,8 +26,34 @@ type MenuItem struct {
 	Image       string  `json:"image"`
 	PriceID     string  `json:"priceId"`
 	Quantity    int32   `json:"quantity"`
+	// Stale: Stripe nicht erreichbar → Daten stammen aus dem PriceCache
+	Stale bool `json:"stale,omitempty"`
+	// PriceUnavailable: Stripe nicht erreichbar UND nichts gecached → Frontend zeigt "Preis nicht verfügbar"
+	PriceUnavailable bool `json:"priceUnavailable,omitempty"`
+	// PricePending: Enrichment Budget abgelaufen bevor Stripe für dieses Item geantwortet hat
+	// → Cache Hit: Stale Daten, sonst PriceUnavailable → Frontend kann später neu laden
+	PricePending bool `json:"pricePending,omitempty"`
 }
 
+// Stripe Circuit Breaker Settings
+// → 5 Fehler in Folge → 30s keine Stripe Calls (Fallback auf PriceCache)
+const (
+	stripeFailureThreshold = 5
+	stripeOpenTimeout      = 30 * time.Second
+)
+
+// DefaultMenuEnrichmentTimeout: Gesamtbudget für ALLE Stripe Calls eines Menu Requests
+// Warum ein Gesamtbudget statt nur r.Context()?
+// → Langsames Stripe (ohne Ausfall → Breaker bleibt zu) × N Items = Menu hängt bis zum Client Timeout
+// → Nach dem Budget: Was da ist wird ausgeliefert, der Rest als PricePending
+const DefaultMenuEnrichmentTimeout = 2 * time.Second
+
+// menuEnrichmentConcurrency: Max. parallele Stripe Lookups pro Menu Request
+// Warum begrenzt?
+// → Seriell: 20 Items = 20 Round Trips hintereinander → erster Load (leerer Cache) sprengt das Budget
+// → Unbegrenzt: 100 Items = 100 gleichzeitige Stripe Calls → Rate Limit (429) für ALLE Requests
+const menuEnrichmentConcurrency = 5
+
 // handleGetMenu: GET /api/menu
 // Fetches menu from Stock Service and enriches with Stripe Product data
 func (h *handler) handleGetMenu(w http.ResponseWriter, r *http.Request) {
@@ -45,34 +73,28 @@ func (h *handler) handleGetMenu(w http.ResponseWriter, r *http.Request) {
 	stockItems, err := stockClient.GetItems(ctx, &api.GetItemsRequest{})
 	if err != nil {
 		h.logger.Error("failed to get items from stock", slog.Any("error", err))
-		http.Error(w, "Failed to get menu items", http.StatusInternalServerError)
+		writeGRPCError(w, err, "Failed to get menu items")
 		return
 	}
 
-	// 3️⃣ Enrich with Stripe Product Data
-	menuItems := make([]MenuItem, 0, len(stockItems.Items))
+	// 3️⃣ Enrich with Stripe Product Data (innerhalb des Budgets)
+	available := make([]*api.Item, 0, len(stockItems.Items))
 	for _, item := range stockItems.Items {
-		menuItem, err := h.getMenuItemWithStripeData(ctx, item)
-		if err != nil {
-			h.logger.Warn("failed to get stripe data for item",
-				slog.String("item_id", item.ID),
-				slog.Any("error", err),
-			)
-			// Fallback to basic data without Stripe enrichment
-			menuItem = &MenuItem{
-				ID:          item.ID,
-				Name:        item.Name,
-				Price:       float64(item.Quantity) / 100.0,
-				Description: "",
-				Image:       "",
-				PriceID:     item.PriceID,
-			Quantity:    item.Quantity,
-			}
+		// Vorübergehend deaktiviert (z.B. "heute aus") → gar nicht erst anzeigen, auch kein Stripe Call
+		if item.Unavailable {
+			continue
 		}
-		menuItems = append(menuItems, *menuItem)
+		available = append(available, item)
 	}
 
-	h.logger.Info("menu retrieved successfully", slog.Int("items_count", len(menuItems)))
+	enrichStart := time.Now()
+	menuItems, pending := h.enrichMenu(ctx, available)
+
+	h.logger.Info("menu retrieved successfully",
+		slog.Int("items_count", len(menuItems)),
+		slog.Int("price_pending", pending),
+		slog.Duration("enrichment_latency", time.Since(enrichStart)),
+	)
 
 	// 4️⃣ Return JSON
 	w.Header().Set("Content-Type", "application/json")
@@ -81,14 +103,158 @@ func (h *handler) handleGetMenu(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(menuItems)
 }
 
-// getMenuItemWithStripeData: Fetch Stripe Product + Price data
+// enrichMenu: Stripe Daten für alle Items, höchstens menuEnrichmentTimeout lang
+// → Stripe Calls laufen im Hintergrund (max. menuEnrichmentConcurrency parallel) → Handler wartet nur bis zum Deadline
+// → Deadline oder Client weg (ctx) → keine neuen Stripe Calls, offene Items werden PricePending
+// → Fehler bei EINEM Item → nur dieses Item bekommt Fallback Daten, der Rest des Menüs bleibt intakt
+// → Reihenfolge bleibt die des Menüs (Ergebnis per Index, nicht per Ankunft)
+// → Gibt die Anzahl der PricePending Items zurück (fürs Logging)
+func (h *handler) enrichMenu(ctx context.Context, items []*api.Item) ([]MenuItem, int) {
+	ctx, cancel := context.WithTimeout(ctx, h.menuEnrichmentTimeout)
+	defer cancel()
+
+	type enriched struct {
+		index int
+		item  *MenuItem
+	}
+	// Gepuffert → die Goroutine blockiert nach dem Deadline nicht auf einem Send den keiner mehr liest
+	results := make(chan enriched, len(items))
+
+	// Warum errgroup ohne WithContext?
+	// → Worker geben NIE einen Fehler zurück (Fallback statt Abbruch) → ein Item darf die anderen nicht canceln
+	// → SetLimit: g.Go blockiert solange schon menuEnrichmentConcurrency Lookups laufen
+	go func() {
+		var g errgroup.Group
+		g.SetLimit(menuEnrichmentConcurrency)
+		for i, item := range items {
+			if ctx.Err() != nil {
+				break
+			}
+			g.Go(func() error {
+				if ctx.Err() != nil {
+					return nil
+				}
+				menuItem, err := h.getMenuItemWithStripeData(ctx, item)
+				if err != nil {
+					h.logger.Warn("failed to get stripe data for item",
+						slog.String("item_id", item.ID),
+						slog.Any("error", err),
+					)
+					// Fallback: Last-Known-Good aus dem PriceCache oder "price unavailable"
+					menuItem = h.fallbackMenuItem(item)
+				}
+				results <- enriched{index: i, item: menuItem}
+				return nil
+			})
+		}
+		g.Wait()
+	}()
+
+	menuItems := make([]*MenuItem, len(items))
+collect:
+	for received := 0; received < len(items); received++ {
+		select {
+		case r := <-results:
+			menuItems[r.index] = r.item
+		case <-ctx.Done():
+			h.logger.Warn("menu enrichment budget exceeded",
+				slog.Duration("budget", h.menuEnrichmentTimeout),
+				slog.Int("enriched", received),
+				slog.Int("items", len(items)),
+			)
+			break collect
+		}
+	}
+
+	result := make([]MenuItem, 0, len(items))
+	pending := 0
+	for i, item := range items {
+		menuItem := menuItems[i]
+		if menuItem == nil {
+			menuItem = h.fallbackMenuItem(item)
+			menuItem.PricePending = true
+			pending++
+		}
+		result = append(result, *menuItem)
+	}
+	return result, pending
+}
+
+// getMenuItemWithStripeData: Fetch Stripe Product + Price data (through the circuit breaker)
+// Frische Daten im PriceCache (jünger als MENU_CACHE_TTL) → kein Stripe Call
+// Warum nicht jeder Fehler zählt?
+// → 4xx (z.B. unbekannte PriceID) = Problem mit EINEM Item, Stripe selbst ist gesund
+// → Nur Netzwerkfehler, 5xx und 429 öffnen den Breaker
 func (h *handler) getMenuItemWithStripeData(ctx context.Context, item *api.Item) (*MenuItem, error) {
+	if cached, ok := h.priceCache.Fresh(item.PriceID); ok {
+		h.menuMetrics.RecordPriceCache("hit")
+		cached.ID = item.ID
+		cached.Quantity = item.Quantity // Stock ist live, nur Stripe Daten kommen aus dem Cache
+		return &cached, nil
+	}
+	h.menuMetrics.RecordPriceCache("miss")
+
 	// Set Stripe API key
 	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
 	if stripe.Key == "" {
 		return nil, fmt.Errorf("STRIPE_SECRET_KEY not set")
 	}
 
+	var menuItem *MenuItem
+	var itemErr error
+	err := h.stripeBreaker.Execute(func() error {
+		menuItem, itemErr = fetchStripeMenuItem(item)
+		if itemErr != nil && isStripeOutage(itemErr) {
+			return itemErr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if itemErr != nil {
+		return nil, itemErr
+	}
+
+	h.priceCache.Set(item.PriceID, *menuItem)
+	return menuItem, nil
+}
+
+// fallbackMenuItem: Menu Item wenn Stripe Daten nicht geladen werden konnten
+// → Cache Hit: Letzte bekannte Stripe Daten, markiert als Stale
+// → Cache Miss: Price 0 + PriceUnavailable (KEIN erfundener Preis!)
+func (h *handler) fallbackMenuItem(item *api.Item) *MenuItem {
+	if cached, fetchedAt, ok := h.priceCache.Get(item.PriceID); ok {
+		h.logger.Info("serving cached stripe data",
+			slog.String("item_id", item.ID),
+			slog.Time("fetched_at", fetchedAt),
+		)
+		cached.ID = item.ID
+		cached.Quantity = item.Quantity // Stock ist live, nur Stripe Daten sind alt
+		cached.Stale = true
+		return &cached
+	}
+
+	return &MenuItem{
+		ID:               item.ID,
+		Name:             item.Name,
+		PriceID:          item.PriceID,
+		Quantity:         item.Quantity,
+		PriceUnavailable: true,
+	}
+}
+
+// isStripeOutage: Fehler der auf einen Stripe Ausfall hindeutet (nicht auf ein kaputtes Item)
+func isStripeOutage(err error) bool {
+	var stripeErr *stripe.Error
+	if errors.As(err, &stripeErr) {
+		return stripeErr.HTTPStatusCode >= 500 || stripeErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	return true // Netzwerkfehler, Timeouts, ...
+}
+
+// fetchStripeMenuItem: Die eigentlichen Stripe Calls (Price → Product)
+func fetchStripeMenuItem(item *api.Item) (*MenuItem, error) {
 	// Get Price (includes Product ID)
 	priceData, err := price.Get(item.PriceID, nil)
 	if err != nil {
@@ -109,7 +275,7 @@ func (h *handler) getMenuItemWithStripeData(ctx context.Context, item *api.Item)
 		Description: productData.Description,
 		Image:       "",
 		PriceID:     item.PriceID,
-			Quantity:    item.Quantity,
+		Quantity:    item.Quantity,
 	}
 
 	// Get first image if available
@@ -122,7 +288,7 @@ func (h *handler) getMenuItemWithStripeData(ctx context.Context, item *api.Item)
 
 // getStockClient: Service Discovery for Stock Service
 func (h *handler) getStockClient(ctx context.Context) (api.StockServiceClient, error) {
-	conn, err := discovery.ServiceConnection(ctx, "stock", h.registry)
+	conn, err := h.serviceConnection(ctx, "stock")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stripe/stripe-go/v81"
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/discovery/inmem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeStock: Stock Service der immer dieselben Items liefert
+type fakeStock struct {
+	api.UnimplementedStockServiceServer
+	items []*api.Item
+}
+
+func (f *fakeStock) GetItems(context.Context, *api.GetItemsRequest) (*api.GetItemsResponse, error) {
+	return &api.GetItemsResponse{Items: f.items}, nil
+}
+
+// newStockTestHandler: Handler gegen einen Fake Stock gRPC Server (inmem Registry)
+func newStockTestHandler(t *testing.T, stock api.StockServiceServer) *handler {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	api.RegisterStockServiceServer(srv, stock)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	registry := inmem.NewRegistry()
+	if err := registry.Register(context.Background(), "stock-1", "stock", lis.Addr().String()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := NewHandler(registry, slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil, 0, 0, "")
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestGetMenuHidesUnavailableItems(t *testing.T) {
+	t.Setenv("STRIPE_SECRET_KEY", "") // Kein Stripe → Fallback Daten, kein Netzwerk Call
+
+	h := newStockTestHandler(t, &fakeStock{items: []*api.Item{
+		{ID: "1", Name: "Burger", PriceID: "price_burger", Quantity: 20, Unavailable: true},
+		{ID: "2", Name: "Pommes", PriceID: "price_pommes", Quantity: 15},
+	}})
+
+	w := httptest.NewRecorder()
+	h.handleGetMenu(w, httptest.NewRequest("GET", "/api/menu", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200", w.Code)
+	}
+
+	var menu []MenuItem
+	if err := json.NewDecoder(w.Body).Decode(&menu); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(menu) != 1 || menu[0].ID != "2" {
+		t.Fatalf("menu = %+v; want only the available item 2", menu)
+	}
+}
+
+// slowStripe: Stripe API Stub der erst nach release antwortet → Enrichment läuft garantiert ins Budget
+func slowStripe(t *testing.T) {
+	t.Helper()
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		http.Error(w, `{"error":{"message":"too slow"}}`, http.StatusServiceUnavailable)
+	}))
+
+	// stripe-go nutzt globale Backends → für diesen Test auf den Stub umbiegen
+	prev := stripe.GetBackend(stripe.APIBackend)
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL:               stripe.String(srv.URL),
+		MaxNetworkRetries: stripe.Int64(0),
+		LeveledLogger:     &stripe.LeveledLogger{Level: stripe.LevelNull},
+	}))
+	t.Setenv("STRIPE_SECRET_KEY", "sk_test_slow")
+
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+		stripe.SetBackend(stripe.APIBackend, prev)
+	})
+}
+
+// Langsames Stripe → Menu kommt nach dem Budget zurück, Cache Treffer mit Preis, der Rest PricePending
+func TestGetMenuReturnsWithinEnrichmentBudget(t *testing.T) {
+	slowStripe(t)
+
+	h := newStockTestHandler(t, &fakeStock{items: []*api.Item{
+		{ID: "1", Name: "Burger", PriceID: "price_burger", Quantity: 20},
+		{ID: "2", Name: "Pommes", PriceID: "price_pommes", Quantity: 15},
+	}})
+	const budget = 100 * time.Millisecond
+	h.menuEnrichmentTimeout = budget
+	h.priceCache = NewPriceCache(time.Minute) // Burger frisch im Cache → kein Stripe Call
+	h.priceCache.Set("price_burger", MenuItem{Name: "Burger", PriceID: "price_burger", Price: 8.5})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	h.handleGetMenu(w, httptest.NewRequest("GET", "/api/menu", nil))
+	elapsed := time.Since(start)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200", w.Code)
+	}
+	// Großzügige Obergrenze (langsame CI), aber weit unter "hängt bis Stripe antwortet"
+	if elapsed > budget+time.Second {
+		t.Fatalf("menu took %s; want about the %s budget", elapsed, budget)
+	}
+
+	var menu []MenuItem
+	if err := json.NewDecoder(w.Body).Decode(&menu); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(menu) != 2 {
+		t.Fatalf("menu = %+v; want both items", menu)
+	}
+	if burger := menu[0]; burger.ID != "1" || burger.Price != 8.5 || burger.PricePending {
+		t.Errorf("burger = %+v; want cached price 8.5, not pending", burger)
+	}
+	if pommes := menu[1]; pommes.ID != "2" || !pommes.PricePending || !pommes.PriceUnavailable {
+		t.Errorf("pommes = %+v; want price pending (no cache, Stripe too slow)", pommes)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+// handleDeleteOrder: DELETE /api/admin/orders/{orderID} (Admin Token)
+// Soft-Delete: Order verschwindet aus Kitchen Display + Bestellhistorie, bleibt aber für Audit gespeichert
+// → Nur Endzustände (completed/cancelled/expired), sonst 409
+func (h *handler) handleDeleteOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+	ctx := r.Context()
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	order, err := ordersClient.DeleteOrder(ctx, &api.DeleteOrderRequest{OrderId: orderID})
+	if err != nil {
+		h.logger.Error("failed to delete order",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to delete order")
+		return
+	}
+
+	h.logger.Info("order deleted by admin",
+		slog.String("order_id", order.Id),
+		slog.String("client_ip", h.trustedProxies.ClientIP(r)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+// handleRestoreOrder: POST /api/admin/orders/{orderID}/restore (Admin Token)
+// Undo für handleDeleteOrder → Order taucht wieder in allen Listen auf
+func (h *handler) handleRestoreOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+	ctx := r.Context()
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	order, err := ordersClient.RestoreOrder(ctx, &api.RestoreOrderRequest{OrderId: orderID})
+	if err != nil {
+		h.logger.Error("failed to restore order",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to restore order")
+		return
+	}
+
+	h.logger.Info("order restored by admin",
+		slog.String("order_id", order.Id),
+		slog.String("client_ip", h.trustedProxies.ClientIP(r)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+)
+
+const (
+	// orderEventBuffer: Events pro SSE Client, die auf den Write warten dürfen (voll → Event wird verworfen)
+	orderEventBuffer = 16
+	// sseKeepAlive: Kommentarzeile gegen Proxy/LB Idle Timeouts
+	sseKeepAlive = 15 * time.Second
+)
+
+// liveOrderEvents: Broker Events, die als SSE an den Kunden gehen
+// → Event Name = SSE "event:" Feld → Frontend registriert pro Typ einen Listener
+var liveOrderEvents = []string{
+	broker.OrderPreparingEvent, // "order.preparing": Küche hat angefangen
+	broker.OrderReadyEvent,     // "order.ready": Abholbereit
+}
+
+// orderEvent: Ein typisiertes SSE Event für den Customer Stream
+type orderEvent struct {
+	Type string // broker.OrderPreparingEvent | broker.OrderReadyEvent
+	Data orderEventData
+}
+
+// orderEventData: JSON im "data:" Feld
+type orderEventData struct {
+	OrderID        string `json:"order_id"`
+	Status         string `json:"status"`
+	ReadyForPickup bool   `json:"ready_for_pickup"` // Nur bei order.ready → App zeigt "Bitte abholen"
+}
+
+// newOrderEvent: Broker Event + Order → SSE Event
+// Returns: false für Events, die nicht an den Kunden gehen
+func newOrderEvent(event string, order *api.Order) (orderEvent, bool) {
+	switch event {
+	case broker.OrderPreparingEvent, broker.OrderReadyEvent:
+	default:
+		return orderEvent{}, false
+	}
+
+	return orderEvent{
+		Type: event,
+		Data: orderEventData{
+			OrderID:        order.Id,
+			Status:         order.Status,
+			ReadyForPickup: event == broker.OrderReadyEvent,
+		},
+	}, true
+}
+
+// writeSSE: "event: order.ready\ndata: {...}\n\n"
+func writeSSE(w io.Writer, ev orderEvent) error {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", ev.Type, err)
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
+
+// orderEventHub: Verteilt Order Events an die offenen SSE Streams eines Kunden
+// Warum pro Kunde?
+// → Events enthalten die customerID → nur dessen Streams bekommen sie
+// → Ein Kunde kann mehrere Tabs/Geräte offen haben → Set von Channels
+type orderEventHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan orderEvent]struct{}
+}
+
+func newOrderEventHub() *orderEventHub {
+	return &orderEventHub{subs: make(map[string]map[chan orderEvent]struct{})}
+}
+
+// Subscribe: Neuer Stream für customerID → unsubscribe MUSS aufgerufen werden (defer)
+func (h *orderEventHub) Subscribe(customerID string) (<-chan orderEvent, func()) {
+	ch := make(chan orderEvent, orderEventBuffer)
+
+	h.mu.Lock()
+	if h.subs[customerID] == nil {
+		h.subs[customerID] = make(map[chan orderEvent]struct{})
+	}
+	h.subs[customerID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[customerID], ch)
+		if len(h.subs[customerID]) == 0 {
+			delete(h.subs, customerID)
+		}
+	}
+}
+
+// Publish: Event an alle Streams des Kunden → Anzahl der Streams, die es bekommen haben
+// Warum nicht blockieren?
+// → Ein langsamer Client darf den Consumer (und damit alle anderen Kunden) nicht aufhalten
+func (h *orderEventHub) Publish(customerID string, ev orderEvent) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delivered := 0
+	for ch := range h.subs[customerID] {
+		select {
+		case ch <- ev:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
+
+// handleStream: GET /api/customers/{customerID}/events → Server-Sent Events
+// → Typisierte Events (order.preparing, order.ready), Keep-Alive Kommentar alle sseKeepAlive
+func (h *orderEventHub) handleStream(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("customerID")
+	if customerID == "" {
+		http.Error(w, "customer ID is required", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Warum ResponseController?
+	// → w ist der responseRecorder der Metrics Middleware → Flush über Unwrap
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := h.Subscribe(customerID)
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			if err := writeSSE(w, ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// orderEventConsumer: order.preparing / order.ready → orderEventHub
+type orderEventConsumer struct {
+	hub    *orderEventHub
+	logger *slog.Logger
+}
+
+func NewOrderEventConsumer(hub *orderEventHub, logger *slog.Logger) *orderEventConsumer {
+	return &orderEventConsumer{hub: hub, logger: logger}
+}
+
+// Listen: Eigene exklusive Queue am Exchange des Events → JEDE Gateway Instanz bekommt jedes Event
+// Warum keine durable Queue?
+// → Streams hängen an DIESER Instanz, nach einem Restart gibt es keine Empfänger mehr
+// → Live Updates sind best effort, der aktuelle Status kommt über GET .../orders/{orderID}
+func (c *orderEventConsumer) Listen(ch *amqp.Channel, event string) error {
+	q, err := ch.QueueDeclare(
+		"",    // name: vom Server vergeben
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare %s queue: %w", event, err)
+	}
+
+	if err := ch.QueueBind(q.Name, "", event, false, nil); err != nil {
+		return fmt.Errorf("failed to bind %s queue: %w", event, err)
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, true, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", event, err)
+	}
+
+	go func() {
+		for d := range msgs {
+			c.handle(event, d)
+		}
+	}()
+
+	c.logger.Info("listening for live order events", slog.String("event", event))
+	return nil
+}
+
+// handle: EINE Delivery → SSE Event an die Streams des Kunden
+// → Kaputte Message → Nack ohne Requeue (kein DLX an der exklusiven Queue → verworfen)
+func (c *orderEventConsumer) handle(event string, d amqp.Delivery) {
+	var order api.Order
+	if err := json.Unmarshal(d.Body, &order); err != nil {
+		c.logger.Error("failed to unmarshal order event",
+			slog.String("event", event),
+			slog.Any("error", err),
+		)
+		d.Nack(false, false)
+		return
+	}
+
+	ev, ok := newOrderEvent(event, &order)
+	if ok {
+		delivered := c.hub.Publish(order.CustomerId, ev)
+		c.logger.Info("live order event sent",
+			slog.String("event", event),
+			slog.String("order_id", order.Id),
+			slog.Int("streams", delivered),
+		)
+	}
+
+	d.Ack(false)
+}
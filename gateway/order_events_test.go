@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// openStream: Echter SSE Request gegen hub.handleStream → Reader auf den Response Body
+// → Wartet bis der Stream im Hub angemeldet ist, sonst gehen Events vor dem Subscribe verloren
+func openStream(t *testing.T, hub *orderEventHub, customerID string) *bufio.Reader {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/customers/{customerID}/events", hub.handleStream)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/customers/"+customerID+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q; want text/event-stream", ct)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		hub.mu.Lock()
+		n := len(hub.subs[customerID])
+		hub.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream never subscribed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// readSSE: Nächstes Event ("event:" + "data:" bis zur Leerzeile)
+func readSSE(t *testing.T, r *bufio.Reader) (string, orderEventData) {
+	t.Helper()
+
+	var event string
+	var data orderEventData
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data); err != nil {
+				t.Fatalf("decode data %q: %v", line, err)
+			}
+		case line == "" && event != "":
+			return event, data
+		}
+	}
+}
+
+func deliverOrderEvent(c *orderEventConsumer, event string, order *api.Order) {
+	body, _ := json.Marshal(order)
+	c.handle(event, amqp.Delivery{Body: body})
+}
+
+// order.preparing und order.ready → zwei typisierte SSE Events, nur order.ready ist abholbereit
+func TestOrderEventConsumerStreamsTypedEvents(t *testing.T) {
+	hub := newOrderEventHub()
+	c := NewOrderEventConsumer(hub, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	stream := openStream(t, hub, "c1")
+
+	deliverOrderEvent(c, broker.OrderPreparingEvent, &api.Order{Id: "o1", CustomerId: "c1", Status: orderstatus.StatusPreparing})
+	deliverOrderEvent(c, broker.OrderReadyEvent, &api.Order{Id: "o1", CustomerId: "c1", Status: orderstatus.StatusReady})
+
+	tests := []struct {
+		event string
+		want  orderEventData
+	}{
+		{broker.OrderPreparingEvent, orderEventData{OrderID: "o1", Status: orderstatus.StatusPreparing}},
+		{broker.OrderReadyEvent, orderEventData{OrderID: "o1", Status: orderstatus.StatusReady, ReadyForPickup: true}},
+	}
+	for _, tt := range tests {
+		event, data := readSSE(t, stream)
+		if event != tt.event || data != tt.want {
+			t.Errorf("SSE = %s %+v; want %s %+v", event, data, tt.event, tt.want)
+		}
+	}
+}
+
+// Events gehen nur an die Streams des eigenen Kunden, andere Events gar nicht an den Kunden
+func TestOrderEventHubRouting(t *testing.T) {
+	hub := newOrderEventHub()
+	c1, unsubscribe := hub.Subscribe("c1")
+	defer unsubscribe()
+
+	ev, ok := newOrderEvent(broker.OrderReadyEvent, &api.Order{Id: "o1", CustomerId: "c1", Status: orderstatus.StatusReady})
+	if !ok {
+		t.Fatal("newOrderEvent(order.ready) not streamed")
+	}
+	if n := hub.Publish("c2", ev); n != 0 {
+		t.Errorf("Publish(c2) delivered to %d streams; want 0", n)
+	}
+	if n := hub.Publish("c1", ev); n != 1 {
+		t.Errorf("Publish(c1) delivered to %d streams; want 1", n)
+	}
+	if got := <-c1; got.Type != broker.OrderReadyEvent {
+		t.Errorf("c1 event = %s; want %s", got.Type, broker.OrderReadyEvent)
+	}
+
+	if _, ok := newOrderEvent(broker.OrderPaidEvent, &api.Order{Id: "o1", CustomerId: "c1"}); ok {
+		t.Error("newOrderEvent(order.paid) streamed; want only preparing/ready")
+	}
+}
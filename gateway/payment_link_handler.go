@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/common/telemetry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// paymentLinkTimeout: Stripe Session + Orders Update brauchen ein paar Sekunden
+const paymentLinkTimeout = 10 * time.Second
+
+// handleReissuePaymentLink: POST /api/customers/{customerID}/orders/{orderID}/payment-link
+// Warum?
+// → Customer bricht Stripe Checkout ab, Session läuft ab → Link ist tot
+// → Ohne diesen Endpoint müsste er komplett neu bestellen
+//
+// Flow:
+// 1. Orders: Order laden (nur pending/waiting_payment erlaubt, bezahlt → 409)
+// 2. Stock: Reservation verlängern (oder neu reservieren falls abgelaufen)
+// 3. Payments: Alte Stripe Session schließen + neue anlegen → Orders speichert den neuen Link
+//
+// Warum schließt Payments die alte Session?
+// → Sonst sind alter UND neuer Link bezahlbar → Kunde zahlt doppelt
+// → Alte Session schon bezahlt (Webhook unterwegs) → Payments antwortet 409, kein neuer Link
+func (h *handler) handleReissuePaymentLink(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("customerID")
+	orderID := r.PathValue("orderID")
+
+	ctx, cancel := context.WithTimeout(telemetry.WithCustomerID(r.Context(), customerID), paymentLinkTimeout)
+	defer cancel()
+
+	h.logger.Info("reissue payment link request",
+		slog.String("customer_id", customerID),
+		slog.String("order_id", orderID),
+	)
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	order, err := ordersClient.GetOrder(ctx, &api.GetOrderRequest{
+		OrderId:    orderID,
+		CustomerId: customerID,
+	})
+	if err != nil {
+		h.logger.Error("failed to get order",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to get order")
+		return
+	}
+
+	if order.CustomerId != customerID {
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	}
+
+	// Warum nur pending/waiting_payment?
+	// → Bezahlte Order + neuer Checkout = Kunde zahlt doppelt!
+	if order.Status != orderstatus.StatusPending && order.Status != orderstatus.StatusWaitingPayment {
+		h.logger.Warn("payment link reissue rejected",
+			slog.String("order_id", orderID),
+			slog.String("status", order.Status),
+		)
+		http.Error(w, fmt.Sprintf("Order is already %s", order.Status), http.StatusConflict)
+		return
+	}
+
+	// ⭐ Reservation verlängern BEVOR der Link rausgeht
+	// → Sonst könnte der Kunde für Stock zahlen der längst weiterverkauft ist
+	conn, err := h.serviceConnection(ctx, "stock")
+	if err != nil {
+		h.logger.Error("failed to discover stock service", slog.Any("error", err))
+		http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	reservation, err := api.NewStockServiceClient(conn).RenewReservation(ctx, &api.RenewReservationRequest{
+		OrderID: order.Id,
+		Items:   order.Items,
+	})
+	if err != nil {
+		h.logger.Warn("failed to renew reservation",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		if status.Code(err) == codes.Unavailable {
+			http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, "Items are no longer available", http.StatusConflict)
+		return
+	}
+
+	paymentLink, err := h.requestPaymentLink(ctx, order.Id)
+	if errors.Is(err, errPaymentConflict) {
+		h.logger.Warn("payment link reissue rejected by payments",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		http.Error(w, "Order is already paid", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to create payment link",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		http.Error(w, "Failed to create payment link", http.StatusBadGateway)
+		return
+	}
+
+	h.logger.Info("payment link reissued",
+		slog.String("order_id", orderID),
+		slog.String("reservation_id", reservation.ReservationID),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"order_id":               order.Id,
+		"payment_link":           paymentLink,
+		"reservation_expires_at": reservation.ExpiresAt, // Neue Frist → Frontend startet den Countdown neu
+	})
+}
+
+// requestPaymentLink: Ruft den internen Payments Endpoint auf
+// Warum HTTP statt gRPC?
+// → Payments hat keinen gRPC Server, nur den HTTP Server (Webhook + intern)
+// Nur die Order ID → Payments lädt die Order selbst aus Orders
+func (h *handler) requestPaymentLink(ctx context.Context, orderID string) (string, error) {
+	return h.postPaymentLink(ctx, fmt.Sprintf("/orders/%s/payment-link", orderID))
+}
+
+// errPaymentConflict: Payments verweigert den Link (409), z.B. weil die alte Session schon bezahlt ist
+var errPaymentConflict = errors.New("payments rejected the payment link")
+
+// postPaymentLink: POST an einen internen Payments Endpoint → {"payment_link": "..."}
+// → Ohne Body (Payments lädt die Orders selbst), Authorization mit PAYMENTS_INTERNAL_TOKEN
+// → 409 → errPaymentConflict (Caller antwortet 409 statt 502)
+func (h *handler) postPaymentLink(ctx context.Context, path string) (string, error) {
+	url := fmt.Sprintf("http://%s%s", h.paymentsAddr, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+h.paymentsToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		return "", fmt.Errorf("%w: payments service returned %s", errPaymentConflict, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("payments service returned %s", resp.Status)
+	}
+
+	var result struct {
+		PaymentLink string `json:"payment_link"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode payments response: %w", err)
+	}
+
+	return result.PaymentLink, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/discovery/inmem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeOrders: Orders Service mit einer Order (GetOrder) bzw. den Orders einer Session
+// → CreateOrder merkt sich den Request, UpdateOrder den gesetzten Status
+type fakeOrders struct {
+	api.UnimplementedOrderServiceServer
+	order   *api.Order
+	session []*api.Order
+
+	mu      sync.Mutex
+	created []*api.CreateOrderRequest
+	updated []string
+}
+
+func (f *fakeOrders) CreateOrder(_ context.Context, req *api.CreateOrderRequest) (*api.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, req)
+	return &api.Order{Id: "o1", CustomerId: req.CustomerId, StripeAccount: req.StripeAccount}, nil
+}
+
+func (f *fakeOrders) GetOrder(context.Context, *api.GetOrderRequest) (*api.Order, error) {
+	return f.order, nil
+}
+
+func (f *fakeOrders) UpdateOrder(_ context.Context, o *api.Order) (*api.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updated = append(f.updated, o.Status)
+	return o, nil
+}
+
+func (f *fakeOrders) GetOrdersBySession(context.Context, *api.GetOrdersBySessionRequest) (*api.GetOrdersBySessionResponse, error) {
+	return &api.GetOrdersBySessionResponse{Orders: f.session}, nil
+}
+
+// fakeRenewStock: Stock Service der jede Reservation verlängert und die Calls zählt
+type fakeRenewStock struct {
+	api.UnimplementedStockServiceServer
+	renewals atomic.Int32
+}
+
+func (f *fakeRenewStock) RenewReservation(context.Context, *api.RenewReservationRequest) (*api.ReserveStockResponse, error) {
+	f.renewals.Add(1)
+	return &api.ReserveStockResponse{ReservationID: "res-1", ExpiresAt: "2026-01-01T00:15:00Z"}, nil
+}
+
+// fakePayments: Interner Payments HTTP Endpoint → merkt sich Pfad + Body jedes Calls
+// → Falscher Token → 401 wie im echten Payments Service
+type fakePayments struct {
+	mu     sync.Mutex
+	paths  []string
+	bodies [][]byte
+	status int // != 0 → Payments antwortet mit diesem Status statt einem Link
+}
+
+const testPaymentsToken = "payments-secret"
+
+func (f *fakePayments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+testPaymentsToken {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	f.paths = append(f.paths, r.URL.Path)
+	f.bodies = append(f.bodies, body)
+	status := f.status
+	f.mu.Unlock()
+
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"payment_link": "https://pay.example" + r.URL.Path})
+}
+
+func (f *fakePayments) Paths() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.paths...)
+}
+
+// newPaymentLinkTestHandler: Orders + Stock auf EINEM gRPC Server, Payments als httptest Server
+// Returns: Handler, Stock Fake und Payments Fake
+func newPaymentLinkTestHandler(t *testing.T, orders *fakeOrders) (*handler, *fakeRenewStock, *fakePayments) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stock := &fakeRenewStock{}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	api.RegisterOrderServiceServer(srv, orders)
+	api.RegisterStockServiceServer(srv, stock)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	registry := inmem.NewRegistry()
+	for _, name := range []string{"orders", "stock"} {
+		if err := registry.Register(context.Background(), name+"-1", name, lis.Addr().String()); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+
+	payments := &fakePayments{}
+	paymentsServer := httptest.NewServer(payments)
+	t.Cleanup(paymentsServer.Close)
+
+	paymentsAddr := strings.TrimPrefix(paymentsServer.URL, "http://")
+	h := NewHandler(registry, slog.New(slog.NewTextHandler(io.Discard, nil)), paymentsAddr, nil, nil, 0, 0, "")
+	h.paymentsToken = testPaymentsToken
+	t.Cleanup(func() { h.Close() })
+	return h, stock, payments
+}
+
+// serveRoute: Request durch die echten Routes → PathValues wie in Produktion
+func serveRoute(h *handler, method, path string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.registerRoute(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestReissuePaymentLink(t *testing.T) {
+	h, stock, payments := newPaymentLinkTestHandler(t, &fakeOrders{order: &api.Order{
+		Id:         "o1",
+		CustomerId: "c1",
+		Status:     orderstatus.StatusWaitingPayment,
+		Items:      []*api.Item{{ID: "1", Quantity: 2}},
+	}})
+
+	w := serveRoute(h, "POST", "/api/customers/c1/orders/o1/payment-link")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+
+	var resp map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp["payment_link"] != "https://pay.example/orders/o1/payment-link" {
+		t.Errorf("payment_link = %q; want the link from payments", resp["payment_link"])
+	}
+	if resp["reservation_expires_at"] != "2026-01-01T00:15:00Z" {
+		t.Errorf("reservation_expires_at = %q; want the renewed expiry", resp["reservation_expires_at"])
+	}
+	if n := stock.renewals.Load(); n != 1 {
+		t.Errorf("renewals = %d; want 1", n)
+	}
+	if paths := payments.Paths(); len(paths) != 1 {
+		t.Fatalf("payments calls = %v; want 1", paths)
+	}
+	// Nur die ID → Payments lädt Items + Status selbst aus Orders
+	if len(payments.bodies[0]) != 0 {
+		t.Errorf("payments body = %s; want empty", payments.bodies[0])
+	}
+}
+
+// Bezahlte Order → 409, weder Reservation noch neue Stripe Session
+func TestReissuePaymentLinkRejectsPaidOrder(t *testing.T) {
+	h, stock, payments := newPaymentLinkTestHandler(t, &fakeOrders{order: &api.Order{
+		Id:         "o1",
+		CustomerId: "c1",
+		Status:     orderstatus.StatusPaid,
+	}})
+
+	w := serveRoute(h, "POST", "/api/customers/c1/orders/o1/payment-link")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d; want 409", w.Code)
+	}
+	if n := stock.renewals.Load(); n != 0 {
+		t.Errorf("renewals = %d; want 0", n)
+	}
+	if paths := payments.Paths(); len(paths) != 0 {
+		t.Errorf("payments calls = %v; want none", paths)
+	}
+}
+
+// Payments verweigert den neuen Link (alte Stripe Session schon bezahlt) → 409 statt 502
+func TestReissuePaymentLinkPaymentsConflict(t *testing.T) {
+	h, _, payments := newPaymentLinkTestHandler(t, &fakeOrders{order: &api.Order{
+		Id:         "o1",
+		CustomerId: "c1",
+		Status:     orderstatus.StatusWaitingPayment,
+		Items:      []*api.Item{{ID: "1", Quantity: 2}},
+	}})
+	payments.status = http.StatusConflict
+
+	w := serveRoute(h, "POST", "/api/customers/c1/orders/o1/payment-link")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d (%s); want 409", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/common/telemetry"
+)
+
+// paymentStatusResponse: Countdown bis der Kunde bezahlt haben muss
+// Warum Sekunden vom Gateway statt nur Timestamps?
+// → Browser Uhr kann falsch gehen → Countdown auf Basis der Server Uhr ist genau
+// → Frontend zählt nur noch lokal runter und fragt gelegentlich neu an
+type paymentStatusResponse struct {
+	OrderID              string `json:"order_id"`
+	Status               string `json:"status"`
+	AwaitingPayment      bool   `json:"awaiting_payment"` // pending/waiting_payment → Countdown anzeigen
+	PaymentLink          string `json:"payment_link,omitempty"`
+	ReservationExpiresAt string `json:"reservation_expires_at,omitempty"`
+	PaymentLinkExpiresAt string `json:"payment_link_expires_at,omitempty"`
+
+	// *SecondsRemaining: nil = Ablauf unbekannt, 0 = abgelaufen
+	ReservationSecondsRemaining *int64 `json:"reservation_seconds_remaining,omitempty"`
+	PaymentLinkSecondsRemaining *int64 `json:"payment_link_seconds_remaining,omitempty"`
+	// SecondsRemaining: Das FRÜHERE der beiden Limits → das zeigt das Frontend an
+	SecondsRemaining *int64 `json:"seconds_remaining,omitempty"`
+}
+
+// secondsRemaining: RFC3339 Ablaufzeit → Sekunden ab now (nie negativ)
+// Returns: false wenn expiresAt leer/kaputt ist (Legacy Orders, Payments ohne Expiry)
+func secondsRemaining(expiresAt string, now time.Time) (int64, bool) {
+	if expiresAt == "" {
+		return 0, false
+	}
+	t, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return 0, false
+	}
+	remaining := int64(t.Sub(now) / time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// newPaymentStatus: Order + Server Zeit → Countdown Response
+// → Bezahlte/stornierte Orders bekommen keinen Countdown (awaiting_payment=false)
+func newPaymentStatus(order *api.Order, now time.Time) paymentStatusResponse {
+	resp := paymentStatusResponse{
+		OrderID:              order.Id,
+		Status:               order.Status,
+		AwaitingPayment:      order.Status == orderstatus.StatusPending || order.Status == orderstatus.StatusWaitingPayment,
+		PaymentLink:          order.PaymentLink,
+		ReservationExpiresAt: order.ReservationExpiresAt,
+		PaymentLinkExpiresAt: order.PaymentLinkExpiresAt,
+	}
+	if !resp.AwaitingPayment {
+		return resp
+	}
+
+	if s, ok := secondsRemaining(order.ReservationExpiresAt, now); ok {
+		resp.ReservationSecondsRemaining = &s
+		resp.SecondsRemaining = &s
+	}
+	if s, ok := secondsRemaining(order.PaymentLinkExpiresAt, now); ok {
+		resp.PaymentLinkSecondsRemaining = &s
+		if resp.SecondsRemaining == nil || s < *resp.SecondsRemaining {
+			resp.SecondsRemaining = &s
+		}
+	}
+	return resp
+}
+
+// handleGetPaymentStatus: GET /api/customers/{customerID}/orders/{orderID}/payment-status
+// → Success/Checkout Seite zeigt "Noch 12:34 zum Bezahlen", bei 0 → Link neu anfordern (POST .../payment-link)
+func (h *handler) handleGetPaymentStatus(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("customerID")
+	orderID := r.PathValue("orderID")
+
+	ctx := telemetry.WithCustomerID(r.Context(), customerID)
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	order, err := ordersClient.GetOrder(ctx, &api.GetOrderRequest{
+		OrderId:    orderID,
+		CustomerId: customerID,
+	})
+	if err != nil {
+		h.logger.Error("failed to get order",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to get order")
+		return
+	}
+
+	if order.CustomerId != customerID {
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	}
+
+	// no-store: Countdown ist nur im Moment der Antwort richtig
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPaymentStatus(order, time.Now()))
+}
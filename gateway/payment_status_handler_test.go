@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+func TestSecondsRemaining(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		expiresAt string
+		want      int64
+		wantOK    bool
+	}{
+		{"future", "2026-01-01T00:12:34Z", 754, true},
+		{"other zone", "2026-01-01T01:05:00+01:00", 300, true},
+		{"expired", "2025-12-31T23:59:00Z", 0, true},
+		{"empty", "", 0, false},
+		{"invalid", "tomorrow", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := secondsRemaining(tt.expiresAt, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("secondsRemaining(%q) = %d, %v; want %d, %v", tt.expiresAt, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// Countdown = das frühere der beiden Limits, bezahlte Orders bekommen keinen
+func TestNewPaymentStatus(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	order := &api.Order{
+		Id:                   "o1",
+		Status:               orderstatus.StatusWaitingPayment,
+		ReservationExpiresAt: "2026-01-01T00:15:00Z",
+		PaymentLinkExpiresAt: "2026-01-01T00:10:00Z",
+	}
+
+	resp := newPaymentStatus(order, now)
+	if !resp.AwaitingPayment {
+		t.Fatal("awaiting_payment = false; want true")
+	}
+	if resp.ReservationSecondsRemaining == nil || *resp.ReservationSecondsRemaining != 900 {
+		t.Errorf("reservation_seconds_remaining = %v; want 900", resp.ReservationSecondsRemaining)
+	}
+	if resp.PaymentLinkSecondsRemaining == nil || *resp.PaymentLinkSecondsRemaining != 600 {
+		t.Errorf("payment_link_seconds_remaining = %v; want 600", resp.PaymentLinkSecondsRemaining)
+	}
+	if resp.SecondsRemaining == nil || *resp.SecondsRemaining != 600 {
+		t.Errorf("seconds_remaining = %v; want 600 (payment link expires first)", resp.SecondsRemaining)
+	}
+
+	// Ohne Payment Link Expiry → Reservierung bestimmt den Countdown
+	order.PaymentLinkExpiresAt = ""
+	if resp := newPaymentStatus(order, now); resp.SecondsRemaining == nil || *resp.SecondsRemaining != 900 {
+		t.Errorf("seconds_remaining without link expiry = %v; want 900", resp.SecondsRemaining)
+	}
+
+	order.Status = orderstatus.StatusPaid
+	if resp := newPaymentStatus(order, now); resp.AwaitingPayment || resp.SecondsRemaining != nil {
+		t.Errorf("paid order = %+v; want no countdown", resp)
+	}
+}
+
+// Endpoint rechnet mit der gespeicherten Expiry der Order
+func TestGetPaymentStatus(t *testing.T) {
+	expiresAt := time.Now().Add(10 * time.Minute).UTC().Format(time.RFC3339)
+	h, _, _ := newPaymentLinkTestHandler(t, &fakeOrders{order: &api.Order{
+		Id:                   "o1",
+		CustomerId:           "c1",
+		Status:               orderstatus.StatusWaitingPayment,
+		PaymentLinkExpiresAt: expiresAt,
+	}})
+
+	w := serveRoute(h, "GET", "/api/customers/c1/orders/o1/payment-status")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Cache-Control = %q; want no-store", cc)
+	}
+
+	var resp paymentStatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.PaymentLinkExpiresAt != expiresAt {
+		t.Errorf("payment_link_expires_at = %q; want %q", resp.PaymentLinkExpiresAt, expiresAt)
+	}
+	if resp.SecondsRemaining == nil || *resp.SecondsRemaining < 590 || *resp.SecondsRemaining > 600 {
+		t.Errorf("seconds_remaining = %v; want ~600", resp.SecondsRemaining)
+	}
+
+	// Fremde Order → 404
+	if w := serveRoute(h, "GET", "/api/customers/c2/orders/o1/payment-status"); w.Code != http.StatusNotFound {
+		t.Errorf("other customer: status = %d; want 404", w.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultMenuCacheTTL: Wie lange Stripe Daten ohne erneuten Stripe Call ausgeliefert werden
+// → Preise/Produkte ändern sich selten, das Menu wird ständig geladen
+const DefaultMenuCacheTTL = 5 * time.Minute
+
+// cachedStripeData: Letzte erfolgreich von Stripe geladene Daten eines Items
+type cachedStripeData struct {
+	item      MenuItem
+	fetchedAt time.Time
+}
+
+// PriceCache: Stripe Daten (Name, Preis, Beschreibung, Bild) pro PriceID
+// Zwei Rollen:
+// → Frisch (jünger als ttl): Menu nutzt die Daten direkt → kein price.Get/product.Get pro Item und Request
+// → Last-Known-Good (älter): Stripe down / Breaker offen → Menu zeigt die zuletzt bekannten Preise
+// → Besser als ein erfundener Preis!
+type PriceCache struct {
+	mu    sync.RWMutex
+	items map[string]cachedStripeData
+	ttl   time.Duration    // <= 0 → nie frisch, jeder Request fragt Stripe (nur Fallback)
+	now   func() time.Time // Injizierbar → Tests ohne Sleep
+}
+
+func NewPriceCache(ttl time.Duration) *PriceCache {
+	return &PriceCache{
+		items: make(map[string]cachedStripeData),
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// Get: Last-Known-Good, egal wie alt
+func (c *PriceCache) Get(priceID string) (MenuItem, time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	data, ok := c.items[priceID]
+	return data.item, data.fetchedAt, ok
+}
+
+// Fresh: Nur Einträge jünger als ttl → Cache Hit ersetzt den Stripe Call
+// → Abgelaufene Einträge bleiben als Last-Known-Good für Get liegen
+func (c *PriceCache) Fresh(priceID string) (MenuItem, bool) {
+	if c.ttl <= 0 {
+		return MenuItem{}, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	data, ok := c.items[priceID]
+	if !ok || c.now().Sub(data.fetchedAt) >= c.ttl {
+		return MenuItem{}, false
+	}
+	return data.item, true
+}
+
+func (c *PriceCache) Set(priceID string, item MenuItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[priceID] = cachedStripeData{item: item, fetchedAt: c.now()}
+}
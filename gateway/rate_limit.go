@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitMaxClients: Ab so vielen Buckets werden volle (= inaktive) Clients aufgeräumt
+// → Ohne Aufräumen wächst die Map mit jeder neuen IP für immer
+const rateLimitMaxClients = 10000
+
+// clientRateLimiter: Token Bucket pro Client IP (RATE_LIMIT_PER_MINUTE)
+// Warum pro Client IP statt global?
+// → Ein einzelner Client (Script, Bot) soll nicht alle anderen Kunden mit ausbremsen
+// Warum TrustedProxies?
+// → Hinter dem Load Balancer ist RemoteAddr immer der LB → alle Clients teilen sich EINEN Bucket
+type clientRateLimiter struct {
+	perMinute int
+	proxies   TrustedProxies
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newClientRateLimiter: perMinute <= 0 → kein Limit (middleware reicht nur durch)
+func newClientRateLimiter(perMinute int, proxies TrustedProxies, logger *slog.Logger) *clientRateLimiter {
+	return &clientRateLimiter{
+		perMinute: perMinute,
+		proxies:   proxies,
+		logger:    logger,
+		now:       time.Now,
+		buckets:   make(map[string]*tokenBucket),
+	}
+}
+
+// allow: Einen Token für ip verbrauchen
+// Bucket fasst perMinute Tokens und füllt sich mit perMinute pro Minute wieder auf
+// Returns: false + Wartezeit bis zum nächsten Token wenn leer
+func (l *clientRateLimiter) allow(ip string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(l.perMinute)
+	perSecond := capacity / 60
+
+	b, ok := l.buckets[ip]
+	if !ok {
+		if len(l.buckets) >= rateLimitMaxClients {
+			l.prune(now, capacity, perSecond)
+		}
+		b = &tokenBucket{tokens: capacity, last: now}
+		l.buckets[ip] = b
+	}
+
+	b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune: Buckets entfernen, die inzwischen wieder voll wären (Client war lange genug still)
+func (l *clientRateLimiter) prune(now time.Time, capacity, perSecond float64) {
+	for ip, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*perSecond >= capacity {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+// middleware: 429 + Retry-After wenn die Client IP ihr Limit erreicht hat
+// /metrics bleibt ausgenommen → Prometheus Scrapes dürfen nie gedrosselt werden
+func (l *clientRateLimiter) middleware(next http.Handler) http.Handler {
+	if l.perMinute <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := l.proxies.ClientIP(r)
+		if ok, wait := l.allow(ip); !ok {
+			l.logger.Warn("rate limit exceeded",
+				slog.String("client_ip", ip),
+				slog.String("path", r.URL.Path),
+			)
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(perMinute int, proxies TrustedProxies) (*clientRateLimiter, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newClientRateLimiter(perMinute, proxies, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestClientRateLimiterRefills(t *testing.T) {
+	l, now := newTestRateLimiter(2, nil)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("203.0.113.7"); !ok {
+			t.Fatalf("request %d rejected; want allowed", i+1)
+		}
+	}
+	ok, wait := l.allow("203.0.113.7")
+	if ok || wait != 30*time.Second {
+		t.Fatalf("third request = %v, wait %s; want rejected with 30s wait", ok, wait)
+	}
+	if ok, _ := l.allow("203.0.113.8"); !ok {
+		t.Fatalf("other client rejected; buckets must be per IP")
+	}
+
+	*now = now.Add(30 * time.Second)
+	if ok, _ := l.allow("203.0.113.7"); !ok {
+		t.Fatalf("request after refill rejected")
+	}
+}
+
+func TestClientRateLimiterUsesTrustedClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := newTestRateLimiter(1, proxies)
+	h := l.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	do := func(remoteAddr, xff string) int {
+		r := httptest.NewRequest("GET", "/api/menu", nil)
+		r.RemoteAddr = remoteAddr
+		if xff != "" {
+			r.Header.Set("X-Forwarded-For", xff)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Zwei Clients hinter demselben Load Balancer → eigene Buckets
+	if code := do("10.0.0.1:1234", "203.0.113.7"); code != http.StatusOK {
+		t.Fatalf("client A = %d; want 200", code)
+	}
+	if code := do("10.0.0.1:1234", "203.0.113.8"); code != http.StatusOK {
+		t.Fatalf("client B behind same proxy = %d; want 200", code)
+	}
+	if code := do("10.0.0.1:1234", "203.0.113.7"); code != http.StatusTooManyRequests {
+		t.Fatalf("client A again = %d; want 429", code)
+	}
+
+	// Untrusted Peer kann sich per X-Forwarded-For keinen frischen Bucket erschleichen
+	if code := do("198.51.100.9:1234", "203.0.113.50"); code != http.StatusOK {
+		t.Fatalf("direct client = %d; want 200", code)
+	}
+	if code := do("198.51.100.9:1234", "203.0.113.51"); code != http.StatusTooManyRequests {
+		t.Fatalf("direct client with spoofed X-Forwarded-For = %d; want 429", code)
+	}
+}
+
+func TestClientRateLimiterDisabled(t *testing.T) {
+	l, _ := newTestRateLimiter(0, nil)
+	h := l.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 5; i++ {
+		r := httptest.NewRequest("GET", "/api/menu", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d = %d; want 200 with limit disabled", i+1, w.Code)
+		}
+	}
+}
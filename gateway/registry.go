@@ -2,26 +2,50 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/timour/order-microservices/discovery"
 )
 
+// RegisterBackoff: Wie oft und wie lange wir die Consul Registration wiederholen
+// Warum konfigurierbar?
+// → Beim Deploy startet Consul manchmal gleichzeitig mit den Services
+// → Lokal reichen wenige Versuche, in Kubernetes eher mehr
+type RegisterBackoff struct {
+	MaxAttempts int           // Versuche insgesamt (inkl. dem ersten)
+	Initial     time.Duration // Wartezeit nach dem ersten Fehlschlag
+	Max         time.Duration // Obergrenze für die (verdoppelte) Wartezeit
+}
+
+// DefaultRegisterBackoff: 5 Versuche → 500ms, 1s, 2s, 4s (~7.5s insgesamt)
+var DefaultRegisterBackoff = RegisterBackoff{
+	MaxAttempts: 5,
+	Initial:     500 * time.Millisecond,
+	Max:         5 * time.Second,
+}
+
 type ServiceRegistration struct {
 	registry    discovery.Registry
 	instanceID  string
 	serviceName string
+	addr        string
 	stopChan    chan struct{}
 }
 
 // RegisterService: Registriert Service bei Consul und startet Health Checks
+// Warum Retry?
+// → Consul kurz nicht erreichbar (Deploy, Restart) soll nicht den ganzen Service killen
+// → Erst nach backoff.MaxAttempts Fehlschlägen geben wir auf
 func RegisterService(
 	ctx context.Context,
 	registry discovery.Registry,
 	instanceID, serviceName, addr string,
+	backoff RegisterBackoff,
 ) (*ServiceRegistration, error) {
-	if err := registry.Register(ctx, instanceID, serviceName, addr); err != nil {
+	if err := registerWithRetry(ctx, registry, instanceID, serviceName, addr, backoff); err != nil {
 		return nil, err
 	}
 
@@ -29,6 +53,7 @@ func RegisterService(
 		registry:    registry,
 		instanceID:  instanceID,
 		serviceName: serviceName,
+		addr:        addr,
 		stopChan:    make(chan struct{}),
 	}
 
@@ -37,6 +62,40 @@ func RegisterService(
 	return sr, nil
 }
 
+// registerWithRetry: Register mit exponentiellem Backoff (Initial, 2x, 4x, ... bis Max)
+func registerWithRetry(
+	ctx context.Context,
+	registry discovery.Registry,
+	instanceID, serviceName, addr string,
+	backoff RegisterBackoff,
+) error {
+	attempts := max(backoff.MaxAttempts, 1)
+	wait := backoff.Initial
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = registry.Register(ctx, instanceID, serviceName, addr); err == nil {
+			return nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		log.Printf("Register attempt %d/%d failed, retrying in %s: %v", attempt, attempts, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait = min(wait*2, backoff.Max)
+	}
+
+	return fmt.Errorf("register %s after %d attempts: %w", serviceName, attempts, err)
+}
+
 func (sr *ServiceRegistration) startHealthCheck() {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -46,13 +105,33 @@ func (sr *ServiceRegistration) startHealthCheck() {
 		case <-sr.stopChan:
 			return
 		case <-ticker.C:
-			if err := sr.registry.HealthCheck(sr.instanceID, sr.serviceName); err != nil {
-				log.Printf("Health check failed: %v", err)
-			}
+			sr.healthCheck()
 		}
 	}
 }
 
+// healthCheck: Ein TTL Update - re-registriert wenn Consul die Instance nicht mehr kennt
+// Warum re-registrieren?
+// → Consul Restart (ohne persistente Daten) verliert alle Registrations
+// → Ohne Re-Register wäre der Service für immer unsichtbar, obwohl er läuft!
+func (sr *ServiceRegistration) healthCheck() {
+	err := sr.registry.HealthCheck(sr.instanceID, sr.serviceName)
+	if err == nil {
+		return
+	}
+
+	if !errors.Is(err, discovery.ErrNotRegistered) {
+		log.Printf("Health check failed: %v", err)
+		return
+	}
+
+	log.Printf("Instance %s unknown to registry, re-registering", sr.instanceID)
+	if err := sr.registry.Register(context.Background(), sr.instanceID, sr.serviceName, sr.addr); err != nil {
+		// Nächster Tick versucht es erneut
+		log.Printf("Re-register failed: %v", err)
+	}
+}
+
 // Deregister: Meldet Service bei Consul ab
 func (sr *ServiceRegistration) Deregister(ctx context.Context) error {
 	close(sr.stopChan)
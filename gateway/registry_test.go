@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timour/order-microservices/discovery"
+)
+
+// flakyRegistry: Register schlägt failRegister mal fehl, HealthCheck liefert healthErr
+type flakyRegistry struct {
+	discovery.Registry
+
+	mu           sync.Mutex
+	failRegister int
+	registers    int
+	healthErr    error
+}
+
+func (r *flakyRegistry) Register(context.Context, string, string, string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.registers++
+	if r.registers <= r.failRegister {
+		return errors.New("consul unavailable")
+	}
+	r.healthErr = nil // Registriert → Consul kennt die Instance wieder
+	return nil
+}
+
+func (r *flakyRegistry) HealthCheck(string, string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.healthErr
+}
+
+func (r *flakyRegistry) Deregister(context.Context, string, string) error { return nil }
+
+func (r *flakyRegistry) Registers() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.registers
+}
+
+var testBackoff = RegisterBackoff{MaxAttempts: 3, Initial: time.Millisecond, Max: 2 * time.Millisecond}
+
+func TestRegisterServiceRetriesThenSucceeds(t *testing.T) {
+	registry := &flakyRegistry{failRegister: 2}
+
+	sr, err := RegisterService(context.Background(), registry, "gateway-1", "gateway", "localhost:8081", testBackoff)
+	if err != nil {
+		t.Fatalf("RegisterService: %v", err)
+	}
+	defer sr.Deregister(context.Background())
+
+	if n := registry.Registers(); n != 3 {
+		t.Fatalf("Register called %d times; want 3 (2 failures + success)", n)
+	}
+}
+
+func TestRegisterServiceGivesUp(t *testing.T) {
+	registry := &flakyRegistry{failRegister: 10}
+
+	if _, err := RegisterService(context.Background(), registry, "gateway-1", "gateway", "localhost:8081", testBackoff); err == nil {
+		t.Fatal("RegisterService succeeded although consul never came up")
+	}
+	if n := registry.Registers(); n != testBackoff.MaxAttempts {
+		t.Fatalf("Register called %d times; want %d", n, testBackoff.MaxAttempts)
+	}
+}
+
+func TestHealthCheckReRegistersUnknownInstance(t *testing.T) {
+	registry := &flakyRegistry{healthErr: fmt.Errorf("%w: gateway-1", discovery.ErrNotRegistered)}
+	sr := &ServiceRegistration{registry: registry, instanceID: "gateway-1", serviceName: "gateway", addr: "localhost:8081"}
+
+	sr.healthCheck()
+	if n := registry.Registers(); n != 1 {
+		t.Fatalf("Register called %d times after unknown instance; want 1", n)
+	}
+
+	// Wieder registriert → normaler TTL Update, kein weiteres Register
+	sr.healthCheck()
+	if n := registry.Registers(); n != 1 {
+		t.Fatalf("Register called %d times; want still 1", n)
+	}
+}
+
+func TestHealthCheckDoesNotReRegisterOnOtherErrors(t *testing.T) {
+	registry := &flakyRegistry{healthErr: errors.New("consul timeout")}
+	sr := &ServiceRegistration{registry: registry, instanceID: "gateway-1", serviceName: "gateway", addr: "localhost:8081"}
+
+	sr.healthCheck()
+	if n := registry.Registers(); n != 0 {
+		t.Fatalf("Register called %d times on a plain consul error; want 0", n)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handleGetSessionOrders: GET /api/sessions/{sessionID}/orders
+// Alle Orders eines Tisches/einer Session (Dine-In)
+func (h *handler) handleGetSessionOrders(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+
+	h.logger.Info("get session orders request",
+		slog.String("session_id", sessionID),
+	)
+
+	ordersClient, err := h.getOrdersClient(r.Context())
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	response, err := ordersClient.GetOrdersBySession(r.Context(), &api.GetOrdersBySessionRequest{
+		SessionId: sessionID,
+	})
+	if err != nil {
+		h.logger.Error("failed to get session orders",
+			slog.String("session_id", sessionID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to get session orders")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleSessionPaymentLink: POST /api/sessions/{sessionID}/payment-link
+// Warum?
+// → Dine-In: Mehrere Bestellungen am Tisch → EIN Checkout für alles Unbezahlte
+//
+// Flow:
+// 1. Orders: Alle Orders der Session laden → nur pending/waiting_payment
+// 2. Stock: Reservation jeder Order verlängern (wie beim Einzel-Link)
+// 3. Payments: EINE Stripe Session mit allen Line Items → jede Order bekommt den Link
+// → Payments lädt die unbezahlten Orders der Session selbst aus Orders
+func (h *handler) handleSessionPaymentLink(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+
+	ctx, cancel := context.WithTimeout(r.Context(), paymentLinkTimeout)
+	defer cancel()
+
+	h.logger.Info("session payment link request",
+		slog.String("session_id", sessionID),
+	)
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	response, err := ordersClient.GetOrdersBySession(ctx, &api.GetOrdersBySessionRequest{
+		SessionId: sessionID,
+	})
+	if err != nil {
+		h.logger.Error("failed to get session orders",
+			slog.String("session_id", sessionID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to get session orders")
+		return
+	}
+
+	unpaid := unpaidOrders(response.Orders)
+	if len(unpaid) == 0 {
+		http.Error(w, fmt.Sprintf("Session %s has no unpaid orders", sessionID), http.StatusConflict)
+		return
+	}
+
+	// ⭐ Reservations verlängern BEVOR der Link rausgeht (siehe handleReissuePaymentLink)
+	conn, err := h.serviceConnection(ctx, "stock")
+	if err != nil {
+		h.logger.Error("failed to discover stock service", slog.Any("error", err))
+		http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	stockClient := api.NewStockServiceClient(conn)
+	for _, order := range unpaid {
+		_, err := stockClient.RenewReservation(ctx, &api.RenewReservationRequest{
+			OrderID: order.Id,
+			Items:   order.Items,
+		})
+		if err != nil {
+			h.logger.Warn("failed to renew reservation",
+				slog.String("session_id", sessionID),
+				slog.String("order_id", order.Id),
+				slog.Any("error", err),
+			)
+			if status.Code(err) == codes.Unavailable {
+				http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			http.Error(w, fmt.Sprintf("Items of order %s are no longer available", order.Id), http.StatusConflict)
+			return
+		}
+	}
+
+	paymentLink, err := h.postPaymentLink(ctx, fmt.Sprintf("/sessions/%s/payment-link", sessionID))
+	if err != nil {
+		h.logger.Error("failed to create session payment link",
+			slog.String("session_id", sessionID),
+			slog.Any("error", err),
+		)
+		http.Error(w, "Failed to create payment link", http.StatusBadGateway)
+		return
+	}
+
+	orderIDs := make([]string, 0, len(unpaid))
+	for _, order := range unpaid {
+		orderIDs = append(orderIDs, order.Id)
+	}
+
+	h.logger.Info("session payment link created",
+		slog.String("session_id", sessionID),
+		slog.Int("orders", len(unpaid)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"session_id":   sessionID,
+		"order_ids":    orderIDs,
+		"payment_link": paymentLink,
+	})
+}
+
+// unpaidOrders: Nur Orders die noch bezahlt werden können
+// → paid/preparing/ready/completed NIE nochmal abrechnen
+func unpaidOrders(orders []*api.Order) []*api.Order {
+	var unpaid []*api.Order
+	for _, order := range orders {
+		if order.Status == orderstatus.StatusPending || order.Status == orderstatus.StatusWaitingPayment {
+			unpaid = append(unpaid, order)
+		}
+	}
+	return unpaid
+}
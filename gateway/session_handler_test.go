@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// Tisch mit 3 Orders: nur die unbezahlten landen im gemeinsamen Checkout
+func TestSessionPaymentLinkCombinesUnpaidOrders(t *testing.T) {
+	h, stock, payments := newPaymentLinkTestHandler(t, &fakeOrders{session: []*api.Order{
+		{Id: "o1", CustomerId: "c1", SessionId: "t7", Status: orderstatus.StatusWaitingPayment, Items: []*api.Item{{ID: "1", PriceID: "price_burger", Quantity: 1}}},
+		{Id: "o2", CustomerId: "c2", SessionId: "t7", Status: orderstatus.StatusPending, Items: []*api.Item{{ID: "1", PriceID: "price_burger", Quantity: 2}}},
+		{Id: "o3", CustomerId: "c3", SessionId: "t7", Status: orderstatus.StatusPaid, Items: []*api.Item{{ID: "2", PriceID: "price_pommes", Quantity: 1}}},
+	}})
+
+	w := serveRoute(h, "POST", "/api/sessions/t7/payment-link")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		OrderIDs    []string `json:"order_ids"`
+		PaymentLink string   `json:"payment_link"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !slices.Equal(resp.OrderIDs, []string{"o1", "o2"}) {
+		t.Errorf("order_ids = %v; want [o1 o2]", resp.OrderIDs)
+	}
+	if n := stock.renewals.Load(); n != 2 {
+		t.Errorf("renewals = %d; want 2 (one per unpaid order)", n)
+	}
+
+	// EIN Payments Call für die ganze Session, ohne Body → Payments lädt die Orders selbst
+	if paths := payments.Paths(); !slices.Equal(paths, []string{"/sessions/t7/payment-link"}) {
+		t.Fatalf("payments calls = %v; want one session call", paths)
+	}
+	if len(payments.bodies[0]) != 0 {
+		t.Errorf("payments body = %s; want empty", payments.bodies[0])
+	}
+}
+
+// Alles bezahlt → 409, kein neuer Checkout
+func TestSessionPaymentLinkRejectsPaidSession(t *testing.T) {
+	h, _, payments := newPaymentLinkTestHandler(t, &fakeOrders{session: []*api.Order{
+		{Id: "o1", SessionId: "t7", Status: orderstatus.StatusPaid},
+		{Id: "o2", SessionId: "t7", Status: orderstatus.StatusCompleted},
+	}})
+
+	w := serveRoute(h, "POST", "/api/sessions/t7/payment-link")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d; want 409", w.Code)
+	}
+	if paths := payments.Paths(); len(paths) != 0 {
+		t.Errorf("payments calls = %v; want none", paths)
+	}
+}
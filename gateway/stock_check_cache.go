@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stockCheckCacheTTL: Wie lange ein Availability Ergebnis wiederverwendet wird
+// → Kunde klickt +/+/+ im Warenkorb → EIN gRPC Call statt drei
+// → Kurz genug dass "verfügbar" nicht lange veraltet ist (Reservierung prüft ohnehin nochmal)
+const stockCheckCacheTTL = 2 * time.Second
+
+// stockCheckCacheMaxEntries: Ab hier werden abgelaufene Einträge beim Set aufgeräumt
+const stockCheckCacheMaxEntries = 1000
+
+type cachedStockCheck struct {
+	response  StockCheckResponse
+	expiresAt time.Time
+}
+
+// StockCheckCache: Kurzlebiger Cache für POST /api/stock/check
+// Key = normalisierter Warenkorb → gleiche Items in anderer Reihenfolge treffen denselben Eintrag
+type StockCheckCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedStockCheck
+	ttl     time.Duration
+	now     func() time.Time // Injizierbar → Tests ohne Sleep
+}
+
+func NewStockCheckCache(ttl time.Duration) *StockCheckCache {
+	return &StockCheckCache{
+		entries: make(map[string]cachedStockCheck),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+func (c *StockCheckCache) Get(items []CreateOrderItem) (StockCheckResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := stockCheckKey(items)
+	entry, ok := c.entries[key]
+	if !ok {
+		return StockCheckResponse{}, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return StockCheckResponse{}, false
+	}
+	return entry.response, true
+}
+
+func (c *StockCheckCache) Set(items []CreateOrderItem, response StockCheckResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= stockCheckCacheMaxEntries {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+	}
+
+	c.entries[stockCheckKey(items)] = cachedStockCheck{
+		response:  response,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// stockCheckKey: "1:2,2:1" (sortiert nach ID)
+func stockCheckKey(items []CreateOrderItem) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		parts = append(parts, fmt.Sprintf("%s:%d", item.ID, item.Quantity))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+// StockCheckResult: Verfügbarkeit EINES Warenkorb Items
+type StockCheckResult struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Quantity  int32  `json:"quantity"`
+	Available bool   `json:"available"`
+}
+
+// StockCheckResponse: Antwort von POST /api/stock/check
+type StockCheckResponse struct {
+	InStock bool               `json:"in_stock"` // true = ganzer Warenkorb bestellbar
+	Items   []StockCheckResult `json:"items"`
+}
+
+// handleStockCheck: POST /api/stock/check
+// Body: [{"id": "1", "quantity": 2}, ...]
+// Warum eigener Endpoint?
+// → Frontend will bei jeder Warenkorb Änderung sofort wissen ob alles lieferbar ist
+// → Vorher erst beim Order Create (→ Fehler NACH dem Klick auf "Bestellen")
+func (h *handler) handleStockCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var items []CreateOrderItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateItems(items); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, ok := h.stockCheckCache.Get(items)
+	if !ok {
+		stockClient, err := h.getStockClient(ctx)
+		if err != nil {
+			h.logger.Error("failed to discover stock service", slog.Any("error", err))
+			http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		protoItems := make([]*api.ItemsWithQuantity, len(items))
+		for i, item := range items {
+			protoItems[i] = &api.ItemsWithQuantity{ID: item.ID, Quantity: item.Quantity}
+		}
+
+		stockResponse, err := stockClient.CheckIfItemIsInStock(ctx, &api.CheckIfItemIsInStockRequest{
+			Items: protoItems,
+		})
+		if err != nil {
+			h.logger.Error("failed to check stock", slog.Any("error", err))
+			http.Error(w, "Failed to check stock", http.StatusBadGateway)
+			return
+		}
+
+		response = mapStockCheck(items, stockResponse)
+		h.stockCheckCache.Set(items, response)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// mapStockCheck: Stock Antwort → Ergebnis pro angefragtem Item
+// Warum so umständlich?
+// → CheckIfItemIsInStock liefert nur EIN bool für den ganzen Warenkorb
+// → InStock=false → Items enthalten den Lagerbestand → pro Item vergleichen
+// → Items die Stock nicht kennt fehlen in der Antwort → nicht verfügbar
+// → Vorübergehend deaktivierte Items (Unavailable) → nicht verfügbar, egal wie viel Bestand
+func mapStockCheck(items []CreateOrderItem, resp *api.CheckIfItemIsInStockResponse) StockCheckResponse {
+	known := make(map[string]*api.Item, len(resp.Items))
+	for _, item := range resp.Items {
+		known[item.ID] = item
+	}
+
+	result := StockCheckResponse{
+		InStock: true,
+		Items:   make([]StockCheckResult, 0, len(items)),
+	}
+	for _, item := range items {
+		stockItem, ok := known[item.ID]
+		available := ok && !stockItem.Unavailable && (resp.InStock || stockItem.Quantity >= item.Quantity)
+
+		check := StockCheckResult{ID: item.ID, Quantity: item.Quantity, Available: available}
+		if ok {
+			check.Name = stockItem.Name
+		}
+		if !available {
+			result.InStock = false
+		}
+		result.Items = append(result.Items, check)
+	}
+
+	return result
+}
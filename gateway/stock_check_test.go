@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+func TestMapStockCheck(t *testing.T) {
+	cart := []CreateOrderItem{{ID: "1", Quantity: 2}, {ID: "2", Quantity: 5}}
+
+	tests := []struct {
+		name      string
+		resp      *api.CheckIfItemIsInStockResponse
+		inStock   bool
+		available []bool
+	}{
+		{
+			name: "everything in stock",
+			resp: &api.CheckIfItemIsInStockResponse{InStock: true, Items: []*api.Item{
+				{ID: "1", Name: "Burger", Quantity: 2}, {ID: "2", Name: "Pommes", Quantity: 5},
+			}},
+			inStock:   true,
+			available: []bool{true, true},
+		},
+		{
+			name: "one item short",
+			resp: &api.CheckIfItemIsInStockResponse{InStock: false, Items: []*api.Item{
+				{ID: "1", Name: "Burger", Quantity: 20}, {ID: "2", Name: "Pommes", Quantity: 3},
+			}},
+			inStock:   false,
+			available: []bool{true, false},
+		},
+		{
+			name: "unknown item",
+			resp: &api.CheckIfItemIsInStockResponse{InStock: false, Items: []*api.Item{
+				{ID: "1", Name: "Burger", Quantity: 20},
+			}},
+			inStock:   false,
+			available: []bool{true, false},
+		},
+		{
+			name: "deactivated item with stock",
+			resp: &api.CheckIfItemIsInStockResponse{InStock: false, Items: []*api.Item{
+				{ID: "1", Name: "Burger", Quantity: 20, Unavailable: true}, {ID: "2", Name: "Pommes", Quantity: 15},
+			}},
+			inStock:   false,
+			available: []bool{false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapStockCheck(cart, tt.resp)
+			if got.InStock != tt.inStock {
+				t.Errorf("InStock = %v; want %v", got.InStock, tt.inStock)
+			}
+			if len(got.Items) != len(cart) {
+				t.Fatalf("items = %d; want one per cart item", len(got.Items))
+			}
+			for i, item := range got.Items {
+				if item.ID != cart[i].ID || item.Quantity != cart[i].Quantity || item.Available != tt.available[i] {
+					t.Errorf("item %d = %+v; want id %s qty %d available %v", i, item, cart[i].ID, cart[i].Quantity, tt.available[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStockCheckCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewStockCheckCache(2 * time.Second)
+	c.now = func() time.Time { return now }
+
+	c.Set([]CreateOrderItem{{ID: "1", Quantity: 2}, {ID: "2", Quantity: 1}}, StockCheckResponse{InStock: true})
+
+	// Gleicher Warenkorb, andere Reihenfolge → Treffer
+	if _, ok := c.Get([]CreateOrderItem{{ID: "2", Quantity: 1}, {ID: "1", Quantity: 2}}); !ok {
+		t.Fatal("reordered cart missed the cache")
+	}
+	// Andere Menge → eigener Eintrag
+	if _, ok := c.Get([]CreateOrderItem{{ID: "1", Quantity: 3}, {ID: "2", Quantity: 1}}); ok {
+		t.Fatal("different quantity hit the cache")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := c.Get([]CreateOrderItem{{ID: "1", Quantity: 2}, {ID: "2", Quantity: 1}}); ok {
+		t.Fatal("entry still cached after TTL")
+	}
+}
+
+// fakeStockCheck: CheckIfItemIsInStock mit Call Zähler
+type fakeStockCheck struct {
+	api.UnimplementedStockServiceServer
+	calls atomic.Int32
+}
+
+func (f *fakeStockCheck) CheckIfItemIsInStock(_ context.Context, req *api.CheckIfItemIsInStockRequest) (*api.CheckIfItemIsInStockResponse, error) {
+	f.calls.Add(1)
+	items := make([]*api.Item, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &api.Item{ID: item.ID, Name: "Item " + item.ID, Quantity: 10})
+	}
+	return &api.CheckIfItemIsInStockResponse{InStock: true, Items: items}, nil
+}
+
+// Schnelle Warenkorb Änderungen → EIN gRPC Call, danach aus dem Cache
+func TestStockCheckProxiesAndCaches(t *testing.T) {
+	stock := &fakeStockCheck{}
+	h := newStockTestHandler(t, stock)
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		h.handleStockCheck(w, httptest.NewRequest("POST", "/api/stock/check", strings.NewReader(`[{"id":"1","quantity":2}]`)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+		}
+
+		var resp StockCheckResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if !resp.InStock || len(resp.Items) != 1 || resp.Items[0].Name != "Item 1" || !resp.Items[0].Available {
+			t.Fatalf("response = %+v; want item 1 available", resp)
+		}
+	}
+
+	if n := stock.calls.Load(); n != 1 {
+		t.Fatalf("stock calls = %d; want 1 (rest served from cache)", n)
+	}
+}
+
+func TestStockCheckRejectsInvalidCart(t *testing.T) {
+	stock := &fakeStockCheck{}
+	h := newStockTestHandler(t, stock)
+
+	w := httptest.NewRecorder()
+	h.handleStockCheck(w, httptest.NewRequest("POST", "/api/stock/check", strings.NewReader(`[{"id":"1","quantity":0}]`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; want 400", w.Code)
+	}
+	if n := stock.calls.Load(); n != 0 {
+		t.Fatalf("stock calls = %d; want 0", n)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+// StuckOrdersResponse: Antwort von GET /api/admin/orders/stuck
+type StuckOrdersResponse struct {
+	OlderThan string       `json:"older_than"` // Effektiver Threshold ("" = Default des Orders Service)
+	Count     int          `json:"count"`
+	Orders    []*api.Order `json:"orders"`
+}
+
+// handleGetStuckOrders: GET /api/admin/orders/stuck?older_than=30m (Admin Token)
+// Support: Welche Orders hängen in pending/waiting_payment fest?
+// → older_than fehlt → Orders Service nimmt die Reservation TTL (15m)
+func (h *handler) handleGetStuckOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var olderThan time.Duration
+	if v := r.URL.Query().Get("older_than"); v != "" {
+		var err error
+		olderThan, err = time.ParseDuration(v)
+		if err != nil || olderThan < time.Second {
+			http.Error(w, "older_than must be a duration of at least 1s (e.g. 30m)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	resp, err := ordersClient.GetStuckOrders(ctx, &api.GetStuckOrdersRequest{
+		OlderThanSeconds: int64(olderThan / time.Second),
+	})
+	if err != nil {
+		h.logger.Error("failed to get stuck orders", slog.Any("error", err))
+		writeGRPCError(w, err, "Failed to get stuck orders")
+		return
+	}
+
+	result := StuckOrdersResponse{
+		Count:  len(resp.Orders),
+		Orders: resp.Orders,
+	}
+	if olderThan > 0 {
+		result.OlderThan = olderThan.String()
+	}
+	if result.Orders == nil {
+		result.Orders = []*api.Order{} // [] statt null → Dashboard muss nicht auf null prüfen
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// CancelUnpaidOrdersResponse: Antwort von POST /api/admin/customers/{customerID}/orders/cancel-unpaid
+type CancelUnpaidOrdersResponse struct {
+	Cancelled int32 `json:"cancelled"`
+	Failed    int32 `json:"failed"` // > 0 → nochmal aufrufen, stornierte Orders werden nicht doppelt angefasst
+}
+
+// handleCancelUnpaidOrders: POST /api/admin/customers/{customerID}/orders/cancel-unpaid (Admin Token)
+// Support: Verlassene Warenkörbe eines Kunden abräumen → Reservationen werden freigegeben
+func (h *handler) handleCancelUnpaidOrders(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("customerID")
+	ctx := r.Context()
+
+	ordersClient, err := h.getOrdersClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover orders service", slog.Any("error", err))
+		http.Error(w, "Orders service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	resp, err := ordersClient.CancelCustomerUnpaidOrders(ctx, &api.CancelCustomerUnpaidOrdersRequest{
+		CustomerId: customerID,
+	})
+	if err != nil {
+		h.logger.Error("failed to cancel unpaid orders",
+			slog.String("customer_id", customerID),
+			slog.Any("error", err),
+		)
+		writeGRPCError(w, err, "Failed to cancel unpaid orders")
+		return
+	}
+
+	h.logger.Info("unpaid orders cancelled by admin",
+		slog.String("customer_id", customerID),
+		slog.Int("cancelled", int(resp.Cancelled)),
+		slog.Int("failed", int(resp.Failed)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CancelUnpaidOrdersResponse{Cancelled: resp.Cancelled, Failed: resp.Failed})
+}
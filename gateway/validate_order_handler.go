@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+// ValidatedOrderItem: Ein Item der "would-be" Order inkl. Preis
+type ValidatedOrderItem struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name,omitempty"`
+	PriceID   string  `json:"price_id,omitempty"`
+	Quantity  int32   `json:"quantity"`
+	Available bool    `json:"available"`
+	UnitPrice float64 `json:"unit_price"`
+	Subtotal  float64 `json:"subtotal"`
+	// Stale: Preis aus dem PriceCache (Stripe nicht erreichbar)
+	Stale bool `json:"stale,omitempty"`
+	// PriceUnavailable: Kein Preis bekannt → Subtotal zählt nicht zum Total
+	PriceUnavailable bool `json:"price_unavailable,omitempty"`
+}
+
+// OrderValidationResponse: Antwort von POST /api/customers/{customerID}/orders/validate
+type OrderValidationResponse struct {
+	CustomerID       string               `json:"customer_id"`
+	Valid            bool                 `json:"valid"`    // true = CreateOrder würde (Stand jetzt) durchgehen
+	InStock          bool                 `json:"in_stock"` // true = ganzer Warenkorb lieferbar
+	Items            []ValidatedOrderItem `json:"items"`
+	Total            float64              `json:"total"`
+	PriceUnavailable bool                 `json:"price_unavailable,omitempty"` // Total ist unvollständig
+}
+
+// handleValidateOrder: POST /api/customers/{customerID}/orders/validate
+// Body: wie beim Create ([{"id": "1", "quantity": 2}, ...])
+// Warum ein Dry-Run?
+// → Client will VOR dem Bestellen Items, Verfügbarkeit und Total sehen
+// → Nur lesende Calls: CheckIfItemIsInStock + Stripe Preise
+// → KEIN Orders Call → keine Order in Mongo, keine Reservierung in Stock, keine Stripe Session
+func (h *handler) handleValidateOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerID := r.PathValue("customerID")
+
+	var items []CreateOrderItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateItems(items); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stockClient, err := h.getStockClient(ctx)
+	if err != nil {
+		h.logger.Error("failed to discover stock service", slog.Any("error", err))
+		http.Error(w, "Stock service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	protoItems := make([]*api.ItemsWithQuantity, len(items))
+	for i, item := range items {
+		protoItems[i] = &api.ItemsWithQuantity{ID: item.ID, Quantity: item.Quantity}
+	}
+
+	stockResponse, err := stockClient.CheckIfItemIsInStock(ctx, &api.CheckIfItemIsInStockRequest{
+		Items: protoItems,
+	})
+	if err != nil {
+		h.logger.Error("failed to check stock", slog.Any("error", err))
+		http.Error(w, "Failed to check stock", http.StatusBadGateway)
+		return
+	}
+
+	// Preise wie im Menu: Stripe über den Breaker, sonst Last-Known-Good aus dem PriceCache
+	prices := make(map[string]*MenuItem, len(stockResponse.Items))
+	for _, item := range stockResponse.Items {
+		menuItem, err := h.getMenuItemWithStripeData(ctx, item)
+		if err != nil {
+			h.logger.Warn("failed to get stripe data for item",
+				slog.String("item_id", item.ID),
+				slog.Any("error", err),
+			)
+			menuItem = h.fallbackMenuItem(item)
+		}
+		prices[item.ID] = menuItem
+	}
+
+	response := buildOrderValidation(customerID, items, stockResponse, prices)
+
+	h.logger.Info("order validated",
+		slog.String("customer_id", customerID),
+		slog.Bool("valid", response.Valid),
+		slog.Float64("total", response.Total),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// buildOrderValidation: Stock Check + Preise → would-be Order
+// → Availability pro Item über mapStockCheck (gleiche Logik wie POST /api/stock/check)
+// → Total in Cent aufsummiert → keine Float Rundungsfehler bei vielen Items
+func buildOrderValidation(customerID string, items []CreateOrderItem, stockResponse *api.CheckIfItemIsInStockResponse, prices map[string]*MenuItem) OrderValidationResponse {
+	check := mapStockCheck(items, stockResponse)
+
+	response := OrderValidationResponse{
+		CustomerID: customerID,
+		InStock:    check.InStock,
+		Items:      make([]ValidatedOrderItem, 0, len(check.Items)),
+	}
+
+	var totalCents int64
+	for _, item := range check.Items {
+		validated := ValidatedOrderItem{
+			ID:        item.ID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			Available: item.Available,
+		}
+
+		menuItem, ok := prices[item.ID]
+		switch {
+		case !ok || menuItem.PriceUnavailable:
+			validated.PriceUnavailable = true
+			response.PriceUnavailable = true
+		default:
+			unitCents := int64(math.Round(menuItem.Price * 100))
+			totalCents += unitCents * int64(item.Quantity)
+
+			validated.PriceID = menuItem.PriceID
+			validated.UnitPrice = menuItem.Price
+			validated.Subtotal = float64(unitCents*int64(item.Quantity)) / 100.0
+			validated.Stale = menuItem.Stale
+		}
+		if ok && validated.Name == "" {
+			validated.Name = menuItem.Name
+		}
+
+		response.Items = append(response.Items, validated)
+	}
+
+	response.Total = float64(totalCents) / 100.0
+	response.Valid = response.InStock
+	return response
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/discovery/inmem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeValidateStock: Alles mit 10 Stück auf Lager, zählt Reservierungen
+type fakeValidateStock struct {
+	api.UnimplementedStockServiceServer
+	reservations atomic.Int32
+}
+
+func (f *fakeValidateStock) CheckIfItemIsInStock(_ context.Context, req *api.CheckIfItemIsInStockRequest) (*api.CheckIfItemIsInStockResponse, error) {
+	inStock := true
+	items := make([]*api.Item, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &api.Item{ID: item.ID, Name: "Item " + item.ID, PriceID: "price_" + item.ID, Quantity: 10})
+		if item.Quantity > 10 {
+			inStock = false
+		}
+	}
+	return &api.CheckIfItemIsInStockResponse{InStock: inStock, Items: items}, nil
+}
+
+func (f *fakeValidateStock) ReserveStock(context.Context, *api.ReserveStockRequest) (*api.ReserveStockResponse, error) {
+	f.reservations.Add(1)
+	return &api.ReserveStockResponse{ReservationID: "res-1"}, nil
+}
+
+// fakeValidateOrders: Zählt CreateOrder Calls → beim Dry-Run muss es 0 bleiben
+type fakeValidateOrders struct {
+	api.UnimplementedOrderServiceServer
+	creates atomic.Int32
+}
+
+func (f *fakeValidateOrders) CreateOrder(context.Context, *api.CreateOrderRequest) (*api.Order, error) {
+	f.creates.Add(1)
+	return &api.Order{Id: "o1"}, nil
+}
+
+// newValidateTestHandler: Stock UND Orders Fake in der Registry → ein versehentlicher Orders Call würde gezählt
+func newValidateTestHandler(t *testing.T) (*handler, *fakeValidateStock, *fakeValidateOrders) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stock := &fakeValidateStock{}
+	orders := &fakeValidateOrders{}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	api.RegisterStockServiceServer(srv, stock)
+	api.RegisterOrderServiceServer(srv, orders)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	registry := inmem.NewRegistry()
+	for _, name := range []string{"stock", "orders"} {
+		if err := registry.Register(context.Background(), name+"-1", name, lis.Addr().String()); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	h := NewHandler(registry, slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil, 0, 0, "")
+	t.Cleanup(func() { h.Close() })
+	return h, stock, orders
+}
+
+func validateOrder(h *handler, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/api/customers/c1/orders/validate", strings.NewReader(body))
+	r.SetPathValue("customerID", "c1")
+	w := httptest.NewRecorder()
+	h.handleValidateOrder(w, r)
+	return w
+}
+
+// Dry-Run: Items, Verfügbarkeit und Total → aber keine Order und keine Reservierung
+func TestValidateOrderPersistsAndReservesNothing(t *testing.T) {
+	t.Setenv("STRIPE_SECRET_KEY", "") // Kein Stripe → Preise nur aus dem PriceCache
+	h, stock, orders := newValidateTestHandler(t)
+	h.priceCache.Set("price_1", MenuItem{Name: "Burger", PriceID: "price_1", Price: 8.5})
+	h.priceCache.Set("price_2", MenuItem{Name: "Pommes", PriceID: "price_2", Price: 3.2})
+
+	w := validateOrder(h, `[{"id":"1","quantity":2},{"id":"2","quantity":3}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+
+	var resp OrderValidationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Valid || !resp.InStock || resp.CustomerID != "c1" {
+		t.Fatalf("response = %+v; want valid in-stock order for c1", resp)
+	}
+	if resp.Total != 26.6 {
+		t.Errorf("total = %v; want 26.6", resp.Total)
+	}
+	if len(resp.Items) != 2 || resp.Items[0].Subtotal != 17 || resp.Items[1].Subtotal != 9.6 {
+		t.Errorf("items = %+v; want subtotals 17 and 9.6", resp.Items)
+	}
+
+	if n := stock.reservations.Load(); n != 0 {
+		t.Errorf("ReserveStock calls = %d; want 0", n)
+	}
+	if n := orders.creates.Load(); n != 0 {
+		t.Errorf("CreateOrder calls = %d; want 0", n)
+	}
+}
+
+// Zu viel im Warenkorb → invalid, aber trotzdem 200 mit Details
+func TestValidateOrderOutOfStock(t *testing.T) {
+	t.Setenv("STRIPE_SECRET_KEY", "")
+	h, stock, orders := newValidateTestHandler(t)
+
+	w := validateOrder(h, `[{"id":"1","quantity":11}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+
+	var resp OrderValidationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Valid || resp.InStock || len(resp.Items) != 1 || resp.Items[0].Available {
+		t.Fatalf("response = %+v; want invalid order with item 1 unavailable", resp)
+	}
+	// Kein Preis im Cache und kein Stripe → Total unvollständig
+	if !resp.PriceUnavailable {
+		t.Errorf("price_unavailable = false; want true")
+	}
+	if stock.reservations.Load() != 0 || orders.creates.Load() != 0 {
+		t.Errorf("reservations = %d, creates = %d; want 0, 0", stock.reservations.Load(), orders.creates.Load())
+	}
+}
+
+func TestValidateOrderRejectsInvalidCart(t *testing.T) {
+	h, _, _ := newValidateTestHandler(t)
+
+	if w := validateOrder(h, `[{"id":"1","quantity":0}]`); w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; want 400", w.Code)
+	}
+}
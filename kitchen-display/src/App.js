@@ -3,6 +3,9 @@ import './App.css';
 
 const ORDERS_API = 'http://localhost:8081/api';
 
+// Statuswechsel außer "cancelled" laufen über die Admin Route → ADMIN_TOKEN des Gateways
+const ADMIN_TOKEN = process.env.REACT_APP_ADMIN_TOKEN || '';
+
 // Fetch orders with status="preparing"
 const fetchPreparingOrders = async () => {
   const response = await fetch(`${ORDERS_API}/orders?status=preparing`);
@@ -39,10 +42,11 @@ function App() {
     setError(null);
 
     try {
-      const response = await fetch(`${ORDERS_API}/customers/${customerId}/orders/${orderId}`, {
+      const response = await fetch(`${ORDERS_API}/admin/customers/${customerId}/orders/${orderId}`, {
         method: 'PUT',
         headers: {
-          'Content-Type': 'application/json'
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${ADMIN_TOKEN}`
         },
         body: JSON.stringify({ status: 'ready' })
       });
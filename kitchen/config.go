@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/timour/order-microservices/common/config"
+	"github.com/timour/order-microservices/common/discovery"
+)
+
+// Defaults: Lokales Setup (docker-compose Ports), per ENV überschreibbar
+const (
+	// Prep-Time SLA: Order länger als PrepSLAThreshold in "preparing" → order.sla_breach
+	DefaultPrepSLAThreshold = 20 * time.Minute
+	DefaultSLACheckInterval = 30 * time.Second
+
+	// Consumer Idle Warnung: 0 = aus
+	DefaultConsumerIdleThreshold = time.Duration(0)
+	DefaultIdleCheckInterval     = time.Minute
+)
+
+// Config: Alles was der Kitchen Service beim Start braucht
+// Warum Struct statt Package Vars?
+// → Vorher fest verdrahtet auf localhost → lief nur im lokalen Setup
+// → Gleiches Muster wie Orders/Payments/Gateway: config.GetEnv mit denselben Defaults
+type Config struct {
+	ServiceName string
+	InstanceID  string // "" → discovery.GenerateInstanceID
+	HTTPAddr    string
+	ConsulAddr  string
+
+	AMQPUser string
+	AMQPPass string
+	AMQPHost string // Komma-separiert für Cluster Nodes
+	AMQPPort string
+
+	PrepSLAThreshold      time.Duration
+	SLACheckInterval      time.Duration
+	ConsumerIdleThreshold time.Duration // 0 = aus
+	IdleCheckInterval     time.Duration
+
+	// Wartezeit Schätzung (GET /api/kitchen/wait-estimate)
+	PrepTimes PrepTimes // ITEM_PREP_TIMES ("Burger=8m,Pommes=3m") + DEFAULT_ITEM_PREP_TIME
+	Stations  int       // KITCHEN_STATIONS
+}
+
+// LoadConfig: Config aus der Umgebung
+// Ungültige Duration → Fehler statt stillem Default (Tippfehler soll den Start abbrechen)
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		ServiceName: config.GetEnv("SERVICE_NAME", "kitchen"),
+		HTTPAddr:    config.GetEnv("HTTP_ADDR", "localhost:8083"),
+		ConsulAddr:  config.GetEnv("CONSUL_ADDR", "localhost:8500"),
+		AMQPUser:    config.GetEnv("AMQP_USER", "guest"),
+		AMQPPass:    config.GetEnv("AMQP_PASS", "guest"),
+		AMQPHost:    config.GetEnv("AMQP_HOST", "localhost"),
+		AMQPPort:    config.GetEnv("AMQP_PORT", "5672"),
+	}
+	cfg.InstanceID = config.GetEnv("INSTANCE_ID", discovery.GenerateInstanceID(cfg.ServiceName))
+
+	var defaultPrepTime time.Duration // Nur Zwischenwert für ParsePrepTimes
+	durations := []struct {
+		key      string
+		fallback time.Duration
+		dst      *time.Duration
+	}{
+		{"PREP_SLA_THRESHOLD", DefaultPrepSLAThreshold, &cfg.PrepSLAThreshold},
+		{"SLA_CHECK_INTERVAL", DefaultSLACheckInterval, &cfg.SLACheckInterval},
+		{"CONSUMER_IDLE_THRESHOLD", DefaultConsumerIdleThreshold, &cfg.ConsumerIdleThreshold},
+		{"IDLE_CHECK_INTERVAL", DefaultIdleCheckInterval, &cfg.IdleCheckInterval},
+		{"DEFAULT_ITEM_PREP_TIME", DefaultItemPrepTime, &defaultPrepTime},
+	}
+	for _, d := range durations {
+		v, err := envDuration(d.key, d.fallback)
+		if err != nil {
+			return Config{}, err
+		}
+		*d.dst = v
+	}
+
+	prepTimes, err := ParsePrepTimes(config.GetEnv("ITEM_PREP_TIMES", ""), defaultPrepTime)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid ITEM_PREP_TIMES: %w", err)
+	}
+	cfg.PrepTimes = prepTimes
+
+	stations, err := strconv.Atoi(config.GetEnv("KITCHEN_STATIONS", strconv.Itoa(DefaultKitchenStations)))
+	if err != nil || stations < 1 {
+		return Config{}, fmt.Errorf("KITCHEN_STATIONS must be a positive integer")
+	}
+	cfg.Stations = stations
+
+	// Ticker Intervalle <= 0 → time.NewTicker panict
+	if cfg.SLACheckInterval <= 0 || cfg.IdleCheckInterval <= 0 {
+		return Config{}, fmt.Errorf("SLA_CHECK_INTERVAL and IDLE_CHECK_INTERVAL must be positive")
+	}
+
+	return cfg, nil
+}
+
+// envDuration: "15m", "30s" aus der Umgebung, fallback wenn nicht gesetzt
+func envDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := config.GetEnv(key, "")
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Ohne ENV → lokale Defaults (docker-compose Setup)
+func TestLoadConfigDefaults(t *testing.T) {
+	for _, key := range []string{"SERVICE_NAME", "INSTANCE_ID", "HTTP_ADDR", "CONSUL_ADDR", "AMQP_USER", "AMQP_PASS", "AMQP_HOST", "AMQP_PORT", "PREP_SLA_THRESHOLD", "SLA_CHECK_INTERVAL", "KITCHEN_STATIONS"} {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.ServiceName != "kitchen" || cfg.HTTPAddr != "localhost:8083" || cfg.ConsulAddr != "localhost:8500" {
+		t.Errorf("addresses = %s %s %s; want kitchen localhost:8083 localhost:8500", cfg.ServiceName, cfg.HTTPAddr, cfg.ConsulAddr)
+	}
+	if cfg.AMQPHost != "localhost" || cfg.AMQPPort != "5672" || cfg.AMQPUser != "guest" {
+		t.Errorf("amqp = %s@%s:%s; want guest@localhost:5672", cfg.AMQPUser, cfg.AMQPHost, cfg.AMQPPort)
+	}
+	if !strings.HasPrefix(cfg.InstanceID, "kitchen") {
+		t.Errorf("InstanceID = %q; want a generated kitchen instance id", cfg.InstanceID)
+	}
+	if cfg.PrepSLAThreshold != DefaultPrepSLAThreshold || cfg.Stations != DefaultKitchenStations {
+		t.Errorf("sla/stations = %s/%d; want %s/%d", cfg.PrepSLAThreshold, cfg.Stations, DefaultPrepSLAThreshold, DefaultKitchenStations)
+	}
+}
+
+// ENV überschreibt jede Adresse → deploybar außerhalb von localhost
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	t.Setenv("SERVICE_NAME", "kitchen-eu")
+	t.Setenv("INSTANCE_ID", "kitchen-eu-1")
+	t.Setenv("HTTP_ADDR", "0.0.0.0:9000")
+	t.Setenv("CONSUL_ADDR", "consul:8500")
+	t.Setenv("AMQP_USER", "oms")
+	t.Setenv("AMQP_PASS", "secret")
+	t.Setenv("AMQP_HOST", "rabbit-1,rabbit-2")
+	t.Setenv("AMQP_PORT", "5673")
+	t.Setenv("PREP_SLA_THRESHOLD", "45m")
+	t.Setenv("SLA_CHECK_INTERVAL", "10s")
+	t.Setenv("KITCHEN_STATIONS", "3")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	tests := []struct{ name, got, want string }{
+		{"ServiceName", cfg.ServiceName, "kitchen-eu"},
+		{"InstanceID", cfg.InstanceID, "kitchen-eu-1"},
+		{"HTTPAddr", cfg.HTTPAddr, "0.0.0.0:9000"},
+		{"ConsulAddr", cfg.ConsulAddr, "consul:8500"},
+		{"AMQPUser", cfg.AMQPUser, "oms"},
+		{"AMQPPass", cfg.AMQPPass, "secret"},
+		{"AMQPHost", cfg.AMQPHost, "rabbit-1,rabbit-2"},
+		{"AMQPPort", cfg.AMQPPort, "5673"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q; want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if cfg.PrepSLAThreshold != 45*time.Minute || cfg.SLACheckInterval != 10*time.Second || cfg.Stations != 3 {
+		t.Errorf("sla/interval/stations = %s/%s/%d; want 45m/10s/3", cfg.PrepSLAThreshold, cfg.SLACheckInterval, cfg.Stations)
+	}
+}
+
+// Tippfehler in der ENV → Start bricht ab statt still den Default zu nehmen
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct{ key, value string }{
+		{"PREP_SLA_THRESHOLD", "20 minutes"},
+		{"SLA_CHECK_INTERVAL", "0s"},
+		{"KITCHEN_STATIONS", "0"},
+		{"ITEM_PREP_TIMES", "Burger"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			if _, err := LoadConfig(); err == nil {
+				t.Errorf("LoadConfig with %s=%q succeeded; want error", tt.key, tt.value)
+			}
+		})
+	}
+}
@@ -9,18 +9,32 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/timour/order-microservices/common/api"
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Consumer struct {
 	gateway Gateway
-	channel *amqp.Channel
+	channel broker.Channel // *amqp.Channel in Prod, brokertest.Broker in Tests
+	sla     *SLATracker
+	prep    PrepTimes                // Zubereitungszeit pro Item → Wartezeit Schätzung
+	idle    *broker.IdleMonitor      // nil = Idle Warnung deaktiviert
+	dedup   *broker.Deduplicator     // nil = Dedup deaktiviert
+	metrics *metrics.ConsumerMetrics // Processing Dauer + Outcome, nil = aus
 	logger  *slog.Logger
 }
 
-func NewConsumer(gateway Gateway, channel *amqp.Channel, logger *slog.Logger) *Consumer {
+func NewConsumer(gateway Gateway, channel broker.Channel, sla *SLATracker, prep PrepTimes, idle *broker.IdleMonitor, dedup *broker.Deduplicator, consumerMetrics *metrics.ConsumerMetrics, logger *slog.Logger) *Consumer {
 	return &Consumer{
 		gateway: gateway,
 		channel: channel,
+		sla:     sla,
+		prep:    prep,
+		idle:    idle,
+		dedup:   dedup,
+		metrics: consumerMetrics,
 		logger:  logger,
 	}
 }
@@ -122,109 +136,148 @@ func (c *Consumer) Listen() {
 	// → Consumer läuft DAUERHAFT! Wartet auf Messages
 	// → Blockiert bis Message ankommt
 	for d := range msgs {
-		c.logger.Info("received message",
+		c.idle.Touch()
+		broker.Process(c.metrics, c.channel, &d, broker.OrderPaidEvent, func() broker.Outcome {
+			return c.handle(d)
+		})
+	}
+
+	log.Println("Consumer stopped")
+}
+
+// handle: Verarbeitet EINE order.paid Delivery (Ack/Retry passiert hier drin)
+// Returns: Outcome für die Processing Metrik (broker.Process misst die Dauer)
+func (c *Consumer) handle(d amqp.Delivery) broker.Outcome {
+	c.logger.Info("received message",
+		slog.String("service", "kitchen"),
+		slog.String("event", broker.OrderPaidEvent),
+		slog.Int("body_size", len(d.Body)),
+	)
+
+	// Zu groß? → DLQ ohne Unmarshal
+	if broker.RejectOversized(context.Background(), c.channel, &d, broker.OrderPaidEvent) {
+		return broker.OutcomeDeadLetter
+	}
+
+	// Schon verarbeitet? → sonst zweites "preparing" Update + doppelte SLA Uhr
+	if c.dedup.Duplicate(&d) {
+		return broker.OutcomeDuplicate
+	}
+
+	// Warum Unmarshal?
+	// → Message Body ist JSON bytes
+	// → Konvertieren zu Go struct (*api.Order)
+	var order api.Order
+	if err := json.Unmarshal(d.Body, &order); err != nil {
+		c.logger.Error("failed to unmarshal order",
 			slog.String("service", "kitchen"),
-			slog.String("event", broker.OrderPaidEvent),
-			slog.Int("body_size", len(d.Body)),
+			slog.Any("error", err),
 		)
 
-		// Warum Unmarshal?
-		// → Message Body ist JSON bytes
-		// → Konvertieren zu Go struct (*api.Order)
-		var order api.Order
-		if err := json.Unmarshal(d.Body, &order); err != nil {
-			c.logger.Error("failed to unmarshal order",
+		// Warum Nack mit requeue=false?
+		// → Message ist kaputt (invalid JSON)
+		// → Retry macht keinen Sinn!
+		// → Send to DLQ
+		if err := broker.HandleRetry(c.channel, &d, broker.OrderPaidEvent, err); err != nil {
+			c.logger.Error("failed to handle retry",
 				slog.String("service", "kitchen"),
 				slog.Any("error", err),
 			)
-
-			// Warum Nack mit requeue=false?
-			// → Message ist kaputt (invalid JSON)
-			// → Retry macht keinen Sinn!
-			// → Send to DLQ
-			if err := broker.HandleRetry(c.channel, &d); err != nil {
-				c.logger.Error("failed to handle retry",
-					slog.String("service", "kitchen"),
-					slog.Any("error", err),
-				)
-			}
-			continue
 		}
+		return broker.OutcomeRetry
+	}
+
+	c.logger.Info("order unmarshalled",
+		slog.String("service", "kitchen"),
+		slog.String("order_id", order.Id),
+		slog.String("customer_id", order.CustomerId),
+		slog.String("status", order.Status),
+	)
 
-		c.logger.Info("order unmarshalled",
+	// ⭐ BUSINESS LOGIC: Update Order Status zu "preparing"
+	// Warum "preparing" und nicht "ready"?
+	// → order.paid → Kitchen empfängt Order → Automatisch "preparing"
+	// → Chef kocht das Essen 👨‍🍳
+	// → Chef bestätigt manuell via REST API → "ready"
+	if order.Status == orderstatus.StatusPaid {
+		c.logger.Info("updating order status to preparing",
 			slog.String("service", "kitchen"),
 			slog.String("order_id", order.Id),
-			slog.String("customer_id", order.CustomerId),
-			slog.String("status", order.Status),
 		)
 
-		// ⭐ BUSINESS LOGIC: Update Order Status zu "preparing"
-		// Warum "preparing" und nicht "ready"?
-		// → order.paid → Kitchen empfängt Order → Automatisch "preparing"
-		// → Chef kocht das Essen 👨‍🍳
-		// → Chef bestätigt manuell via REST API → "ready"
-		if order.Status == "paid" {
-			c.logger.Info("updating order status to preparing",
+		// Warum nur Status und ID senden?
+		// → UpdateOrder merged mit existierender Order
+		// → Wir wollen nur Status ändern, nichts anderes!
+		err := c.gateway.UpdateOrder(context.Background(), &api.Order{
+			Id:         order.Id,
+			CustomerId: order.CustomerId,
+			Status:     orderstatus.StatusPreparing, // ⭐ AUTOMATISCH
+		})
+		switch {
+		case alreadyPastPreparing(err):
+			// Redelivery nachdem die Order schon "preparing" (oder weiter) ist → nichts zu tun
+			// → Ack statt Retry/DLQ, SLA Uhr läuft bereits bzw. ist abgeschlossen
+			c.logger.Info("order already past preparing, acking without retry",
 				slog.String("service", "kitchen"),
 				slog.String("order_id", order.Id),
+				slog.Any("reason", err),
+			)
+		case err != nil:
+			c.logger.Error("failed to update order",
+				slog.String("service", "kitchen"),
+				slog.String("order_id", order.Id),
+				slog.Any("error", err),
 			)
 
-			// Warum nur Status und ID senden?
-			// → UpdateOrder merged mit existierender Order
-			// → Wir wollen nur Status ändern, nichts anderes!
-			err := c.gateway.UpdateOrder(context.Background(), &api.Order{
-				Id:         order.Id,
-				CustomerId: order.CustomerId,
-				Status:     "preparing", // ⭐ AUTOMATISCH
-			})
-			if err != nil {
-				c.logger.Error("failed to update order",
+			// Warum Retry?
+			// → UpdateOrder kann fehlschlagen (Orders Service down, Network issue)
+			// → Retry mit exponential backoff
+			// → Nach 3 Retries → DLQ
+			if err := broker.HandleRetry(c.channel, &d, broker.OrderPaidEvent, err); err != nil {
+				c.logger.Error("failed to handle retry",
 					slog.String("service", "kitchen"),
-					slog.String("order_id", order.Id),
 					slog.Any("error", err),
 				)
-
-				// Warum Retry?
-				// → UpdateOrder kann fehlschlagen (Orders Service down, Network issue)
-				// → Retry mit exponential backoff
-				// → Nach 3 Retries → DLQ
-				if err := broker.HandleRetry(c.channel, &d); err != nil {
-					c.logger.Error("failed to handle retry",
-						slog.String("service", "kitchen"),
-						slog.Any("error", err),
-					)
-				}
-				continue
 			}
-
+			return broker.OutcomeRetry
+		default:
 			c.logger.Info("order status updated to preparing",
 				slog.String("service", "kitchen"),
 				slog.String("order_id", order.Id),
 			)
-		} else {
-			c.logger.Warn("unexpected order status, skipping",
-				slog.String("service", "kitchen"),
-				slog.String("order_id", order.Id),
-				slog.String("status", order.Status),
-			)
-		}
 
-		// Warum Ack?
-		// → Bestätigt RabbitMQ: "Message erfolgreich verarbeitet!"
-		// → RabbitMQ löscht Message aus Queue
-		// → Ohne Ack: Message bleibt in Queue (wird nochmal delivered!)
-		if err := d.Ack(false); err != nil {
-			c.logger.Error("failed to ack message",
-				slog.String("service", "kitchen"),
-				slog.Any("error", err),
-			)
-		} else {
-			c.logger.Info("message acknowledged",
-				slog.String("service", "kitchen"),
-				slog.String("order_id", order.Id),
-			)
+			// ⭐ Ab jetzt läuft die Prep-Time SLA Uhr
+			c.sla.Start(order.Id, order.CustomerId, c.prep.OrderWork(order.Items))
 		}
+	} else {
+		c.logger.Warn("unexpected order status, skipping",
+			slog.String("service", "kitchen"),
+			slog.String("order_id", order.Id),
+			slog.String("status", order.Status),
+		)
 	}
 
-	log.Println("Consumer stopped")
+	// Warum Ack?
+	// → Bestätigt RabbitMQ: "Message erfolgreich verarbeitet!"
+	// → RabbitMQ löscht Message aus Queue
+	// → Ohne Ack: Message bleibt in Queue (wird nochmal delivered!)
+	if err := d.Ack(false); err != nil {
+		c.logger.Error("failed to ack message",
+			slog.String("service", "kitchen"),
+			slog.Any("error", err),
+		)
+	} else {
+		c.dedup.Done(&d)
+		c.logger.Info("message acknowledged",
+			slog.String("service", "kitchen"),
+			slog.String("order_id", order.Id),
+		)
+	}
+	return broker.OutcomeSuccess
+}
+
+// alreadyPastPreparing: Orders lehnt "preparing" ab weil die Order schon weiter ist (ready, completed, ...)
+// → FailedPrecondition = falscher Zustand, KEIN transienter Fehler → Retry würde nur in der DLQ enden
+func alreadyPastPreparing(err error) bool {
+	return err != nil && status.Code(err) == codes.FailedPrecondition
 }
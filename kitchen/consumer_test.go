@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// deliverPaid: Kitchen Consumer auf dem In-Memory Broker starten und EIN order.paid publizieren
+func deliverPaid(t *testing.T, gw Gateway) (*brokertest.Broker, *SLATracker, *fakeClock) {
+	t.Helper()
+
+	sla, clock, _, _ := newTestSLATracker(10 * time.Minute)
+	b := brokertest.New()
+	c := NewConsumer(gw, b, sla, PrepTimes{}, nil, nil, nil, discardLogger())
+	go c.Listen()
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForBinding(broker.OrderPaidEvent, broker.OrderPaidEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(&api.Order{Id: "o1", CustomerId: "c1", Status: orderstatus.StatusPaid})
+	if err := b.PublishWithContext(context.Background(), broker.OrderPaidEvent, "", false, false, amqp.Publishing{Body: body}); err != nil {
+		t.Fatal(err)
+	}
+	return b, sla, clock
+}
+
+// retryPublished: Gibt es (bis timeout) ein Publish in die erste Retry Queue von order.paid?
+func retryPublished(b *brokertest.Broker, timeout time.Duration) bool {
+	retryQueue := broker.RetryQueueName(broker.OrderPaidEvent, 1)
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, m := range b.Published() {
+			if m.RoutingKey == retryQueue {
+				return true
+			}
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Order ist schon weiter ("ready") → Orders antwortet FailedPrecondition → Ack, kein Retry
+func TestConsumerAcksOrderAlreadyPastPreparing(t *testing.T) {
+	gw := newFakeGateway(map[string]string{"o1": orderstatus.StatusReady})
+	b, sla, clock := deliverPaid(t, gw)
+
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(b.Nacked()); n != 0 {
+		t.Errorf("nacked = %d; want 0", n)
+	}
+	if retryPublished(b, 0) {
+		t.Errorf("published = %+v; want no retry", b.Published())
+	}
+	if n := len(gw.Updates()); n != 1 {
+		t.Errorf("UpdateOrder calls = %d; want 1", n)
+	}
+
+	// Order ist schon fertig → keine neue SLA Uhr
+	clock.Advance(time.Hour)
+	if breaches := sla.Check(context.Background()); len(breaches) != 0 {
+		t.Errorf("breaches = %+v; want none (SLA not started)", breaches)
+	}
+}
+
+// Redelivery: Order ist schon "preparing" → No-Op Update bei Orders → Ack, kein Retry
+func TestConsumerAcksRedeliveryForPreparingOrder(t *testing.T) {
+	gw := newFakeGateway(map[string]string{"o1": orderstatus.StatusPreparing})
+	b, _, _ := deliverPaid(t, gw)
+
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if retryPublished(b, 0) {
+		t.Errorf("published = %+v; want no retry", b.Published())
+	}
+}
+
+// Transienter Fehler (Orders down) → weiterhin Retry
+func TestConsumerRetriesUnavailableOrders(t *testing.T) {
+	gw := newFakeGateway(map[string]string{"o1": orderstatus.StatusPaid})
+	gw.err = status.Error(codes.Unavailable, "orders down")
+	b, _, _ := deliverPaid(t, gw)
+
+	if !retryPublished(b, time.Second) {
+		t.Fatalf("no retry published; published = %+v", b.Published())
+	}
+	// HandleRetry settled das Original selbst → genau ein Ack, kein Nack (sonst Kopie in der DLQ)
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(b.Nacked()); n != 0 {
+		t.Errorf("nacked = %d; want 0", n)
+	}
+}
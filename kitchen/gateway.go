@@ -6,6 +6,8 @@ import (
 
 	"github.com/timour/order-microservices/common/api"
 	"github.com/timour/order-microservices/common/discovery"
+	"github.com/timour/order-microservices/common/metrics"
+	"google.golang.org/grpc"
 )
 
 // Gateway - Interface zum Orders Service
@@ -16,12 +18,14 @@ type Gateway interface {
 type gateway struct {
 	registry discovery.Registry
 	logger   *slog.Logger
+	upstream *metrics.UpstreamMetrics // Latenz + Fehlerrate der Calls zum Orders Service
 }
 
-func NewGateway(registry discovery.Registry, logger *slog.Logger) Gateway {
+func NewGateway(registry discovery.Registry, logger *slog.Logger, upstream *metrics.UpstreamMetrics) Gateway {
 	return &gateway{
 		registry: registry,
 		logger:   logger,
+		upstream: upstream,
 	}
 }
 
@@ -35,7 +39,9 @@ func (g *gateway) UpdateOrder(ctx context.Context, order *api.Order) error {
 	// Warum Discovery?
 	// → Orders Service IP kann sich ändern (Kubernetes!)
 	// → Consul weiß immer die aktuelle Adresse
-	conn, err := discovery.ServiceConnection(ctx, "orders", g.registry)
+	conn, err := discovery.ServiceConnection(ctx, "orders", g.registry,
+		grpc.WithUnaryInterceptor(g.upstream.UnaryClientInterceptor("orders")),
+	)
 	if err != nil {
 		g.logger.Error("failed to connect to orders service", slog.Any("error", err))
 		return err
@@ -9,17 +9,24 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type HTTPHandler struct {
-	gateway Gateway
-	logger  *slog.Logger
+	gateway  Gateway
+	sla      *SLATracker
+	stations int // Parallel arbeitende Stationen (Wartezeit Schätzung)
+	logger   *slog.Logger
 }
 
-func NewHTTPHandler(gateway Gateway, logger *slog.Logger) *HTTPHandler {
+func NewHTTPHandler(gateway Gateway, sla *SLATracker, stations int, logger *slog.Logger) *HTTPHandler {
 	return &HTTPHandler{
-		gateway: gateway,
-		logger:  logger,
+		gateway:  gateway,
+		sla:      sla,
+		stations: stations,
+		logger:   logger,
 	}
 }
 
@@ -27,20 +34,37 @@ func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	// Prometheus metrics endpoint
 	mux.Handle("/metrics", promhttp.Handler())
 
-	// ⭐ REST API: Chef markiert Order als "ready"
+	// ⭐ REST API: Chef markiert Order als "ready" / bestätigt Abholung "completed"
 	// POST /api/orders/{orderID}/ready
+	// POST /api/orders/{orderID}/complete
 	// Example: POST http://localhost:8083/api/orders/42/ready
-	mux.HandleFunc("/api/orders/", h.handleOrderReady)
+	mux.HandleFunc("/api/orders/", h.handleOrderAction)
+
+	// Aktuelle Wartezeit für neue Orders (Restarbeit in "preparing" / Stationen)
+	mux.HandleFunc("GET /api/kitchen/wait-estimate", h.handleWaitEstimate)
+}
+
+// orderActions: Pfad-Aktion → Ziel-Status
+// Warum Map?
+// → Gleicher Handler für alle Chef-Aktionen, nur der Ziel-Status unterscheidet sich
+var orderActions = map[string]string{
+	"ready":    orderstatus.StatusReady,     // Chef: Essen ist fertig
+	"complete": orderstatus.StatusCompleted, // Kunde hat abgeholt → raus aus der aktiven Queue
 }
 
-// handleOrderReady - Chef bestätigt dass Order fertig ist
-// Flow:
+// handleOrderAction - Chef bestätigt "ready" oder Abholung ("complete")
+// Flow (ready):
 // 1. Chef klickt Button im Terminal UI
 // 2. Terminal sendet POST /api/orders/42/ready
 // 3. Kitchen Service ruft UpdateOrder auf → Status "ready"
 // 4. Orders Service publiziert order.ready Event
 // 5. Notification Service zeigt Customer: "Your order #42 is ready!" ✅
-func (h *HTTPHandler) handleOrderReady(w http.ResponseWriter, r *http.Request) {
+//
+// Flow (complete):
+// 1. Kunde holt Order ab → Terminal sendet POST /api/orders/42/complete
+// 2. Kitchen Service ruft UpdateOrder auf → Status "completed"
+// 3. Orders Service erlaubt das NUR wenn Order "ready" ist (sonst 409)
+func (h *HTTPHandler) handleOrderAction(w http.ResponseWriter, r *http.Request) {
 	// Warum Method Check?
 	// → Nur POST erlaubt! GET/PUT/DELETE nicht sinnvoll
 	if r.Method != http.MethodPost {
@@ -52,46 +76,64 @@ func (h *HTTPHandler) handleOrderReady(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract orderID from path: /api/orders/{orderID}/ready
-	// Example: /api/orders/42/ready → orderID = "42"
+	// Extract orderID + action from path: /api/orders/{orderID}/{action}
+	// Example: /api/orders/42/ready → orderID = "42", action = "ready"
 	path := strings.TrimPrefix(r.URL.Path, "/api/orders/")
 	parts := strings.Split(path, "/")
-	if len(parts) < 2 || parts[1] != "ready" {
+	var targetStatus string
+	if len(parts) >= 2 {
+		targetStatus = orderActions[parts[1]]
+	}
+	if targetStatus == "" || parts[0] == "" {
 		h.logger.Warn("invalid path format",
 			slog.String("path", r.URL.Path),
 		)
-		http.Error(w, "Invalid path format. Expected: /api/orders/{orderID}/ready", http.StatusBadRequest)
+		http.Error(w, "Invalid path format. Expected: /api/orders/{orderID}/ready or /api/orders/{orderID}/complete", http.StatusBadRequest)
 		return
 	}
 
 	orderID := parts[0]
 
-	h.logger.Info("chef marking order as ready",
+	h.logger.Info("chef updating order",
 		slog.String("service", "kitchen"),
 		slog.String("order_id", orderID),
+		slog.String("status", targetStatus),
 	)
 
 	// Warum nur orderID und Status senden?
 	// → UpdateOrder merged mit existierender Order
-	// → Wir wissen nur: Order ist fertig!
+	// → Wir wissen nur: Order ist fertig / abgeholt!
 	// → CustomerID, Items, etc. sind im Orders Service gespeichert
 	err := h.gateway.UpdateOrder(context.Background(), &api.Order{
 		Id:     orderID,
-		Status: "ready", // ⭐ MANUELL vom Chef bestätigt!
+		Status: targetStatus, // ⭐ MANUELL vom Chef bestätigt!
 	})
 	if err != nil {
-		h.logger.Error("failed to update order to ready",
+		h.logger.Error("failed to update order",
 			slog.String("service", "kitchen"),
 			slog.String("order_id", orderID),
+			slog.String("status", targetStatus),
 			slog.Any("error", err),
 		)
+
+		// Warum 409 bei FailedPrecondition?
+		// → z.B. "complete" für eine Order die noch nicht "ready" ist
+		// → Kein Server-Fehler, sondern falscher Order-Zustand
+		if status.Code(err) == codes.FailedPrecondition {
+			http.Error(w, status.Convert(err).Message(), http.StatusConflict)
+			return
+		}
 		http.Error(w, "Failed to update order", http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.Info("order marked as ready",
+	// Zubereitung beendet → kein SLA Alert mehr für diese Order
+	h.sla.Stop(orderID)
+
+	h.logger.Info("order status updated by chef",
 		slog.String("service", "kitchen"),
 		slog.String("order_id", orderID),
+		slog.String("status", targetStatus),
 	)
 
 	// Response: JSON mit Bestätigung
@@ -99,7 +141,7 @@ func (h *HTTPHandler) handleOrderReady(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"order_id": orderID,
-		"status":   "ready",
-		"message":  "Order successfully marked as ready",
+		"status":   targetStatus,
+		"message":  "Order successfully marked as " + targetStatus,
 	})
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeGateway: Orders Service im Speicher, prüft Übergänge wie UpdateOrder
+type fakeGateway struct {
+	mu       sync.Mutex
+	statuses map[string]string
+	updates  []*api.Order
+	err      error // != nil → jeder UpdateOrder Call schlägt so fehl
+}
+
+func newFakeGateway(statuses map[string]string) *fakeGateway {
+	return &fakeGateway{statuses: statuses}
+}
+
+func (g *fakeGateway) UpdateOrder(_ context.Context, o *api.Order) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.updates = append(g.updates, o)
+	if g.err != nil {
+		return g.err
+	}
+	if !orderstatus.CanTransition(g.statuses[o.Id], o.Status) {
+		return status.Errorf(codes.FailedPrecondition, "cannot transition order from %q to %q", g.statuses[o.Id], o.Status)
+	}
+	g.statuses[o.Id] = o.Status
+	return nil
+}
+
+func (g *fakeGateway) Updates() []*api.Order {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*api.Order(nil), g.updates...)
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newTestHTTPHandler(g Gateway) (*HTTPHandler, *SLATracker) {
+	sla := NewSLATracker(time.Minute, nil, nil, discardLogger())
+	return NewHTTPHandler(g, sla, 1, discardLogger()), sla
+}
+
+func postAction(h *HTTPHandler, path string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+	return w
+}
+
+func TestCompleteOrder(t *testing.T) {
+	g := newFakeGateway(map[string]string{"42": orderstatus.StatusReady})
+	h, _ := newTestHTTPHandler(g)
+
+	if w := postAction(h, "/api/orders/42/complete"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200 (%s)", w.Code, w.Body)
+	}
+	if g.statuses["42"] != orderstatus.StatusCompleted {
+		t.Fatalf("order status = %q; want completed", g.statuses["42"])
+	}
+}
+
+func TestCompleteOrderNotReady(t *testing.T) {
+	g := newFakeGateway(map[string]string{"42": orderstatus.StatusPreparing})
+	h, sla := newTestHTTPHandler(g)
+	sla.Start("42", "c1", 0)
+
+	if w := postAction(h, "/api/orders/42/complete"); w.Code != http.StatusConflict {
+		t.Fatalf("status = %d; want 409", w.Code)
+	}
+	// Abgelehnt → Order ist noch in Zubereitung und muss weiter getrackt werden
+	if n, _ := sla.RemainingWork(); n != 1 {
+		t.Fatalf("tracked orders = %d; want 1", n)
+	}
+}
+
+func TestOrderActionRejectsUnknownAction(t *testing.T) {
+	g := newFakeGateway(map[string]string{})
+	h, _ := newTestHTTPHandler(g)
+
+	if w := postAction(h, "/api/orders/42/pickup"); w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; want 400", w.Code)
+	}
+	if len(g.Updates()) != 0 {
+		t.Fatal("unknown action must not call UpdateOrder")
+	}
+}
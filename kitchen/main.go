@@ -10,21 +10,11 @@ import (
 	"syscall"
 	"time"
 
+	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/timour/order-microservices/common/broker"
-	"github.com/timour/order-microservices/common/discovery"
 	"github.com/timour/order-microservices/common/discovery/consul"
-)
-
-// Service Configuration
-var (
-	serviceName  = "kitchen"
-	httpAddr     = "localhost:8083"
-	consulAddr   = "localhost:8500"
-	amqpUser     = "guest"
-	amqpPass     = "guest"
-	amqpHost     = "localhost"
-	amqpPort     = "5672"
-	jaegerAddr   = "localhost:4317"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/startup"
 )
 
 func main() {
@@ -33,22 +23,35 @@ func main() {
 		AddSource: false,
 	}))
 
+	cfg, err := LoadConfig()
+	if err != nil {
+		logger.Error("invalid configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+	serviceName := cfg.ServiceName
+
 	logger.Info("starting service",
 		slog.String("service", serviceName),
-		slog.String("http_addr", httpAddr),
+		slog.String("http_addr", cfg.HTTPAddr),
 	)
 
-	// Initialize Consul registry
-	registry, err := consul.NewRegistry(consulAddr, serviceName)
-	if err != nil {
-		log.Fatalf("failed to initialize consul registry: %v", err)
-	}
+	// Startup Report: Consul + RabbitMQ → one summary at the end of init
+	report := startup.NewReport(serviceName)
 
+	// Initialize Consul registry
 	ctx := context.Background()
-	instanceID := discovery.GenerateInstanceID(serviceName)
+	instanceID := cfg.InstanceID
 
-	if err := registry.Register(ctx, instanceID, serviceName, httpAddr); err != nil {
-		log.Fatalf("failed to register service: %v", err)
+	var registry *consul.Registry
+	if err := report.Check("consul", true, func() (err error) {
+		registry, err = consul.NewRegistry(cfg.ConsulAddr, serviceName)
+		if err != nil {
+			return err
+		}
+		return registry.Register(ctx, instanceID, serviceName, cfg.HTTPAddr)
+	}); err != nil {
+		report.Log(logger)
+		os.Exit(1)
 	}
 	defer registry.Deregister(ctx, instanceID, serviceName)
 
@@ -57,43 +60,69 @@ func main() {
 	// Connect to RabbitMQ
 	logger.Info("connecting to rabbitmq",
 		slog.String("service", serviceName),
-		slog.String("host", amqpHost),
-		slog.String("port", amqpPort),
+		slog.String("host", cfg.AMQPHost),
+		slog.String("port", cfg.AMQPPort),
 	)
 
-	ch, close, err := broker.Connect(amqpUser, amqpPass, amqpHost, amqpPort)
-	if err != nil {
-		log.Fatalf("failed to connect to rabbitmq: %v", err)
+	var ch *amqp.Channel
+	var close func() error
+	if err := report.Check("rabbitmq", true, func() (err error) {
+		ch, close, err = broker.Connect(cfg.AMQPUser, cfg.AMQPPass, cfg.AMQPHost, cfg.AMQPPort)
+		return err
+	}); err != nil {
+		report.Log(logger)
+		os.Exit(1)
 	}
 	defer close()
 
 	logger.Info("rabbitmq connected successfully", slog.String("service", serviceName))
 
 	// Initialize Gateway (gRPC client to Orders Service)
-	gateway := NewGateway(registry, logger)
+	gateway := NewGateway(registry, logger, metrics.NewUpstreamMetrics(serviceName))
 	logger.Info("orders gateway initialized", slog.String("service", serviceName))
 
+	if err := report.Log(logger.With(slog.String("service", serviceName))); err != nil {
+		os.Exit(1)
+	}
+
+	// SLA Tracker: preparing → ready Zeit überwachen (PREP_SLA_THRESHOLD)
+	sla := NewSLATracker(cfg.PrepSLAThreshold, ch, metrics.NewSLAMetrics(), logger)
+
+	slaCtx, stopSLA := context.WithCancel(context.Background())
+	defer stopSLA()
+	go sla.Run(slaCtx, cfg.SLACheckInterval)
+
+	logger.Info("sla tracker started",
+		slog.String("service", serviceName),
+		slog.Duration("threshold", cfg.PrepSLAThreshold),
+	)
+
+	// Idle Monitor: Erkennt kaputte Bindings (Consumer bekommt nichts mehr, CONSUMER_IDLE_THRESHOLD)
+	consumerMetrics := metrics.NewConsumerMetrics(serviceName) // EINE Instanz: Idle Monitor + Processing Dauer
+	idle := broker.NewIdleMonitor(broker.OrderPaidEvent, cfg.ConsumerIdleThreshold, consumerMetrics)
+	go idle.Run(slaCtx, cfg.IdleCheckInterval)
+
 	// Start Consumer (listens to order.paid events)
-	consumer := NewConsumer(gateway, ch, logger)
+	consumer := NewConsumer(gateway, ch, sla, cfg.PrepTimes, idle, broker.NewDeduplicatorFromEnv(serviceName), consumerMetrics, logger)
 	go consumer.Listen()
 
 	logger.Info("consumer started, waiting for messages...", slog.String("service", serviceName))
 
 	// Setup HTTP Server (REST API for chef)
 	mux := http.NewServeMux()
-	handler := NewHTTPHandler(gateway, logger)
+	handler := NewHTTPHandler(gateway, sla, cfg.Stations, logger)
 	handler.RegisterRoutes(mux)
 
 	// Start HTTP Server
 	srv := &http.Server{
-		Addr:    httpAddr,
+		Addr:    cfg.HTTPAddr,
 		Handler: mux,
 	}
 
 	go func() {
 		logger.Info("starting http server",
 			slog.String("service", serviceName),
-			slog.String("addr", httpAddr),
+			slog.String("addr", cfg.HTTPAddr),
 		)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("failed to start http server: %v", err)
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// SLABreach: Payload des order.sla_breach Events
+type SLABreach struct {
+	OrderID        string    `json:"order_id"`
+	CustomerID     string    `json:"customer_id"`
+	PreparingSince time.Time `json:"preparing_since"`
+	Threshold      float64   `json:"threshold_seconds"`
+	Elapsed        float64   `json:"elapsed_seconds"`
+}
+
+type preparingOrder struct {
+	customerID string
+	since      time.Time
+	work       time.Duration // Geschätzte Zubereitungszeit (PrepTimes.OrderWork) → Wartezeit Schätzung
+	breached   bool          // Pro Order nur EIN Alert, nicht bei jedem Check
+}
+
+// SLATracker: Misst wie lange Orders in "preparing" hängen
+// Warum in Kitchen?
+// → Kitchen setzt "preparing" (order.paid Consumer) UND "ready" (Chef REST API)
+// → Start und Ende der Zubereitung laufen hier durch
+//
+// Einschränkung: In-Memory → nach Restart werden laufende Orders nicht mehr getrackt
+type SLATracker struct {
+	mu        sync.Mutex
+	orders    map[string]*preparingOrder
+	threshold time.Duration
+	channel   broker.Channel
+	metrics   *metrics.SLAMetrics
+	logger    *slog.Logger
+	now       func() time.Time // Injizierbar → Tests ohne Sleep
+}
+
+func NewSLATracker(threshold time.Duration, channel broker.Channel, m *metrics.SLAMetrics, logger *slog.Logger) *SLATracker {
+	return &SLATracker{
+		orders:    make(map[string]*preparingOrder),
+		threshold: threshold,
+		channel:   channel,
+		metrics:   m,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Start: Order ist jetzt "preparing" → Uhr läuft
+// work: Geschätzte Zubereitungszeit der Order (0 = unbekannt, zählt nicht zur Wartezeit)
+func (t *SLATracker) Start(orderID, customerID string, work time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Redelivery von order.paid → Startzeit NICHT zurücksetzen
+	if _, ok := t.orders[orderID]; ok {
+		return
+	}
+	t.orders[orderID] = &preparingOrder{customerID: customerID, since: t.now(), work: work}
+}
+
+// RemainingWork: Orders in "preparing" + ihre noch offene Zubereitungszeit
+// → Pro Order: work - schon vergangene Zeit (nie negativ, überfällige Orders zählen mit 0)
+func (t *SLATracker) RemainingWork() (int, time.Duration) {
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var remaining time.Duration
+	for _, o := range t.orders {
+		remaining += max(o.work-now.Sub(o.since), 0)
+	}
+	return len(t.orders), remaining
+}
+
+// Stop: Order ist "ready" (oder abgeholt) → nicht mehr tracken
+func (t *SLATracker) Stop(orderID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.orders, orderID)
+}
+
+// Check: Findet Orders über dem Threshold, zählt die Metric hoch und publiziert order.sla_breach
+func (t *SLATracker) Check(ctx context.Context) []SLABreach {
+	now := t.now()
+
+	t.mu.Lock()
+	var breaches []SLABreach
+	for orderID, o := range t.orders {
+		elapsed := now.Sub(o.since)
+		if o.breached || elapsed < t.threshold {
+			continue
+		}
+		o.breached = true
+		breaches = append(breaches, SLABreach{
+			OrderID:        orderID,
+			CustomerID:     o.customerID,
+			PreparingSince: o.since,
+			Threshold:      t.threshold.Seconds(),
+			Elapsed:        elapsed.Seconds(),
+		})
+	}
+	t.mu.Unlock()
+
+	// Publish außerhalb des Locks → langsames RabbitMQ blockiert nicht Start/Stop
+	for _, b := range breaches {
+		t.metrics.RecordSLABreach()
+
+		t.logger.Warn("order exceeded prep-time SLA",
+			slog.String("service", "kitchen"),
+			slog.String("order_id", b.OrderID),
+			slog.Float64("elapsed_seconds", b.Elapsed),
+			slog.Float64("threshold_seconds", b.Threshold),
+		)
+
+		if err := t.publish(ctx, b); err != nil {
+			t.logger.Error("failed to publish sla breach",
+				slog.String("service", "kitchen"),
+				slog.String("order_id", b.OrderID),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return breaches
+}
+
+// Run: Prüft alle interval auf SLA Verletzungen bis ctx beendet ist
+func (t *SLATracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Check(ctx)
+		}
+	}
+}
+
+func (t *SLATracker) publish(ctx context.Context, b SLABreach) error {
+	// Warum QueueDeclare vor jedem Publish?
+	// → Idempotent, und Alerts gehen nicht verloren wenn noch kein Consumer läuft
+	if _, err := t.channel.QueueDeclare(broker.OrderSLABreachEvent, true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	return t.channel.PublishWithContext(ctx,
+		"",                         // default exchange
+		broker.OrderSLABreachEvent, // routing key = queue name
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+			Headers:      broker.InjectTraceContext(ctx),
+			MessageId:    broker.MessageID(broker.OrderSLABreachEvent, b.OrderID),
+		},
+	)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// fakeClock: Steuerbare Uhr für SLATracker.now
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestSLATracker: Tracker mit fakeClock, In-Memory Broker und unregistriertem Counter
+// Warum nicht NewSLAMetrics?
+// → promauto registriert global → zweiter Aufruf im selben Test Binary paniced
+func newTestSLATracker(threshold time.Duration) (*SLATracker, *fakeClock, *brokertest.Broker, *metrics.SLAMetrics) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	b := brokertest.New()
+	m := &metrics.SLAMetrics{Breaches: prometheus.NewCounter(prometheus.CounterOpts{Name: "test_orders_sla_breach_total"})}
+
+	sla := NewSLATracker(threshold, b, m, discardLogger())
+	sla.now = clock.Now
+	return sla, clock, b, m
+}
+
+func TestSLABreachFiresAfterThreshold(t *testing.T) {
+	sla, clock, b, m := newTestSLATracker(10 * time.Minute)
+	ctx := context.Background()
+
+	sla.Start("42", "c1", 0)
+
+	clock.Advance(9 * time.Minute)
+	if breaches := sla.Check(ctx); len(breaches) != 0 {
+		t.Fatalf("breaches before threshold = %+v; want none", breaches)
+	}
+
+	clock.Advance(2 * time.Minute)
+	breaches := sla.Check(ctx)
+	if len(breaches) != 1 || breaches[0].OrderID != "42" {
+		t.Fatalf("breaches = %+v; want order 42", breaches)
+	}
+	if got := testutil.ToFloat64(m.Breaches); got != 1 {
+		t.Errorf("orders_sla_breach_total = %v; want 1", got)
+	}
+
+	published := b.Published()
+	if len(published) != 1 || published[0].RoutingKey != broker.OrderSLABreachEvent {
+		t.Fatalf("published = %+v; want one %s event", published, broker.OrderSLABreachEvent)
+	}
+	var event SLABreach
+	if err := json.Unmarshal(published[0].Publishing.Body, &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event.OrderID != "42" || event.CustomerID != "c1" || event.Elapsed != (11*time.Minute).Seconds() {
+		t.Errorf("event = %+v; want order 42, customer c1, 660s elapsed", event)
+	}
+
+	// Pro Order nur EIN Alert
+	clock.Advance(time.Minute)
+	if breaches := sla.Check(ctx); len(breaches) != 0 {
+		t.Errorf("second check breaches = %+v; want none", breaches)
+	}
+	if got := testutil.ToFloat64(m.Breaches); got != 1 {
+		t.Errorf("orders_sla_breach_total after second check = %v; want 1", got)
+	}
+}
+
+// Order wird rechtzeitig "ready" → kein Breach, auch wenn die Zeit später abläuft
+func TestSLANoBreachWhenReadyInTime(t *testing.T) {
+	sla, clock, b, m := newTestSLATracker(10 * time.Minute)
+
+	sla.Start("42", "c1", 0)
+	clock.Advance(5 * time.Minute)
+	sla.Stop("42")
+	clock.Advance(time.Hour)
+
+	if breaches := sla.Check(context.Background()); len(breaches) != 0 {
+		t.Fatalf("breaches = %+v; want none", breaches)
+	}
+	if got := testutil.ToFloat64(m.Breaches); got != 0 {
+		t.Errorf("orders_sla_breach_total = %v; want 0", got)
+	}
+	if n := len(b.Published()); n != 0 {
+		t.Errorf("published = %d; want 0", n)
+	}
+}
+
+// Redelivery von order.paid setzt die Startzeit nicht zurück
+func TestSLAStartKeepsOriginalStartTime(t *testing.T) {
+	sla, clock, _, _ := newTestSLATracker(10 * time.Minute)
+
+	sla.Start("42", "c1", 0)
+	clock.Advance(8 * time.Minute)
+	sla.Start("42", "c1", 0)
+	clock.Advance(3 * time.Minute)
+
+	if breaches := sla.Check(context.Background()); len(breaches) != 1 {
+		t.Fatalf("breaches = %+v; want 1", breaches)
+	}
+}
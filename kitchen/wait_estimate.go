@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+// Defaults für die Wartezeit Schätzung (ITEM_PREP_TIMES / DEFAULT_ITEM_PREP_TIME / KITCHEN_STATIONS)
+const (
+	DefaultItemPrepTime    = 5 * time.Minute
+	DefaultKitchenStations = 1
+)
+
+// PrepTimes: Zubereitungszeit pro Stück und Item ID
+// → Unbekannte Items bekommen fallback (neue Menüpunkte brauchen keinen Deploy)
+type PrepTimes struct {
+	perItem  map[string]time.Duration
+	fallback time.Duration
+}
+
+// ParsePrepTimes: "Burger=8m,Pommes=3m" → PrepTimes ("" = nur fallback)
+func ParsePrepTimes(s string, fallback time.Duration) (PrepTimes, error) {
+	p := PrepTimes{perItem: make(map[string]time.Duration), fallback: fallback}
+	if s == "" {
+		return p, nil
+	}
+
+	for _, entry := range strings.Split(s, ",") {
+		itemID, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || itemID == "" {
+			return PrepTimes{}, fmt.Errorf("invalid prep time entry %q (want itemID=duration)", entry)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return PrepTimes{}, fmt.Errorf("invalid prep time for %s: %q", itemID, value)
+		}
+		p.perItem[itemID] = d
+	}
+	return p, nil
+}
+
+// OrderWork: Gesamte Zubereitungszeit einer Order (Prep Time × Menge, über alle Items)
+func (p PrepTimes) OrderWork(items []*api.Item) time.Duration {
+	var work time.Duration
+	for _, item := range items {
+		d, ok := p.perItem[item.ID]
+		if !ok {
+			d = p.fallback
+		}
+		work += d * time.Duration(item.Quantity)
+	}
+	return work
+}
+
+// EstimateWait: Restarbeit aller Orders in "preparing" verteilt auf die Stationen
+// Warum geteilt durch Stationen?
+// → Zwei Stationen arbeiten parallel → doppelte Queue = gleiche Wartezeit wie halbe Queue bei einer Station
+func EstimateWait(remaining time.Duration, stations int) time.Duration {
+	if stations < 1 {
+		stations = 1
+	}
+	return remaining / time.Duration(stations)
+}
+
+// WaitEstimate: Response von GET /api/kitchen/wait-estimate
+type WaitEstimate struct {
+	PreparingOrders      int     `json:"preparing_orders"`
+	Stations             int     `json:"stations"`
+	RemainingWorkSeconds float64 `json:"remaining_work_seconds"`
+	WaitSeconds          float64 `json:"wait_seconds"`
+	WaitMinutes          int     `json:"wait_minutes"` // Aufgerundet → fürs Menü/Checkout ("ca. 12 min")
+}
+
+// handleWaitEstimate: GET /api/kitchen/wait-estimate → aktuelle Wartezeit für NEUE Orders
+// Einschränkung: Basiert auf dem SLATracker (In-Memory) → nach einem Restart zählen laufende Orders nicht mit
+func (h *HTTPHandler) handleWaitEstimate(w http.ResponseWriter, r *http.Request) {
+	orders, remaining := h.sla.RemainingWork()
+	wait := EstimateWait(remaining, h.stations)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WaitEstimate{
+		PreparingOrders:      orders,
+		Stations:             max(h.stations, 1),
+		RemainingWorkSeconds: remaining.Seconds(),
+		WaitSeconds:          wait.Seconds(),
+		WaitMinutes:          int(math.Ceil(wait.Minutes())),
+	})
+}
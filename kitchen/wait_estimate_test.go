@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+)
+
+func TestParsePrepTimes(t *testing.T) {
+	p, err := ParsePrepTimes("Burger=8m, Pommes=3m", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("ParsePrepTimes: %v", err)
+	}
+
+	// 2×8m + 1×3m + 1×5m (unbekannt → fallback)
+	work := p.OrderWork([]*api.Item{{ID: "Burger", Quantity: 2}, {ID: "Pommes", Quantity: 1}, {ID: "Cola", Quantity: 1}})
+	if work != 24*time.Minute {
+		t.Errorf("OrderWork = %s; want 24m", work)
+	}
+
+	for _, s := range []string{"Burger", "=8m", "Burger=soon", "Burger=-1m"} {
+		if _, err := ParsePrepTimes(s, time.Minute); err == nil {
+			t.Errorf("ParsePrepTimes(%q) succeeded; want error", s)
+		}
+	}
+}
+
+// Restarbeit: schon vergangene Zeit wird abgezogen, überfällige Orders zählen mit 0
+func TestRemainingWorkSubtractsElapsed(t *testing.T) {
+	sla, clock, _, _ := newTestSLATracker(time.Hour)
+
+	sla.Start("o1", "c1", 10*time.Minute)
+	clock.Advance(4 * time.Minute)
+	sla.Start("o2", "c2", 2*time.Minute)
+	clock.Advance(3 * time.Minute)
+
+	n, remaining := sla.RemainingWork()
+	if n != 2 || remaining != 3*time.Minute {
+		t.Fatalf("RemainingWork = %d, %s; want 2, 3m (o1: 10m-7m, o2 overdue)", n, remaining)
+	}
+}
+
+func getWaitEstimate(t *testing.T, h *HTTPHandler) WaitEstimate {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/kitchen/wait-estimate", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body)
+	}
+
+	var est WaitEstimate
+	if err := json.NewDecoder(w.Body).Decode(&est); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return est
+}
+
+// Wartezeit wächst mit der Queue und schrumpft mit der Zahl der Stationen
+func TestWaitEstimateScalesWithQueueAndStations(t *testing.T) {
+	tests := []struct {
+		orders      int
+		stations    int
+		wantSeconds float64
+		wantMinutes int
+	}{
+		{0, 1, 0, 0},
+		{1, 1, 600, 10},
+		{3, 1, 1800, 30},
+		{3, 2, 900, 15},
+		{3, 4, 450, 8}, // 7.5 min → aufgerundet
+		{3, 0, 1800, 30},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d orders/%d stations", tt.orders, tt.stations), func(t *testing.T) {
+			sla, _, _, _ := newTestSLATracker(time.Hour)
+			for i := 0; i < tt.orders; i++ {
+				sla.Start(fmt.Sprintf("o%d", i), "c1", 10*time.Minute)
+			}
+
+			est := getWaitEstimate(t, NewHTTPHandler(newFakeGateway(nil), sla, tt.stations, discardLogger()))
+			if est.PreparingOrders != tt.orders || est.WaitSeconds != tt.wantSeconds || est.WaitMinutes != tt.wantMinutes {
+				t.Errorf("estimate = %+v; want %d orders, %vs, %d min", est, tt.orders, tt.wantSeconds, tt.wantMinutes)
+			}
+			if est.Stations != max(tt.stations, 1) {
+				t.Errorf("stations = %d; want %d", est.Stations, max(tt.stations, 1))
+			}
+		})
+	}
+}
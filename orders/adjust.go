@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/timour/order-microservices/common/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// computeItemAdjustment: Berechnet neue Items + entfernte Mengen einer Order
+// Warum nur Reduktion?
+// → Order ist bereits bezahlt: Mehr Items = neuer Checkout, nicht Anpassung
+// → Entfernte Menge = Restock (Stock) + anteilige Rückerstattung (Payments)
+//
+// Returns:
+// → remaining: Items mit NEUEN Mengen (Items mit 0 fallen raus)
+// → removed: Items mit der ENTFERNTEN Menge (nur Items die sich geändert haben)
+func computeItemAdjustment(current []*api.Item, requested []*api.ItemsWithQuantity) (remaining, removed []*api.Item, err error) {
+	newQuantities := make(map[string]int32, len(requested))
+	for _, req := range requested {
+		if _, dup := newQuantities[req.ID]; dup {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "item %s listed more than once", req.ID)
+		}
+		if req.Quantity < 0 {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "negative quantity %d for item %s", req.Quantity, req.ID)
+		}
+		newQuantities[req.ID] = req.Quantity
+	}
+
+	known := make(map[string]bool, len(current))
+	for _, item := range current {
+		known[item.ID] = true
+
+		newQuantity, ok := newQuantities[item.ID]
+		if !ok {
+			// Nicht im Request → Menge bleibt unverändert
+			remaining = append(remaining, item)
+			continue
+		}
+
+		if newQuantity > item.Quantity {
+			return nil, nil, status.Errorf(codes.InvalidArgument,
+				"cannot increase quantity of item %s (%d → %d)", item.ID, item.Quantity, newQuantity)
+		}
+
+		if diff := item.Quantity - newQuantity; diff > 0 {
+			removed = append(removed, &api.Item{
+				ID:       item.ID,
+				Name:     item.Name,
+				Quantity: diff,
+				PriceID:  item.PriceID,
+			})
+		}
+
+		if newQuantity > 0 {
+			remaining = append(remaining, &api.Item{
+				ID:       item.ID,
+				Name:     item.Name,
+				Quantity: newQuantity,
+				PriceID:  item.PriceID,
+			})
+		}
+	}
+
+	for id := range newQuantities {
+		if !known[id] {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "item %s is not part of the order", id)
+		}
+	}
+
+	return remaining, removed, nil
+}
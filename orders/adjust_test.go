@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestComputeItemAdjustment(t *testing.T) {
+	current := []*api.Item{
+		{ID: "1", Name: "Burger", Quantity: 3, PriceID: "price_burger"},
+		{ID: "2", Name: "Pommes", Quantity: 2, PriceID: "price_pommes"},
+		{ID: "3", Name: "Cola", Quantity: 1, PriceID: "price_cola"},
+	}
+
+	remaining, removed, err := computeItemAdjustment(current, []*api.ItemsWithQuantity{
+		{ID: "1", Quantity: 1}, // 2 Burger weniger
+		{ID: "2", Quantity: 0}, // Pommes komplett raus
+	})
+	if err != nil {
+		t.Fatalf("computeItemAdjustment: %v", err)
+	}
+
+	// Restock Menge = entfernte Menge pro Item
+	wantRemoved := map[string]int32{"1": 2, "2": 2}
+	if len(removed) != len(wantRemoved) {
+		t.Fatalf("removed = %v; want %v", removed, wantRemoved)
+	}
+	for _, item := range removed {
+		if wantRemoved[item.ID] != item.Quantity || item.PriceID == "" {
+			t.Errorf("removed %s x%d (price %q); want x%d with price", item.ID, item.Quantity, item.PriceID, wantRemoved[item.ID])
+		}
+	}
+
+	wantRemaining := map[string]int32{"1": 1, "3": 1}
+	if len(remaining) != len(wantRemaining) {
+		t.Fatalf("remaining = %v; want %v", remaining, wantRemaining)
+	}
+	for _, item := range remaining {
+		if wantRemaining[item.ID] != item.Quantity {
+			t.Errorf("remaining %s x%d; want x%d", item.ID, item.Quantity, wantRemaining[item.ID])
+		}
+	}
+}
+
+func TestComputeItemAdjustmentRejectsInvalidRequests(t *testing.T) {
+	current := []*api.Item{{ID: "1", Quantity: 2, PriceID: "price_burger"}}
+
+	tests := map[string][]*api.ItemsWithQuantity{
+		"increase":      {{ID: "1", Quantity: 3}},
+		"negative":      {{ID: "1", Quantity: -1}},
+		"unknown item":  {{ID: "9", Quantity: 0}},
+		"duplicate row": {{ID: "1", Quantity: 1}, {ID: "1", Quantity: 0}},
+	}
+	for name, requested := range tests {
+		if _, _, err := computeItemAdjustment(current, requested); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: err = %v; want InvalidArgument", name, err)
+		}
+	}
+}
+
+// adjustStore: Eine Order im Speicher, AdjustItems/RevertAdjustment vergleichen wie der Mongo Filter
+// racedTo: Status den die Order "zwischen Lesen und Schreiben" annimmt
+type adjustStore struct {
+	OrdersStore
+
+	mu      sync.Mutex
+	order   *api.Order
+	pending []*api.OrderItemsAdjusted
+	racedTo string
+}
+
+func (s *adjustStore) Get(context.Context, string) (*api.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return proto.Clone(s.order).(*api.Order), nil
+}
+
+func (s *adjustStore) AdjustItems(_ context.Context, _ string, previous, remaining []*api.Item, adjustment *api.OrderItemsAdjusted) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.racedTo != "" {
+		s.order.Status = s.racedTo
+	}
+	if s.order.Status != orderstatus.StatusPaid || !sameItems(s.order.Items, previous) {
+		return false, nil
+	}
+	s.order.Items = remaining
+	s.pending = append(s.pending, adjustment)
+	return true, nil
+}
+
+func (s *adjustStore) RevertAdjustment(_ context.Context, _, adjustmentID string, previous, remaining []*api.Item) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !sameItems(s.order.Items, remaining) {
+		return false, nil
+	}
+	s.order.Items = previous
+	s.pending = slices.DeleteFunc(s.pending, func(a *api.OrderItemsAdjusted) bool { return a.AdjustmentId == adjustmentID })
+	return true, nil
+}
+
+func (s *adjustStore) PendingAdjustments(context.Context, string) ([]*api.OrderItemsAdjusted, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.pending), nil
+}
+
+func (s *adjustStore) CompleteAdjustment(_ context.Context, _, adjustmentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = slices.DeleteFunc(s.pending, func(a *api.OrderItemsAdjusted) bool { return a.AdjustmentId == adjustmentID })
+	return nil
+}
+
+// StaleAdjustments: Alter filtert der Mongo Store → hier zählt jeder Eintrag als alt
+func (s *adjustStore) StaleAdjustments(context.Context, time.Time, int64) ([]*api.OrderItemsAdjusted, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.pending), nil
+}
+
+func sameItems(a, b []*api.Item) bool {
+	return slices.EqualFunc(a, b, func(x, y *api.Item) bool { return proto.Equal(x, y) })
+}
+
+// restockStockServer: Zählt RestockItems Calls, err → jeder Restock schlägt fehl
+type restockStockServer struct {
+	releaseStockServer
+	err      error
+	restocks int
+}
+
+func (s *restockStockServer) RestockItems(context.Context, *api.RestockItemsRequest) (*api.RestockItemsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.restocks++
+	return &api.RestockItemsResponse{}, s.err
+}
+
+func (s *restockStockServer) Restocks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restocks
+}
+
+// flakyChannel: Publish schlägt fehl solange down=true (RabbitMQ kurz weg)
+type flakyChannel struct {
+	*brokertest.Broker
+	down bool
+}
+
+func (c *flakyChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if c.down {
+		return errors.New("channel closed")
+	}
+	return c.Broker.PublishWithContext(ctx, exchange, key, mandatory, immediate, msg)
+}
+
+func newPaidAdjustStore() *adjustStore {
+	return &adjustStore{order: &api.Order{
+		Id:         "o1",
+		CustomerId: "c1",
+		Status:     orderstatus.StatusPaid,
+		Items:      []*api.Item{{ID: "1", Name: "Burger", Quantity: 3, PriceID: "price_burger"}},
+	}}
+}
+
+func newAdjustTestHandler(t *testing.T, store OrdersStore, stock *restockStockServer) (*grpcHandler, *flakyChannel) {
+	t.Helper()
+
+	h, b := newStockTestHandler(t, store, stock)
+	ch := &flakyChannel{Broker: b}
+	h.channel = ch
+	return h, ch
+}
+
+func adjustedEvents(ch *flakyChannel) []string {
+	var ids []string
+	for _, m := range ch.Published() {
+		if m.RoutingKey == broker.OrderItemsAdjustedEvent {
+			ids = append(ids, m.Publishing.MessageId)
+		}
+	}
+	return ids
+}
+
+var burgerDownToOne = []*api.ItemsWithQuantity{{ID: "1", Quantity: 1}}
+
+// Publish fehlgeschlagen → Fehler statt Erfolg, Event bleibt in der Outbox; nächster Call publiziert es mit derselben adjustment_id
+func TestAdjustOrderItemsReportsAndRepublishesFailedEvent(t *testing.T) {
+	store := newPaidAdjustStore()
+	stock := &restockStockServer{}
+	h, ch := newAdjustTestHandler(t, store, stock)
+	ctx := context.Background()
+
+	ch.down = true
+	_, err := h.AdjustOrderItems(ctx, &api.AdjustOrderItemsRequest{OrderId: "o1", CustomerId: "c1", Items: burgerDownToOne})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("AdjustOrderItems error = %v; want Unavailable", err)
+	}
+	if len(store.pending) != 1 {
+		t.Fatalf("pending = %d; want the event kept in the outbox", len(store.pending))
+	}
+	adjustmentID := store.pending[0].AdjustmentId
+
+	// Retry desselben Requests: Items sind schon reduziert → nur die Outbox wird geleert
+	ch.down = false
+	if _, err := h.AdjustOrderItems(ctx, &api.AdjustOrderItemsRequest{OrderId: "o1", CustomerId: "c1", Items: burgerDownToOne}); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if got := adjustedEvents(ch); !slices.Equal(got, []string{adjustmentID}) {
+		t.Errorf("order.items_adjusted events = %v; want [%s]", got, adjustmentID)
+	}
+	if len(store.pending) != 0 {
+		t.Errorf("pending = %d; want outbox cleared", len(store.pending))
+	}
+	if n := stock.Restocks(); n != 1 {
+		t.Errorf("restocks = %d; want 1", n)
+	}
+}
+
+// Order zwischen Lesen und Schreiben weitergelaufen → Aborted, kein Restock, kein Refund Event
+func TestAdjustOrderItemsRejectsConcurrentChange(t *testing.T) {
+	store := newPaidAdjustStore()
+	store.racedTo = orderstatus.StatusPreparing
+	stock := &restockStockServer{}
+	h, ch := newAdjustTestHandler(t, store, stock)
+
+	_, err := h.AdjustOrderItems(context.Background(), &api.AdjustOrderItemsRequest{OrderId: "o1", CustomerId: "c1", Items: burgerDownToOne})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("AdjustOrderItems error = %v; want Aborted", err)
+	}
+	if n := stock.Restocks(); n != 0 {
+		t.Errorf("restocks = %d; want 0", n)
+	}
+	if got := adjustedEvents(ch); len(got) != 0 {
+		t.Errorf("order.items_adjusted events = %v; want none", got)
+	}
+}
+
+// Restock fehlgeschlagen → Items + Outbox zurückgerollt, kein Refund Event
+func TestAdjustOrderItemsRevertsWhenRestockFails(t *testing.T) {
+	store := newPaidAdjustStore()
+	stock := &restockStockServer{err: status.Error(codes.Unavailable, "stock down")}
+	h, ch := newAdjustTestHandler(t, store, stock)
+
+	if _, err := h.AdjustOrderItems(context.Background(), &api.AdjustOrderItemsRequest{OrderId: "o1", CustomerId: "c1", Items: burgerDownToOne}); err == nil {
+		t.Fatal("AdjustOrderItems succeeded; want restock error")
+	}
+	if got := store.order.Items[0].Quantity; got != 3 {
+		t.Errorf("burger quantity = %d; want 3 (reverted)", got)
+	}
+	if len(store.pending) != 0 {
+		t.Errorf("pending = %d; want 0", len(store.pending))
+	}
+	if got := adjustedEvents(ch); len(got) != 0 {
+		t.Errorf("order.items_adjusted events = %v; want none", got)
+	}
+}
+
+// Publish in AdjustOrderItems fehlgeschlagen, kein weiterer Call → der Relay holt den Refund nach
+// RabbitMQ noch weg → Fehler, Eintrag bleibt für den nächsten Tick
+func TestAdjustmentRelayPublishesStuckAdjustment(t *testing.T) {
+	store := newPaidAdjustStore()
+	h, ch := newAdjustTestHandler(t, store, &restockStockServer{})
+	ctx := context.Background()
+
+	ch.down = true
+	if _, err := h.AdjustOrderItems(ctx, &api.AdjustOrderItemsRequest{OrderId: "o1", CustomerId: "c1", Items: burgerDownToOne}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("AdjustOrderItems error = %v; want Unavailable", err)
+	}
+	adjustmentID := store.pending[0].AdjustmentId
+
+	relay := NewAdjustmentRelay(store, ch, h.logger)
+	if n, err := relay.relay(ctx); err == nil || n != 0 {
+		t.Fatalf("relay while down = %d, %v; want 0 and an error", n, err)
+	}
+	if len(store.pending) != 1 {
+		t.Fatalf("pending = %d; want the event kept in the outbox", len(store.pending))
+	}
+
+	ch.down = false
+	if n, err := relay.relay(ctx); err != nil || n != 1 {
+		t.Fatalf("relay = %d, %v; want 1", n, err)
+	}
+	if n, err := relay.relay(ctx); err != nil || n != 0 {
+		t.Fatalf("second relay = %d, %v; want 0 (outbox empty)", n, err)
+	}
+	if got := adjustedEvents(ch); !slices.Equal(got, []string{adjustmentID}) {
+		t.Errorf("order.items_adjusted events = %v; want [%s]", got, adjustmentID)
+	}
+}
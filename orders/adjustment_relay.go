@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+)
+
+// Defaults für das Outbox Relay (ADJUSTMENT_RELAY_INTERVAL)
+const (
+	DefaultAdjustmentRelayInterval = 30 * time.Second
+	adjustmentRelayMinAge          = 1 * time.Minute // Deutlich länger als ein AdjustOrderItems Call (Restock + Publish)
+	adjustmentRelayBatch           = 100             // Orders pro Durchlauf → Rest beim nächsten Tick
+)
+
+// adjustmentRelay: Background Job → publiziert order.items_adjusted Events die in der Outbox hängen geblieben sind
+// Warum?
+// → Publish in AdjustOrderItems fehlgeschlagen → Eintrag bleibt in der Outbox
+// → Vorher wurde er erst beim NÄCHSTEN AdjustOrderItems Call der Order nachgeholt → ohne den kein Refund
+// Gleiches Muster wie der orderExpirer
+type adjustmentRelay struct {
+	store   OrdersStore
+	channel broker.Channel
+	logger  *slog.Logger
+	minAge  time.Duration // Jüngere Einträge gehören noch zum laufenden Call
+}
+
+func NewAdjustmentRelay(store OrdersStore, channel broker.Channel, logger *slog.Logger) *adjustmentRelay {
+	return &adjustmentRelay{
+		store:   store,
+		channel: channel,
+		logger:  logger,
+		minAge:  adjustmentRelayMinAge,
+	}
+}
+
+// Run: Alle interval einen Durchlauf, bis ctx endet
+func (r *adjustmentRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			published, err := r.relay(ctx)
+			if err != nil {
+				r.logger.Error("failed to relay pending adjustments", slog.Any("error", err))
+			}
+			if published > 0 {
+				r.logger.Info("relayed pending adjustments", slog.Int("count", published))
+			}
+		}
+	}
+}
+
+// relay: Ein Durchlauf → Anzahl der publizierten Events
+// → Publish scheitert → abbrechen: RabbitMQ ist weg, der nächste Tick versucht den Rest
+func (r *adjustmentRelay) relay(ctx context.Context) (int, error) {
+	pending, err := r.store.StaleAdjustments(ctx, time.Now().Add(-r.minAge), adjustmentRelayBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, adjusted := range pending {
+		if err := publishAdjustment(ctx, r.channel, r.store, r.logger, adjusted); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// publishAdjustment: EIN Outbox Eintrag → order.items_adjusted Event, danach aus der Outbox entfernen
+// → Gleiche adjustment_id wie beim ersten Versuch → MessageId Dedup + Stripe Idempotency Key verhindern doppelte Refunds
+// → CompleteAdjustment fehlgeschlagen → nur loggen: Nächster Versuch publiziert nochmal, Payments dedupliziert
+func publishAdjustment(ctx context.Context, ch broker.Channel, store OrdersStore, logger *slog.Logger, adjusted *api.OrderItemsAdjusted) error {
+	if err := publishEvent(ctx, ch, broker.OrderItemsAdjustedEvent, adjusted.AdjustmentId, adjusted); err != nil {
+		logger.Error("failed to publish event",
+			slog.String("event", broker.OrderItemsAdjustedEvent),
+			slog.String("order_id", adjusted.OrderId),
+			slog.String("adjustment_id", adjusted.AdjustmentId),
+			slog.Any("error", err),
+		)
+		return err
+	}
+
+	logger.Info("event published",
+		slog.String("event", broker.OrderItemsAdjustedEvent),
+		slog.String("order_id", adjusted.OrderId),
+		slog.String("adjustment_id", adjusted.AdjustmentId),
+	)
+
+	if err := store.CompleteAdjustment(ctx, adjusted.OrderId, adjusted.AdjustmentId); err != nil {
+		logger.Warn("failed to clear published adjustment from outbox",
+			slog.String("order_id", adjusted.OrderId),
+			slog.String("adjustment_id", adjusted.AdjustmentId),
+			slog.Any("error", err),
+		)
+	}
+	return nil
+}
@@ -5,14 +5,19 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/config"
 	"github.com/timour/order-microservices/common/discovery"
 	"github.com/timour/order-microservices/common/discovery/consul"
 	"github.com/timour/order-microservices/common/logger"
 	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/startup"
+	"github.com/timour/order-microservices/common/telemetry"
+	"github.com/timour/order-microservices/common/validation"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
@@ -30,6 +35,7 @@ type App struct {
 	logger         *slog.Logger
 	grpcMetrics    *metrics.GRPCMetrics
 	businessMetrics *metrics.BusinessMetrics
+	consumerMetrics *metrics.ConsumerMetrics
 }
 
 type Config struct {
@@ -43,14 +49,26 @@ type Config struct {
 	AMQPHost    string
 	AMQPPort    string
 	MongoURI    string
+
+	// ReservationMode: on_create (Default) oder on_pay → muss zu Payments passen
+	ReservationMode config.ReservationMode
+
+	// OrderExpiryInterval: Wie oft der Expiry Job läuft (0 = aus)
+	OrderExpiryInterval time.Duration
+	// OrderExpiryAfter: Unbezahlte Orders älter als das → "expired" + order.expired
+	OrderExpiryAfter time.Duration
+
+	// AdjustmentRelayInterval: Wie oft hängen gebliebene order.items_adjusted Events nachpubliziert werden (0 = aus)
+	AdjustmentRelayInterval time.Duration
 }
 
-func NewApp(config Config, mongoClient *mongo.Client) (*App, error) {
+func NewApp(config Config, mongoClient *mongo.Client, report *startup.Report) (*App, error) {
 	log := logger.NewLogger(config.ServiceName)
 
 	// Warum createRegistry?
 	// → Verbindet mit Consul für Service Discovery
 	registry, err := createRegistry(config.ConsulAddr, log)
+	report.Record("consul", true, err)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +80,12 @@ func NewApp(config Config, mongoClient *mongo.Client) (*App, error) {
 		slog.String("host", config.AMQPHost),
 		slog.String("port", config.AMQPPort),
 	)
-	ch, close, err := broker.Connect(config.AMQPUser, config.AMQPPass, config.AMQPHost, config.AMQPPort)
+	var ch *amqp.Channel
+	var close func() error
+	err = report.Check("rabbitmq", true, func() (err error) {
+		ch, close, err = broker.Connect(config.AMQPUser, config.AMQPPass, config.AMQPHost, config.AMQPPort)
+		return err
+	})
 	if err != nil {
 		log.Error("failed to connect to rabbitmq", slog.Any("error", err))
 		return nil, err
@@ -84,7 +107,14 @@ func NewApp(config Config, mongoClient *mongo.Client) (*App, error) {
 	// → Trace Context wird von Client (Gateway/Payment) propagiert
 	return &App{
 		registry:        registry,
-		grpcServer:      grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler())),
+		grpcServer:      grpc.NewServer(
+			grpc.StatsHandler(otelgrpc.NewServerHandler()),
+			grpc.ChainUnaryInterceptor(
+				grpcMetrics.UnaryServerInterceptor(), // Latenz + Status pro Methode (auch abgelehnte Calls)
+				validation.UnaryServerInterceptor(),  // ⭐ InvalidArgument vor dem Handler
+				telemetry.UnaryServerInterceptor(),   // customer.id aus der Baggage → Server Span
+			),
+		),
 		channel:         ch,              // RabbitMQ Channel
 		closeRabbitMQ:   close,           // Cleanup Function
 		mongoClient:     mongoClient,     // MongoDB Client
@@ -92,6 +122,7 @@ func NewApp(config Config, mongoClient *mongo.Client) (*App, error) {
 		logger:          log,
 		grpcMetrics:     grpcMetrics,     // Prometheus gRPC Metrics
 		businessMetrics: businessMetrics, // Prometheus Business Metrics
+		consumerMetrics: metrics.NewConsumerMetrics(config.ServiceName), // order.paid + order.payment_link Processing Dauer + Outcome
 	}, nil
 }
 
@@ -111,8 +142,29 @@ func (a *App) Start(ctx context.Context) error {
 
 	// 2. Setup Business Logic with MongoDB
 	store := NewStore(a.mongoClient)
+	if err := store.EnsureIndexes(ctx); err != nil {
+		// Kein Abbruch: Orders funktionieren weiter, nur ohne Schutz vor Duplikaten
+		a.logger.Warn("failed to ensure order indexes", slog.Any("error", err))
+	}
+	// Einmaliger Backfill (idempotent): Legacy Orders ohne createdAt bekommen den ObjectID Timestamp
+	// → Im Hintergrund: Große Collections sollen den Start nicht blockieren
+	go func() {
+		backfilled, err := store.BackfillCreatedAt(ctx)
+		if err != nil {
+			a.logger.Warn("failed to backfill order createdAt", slog.Any("error", err))
+			return
+		}
+		if backfilled > 0 {
+			a.logger.Info("backfilled order createdAt", slog.Int64("orders", backfilled))
+		}
+	}()
+	idempotency := NewIdempotencyStore(a.mongoClient)
+	if err := idempotency.EnsureIndexes(ctx); err != nil {
+		// Ohne Unique/TTL Index kein Race-Schutz → Unique Index auf orders bleibt als Backstop
+		a.logger.Warn("failed to ensure idempotency key indexes", slog.Any("error", err))
+	}
 	svc := NewService(store)
-	NewGRPCHandler(a.grpcServer, svc, store, a.channel, a.logger, a.registry)
+	NewGRPCHandler(a.grpcServer, svc, store, a.channel, a.logger, a.registry, a.config.ReservationMode, idempotency, a.businessMetrics)
 
 	// 3. Start Prometheus Metrics HTTP Server
 	metricsMux := http.NewServeMux()
@@ -132,9 +184,32 @@ func (a *App) Start(ctx context.Context) error {
 	// → EVENT-DRIVEN ARCHITECTURE!
 	// → Payment Service publishes order.paid → Orders Consumer updates Order
 	// → In Goroutine: Listen() blockiert (Consumer läuft parallel zu gRPC!)
-	consumer := NewConsumer(store, a.logger)
+	consumer := NewConsumer(store, broker.NewDeduplicatorFromEnv(a.config.ServiceName), a.businessMetrics, a.consumerMetrics, a.logger)
 	go consumer.Listen(a.channel)
 
+	// Fallback von Payments: Payment Link kam als Event statt per gRPC (Orders war down)
+	paymentLinks := NewPaymentLinkConsumer(store, broker.NewDeduplicatorFromEnv(a.config.ServiceName), a.consumerMetrics, a.logger)
+	go paymentLinks.Listen(a.channel)
+
+	// Unbezahlte Orders ablaufen lassen → Stock gibt per order.expired die Reservation frei
+	if a.config.OrderExpiryInterval > 0 {
+		expirer := NewOrderExpirer(store, a.channel, a.logger, a.config.OrderExpiryAfter)
+		go expirer.Run(ctx, a.config.OrderExpiryInterval)
+		a.logger.Info("order expiry enabled",
+			slog.Duration("interval", a.config.OrderExpiryInterval),
+			slog.Duration("after", a.config.OrderExpiryAfter),
+		)
+	}
+
+	// Outbox Relay: order.items_adjusted Events deren Publish fehlgeschlagen ist → Refund kommt trotzdem
+	if a.config.AdjustmentRelayInterval > 0 {
+		relay := NewAdjustmentRelay(store, a.channel, a.logger)
+		go relay.Run(ctx, a.config.AdjustmentRelayInterval)
+		a.logger.Info("adjustment relay enabled",
+			slog.Duration("interval", a.config.AdjustmentRelayInterval),
+		)
+	}
+
 	// 5. Start gRPC Server
 	lis, err := net.Listen("tcp", a.config.GRPCAddr)
 	if err != nil {
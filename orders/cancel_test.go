@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"github.com/timour/order-microservices/common/discovery/inmem"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// releaseStockServer: Merkt sich welche Reservierungen freigegeben wurden
+type releaseStockServer struct {
+	pb.UnimplementedStockServiceServer
+
+	mu       sync.Mutex
+	released []string // "orderID/reservationID"
+}
+
+func (s *releaseStockServer) ReleaseReservation(_ context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, req.OrderID+"/"+req.ReservationID)
+	return &pb.ReleaseReservationResponse{}, nil
+}
+
+func (s *releaseStockServer) Released() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(slices.Values(s.released))
+}
+
+// customerStore: Orders im Speicher, GetByCustomerAndStatus filtert wie der Mongo Filter
+// racedTo: Status den die Order "zwischen Lesen und Schreiben" annimmt (Kitchen/Webhook war schneller)
+type customerStore struct {
+	OrdersStore
+	orders  map[string]*pb.Order
+	racedTo map[string]string
+}
+
+func (s *customerStore) Get(_ context.Context, id string) (*pb.Order, error) {
+	o, ok := s.orders[id]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	return proto.Clone(o).(*pb.Order), nil
+}
+
+func (s *customerStore) GetByCustomerAndStatus(_ context.Context, customerID string, statuses []string) ([]*pb.Order, error) {
+	var orders []*pb.Order
+	for _, o := range s.orders {
+		if o.CustomerId == customerID && slices.Contains(statuses, o.Status) {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (s *customerStore) UpdateStatusIf(_ context.Context, id string, from []string, to string) (bool, error) {
+	o := s.orders[id]
+	if raced, ok := s.racedTo[id]; ok {
+		o.Status = raced
+	}
+	if !slices.Contains(from, o.Status) {
+		return false, nil
+	}
+	o.Status = to
+	return true, nil
+}
+
+// newCancelTestHandler: Stock Fake hinter einer In-Memory Registry, Events in den In-Memory Broker
+func newCancelTestHandler(t *testing.T, store OrdersStore) (*grpcHandler, *releaseStockServer, *brokertest.Broker) {
+	t.Helper()
+
+	stock := &releaseStockServer{}
+	h, b := newStockTestHandler(t, store, stock)
+	return h, stock, b
+}
+
+// newStockTestHandler: grpcHandler gegen einen beliebigen Stock Fake
+func newStockTestHandler(t *testing.T, store OrdersStore, stock pb.StockServiceServer) (*grpcHandler, *brokertest.Broker) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	pb.RegisterStockServiceServer(srv, stock)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	registry := inmem.NewRegistry()
+	if err := registry.Register(context.Background(), "stock-1", "stock", lis.Addr().String()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	b := brokertest.New()
+	t.Cleanup(func() { b.Close() })
+	return &grpcHandler{
+		store:    store,
+		channel:  b,
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		registry: registry,
+	}, b
+}
+
+// Nur pending/waiting_payment des Kunden werden storniert + freigegeben; bezahlte und fremde Orders bleiben
+func TestCancelCustomerUnpaidOrders(t *testing.T) {
+	store := &customerStore{orders: map[string]*pb.Order{
+		"o1": {Id: "o1", CustomerId: "c1", Status: orderstatus.StatusPending, ReservationId: "res-1"},
+		"o2": {Id: "o2", CustomerId: "c1", Status: orderstatus.StatusWaitingPayment, ReservationId: "res-2"},
+		"o3": {Id: "o3", CustomerId: "c1", Status: orderstatus.StatusPaid, ReservationId: "res-3"},
+		"o4": {Id: "o4", CustomerId: "c1", Status: orderstatus.StatusCompleted},
+		"o5": {Id: "o5", CustomerId: "c2", Status: orderstatus.StatusPending, ReservationId: "res-5"},
+	}}
+	h, stock, b := newCancelTestHandler(t, store)
+
+	resp, err := h.CancelCustomerUnpaidOrders(context.Background(), &pb.CancelCustomerUnpaidOrdersRequest{CustomerId: "c1"})
+	if err != nil {
+		t.Fatalf("CancelCustomerUnpaidOrders: %v", err)
+	}
+	if resp.Cancelled != 2 || resp.Failed != 0 {
+		t.Fatalf("cancelled/failed = %d/%d; want 2/0", resp.Cancelled, resp.Failed)
+	}
+
+	want := map[string]string{
+		"o1": orderstatus.StatusCancelled,
+		"o2": orderstatus.StatusCancelled,
+		"o3": orderstatus.StatusPaid,
+		"o4": orderstatus.StatusCompleted,
+		"o5": orderstatus.StatusPending,
+	}
+	for id, status := range want {
+		if got := store.orders[id].Status; got != status {
+			t.Errorf("order %s status = %q; want %q", id, got, status)
+		}
+	}
+
+	if got := stock.Released(); !slices.Equal(got, []string{"o1/res-1", "o2/res-2"}) {
+		t.Errorf("released = %v; want o1/res-1 and o2/res-2", got)
+	}
+
+	var cancelled []string
+	for _, m := range b.Published() {
+		if m.Exchange == broker.OrderCancelledEvent {
+			cancelled = append(cancelled, m.Publishing.MessageId)
+		}
+	}
+	slices.Sort(cancelled)
+	wantEvents := []string{broker.MessageID(broker.OrderCancelledEvent, "o1"), broker.MessageID(broker.OrderCancelledEvent, "o2")}
+	if !slices.Equal(cancelled, wantEvents) {
+		t.Errorf("order.cancelled events = %v; want %v", cancelled, wantEvents)
+	}
+}
+
+// Kunde ohne offene Orders → 0, nichts wird angefasst
+func TestCancelCustomerUnpaidOrdersNoneOpen(t *testing.T) {
+	store := &customerStore{orders: map[string]*pb.Order{
+		"o1": {Id: "o1", CustomerId: "c1", Status: orderstatus.StatusPaid},
+	}}
+	h, stock, _ := newCancelTestHandler(t, store)
+
+	resp, err := h.CancelCustomerUnpaidOrders(context.Background(), &pb.CancelCustomerUnpaidOrdersRequest{CustomerId: "c1"})
+	if err != nil {
+		t.Fatalf("CancelCustomerUnpaidOrders: %v", err)
+	}
+	if resp.Cancelled != 0 {
+		t.Errorf("cancelled = %d; want 0", resp.Cancelled)
+	}
+	if got := stock.Released(); len(got) != 0 {
+		t.Errorf("released = %v; want none", got)
+	}
+}
+
+func cancelledEvents(b *brokertest.Broker) int {
+	n := 0
+	for _, m := range b.Published() {
+		if m.Exchange == broker.OrderCancelledEvent {
+			n++
+		}
+	}
+	return n
+}
+
+// Kitchen setzt "preparing" zwischen Lesen und Stornieren → kein Überschreiben, kein Release, kein Refund Event
+func TestCancelOrderDoesNotOverwriteConcurrentStatusChange(t *testing.T) {
+	store := &customerStore{
+		orders:  map[string]*pb.Order{"o1": {Id: "o1", CustomerId: "c1", Status: orderstatus.StatusPaid, ReservationId: "res-1"}},
+		racedTo: map[string]string{"o1": orderstatus.StatusPreparing},
+	}
+	h, stock, b := newCancelTestHandler(t, store)
+
+	_, err := h.CancelOrder(context.Background(), &pb.CancelOrderRequest{OrderId: "o1", CustomerId: "c1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("CancelOrder error = %v; want FailedPrecondition", err)
+	}
+	if got := store.orders["o1"].Status; got != orderstatus.StatusPreparing {
+		t.Errorf("status = %q; want %q", got, orderstatus.StatusPreparing)
+	}
+	if got := stock.Released(); len(got) != 0 {
+		t.Errorf("released = %v; want none", got)
+	}
+	if n := cancelledEvents(b); n != 0 {
+		t.Errorf("order.cancelled events = %d; want 0", n)
+	}
+}
+
+// Schon storniert (z.B. Release beim ersten Versuch fehlgeschlagen) → Release + Event laufen erneut
+func TestCancelOrderRerunsReleaseWhenAlreadyCancelled(t *testing.T) {
+	store := &customerStore{orders: map[string]*pb.Order{
+		"o1": {Id: "o1", CustomerId: "c1", Status: orderstatus.StatusCancelled, ReservationId: "res-1"},
+	}}
+	h, stock, b := newCancelTestHandler(t, store)
+
+	order, err := h.CancelOrder(context.Background(), &pb.CancelOrderRequest{OrderId: "o1", CustomerId: "c1"})
+	if err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if order.Status != orderstatus.StatusCancelled {
+		t.Errorf("status = %q; want cancelled", order.Status)
+	}
+	if got := stock.Released(); !slices.Equal(got, []string{"o1/res-1"}) {
+		t.Errorf("released = %v; want [o1/res-1]", got)
+	}
+	if n := cancelledEvents(b); n != 1 {
+		t.Errorf("order.cancelled events = %d; want 1", n)
+	}
+}
+
+// Bulk Cancel: Eine Order wird zwischendurch bezahlt → bleibt "paid", zählt weder als storniert noch als fehlgeschlagen
+func TestCancelCustomerUnpaidOrdersSkipsOrdersPaidMeanwhile(t *testing.T) {
+	store := &customerStore{
+		orders: map[string]*pb.Order{
+			"o1": {Id: "o1", CustomerId: "c1", Status: orderstatus.StatusPending, ReservationId: "res-1"},
+			"o2": {Id: "o2", CustomerId: "c1", Status: orderstatus.StatusWaitingPayment, ReservationId: "res-2"},
+		},
+		racedTo: map[string]string{"o2": orderstatus.StatusPaid},
+	}
+	h, stock, _ := newCancelTestHandler(t, store)
+
+	resp, err := h.CancelCustomerUnpaidOrders(context.Background(), &pb.CancelCustomerUnpaidOrdersRequest{CustomerId: "c1"})
+	if err != nil {
+		t.Fatalf("CancelCustomerUnpaidOrders: %v", err)
+	}
+	if resp.Cancelled != 1 || resp.Failed != 0 {
+		t.Fatalf("cancelled/failed = %d/%d; want 1/0", resp.Cancelled, resp.Failed)
+	}
+	if got := store.orders["o2"].Status; got != orderstatus.StatusPaid {
+		t.Errorf("o2 status = %q; want paid", got)
+	}
+	if got := stock.Released(); !slices.Equal(got, []string{"o1/res-1"}) {
+		t.Errorf("released = %v; want only o1/res-1", got)
+	}
+}
@@ -10,17 +10,26 @@ import (
 
 	pb "github.com/timour/order-microservices/common/api"
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/common/telemetry"
 )
 
 type consumer struct {
-	store  OrdersStore
-	logger *slog.Logger
+	store           OrdersStore
+	dedup           *broker.Deduplicator     // Redeliveries (gleiche MessageId) überspringen, nil = aus
+	metrics         *metrics.BusinessMetrics // nil = keine Business Metrics (nil-safe)
+	consumerMetrics *metrics.ConsumerMetrics // Processing Dauer + Outcome, nil = aus
+	logger          *slog.Logger
 }
 
-func NewConsumer(store OrdersStore, logger *slog.Logger) *consumer {
+func NewConsumer(store OrdersStore, dedup *broker.Deduplicator, businessMetrics *metrics.BusinessMetrics, consumerMetrics *metrics.ConsumerMetrics, logger *slog.Logger) *consumer {
 	return &consumer{
-		store:  store,
-		logger: logger,
+		store:           store,
+		dedup:           dedup,
+		metrics:         businessMetrics,
+		consumerMetrics: consumerMetrics,
+		logger:          logger,
 	}
 }
 
@@ -29,7 +38,7 @@ func NewConsumer(store OrdersStore, logger *slog.Logger) *consumer {
 // → Orders Service empfängt "order.paid" von Payment Service
 // → Updated Order mit payment_link + status "waiting_payment"
 // → Event-Driven Architecture statt gRPC!
-func (c *consumer) Listen(ch *amqp.Channel) {
+func (c *consumer) Listen(ch broker.Channel) {
 	// Warum QueueDeclare?
 	// → Erstellt Queue für order.paid events
 	// → Payment Service published hier rein!
@@ -109,73 +118,9 @@ func (c *consumer) Listen(ch *amqp.Channel) {
 		// → Wartet auf neue Messages von RabbitMQ
 		// → Blockiert bis Message kommt!
 		for d := range msgs {
-			// ⭐ OpenTelemetry: Extract trace context from AMQP headers FIRST
-			// → Must be done before any processing to continue distributed trace
-			ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
-
-			// ⭐ OpenTelemetry: Start span for message processing
-			// → This span represents the consumer processing the message
-			// → Will be visible in Jaeger as "AMQP - consume - order.paid"
-			tracer := otel.Tracer("orders")
-			ctx, span := tracer.Start(ctx, "AMQP - consume - order.paid")
-
-			c.logger.Info("received message",
-				slog.String("body", string(d.Body)),
-			)
-
-			// Warum json.Unmarshal?
-			// → d.Body ist []byte (JSON)
-			// → Konvertiert zurück zu *pb.Order struct
-			// → GLEICHE Order die Payment Service published hat!
-			o := &pb.Order{}
-			if err := json.Unmarshal(d.Body, o); err != nil {
-				c.logger.Error("failed to unmarshal order", slog.Any("error", err))
-				// Warum HandleRetry?
-				// → Smart retry: Will retry up to 3 times
-				// → After 3 retries → sends to DLQ
-				if err := broker.HandleRetry(ch, &d); err != nil {
-					c.logger.Error("error handling retry", slog.Any("error", err))
-				}
-				d.Nack(false, false)
-				span.End() // ⭐ End span before continue!
-				continue
-			}
-
-			// Warum store.Update?
-			// → Business Logic: Updated Order mit payment_link + status
-			// → Store wird updated (in-memory)
-			err = c.store.Update(ctx, o.Id, o)
-			if err != nil {
-				c.logger.Error("failed to update order", slog.Any("error", err))
-				// Warum HandleRetry bei Update Failure?
-				// → Order not found? → Will fail 3 times → DLQ for investigation
-				// → Store error? → Retry with backoff
-				if err := broker.HandleRetry(ch, &d); err != nil {
-					c.logger.Error("error handling retry", slog.Any("error", err))
-				}
-				d.Nack(false, false)
-				span.End() // ⭐ End span before continue!
-				continue
-			}
-
-			// ✅ SUCCESS: Order updated!
-			// Warum d.Ack?
-			// → Bestätigt RabbitMQ: "Message erfolgreich verarbeitet"
-			// → Message wird aus Queue GELÖSCHT
-			d.Ack(false)
-
-			c.logger.Info("updating order",
-				slog.String("order_id", o.Id),
-				slog.String("status", o.Status),
-				slog.String("payment_link", o.PaymentLink),
-			)
-			c.logger.Info("order updated successfully",
-				slog.String("order_id", o.Id),
-				slog.String("status", o.Status),
-			)
-
-			// ⭐ End span after successful processing
-			span.End()
+			broker.Process(c.consumerMetrics, ch, &d, broker.OrderPaidEvent, func() broker.Outcome {
+				return c.handle(ch, d)
+			})
 		}
 	}()
 
@@ -188,4 +133,92 @@ func (c *consumer) Listen(ch *amqp.Channel) {
 	// → Listen() returnt NIE (Consumer läuft bis Process killed wird)
 	<-forever
 }
+
+// handle: Verarbeitet EINE order.paid Delivery (Ack/Nack passiert hier drin)
+// Returns: Outcome für die Processing Metrik (broker.Process misst die Dauer)
+func (c *consumer) handle(ch broker.Channel, d amqp.Delivery) broker.Outcome {
+	// ⭐ OpenTelemetry: Extract trace context from AMQP headers FIRST
+	// → Must be done before any processing to continue distributed trace
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+
+	// ⭐ OpenTelemetry: Start span for message processing
+	// → This span represents the consumer processing the message
+	// → Will be visible in Jaeger as "AMQP - consume - order.paid"
+	tracer := otel.Tracer("orders")
+	ctx, span := tracer.Start(ctx, "AMQP - consume - order.paid")
+	telemetry.TagSpan(ctx) // customer.id aus der Baggage
+
+	// Zu groß? → DLQ ohne Unmarshal (Body wird NICHT geloggt)
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderPaidEvent) {
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	// Schon verarbeitet (Redelivery / Webhook doppelt)? → Ack ohne erneutes Update
+	if c.dedup.Duplicate(&d) {
+		span.End()
+		return broker.OutcomeDuplicate
+	}
+
+	c.logger.Info("received message",
+		slog.String("body", string(d.Body)),
+	)
+
+	// Warum json.Unmarshal?
+	// → d.Body ist []byte (JSON)
+	// → Konvertiert zurück zu *pb.Order struct
+	// → GLEICHE Order die Payment Service published hat!
+	o := &pb.Order{}
+	if err := json.Unmarshal(d.Body, o); err != nil {
+		c.logger.Error("failed to unmarshal order", slog.Any("error", err))
+		// Warum HandleRetry?
+		// → Smart retry: Will retry up to 3 times
+		// → After 3 retries → sends to DLQ
+		if err := broker.HandleRetry(ch, &d, broker.OrderPaidEvent, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End() // ⭐ End span before return!
+		return broker.OutcomeRetry
+	}
+
+	// Warum store.Update?
+	// → Business Logic: Updated Order mit payment_link + status
+	// → Store wird updated (in-memory)
+	err := c.store.Update(ctx, o.Id, o)
+	if err != nil {
+		c.logger.Error("failed to update order", slog.Any("error", err))
+		// Warum HandleRetry bei Update Failure?
+		// → Order not found? → Will fail 3 times → DLQ for investigation
+		// → Store error? → Retry with backoff
+		if err := broker.HandleRetry(ch, &d, broker.OrderPaidEvent, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End() // ⭐ End span before return!
+		return broker.OutcomeRetry
+	}
+
+	// ✅ SUCCESS: Order updated!
+	// Warum d.Ack?
+	// → Bestätigt RabbitMQ: "Message erfolgreich verarbeitet"
+	// → Message wird aus Queue GELÖSCHT
+	d.Ack(false)
+	c.dedup.Done(&d)
+	if o.Status == orderstatus.StatusPaid {
+		c.metrics.RecordOrderPaid()
+	}
+
+	c.logger.Info("updating order",
+		slog.String("order_id", o.Id),
+		slog.String("status", o.Status),
+		slog.String("payment_link", o.PaymentLink),
+	)
+	c.logger.Info("order updated successfully",
+		slog.String("order_id", o.Id),
+		slog.String("status", o.Status),
+	)
+
+	// ⭐ End span after successful processing
+	span.End()
+	return broker.OutcomeSuccess
+}
 // rebuild trigger
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// Nur ein erfolgreiches Update auf "paid" zählt als bezahlte Order
+func TestConsumerRecordsOrderPaid(t *testing.T) {
+	store := &fakeOrdersStore{orders: map[string]*pb.Order{
+		"o1": {Id: "o1", Status: orderstatus.StatusWaitingPayment},
+		"o2": {Id: "o2", Status: orderstatus.StatusPending},
+	}}
+	m := metrics.NewBusinessMetrics("orders_paid_consumer_test")
+	b := brokertest.New()
+	go NewConsumer(store, nil, m, nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Listen(b)
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForBinding(broker.OrderPaidEvent, broker.OrderPaidEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	publish := func(o *pb.Order) {
+		t.Helper()
+		body, _ := json.Marshal(o)
+		err := b.PublishWithContext(context.Background(), broker.OrderPaidEvent, "", false, false, amqp.Publishing{Body: body})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publish(&pb.Order{Id: "o1", Status: orderstatus.StatusPaid})
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(m.OrdersPaid); got != 1 {
+		t.Fatalf("orders paid = %v; want 1", got)
+	}
+
+	// Anderer Status über dieselbe Queue → kein "paid"
+	publish(&pb.Order{Id: "o2", Status: orderstatus.StatusWaitingPayment})
+	if err := b.WaitForAcks(2, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(m.OrdersPaid); got != 1 {
+		t.Errorf("orders paid = %v after a non-paid update; want 1", got)
+	}
+	if got := store.order("o1").Status; got != orderstatus.StatusPaid {
+		t.Errorf("o1 status = %q; want paid", got)
+	}
+}
+
+// Handler panict (fakeOrdersStore.Update auf eine unbekannte Order → nil Pointer)
+// → Message landet in der DLQ, die Consumer Goroutine lebt weiter und verarbeitet die nächste Order
+func TestConsumerSurvivesHandlerPanic(t *testing.T) {
+	store := &fakeOrdersStore{orders: map[string]*pb.Order{
+		"o1": {Id: "o1", Status: orderstatus.StatusWaitingPayment},
+	}}
+	b := brokertest.New()
+	go NewConsumer(store, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Listen(b)
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForBinding(broker.OrderPaidEvent, broker.OrderPaidEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"ghost", "o1"} {
+		body, _ := json.Marshal(&pb.Order{Id: id, Status: orderstatus.StatusPaid})
+		if err := b.PublishWithContext(context.Background(), broker.OrderPaidEvent, "", false, false, amqp.Publishing{Body: body}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.WaitForAcks(2, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.order("o1").Status; got != orderstatus.StatusPaid {
+		t.Errorf("o1 status = %q; want paid (consumer kept processing after the panic)", got)
+	}
+	var deadLettered int
+	for _, m := range b.Published() {
+		if m.Exchange == broker.DLX && m.RoutingKey == broker.OrderPaidEvent {
+			deadLettered++
+			var o pb.Order
+			if err := json.Unmarshal(m.Publishing.Body, &o); err != nil || o.Id != "ghost" {
+				t.Errorf("dead-lettered order = %q (%v); want ghost", o.Id, err)
+			}
+		}
+	}
+	if deadLettered != 1 {
+		t.Errorf("dead-lettered = %d; want 1", deadLettered)
+	}
+}
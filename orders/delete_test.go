@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// softDeleteStore: OrdersStore im Speicher, nur Get + SoftDelete + Restore
+// → Zählt die Store Calls → idempotente Aufrufe dürfen nichts schreiben
+type softDeleteStore struct {
+	OrdersStore
+
+	mu     sync.Mutex
+	orders map[string]*pb.Order
+	writes int
+}
+
+func (s *softDeleteStore) Get(_ context.Context, id string) (*pb.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[id]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	return &pb.Order{Id: o.Id, Status: o.Status, DeletedAt: o.DeletedAt}, nil
+}
+
+func (s *softDeleteStore) SoftDelete(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	s.orders[id].DeletedAt = "2026-01-01T12:00:00Z"
+	return true, nil
+}
+
+func (s *softDeleteStore) Restore(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	s.orders[id].DeletedAt = ""
+	return true, nil
+}
+
+func newDeleteTestHandler(orders ...*pb.Order) (*grpcHandler, *softDeleteStore) {
+	store := &softDeleteStore{orders: make(map[string]*pb.Order)}
+	for _, o := range orders {
+		store.orders[o.Id] = o
+	}
+	return &grpcHandler{store: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, store
+}
+
+// Delete → DeletedAt gesetzt, zweiter Delete idempotent, Restore → wieder sichtbar
+func TestDeleteAndRestoreOrder(t *testing.T) {
+	h, store := newDeleteTestHandler(&pb.Order{Id: "o1", Status: orderstatus.StatusCompleted})
+	ctx := context.Background()
+
+	o, err := h.DeleteOrder(ctx, &pb.DeleteOrderRequest{OrderId: "o1"})
+	if err != nil || o.DeletedAt == "" {
+		t.Fatalf("DeleteOrder = %+v, %v; want DeletedAt set", o, err)
+	}
+	if _, err := h.DeleteOrder(ctx, &pb.DeleteOrderRequest{OrderId: "o1"}); err != nil {
+		t.Fatalf("second DeleteOrder: %v", err)
+	}
+
+	o, err = h.RestoreOrder(ctx, &pb.RestoreOrderRequest{OrderId: "o1"})
+	if err != nil || o.DeletedAt != "" {
+		t.Fatalf("RestoreOrder = %+v, %v; want DeletedAt cleared", o, err)
+	}
+	if _, err := h.RestoreOrder(ctx, &pb.RestoreOrderRequest{OrderId: "o1"}); err != nil {
+		t.Fatalf("second RestoreOrder: %v", err)
+	}
+
+	if store.writes != 2 {
+		t.Errorf("store writes = %d; want 2 (repeated delete/restore are no-ops)", store.writes)
+	}
+}
+
+// Aktive Orders bleiben sichtbar (Kitchen Display), unbekannte Orders → NotFound
+func TestDeleteOrderRejected(t *testing.T) {
+	h, store := newDeleteTestHandler(&pb.Order{Id: "o1", Status: orderstatus.StatusPreparing})
+	ctx := context.Background()
+
+	if _, err := h.DeleteOrder(ctx, &pb.DeleteOrderRequest{OrderId: "o1"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("DeleteOrder(preparing) = %v; want FailedPrecondition", err)
+	}
+	if _, err := h.DeleteOrder(ctx, &pb.DeleteOrderRequest{OrderId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteOrder(missing) = %v; want NotFound", err)
+	}
+	if _, err := h.RestoreOrder(ctx, &pb.RestoreOrderRequest{OrderId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("RestoreOrder(missing) = %v; want NotFound", err)
+	}
+	if store.writes != 0 {
+		t.Errorf("store writes = %d; want 0", store.writes)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/timour/order-microservices/common/api"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// orderLookupError: Store Fehler beim Laden einer Order → gRPC Code
+// Warum?
+// → fmt.Errorf kommt beim Client als codes.Unknown an → Gateway kann nur 500 antworten
+// → Ungültige Hex ID = es gibt keine Order mit dieser ID → NotFound wie ein fehlendes Dokument
+// → Alles andere (MongoDB down, Timeout) bleibt ein Serverfehler
+func orderLookupError(orderID string, err error) error {
+	if errors.Is(err, ErrOrderNotFound) || errors.Is(err, primitive.ErrInvalidHex) {
+		return status.Errorf(codes.NotFound, "order %s not found", orderID)
+	}
+	return status.Errorf(codes.Internal, "failed to load order %s: %v", orderID, err)
+}
+
+// outOfStockError: FailedPrecondition + WELCHE Items fehlen als Status Details
+// Warum Details statt Items im Message Text?
+// → Message bleibt lesbar, Gateway liest die Items typisiert (status.Details) statt Strings zu parsen
+// → Details lassen sich nicht anhängen → trotzdem FailedPrecondition, nur ohne Liste
+func outOfStockError(items []*api.OutOfStockItem) error {
+	st := status.New(codes.FailedPrecondition, "one or more items are not in stock")
+	if len(items) == 0 {
+		return st.Err()
+	}
+
+	details := make([]protoadapt.MessageV1, 0, len(items))
+	for _, item := range items {
+		details = append(details, item)
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// stockChangedError: Reservation scheitert NACH bestandenem Stock Check (CreateOrder) → Aborted (Gateway: 409)
+// Warum nicht einfach FailedPrecondition/Internal durchreichen?
+// → Der Check kann aus dem Stock Cache kommen oder ein anderer Kunde war zwischen Check und Reserve schneller
+// → Kein Serverfehler: Derselbe Request kann gleich klappen (oder bekommt dann den echten Out-of-Stock 409)
+// → Stock down, unbekanntes Item usw. bleiben downstreamError
+func stockChangedError(err error) error {
+	switch status.Code(err) {
+	case codes.FailedPrecondition, codes.Aborted:
+		return status.Error(codes.Aborted, "stock changed since availability check, please retry")
+	default:
+		return downstreamError("stock", err)
+	}
+}
+
+// downstreamError: Fehler eines Calls zu einem anderen Service (z.B. Stock) → gRPC Code für UNSEREN Client
+// Warum nicht einfach durchreichen?
+// → Fachliche Codes (zu wenig Bestand, unbekanntes Item) gelten auch für den Aufrufer → bleiben erhalten
+// → Netzwerk/Timeout/kein gRPC Status → Unavailable: Retry später kann klappen (HTTP 503)
+// → Sonstiges (Internal, Unknown) → Internal: Fehler liegt nicht beim Client
+func downstreamError(service string, err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return status.Errorf(codes.Unavailable, "%s service unavailable: %v", service, err)
+	}
+
+	switch s.Code() {
+	case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition, codes.Aborted:
+		return status.Error(s.Code(), s.Message())
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return status.Errorf(codes.Unavailable, "%s service unavailable: %s", service, s.Message())
+	default:
+		return status.Errorf(codes.Internal, "%s service failed: %s", service, s.Message())
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// Defaults für den Expiry Job (ORDER_EXPIRY_INTERVAL / ORDER_EXPIRY_AFTER)
+const (
+	DefaultOrderExpiryInterval = 1 * time.Minute
+	DefaultOrderExpiryAfter    = defaultStuckOrderAge // = Reservation TTL im Stock Service
+)
+
+// orderExpirer: Background Job → unbezahlte Orders (pending/waiting_payment) nach einer Frist auf "expired"
+// Warum?
+// → Die Reservation läuft im Stock Service still ab, die Order blieb für immer "waiting_payment"
+// → Kunde/Gateway sehen jetzt einen Endzustand, Stock gibt per order.expired sofort frei
+// Gleiches Muster wie der Reservation Cleanup Ticker im Stock Service
+type orderExpirer struct {
+	store   OrdersStore
+	channel broker.Channel
+	logger  *slog.Logger
+	after   time.Duration // Orders älter als after (seit Erstellung) laufen ab
+}
+
+func NewOrderExpirer(store OrdersStore, channel broker.Channel, logger *slog.Logger, after time.Duration) *orderExpirer {
+	return &orderExpirer{
+		store:   store,
+		channel: channel,
+		logger:  logger,
+		after:   after,
+	}
+}
+
+// Run: Alle interval einen Durchlauf, bis ctx endet
+func (e *orderExpirer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := e.expire(ctx)
+			if err != nil {
+				e.logger.Error("failed to expire unpaid orders", slog.Any("error", err))
+			} else if expired > 0 {
+				e.logger.Info("expired unpaid orders", slog.Int("count", expired))
+			}
+		}
+	}
+}
+
+// expire: Ein Durchlauf → Anzahl der abgelaufenen Orders
+// Warum weitermachen wenn eine Order fehlschlägt?
+// → Nächster Tick findet sie wieder (Status ist dann noch pending/waiting_payment)
+func (e *orderExpirer) expire(ctx context.Context) (int, error) {
+	orders, err := e.store.GetStuck(ctx, stuckOrderStatuses, time.Now().Add(-e.after))
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, order := range orders {
+		if e.expireOrder(ctx, order) {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+// expireOrder: status="expired" (nur wenn noch unbezahlt) → order.expired Event
+// → Event fehlgeschlagen → kein Retry: Die Reservation läuft im Stock Service ohnehin über die TTL ab
+func (e *orderExpirer) expireOrder(ctx context.Context, order *api.Order) bool {
+	changed, err := e.store.UpdateStatusIf(ctx, order.Id, stuckOrderStatuses, orderstatus.StatusExpired)
+	if err != nil {
+		e.logger.Error("failed to expire order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		return false
+	}
+	if !changed {
+		// Inzwischen bezahlt/storniert → nichts zu tun
+		e.logger.Info("order changed status before expiry, skipping",
+			slog.String("order_id", order.Id),
+		)
+		return false
+	}
+	order.Status = orderstatus.StatusExpired
+
+	if err := publishExchangeEvent(ctx, e.channel, broker.OrderExpiredEvent, broker.MessageID(broker.OrderExpiredEvent, order.Id), order); err != nil {
+		e.logger.Error("failed to publish event",
+			slog.String("event", broker.OrderExpiredEvent),
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+	} else {
+		e.logger.Info("event published",
+			slog.String("event", broker.OrderExpiredEvent),
+			slog.String("order_id", order.Id),
+		)
+	}
+
+	return true
+}
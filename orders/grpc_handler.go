@@ -3,37 +3,119 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/timour/order-microservices/common/api"
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/config"
 	"github.com/timour/order-microservices/common/discovery"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/common/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ordersByStatusTimeout: Zeitbudget für GetOrdersByStatus → danach Teilergebnis statt Fehler
+const ordersByStatusTimeout = 2 * time.Second
+
+// maxOrdersPageSize: Default UND Obergrenze für GetOrdersByStatus (limit=0 → 100)
+// Warum Obergrenze?
+// → Zehntausende "ready" Orders in EINER Antwort → Memory Spike in Orders + Gateway, Kitchen Display lahm
+const maxOrdersPageSize = 100
+
+// defaultStuckOrderAge: Ab wann eine unbezahlte Order als "hängend" gilt (= Reservation TTL im Stock Service)
+const defaultStuckOrderAge = 15 * time.Minute
+
+// stuckOrderStatuses: Status in denen eine Order NICHT ewig bleiben sollte
+var stuckOrderStatuses = []string{orderstatus.StatusPending, orderstatus.StatusWaitingPayment}
+
 type grpcHandler struct {
 	api.UnimplementedOrderServiceServer
 	service  OrdersService
 	store    OrdersStore
-	channel  *amqp.Channel
+	channel  broker.Channel // nil = kein RabbitMQ (Tests/Entwicklung) → Events werden nur geloggt
 	logger   *slog.Logger
 	registry discovery.Registry
+
+	reservationMode config.ReservationMode   // on_create: CreateOrder reserviert, on_pay: Payments reserviert
+	idempotency     IdempotencyStore         // nil = Idempotency Keys nur über den Unique Index auf orders
+	metrics         *metrics.BusinessMetrics // nil = keine Business Metrics (nil-safe)
 }
 
-func NewGRPCHandler(grpcServer *grpc.Server, service OrdersService, store OrdersStore, channel *amqp.Channel, logger *slog.Logger, registry discovery.Registry) {
+func NewGRPCHandler(grpcServer *grpc.Server, service OrdersService, store OrdersStore, channel broker.Channel, logger *slog.Logger, registry discovery.Registry, reservationMode config.ReservationMode, idempotency IdempotencyStore, businessMetrics *metrics.BusinessMetrics) {
 	handler := &grpcHandler{
 		service:  service,
 		store:    store,
 		channel:  channel,
 		logger:   logger,
 		registry: registry,
+
+		reservationMode: reservationMode,
+		idempotency:     idempotency,
+		metrics:         businessMetrics,
 	}
 	api.RegisterOrderServiceServer(grpcServer, handler)
 }
 
+// CreateOrder: Mit Idempotency Key → höchstens EINE Order pro (customerID, Key)
+// Warum den Key VOR allem anderen beanspruchen?
+// → Gateway retried nach Timeout, der erste Request läuft evtl. noch
+// → Ohne Claim: Beide prüfen Stock, beide reservieren → doppelte Reservierung bis der Unique Index greift
 func (h *grpcHandler) CreateOrder(ctx context.Context, req *api.CreateOrderRequest) (*api.Order, error) {
+	if req.IdempotencyKey == "" || h.idempotency == nil {
+		return h.createOrder(ctx, req)
+	}
+
+	existingID, claimed, err := h.idempotency.Claim(ctx, req.CustomerId, req.IdempotencyKey)
+	if err != nil {
+		// Kein Abbruch: Der Unique Index auf orders verhindert Duplikate weiterhin
+		h.logger.Warn("failed to claim idempotency key, relying on order index",
+			slog.String("customer_id", req.CustomerId),
+			slog.Any("error", err),
+		)
+		return h.createOrder(ctx, req)
+	}
+
+	if !claimed {
+		if existingID == "" {
+			// Erst-Request läuft noch → Client soll es gleich nochmal versuchen
+			return nil, status.Error(codes.Aborted, "a request with this idempotency key is still in progress")
+		}
+		h.logger.Info("idempotency key already used, returning existing order",
+			slog.String("order_id", existingID),
+			slog.String("customer_id", req.CustomerId),
+		)
+		return h.store.Get(ctx, existingID)
+	}
+
+	order, err := h.createOrder(ctx, req)
+	if err != nil {
+		// Fehlgeschlagen → Key freigeben, sonst bekäme jeder Retry "in progress"
+		if releaseErr := h.idempotency.Release(context.WithoutCancel(ctx), req.CustomerId, req.IdempotencyKey); releaseErr != nil {
+			h.logger.Error("failed to release idempotency key", slog.Any("error", releaseErr))
+		}
+		return nil, err
+	}
+
+	if err := h.idempotency.Complete(context.WithoutCancel(ctx), req.CustomerId, req.IdempotencyKey, order.Id); err != nil {
+		h.logger.Error("failed to bind idempotency key to order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+	}
+
+	return order, nil
+}
+
+// createOrder: Stock Check → MongoDB → Reservierung → order.created Event
+func (h *grpcHandler) createOrder(ctx context.Context, req *api.CreateOrderRequest) (*api.Order, error) {
 	h.logger.Info("order received",
 		slog.String("customer_id", req.CustomerId),
 		slog.Int("items_count", len(req.Items)),
@@ -47,7 +129,7 @@ func (h *grpcHandler) CreateOrder(ctx context.Context, req *api.CreateOrderReque
 	conn, err := discovery.ServiceConnection(ctx, "stock", h.registry)
 	if err != nil {
 		h.logger.Error("failed to connect to stock service", slog.Any("error", err))
-		return nil, fmt.Errorf("stock service unavailable: %w", err)
+		return nil, status.Errorf(codes.Unavailable, "stock service unavailable: %v", err)
 	}
 	defer conn.Close()
 
@@ -70,7 +152,7 @@ func (h *grpcHandler) CreateOrder(ctx context.Context, req *api.CreateOrderReque
 	stockResp, err := stockClient.CheckIfItemIsInStock(ctx, stockCheckReq)
 	if err != nil {
 		h.logger.Error("stock check failed", slog.Any("error", err))
-		return nil, fmt.Errorf("failed to check stock: %w", err)
+		return nil, downstreamError("stock", err)
 	}
 
 	// Warum InStock Check?
@@ -80,8 +162,17 @@ func (h *grpcHandler) CreateOrder(ctx context.Context, req *api.CreateOrderReque
 		h.logger.Warn("items not in stock",
 			slog.Int("requested_items", len(req.Items)),
 			slog.Int("available_items", len(stockResp.Items)),
+			slog.Int("out_of_stock_items", len(stockResp.OutOfStockItems)),
 		)
-		return nil, fmt.Errorf("one or more items are not in stock")
+
+		// Verlorener Verkauf → pro fehlendem Item zählen (Nachfrage vs. Verfügbarkeit)
+		itemIDs := make([]string, 0, len(stockResp.OutOfStockItems))
+		for _, item := range stockResp.OutOfStockItems {
+			itemIDs = append(itemIDs, item.ItemID)
+		}
+		h.metrics.RecordStockoutRejection(itemIDs...)
+
+		return nil, outOfStockError(stockResp.OutOfStockItems)
 	}
 
 	h.logger.Info("stock check passed",
@@ -123,7 +214,7 @@ func (h *grpcHandler) CreateOrder(ctx context.Context, req *api.CreateOrderReque
 			h.logger.Error("item not found in stock response",
 				slog.String("item_id", itemId),
 			)
-			return nil, fmt.Errorf("item %s not found in stock", itemId)
+			return nil, status.Errorf(codes.InvalidArgument, "item %s not found in stock", itemId)
 		}
 		items = append(items, &api.Item{
 			ID:       itemId,
@@ -135,57 +226,55 @@ func (h *grpcHandler) CreateOrder(ctx context.Context, req *api.CreateOrderReque
 
 	// Create order WITHOUT ID - MongoDB will generate unique _id
 	orderToCreate := &api.Order{
-		CustomerId: req.CustomerId,
-		Status:     "pending",
-		Items:      items,
+		CustomerId:     req.CustomerId,
+		Status:         orderstatus.StatusPending,
+		Items:          items,
+		SessionId:      req.SessionId,      // Optional: Tisch/Session → gemeinsame Bezahlung
+		StripeAccount:  req.StripeAccount,  // Optional: Restaurant Account → Payments erstellt den Link dort
+		IdempotencyKey: req.IdempotencyKey, // Optional: Unique Index verhindert doppelte Orders
 	}
 
 	// Store order and get MongoDB-generated _id
 	objectID, err := h.store.Create(ctx, orderToCreate)
+	if errors.Is(err, ErrDuplicateOrder) {
+		// ⭐ Gleicher Request nochmal → bestehende Order zurück
+		// → KEINE zweite Reservation, KEIN zweites order.created Event
+		h.logger.Info("duplicate order request, returning existing order",
+			slog.String("order_id", objectID.Hex()),
+			slog.String("customer_id", req.CustomerId),
+		)
+		return h.store.Get(ctx, objectID.Hex())
+	}
 	if err != nil {
 		h.logger.Error("failed to store order", slog.Any("error", err))
 		return nil, err
 	}
+	h.metrics.RecordOrderCreated()
 
 	// Use MongoDB's _id as Order ID (hex string)
 	order := &api.Order{
-		Id:         objectID.Hex(),  // ✅ Unique MongoDB ObjectID!
-		CustomerId: req.CustomerId,
-		Status:     "pending",
-		Items:      items,
-		CreatedAt:  objectID.Timestamp().Format("2006-01-02T15:04:05Z07:00"), // ISO 8601 timestamp from MongoDB ObjectID
+		Id:             objectID.Hex(),  // ✅ Unique MongoDB ObjectID!
+		CustomerId:     req.CustomerId,
+		Status:         orderstatus.StatusPending,
+		Items:          items,
+		CreatedAt:      orderToCreate.CreatedAt, // Von store.Create gesetzt → UTC RFC3339Nano wie GetOrder
+		UpdatedAt:      orderToCreate.UpdatedAt,
+		SessionId:      req.SessionId,
+		StripeAccount:  req.StripeAccount,
+		IdempotencyKey: req.IdempotencyKey,
 	}
 
 	// ⭐ STEP 3: Reserve Stock (NEW!)
-	// Warum JETZT?
-	// → Order existiert bereits in MongoDB mit status="pending"
-	// → Falls Reservation fehlschlägt: Order bleibt "pending" (kein Payment Link)
-	// → Falls Reservation erfolgreich: Stock ist reserviert für 15 Minuten!
-	h.logger.Info("reserving stock for order",
-		slog.String("order_id", order.Id),
-		slog.Int("items_count", len(items)),
-	)
-
-	reserveReq := &api.ReserveStockRequest{
-		OrderID: order.Id,
-		Items:   items,
-	}
-
-	reserveResp, err := stockClient.ReserveStock(ctx, reserveReq)
-	if err != nil {
-		h.logger.Error("failed to reserve stock",
-			slog.String("order_id", order.Id),
-			slog.Any("error", err),
-		)
-		// Stock reservation failed → Order stays "pending" without payment link
-		return nil, fmt.Errorf("failed to reserve stock: %w", err)
+	// on_pay Mode: Hier NUR der Availability Check (STEP 1)
+	// → Payments reserviert erst direkt vor der Checkout Session
+	// → Browser die nie zahlen blockieren keinen Stock
+	if h.reservationMode == config.ReservationOnCreate {
+		if err := h.reserveStock(ctx, stockClient, order); err != nil {
+			h.abandonOrder(ctx, stockClient, order)
+			return nil, err
+		}
 	}
 
-	h.logger.Info("stock reserved successfully",
-		slog.String("order_id", order.Id),
-		slog.String("reservation_id", reserveResp.ReservationID),
-	)
-
 	// ⭐ STEP 4: Publish Event to RabbitMQ
 	// Warum channel == nil Check?
 	// → RabbitMQ ist OPTIONAL! Service funktioniert auch OHNE Events
@@ -244,6 +333,7 @@ func (h *grpcHandler) CreateOrder(ctx context.Context, req *api.CreateOrderReque
 			ContentType: "application/json",        // Warum? Payment Service weiß: Body ist JSON!
 			Body:        marshalledOrder,           // Die eigentliche Order als JSON bytes
 			Headers:     broker.InjectTraceContext(ctx), // ⭐ OpenTelemetry trace context!
+			MessageId:   broker.MessageID(broker.OrderCreatedEvent, order.Id), // Consumer Dedup bei Redelivery
 		},
 	)
 	if err != nil {
@@ -274,7 +364,20 @@ func (h *grpcHandler) UpdateOrder(ctx context.Context, req *api.Order) (*api.Ord
 	previousOrder, err := h.store.Get(ctx, req.Id)
 	if err != nil {
 		h.logger.Error("failed to get previous order", slog.Any("error", err))
-		return nil, fmt.Errorf("order not found: %w", err)
+		return nil, orderLookupError(req.Id, err)
+	}
+
+	// Warum Transition Check VOR dem Update?
+	// → Ungültiger Wechsel (z.B. preparing → completed) darf MongoDB nie erreichen
+	if req.Status != "" {
+		if err := validateStatusTransition(previousOrder.Status, req.Status); err != nil {
+			h.logger.Warn("rejected status transition",
+				slog.String("order_id", req.Id),
+				slog.String("previous_status", previousOrder.Status),
+				slog.String("status", req.Status),
+			)
+			return nil, err
+		}
 	}
 
 	// Update the order
@@ -294,11 +397,11 @@ func (h *grpcHandler) UpdateOrder(ctx context.Context, req *api.Order) (*api.Ord
 	if previousOrder.Status != updatedOrder.Status && h.channel != nil {
 		var eventName string
 		switch updatedOrder.Status {
-		case "paid":
+		case orderstatus.StatusPaid:
 			eventName = broker.OrderPaidEvent
-		case "preparing":
+		case orderstatus.StatusPreparing:
 			eventName = broker.OrderPreparingEvent
-		case "ready":
+		case orderstatus.StatusReady:
 			eventName = broker.OrderReadyEvent
 		default:
 			// No event for other status changes (e.g., payment_link updates)
@@ -308,23 +411,6 @@ func (h *grpcHandler) UpdateOrder(ctx context.Context, req *api.Order) (*api.Ord
 			return updatedOrder, nil
 		}
 
-		// Declare queue for this event
-		q, err := h.channel.QueueDeclare(
-			eventName, // name: "order.paid", "order.preparing", or "order.ready"
-			true,      // durable
-			false,     // auto-delete
-			false,     // exclusive
-			false,     // no-wait
-			nil,       // arguments
-		)
-		if err != nil {
-			h.logger.Error("failed to declare queue",
-				slog.String("queue", eventName),
-				slog.Any("error", err),
-			)
-			return updatedOrder, nil // Return order anyway (event publishing is non-critical)
-		}
-
 		// Marshal order to JSON
 		marshalledOrder, err := json.Marshal(updatedOrder)
 		if err != nil {
@@ -333,16 +419,21 @@ func (h *grpcHandler) UpdateOrder(ctx context.Context, req *api.Order) (*api.Ord
 		}
 
 		// Publish event with trace context
+		// Warum Exchange statt Default Exchange + Queue Name?
+		// → Payments publiziert order.paid auf den Exchange → ALLE gebundenen Queues (Orders, Kitchen, Stock) bekommen es
+		// → Default Exchange erreicht nur die gleichnamige Queue → Stock (eigene Queue am Exchange) ging leer aus
+		// → Exchanges deklariert broker.Connect, die Queues deklarieren/binden die Consumer
 		err = h.channel.PublishWithContext(
 			ctx,
-			"",     // exchange: default
-			q.Name, // routing key: queue name
-			false,  // mandatory
-			false,  // immediate
+			eventName, // exchange: "order.paid", "order.preparing" oder "order.ready"
+			"",        // routing key: Consumer binden mit "" (direct)
+			false,     // mandatory
+			false,     // immediate
 			amqp.Publishing{
 				ContentType: "application/json",
 				Body:        marshalledOrder,
 				Headers:     broker.InjectTraceContext(ctx),
+				MessageId:   broker.MessageID(eventName, updatedOrder.Id),
 			},
 		)
 		if err != nil {
@@ -372,7 +463,7 @@ func (h *grpcHandler) GetOrder(ctx context.Context, req *api.GetOrderRequest) (*
 	order, err := h.service.GetOrder(ctx, req.OrderId)
 	if err != nil {
 		h.logger.Error("failed to get order", slog.Any("error", err))
-		return nil, err
+		return nil, orderLookupError(req.OrderId, err)
 	}
 
 	return order, nil
@@ -383,7 +474,23 @@ func (h *grpcHandler) GetOrdersByStatus(ctx context.Context, req *api.GetOrdersB
 		slog.String("status", req.Status),
 	)
 
-	orders, err := h.store.GetByStatus(ctx, req.Status)
+	// Page Token = Hex _id der letzten Order der vorherigen Seite
+	var afterID primitive.ObjectID
+	if req.PageToken != "" {
+		id, err := primitive.ObjectIDFromHex(req.PageToken)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		afterID = id
+	}
+	limit := pageSize(req.Limit)
+
+	// ⭐ Zeitbudget für MongoDB → Kitchen Display blockiert nicht wenn Mongo langsam ist
+	queryCtx, cancel := context.WithTimeout(ctx, ordersByStatusTimeout)
+	defer cancel()
+
+	// limit+1: Die Extra Order zeigt nur an ob es eine nächste Seite gibt
+	orders, partial, err := h.store.GetByStatus(queryCtx, req.Status, afterID, int64(limit)+1)
 	if err != nil {
 		h.logger.Error("failed to get orders by status",
 			slog.String("status", req.Status),
@@ -392,10 +499,649 @@ func (h *grpcHandler) GetOrdersByStatus(ctx context.Context, req *api.GetOrdersB
 		return nil, err
 	}
 
-	h.logger.Info("orders retrieved successfully",
+	if partial {
+		h.logger.Warn("orders by status query hit deadline, returning partial result",
+			slog.String("status", req.Status),
+			slog.Int("count", len(orders)),
+			slog.Duration("timeout", ordersByStatusTimeout),
+		)
+	} else {
+		h.logger.Info("orders retrieved successfully",
+			slog.String("status", req.Status),
+			slog.Int("count", len(orders)),
+		)
+	}
+
+	orders, next := pageOrders(orders, limit)
+	if partial && len(orders) > 0 {
+		// Teilergebnis → ab der letzten gelesenen Order weiterblättern statt Orders zu verschlucken
+		next = orders[len(orders)-1].Id
+	}
+	return &api.GetOrdersByStatusResponse{Orders: orders, Partial: partial, NextPageToken: next}, nil
+}
+
+// pageSize: 0 (alte Clients) → maxOrdersPageSize, größere Werte werden gedeckelt
+func pageSize(limit int32) int {
+	if limit <= 0 || limit > maxOrdersPageSize {
+		return maxOrdersPageSize
+	}
+	return int(limit)
+}
+
+// pageOrders: Kürzt auf limit und liefert den Token der nächsten Seite ("" = letzte Seite)
+// → Erwartet bis zu limit+1 Orders sortiert nach _id
+func pageOrders(orders []*api.Order, limit int) ([]*api.Order, string) {
+	if len(orders) <= limit {
+		return orders, ""
+	}
+	orders = orders[:limit]
+	return orders, orders[limit-1].Id
+}
+
+// GetOrdersBySession: Alle Orders eines Tisches/einer Session
+// Warum?
+// → Dine-In: Mehrere Bestellungen an einem Tisch → EIN gemeinsamer Checkout
+// → Gateway filtert daraus die unbezahlten Orders für den kombinierten Payment Link
+func (h *grpcHandler) GetOrdersBySession(ctx context.Context, req *api.GetOrdersBySessionRequest) (*api.GetOrdersBySessionResponse, error) {
+	h.logger.Info("getting orders by session",
+		slog.String("session_id", req.SessionId),
+	)
+
+	orders, err := h.store.GetBySession(ctx, req.SessionId)
+	if err != nil {
+		h.logger.Error("failed to get orders by session",
+			slog.String("session_id", req.SessionId),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	h.logger.Info("session orders retrieved successfully",
+		slog.String("session_id", req.SessionId),
+		slog.Int("count", len(orders)),
+	)
+
+	return &api.GetOrdersBySessionResponse{Orders: orders}, nil
+}
+
+// GetOrdersByCustomer: Bestellhistorie eines Kunden ("Meine Bestellungen")
+// → Optional gefiltert nach Status und Zeitraum [since, until), neueste zuerst, höchstens limit Orders
+// → customer_id, status und Zeitformat prüft der validation Interceptor
+func (h *grpcHandler) GetOrdersByCustomer(ctx context.Context, req *api.GetOrdersByCustomerRequest) (*api.GetOrdersByCustomerResponse, error) {
+	h.logger.Info("getting orders by customer",
+		slog.String("customer_id", req.CustomerId),
 		slog.String("status", req.Status),
+		slog.String("since", req.Since),
+		slog.String("until", req.Until),
+	)
+
+	since, until, err := validation.ParseTimeRange(req.Since, req.Until)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	orders, err := h.store.GetByCustomer(ctx, req.CustomerId, req.Status, since, until, int64(pageSize(req.Limit)))
+	if err != nil {
+		h.logger.Error("failed to get orders by customer",
+			slog.String("customer_id", req.CustomerId),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	h.logger.Info("customer orders retrieved successfully",
+		slog.String("customer_id", req.CustomerId),
+		slog.Int("count", len(orders)),
+	)
+
+	return &api.GetOrdersByCustomerResponse{Orders: orders}, nil
+}
+
+// GetStuckOrders: Unbezahlte Orders älter als older_than_seconds
+// Warum pending UND waiting_payment?
+// → pending = Reservation/Event/Payment Link fehlgeschlagen → Kunde hat nie einen Link bekommen
+// → waiting_payment = Link da, aber nie bezahlt → Reservation ist längst abgelaufen
+func (h *grpcHandler) GetStuckOrders(ctx context.Context, req *api.GetStuckOrdersRequest) (*api.GetStuckOrdersResponse, error) {
+	olderThan := time.Duration(req.OlderThanSeconds) * time.Second
+	if olderThan == 0 {
+		olderThan = defaultStuckOrderAge
+	}
+
+	orders, err := h.store.GetStuck(ctx, stuckOrderStatuses, time.Now().Add(-olderThan))
+	if err != nil {
+		h.logger.Error("failed to get stuck orders",
+			slog.Duration("older_than", olderThan),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	h.logger.Info("stuck orders retrieved",
+		slog.Duration("older_than", olderThan),
 		slog.Int("count", len(orders)),
 	)
 
-	return &api.GetOrdersByStatusResponse{Orders: orders}, nil
+	return &api.GetStuckOrdersResponse{Orders: orders}, nil
+}
+
+// AdjustOrderItems: Reduziert Mengen einer BEZAHLTEN Order
+// Flow:
+// 1. Order laden, liegengebliebene order.items_adjusted Events nachholen (Outbox)
+// 2. Status prüfen (nur "paid") + neue/entfernte Mengen berechnen (computeItemAdjustment)
+// 3. MongoDB: Items per Compare-and-Set ersetzen + Event in die Outbox
+// 4. Stock Service: Entfernte Mengen zurückbuchen (RestockItems)
+// 5. RabbitMQ: "order.items_adjusted" → Payments erstellt anteilige Rückerstattung
+func (h *grpcHandler) AdjustOrderItems(ctx context.Context, req *api.AdjustOrderItemsRequest) (*api.Order, error) {
+	h.logger.Info("adjusting order items",
+		slog.String("order_id", req.OrderId),
+		slog.Int("items_count", len(req.Items)),
+	)
+
+	// order_id + items werden vom validation Interceptor geprüft
+
+	order, err := h.store.Get(ctx, req.OrderId)
+	if err != nil {
+		h.logger.Error("failed to get order", slog.Any("error", err))
+		return nil, orderLookupError(req.OrderId, err)
+	}
+
+	if req.CustomerId != "" && req.CustomerId != order.CustomerId {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+
+	// Publish beim letzten Mal fehlgeschlagen → Erstattung JETZT nachholen, auch wenn sich sonst nichts ändert
+	if err := h.publishPendingAdjustments(ctx, order.Id); err != nil {
+		return nil, err
+	}
+
+	// Warum nur "paid"?
+	// → Vorher: Noch nichts bezahlt → nichts zu erstatten (Kunde bestellt einfach neu)
+	// → Danach (preparing/ready): Küche hat schon gekocht → kein Restock mehr möglich
+	if order.Status != orderstatus.StatusPaid {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"order %s is %q, only paid orders can be adjusted", order.Id, order.Status)
+	}
+
+	remaining, removed, err := computeItemAdjustment(order.Items, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(removed) == 0 {
+		return order, nil // Nichts reduziert → nichts zu tun
+	}
+
+	if len(remaining) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "adjustment would remove all items, cancel the order instead")
+	}
+
+	// ⭐ STEP 1: Order in MongoDB anpassen (Compare-and-Set) + Event in die Outbox
+	// Warum vor dem Restock?
+	// → Nur EINE von zwei parallelen Anpassungen gewinnt → nur sie bucht zurück und erstattet
+	// Warum adjustment_id?
+	// → Payments nutzt sie als Stripe Idempotency Key → Retry erstattet NICHT doppelt
+	previous := order.Items
+	adjusted := &api.OrderItemsAdjusted{
+		AdjustmentId: primitive.NewObjectID().Hex(),
+		OrderId:      order.Id,
+		CustomerId:   order.CustomerId,
+		RemovedItems: removed,
+	}
+	changed, err := h.store.AdjustItems(ctx, order.Id, previous, remaining, adjusted)
+	if err != nil {
+		h.logger.Error("failed to adjust order items",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		return nil, status.Errorf(codes.Internal, "failed to adjust order %s: %v", order.Id, err)
+	}
+	if !changed {
+		// Parallel angepasst oder nicht mehr "paid" (z.B. Kitchen → "preparing")
+		return nil, status.Errorf(codes.Aborted, "order %s changed while adjusting, please retry", order.Id)
+	}
+
+	// ⭐ STEP 2: Restock
+	// → Schlägt Restock fehl, wird die Anpassung zurückgerollt (kein Refund ohne Restock)
+	// ⚠️ Absturz zwischen STEP 1 und 2: Outbox Eintrag bleibt → Kunde wird erstattet, Stock zählt die Items zu niedrig (kein Overselling)
+	if err := h.restockItems(ctx, order.Id, removed); err != nil {
+		if reverted, revertErr := h.store.RevertAdjustment(ctx, order.Id, adjusted.AdjustmentId, previous, remaining); revertErr != nil || !reverted {
+			// Items reduziert, Stock nicht zurückgebucht → laut loggen für manuelle Korrektur
+			h.logger.Error("restock failed and order adjustment could not be reverted",
+				slog.String("order_id", order.Id),
+				slog.String("adjustment_id", adjusted.AdjustmentId),
+				slog.Any("error", revertErr),
+			)
+		}
+		return nil, err
+	}
+	order.Items = remaining
+
+	h.logger.Info("order items adjusted",
+		slog.String("order_id", order.Id),
+		slog.Int("removed_items", len(removed)),
+	)
+
+	// ⭐ STEP 3: Event für Payments (anteilige Rückerstattung)
+	// Warum Fehler statt nur Log?
+	// → Sonst meldet der RPC Erfolg und der Kunde wird nie erstattet
+	// → Eintrag bleibt in der Outbox → nächster AdjustOrderItems Call oder der adjustmentRelay publiziert ihn nach
+	if err := h.publishPendingAdjustments(ctx, order.Id); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// restockItems: Entfernte Mengen im Stock Service zurückbuchen
+func (h *grpcHandler) restockItems(ctx context.Context, orderID string, removed []*api.Item) error {
+	conn, err := discovery.ServiceConnection(ctx, "stock", h.registry)
+	if err != nil {
+		h.logger.Error("failed to connect to stock service", slog.Any("error", err))
+		return status.Errorf(codes.Unavailable, "stock service unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	restockItems := make([]*api.ItemsWithQuantity, 0, len(removed))
+	for _, item := range removed {
+		restockItems = append(restockItems, &api.ItemsWithQuantity{ID: item.ID, Quantity: item.Quantity})
+	}
+
+	if _, err := api.NewStockServiceClient(conn).RestockItems(ctx, &api.RestockItemsRequest{
+		OrderID: orderID,
+		Items:   restockItems,
+	}); err != nil {
+		h.logger.Error("failed to restock items",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		return downstreamError("stock", err)
+	}
+	return nil
+}
+
+// publishPendingAdjustments: Outbox der Order leeren → ein order.items_adjusted Event pro Eintrag (publishAdjustment)
+// Returns: Unavailable wenn ein Publish scheitert (Eintrag bleibt liegen → nächster Call oder adjustmentRelay)
+func (h *grpcHandler) publishPendingAdjustments(ctx context.Context, orderID string) error {
+	pending, err := h.store.PendingAdjustments(ctx, orderID)
+	if err != nil {
+		h.logger.Error("failed to load pending adjustments",
+			slog.String("order_id", orderID),
+			slog.Any("error", err),
+		)
+		return orderLookupError(orderID, err)
+	}
+
+	for _, adjusted := range pending {
+		if err := publishAdjustment(ctx, h.channel, h.store, h.logger, adjusted); err != nil {
+			return status.Errorf(codes.Unavailable, "order %s adjusted but refund event not yet published, please retry: %v", orderID, err)
+		}
+	}
+	return nil
+}
+
+// CancelOrder: Order vor der Zubereitung stornieren (unbezahlt oder "paid")
+// Ablauf: status="cancelled" (Compare-and-Set) → Reservation freigeben → order.cancelled Event
+// → Bezahlte Order: Payments konsumiert order.cancelled und erstattet über Stripe
+// Warum idempotent?
+// → Gateway/Client retried bei Timeout → zweiter Call darf nicht fehlschlagen
+// → Order schon "cancelled" → Release + Event nochmal (beides idempotent): Ein abgebrochener erster Versuch wird so zu Ende geführt
+func (h *grpcHandler) CancelOrder(ctx context.Context, req *api.CancelOrderRequest) (*api.Order, error) {
+	h.logger.Info("cancelling order",
+		slog.String("order_id", req.OrderId),
+	)
+
+	order, err := h.store.Get(ctx, req.OrderId)
+	if err != nil {
+		h.logger.Error("failed to get order", slog.Any("error", err))
+		return nil, orderLookupError(req.OrderId, err)
+	}
+
+	if req.CustomerId != "" && req.CustomerId != order.CustomerId {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+
+	if err := validateCancellation(order.Status); err != nil {
+		h.logger.Warn("rejected cancellation",
+			slog.String("order_id", order.Id),
+			slog.String("status", order.Status),
+		)
+		return nil, err
+	}
+
+	observed := order.Status
+	claimed, err := h.cancel(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// Order ist zwischen Lesen und Stornieren weitergelaufen (z.B. Kitchen → "preparing")
+		if err := validateCancellation(order.Status); err != nil {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Aborted, "order changed status from %q to %q while cancelling, please retry", observed, order.Status)
+	}
+	return order, nil
+}
+
+// CancelCustomerUnpaidOrders: Alle pending/waiting_payment Orders eines Kunden stornieren (Support)
+// Warum weitermachen wenn eine Order fehlschlägt?
+// → Eine hängende Reservation soll nicht alle anderen Warenkörbe blockieren
+// → failed > 0 → Support ruft nochmal auf, schon stornierte Orders sind dann nicht mehr "unbezahlt"
+func (h *grpcHandler) CancelCustomerUnpaidOrders(ctx context.Context, req *api.CancelCustomerUnpaidOrdersRequest) (*api.CancelCustomerUnpaidOrdersResponse, error) {
+	orders, err := h.store.GetByCustomerAndStatus(ctx, req.CustomerId, stuckOrderStatuses)
+	if err != nil {
+		h.logger.Error("failed to get unpaid orders",
+			slog.String("customer_id", req.CustomerId),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	resp := &api.CancelCustomerUnpaidOrdersResponse{}
+	for _, order := range orders {
+		claimed, err := h.cancel(ctx, order)
+		if err != nil {
+			resp.Failed++
+			continue
+		}
+		if !claimed {
+			// Inzwischen bezahlt/weitergelaufen → nicht mehr unbezahlt, bleibt unangetastet
+			continue
+		}
+		resp.Cancelled++
+	}
+
+	h.logger.Info("unpaid customer orders cancelled",
+		slog.String("customer_id", req.CustomerId),
+		slog.Int("cancelled", int(resp.Cancelled)),
+		slog.Int("failed", int(resp.Failed)),
+	)
+
+	return resp, nil
+}
+
+// DeleteOrder: Order soft-deleten (Admin, Data Retention)
+// → Verschwindet aus GetOrdersByStatus/GetOrdersByCustomer/GetOrdersBySession, GetOrder findet sie weiterhin (Audit)
+// Warum nur Endzustände?
+// → Aktive Order ausblenden = Kitchen Display sieht sie nicht mehr, obwohl bezahlt/gekocht wird
+// Idempotent: Schon gelöscht → Order unverändert zurückgeben
+func (h *grpcHandler) DeleteOrder(ctx context.Context, req *api.DeleteOrderRequest) (*api.Order, error) {
+	order, err := h.store.Get(ctx, req.OrderId)
+	if err != nil {
+		h.logger.Error("failed to get order", slog.Any("error", err))
+		return nil, orderLookupError(req.OrderId, err)
+	}
+
+	if order.DeletedAt != "" {
+		h.logger.Info("order already deleted", slog.String("order_id", order.Id))
+		return order, nil
+	}
+
+	if !orderstatus.IsFinal(order.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "order is %q and can only be deleted once it is completed, cancelled or expired", order.Status)
+	}
+
+	if _, err := h.store.SoftDelete(ctx, order.Id); err != nil {
+		h.logger.Error("failed to delete order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		return nil, status.Errorf(codes.Internal, "failed to delete order %s: %v", order.Id, err)
+	}
+
+	h.logger.Info("order soft-deleted", slog.String("order_id", order.Id))
+	return h.reloadOrder(ctx, order.Id)
+}
+
+// RestoreOrder: Soft-Delete rückgängig machen (Admin, Undo)
+// Idempotent: Nicht gelöscht → Order unverändert zurückgeben
+func (h *grpcHandler) RestoreOrder(ctx context.Context, req *api.RestoreOrderRequest) (*api.Order, error) {
+	order, err := h.store.Get(ctx, req.OrderId)
+	if err != nil {
+		h.logger.Error("failed to get order", slog.Any("error", err))
+		return nil, orderLookupError(req.OrderId, err)
+	}
+
+	if order.DeletedAt == "" {
+		h.logger.Info("order not deleted, nothing to restore", slog.String("order_id", order.Id))
+		return order, nil
+	}
+
+	if _, err := h.store.Restore(ctx, order.Id); err != nil {
+		h.logger.Error("failed to restore order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		return nil, status.Errorf(codes.Internal, "failed to restore order %s: %v", order.Id, err)
+	}
+
+	h.logger.Info("order restored", slog.String("order_id", order.Id))
+	return h.reloadOrder(ctx, order.Id)
+}
+
+// reloadOrder: Order nach einem Store Update neu laden → deletedAt/updatedAt wie gespeichert
+func (h *grpcHandler) reloadOrder(ctx context.Context, orderID string) (*api.Order, error) {
+	order, err := h.store.Get(ctx, orderID)
+	if err != nil {
+		return nil, orderLookupError(orderID, err)
+	}
+	return order, nil
+}
+
+// cancel: status="cancelled" (Compare-and-Set) → Reservation freigeben → order.cancelled Event
+// → Status muss vorher geprüft sein (validateCancellation), setzt order.Status bei Erfolg
+// Returns: false wenn die Order seit dem Lesen weitergelaufen ist → NICHTS freigegeben/erstattet, order.Status = aktueller Status
+func (h *grpcHandler) cancel(ctx context.Context, order *api.Order) (bool, error) {
+	// ⭐ STEP 1: Status in MongoDB, nur wenn er noch der gelesene ist
+	// Warum nicht store.Update?
+	// → Zwischen Lesen und Schreiben kann die Kitchen "preparing" setzen oder der Webhook "paid"
+	// → Unbedingtes Update überschreibt das → Essen wird gekocht, aber erstattet + Stock freigegeben
+	// Warum Status VOR dem Release?
+	// → Release erst wenn feststeht, dass WIR storniert haben
+	// → Release schlägt fehl → Retry sieht "cancelled" und führt Release + Event erneut aus (idempotent)
+	if order.Status != orderstatus.StatusCancelled {
+		changed, err := h.store.UpdateStatusIf(ctx, order.Id, []string{order.Status}, orderstatus.StatusCancelled)
+		if err != nil {
+			h.logger.Error("failed to cancel order",
+				slog.String("order_id", order.Id),
+				slog.Any("error", err),
+			)
+			return false, status.Errorf(codes.Internal, "failed to cancel order %s: %v", order.Id, err)
+		}
+		if !changed {
+			current, err := h.store.Get(ctx, order.Id)
+			if err != nil {
+				return false, orderLookupError(order.Id, err)
+			}
+			order.Status = current.Status
+			if current.Status != orderstatus.StatusCancelled {
+				h.logger.Warn("order changed status before cancellation, skipping",
+					slog.String("order_id", order.Id),
+					slog.String("status", current.Status),
+				)
+				return false, nil
+			}
+			// Paralleler Cancel war schneller → Release + Event trotzdem (idempotent)
+		}
+		order.Status = orderstatus.StatusCancelled
+
+		h.logger.Info("order cancelled",
+			slog.String("order_id", order.Id),
+		)
+	}
+
+	// ⭐ STEP 2: Reservation freigeben
+	// → Release ist im Stock Service idempotent (keine aktive Reservation → Erfolg)
+	conn, err := discovery.ServiceConnection(ctx, "stock", h.registry)
+	if err != nil {
+		h.logger.Error("failed to connect to stock service", slog.Any("error", err))
+		return false, status.Errorf(codes.Unavailable, "stock service unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	// ReservationID aus CreateOrder → genau diese Reservation (leer bei on_pay/Legacy Orders → alle der Order)
+	if _, err := api.NewStockServiceClient(conn).ReleaseReservation(ctx, &api.ReleaseReservationRequest{
+		OrderID:       order.Id,
+		ReservationID: order.ReservationId,
+	}); err != nil {
+		h.logger.Error("order cancelled but reservation release failed",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		return false, downstreamError("stock", err)
+	}
+
+	// ⭐ STEP 3: Event (Payments erstattet bezahlte Orders)
+	if err := publishExchangeEvent(ctx, h.channel, broker.OrderCancelledEvent, broker.MessageID(broker.OrderCancelledEvent, order.Id), order); err != nil {
+		h.logger.Error("failed to publish event",
+			slog.String("event", broker.OrderCancelledEvent),
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+	} else {
+		h.logger.Info("event published",
+			slog.String("event", broker.OrderCancelledEvent),
+			slog.String("order_id", order.Id),
+		)
+	}
+
+	return true, nil
+}
+
+// reserveStock: Reservation für eine frisch angelegte Order (on_create Mode)
+// Warum erst NACH store.Create?
+// → Order existiert bereits in MongoDB mit status="pending"
+// → Falls Reservation fehlschlägt: Caller räumt die Order per abandonOrder ab (kein Payment Link)
+// → Falls Reservation erfolgreich: Stock ist reserviert bis reserveResp.ExpiresAt (TTL lebt im Stock Service)
+func (h *grpcHandler) reserveStock(ctx context.Context, stockClient api.StockServiceClient, order *api.Order) error {
+	h.logger.Info("reserving stock for order",
+		slog.String("order_id", order.Id),
+		slog.Int("items_count", len(order.Items)),
+	)
+
+	reserveReq := &api.ReserveStockRequest{
+		OrderID: order.Id,
+		Items:   order.Items,
+	}
+
+	reserveResp, err := stockClient.ReserveStock(ctx, reserveReq)
+	if err != nil {
+		h.logger.Error("failed to reserve stock",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		// Stock Check war OK, Reserve nicht → Bestand hat sich dazwischen geändert → Aborted (409 "please retry")
+		return stockChangedError(err)
+	}
+
+	h.logger.Info("stock reserved successfully",
+		slog.String("order_id", order.Id),
+		slog.String("reservation_id", reserveResp.ReservationID),
+		slog.String("expires_at", reserveResp.ExpiresAt),
+	)
+
+	// Ablaufzeit + Reservation ID an der Order speichern
+	// → GetOrder (Success Page) kann den Countdown zeigen
+	// → CancelOrder gibt genau DIESE Reservation frei statt alles was an der Order ID hängt
+	order.ReservationExpiresAt = reserveResp.ExpiresAt
+	order.ReservationId = reserveResp.ReservationID
+	if err := h.store.Update(ctx, order.Id, &api.Order{
+		ReservationExpiresAt: reserveResp.ExpiresAt,
+		ReservationId:        reserveResp.ReservationID,
+	}); err != nil {
+		// Nicht kritisch: Reservation steht, nur Countdown/ID fehlen bei späteren Abfragen (Cancel gibt dann per Order ID frei)
+		h.logger.Warn("failed to store reservation",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+	}
+
+	return nil
+}
+
+// abandonOrder: Order aufräumen, deren Reservation in CreateOrder fehlgeschlagen ist
+// Warum?
+// → Client bekommt nur den Fehler (keine Order ID) → die Order würde als "pending" ohne Link hängen bleiben
+// → ReleaseReservation: Stock reserviert transaktional, aber bei Timeout/Verbindungsabbruch kann die Reservation trotzdem stehen
+// → Abandon: "pending" → "cancelled" + Idempotency Key frei → Retry mit demselben Key legt eine NEUE Order an
+// Fehler nur loggen: Reservation TTL und Expiry Job räumen notfalls später auf
+func (h *grpcHandler) abandonOrder(ctx context.Context, stockClient api.StockServiceClient, order *api.Order) {
+	ctx = context.WithoutCancel(ctx) // Request kann schon abgebrochen sein (Deadline), aufräumen trotzdem
+
+	if _, err := stockClient.ReleaseReservation(ctx, &api.ReleaseReservationRequest{OrderID: order.Id}); err != nil {
+		h.logger.Error("failed to release reservation of abandoned order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+	}
+
+	abandoned, err := h.store.Abandon(ctx, order.Id)
+	if err != nil {
+		h.logger.Error("failed to abandon order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	h.logger.Info("order abandoned after failed reservation",
+		slog.String("order_id", order.Id),
+		slog.Bool("cancelled", abandoned),
+	)
+}
+
+// publish: Declare Queue (mit DLX) + JSON Publish über den Default Exchange
+// → Gleiches Muster wie CreateOrder, nur als Helper für neue Events
+// → messageID: Eindeutig pro Event → Consumer Dedup bei Redelivery
+func (h *grpcHandler) publish(ctx context.Context, queue, messageID string, payload any) error {
+	return publishEvent(ctx, h.channel, queue, messageID, payload)
+}
+
+// publishEvent: publish ohne grpcHandler (Declare Queue + Default Exchange)
+func publishEvent(ctx context.Context, ch broker.Channel, queue, messageID string, payload any) error {
+	if ch == nil {
+		return fmt.Errorf("rabbitmq channel is nil")
+	}
+
+	q, err := ch.QueueDeclare(
+		queue, // name
+		true,  // durable
+		false, // auto-delete
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange": broker.DLX,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+
+	return publishJSON(ctx, ch, "", q.Name, messageID, payload)
+}
+
+// publishExchangeEvent: JSON Publish an den Exchange exchange (Routing Key "")
+// Warum nicht publishEvent?
+// → publishEvent schreibt über den Default Exchange direkt in EINE Queue → Exchange + Bindings werden umgangen
+// → order.cancelled / order.expired haben eigene Exchanges (createExchanges) → Consumer binden ihre Queues selbst
+func publishExchangeEvent(ctx context.Context, ch broker.Channel, exchange, messageID string, payload any) error {
+	if ch == nil {
+		return fmt.Errorf("rabbitmq channel is nil")
+	}
+	return publishJSON(ctx, ch, exchange, "", messageID, payload)
+}
+
+func publishJSON(ctx context.Context, ch broker.Channel, exchange, key, messageID string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return ch.PublishWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+		Headers:      broker.InjectTraceContext(ctx),
+		MessageId:    messageID,
+	})
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// stuckStore: GetStuck merkt sich Status + Cutoff und liefert nur Orders vor dem Cutoff
+type stuckStore struct {
+	OrdersStore
+
+	orders        map[string]time.Time // orderID → createdAt
+	statuses      []string
+	createdBefore time.Time
+}
+
+func (s *stuckStore) GetStuck(_ context.Context, statuses []string, createdBefore time.Time) ([]*pb.Order, error) {
+	s.statuses, s.createdBefore = statuses, createdBefore
+
+	var orders []*pb.Order
+	for id, created := range s.orders {
+		if created.Before(createdBefore) {
+			orders = append(orders, &pb.Order{Id: id})
+		}
+	}
+	return orders, nil
+}
+
+func TestGetStuckOrders(t *testing.T) {
+	store := &stuckStore{orders: map[string]time.Time{
+		"stuck": time.Now().Add(-time.Hour),
+		"fresh": time.Now().Add(-time.Minute),
+	}}
+	h := &grpcHandler{store: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	resp, err := h.GetStuckOrders(context.Background(), &pb.GetStuckOrdersRequest{OlderThanSeconds: 30 * 60})
+	if err != nil {
+		t.Fatalf("GetStuckOrders: %v", err)
+	}
+	if len(resp.Orders) != 1 || resp.Orders[0].Id != "stuck" {
+		t.Fatalf("orders = %v; want only the stuck order", resp.Orders)
+	}
+	if !slices.Equal(store.statuses, stuckOrderStatuses) {
+		t.Errorf("statuses = %v; want %v", store.statuses, stuckOrderStatuses)
+	}
+	if age := time.Since(store.createdBefore); age < 30*time.Minute || age > 31*time.Minute {
+		t.Errorf("cutoff = %s ago; want 30m", age)
+	}
+}
+
+// older_than fehlt → Reservation TTL als Threshold
+func TestGetStuckOrdersDefaultAge(t *testing.T) {
+	store := &stuckStore{}
+	h := &grpcHandler{store: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	if _, err := h.GetStuckOrders(context.Background(), &pb.GetStuckOrdersRequest{}); err != nil {
+		t.Fatalf("GetStuckOrders: %v", err)
+	}
+	if age := time.Since(store.createdBefore); age < defaultStuckOrderAge || age > defaultStuckOrderAge+time.Minute {
+		t.Errorf("cutoff = %s ago; want %s", age, defaultStuckOrderAge)
+	}
+}
+
+// transitionStore: Get liefert die Order im vorherigen Status
+type transitionStore struct {
+	OrdersStore
+	status string
+}
+
+func (s *transitionStore) Get(_ context.Context, id string) (*pb.Order, error) {
+	return &pb.Order{Id: id, CustomerId: "c1", Status: s.status}, nil
+}
+
+// echoService: UpdateOrder übernimmt den Request unverändert
+type echoService struct {
+	OrdersService
+}
+
+func (echoService) UpdateOrder(_ context.Context, o *pb.Order) (*pb.Order, error) {
+	return &pb.Order{Id: o.Id, CustomerId: "c1", Status: o.Status}, nil
+}
+
+// order.paid aus Orders geht über den Exchange → JEDE gebundene Queue bekommt es (wie beim Webhook Publish in Payments)
+func TestUpdateOrderPublishesOnExchange(t *testing.T) {
+	b := brokertest.New()
+	queues := []string{"kitchen.order.paid", "stock.order.paid"}
+	for _, q := range queues {
+		if _, err := b.QueueDeclare(q, true, false, false, false, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.QueueBind(q, "", broker.OrderPaidEvent, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := &grpcHandler{
+		service: echoService{},
+		store:   &transitionStore{status: orderstatus.StatusWaitingPayment},
+		channel: b,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if _, err := h.UpdateOrder(context.Background(), &pb.Order{Id: "o1", Status: orderstatus.StatusPaid}); err != nil {
+		t.Fatalf("UpdateOrder: %v", err)
+	}
+
+	for _, q := range queues {
+		d, ok, err := b.Get(q, true)
+		if err != nil || !ok {
+			t.Fatalf("queue %s: no message (%v)", q, err)
+		}
+		if d.Exchange != broker.OrderPaidEvent || d.MessageId != broker.MessageID(broker.OrderPaidEvent, "o1") {
+			t.Errorf("queue %s: exchange/message id = %q/%q; want %q/%q", q, d.Exchange, d.MessageId, broker.OrderPaidEvent, broker.MessageID(broker.OrderPaidEvent, "o1"))
+		}
+		var o pb.Order
+		if err := json.Unmarshal(d.Body, &o); err != nil || o.Id != "o1" || o.Status != orderstatus.StatusPaid {
+			t.Errorf("queue %s: order = %+v (%v); want o1 paid", q, &o, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// idempotencyKeyTTL: Wie lange ein Idempotency Key an seine Order gebunden bleibt
+// → Gateway/Client Retries passieren innerhalb von Sekunden bis Minuten → 24h ist großzügig
+// → Danach räumt MongoDB den Key per TTL Index selbst weg
+const idempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyKeyIndexName: Unique Index über (customerID, key) → entscheidet welcher Retry "gewinnt"
+const idempotencyKeyIndexName = "customer_key"
+
+// idempotencyStore: Collection "idempotency_keys" → (customerID, key) → orderID
+// Warum eigene Collection statt nur dem Unique Index auf orders?
+// → Key wird VOR Stock Check + Reservierung beansprucht → gleichzeitige Retries reservieren nicht doppelt
+// → TTL: Keys laufen nach 24h ab, der Index auf orders hält sie für immer
+type idempotencyStore struct {
+	collection *mongo.Collection
+}
+
+func NewIdempotencyStore(client *mongo.Client) *idempotencyStore {
+	return &idempotencyStore{
+		collection: client.Database("orders").Collection("idempotency_keys"),
+	}
+}
+
+// EnsureIndexes: Unique Index (Race-Schutz) + TTL Index auf createdAt (idempotent)
+func (s *idempotencyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "customerID", Value: 1},
+				{Key: "key", Value: 1},
+			},
+			Options: options.Index().SetName(idempotencyKeyIndexName).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "createdAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(idempotencyKeyTTL.Seconds())),
+		},
+	})
+	return err
+}
+
+// Claim: Key atomar beanspruchen
+// → claimed=true: Dieser Request legt die Order an (danach Complete oder Release aufrufen)
+// → claimed=false: Key war schon vergeben → orderID der bestehenden Order ("" = Erst-Request läuft noch)
+// Warum Upsert mit $setOnInsert?
+// → Nur EIN gleichzeitiger Upsert fügt ein, alle anderen matchen den bestehenden Eintrag
+// → Zwei Upserts gleichzeitig auf einen neuen Key → der Verlierer bekommt DuplicateKey vom Unique Index
+func (s *idempotencyStore) Claim(ctx context.Context, customerID, key string) (orderID string, claimed bool, err error) {
+	filter := bson.M{"customerID": customerID, "key": key}
+	update := bson.M{"$setOnInsert": bson.M{
+		"customerID": customerID,
+		"key":        key,
+		"createdAt":  time.Now(),
+	}}
+
+	result, err := s.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return "", false, err
+	}
+	if err == nil && result.UpsertedCount == 1 {
+		return "", true, nil
+	}
+
+	var existing struct {
+		OrderID string `bson:"orderID"`
+	}
+	if err := s.collection.FindOne(ctx, filter).Decode(&existing); err != nil {
+		return "", false, err
+	}
+	return existing.OrderID, false, nil
+}
+
+// Complete: Angelegte Order an den Key binden → spätere Retries bekommen genau diese Order
+func (s *idempotencyStore) Complete(ctx context.Context, customerID, key, orderID string) error {
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"customerID": customerID, "key": key},
+		bson.M{"$set": bson.M{"orderID": orderID}},
+	)
+	return err
+}
+
+// Release: Claim eines fehlgeschlagenen Requests freigeben → Retry darf es nochmal versuchen
+// → Nur ohne orderID: Eine bereits angelegte Order bleibt an den Key gebunden
+func (s *idempotencyStore) Release(ctx context.Context, customerID, key string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{
+		"customerID": customerID,
+		"key":        key,
+		"orderID":    bson.M{"$exists": false},
+	})
+	return err
+}
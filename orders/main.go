@@ -11,6 +11,7 @@ import (
 
 	"github.com/timour/order-microservices/common/config"
 	"github.com/timour/order-microservices/common/logger"
+	"github.com/timour/order-microservices/common/startup"
 	"github.com/timour/order-microservices/common/tracing"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -26,7 +27,7 @@ func main() {
 		ConsulAddr:  config.GetEnv("CONSUL_ADDR", "localhost:8500"),
 		AMQPUser:    config.GetEnv("AMQP_USER", "guest"),
 		AMQPPass:    config.GetEnv("AMQP_PASS", "guest"),
-		AMQPHost:    config.GetEnv("AMQP_HOST", "localhost"),
+		AMQPHost:    config.GetEnv("AMQP_HOST", "localhost"), // Komma-separiert für Cluster Nodes ("rabbit-1,rabbit-2:5673")
 		AMQPPort:    config.GetEnv("AMQP_PORT", "5672"),
 		MongoURI:    config.GetEnv("MONGO_URI", "mongodb://localhost:27017"),
 	}
@@ -37,18 +38,68 @@ func main() {
 		slog.String("grpc_addr", cfg.GRPCAddr),
 	)
 
-	// ⭐ Initialize OpenTelemetry Tracing
-	shutdown, err := tracing.InitTracer(cfg.ServiceName)
+	// RESERVATION_MODE: on_create (Default) | on_pay
+	mode, err := config.ParseReservationMode(config.GetEnv("RESERVATION_MODE", ""))
 	if err != nil {
-		log.Error("failed to initialize tracer", slog.Any("error", err))
+		log.Error("invalid RESERVATION_MODE", slog.Any("error", err))
+		os.Exit(1)
+	}
+	cfg.ReservationMode = mode
+
+	// ORDER_EXPIRY_INTERVAL: Wie oft unbezahlte Orders geprüft werden (Default 1m, 0 = aus)
+	// ORDER_EXPIRY_AFTER: Ab welchem Alter sie "expired" werden (Default 15m = Reservation TTL)
+	cfg.OrderExpiryInterval = DefaultOrderExpiryInterval
+	if v := config.GetEnv("ORDER_EXPIRY_INTERVAL", ""); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Error("invalid ORDER_EXPIRY_INTERVAL", slog.String("value", v), slog.Any("error", err))
+			os.Exit(1)
+		}
+		cfg.OrderExpiryInterval = d
+	}
+	cfg.OrderExpiryAfter = DefaultOrderExpiryAfter
+	if v := config.GetEnv("ORDER_EXPIRY_AFTER", ""); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Error("invalid ORDER_EXPIRY_AFTER", slog.String("value", v), slog.Any("error", err))
+			os.Exit(1)
+		}
+		cfg.OrderExpiryAfter = d
+	}
+
+	// ADJUSTMENT_RELAY_INTERVAL: Wie oft die order.items_adjusted Outbox geleert wird (Default 30s, 0 = aus)
+	cfg.AdjustmentRelayInterval = DefaultAdjustmentRelayInterval
+	if v := config.GetEnv("ADJUSTMENT_RELAY_INTERVAL", ""); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Error("invalid ADJUSTMENT_RELAY_INTERVAL", slog.String("value", v), slog.Any("error", err))
+			os.Exit(1)
+		}
+		cfg.AdjustmentRelayInterval = d
+	}
+
+	// ⭐ Startup Report: Sammelt Tracer, MongoDB, Consul, RabbitMQ Ergebnisse
+	// → Am Ende von init EINE Zusammenfassung statt verstreuter "connected" Logs
+	report := startup.NewReport(cfg.ServiceName)
+
+	// ⭐ Initialize OpenTelemetry Tracing
+	var shutdown func()
+	if err := report.Check("tracer", true, func() (err error) {
+		shutdown, err = tracing.InitTracer(cfg.ServiceName)
+		return err
+	}); err != nil {
+		report.Log(log)
 		os.Exit(1)
 	}
 	defer shutdown()
 
 	// ⭐ Connect to MongoDB
-	mongoClient, err := connectToMongoDB(cfg.MongoURI)
-	if err != nil {
-		log.Error("failed to connect to mongodb", slog.Any("error", err))
+	var mongoClient *mongo.Client
+	if err := report.Check("mongodb", true, func() (err error) {
+		mongoClient, err = connectToMongoDB(cfg.MongoURI)
+		return err
+	}); err != nil {
+		report.Log(log)
 		os.Exit(1)
 	}
 	defer func() {
@@ -59,9 +110,16 @@ func main() {
 		}
 	}()
 
-	app, err := NewApp(cfg, mongoClient)
+	app, err := NewApp(cfg, mongoClient, report)
 	if err != nil {
-		log.Error("failed to create app", slog.Any("error", err))
+		report.Log(log)
+		os.Exit(1)
+	}
+
+	// Warum hier fail-fast?
+	// → Alle Dependencies sind jetzt initialisiert → Summary loggen
+	// → Required Dependency down → Service startet NICHT halb initialisiert
+	if err := report.Log(log); err != nil {
 		os.Exit(1)
 	}
 
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+// slowCursor: Liefert docs sofort, danach hängt Next bis ctx abläuft (wie ein langsames MongoDB getMore)
+type slowCursor struct {
+	docs    []bson.M
+	current bson.M
+	err     error
+}
+
+func (c *slowCursor) Next(ctx context.Context) bool {
+	if len(c.docs) > 0 {
+		c.current, c.docs = c.docs[0], c.docs[1:]
+		return true
+	}
+	<-ctx.Done()
+	c.err = ctx.Err()
+	return false
+}
+
+func (c *slowCursor) Decode(val interface{}) error {
+	raw, err := bson.Marshal(c.current)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, val)
+}
+
+func (c *slowCursor) Err() error { return c.err }
+
+func TestReadOrdersReturnsPartialResultOnDeadline(t *testing.T) {
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+	cursor := &slowCursor{docs: []bson.M{
+		{"_id": ids[0], "status": "pending"},
+		{"_id": ids[1], "status": "pending"},
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	orders, partial, err := readOrders(ctx, cursor)
+	if err != nil {
+		t.Fatalf("readOrders: %v", err)
+	}
+	if !partial {
+		t.Fatal("partial = false, want true after deadline")
+	}
+	if len(orders) != 2 || orders[0].Id != ids[0].Hex() || orders[1].Id != ids[1].Hex() {
+		t.Fatalf("orders = %v, want the 2 orders read before the deadline", orders)
+	}
+}
+
+func TestReadOrdersCancelIsError(t *testing.T) {
+	// Client bricht ab → kein Teilergebnis, sondern Fehler
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	orders, partial, err := readOrders(ctx, &slowCursor{})
+	if err == nil {
+		t.Fatalf("readOrders = %v, partial=%v, want error on cancel", orders, partial)
+	}
+}
+
+// statusStore: GetByStatus liefert ein Teilergebnis und merkt sich ob eine Deadline gesetzt war
+type statusStore struct {
+	OrdersStore
+
+	orders      []*pb.Order
+	partial     bool
+	hadDeadline bool
+}
+
+func (s *statusStore) GetByStatus(ctx context.Context, _ string, _ primitive.ObjectID, _ int64) ([]*pb.Order, bool, error) {
+	_, s.hadDeadline = ctx.Deadline()
+	return s.orders, s.partial, nil
+}
+
+func TestGetOrdersByStatusPartial(t *testing.T) {
+	store := &statusStore{
+		orders:  []*pb.Order{{Id: "a"}, {Id: "b"}},
+		partial: true,
+	}
+	h := &grpcHandler{store: store, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	resp, err := h.GetOrdersByStatus(context.Background(), &pb.GetOrdersByStatusRequest{Status: "pending"})
+	if err != nil {
+		t.Fatalf("GetOrdersByStatus: %v", err)
+	}
+	if !store.hadDeadline {
+		t.Error("store called without deadline")
+	}
+	if !resp.Partial {
+		t.Error("Partial = false, want true")
+	}
+	if len(resp.Orders) != 2 {
+		t.Errorf("orders = %d, want 2", len(resp.Orders))
+	}
+	// Weiterblättern ab der letzten gelesenen Order
+	if resp.NextPageToken != "b" {
+		t.Errorf("NextPageToken = %q, want %q", resp.NextPageToken, "b")
+	}
+}
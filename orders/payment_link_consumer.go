@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/common/telemetry"
+)
+
+// paymentLinkConsumer: Konsumiert "order.payment_link" → Payment Link nachträglich speichern
+// Warum?
+// → Payments ruft normalerweise UpdateOrder per gRPC
+// → War Orders dabei nicht erreichbar, kommt der Link stattdessen als Event
+// → Stripe Session existiert schon → Link darf nicht verloren gehen
+type paymentLinkConsumer struct {
+	store   OrdersStore
+	dedup   *broker.Deduplicator
+	metrics *metrics.ConsumerMetrics // Processing Dauer + Outcome, nil = aus
+	logger  *slog.Logger
+}
+
+func NewPaymentLinkConsumer(store OrdersStore, dedup *broker.Deduplicator, consumerMetrics *metrics.ConsumerMetrics, logger *slog.Logger) *paymentLinkConsumer {
+	return &paymentLinkConsumer{
+		store:   store,
+		dedup:   dedup,
+		metrics: consumerMetrics,
+		logger:  logger,
+	}
+}
+
+// Listen: Blockiert solange der Delivery Channel offen ist
+func (c *paymentLinkConsumer) Listen(ch broker.Channel) {
+	q, err := ch.QueueDeclare(
+		broker.OrderPaymentLinkEvent, // queue name: "order.payment_link"
+		true,                         // durable
+		false,                        // delete when unused
+		false,                        // exclusive
+		false,                        // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange": broker.DLX, // → order.payment_link.dlq
+		},
+	)
+	if err != nil {
+		c.logger.Error("failed to declare queue", slog.Any("error", err))
+		return
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		c.logger.Error("failed to start consuming", slog.Any("error", err))
+		return
+	}
+
+	c.logger.Info("payment link consumer started",
+		slog.String("queue", broker.OrderPaymentLinkEvent),
+	)
+
+	// broker.Process: Panic → DLQ statt Crash der Goroutine, Dauer + Outcome in der Metrik
+	for d := range msgs {
+		broker.Process(c.metrics, ch, &d, broker.OrderPaymentLinkEvent, func() broker.Outcome {
+			return c.handle(ch, d)
+		})
+	}
+}
+
+// handle: Verarbeitet EINE order.payment_link Delivery (Ack/Nack passiert hier drin)
+func (c *paymentLinkConsumer) handle(ch broker.Channel, d amqp.Delivery) broker.Outcome {
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+	ctx, span := otel.Tracer("orders").Start(ctx, "AMQP - consume - "+broker.OrderPaymentLinkEvent)
+	telemetry.TagSpan(ctx)
+
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderPaymentLinkEvent) {
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+	if c.dedup.Duplicate(&d) {
+		span.End()
+		return broker.OutcomeDuplicate
+	}
+
+	o := &pb.Order{}
+	if err := json.Unmarshal(d.Body, o); err != nil || o.Id == "" || o.PaymentLink == "" {
+		// Kaputtes Event wird durch Retry nicht besser → direkt in die DLQ
+		c.logger.Error("invalid payment link event", slog.Any("error", err))
+		d.Nack(false, false)
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	if err := c.apply(ctx, o); err != nil {
+		c.logger.Error("failed to apply payment link",
+			slog.String("order_id", o.Id),
+			slog.Any("error", err),
+		)
+		if err := broker.HandleRetry(ch, &d, broker.OrderPaymentLinkEvent, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End()
+		return broker.OutcomeRetry
+	}
+
+	d.Ack(false)
+	c.dedup.Done(&d)
+	span.End()
+	return broker.OutcomeSuccess
+}
+
+// apply: Link + "waiting_payment" setzen, außer die Order ist schon weiter
+// Warum Compare-and-Set statt Get + Update?
+// → Event kann NACH dem Webhook ankommen (Kunde hat schnell bezahlt)
+// → Zwischen Get und Update kann "paid"/"cancelled" gesetzt werden → Update würde es mit "waiting_payment" überschreiben
+func (c *paymentLinkConsumer) apply(ctx context.Context, o *pb.Order) error {
+	changed, err := c.store.UpdateStatusIf(ctx, o.Id,
+		[]string{orderstatus.StatusPending, orderstatus.StatusWaitingPayment},
+		orderstatus.StatusWaitingPayment,
+	)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		c.logger.Info("order already past payment link, skipping event",
+			slog.String("order_id", o.Id),
+		)
+		return nil
+	}
+
+	// Status steht schon → nur Link + Ablauf, OHNE Status (überschreibt keinen späteren Wechsel)
+	if err := c.store.Update(ctx, o.Id, &pb.Order{
+		PaymentLink:          o.PaymentLink,
+		PaymentLinkExpiresAt: o.PaymentLinkExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	c.logger.Info("payment link applied from event",
+		slog.String("order_id", o.Id),
+	)
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	orderstatus "github.com/timour/order-microservices/common/order"
+)
+
+// fakeOrdersStore: OrdersStore im Speicher, nur UpdateStatusIf + Update werden vom paymentLinkConsumer genutzt
+type fakeOrdersStore struct {
+	OrdersStore
+
+	mu     sync.Mutex
+	orders map[string]*pb.Order
+}
+
+func (s *fakeOrdersStore) UpdateStatusIf(_ context.Context, id string, from []string, to string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[id]
+	if !ok || !slices.Contains(from, o.Status) {
+		return false, nil
+	}
+	o.Status = to
+	return true, nil
+}
+
+// Update: Wie der echte Store → nur nicht-leere Felder
+func (s *fakeOrdersStore) Update(_ context.Context, id string, o *pb.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o.Status != "" {
+		s.orders[id].Status = o.Status
+	}
+	if o.PaymentLink != "" {
+		s.orders[id].PaymentLink = o.PaymentLink
+	}
+	return nil
+}
+
+func (s *fakeOrdersStore) order(id string) *pb.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &pb.Order{Id: id, Status: s.orders[id].Status, PaymentLink: s.orders[id].PaymentLink}
+}
+
+// applyPaymentLinkEvent: Consumer starten, EIN order.payment_link Event publizieren, auf das Ack warten
+func applyPaymentLinkEvent(t *testing.T, store *fakeOrdersStore, orderID string) {
+	t.Helper()
+
+	b := brokertest.New()
+	c := NewPaymentLinkConsumer(store, broker.NewDeduplicator(100, time.Minute), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	go c.Listen(b)
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForQueue(broker.OrderPaymentLinkEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(&pb.Order{Id: orderID, Status: orderstatus.StatusWaitingPayment, PaymentLink: "https://pay/" + orderID})
+	if err := b.PublishWithContext(context.Background(), "", broker.OrderPaymentLinkEvent, false, false, amqp.Publishing{MessageId: "https://pay/" + orderID, Body: body}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPaymentLinkConsumerAppliesLink(t *testing.T) {
+	store := &fakeOrdersStore{orders: map[string]*pb.Order{"o1": {Id: "o1", Status: orderstatus.StatusPending}}}
+
+	applyPaymentLinkEvent(t, store, "o1")
+
+	if got := store.order("o1"); got.Status != orderstatus.StatusWaitingPayment || got.PaymentLink != "https://pay/o1" {
+		t.Fatalf("order = %+v; want waiting_payment with the event's link", got)
+	}
+}
+
+// Webhook/Storno war schneller als das Event → Status bleibt, kein Link, Event wird trotzdem geackt
+func TestPaymentLinkConsumerSkipsClosedOrder(t *testing.T) {
+	for _, status := range []string{orderstatus.StatusPaid, orderstatus.StatusCancelled, orderstatus.StatusExpired} {
+		t.Run(status, func(t *testing.T) {
+			store := &fakeOrdersStore{orders: map[string]*pb.Order{"o1": {Id: "o1", Status: status}}}
+
+			applyPaymentLinkEvent(t, store, "o1")
+
+			if got := store.order("o1"); got.Status != status || got.PaymentLink != "" {
+				t.Fatalf("order = %+v; want untouched %s order", got, status)
+			}
+		})
+	}
+}
+
+// panickingOrdersStore: UpdateStatusIf auf "ghost" panict → simuliert einen Bug im Handler
+type panickingOrdersStore struct {
+	*fakeOrdersStore
+}
+
+func (s panickingOrdersStore) UpdateStatusIf(ctx context.Context, id string, from []string, to string) (bool, error) {
+	if id == "ghost" {
+		panic("boom")
+	}
+	return s.fakeOrdersStore.UpdateStatusIf(ctx, id, from, to)
+}
+
+// Panic im Handler → Message in die DLQ (broker.Process), die Goroutine verarbeitet das nächste Event
+func TestPaymentLinkConsumerSurvivesHandlerPanic(t *testing.T) {
+	store := &fakeOrdersStore{orders: map[string]*pb.Order{"o1": {Id: "o1", Status: orderstatus.StatusPending}}}
+	b := brokertest.New()
+	go NewPaymentLinkConsumer(panickingOrdersStore{store}, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Listen(b)
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForQueue(broker.OrderPaymentLinkEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"ghost", "o1"} {
+		body, _ := json.Marshal(&pb.Order{Id: id, PaymentLink: "https://pay/" + id})
+		if err := b.PublishWithContext(context.Background(), "", broker.OrderPaymentLinkEvent, false, false, amqp.Publishing{Body: body}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.WaitForAcks(2, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := store.order("o1"); got.PaymentLink != "https://pay/o1" {
+		t.Errorf("o1 = %+v; want the link applied after the panic", got)
+	}
+	var deadLettered int
+	for _, m := range b.Published() {
+		if m.Exchange == broker.DLX && m.RoutingKey == broker.OrderPaymentLinkEvent {
+			deadLettered++
+		}
+	}
+	if deadLettered != 1 {
+		t.Errorf("dead-lettered = %d; want 1", deadLettered)
+	}
+}
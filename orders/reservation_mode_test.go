@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/config"
+	"github.com/timour/order-microservices/common/discovery/inmem"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// fakeStockServer: Alles auf Lager außer outOfStock, zählt die ReserveStock Calls
+// → reserveErr: Check sagt "auf Lager", Reserve scheitert trotzdem (Stock Cache veraltet)
+type fakeStockServer struct {
+	pb.UnimplementedStockServiceServer
+	reservations atomic.Int32
+	releases     atomic.Int32
+	outOfStock   map[string]bool // Item ID → ausverkauft
+	reserveErr   error
+}
+
+func (s *fakeStockServer) CheckIfItemIsInStock(_ context.Context, req *pb.CheckIfItemIsInStockRequest) (*pb.CheckIfItemIsInStockResponse, error) {
+	items := make([]*pb.Item, 0, len(req.Items))
+	var missing []*pb.OutOfStockItem
+	for _, item := range req.Items {
+		items = append(items, &pb.Item{ID: item.ID, Name: "Item " + item.ID, PriceID: "price_" + item.ID})
+		if s.outOfStock[item.ID] {
+			missing = append(missing, &pb.OutOfStockItem{ItemID: item.ID, Requested: item.Quantity})
+		}
+	}
+	return &pb.CheckIfItemIsInStockResponse{InStock: len(missing) == 0, Items: items, OutOfStockItems: missing}, nil
+}
+
+func (s *fakeStockServer) ReserveStock(context.Context, *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	s.reservations.Add(1)
+	if s.reserveErr != nil {
+		return nil, s.reserveErr
+	}
+	return &pb.ReserveStockResponse{ReservationID: "res-1", ExpiresAt: "2026-01-01T12:15:00Z"}, nil
+}
+
+func (s *fakeStockServer) ReleaseReservation(context.Context, *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	s.releases.Add(1)
+	return &pb.ReleaseReservationResponse{}, nil
+}
+
+// createStore: OrdersStore, nur Create + Update werden von CreateOrder genutzt
+// → Create setzt die Zeitstempel wie der Mongo Store
+type createStore struct {
+	OrdersStore
+}
+
+const createStoreCreatedAt = "2026-01-01T12:00:00.123Z"
+
+func (createStore) Create(_ context.Context, o *pb.Order) (primitive.ObjectID, error) {
+	o.CreatedAt = createStoreCreatedAt
+	o.UpdatedAt = createStoreCreatedAt
+	return primitive.NewObjectID(), nil
+}
+
+func (createStore) Update(context.Context, string, *pb.Order) error { return nil }
+
+// noopService: OrdersService.CreateOrder ohne Logik
+type noopService struct {
+	OrdersService
+}
+
+func (noopService) CreateOrder(context.Context) error { return nil }
+
+// newCreateOrderTestHandler: Handler mit echtem gRPC Stock Fake hinter einer In-Memory Registry
+func newCreateOrderTestHandler(t *testing.T, mode config.ReservationMode) (*grpcHandler, *fakeStockServer) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stock := &fakeStockServer{}
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	pb.RegisterStockServiceServer(srv, stock)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	registry := inmem.NewRegistry()
+	if err := registry.Register(context.Background(), "stock-1", "stock", lis.Addr().String()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	return &grpcHandler{
+		service:  noopService{},
+		store:    createStore{},
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		registry: registry,
+
+		reservationMode: mode,
+	}, stock
+}
+
+// on_create reserviert sofort (mit Countdown), on_pay nur Availability Check → Payments reserviert später
+func TestCreateOrderReservationMode(t *testing.T) {
+	tests := []struct {
+		mode             config.ReservationMode
+		wantReservations int32
+		wantExpiresAt    string
+	}{
+		{config.ReservationOnCreate, 1, "2026-01-01T12:15:00Z"},
+		{config.ReservationOnPay, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			h, stock := newCreateOrderTestHandler(t, tt.mode)
+
+			order, err := h.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+				CustomerId: "c1",
+				Items:      []*pb.ItemsWithQuantity{{ID: "1", Quantity: 2}},
+			})
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			if n := stock.reservations.Load(); n != tt.wantReservations {
+				t.Errorf("ReserveStock calls = %d; want %d", n, tt.wantReservations)
+			}
+			if order.ReservationExpiresAt != tt.wantExpiresAt {
+				t.Errorf("ReservationExpiresAt = %q; want %q", order.ReservationExpiresAt, tt.wantExpiresAt)
+			}
+		})
+	}
+}
+
+// Ausverkauft → Order abgelehnt, Counter zählt pro fehlendem Item (nicht pro Order Zeile auf Lager)
+func TestCreateOrderCountsStockoutRejection(t *testing.T) {
+	h, stock := newCreateOrderTestHandler(t, config.ReservationOnCreate)
+	stock.outOfStock = map[string]bool{"2": true, "3": true}
+	h.metrics = metrics.NewBusinessMetrics("orders_stockout_test")
+
+	_, err := h.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+		CustomerId: "c1",
+		Items: []*pb.ItemsWithQuantity{
+			{ID: "1", Quantity: 1},
+			{ID: "2", Quantity: 5},
+			{ID: "3", Quantity: 1},
+		},
+	})
+	if err == nil {
+		t.Fatal("CreateOrder succeeded; want out of stock error")
+	}
+	if n := stock.reservations.Load(); n != 0 {
+		t.Errorf("ReserveStock calls = %d; want 0", n)
+	}
+
+	for item, want := range map[string]float64{"1": 0, "2": 1, "3": 1} {
+		if got := testutil.ToFloat64(h.metrics.OrdersRejectedStockout.WithLabelValues(item)); got != want {
+			t.Errorf("orders_rejected_stockout_total{item_id=%q} = %v; want %v", item, got, want)
+		}
+	}
+
+	// Erfolgreiche Order zählt nicht
+	stock.outOfStock = nil
+	if _, err := h.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+		CustomerId: "c1",
+		Items:      []*pb.ItemsWithQuantity{{ID: "2", Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("CreateOrder in stock: %v", err)
+	}
+	if got := testutil.ToFloat64(h.metrics.OrdersRejectedStockout.WithLabelValues("2")); got != 1 {
+		t.Errorf("orders_rejected_stockout_total{item_id=\"2\"} = %v after a successful order; want 1", got)
+	}
+}
+
+// Gespeicherte Order zählt, abgelehnte (ausverkauft) nicht
+func TestCreateOrderRecordsOrderCreated(t *testing.T) {
+	h, stock := newCreateOrderTestHandler(t, config.ReservationOnCreate)
+	h.metrics = metrics.NewBusinessMetrics("orders_created_test")
+	req := &pb.CreateOrderRequest{CustomerId: "c1", Items: []*pb.ItemsWithQuantity{{ID: "1", Quantity: 1}}}
+
+	order, err := h.CreateOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	// Gespeicherter Zeitstempel (UTC, Millisekunden) statt ObjectID Sekunden in lokaler Zeit
+	if order.CreatedAt != createStoreCreatedAt {
+		t.Errorf("CreatedAt = %q; want the stored %q", order.CreatedAt, createStoreCreatedAt)
+	}
+	stock.outOfStock = map[string]bool{"1": true}
+	if _, err := h.CreateOrder(context.Background(), req); err == nil {
+		t.Fatal("CreateOrder succeeded; want out of stock error")
+	}
+
+	if got := testutil.ToFloat64(h.metrics.OrdersCreated); got != 1 {
+		t.Errorf("orders created = %v; want 1", got)
+	}
+}
+
+// updateRecorder: createStore, merkt sich jedes Update (welche Felder an die Order geschrieben werden)
+type updateRecorder struct {
+	createStore
+	mu      sync.Mutex
+	updates []*pb.Order
+}
+
+func (s *updateRecorder) Update(_ context.Context, _ string, o *pb.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, o)
+	return nil
+}
+
+// on_create: Reservation ID aus ReserveStock landet an der gespeicherten UND der zurückgegebenen Order
+func TestCreateOrderStoresReservationID(t *testing.T) {
+	h, _ := newCreateOrderTestHandler(t, config.ReservationOnCreate)
+	store := &updateRecorder{}
+	h.store = store
+
+	order, err := h.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+		CustomerId: "c1",
+		Items:      []*pb.ItemsWithQuantity{{ID: "1", Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if order.ReservationId != "res-1" {
+		t.Errorf("returned ReservationId = %q; want res-1", order.ReservationId)
+	}
+
+	var stored bool
+	for _, u := range store.updates {
+		if u.ReservationId == "res-1" {
+			stored = true
+		}
+	}
+	if !stored {
+		t.Fatalf("updates = %v; want one storing ReservationId res-1", store.updates)
+	}
+}
+
+// abandonRecorder: createStore, merkt sich welche Orders per Abandon aufgeräumt wurden
+type abandonRecorder struct {
+	createStore
+	mu        sync.Mutex
+	abandoned []string
+}
+
+func (s *abandonRecorder) Abandon(_ context.Context, orderID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.abandoned = append(s.abandoned, orderID)
+	return true, nil
+}
+
+// Check aus dem (veralteten) Cache sagt "auf Lager", Reserve scheitert → 409 "please retry" statt 500,
+// Reservation freigegeben und Order abgeräumt
+func TestCreateOrderStaleStockCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		reserveErr error
+		want       codes.Code
+	}{
+		{"insufficient stock", status.Error(codes.FailedPrecondition, "insufficient stock for item 1"), codes.Aborted},
+		{"concurrent update", status.Error(codes.Aborted, "concurrent stock update"), codes.Aborted},
+		{"stock down", status.Error(codes.Unavailable, "connection refused"), codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, stock := newCreateOrderTestHandler(t, config.ReservationOnCreate)
+			stock.reserveErr = tt.reserveErr
+			store := &abandonRecorder{}
+			h.store = store
+
+			_, err := h.CreateOrder(context.Background(), &pb.CreateOrderRequest{
+				CustomerId: "c1",
+				Items:      []*pb.ItemsWithQuantity{{ID: "1", Quantity: 2}},
+			})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("CreateOrder = %v; want %s", err, tt.want)
+			}
+			if n := stock.releases.Load(); n != 1 {
+				t.Errorf("ReleaseReservation calls = %d; want 1 (no lingering partial reservation)", n)
+			}
+			if len(store.abandoned) != 1 {
+				t.Errorf("abandoned = %v; want the new order", store.abandoned)
+			}
+		})
+	}
+}
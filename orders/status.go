@@ -0,0 +1,52 @@
+package main
+
+import (
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validateCancellation: "cancelled" selbst ist erlaubt → CancelOrder ist idempotent
+func validateCancellation(current string) error {
+	if current == orderstatus.StatusCancelled || orderstatus.IsCancellable(current) {
+		return nil
+	}
+	return status.Errorf(codes.FailedPrecondition, "order is %q and can no longer be cancelled", current)
+}
+
+// validateUpdateTarget: "cancelled"/"expired" NICHT über UpdateOrder
+// Warum?
+// → UpdateOrder schreibt nur den Status: keine Reservation Freigabe, kein order.cancelled → KEIN Refund
+// → Bezahlte Order per PUT storniert = Geld weg, Stock blockiert
+// → Storno nur über CancelOrder (cancel()), Ablauf nur über den orderExpirer
+func validateUpdateTarget(to string) error {
+	switch to {
+	case orderstatus.StatusCancelled:
+		return status.Error(codes.FailedPrecondition, "orders cannot be cancelled via UpdateOrder, use CancelOrder")
+	case orderstatus.StatusExpired:
+		return status.Error(codes.FailedPrecondition, "orders expire automatically and cannot be set to expired via UpdateOrder")
+	}
+	return nil
+}
+
+// validateStatusTransition: Prüft ob der Wechsel from → to erlaubt ist (order.Transitions)
+// Warum codes.FailedPrecondition?
+// → Request ist gültig, aber die Order ist im falschen Zustand
+// → Client (Kitchen/Gateway) kann das von "Order not found" unterscheiden
+// Unbekannter Ziel-Status (Tippfehler) → InvalidArgument: Kein Zustand macht den Request gültig
+func validateStatusTransition(from, to string) error {
+	if !orderstatus.IsValid(to) {
+		return status.Errorf(codes.InvalidArgument, "unknown order status %q", to)
+	}
+
+	if err := validateUpdateTarget(to); err != nil {
+		return err
+	}
+
+	if !orderstatus.CanTransition(from, to) {
+		return status.Errorf(codes.FailedPrecondition,
+			"invalid status transition %q → %q", from, to)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidateStatusTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     codes.Code
+	}{
+		{"paid to preparing", orderstatus.StatusPaid, orderstatus.StatusPreparing, codes.OK},
+		{"same status is a no-op", orderstatus.StatusPaid, orderstatus.StatusPaid, codes.OK},
+		{"preparing to completed skips ready", orderstatus.StatusPreparing, orderstatus.StatusCompleted, codes.FailedPrecondition},
+		{"unknown target", orderstatus.StatusPaid, "preparng", codes.InvalidArgument},
+		// Storno/Ablauf nur über CancelOrder bzw. den orderExpirer (Refund + Reservation Freigabe)
+		{"paid to cancelled", orderstatus.StatusPaid, orderstatus.StatusCancelled, codes.FailedPrecondition},
+		{"pending to cancelled", orderstatus.StatusPending, orderstatus.StatusCancelled, codes.FailedPrecondition},
+		{"waiting_payment to expired", orderstatus.StatusWaitingPayment, orderstatus.StatusExpired, codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStatusTransition(tt.from, tt.to)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("validateStatusTransition(%q, %q) = %v; want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCancellation(t *testing.T) {
+	for _, s := range []string{orderstatus.StatusPending, orderstatus.StatusWaitingPayment, orderstatus.StatusPaid, orderstatus.StatusCancelled} {
+		if err := validateCancellation(s); err != nil {
+			t.Errorf("validateCancellation(%q) = %v; want nil", s, err)
+		}
+	}
+	for _, s := range []string{orderstatus.StatusPreparing, orderstatus.StatusReady, orderstatus.StatusCompleted, orderstatus.StatusExpired} {
+		if status.Code(validateCancellation(s)) != codes.FailedPrecondition {
+			t.Errorf("validateCancellation(%q) should be FailedPrecondition", s)
+		}
+	}
+}
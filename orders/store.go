@@ -3,17 +3,27 @@ package main
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/timour/order-microservices/common/api"
+	orderstatus "github.com/timour/order-microservices/common/order"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
 	ErrOrderNotFound = errors.New("order not found")
+	// ErrDuplicateOrder: Order mit gleichem (customerID, idempotencyKey) existiert schon
+	// → Create gibt trotzdem die ID der BESTEHENDEN Order zurück
+	ErrDuplicateOrder = errors.New("order with this idempotency key already exists")
 )
 
+// idempotencyIndexName: Unique Index über (customerID, idempotencyKey)
+const idempotencyIndexName = "customer_idempotency_key"
+
+
 type store struct {
 	collection *mongo.Collection
 }
@@ -29,21 +39,95 @@ func NewStore(client *mongo.Client) *store {
 func (s *store) Create(ctx context.Context, order *api.Order) (primitive.ObjectID, error) {
 	// Let MongoDB generate unique _id - no custom "id" field!
 	// This is the senior's approach for guaranteed uniqueness
+	// Warum createdAt/updatedAt zusätzlich zum ObjectID Timestamp?
+	// → ObjectID hat nur Sekunden-Auflösung und hängt am ID Format (Migration der IDs = Zeitpunkte weg)
+	// → BSON Date (Millisekunden) → präzise Zeitstempel im UI + Range Queries auf ein echtes Feld
+	// Truncate → order.CreatedAt ist exakt der Wert den GetOrder später aus Mongo liest
+	now := time.Now().Truncate(time.Millisecond)
 	doc := bson.M{
-		"customerID":   order.CustomerId,
-		"status":       order.Status,
-		"items":        order.Items,
-		"paymentLink":  order.PaymentLink,
+		"customerID":    order.CustomerId,
+		"status":        order.Status,
+		"items":         toOrderItems(order.Items),
+		"paymentLink":   order.PaymentLink,
+		"sessionID":     order.SessionId,
+		"stripeAccount": order.StripeAccount,
+		"createdAt":     now,
+		"updatedAt":     now,
+	}
+	// Warum nur wenn gesetzt?
+	// → Partial Index greift nur auf Dokumente MIT Key → Orders ohne Key kollidieren nie
+	if order.IdempotencyKey != "" {
+		doc["idempotencyKey"] = order.IdempotencyKey
 	}
 	result, err := s.collection.InsertOne(ctx, doc)
 	if err != nil {
+		if order.IdempotencyKey != "" && mongo.IsDuplicateKeyError(err) {
+			return s.existingOrderID(ctx, order.CustomerId, order.IdempotencyKey)
+		}
 		return primitive.NilObjectID, err
 	}
 
+	// Gespeicherte Zeitstempel zurück an den Caller → CreateOrder Response = GetOrder
+	order.CreatedAt = formatTimestamp(now)
+	order.UpdatedAt = formatTimestamp(now)
+
 	// Return the MongoDB-generated _id
 	return result.InsertedID.(primitive.ObjectID), nil
 }
 
+// existingOrderID: ID der Order die den Unique Index schon belegt → (ID, ErrDuplicateOrder)
+func (s *store) existingOrderID(ctx context.Context, customerID, idempotencyKey string) (primitive.ObjectID, error) {
+	var existing struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err := s.collection.FindOne(ctx, bson.M{
+		"customerID":     customerID,
+		"idempotencyKey": idempotencyKey,
+	}).Decode(&existing)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return existing.ID, ErrDuplicateOrder
+}
+
+// EnsureIndexes: Legt den Unique Index für Order De-Duplication an (idempotent)
+// Warum im Store und nicht nur im Gateway?
+// → Zwei Gateway Instanzen bekommen denselben Retry gleichzeitig → beide prüfen "gibt es nicht" → 2 Orders
+// → Unique Index = letzte Verteidigungslinie, MongoDB entscheidet atomar
+func (s *store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "customerID", Value: 1},
+			{Key: "idempotencyKey", Value: 1},
+		},
+		Options: options.Index().
+			SetName(idempotencyIndexName).
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"idempotencyKey": bson.M{"$type": "string"}}),
+	})
+	return err
+}
+
+// BackfillCreatedAt: Setzt createdAt aus dem ObjectID Timestamp für Dokumente ohne createdAt (idempotent)
+// Warum?
+// → Range Queries und Lifecycle Metriken brauchen ein echtes createdAt Feld
+// → Historische Orders haben nur den Zeitpunkt im _id
+// Warum Pipeline Update ($toDate auf _id)?
+// → EIN UpdateMany statt jedes Dokument lesen + zurückschreiben, MongoDB rechnet selbst
+// → Filter auf "fehlt" → zweiter Lauf findet nichts mehr und fasst nichts an
+func (s *store) BackfillCreatedAt(ctx context.Context) (int64, error) {
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{"createdAt": bson.M{"$exists": false}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"createdAt": bson.M{"$toDate": "$_id"}}}},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
 func (s *store) Update(ctx context.Context, orderID string, order *api.Order) error {
 	// Convert hex string to ObjectID - senior's approach
 	oID, err := primitive.ObjectIDFromHex(orderID)
@@ -60,10 +144,20 @@ func (s *store) Update(ctx context.Context, orderID string, order *api.Order) er
 	if order.PaymentLink != "" {
 		update["paymentLink"] = order.PaymentLink
 	}
+	if order.PaymentLinkExpiresAt != "" {
+		update["paymentLinkExpiresAt"] = order.PaymentLinkExpiresAt
+	}
+	if order.ReservationExpiresAt != "" {
+		update["reservationExpiresAt"] = order.ReservationExpiresAt
+	}
+	if order.ReservationId != "" {
+		update["reservationID"] = order.ReservationId
+	}
 
 	if len(update) == 0 {
 		return nil // Nothing to update
 	}
+	update["updatedAt"] = time.Now()
 
 	// Filter by _id (always unique!) instead of custom "id" field
 	filter := bson.M{"_id": oID}
@@ -79,6 +173,266 @@ func (s *store) Update(ctx context.Context, orderID string, order *api.Order) er
 	return nil
 }
 
+// UpdateStatusIf: Setzt status NUR wenn die Order noch in einem der from Status ist (Compare-and-Set)
+// Warum nicht Get + Update?
+// → Zwischen Lesen und Schreiben kann order.paid ankommen → "paid" würde mit "expired" überschrieben
+// Returns: false wenn die Order existiert, aber den Status inzwischen gewechselt hat
+func (s *store) UpdateStatusIf(ctx context.Context, orderID string, from []string, to string) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": oID, "status": bson.M{"$in": from}},
+		bson.M{"$set": bson.M{"status": to, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// Abandon: "pending" → "cancelled" und Idempotency Key entfernen (CreateOrder ist fehlgeschlagen)
+// Warum den Key entfernen?
+// → Unique Index (customerID, idempotencyKey) → Retry mit demselben Key bekäme sonst diese stornierte Order zurück
+// → Dokument bleibt für Audit/Debugging erhalten
+// Returns: false wenn die Order nicht mehr "pending" ist
+func (s *store) Abandon(ctx context.Context, orderID string) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": oID, "status": orderstatus.StatusPending},
+		bson.M{
+			"$set":   bson.M{"status": orderstatus.StatusCancelled, "updatedAt": time.Now()},
+			"$unset": bson.M{"idempotencyKey": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// deletedAtKey: Soft-Delete Zeitpunkt → normale Listen filtern auf {deletedAtKey: nil}
+// Warum nil statt {"$exists": false}?
+// → {"deletedAt": nil} matcht fehlendes Feld UND explizites null → robust gegen beide Schreibweisen
+const deletedAtKey = "deletedAt"
+
+// SoftDelete: deletedAt setzen (Order verschwindet aus GetByStatus/GetByCustomer/GetBySession)
+// Warum kein DeleteOne?
+// → Audit/Retention: Dokument bleibt erhalten, RestoreOrder kann es zurückholen
+// Returns: false wenn die Order schon gelöscht ist (oder nicht existiert)
+func (s *store) SoftDelete(ctx context.Context, orderID string) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": oID, deletedAtKey: nil},
+		bson.M{"$set": bson.M{deletedAtKey: now, "updatedAt": now}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// Restore: deletedAt entfernen → Order taucht wieder in den Listen auf
+// Returns: false wenn die Order nicht gelöscht ist (oder nicht existiert)
+func (s *store) Restore(ctx context.Context, orderID string) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": oID, deletedAtKey: bson.M{"$ne": nil}},
+		bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{deletedAtKey: ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// pendingAdjustmentsKey: Outbox für order.items_adjusted → Einträge bis das Event publiziert ist
+const pendingAdjustmentsKey = "pendingAdjustments"
+
+// pendingAdjustment: Gespeichertes order.items_adjusted Event (Outbox Eintrag)
+type pendingAdjustment struct {
+	AdjustmentID string      `bson:"adjustmentID"`
+	CustomerID   string      `bson:"customerID"`
+	RemovedItems []orderItem `bson:"removedItems"`
+	CreatedAt    time.Time   `bson:"createdAt,omitempty"` // Relay publiziert erst nach adjustmentRelayMinAge
+}
+
+// event: Outbox Eintrag → order.items_adjusted Event
+func (p pendingAdjustment) event(orderID string) *api.OrderItemsAdjusted {
+	removed := make([]*api.Item, 0, len(p.RemovedItems))
+	for _, item := range p.RemovedItems {
+		removed = append(removed, &api.Item{ID: item.ID, Name: item.Name, Quantity: item.Quantity, PriceID: item.PriceID})
+	}
+	return &api.OrderItemsAdjusted{
+		AdjustmentId: p.AdjustmentID,
+		OrderId:      orderID,
+		CustomerId:   p.CustomerID,
+		RemovedItems: removed,
+	}
+}
+
+// AdjustItems: Ersetzt die Items NUR wenn die Order noch "paid" ist und noch genau previous enthält (Compare-and-Set)
+// + legt das order.items_adjusted Event im selben Update als Outbox Eintrag ab
+// Warum nicht einfach $set?
+// → Zwei parallele Anpassungen lesen dieselben Items → beide würden zurückbuchen und erstatten
+// → Kitchen setzt zwischendurch "preparing" → Essen wird gekocht, Anpassung darf nicht mehr greifen
+// Warum Event im selben Update?
+// → Items geändert + Event verloren = Kunde bekommt nie sein Geld → Outbox überlebt einen fehlgeschlagenen Publish
+// Returns: false wenn sich Status oder Items inzwischen geändert haben
+func (s *store) AdjustItems(ctx context.Context, orderID string, previous, remaining []*api.Item, adjustment *api.OrderItemsAdjusted) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": oID, "status": orderstatus.StatusPaid, "items": toOrderItems(previous)},
+		bson.M{
+			"$set": bson.M{"items": toOrderItems(remaining), "updatedAt": time.Now()},
+			"$push": bson.M{pendingAdjustmentsKey: pendingAdjustment{
+				AdjustmentID: adjustment.AdjustmentId,
+				CustomerID:   adjustment.CustomerId,
+				RemovedItems: toOrderItems(adjustment.RemovedItems),
+				CreatedAt:    time.Now(),
+			}},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// RevertAdjustment: AdjustItems rückgängig machen (Restock fehlgeschlagen → nichts zu erstatten)
+// → Nur solange die Items noch remaining sind, Outbox Eintrag wird entfernt
+func (s *store) RevertAdjustment(ctx context.Context, orderID, adjustmentID string, previous, remaining []*api.Item) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": oID, "items": toOrderItems(remaining)},
+		bson.M{
+			"$set":  bson.M{"items": toOrderItems(previous), "updatedAt": time.Now()},
+			"$pull": bson.M{pendingAdjustmentsKey: bson.M{"adjustmentID": adjustmentID}},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// PendingAdjustments: Noch nicht publizierte order.items_adjusted Events der Order (älteste zuerst)
+func (s *store) PendingAdjustments(ctx context.Context, orderID string) ([]*api.OrderItemsAdjusted, error) {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Pending []pendingAdjustment `bson:"pendingAdjustments"`
+	}
+	err = s.collection.FindOne(ctx, bson.M{"_id": oID},
+		options.FindOne().SetProjection(bson.M{pendingAdjustmentsKey: 1}),
+	).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	adjustments := make([]*api.OrderItemsAdjusted, 0, len(doc.Pending))
+	for _, p := range doc.Pending {
+		adjustments = append(adjustments, p.event(orderID))
+	}
+	return adjustments, nil
+}
+
+// StaleAdjustments: Outbox Einträge aller Orders, die vor createdBefore angelegt wurden (höchstens limit Orders)
+// Warum nur ältere?
+// → Frische Einträge gehören noch zum laufenden AdjustOrderItems Call → Restock kann scheitern und sie zurückrollen
+// → Einträge ohne createdAt (vor dem Relay angelegt) gelten als alt
+func (s *store) StaleAdjustments(ctx context.Context, createdBefore time.Time, limit int64) ([]*api.OrderItemsAdjusted, error) {
+	stale := bson.M{"$or": bson.A{
+		bson.M{"createdAt": bson.M{"$lt": createdBefore}},
+		bson.M{"createdAt": bson.M{"$exists": false}},
+	}}
+	cursor, err := s.collection.Find(ctx,
+		bson.M{pendingAdjustmentsKey: bson.M{"$elemMatch": stale}},
+		options.Find().
+			SetProjection(bson.M{pendingAdjustmentsKey: 1}).
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var adjustments []*api.OrderItemsAdjusted
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID      primitive.ObjectID  `bson:"_id"`
+			Pending []pendingAdjustment `bson:"pendingAdjustments"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		for _, p := range doc.Pending {
+			if p.CreatedAt.IsZero() || p.CreatedAt.Before(createdBefore) {
+				adjustments = append(adjustments, p.event(doc.ID.Hex()))
+			}
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
+
+// CompleteAdjustment: Event ist publiziert → Outbox Eintrag entfernen (idempotent)
+func (s *store) CompleteAdjustment(ctx context.Context, orderID, adjustmentID string) error {
+	oID, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.UpdateOne(ctx,
+		bson.M{"_id": oID},
+		bson.M{"$pull": bson.M{pendingAdjustmentsKey: bson.M{"adjustmentID": adjustmentID}}},
+	)
+	return err
+}
+
 func (s *store) Get(ctx context.Context, orderID string) (*api.Order, error) {
 	// Convert hex string to ObjectID
 	oID, err := primitive.ObjectIDFromHex(orderID)
@@ -97,93 +451,191 @@ func (s *store) Get(ctx context.Context, orderID string) (*api.Order, error) {
 		return nil, err
 	}
 
-	// Extract _id and convert to hex string
-	var id string
-	var createdAt string
-	if oid, ok := doc["_id"].(primitive.ObjectID); ok {
-		id = oid.Hex()
-		createdAt = oid.Timestamp().Format("2006-01-02T15:04:05Z07:00")
+	return orderFromDoc(doc), nil
+}
+
+// Returns partial=true wenn die Deadline von ctx beim Lesen abläuft
+// Warum kein Fehler?
+// → Kitchen Display zeigt lieber die Hälfte der Orders als eine leere Seite
+// → Caller entscheidet was "langsam" heißt (context.WithTimeout)
+//
+// Pagination: Keyset auf _id statt Skip
+// → afterID = letzte _id der vorherigen Seite → "_id > afterID" nutzt den Index, Skip müsste alles davor lesen
+// → Sortierung auf _id ist stabil → neue Orders verschieben keine bereits gelieferten Seiten
+func (s *store) GetByStatus(ctx context.Context, status string, afterID primitive.ObjectID, limit int64) ([]*api.Order, bool, error) {
+	filter := bson.M{"status": status, deletedAtKey: nil}
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		if deadlineExceeded(ctx) {
+			return nil, true, nil
+		}
+		return nil, false, err
 	}
+	// Eigener Context → Close klappt auch wenn ctx schon abgelaufen ist
+	defer cursor.Close(context.WithoutCancel(ctx))
 
-	// Manually map to protobuf struct with correct field names
-	order := &api.Order{
-		Id:          id,
-		CustomerId:  getString(doc, "customerID"),
-		Status:      getString(doc, "status"),
-		PaymentLink: getString(doc, "paymentLink"),
-		CreatedAt:   createdAt,
+	return readOrders(ctx, cursor)
+}
+
+// orderCursor: Der Teil von *mongo.Cursor, den readOrders braucht
+// Warum ein Interface? → Tests können einen langsamen Cursor ohne MongoDB simulieren
+type orderCursor interface {
+	Next(ctx context.Context) bool
+	Decode(val interface{}) error
+	Err() error
+}
+
+// readOrders: Liest den Cursor bis zum Ende oder bis zur Deadline
+// Returns: partial=true + bisher gelesene Orders wenn ctx während des Lesens abläuft
+func readOrders(ctx context.Context, cursor orderCursor) ([]*api.Order, bool, error) {
+	var orders []*api.Order
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, false, err
+		}
+
+		orders = append(orders, orderFromDoc(doc))
 	}
 
-	// Map items if present
-	if itemsRaw, ok := doc["items"].(bson.A); ok {
-		items := make([]*api.Item, 0, len(itemsRaw))
-		for _, itemRaw := range itemsRaw {
-			if itemDoc, ok := itemRaw.(bson.M); ok {
-				items = append(items, &api.Item{
-					ID:       getString(itemDoc, "id"),
-					Name:     getString(itemDoc, "name"),
-					Quantity: getInt32(itemDoc, "quantity"),
-					PriceID:  getString(itemDoc, "priceid"),
-				})
-			}
+	if err := cursor.Err(); err != nil {
+		if deadlineExceeded(ctx) {
+			return orders, true, nil
 		}
-		order.Items = items
+		return nil, false, err
 	}
 
-	return order, nil
+	return orders, false, nil
+}
+
+// deadlineExceeded: Deadline abgelaufen (→ Teilergebnis) statt Abbruch durch den Client (→ Fehler)
+func deadlineExceeded(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
 }
 
-func (s *store) GetByStatus(ctx context.Context, status string) ([]*api.Order, error) {
-	// Query MongoDB collection for all orders with given status
-	filter := bson.M{"status": status}
-	cursor, err := s.collection.Find(ctx, filter)
+// GetBySession: Alle Orders eines Tisches/einer Session (Dine-In, gemeinsame Bezahlung)
+func (s *store) GetBySession(ctx context.Context, sessionID string) ([]*api.Order, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{"sessionID": sessionID, deletedAtKey: nil})
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	// Iterate through cursor and build order list
 	var orders []*api.Order
 	for cursor.Next(ctx) {
 		var doc bson.M
 		if err := cursor.Decode(&doc); err != nil {
 			return nil, err
 		}
+		orders = append(orders, orderFromDoc(doc))
+	}
 
-		// Extract _id and convert to hex string
-		var id string
-		var createdAt string
-		if oid, ok := doc["_id"].(primitive.ObjectID); ok {
-			id = oid.Hex()
-			createdAt = oid.Timestamp().Format("2006-01-02T15:04:05Z07:00")
-		}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
 
-		// Manually map to protobuf struct
-		order := &api.Order{
-			Id:          id,
-			CustomerId:  getString(doc, "customerID"),
-			Status:      getString(doc, "status"),
-			PaymentLink: getString(doc, "paymentLink"),
-			CreatedAt:   createdAt,
+	return orders, nil
+}
+
+// GetStuck: Orders mit einem der Status, die vor createdBefore angelegt wurden (älteste zuerst)
+// Warum _id statt createdAt Feld?
+// → ObjectID enthält den Erstellungszeitpunkt → Range Query auf _id nutzt den Default Index
+func (s *store) GetStuck(ctx context.Context, statuses []string, createdBefore time.Time) ([]*api.Order, error) {
+	filter := bson.M{
+		"status": bson.M{"$in": statuses},
+		"_id":    bson.M{"$lt": primitive.NewObjectIDFromTimestamp(createdBefore)},
+	}
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*api.Order
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
 		}
+		orders = append(orders, orderFromDoc(doc))
+	}
 
-		// Map items if present
-		if itemsRaw, ok := doc["items"].(bson.A); ok {
-			items := make([]*api.Item, 0, len(itemsRaw))
-			for _, itemRaw := range itemsRaw {
-				if itemDoc, ok := itemRaw.(bson.M); ok {
-					items = append(items, &api.Item{
-						ID:       getString(itemDoc, "id"),
-						Name:     getString(itemDoc, "name"),
-						Quantity: getInt32(itemDoc, "quantity"),
-						PriceID:  getString(itemDoc, "priceid"),
-					})
-				}
-			}
-			order.Items = items
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// GetByCustomerAndStatus: Orders eines Kunden mit einem der Status (älteste zuerst)
+func (s *store) GetByCustomerAndStatus(ctx context.Context, customerID string, statuses []string) ([]*api.Order, error) {
+	filter := bson.M{
+		"customerID": customerID,
+		"status":     bson.M{"$in": statuses},
+	}
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*api.Order
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
 		}
+		orders = append(orders, orderFromDoc(doc))
+	}
 
-		orders = append(orders, order)
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// GetByCustomer: Orders eines Kunden, neueste zuerst
+// → status "" = jeder Status, since/until Zero = offen (until exklusive)
+// Warum _id Range statt createdAt?
+// → ObjectID enthält den Erstellungszeitpunkt → funktioniert auch für Orders ohne createdAt Feld
+// → Sekundengenau (ObjectID Timestamp) → reicht für "meine Bestellungen"
+func (s *store) GetByCustomer(ctx context.Context, customerID, status string, since, until time.Time, limit int64) ([]*api.Order, error) {
+	filter := bson.M{"customerID": customerID, deletedAtKey: nil}
+	if status != "" {
+		filter["status"] = status
+	}
+	idRange := bson.M{}
+	if !since.IsZero() {
+		idRange["$gte"] = primitive.NewObjectIDFromTimestamp(since)
+	}
+	if !until.IsZero() {
+		idRange["$lt"] = primitive.NewObjectIDFromTimestamp(until)
+	}
+	if len(idRange) > 0 {
+		filter["_id"] = idRange
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit)
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []*api.Order
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		orders = append(orders, orderFromDoc(doc))
 	}
 
 	if err := cursor.Err(); err != nil {
@@ -193,6 +645,90 @@ func (s *store) GetByStatus(ctx context.Context, status string) ([]*api.Order, e
 	return orders, nil
 }
 
+// orderFromDoc: Mappt ein MongoDB Dokument auf *api.Order
+// Warum manuell statt Decode(&api.Order)?
+// → Protobuf Feldnamen ≠ MongoDB Keys ("customerID" vs CustomerId)
+// → _id ist ObjectID → Hex String + CreatedAt aus dem ObjectID Timestamp
+func orderFromDoc(doc bson.M) *api.Order {
+	var id string
+	var createdAt string
+	if oid, ok := doc["_id"].(primitive.ObjectID); ok {
+		id = oid.Hex()
+		createdAt = formatTimestamp(oid.Timestamp()) // Legacy Fallback: Sekundengenau
+	}
+	// Gespeichertes Feld gewinnt → Millisekunden statt Sekunden
+	if t, ok := getTime(doc, "createdAt"); ok {
+		createdAt = formatTimestamp(t)
+	}
+	var updatedAt string
+	if t, ok := getTime(doc, "updatedAt"); ok {
+		updatedAt = formatTimestamp(t)
+	}
+	var deletedAt string
+	if t, ok := getTime(doc, deletedAtKey); ok {
+		deletedAt = formatTimestamp(t)
+	}
+
+	order := &api.Order{
+		Id:                   id,
+		CustomerId:           getString(doc, "customerID"),
+		Status:               getString(doc, "status"),
+		PaymentLink:          getString(doc, "paymentLink"),
+		PaymentLinkExpiresAt: getString(doc, "paymentLinkExpiresAt"),
+		CreatedAt:            createdAt,
+		UpdatedAt:            updatedAt,
+		DeletedAt:            deletedAt,
+		SessionId:            getString(doc, "sessionID"),
+		StripeAccount:        getString(doc, "stripeAccount"),
+		IdempotencyKey:       getString(doc, "idempotencyKey"),
+		ReservationExpiresAt: getString(doc, "reservationExpiresAt"),
+		ReservationId:        getString(doc, "reservationID"),
+	}
+
+	// Map items if present
+	if itemsRaw, ok := doc["items"].(bson.A); ok {
+		items := make([]*api.Item, 0, len(itemsRaw))
+		for _, itemRaw := range itemsRaw {
+			if itemDoc, ok := itemRaw.(bson.M); ok {
+				items = append(items, &api.Item{
+					ID:       getString(itemDoc, "id"),
+					Name:     getString(itemDoc, "name"),
+					Quantity: getInt32(itemDoc, "quantity"),
+					PriceID:  getString(itemDoc, "priceid"),
+				})
+			}
+		}
+		order.Items = items
+	}
+
+	return order
+}
+
+// orderItem: Stabiles Mongo Schema eines Order Items ("id", "name", "quantity", "priceid")
+// Warum eigener Typ statt *api.Item direkt?
+// → Default bson Marshaling des Proto Structs leitet die Keys aus den Go Feldnamen ab
+// → Jedes neue Proto Feld (z.B. Unavailable) landet ungefragt in Mongo, Umbenennungen brechen Get
+// → Explizite Tags: Create + AdjustItems schreiben genau die Keys die orderFromDoc liest
+type orderItem struct {
+	ID       string `bson:"id"`
+	Name     string `bson:"name"`
+	Quantity int32  `bson:"quantity"`
+	PriceID  string `bson:"priceid"`
+}
+
+func toOrderItems(items []*api.Item) []orderItem {
+	docs := make([]orderItem, 0, len(items))
+	for _, item := range items {
+		docs = append(docs, orderItem{
+			ID:       item.ID,
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			PriceID:  item.PriceID,
+		})
+	}
+	return docs
+}
+
 // Helper functions for safe type conversion
 func getString(m bson.M, key string) string {
 	if val, ok := m[key].(string); ok {
@@ -210,3 +746,19 @@ func getInt32(m bson.M, key string) int32 {
 	}
 	return 0
 }
+
+// getTime: BSON Date → time.Time (false wenn das Feld fehlt, z.B. Legacy Orders)
+func getTime(m bson.M, key string) (time.Time, bool) {
+	switch val := m[key].(type) {
+	case primitive.DateTime:
+		return val.Time(), true
+	case time.Time:
+		return val, true
+	}
+	return time.Time{}, false
+}
+
+// formatTimestamp: RFC3339 in UTC, Bruchteile nur wenn vorhanden (ObjectID Fallback bleibt "...:05Z")
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,502 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+// newMongoTestStore: Echte MongoDB (ORDERS_TEST_MONGO_URI, z.B. die docker-compose DB), sonst Skip
+// Warum keine Fake Collection?
+// → Getestet wird der Unique Index, den entscheidet nur MongoDB selbst
+//
+// Eigene Datenbank pro Test → wird danach komplett gedroppt
+func newMongoTestStore(t *testing.T) *store {
+	t.Helper()
+
+	uri := os.Getenv("ORDERS_TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("ORDERS_TEST_MONGO_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("mongo.Connect: %v", err)
+	}
+
+	db := client.Database(fmt.Sprintf("orders_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+	return &store{collection: db.Collection("orders")}
+}
+
+// Zweiter Insert mit gleichem (customerID, idempotencyKey) → ID der ersten Order statt Duplicate Key Error
+func TestStoreCreateDuplicateIdempotencyKey(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+	if err := s.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	first, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "pending", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatalf("first Create: %v", err)
+	}
+
+	second, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "pending", IdempotencyKey: "k1"})
+	if !errors.Is(err, ErrDuplicateOrder) {
+		t.Fatalf("second Create = %v; want ErrDuplicateOrder", err)
+	}
+	if second != first {
+		t.Fatalf("second Create returned %s; want existing order %s", second.Hex(), first.Hex())
+	}
+
+	// Gleicher Key, anderer Kunde → eigene Order
+	other, err := s.Create(ctx, &pb.Order{CustomerId: "c2", Status: "pending", IdempotencyKey: "k1"})
+	if err != nil || other == first {
+		t.Fatalf("Create for other customer = %s, %v; want a new order", other.Hex(), err)
+	}
+}
+
+// Orders ohne Key fallen nicht unter den Partial Index → beliebig viele pro Kunde
+func TestStoreCreateWithoutIdempotencyKey(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+	if err := s.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "pending"}); err != nil {
+			t.Fatalf("Create #%d without key: %v", i+1, err)
+		}
+	}
+}
+
+// Create gibt den gespeicherten createdAt zurück → CreateOrder Response und GetOrder sind identisch (UTC)
+func TestStoreCreateReturnsStoredCreatedAt(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	order := &pb.Order{CustomerId: "c1", Status: "pending"}
+	id, err := s.Create(ctx, order)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := s.Get(ctx, id.Hex())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if order.CreatedAt == "" || order.CreatedAt != got.CreatedAt {
+		t.Fatalf("Create CreatedAt = %q; Get = %q", order.CreatedAt, got.CreatedAt)
+	}
+	if !strings.HasSuffix(order.CreatedAt, "Z") {
+		t.Fatalf("CreatedAt = %q; want UTC", order.CreatedAt)
+	}
+}
+
+// Reservation Frist an der Order → GetOrder (Success Page) liefert sie für den Countdown
+func TestStoreReservationExpiresAt(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	id, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "pending"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	const expiresAt = "2026-01-01T12:15:00Z"
+	if err := s.Update(ctx, id.Hex(), &pb.Order{ReservationExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	order, err := s.Get(ctx, id.Hex())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if order.ReservationExpiresAt != expiresAt {
+		t.Fatalf("ReservationExpiresAt = %q; want %q", order.ReservationExpiresAt, expiresAt)
+	}
+}
+
+func TestOrderFromDocReservationExpiresAt(t *testing.T) {
+	order := orderFromDoc(bson.M{"_id": primitive.NewObjectID(), "reservationExpiresAt": "2026-01-01T12:15:00Z"})
+	if order.ReservationExpiresAt != "2026-01-01T12:15:00Z" {
+		t.Fatalf("ReservationExpiresAt = %q; want 2026-01-01T12:15:00Z", order.ReservationExpiresAt)
+	}
+}
+
+// Reservation ID an der Order → CancelOrder gibt genau diese Reservation frei
+func TestStoreReservationID(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	id, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "pending"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := s.Update(ctx, id.Hex(), &pb.Order{ReservationId: "res-1", ReservationExpiresAt: "2026-01-01T12:15:00Z"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	// Späteres Status Update ohne ReservationId → ID bleibt stehen
+	if err := s.Update(ctx, id.Hex(), &pb.Order{Status: "paid"}); err != nil {
+		t.Fatalf("Update status: %v", err)
+	}
+
+	order, err := s.Get(ctx, id.Hex())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if order.ReservationId != "res-1" {
+		t.Fatalf("ReservationId = %q; want res-1", order.ReservationId)
+	}
+}
+
+func TestOrderFromDocReservationID(t *testing.T) {
+	order := orderFromDoc(bson.M{"_id": primitive.NewObjectID(), "reservationID": "res-1"})
+	if order.ReservationId != "res-1" {
+		t.Fatalf("ReservationId = %q; want res-1", order.ReservationId)
+	}
+}
+
+// Nur pending/waiting_payment Orders VOR createdBefore → frische und bezahlte Orders bleiben draußen
+func TestStoreGetStuck(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+	old := time.Now().Add(-time.Hour)
+
+	seed := []struct {
+		created time.Time
+		status  string
+	}{
+		{old, "pending"},                // stuck
+		{old, "waiting_payment"},        // stuck
+		{old, "paid"},                   // alt, aber bezahlt
+		{time.Now(), "pending"},         // frisch
+		{time.Now(), "waiting_payment"}, // frisch
+	}
+	ids := make([]primitive.ObjectID, len(seed))
+	for i, o := range seed {
+		ids[i] = primitive.NewObjectIDFromTimestamp(o.created)
+		if _, err := s.collection.InsertOne(ctx, bson.M{"_id": ids[i], "customerID": "c1", "status": o.status}); err != nil {
+			t.Fatalf("seed order %d: %v", i, err)
+		}
+	}
+
+	orders, err := s.GetStuck(ctx, stuckOrderStatuses, time.Now().Add(-15*time.Minute))
+	if err != nil {
+		t.Fatalf("GetStuck: %v", err)
+	}
+	if len(orders) != 2 || orders[0].Id != ids[0].Hex() || orders[1].Id != ids[1].Hex() {
+		t.Fatalf("stuck orders = %v; want the two old unpaid orders", orders)
+	}
+}
+
+// Items mit PriceID + Name → Create schreibt die Keys, die Get liest (früher kam PriceID leer zurück)
+func TestStoreCreateItemsRoundTrip(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	items := []*pb.Item{
+		{ID: "1", Name: "Burger", Quantity: 2, PriceID: "price_burger"},
+		{ID: "2", Name: "Pommes", Quantity: 1, PriceID: "price_pommes"},
+	}
+	id, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "pending", Items: items})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	order, err := s.Get(ctx, id.Hex())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertItems(t, order.Items, items)
+}
+
+// Ohne Mongo: Gleiches Schema durch den echten bson Codec → orderFromDoc
+func TestOrderItemsBSONRoundTrip(t *testing.T) {
+	items := []*pb.Item{{ID: "1", Name: "Burger", Quantity: 2, PriceID: "price_burger", Unavailable: true}}
+
+	raw, err := bson.Marshal(bson.M{"_id": primitive.NewObjectID(), "items": toOrderItems(items)})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	stored := doc["items"].(bson.A)[0].(bson.M)
+	if len(stored) != 4 {
+		t.Errorf("stored item keys = %v; want exactly id, name, quantity, priceid", stored)
+	}
+	// Unavailable ist Live-Stock-Zustand, nicht Teil der Order
+	assertItems(t, orderFromDoc(doc).Items, []*pb.Item{{ID: "1", Name: "Burger", Quantity: 2, PriceID: "price_burger"}})
+}
+
+func assertItems(t *testing.T, got, want []*pb.Item) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("items = %v; want %v", got, want)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.ID != w.ID || g.Name != w.Name || g.Quantity != w.Quantity || g.PriceID != w.PriceID {
+			t.Errorf("item %d = %+v; want %+v", i, g, w)
+		}
+	}
+}
+
+// Legacy Dokumente ohne createdAt → Backfill setzt den ObjectID Timestamp, vorhandenes createdAt bleibt, zweiter Lauf fasst nichts an
+func TestStoreBackfillCreatedAt(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	legacyAt := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	legacy := []primitive.ObjectID{
+		primitive.NewObjectIDFromTimestamp(legacyAt),
+		primitive.NewObjectIDFromTimestamp(legacyAt.Add(time.Hour)),
+	}
+	for _, id := range legacy {
+		if _, err := s.collection.InsertOne(ctx, bson.M{"_id": id, "customerID": "c1", "status": "paid"}); err != nil {
+			t.Fatalf("insert legacy order: %v", err)
+		}
+	}
+	explicitAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := primitive.NewObjectIDFromTimestamp(legacyAt)
+	if _, err := s.collection.InsertOne(ctx, bson.M{"_id": current, "customerID": "c1", "status": "paid", "createdAt": explicitAt}); err != nil {
+		t.Fatalf("insert current order: %v", err)
+	}
+
+	n, err := s.BackfillCreatedAt(ctx)
+	if err != nil {
+		t.Fatalf("BackfillCreatedAt: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("backfilled = %d; want 2", n)
+	}
+
+	createdAt := func(id primitive.ObjectID) time.Time {
+		t.Helper()
+		var doc struct {
+			CreatedAt time.Time `bson:"createdAt"`
+		}
+		if err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+			t.Fatalf("FindOne %s: %v", id.Hex(), err)
+		}
+		return doc.CreatedAt
+	}
+	for _, id := range legacy {
+		if got := createdAt(id); !got.Equal(id.Timestamp()) {
+			t.Errorf("order %s createdAt = %s; want %s", id.Hex(), got, id.Timestamp())
+		}
+	}
+	if got := createdAt(current); !got.Equal(explicitAt) {
+		t.Errorf("existing createdAt = %s; want unchanged %s", got, explicitAt)
+	}
+
+	if n, err := s.BackfillCreatedAt(ctx); err != nil || n != 0 {
+		t.Errorf("second BackfillCreatedAt = %d, %v; want 0, nil", n, err)
+	}
+}
+
+// Filter: nur Orders DIESES Kunden in den angefragten Status, älteste zuerst
+func TestStoreGetByCustomerAndStatus(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	var unpaid []string
+	for _, o := range []*pb.Order{
+		{CustomerId: "c1", Status: "pending"},
+		{CustomerId: "c1", Status: "paid"},
+		{CustomerId: "c1", Status: "waiting_payment"},
+		{CustomerId: "c2", Status: "pending"},
+	} {
+		id, err := s.Create(ctx, o)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if o.CustomerId == "c1" && o.Status != "paid" {
+			unpaid = append(unpaid, id.Hex())
+		}
+	}
+
+	orders, err := s.GetByCustomerAndStatus(ctx, "c1", stuckOrderStatuses)
+	if err != nil {
+		t.Fatalf("GetByCustomerAndStatus: %v", err)
+	}
+	var got []string
+	for _, o := range orders {
+		got = append(got, o.Id)
+	}
+	if len(got) != len(unpaid) || got[0] != unpaid[0] || got[1] != unpaid[1] {
+		t.Fatalf("orders = %v; want %v", got, unpaid)
+	}
+}
+
+// Soft-Delete: aus GetByStatus/GetByCustomer/GetBySession verschwunden, Get findet sie weiter, Restore holt sie zurück
+func TestStoreSoftDeleteAndRestore(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		id, err := s.Create(ctx, &pb.Order{CustomerId: "c1", SessionId: "t1", Status: "completed"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		ids = append(ids, id.Hex())
+	}
+	deleted, kept := ids[0], ids[1]
+
+	visible := func() map[string][]string {
+		t.Helper()
+		byStatus, _, err := s.GetByStatus(ctx, "completed", primitive.NilObjectID, 10)
+		if err != nil {
+			t.Fatalf("GetByStatus: %v", err)
+		}
+		byCustomer, err := s.GetByCustomer(ctx, "c1", "", time.Time{}, time.Time{}, 10)
+		if err != nil {
+			t.Fatalf("GetByCustomer: %v", err)
+		}
+		bySession, err := s.GetBySession(ctx, "t1")
+		if err != nil {
+			t.Fatalf("GetBySession: %v", err)
+		}
+		got := make(map[string][]string)
+		for name, orders := range map[string][]*pb.Order{"status": byStatus, "customer": byCustomer, "session": bySession} {
+			for _, o := range orders {
+				got[name] = append(got[name], o.Id)
+			}
+		}
+		return got
+	}
+
+	if ok, err := s.SoftDelete(ctx, deleted); err != nil || !ok {
+		t.Fatalf("SoftDelete = %v, %v; want true", ok, err)
+	}
+	if ok, err := s.SoftDelete(ctx, deleted); err != nil || ok {
+		t.Fatalf("second SoftDelete = %v, %v; want false (already deleted)", ok, err)
+	}
+	for name, got := range visible() {
+		if len(got) != 1 || got[0] != kept {
+			t.Errorf("%s query = %v; want only %s", name, got, kept)
+		}
+	}
+
+	// Audit: Get liefert die gelöschte Order mit Zeitpunkt
+	o, err := s.Get(ctx, deleted)
+	if err != nil || o.DeletedAt == "" {
+		t.Fatalf("Get deleted order = %+v, %v; want it with DeletedAt", o, err)
+	}
+
+	if ok, err := s.Restore(ctx, deleted); err != nil || !ok {
+		t.Fatalf("Restore = %v, %v; want true", ok, err)
+	}
+	if ok, err := s.Restore(ctx, deleted); err != nil || ok {
+		t.Fatalf("second Restore = %v, %v; want false (not deleted)", ok, err)
+	}
+	for _, name := range []string{"status", "customer", "session"} {
+		if got := visible()[name]; len(got) != 2 {
+			t.Errorf("%s query after restore = %v; want both orders", name, got)
+		}
+	}
+	if o, _ := s.Get(ctx, deleted); o.DeletedAt != "" {
+		t.Errorf("DeletedAt after restore = %q; want empty", o.DeletedAt)
+	}
+}
+
+func TestOrderFromDocDeletedAt(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := orderFromDoc(bson.M{"_id": primitive.NewObjectID(), deletedAtKey: primitive.NewDateTimeFromTime(deletedAt)})
+	if o.DeletedAt != formatTimestamp(deletedAt) {
+		t.Errorf("DeletedAt = %q; want %q", o.DeletedAt, formatTimestamp(deletedAt))
+	}
+	if o := orderFromDoc(bson.M{"_id": primitive.NewObjectID()}); o.DeletedAt != "" {
+		t.Errorf("DeletedAt without field = %q; want empty", o.DeletedAt)
+	}
+}
+
+// AdjustItems: Nur EINE von zwei Anpassungen mit denselben alten Items gewinnt, Outbox hält ihr Event
+func TestStoreAdjustItemsCompareAndSet(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	previous := []*pb.Item{{ID: "1", Name: "Burger", Quantity: 3, PriceID: "price_burger"}}
+	remaining := []*pb.Item{{ID: "1", Name: "Burger", Quantity: 1, PriceID: "price_burger"}}
+	removed := []*pb.Item{{ID: "1", Name: "Burger", Quantity: 2, PriceID: "price_burger"}}
+	id, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "paid", Items: previous})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	first := &pb.OrderItemsAdjusted{AdjustmentId: "adj-1", OrderId: id.Hex(), CustomerId: "c1", RemovedItems: removed}
+	if ok, err := s.AdjustItems(ctx, id.Hex(), previous, remaining, first); err != nil || !ok {
+		t.Fatalf("first AdjustItems = %v, %v; want true", ok, err)
+	}
+	second := &pb.OrderItemsAdjusted{AdjustmentId: "adj-2", OrderId: id.Hex(), CustomerId: "c1", RemovedItems: removed}
+	if ok, err := s.AdjustItems(ctx, id.Hex(), previous, remaining, second); err != nil || ok {
+		t.Fatalf("second AdjustItems = %v, %v; want false", ok, err)
+	}
+
+	pending, err := s.PendingAdjustments(ctx, id.Hex())
+	if err != nil {
+		t.Fatalf("PendingAdjustments: %v", err)
+	}
+	if len(pending) != 1 || pending[0].AdjustmentId != "adj-1" {
+		t.Fatalf("pending = %v; want only adj-1", pending)
+	}
+	assertItems(t, pending[0].RemovedItems, removed)
+
+	if err := s.CompleteAdjustment(ctx, id.Hex(), "adj-1"); err != nil {
+		t.Fatalf("CompleteAdjustment: %v", err)
+	}
+	if pending, _ := s.PendingAdjustments(ctx, id.Hex()); len(pending) != 0 {
+		t.Errorf("pending after complete = %v; want none", pending)
+	}
+}
+
+// Relay sieht nur Outbox Einträge die älter als der Cutoff sind (frische gehören noch zum laufenden Call)
+func TestStoreStaleAdjustments(t *testing.T) {
+	s := newMongoTestStore(t)
+	ctx := context.Background()
+
+	previous := []*pb.Item{{ID: "1", Name: "Burger", Quantity: 3, PriceID: "price_burger"}}
+	remaining := []*pb.Item{{ID: "1", Name: "Burger", Quantity: 1, PriceID: "price_burger"}}
+	removed := []*pb.Item{{ID: "1", Name: "Burger", Quantity: 2, PriceID: "price_burger"}}
+	id, err := s.Create(ctx, &pb.Order{CustomerId: "c1", Status: "paid", Items: previous})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	adjusted := &pb.OrderItemsAdjusted{AdjustmentId: "adj-1", OrderId: id.Hex(), CustomerId: "c1", RemovedItems: removed}
+	if ok, err := s.AdjustItems(ctx, id.Hex(), previous, remaining, adjusted); err != nil || !ok {
+		t.Fatalf("AdjustItems = %v, %v; want true", ok, err)
+	}
+
+	if stale, err := s.StaleAdjustments(ctx, time.Now().Add(-time.Minute), 10); err != nil || len(stale) != 0 {
+		t.Fatalf("StaleAdjustments(1m ago) = %v, %v; want none", stale, err)
+	}
+	stale, err := s.StaleAdjustments(ctx, time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("StaleAdjustments: %v", err)
+	}
+	if len(stale) != 1 || stale[0].AdjustmentId != "adj-1" || stale[0].OrderId != id.Hex() {
+		t.Fatalf("stale = %v; want adj-1 of the order", stale)
+	}
+	assertItems(t, stale[0].RemovedItems, removed)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"time"
 
 	"github.com/timour/order-microservices/common/api"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,6 +17,26 @@ type OrdersService interface {
 type OrdersStore interface {
 	Create(context.Context, *api.Order) (primitive.ObjectID, error)
 	Update(context.Context, string, *api.Order) error
+	UpdateStatusIf(ctx context.Context, orderID string, from []string, to string) (bool, error)
+	Abandon(ctx context.Context, orderID string) (bool, error)
+	SoftDelete(ctx context.Context, orderID string) (bool, error)
+	Restore(ctx context.Context, orderID string) (bool, error)
+	AdjustItems(ctx context.Context, orderID string, previous, remaining []*api.Item, adjustment *api.OrderItemsAdjusted) (bool, error)
+	RevertAdjustment(ctx context.Context, orderID, adjustmentID string, previous, remaining []*api.Item) (bool, error)
+	PendingAdjustments(ctx context.Context, orderID string) ([]*api.OrderItemsAdjusted, error)
+	CompleteAdjustment(ctx context.Context, orderID, adjustmentID string) error
+	StaleAdjustments(ctx context.Context, createdBefore time.Time, limit int64) ([]*api.OrderItemsAdjusted, error)
 	Get(context.Context, string) (*api.Order, error)
-	GetByStatus(context.Context, string) ([]*api.Order, error)
+	GetByStatus(ctx context.Context, status string, afterID primitive.ObjectID, limit int64) (orders []*api.Order, partial bool, err error)
+	GetBySession(context.Context, string) ([]*api.Order, error)
+	GetStuck(ctx context.Context, statuses []string, createdBefore time.Time) ([]*api.Order, error)
+	GetByCustomerAndStatus(ctx context.Context, customerID string, statuses []string) ([]*api.Order, error)
+	GetByCustomer(ctx context.Context, customerID, status string, since, until time.Time, limit int64) ([]*api.Order, error)
+}
+
+// IdempotencyStore: (customerID, Idempotency Key) → Order, siehe idempotencyStore
+type IdempotencyStore interface {
+	Claim(ctx context.Context, customerID, key string) (orderID string, claimed bool, err error)
+	Complete(ctx context.Context, customerID, key, orderID string) error
+	Release(ctx context.Context, customerID, key string) error
 }
@@ -3,11 +3,15 @@ package main
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/config"
 	"github.com/timour/order-microservices/common/logger"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/startup"
 	"github.com/timour/order-microservices/discovery"
 	"github.com/timour/order-microservices/discovery/consul"
 	"github.com/timour/order-microservices/payments/gateway"
@@ -21,6 +25,16 @@ type App struct {
 	config        Config
 	logger        *slog.Logger
 	ordersGateway gateway.OrdersGateway
+	ledger        PaymentLedger            // Geteilt mit dem Webhook Handler (main.go)
+	business      *metrics.BusinessMetrics // Payment Links + Stripe Latenz (EINE Instanz, geteilt mit dem HTTP Service)
+	upstream      *metrics.UpstreamMetrics // gRPC Latenz + Fehlerrate zu Orders/Stock (EINE Instanz → promauto registriert nur einmal)
+	consumers     *metrics.ConsumerMetrics // Processing Dauer + Outcome, geteilt von allen vier Consumern
+	stripeLimiter *processor.Limiter       // EINE Semaphore für alle Stripe Processoren (Consumer + HTTP Service)
+
+	// Consumer Lifecycle: Shutdown stoppt den Consumer und wartet auf die laufende Message
+	consumerCtx  context.Context
+	stopConsumer context.CancelFunc
+	consumerDone chan struct{} // Geschlossen sobald Start (und damit Listen) returnt
 }
 
 type Config struct {
@@ -34,9 +48,31 @@ type Config struct {
 	StripeKey   string
 	HTTPAddr    string
 	OrdersAddr  string
+
+	// InternalToken: Bearer Token für Payment Link + Ledger Endpoints (Gateway kennt ihn, "" = gesperrt)
+	InternalToken string
+
+	// PaymentLinkTTL: Reservation TTL von Stock → Checkout Session läuft nicht länger
+	PaymentLinkTTL time.Duration
+
+	// OrdersUpdateAttempts: gRPC Versuche für das Payment Link Update, danach order.payment_link Event
+	OrdersUpdateAttempts int
+
+	// ReservationMode: on_pay → Payments reserviert Stock vor der Checkout Session (muss zu Orders passen)
+	ReservationMode config.ReservationMode
+
+	// ShutdownDrainTimeout: Wie lange Shutdown auf die laufende Message (Stripe Call) wartet
+	// bevor RabbitMQ trotzdem geschlossen wird
+	ShutdownDrainTimeout time.Duration
+
+	// StripeMaxConcurrency: Max. gleichzeitige Stripe API Calls (Consumer + HTTP), 0 = unbegrenzt
+	StripeMaxConcurrency int
 }
 
-func NewApp(config Config) (*App, error) {
+// DefaultShutdownDrainTimeout: Stripe Call + Orders Update inkl. Retries passen locker rein
+const DefaultShutdownDrainTimeout = 30 * time.Second
+
+func NewApp(config Config, report *startup.Report) (*App, error) {
 	log := logger.NewLogger(config.ServiceName)
 
 	// Connect to Consul
@@ -44,6 +80,7 @@ func NewApp(config Config) (*App, error) {
 	var err error
 	if config.ConsulAddr != "" {
 		registry, err = consul.NewRegistry(config.ConsulAddr)
+		report.Record("consul", true, err)
 		if err != nil {
 			log.Error("failed to connect to consul", slog.Any("error", err))
 			return nil, err
@@ -57,12 +94,17 @@ func NewApp(config Config) (*App, error) {
 		slog.String("port", config.AMQPPort),
 	)
 
-	ch, close, err := broker.Connect(
-		config.AMQPUser,
-		config.AMQPPass,
-		config.AMQPHost,
-		config.AMQPPort,
-	)
+	var ch *amqp.Channel
+	var close func() error
+	err = report.Check("rabbitmq", true, func() (err error) {
+		ch, close, err = broker.Connect(
+			config.AMQPUser,
+			config.AMQPPass,
+			config.AMQPHost,
+			config.AMQPPort,
+		)
+		return err
+	})
 	if err != nil {
 		log.Error("failed to connect to rabbitmq", slog.Any("error", err))
 		return nil, err
@@ -70,18 +112,31 @@ func NewApp(config Config) (*App, error) {
 
 	log.Info("rabbitmq connected successfully")
 
+	// Warum eigener Context statt dem aus main?
+	// → main cancelt seinen ctx erst NACH Shutdown → Shutdown muss den Consumer selbst stoppen können
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+
 	return &App{
 		channel:       ch,
 		closeRabbitMQ: close,
 		registry:      registry,
 		config:        config,
 		logger:        log,
+		business:      metrics.NewBusinessMetrics(config.ServiceName),
+		upstream:      metrics.NewUpstreamMetrics(config.ServiceName),
+		consumers:     metrics.NewConsumerMetrics(config.ServiceName),
+		stripeLimiter: processor.NewLimiter(config.StripeMaxConcurrency),
+		consumerCtx:   consumerCtx,
+		stopConsumer:  stopConsumer,
+		consumerDone:  make(chan struct{}),
 	}, nil
 }
 
 func (a *App) Start(ctx context.Context) error {
+	defer close(a.consumerDone)
+
 	// 1. Initialize Stripe Processor
-	stripeProcessor := processor.NewStripeProcessor(a.config.StripeKey)
+	stripeProcessor := processor.NewStripeProcessor(a.config.StripeKey, a.config.PaymentLinkTTL, a.business, a.stripeLimiter)
 	a.logger.Info("stripe processor initialized")
 
 	// 2. OrdersGateway is now initialized in main.go BEFORE app.Start() to avoid race condition with HTTP handler
@@ -89,13 +144,25 @@ func (a *App) Start(ctx context.Context) error {
 	// 3. Setup Business Logic
 	// → Service nutzt Gateway für synchrone Calls
 	// → Webhook handler wird später Events publishen!
-	svc := NewService(stripeProcessor, a.ordersGateway, a.logger)
+	svc := NewService(stripeProcessor, a.ordersGateway, gateway.NewStockGateway(a.registry, a.upstream), a.ledger, a.config.ReservationMode, a.business, a.logger)
 
-	// 4. Start RabbitMQ Consumer
-	consumer := NewConsumer(svc, a.logger)
+	// 4. Start Refund Consumer (order.items_adjusted) im Hintergrund
+	refunds := NewRefundConsumer(svc, broker.NewDeduplicatorFromEnv(a.config.ServiceName), a.consumers, a.logger)
+	go refunds.Listen(a.channel)
+
+	// 4b. Start Cancel Consumer (order.cancelled) → Refund bezahlter Orders
+	cancellations := NewCancelConsumer(svc, broker.NewDeduplicatorFromEnv(a.config.ServiceName), a.consumers, a.logger)
+	go cancellations.Listen(a.channel)
+
+	// 4c. Start Expired Consumer (order.expired) → offene Stripe Session schließen
+	expirations := NewExpiredConsumer(svc, broker.NewDeduplicatorFromEnv(a.config.ServiceName), a.consumers, a.logger)
+	go expirations.Listen(a.channel)
+
+	// 5. Start RabbitMQ Consumer
+	consumer := NewConsumer(svc, broker.NewDeduplicatorFromEnv(a.config.ServiceName), a.consumers, a.logger)
 
 	a.logger.Info("consumer started, waiting for messages...")
-	consumer.Listen(a.channel) // Blocking call
+	consumer.Listen(a.consumerCtx, a.channel) // Blocking call bis Shutdown
 
 	return nil
 }
@@ -103,7 +170,13 @@ func (a *App) Start(ctx context.Context) error {
 func (a *App) Shutdown(ctx context.Context) error {
 	a.logger.Info("shutting down gracefully")
 
-	// Close RabbitMQ connection
+	// 1. Consumer stoppen und die laufende Message zu Ende verarbeiten lassen
+	// Warum VOR dem Close?
+	// → Vorher: RabbitMQ wurde unter dem laufenden Consumer geschlossen
+	// → Stripe Session erstellt, aber Ack auf geschlossenem Channel → Redelivery → zweite Session
+	a.drainConsumer(ctx)
+
+	// 2. Close RabbitMQ connection
 	if a.closeRabbitMQ != nil {
 		if err := a.closeRabbitMQ(); err != nil {
 			a.logger.Error("error closing rabbitmq", slog.Any("error", err))
@@ -112,3 +185,26 @@ func (a *App) Shutdown(ctx context.Context) error {
 
 	return nil
 }
+
+// drainConsumer: Wartet bis Listen returnt, höchstens ShutdownDrainTimeout (<= 0 → Default)
+func (a *App) drainConsumer(ctx context.Context) {
+	a.stopConsumer()
+
+	timeout := a.config.ShutdownDrainTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownDrainTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-a.consumerDone:
+		a.logger.Info("consumer drained")
+	case <-timer.C:
+		a.logger.Warn("consumer did not drain in time, closing rabbitmq anyway",
+			slog.Duration("timeout", timeout),
+		)
+	case <-ctx.Done():
+		a.logger.Warn("shutdown context done before consumer drained", slog.Any("error", ctx.Err()))
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/telemetry"
+)
+
+// cancelConsumer: Konsumiert "order.cancelled" → Refund bezahlter Orders + Reservation freigeben
+// Warum eigener Consumer?
+// → Andere Queue, eigener Lebenszyklus → Refund-Fehler blockieren NICHT die Payment Link Erstellung
+type cancelConsumer struct {
+	service PaymentService
+	dedup   *broker.Deduplicator
+	metrics *metrics.ConsumerMetrics // nil = aus
+	logger  *slog.Logger
+}
+
+func NewCancelConsumer(service PaymentService, dedup *broker.Deduplicator, consumerMetrics *metrics.ConsumerMetrics, logger *slog.Logger) *cancelConsumer {
+	return &cancelConsumer{
+		service: service,
+		dedup:   dedup,
+		metrics: consumerMetrics,
+		logger:  logger,
+	}
+}
+
+// Listen: Blockiert solange der Delivery Channel offen ist
+func (c *cancelConsumer) Listen(ch broker.Channel) {
+	q, err := ch.QueueDeclare(
+		broker.OrderCancelledEvent, // queue name: "order.cancelled"
+		true,                       // durable
+		false,                      // delete when unused
+		false,                      // exclusive
+		false,                      // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange": broker.DLX, // Failed refunds → order.cancelled.dlq
+		},
+	)
+	if err != nil {
+		c.logger.Error("failed to declare queue", slog.Any("error", err))
+		return
+	}
+
+	// Orders publiziert an den Exchange "order.cancelled" → ohne Bind kommt hier nichts an
+	err = ch.QueueBind(
+		q.Name,                     // queue name: "order.cancelled"
+		"",                         // routing key: "" = matches all
+		broker.OrderCancelledEvent, // exchange name: "order.cancelled"
+		false,                      // no-wait
+		nil,                        // arguments
+	)
+	if err != nil {
+		c.logger.Error("failed to bind queue to exchange", slog.Any("error", err))
+		return
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		c.logger.Error("failed to start consuming", slog.Any("error", err))
+		return
+	}
+
+	c.logger.Info("cancel consumer started",
+		slog.String("queue", broker.OrderCancelledEvent),
+	)
+
+	for d := range msgs {
+		broker.Process(c.metrics, ch, &d, broker.OrderCancelledEvent, func() broker.Outcome {
+			return c.handle(ch, d)
+		})
+	}
+}
+
+// handle: Verarbeitet EINE order.cancelled Delivery (Ack/Nack passiert hier drin)
+func (c *cancelConsumer) handle(ch broker.Channel, d amqp.Delivery) broker.Outcome {
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+	ctx, span := otel.Tracer("payment").Start(ctx, "AMQP - consume - "+broker.OrderCancelledEvent)
+	telemetry.TagSpan(ctx)
+
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderCancelledEvent) {
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+	if c.dedup.Duplicate(&d) {
+		span.End()
+		return broker.OutcomeDuplicate
+	}
+
+	order := &pb.Order{}
+	if err := json.Unmarshal(d.Body, order); err != nil {
+		// Kaputtes JSON wird durch Retry nicht besser → direkt in die DLQ
+		c.logger.Error("failed to unmarshal cancelled order", slog.Any("error", err))
+		d.Nack(false, false)
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	refundID, err := c.service.RefundCancelledOrder(ctx, order)
+	if err != nil {
+		c.logger.Error("failed to handle cancelled order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		// ErrManualRefund → DLQ mit Grund: Retry erstattet nie, die Nachricht muss jemand sehen
+		if errors.Is(err, ErrInvalidOrder) || errors.Is(err, ErrManualRefund) {
+			if err := broker.DeadLetter(ctx, ch, &d, broker.OrderCancelledEvent, err.Error()); err != nil {
+				c.logger.Error("failed to dead-letter cancelled order", slog.Any("error", err))
+			}
+			span.End()
+			return broker.OutcomeDeadLetter
+		}
+		if retryRateLimited(ch, &d, broker.OrderCancelledEvent, err, c.logger) {
+			span.End()
+			return broker.OutcomeRetry
+		}
+		// Retry ist sicher: "cancel-" + orderID = Stripe Idempotency Key
+		if err := broker.HandleRetry(ch, &d, broker.OrderCancelledEvent, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End()
+		return broker.OutcomeRetry
+	}
+
+	d.Ack(false)
+	c.dedup.Done(&d)
+
+	c.logger.Info("cancelled order handled",
+		slog.String("order_id", order.Id),
+		slog.String("refund_id", refundID),
+	)
+	span.End()
+	return broker.OutcomeSuccess
+}
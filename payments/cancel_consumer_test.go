@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"github.com/timour/order-microservices/common/config"
+)
+
+// fakeRefunds: PaymentService, nur RefundCancelledOrder wird vom cancelConsumer genutzt
+type fakeRefunds struct {
+	PaymentService
+
+	mu       sync.Mutex
+	refunded []string
+}
+
+func (f *fakeRefunds) RefundCancelledOrder(_ context.Context, o *pb.Order) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refunded = append(f.refunded, o.Id)
+	return "re_" + o.Id, nil
+}
+
+func TestCancelConsumerReceivesExchangePublish(t *testing.T) {
+	b := brokertest.New()
+	service := &fakeRefunds{}
+	c := NewCancelConsumer(service, broker.NewDeduplicator(100, time.Minute), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	go c.Listen(b)
+	defer b.Close()
+
+	if err := b.WaitForBinding(broker.OrderCancelledEvent, broker.OrderCancelledEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wie Orders: an den Exchange "order.cancelled", nicht über den Default Exchange
+	body, _ := json.Marshal(&pb.Order{Id: "o1", Status: "cancelled"})
+	err := b.PublishWithContext(context.Background(), broker.OrderCancelledEvent, "", false, false, amqp.Publishing{
+		MessageId: broker.MessageID(broker.OrderCancelledEvent, "o1"),
+		Body:      body,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if len(service.refunded) != 1 || service.refunded[0] != "o1" {
+		t.Fatalf("refunded = %v; want [o1]", service.refunded)
+	}
+}
+
+// Bezahlte Tisch-Order storniert → DLQ mit Grund statt Retry oder stillem Ack
+func TestCancelConsumerDeadLettersManualRefund(t *testing.T) {
+	s, _ := newTestService(config.ReservationOnCreate, nil)
+	if err := s.ledger.CompletePayment(context.Background(), &PaymentRecord{OrderID: "o1", SessionID: "cs_t7"}); err != nil {
+		t.Fatal(err)
+	}
+	b := brokertest.New()
+	t.Cleanup(func() { b.Close() })
+
+	go NewCancelConsumer(s, broker.NewDeduplicator(100, time.Minute), nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Listen(b)
+	if err := b.WaitForBinding(broker.OrderCancelledEvent, broker.OrderCancelledEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&pb.Order{Id: "o1", SessionId: "t7", Status: "cancelled"})
+	if err := b.PublishWithContext(context.Background(), broker.OrderCancelledEvent, "", false, false, amqp.Publishing{Body: body}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var deadLettered int
+	for _, m := range b.Published() {
+		switch {
+		case m.Exchange == broker.DLX && m.RoutingKey == broker.OrderCancelledEvent:
+			deadLettered++
+			if reason, _ := m.Publishing.Headers[broker.HeaderDeadLetterReason].(string); !strings.Contains(reason, "manual refund") {
+				t.Errorf("dead-letter reason = %q; want it to ask for a manual refund", reason)
+			}
+		case m.Exchange == broker.OrderCancelledEvent:
+			// Unser eigener Publish oben
+		default:
+			t.Errorf("unexpected publish %s/%s; want no retry", m.Exchange, m.RoutingKey)
+		}
+	}
+	if deadLettered != 1 {
+		t.Fatalf("dead-lettered = %d; want 1", deadLettered)
+	}
+}
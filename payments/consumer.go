@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -10,16 +11,35 @@ import (
 
 	pb "github.com/timour/order-microservices/common/api"
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/telemetry"
 )
 
+// orderCreatedConsumerTag: Fester Consumer Tag → Shutdown kann genau DIESEN Consumer per ch.Cancel stoppen
+// → Eindeutig pro Channel reicht (jede Instanz hat ihren eigenen Channel)
+const orderCreatedConsumerTag = "payments.order.created"
+
+// cancelableChannel: broker.Channel + Cancel → stop() kann den Consumer beim Server abmelden
+// → *amqp.Channel erfüllt es, brokertest.Broker auch (Shutdown ohne echtes RabbitMQ testen)
+type cancelableChannel interface {
+	broker.Channel
+	Cancel(consumer string, noWait bool) error
+}
+
+var _ cancelableChannel = (*amqp.Channel)(nil)
+
 type consumer struct {
 	service PaymentService
+	dedup   *broker.Deduplicator     // Redeliveries (gleiche MessageId) überspringen, nil = aus
+	metrics *metrics.ConsumerMetrics // Processing Dauer + Outcome, nil = aus
 	logger  *slog.Logger
 }
 
-func NewConsumer(service PaymentService, logger *slog.Logger) *consumer {
+func NewConsumer(service PaymentService, dedup *broker.Deduplicator, consumerMetrics *metrics.ConsumerMetrics, logger *slog.Logger) *consumer {
 	return &consumer{
 		service: service,
+		dedup:   dedup,
+		metrics: consumerMetrics,
 		logger:  logger,
 	}
 }
@@ -28,7 +48,8 @@ func NewConsumer(service PaymentService, logger *slog.Logger) *consumer {
 // Warum Listen?
 // → Payment Service ist PASSIV: Wartet auf "order.created" Events
 // → Orders Service ist AKTIV: Published Events
-func (c *consumer) Listen(ch *amqp.Channel) {
+// Blockiert bis ctx cancelled wird (Shutdown) oder RabbitMQ den Delivery Channel schließt
+func (c *consumer) Listen(ctx context.Context, ch cancelableChannel) {
 	// Warum QueueDeclare?
 	// → Erstellt Queue für order.created events
 	// → DLX + DLQs werden automatisch in broker.Connect() erstellt!
@@ -59,132 +80,223 @@ func (c *consumer) Listen(ch *amqp.Channel) {
 	// → Registriert diesen Service als CONSUMER für Queue "order.created"
 	// → Gibt Channel zurück: Empfängt Messages als Go Channel!
 	msgs, err := ch.Consume(
-		q.Name, // queue: "order.created"
-		"",     // consumer tag: "" = Auto-generiert
-		false,  // auto-ack: FALSE! (Wichtig für DLQ!) → Manuelles Ack/Nack
-		false,  // exclusive: Andere Consumer können auch lesen (Load Balancing!)
-		false,  // no-local: Irrelevant (RabbitMQ Feature)
-		false,  // no-wait: Warte auf Server Bestätigung
-		nil,    // args: Keine extra Config
+		q.Name,                  // queue: "order.created"
+		orderCreatedConsumerTag, // consumer tag: fest → ch.Cancel beim Shutdown
+		false,                   // auto-ack: FALSE! (Wichtig für DLQ!) → Manuelles Ack/Nack
+		false,                   // exclusive: Andere Consumer können auch lesen (Load Balancing!)
+		false,                   // no-local: Irrelevant (RabbitMQ Feature)
+		false,                   // no-wait: Warte auf Server Bestätigung
+		nil,                     // args: Keine extra Config
 	)
 	if err != nil {
 		c.logger.Error("failed to start consuming", slog.Any("error", err))
 		return
 	}
 
-	// Warum var forever chan struct{}?
-	// → Uninitialisierter Channel = blockiert EWIG bei <-forever
-	// → Verhindert dass Listen() returnt (Consumer soll IMMER laufen!)
-	var forever chan struct{}
-
-	// Warum Goroutine?
-	// → for d := range msgs blockiert!
-	// → In Goroutine: Haupt-Thread kann weiter (für Shutdown Handling)
-	go func() {
-		// Warum for d := range msgs?
-		// → Wartet auf neue Messages von RabbitMQ
-		// → Blockiert bis Message kommt!
-		// → d = Delivery (RabbitMQ Message mit Body, Headers, etc.)
-		for d := range msgs {
-			// ⭐ OpenTelemetry: Extract trace context from AMQP headers FIRST
-			// → Must be done before any processing to continue distributed trace
-			ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
-
-			// ⭐ OpenTelemetry: Start span for message processing
-			// → This span represents the consumer processing the message
-			// → Will be visible in Jaeger as "AMQP - consume - order.created"
-			tracer := otel.Tracer("payment")
-			ctx, span := tracer.Start(ctx, "AMQP - consume - order.created")
-
-			c.logger.Info("received message",
-				slog.String("body", string(d.Body)),
-			)
-
-			// Warum json.Unmarshal?
-			// → d.Body ist []byte (JSON)
-			// → Konvertiert zurück zu *pb.Order struct
-			// → GLEICHE Order die Orders Service published hat!
-			o := &pb.Order{}
-			if err := json.Unmarshal(d.Body, o); err != nil {
-				c.logger.Error("failed to unmarshal order", slog.Any("error", err))
-				// Warum HandleRetry?
-				// → Smart retry: Will retry up to 3 times
-				// → After 3 retries → sends to DLQ
-				if err := broker.HandleRetry(ch, &d); err != nil {
-					c.logger.Error("error handling retry", slog.Any("error", err))
-				}
-				// Warum Nack nach HandleRetry?
-				// → Acknowledges THIS message (already republished by HandleRetry)
-				// → Prevents double processing
-				d.Nack(false, false)
-				span.End() // ⭐ End span before continue!
-				continue
-			}
+	c.logger.Info("waiting for messages...",
+		slog.String("queue", broker.OrderCreatedEvent),
+	)
 
-			// 🧪 TEST: Deliberately fail payments for testing DLQ
-			// Warum dieser Test?
-			// → Zum Testen ob DLQ funktioniert!
-			// → Order mit CustomerID "FAIL_TEST" → wird 3x retried → dann DLQ
-			// → In RabbitMQ UI: Message sollte nach 3 retries in "dlq_main" erscheinen
-			if o.CustomerId == "FAIL_TEST" {
-				c.logger.Warn("deliberately failing payment for DLQ test",
-					slog.String("customer_id", o.CustomerId),
-					slog.String("order_id", o.Id),
+	// Warum select statt for d := range msgs?
+	// → range blockiert bis RabbitMQ den Channel schließt → Shutdown hatte keinen Weg den Consumer zu stoppen
+	// → ctx cancelled → keine NEUE Message mehr annehmen, Listen returnt
+	// → Die laufende Message (Stripe Call!) wird vorher noch zu Ende verarbeitet (handle läuft synchron)
+	// → Danach stop(): Delivery beenden + schon zugestellte Messages zurück in die Queue
+	for {
+		select {
+		case <-ctx.Done():
+			c.stop(ch, msgs)
+			c.logger.Info("payment consumer stopped",
+				slog.String("queue", broker.OrderCreatedEvent),
+			)
+			return
+		case d, ok := <-msgs:
+			if !ok {
+				c.logger.Warn("delivery channel closed",
+					slog.String("queue", broker.OrderCreatedEvent),
 				)
-				// Warum HandleRetry + Nack?
-				// → HandleRetry: Manages retry logic and DLQ routing
-				// → Nack: Acknowledges this delivery
-				if err := broker.HandleRetry(ch, &d); err != nil {
-					c.logger.Error("error handling retry", slog.Any("error", err))
-				}
-				d.Nack(false, false)
-				span.End() // ⭐ End span before continue!
-				continue
+				return
 			}
-
-			// Warum service.CreatePayment?
-			// → Business Logic: Erstellt Stripe Payment Link
-			// → Siehe service.go für Details
-			// → Bekommt ctx mit Trace Context (für weitere Propagation!)
-			paymentLink, err := c.service.CreatePayment(ctx, o)
-			if err != nil {
-				c.logger.Error("failed to create payment", slog.Any("error", err))
-				// Warum HandleRetry bei Payment Failure?
-				// → Stripe API down? → Retry up to 3 times with backoff
-				// → After 3 retries → DLQ for manual investigation
-				// → Invalid Data? → Will fail 3 times → DLQ for debugging
-				if err := broker.HandleRetry(ch, &d); err != nil {
-					c.logger.Error("error handling retry", slog.Any("error", err))
+			// Warum ctx nochmal prüfen?
+			// → select wählt zufällig wenn ctx UND eine Message bereit sind → sonst startet nach Shutdown noch ein Stripe Call
+			if ctx.Err() != nil {
+				if err := d.Nack(false, true); err != nil {
+					c.logger.Warn("failed to requeue delivery", slog.Any("error", err))
 				}
-				d.Nack(false, false)
-				span.End() // ⭐ End span before continue!
 				continue
 			}
+			broker.Process(c.metrics, ch, &d, broker.OrderCreatedEvent, func() broker.Outcome {
+				return c.handle(ch, d)
+			})
+		}
+	}
+}
 
-			// ✅ SUCCESS: Payment Link erstellt!
-			// Warum d.Ack?
-			// → Bestätigt RabbitMQ: "Message erfolgreich verarbeitet"
-			// → Message wird aus Queue GELÖSCHT
-			// → Arg (multiple=false): Nur DIESE Message acknowledgen
-			d.Ack(false)
+// stop: Consumer beim Server abmelden und bereits zugestellte Deliveries zurückgeben
+// Warum ch.Cancel statt einfach returnen?
+// → Ohne Cancel liefert RabbitMQ weiter an den Channel → Messages hängen bis zum Channel Close
+// → Nach Cancel-Ok schließt amqp091 den msgs Channel → range endet sobald der Puffer leer ist
+// Warum Requeue statt verarbeiten?
+// → Shutdown läuft: keine neuen Stripe Calls mehr starten → eine andere Instanz übernimmt
+// → Ack/Nack passiert hier, also VOR dem Channel Close in App.Shutdown
+func (c *consumer) stop(ch cancelableChannel, msgs <-chan amqp.Delivery) {
+	if err := ch.Cancel(orderCreatedConsumerTag, false); err != nil {
+		c.logger.Warn("failed to cancel consumer", slog.Any("error", err))
+		return
+	}
 
-			c.logger.Info("payment link created",
-				slog.String("payment_link", paymentLink),
-				slog.String("order_id", o.Id),
-			)
+	requeued := 0
+	for d := range msgs {
+		if err := d.Nack(false, true); err != nil {
+			c.logger.Warn("failed to requeue delivery", slog.Any("error", err))
+			continue
+		}
+		requeued++
+	}
+	if requeued > 0 {
+		c.logger.Info("requeued outstanding deliveries",
+			slog.String("queue", broker.OrderCreatedEvent),
+			slog.Int("count", requeued),
+		)
+	}
+}
 
-			// ⭐ End span after successful processing
+// handle: Verarbeitet EINE order.created Delivery (Ack/Nack passiert hier drin)
+// → d = Delivery (RabbitMQ Message mit Body, Headers, etc.)
+// Returns: Outcome für die Processing Metrik (broker.Process misst die Dauer)
+func (c *consumer) handle(ch broker.Channel, d amqp.Delivery) broker.Outcome {
+	// ⭐ OpenTelemetry: Extract trace context from AMQP headers FIRST
+	// → Must be done before any processing to continue distributed trace
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+
+	// ⭐ OpenTelemetry: Start span for message processing
+	// → This span represents the consumer processing the message
+	// → Will be visible in Jaeger as "AMQP - consume - order.created"
+	tracer := otel.Tracer("payment")
+	ctx, span := tracer.Start(ctx, "AMQP - consume - order.created")
+	telemetry.TagSpan(ctx) // customer.id aus der Baggage
+
+	// Zu groß? → DLQ ohne Unmarshal (Body wird NICHT geloggt)
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderCreatedEvent) {
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	// Schon verarbeitet? → sonst ZWEITE Stripe Session für dieselbe Order
+	if c.dedup.Duplicate(&d) {
+		span.End()
+		return broker.OutcomeDuplicate
+	}
+
+	c.logger.Info("received message",
+		slog.String("body", string(d.Body)),
+	)
+
+	// Warum json.Unmarshal?
+	// → d.Body ist []byte (JSON)
+	// → Konvertiert zurück zu *pb.Order struct
+	// → GLEICHE Order die Orders Service published hat!
+	o := &pb.Order{}
+	if err := json.Unmarshal(d.Body, o); err != nil {
+		c.logger.Error("failed to unmarshal order", slog.Any("error", err))
+		// Warum HandleRetry?
+		// → Smart retry: Will retry up to 3 times
+		// → After 3 retries → sends to DLQ
+		if err := broker.HandleRetry(ch, &d, broker.OrderCreatedEvent, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End() // ⭐ End span before return!
+		return broker.OutcomeRetry
+	}
+
+	// 🧪 TEST: Deliberately fail payments for testing DLQ
+	// Warum dieser Test?
+	// → Zum Testen ob DLQ funktioniert!
+	// → Order mit CustomerID "FAIL_TEST" → wird 3x retried → dann DLQ
+	// → In RabbitMQ UI: Message sollte nach 3 retries in "dlq_main" erscheinen
+	if o.CustomerId == "FAIL_TEST" {
+		c.logger.Warn("deliberately failing payment for DLQ test",
+			slog.String("customer_id", o.CustomerId),
+			slog.String("order_id", o.Id),
+		)
+		// Warum HandleRetry?
+		// → Manages retry logic and DLQ routing (settled die Delivery selbst)
+		if err := broker.HandleRetry(ch, &d, broker.OrderCreatedEvent, errors.New("deliberate DLQ test failure (FAIL_TEST customer)")); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End() // ⭐ End span before return!
+		return broker.OutcomeRetry
+	}
+
+	// Warum Session Orders überspringen?
+	// → Tisch/Session zahlt gemeinsam (POST /sessions/{id}/payment-link)
+	// → Einzel-Link + kombinierter Link = Kunde könnte doppelt zahlen!
+	if o.SessionId != "" {
+		c.logger.Info("order belongs to a session, skipping individual payment link",
+			slog.String("order_id", o.Id),
+			slog.String("session_id", o.SessionId),
+		)
+		d.Ack(false)
+		span.End()
+		return broker.OutcomeSuccess
+	}
+
+	// Warum service.CreatePayment?
+	// → Business Logic: Erstellt Stripe Payment Link
+	// → Siehe service.go für Details
+	// → Bekommt ctx mit Trace Context (für weitere Propagation!)
+	paymentLink, err := c.service.CreatePayment(ctx, o)
+	if errors.Is(err, ErrAlreadyPaid) {
+		// Redelivery nach der Zahlung → nichts mehr zu tun, KEIN zweiter Link
+		c.logger.Info("order already paid, skipping payment link", slog.String("order_id", o.Id))
+		d.Ack(false)
+		span.End()
+		return broker.OutcomeSuccess
+	}
+	if errors.Is(err, ErrInvalidOrder) {
+		// Permanenter Fehler (z.B. Order ohne Items) → Retry bringt nichts → sofort in die DLQ, mit Grund
+		c.logger.Error("order can never be paid, dead-lettering",
+			slog.String("order_id", o.Id),
+			slog.Any("error", err),
+		)
+		if err := broker.DeadLetter(ctx, ch, &d, broker.OrderCreatedEvent, err.Error()); err != nil {
+			c.logger.Error("failed to dead-letter order", slog.Any("error", err))
+		}
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+	if err != nil {
+		c.logger.Error("failed to create payment", slog.Any("error", err))
+		// Stripe 429? → eigener Backoff, zählt NICHT gegen die 3 Retries → sonst landen gesunde Orders in der DLQ
+		if retryRateLimited(ch, &d, broker.OrderCreatedEvent, err, c.logger) {
 			span.End()
+			return broker.OutcomeRetry
+		}
+		// Warum HandleRetry bei Payment Failure?
+		// → Stripe API down? → Retry up to 3 times with backoff
+		// → After 3 retries → DLQ for manual investigation
+		// → Invalid Data? → Will fail 3 times → DLQ for debugging
+		if err := broker.HandleRetry(ch, &d, broker.OrderCreatedEvent, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
 		}
-	}()
+		span.End() // ⭐ End span before return!
+		return broker.OutcomeRetry
+	}
 
-	c.logger.Info("waiting for messages...",
-		slog.String("queue", broker.OrderCreatedEvent),
+	// ✅ SUCCESS: Payment Link erstellt!
+	// Warum d.Ack?
+	// → Bestätigt RabbitMQ: "Message erfolgreich verarbeitet"
+	// → Message wird aus Queue GELÖSCHT
+	// → Arg (multiple=false): Nur DIESE Message acknowledgen
+	d.Ack(false)
+	c.dedup.Done(&d)
+
+	c.logger.Info("payment link created",
+		slog.String("payment_link", paymentLink),
+		slog.String("order_id", o.Id),
 	)
 
-	// Warum <-forever?
-	// → Blockiert EWIG (forever = nil Channel)
-	// → Listen() returnt NIE (Consumer läuft bis Process killed wird)
-	// → Wichtig: Sonst würde Listen() sofort returnen!
-	<-forever
+	// ⭐ End span after successful processing
+	span.End()
+	return broker.OutcomeSuccess
 }
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stripe/stripe-go/v78"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"github.com/timour/order-microservices/common/config"
+)
+
+// blockingPayments: CreatePayment hängt bis release → simuliert einen laufenden Stripe Call
+type blockingPayments struct {
+	PaymentService
+	started chan string
+	release chan struct{}
+
+	mu      sync.Mutex
+	created []string
+}
+
+func (p *blockingPayments) CreatePayment(_ context.Context, o *pb.Order) (string, error) {
+	p.started <- o.Id
+	<-p.release
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.created = append(p.created, o.Id)
+	return "https://pay.example/" + o.Id, nil
+}
+
+func (p *blockingPayments) Created() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.created...)
+}
+
+func publishOrderCreated(t *testing.T, b *brokertest.Broker, id string) {
+	t.Helper()
+	body, _ := json.Marshal(&pb.Order{Id: id, CustomerId: "c1", Items: []*pb.Item{{ID: "1", Quantity: 1}}})
+	if err := b.PublishWithContext(context.Background(), "", broker.OrderCreatedEvent, false, false, amqp.Publishing{Body: body}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Shutdown: ctx cancel → laufender Stripe Call wird fertig + ge-ackt, wartende Message zurück in die Queue
+// → Listen returnt BEVOR der Broker geschlossen wird
+func TestConsumerDrainsOnContextCancel(t *testing.T) {
+	payments := &blockingPayments{started: make(chan string, 2), release: make(chan struct{})}
+	b := brokertest.New()
+	t.Cleanup(func() { b.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	c := NewConsumer(payments, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	go func() {
+		c.Listen(ctx, b)
+		close(done)
+	}()
+
+	if err := b.WaitForQueue(broker.OrderCreatedEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	publishOrderCreated(t, b, "o1")
+
+	select {
+	case <-payments.started:
+	case <-time.After(time.Second):
+		t.Fatal("CreatePayment for o1 never started")
+	}
+
+	// Shutdown während o1 bei Stripe hängt, o2 ist schon zugestellt
+	cancel()
+	publishOrderCreated(t, b, "o2")
+
+	select {
+	case <-done:
+		t.Fatal("Listen returned before the in-flight payment finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(payments.release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Listen did not return after context cancel")
+	}
+
+	if got := payments.Created(); len(got) != 1 || got[0] != "o1" {
+		t.Fatalf("payments created = %v; want only o1", got)
+	}
+	if n := len(b.Acked()); n != 1 {
+		t.Errorf("acked = %d; want 1 (o1)", n)
+	}
+
+	// o2 wurde nicht verarbeitet, sondern requeued → nächste Instanz übernimmt
+	d, ok, err := b.Get(broker.OrderCreatedEvent, false)
+	if err != nil || !ok {
+		t.Fatalf("Get = %v, %v; want o2 back in the queue", ok, err)
+	}
+	var o pb.Order
+	if err := json.Unmarshal(d.Body, &o); err != nil || o.Id != "o2" {
+		t.Fatalf("requeued order = %q (%v); want o2", o.Id, err)
+	}
+}
+
+// Idle Consumer → cancel reicht, kein Broker Close nötig
+func TestConsumerStopsWhenIdle(t *testing.T) {
+	b := brokertest.New()
+	t.Cleanup(func() { b.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c := NewConsumer(&blockingPayments{}, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	go func() {
+		c.Listen(ctx, b)
+		close(done)
+	}()
+	if err := b.WaitForQueue(broker.OrderCreatedEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Listen did not return after context cancel")
+	}
+}
+
+// Order ohne Items → sofort in die DLQ (mit Grund), kein Retry und kein Stripe Call
+func TestConsumerDeadLettersOrderWithoutItems(t *testing.T) {
+	s, log := newTestService(config.ReservationOnPay, nil)
+	b := brokertest.New()
+	t.Cleanup(func() { b.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewConsumer(s, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Listen(ctx, b)
+	if err := b.WaitForQueue(broker.OrderCreatedEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&pb.Order{Id: "o1", CustomerId: "c1"})
+	if err := b.PublishWithContext(context.Background(), "", broker.OrderCreatedEvent, false, false, amqp.Publishing{Body: body}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := log.Calls(); len(got) != 0 {
+		t.Fatalf("calls = %v; want no reservation or Stripe session", got)
+	}
+	var deadLettered int
+	for _, m := range b.Published() {
+		switch {
+		case m.Exchange == broker.DLX && m.RoutingKey == broker.OrderCreatedEvent:
+			deadLettered++
+			if reason, _ := m.Publishing.Headers[broker.HeaderDeadLetterReason].(string); !strings.Contains(reason, "no items") {
+				t.Errorf("dead-letter reason = %q; want it to mention the missing items", reason)
+			}
+		case m.Exchange == "" && m.RoutingKey == broker.OrderCreatedEvent:
+			// Unser eigener Publish oben
+		default:
+			t.Errorf("unexpected publish %s/%s; want no retry", m.Exchange, m.RoutingKey)
+		}
+	}
+	if deadLettered != 1 {
+		t.Fatalf("dead-lettered = %d; want 1", deadLettered)
+	}
+}
+
+// rateLimitedPayments: Stripe antwortet mit 429 (Retry-After 30s)
+type rateLimitedPayments struct {
+	PaymentService
+}
+
+func (rateLimitedPayments) CreatePayment(context.Context, *pb.Order) (string, error) {
+	err := &stripe.Error{HTTPStatusCode: http.StatusTooManyRequests, Code: stripe.ErrorCodeRateLimit}
+	err.LastResponse = &stripe.APIResponse{Header: http.Header{"Retry-After": {"30"}}}
+	return "", fmt.Errorf("failed to create payment link: %w", err)
+}
+
+// Stripe 429 → Rate Limit Backoff (TTL Queue mit Retry-After), KEIN normaler Retry und keine DLQ
+func TestConsumerBacksOffOnStripeRateLimit(t *testing.T) {
+	b := brokertest.New()
+	t.Cleanup(func() { b.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewConsumer(rateLimitedPayments{}, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Listen(ctx, b)
+	if err := b.WaitForQueue(broker.OrderCreatedEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	publishOrderCreated(t, b, "o1")
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	backoffQueue := broker.RateLimitQueueName(broker.OrderCreatedEvent, 1)
+	var backedOff int
+	for _, m := range b.Published() {
+		switch {
+		case m.Exchange == "" && m.RoutingKey == backoffQueue:
+			backedOff++
+			if m.Publishing.Expiration != "30000" {
+				t.Errorf("Expiration = %q; want Retry-After (30000)", m.Publishing.Expiration)
+			}
+			if n, _ := m.Publishing.Headers["x-retry-count"].(int64); n != 0 {
+				t.Errorf("x-retry-count = %d; want 0 (rate limits don't spend the retry budget)", n)
+			}
+		case m.Exchange == "" && m.RoutingKey == broker.OrderCreatedEvent:
+			// Unser eigener Publish oben
+		default:
+			t.Errorf("unexpected publish %s/%s; want only the rate limit backoff", m.Exchange, m.RoutingKey)
+		}
+	}
+	if backedOff != 1 {
+		t.Fatalf("rate limit backoffs = %d; want 1", backedOff)
+	}
+	if n := len(b.Nacked()); n != 0 {
+		t.Errorf("nacked = %d; want 0", n)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/telemetry"
+)
+
+// expiredConsumer: Konsumiert "order.expired" → offene Stripe Session der Order schließen
+// Warum?
+// → Stripe Session lebt mind. 30 Minuten, die Order läuft nach 15 ab → sonst zahlt der Kunde für eine tote Order
+// Eigene Queue (order.expired.payments) → Stock bekommt weiterhin JEDES order.expired
+type expiredConsumer struct {
+	service PaymentService
+	dedup   *broker.Deduplicator
+	metrics *metrics.ConsumerMetrics // nil = aus
+	logger  *slog.Logger
+}
+
+func NewExpiredConsumer(service PaymentService, dedup *broker.Deduplicator, consumerMetrics *metrics.ConsumerMetrics, logger *slog.Logger) *expiredConsumer {
+	return &expiredConsumer{
+		service: service,
+		dedup:   dedup,
+		metrics: consumerMetrics,
+		logger:  logger,
+	}
+}
+
+// Listen: Blockiert solange der Delivery Channel offen ist
+func (c *expiredConsumer) Listen(ch broker.Channel) {
+	q, err := ch.QueueDeclare(
+		broker.PaymentsOrderExpiredQueue, // queue name: "order.expired.payments"
+		true,                             // durable
+		false,                            // delete when unused
+		false,                            // exclusive
+		false,                            // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange": broker.DLX, // → order.expired.payments.dlq
+		},
+	)
+	if err != nil {
+		c.logger.Error("failed to declare queue", slog.Any("error", err))
+		return
+	}
+
+	err = ch.QueueBind(
+		q.Name,                   // queue name: "order.expired.payments"
+		"",                       // routing key: "" = matches all
+		broker.OrderExpiredEvent, // exchange name: "order.expired"
+		false,                    // no-wait
+		nil,                      // arguments
+	)
+	if err != nil {
+		c.logger.Error("failed to bind queue to exchange", slog.Any("error", err))
+		return
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		c.logger.Error("failed to start consuming", slog.Any("error", err))
+		return
+	}
+
+	c.logger.Info("expired consumer started",
+		slog.String("queue", broker.PaymentsOrderExpiredQueue),
+	)
+
+	for d := range msgs {
+		broker.Process(c.metrics, ch, &d, broker.PaymentsOrderExpiredQueue, func() broker.Outcome {
+			return c.handle(ch, d)
+		})
+	}
+}
+
+// handle: Verarbeitet EINE order.expired Delivery (Ack/Nack passiert hier drin)
+func (c *expiredConsumer) handle(ch broker.Channel, d amqp.Delivery) broker.Outcome {
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+	ctx, span := otel.Tracer("payment").Start(ctx, "AMQP - consume - "+broker.OrderExpiredEvent)
+	telemetry.TagSpan(ctx)
+
+	if broker.RejectOversized(ctx, ch, &d, broker.PaymentsOrderExpiredQueue) {
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+	if c.dedup.Duplicate(&d) {
+		span.End()
+		return broker.OutcomeDuplicate
+	}
+
+	order := &pb.Order{}
+	if err := json.Unmarshal(d.Body, order); err != nil {
+		c.logger.Error("failed to unmarshal expired order", slog.Any("error", err))
+		d.Nack(false, false)
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	if err := c.service.ExpireCheckout(ctx, order); err != nil {
+		c.logger.Error("failed to expire checkout of expired order",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		if errors.Is(err, ErrInvalidOrder) {
+			if err := broker.DeadLetter(ctx, ch, &d, broker.PaymentsOrderExpiredQueue, err.Error()); err != nil {
+				c.logger.Error("failed to dead-letter expired order", slog.Any("error", err))
+			}
+			span.End()
+			return broker.OutcomeDeadLetter
+		}
+		if retryRateLimited(ch, &d, broker.PaymentsOrderExpiredQueue, err, c.logger) {
+			span.End()
+			return broker.OutcomeRetry
+		}
+		if err := broker.HandleRetry(ch, &d, broker.PaymentsOrderExpiredQueue, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End()
+		return broker.OutcomeRetry
+	}
+
+	d.Ack(false)
+	c.dedup.Done(&d)
+
+	c.logger.Info("checkout of expired order closed", slog.String("order_id", order.Id))
+	span.End()
+	return broker.OutcomeSuccess
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+// fakeExpirations: PaymentService, nur ExpireCheckout wird vom expiredConsumer genutzt
+type fakeExpirations struct {
+	PaymentService
+
+	mu      sync.Mutex
+	expired []string
+}
+
+func (f *fakeExpirations) ExpireCheckout(_ context.Context, o *pb.Order) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expired = append(f.expired, o.Id)
+	return nil
+}
+
+// Eigene Queue am Exchange "order.expired" → Payments bekommt das Event zusätzlich zu Stock
+func TestExpiredConsumerReceivesExchangePublish(t *testing.T) {
+	b := brokertest.New()
+	service := &fakeExpirations{}
+	c := NewExpiredConsumer(service, broker.NewDeduplicator(100, time.Minute), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	go c.Listen(b)
+	defer b.Close()
+
+	if err := b.WaitForBinding(broker.PaymentsOrderExpiredQueue, broker.OrderExpiredEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&pb.Order{Id: "o1", Status: "expired"})
+	err := b.PublishWithContext(context.Background(), broker.OrderExpiredEvent, "", false, false, amqp.Publishing{
+		MessageId: broker.MessageID(broker.OrderExpiredEvent, "o1"),
+		Body:      body,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if len(service.expired) != 1 || service.expired[0] != "o1" {
+		t.Fatalf("expired = %v; want [o1]", service.expired)
+	}
+}
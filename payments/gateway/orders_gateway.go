@@ -3,32 +3,50 @@ package gateway
 import (
 	"context"
 	"log"
+	"time"
 
 	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 type OrdersGateway interface {
-	UpdateOrderAfterPaymentLink(ctx context.Context, orderID, paymentLink string) error
+	// expiresAt: Ablauf der Stripe Session (Zero = unbekannt, wird nicht gespeichert)
+	UpdateOrderAfterPaymentLink(ctx context.Context, orderID, paymentLink string, expiresAt time.Time) error
 	UpdateOrderStatus(ctx context.Context, orderID, customerID, status string) error
+	// GetOrder: Order so wie Orders sie gespeichert hat (Items, Status) → nie dem Caller glauben
+	GetOrder(ctx context.Context, orderID string) (*pb.Order, error)
+	// GetOrdersBySession: Alle Orders eines Tisches (Session Checkout)
+	GetOrdersBySession(ctx context.Context, sessionID string) ([]*pb.Order, error)
 }
 
 type ordersGateway struct {
 	ordersAddr string
+	upstream   *metrics.UpstreamMetrics // Latenz + Fehlerrate der Calls zum Orders Service (nil = aus)
 }
 
-func NewOrdersGateway(ordersAddr string) OrdersGateway {
+func NewOrdersGateway(ordersAddr string, upstream *metrics.UpstreamMetrics) OrdersGateway {
 	return &ordersGateway{
 		ordersAddr: ordersAddr,
+		upstream:   upstream,
 	}
 }
 
+// dial: Connection zum Orders Service inkl. Upstream Metrics Interceptor
+func (g *ordersGateway) dial() (*grpc.ClientConn, error) {
+	return grpc.NewClient(g.ordersAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(g.upstream.UnaryClientInterceptor("orders")),
+	)
+}
+
 // UpdateOrderAfterPaymentLink updates the order with payment link and status "waiting_payment"
 // This is called after Stripe checkout session is created
-func (g *ordersGateway) UpdateOrderAfterPaymentLink(ctx context.Context, orderID, paymentLink string) error {
+func (g *ordersGateway) UpdateOrderAfterPaymentLink(ctx context.Context, orderID, paymentLink string, expiresAt time.Time) error {
 	// Connect to Orders service via gRPC
-	conn, err := grpc.NewClient(g.ordersAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := g.dial()
 	if err != nil {
 		return err
 	}
@@ -38,9 +56,10 @@ func (g *ordersGateway) UpdateOrderAfterPaymentLink(ctx context.Context, orderID
 
 	// Update order with payment link and new status
 	_, err = ordersClient.UpdateOrder(ctx, &pb.Order{
-		Id:          orderID,
-		Status:      "waiting_payment", // Status changes from "pending" to "waiting_payment"
-		PaymentLink: paymentLink,
+		Id:                   orderID,
+		Status:               orderstatus.StatusWaitingPayment, // Status changes from "pending" to "waiting_payment"
+		PaymentLink:          paymentLink,
+		PaymentLinkExpiresAt: FormatExpiresAt(expiresAt),
 	})
 	if err != nil {
 		log.Printf("Failed to update order via gRPC: %v", err)
@@ -51,11 +70,19 @@ func (g *ordersGateway) UpdateOrderAfterPaymentLink(ctx context.Context, orderID
 	return nil
 }
 
+// FormatExpiresAt: time → RFC3339 (UTC) wie reservation_expires_at, Zero → "" (Feld bleibt leer)
+func FormatExpiresAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 // UpdateOrderStatus updates the order status after payment
 // This is called by the webhook handler when Stripe payment succeeds
 func (g *ordersGateway) UpdateOrderStatus(ctx context.Context, orderID, customerID, status string) error {
 	// Connect to Orders service via gRPC
-	conn, err := grpc.NewClient(g.ordersAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := g.dial()
 	if err != nil {
 		return err
 	}
@@ -77,3 +104,30 @@ func (g *ordersGateway) UpdateOrderStatus(ctx context.Context, orderID, customer
 	log.Printf("Order %s updated to status '%s' via gRPC", orderID, status)
 	return nil
 }
+
+// GetOrder loads the order from the Orders service
+// This is called by the payment link endpoint → Items und Status kommen aus Orders, nicht aus dem Request
+func (g *ordersGateway) GetOrder(ctx context.Context, orderID string) (*pb.Order, error) {
+	conn, err := g.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return pb.NewOrderServiceClient(conn).GetOrder(ctx, &pb.GetOrderRequest{OrderId: orderID})
+}
+
+// GetOrdersBySession loads all orders of a dine-in session from the Orders service
+func (g *ordersGateway) GetOrdersBySession(ctx context.Context, sessionID string) ([]*pb.Order, error) {
+	conn, err := g.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := pb.NewOrderServiceClient(conn).GetOrdersBySession(ctx, &pb.GetOrdersBySessionRequest{SessionId: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Orders, nil
+}
@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultUpdateAttempts: gRPC Versuche bevor der Payment Link als Event rausgeht
+const DefaultUpdateAttempts = 3
+
+// defaultRetryBackoff: Wartezeit vor dem 2. Versuch (verdoppelt sich pro Versuch)
+const defaultRetryBackoff = 200 * time.Millisecond
+
+// PaymentLinkPublisher: Fallback wenn Orders per gRPC nicht erreichbar ist
+type PaymentLinkPublisher interface {
+	PublishPaymentLink(ctx context.Context, orderID, paymentLink string, expiresAt time.Time) error
+}
+
+// retryingOrdersGateway: Decorator um OrdersGateway
+// Warum?
+// → Orders kurz down (Deploy/Restart) → CreatePayment schlug komplett fehl
+// → order.created wurde retried → JEDES Mal eine neue Stripe Session
+// → Jetzt: Transiente Fehler retryen, danach Payment Link als Event → Orders übernimmt ihn später
+type retryingOrdersGateway struct {
+	OrdersGateway
+	fallback PaymentLinkPublisher
+	attempts int
+	backoff  time.Duration
+	sleep    func(context.Context, time.Duration) error // Injizierbar → Tests ohne Sleep
+}
+
+// NewRetryingOrdersGateway: attempts <= 0 → DefaultUpdateAttempts
+// fallback darf nil sein → nach dem letzten Versuch wird der Fehler zurückgegeben
+func NewRetryingOrdersGateway(inner OrdersGateway, fallback PaymentLinkPublisher, attempts int) OrdersGateway {
+	if attempts <= 0 {
+		attempts = DefaultUpdateAttempts
+	}
+	return &retryingOrdersGateway{
+		OrdersGateway: inner,
+		fallback:      fallback,
+		attempts:      attempts,
+		backoff:       defaultRetryBackoff,
+		sleep:         sleepContext,
+	}
+}
+
+// UpdateOrderAfterPaymentLink: Retry bei transienten gRPC Fehlern, dann Event Fallback
+// → UpdateOrderStatus (Webhook) bleibt unverändert: Stripe retried den Webhook selbst
+func (g *retryingOrdersGateway) UpdateOrderAfterPaymentLink(ctx context.Context, orderID, paymentLink string, expiresAt time.Time) error {
+	var err error
+	backoff := g.backoff
+
+	for attempt := 1; attempt <= g.attempts; attempt++ {
+		err = g.OrdersGateway.UpdateOrderAfterPaymentLink(ctx, orderID, paymentLink, expiresAt)
+		if err == nil {
+			return nil
+		}
+		if !isTransient(err) {
+			// z.B. InvalidArgument/NotFound → Retry und Event würden genauso scheitern
+			return err
+		}
+
+		log.Printf("Transient error updating order %s (attempt %d/%d): %v", orderID, attempt, g.attempts, err)
+		if attempt == g.attempts {
+			break
+		}
+		if sleepErr := g.sleep(ctx, backoff); sleepErr != nil {
+			break
+		}
+		backoff *= 2
+	}
+
+	if g.fallback == nil {
+		return err
+	}
+
+	// ⭐ Stripe Session existiert bereits → Link NICHT wegwerfen, Orders bekommt ihn per Event
+	if pubErr := g.fallback.PublishPaymentLink(ctx, orderID, paymentLink, expiresAt); pubErr != nil {
+		return fmt.Errorf("orders unavailable (%v) and fallback event failed: %w", err, pubErr)
+	}
+
+	log.Printf("Orders unavailable, published payment link for order %s as %s event", orderID, broker.OrderPaymentLinkEvent)
+	return nil
+}
+
+// isTransient: Fehler bei denen ein späterer Versuch Erfolg haben kann
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// eventPaymentLinkPublisher: Publiziert order.payment_link für den Orders Consumer
+type eventPaymentLinkPublisher struct {
+	channel broker.Channel
+}
+
+func NewPaymentLinkPublisher(channel broker.Channel) PaymentLinkPublisher {
+	return &eventPaymentLinkPublisher{channel: channel}
+}
+
+func (p *eventPaymentLinkPublisher) PublishPaymentLink(ctx context.Context, orderID, paymentLink string, expiresAt time.Time) error {
+	// Warum QueueDeclare vor dem Publish?
+	// → Orders ist gerade down → hat die Queue evtl. noch nie angelegt → Event ginge verloren
+	_, err := p.channel.QueueDeclare(broker.OrderPaymentLinkEvent, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange": broker.DLX,
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(&pb.Order{
+		Id:                   orderID,
+		Status:               orderstatus.StatusWaitingPayment,
+		PaymentLink:          paymentLink,
+		PaymentLinkExpiresAt: FormatExpiresAt(expiresAt),
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.channel.PublishWithContext(ctx,
+		"",                           // default exchange
+		broker.OrderPaymentLinkEvent, // routing key = queue name
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+			Headers:      broker.InjectTraceContext(ctx),
+			MessageId:    paymentLink, // Link ist pro Stripe Session eindeutig → neuer Link = neues Event
+		},
+	)
+}
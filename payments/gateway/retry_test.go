@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeOrdersGateway: Liefert die Fehler aus errs der Reihe nach (danach nil)
+type fakeOrdersGateway struct {
+	OrdersGateway
+	errs  []error
+	calls int
+}
+
+func (f *fakeOrdersGateway) UpdateOrderAfterPaymentLink(context.Context, string, string, time.Time) error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+// fakePublisher: Merkt sich Fallback Events, err != nil → Publish schlägt fehl
+type fakePublisher struct {
+	err       error
+	published []string
+}
+
+func (f *fakePublisher) PublishPaymentLink(_ context.Context, orderID, paymentLink string, _ time.Time) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published = append(f.published, orderID+" "+paymentLink)
+	return nil
+}
+
+// newTestRetryGateway: Sleep wird nur aufgezeichnet → Backoff prüfbar, kein echtes Warten
+func newTestRetryGateway(inner OrdersGateway, fallback PaymentLinkPublisher) (*retryingOrdersGateway, *[]time.Duration) {
+	var sleeps []time.Duration
+	g := NewRetryingOrdersGateway(inner, fallback, 3).(*retryingOrdersGateway)
+	g.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return g, &sleeps
+}
+
+var errOrdersDown = status.Error(codes.Unavailable, "orders down")
+
+func TestUpdateOrderAfterPaymentLinkRetriesTransientErrors(t *testing.T) {
+	inner := &fakeOrdersGateway{errs: []error{errOrdersDown, errOrdersDown}}
+	fallback := &fakePublisher{}
+	g, sleeps := newTestRetryGateway(inner, fallback)
+
+	if err := g.UpdateOrderAfterPaymentLink(context.Background(), "o1", "https://pay/o1", time.Time{}); err != nil {
+		t.Fatalf("UpdateOrderAfterPaymentLink: %v", err)
+	}
+	if inner.calls != 3 {
+		t.Fatalf("calls = %d; want 3 (third succeeds)", inner.calls)
+	}
+	if want := []time.Duration{defaultRetryBackoff, 2 * defaultRetryBackoff}; !slices.Equal(*sleeps, want) {
+		t.Errorf("sleeps = %v; want %v", *sleeps, want)
+	}
+	if len(fallback.published) != 0 {
+		t.Errorf("fallback published %v; want nothing after a successful retry", fallback.published)
+	}
+}
+
+func TestUpdateOrderAfterPaymentLinkFallsBackToEvent(t *testing.T) {
+	inner := &fakeOrdersGateway{errs: []error{errOrdersDown, errOrdersDown, errOrdersDown}}
+	fallback := &fakePublisher{}
+	g, _ := newTestRetryGateway(inner, fallback)
+
+	if err := g.UpdateOrderAfterPaymentLink(context.Background(), "o1", "https://pay/o1", time.Time{}); err != nil {
+		t.Fatalf("UpdateOrderAfterPaymentLink: %v; want nil (link handed over as event)", err)
+	}
+	if inner.calls != 3 {
+		t.Fatalf("calls = %d; want 3", inner.calls)
+	}
+	if !slices.Equal(fallback.published, []string{"o1 https://pay/o1"}) {
+		t.Fatalf("fallback published %v; want the payment link of o1", fallback.published)
+	}
+}
+
+// Fallback kaputt → Fehler nach oben, damit order.created retried wird
+func TestUpdateOrderAfterPaymentLinkFallbackFails(t *testing.T) {
+	inner := &fakeOrdersGateway{errs: []error{errOrdersDown, errOrdersDown, errOrdersDown}}
+	g, _ := newTestRetryGateway(inner, &fakePublisher{err: errors.New("amqp closed")})
+
+	if err := g.UpdateOrderAfterPaymentLink(context.Background(), "o1", "https://pay/o1", time.Time{}); err == nil {
+		t.Fatal("UpdateOrderAfterPaymentLink succeeded although orders and fallback failed")
+	}
+}
+
+// Nicht transient (z.B. InvalidArgument) → kein Retry, kein Event
+func TestUpdateOrderAfterPaymentLinkPermanentError(t *testing.T) {
+	inner := &fakeOrdersGateway{errs: []error{status.Error(codes.InvalidArgument, "bad order")}}
+	fallback := &fakePublisher{}
+	g, sleeps := newTestRetryGateway(inner, fallback)
+
+	err := g.UpdateOrderAfterPaymentLink(context.Background(), "o1", "https://pay/o1", time.Time{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("error = %v; want InvalidArgument", err)
+	}
+	if inner.calls != 1 || len(*sleeps) != 0 || len(fallback.published) != 0 {
+		t.Fatalf("calls/sleeps/events = %d/%d/%d; want 1/0/0", inner.calls, len(*sleeps), len(fallback.published))
+	}
+}
+
+// Event Fallback: order.payment_link mit Link + waiting_payment in der Queue
+func TestPaymentLinkPublisherPublishesEvent(t *testing.T) {
+	b := brokertest.New()
+	expiresAt := time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	if err := NewPaymentLinkPublisher(b).PublishPaymentLink(context.Background(), "o1", "https://pay/o1", expiresAt); err != nil {
+		t.Fatalf("PublishPaymentLink: %v", err)
+	}
+
+	if args := b.QueueArgs(broker.OrderPaymentLinkEvent); args["x-dead-letter-exchange"] != broker.DLX {
+		t.Errorf("queue args = %v; want dead letter exchange %s", args, broker.DLX)
+	}
+	published := b.Published()
+	if len(published) != 1 || published[0].RoutingKey != broker.OrderPaymentLinkEvent {
+		t.Fatalf("published = %+v; want one %s event", published, broker.OrderPaymentLinkEvent)
+	}
+
+	var o pb.Order
+	if err := json.Unmarshal(published[0].Publishing.Body, &o); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if o.Id != "o1" || o.PaymentLink != "https://pay/o1" || o.Status != "waiting_payment" || o.PaymentLinkExpiresAt != FormatExpiresAt(expiresAt) {
+		t.Fatalf("event = %+v; want o1 waiting_payment with link and expiry", &o)
+	}
+}
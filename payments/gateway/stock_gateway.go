@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"context"
+	"log"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/discovery"
+	"google.golang.org/grpc"
+)
+
+// StockGateway: Reservation aus dem Payments Flow (RESERVATION_MODE=on_pay + Webhook)
+type StockGateway interface {
+	// ReserveForOrder: Reserviert den Stock der Order (oder verlängert eine bestehende Reservation)
+	// Returns: Ablaufzeit der Reservation (RFC3339)
+	ReserveForOrder(ctx context.Context, order *pb.Order) (string, error)
+
+	// ConfirmReservation: Reservation der bezahlten Order bestätigen (idempotent im Stock Service)
+	ConfirmReservation(ctx context.Context, orderID string) error
+
+	// ReleaseReservation: Reservation einer stornierten Order freigeben (idempotent im Stock Service)
+	ReleaseReservation(ctx context.Context, orderID string) error
+}
+
+type stockGateway struct {
+	registry discovery.Registry
+	upstream *metrics.UpstreamMetrics // Latenz + Fehlerrate der Calls zum Stock Service (nil = aus)
+}
+
+func NewStockGateway(registry discovery.Registry, upstream *metrics.UpstreamMetrics) StockGateway {
+	return &stockGateway{registry: registry, upstream: upstream}
+}
+
+// ReserveForOrder: Nutzt RenewReservation statt ReserveStock
+// Warum?
+// → order.created wird bei Fehlern retried → ReserveStock würde jedes Mal NOCHMAL reservieren
+// → RenewReservation verlängert eine bestehende Reservation oder legt sie neu an → idempotent
+func (g *stockGateway) ReserveForOrder(ctx context.Context, order *pb.Order) (string, error) {
+	conn, err := discovery.ServiceConnection(ctx, "stock", g.registry,
+		grpc.WithUnaryInterceptor(g.upstream.UnaryClientInterceptor("stock")),
+	)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	resp, err := pb.NewStockServiceClient(conn).RenewReservation(ctx, &pb.RenewReservationRequest{
+		OrderID: order.Id,
+		Items:   order.Items,
+	})
+	if err != nil {
+		log.Printf("Failed to reserve stock for order %s: %v", order.Id, err)
+		return "", err
+	}
+
+	log.Printf("Stock reserved for order %s until %s (reservation %s)", order.Id, resp.ExpiresAt, resp.ReservationID)
+	return resp.ExpiresAt, nil
+}
+
+// ConfirmReservation: Direkter Pfad vom Webhook → der order.paid Consumer im Stock Service ist nur noch Backup
+// → Beide Pfade laufen → Stock Service bestätigt nur einmal, der zweite Call ist ein No-Op
+func (g *stockGateway) ConfirmReservation(ctx context.Context, orderID string) error {
+	conn, err := discovery.ServiceConnection(ctx, "stock", g.registry,
+		grpc.WithUnaryInterceptor(g.upstream.UnaryClientInterceptor("stock")),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := pb.NewStockServiceClient(conn).ConfirmReservation(ctx, &pb.ConfirmReservationRequest{
+		OrderID: orderID,
+	}); err != nil {
+		log.Printf("Failed to confirm stock reservation for order %s: %v", orderID, err)
+		return err
+	}
+
+	log.Printf("Stock reservation confirmed for order %s", orderID)
+	return nil
+}
+
+// ReleaseReservation: Backup zum Release im Orders CancelOrder
+// → Keine aktive Reservation (schon freigegeben/bestätigt) → Stock Service antwortet trotzdem mit Erfolg
+func (g *stockGateway) ReleaseReservation(ctx context.Context, orderID string) error {
+	conn, err := discovery.ServiceConnection(ctx, "stock", g.registry,
+		grpc.WithUnaryInterceptor(g.upstream.UnaryClientInterceptor("stock")),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := pb.NewStockServiceClient(conn).ReleaseReservation(ctx, &pb.ReleaseReservationRequest{
+		OrderID: orderID,
+	}); err != nil {
+		log.Printf("Failed to release stock reservation for order %s: %v", orderID, err)
+		return err
+	}
+
+	log.Printf("Stock reservation released for order %s", orderID)
+	return nil
+}
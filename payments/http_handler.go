@@ -3,42 +3,198 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	pb "github.com/timour/order-microservices/common/api"
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/common/telemetry"
 	"github.com/timour/order-microservices/payments/gateway"
+	"github.com/timour/order-microservices/payments/processor"
 	"github.com/stripe/stripe-go/v78"
 	"github.com/stripe/stripe-go/v78/webhook"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PaymentHTTPHandler struct {
-	channel       *amqp.Channel
+	channel       broker.Channel
 	ordersGateway gateway.OrdersGateway
+	stockGateway  gateway.StockGateway
 	ordersAddr    string
+	service       PaymentService
+	ledger        PaymentLedger // orderID → Checkout Session + PaymentIntent (Refund, Abgleich)
+	revenue       *metrics.RevenueMetrics
+	internalToken string // Bearer Token für die internen Endpoints ("" = gesperrt)
 }
 
-func NewPaymentHTTPHandler(channel *amqp.Channel, ordersGateway gateway.OrdersGateway, ordersAddr string) *PaymentHTTPHandler {
+func NewPaymentHTTPHandler(channel broker.Channel, ordersGateway gateway.OrdersGateway, stockGateway gateway.StockGateway, ordersAddr string, service PaymentService, ledger PaymentLedger, revenue *metrics.RevenueMetrics, internalToken string) *PaymentHTTPHandler {
 	return &PaymentHTTPHandler{
 		channel:       channel,
 		ordersGateway: ordersGateway,
+		stockGateway:  stockGateway,
 		ordersAddr:    ordersAddr,
+		service:       service,
+		ledger:        ledger,
+		revenue:       revenue,
+		internalToken: internalToken,
 	}
 }
 
 func (h *PaymentHTTPHandler) registerRoutes(router *http.ServeMux) {
 	router.HandleFunc("/webhook", h.handleCheckoutWebhook)
+	router.HandleFunc("/webhook/connect", h.handleConnectWebhook)
+	router.HandleFunc("POST /orders/{orderID}/payment-link", h.requireInternal(h.handleCreatePaymentLink))
+	router.HandleFunc("POST /sessions/{sessionID}/payment-link", h.requireInternal(h.handleCreateSessionPaymentLink))
+	router.HandleFunc("GET /orders/{orderID}/payment", h.requireInternal(h.handleGetPaymentByOrder)) // PaymentIntent + Betrag → nie öffentlich
 	router.Handle("/metrics", promhttp.Handler())
 }
 
+// handleCreatePaymentLink: POST /orders/{orderID}/payment-link (intern, vom Gateway, Token Pflicht)
+// Warum synchron statt über "order.created"?
+// → Customer wartet auf den NEUEN Link (Re-Issue nach abgelaufener Stripe Session)
+// → Gleiche Business Logic wie der Consumer: service.CreatePayment
+// Kein Body: Die Order (Items, Status) kommt per gRPC aus Orders
+// → Vorher kam sie aus dem Request → Caller konnte Preise/Status/Items frei wählen
+func (h *PaymentHTTPHandler) handleCreatePaymentLink(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	order, err := h.ordersGateway.GetOrder(r.Context(), orderID)
+	if status.Code(err) == codes.NotFound {
+		http.Error(w, "order not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading order %s: %v", orderID, err)
+		http.Error(w, "Failed to get order", http.StatusBadGateway)
+		return
+	}
+
+	if len(order.Items) == 0 {
+		http.Error(w, "order without items", http.StatusBadRequest)
+		return
+	}
+
+	// Warum nochmal Status prüfen?
+	// → Bezahlte Order darf NIE einen zweiten Checkout bekommen (Doppelzahlung!)
+	if order.Status != orderstatus.StatusPending && order.Status != orderstatus.StatusWaitingPayment {
+		http.Error(w, fmt.Sprintf("order is %q, payment link can't be re-issued", order.Status), http.StatusConflict)
+		return
+	}
+
+	// Session Orders werden NUR gemeinsam bezahlt (siehe handleCreateSessionPaymentLink)
+	if order.SessionId != "" {
+		http.Error(w, fmt.Sprintf("order belongs to session %q, use the session payment link", order.SessionId), http.StatusConflict)
+		return
+	}
+
+	paymentLink, err := h.service.CreatePayment(r.Context(), order)
+	if errors.Is(err, ErrAlreadyPaid) {
+		http.Error(w, "order is already paid, payment link can't be re-issued", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error re-issuing payment link for order %s: %v", orderID, err)
+		http.Error(w, "Failed to create payment link", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"payment_link": paymentLink})
+}
+
+// handleGetPaymentByOrder: GET /orders/{orderID}/payment (intern: Support, Belege, Abgleich, Token Pflicht)
+// → 404 wenn die Order nie einen Checkout hatte
+func (h *PaymentHTTPHandler) handleGetPaymentByOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("orderID")
+
+	payment, err := h.service.GetPaymentByOrder(r.Context(), orderID)
+	if errors.Is(err, ErrPaymentNotFound) {
+		http.Error(w, "payment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting payment for order %s: %v", orderID, err)
+		http.Error(w, "Failed to get payment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// handleCreateSessionPaymentLink: POST /sessions/{sessionID}/payment-link (intern, vom Gateway, Token Pflicht)
+// Kein Body: Die Orders des Tisches kommen per gRPC aus Orders → nur die unbezahlten
+// → EIN Stripe Checkout für alle Orders zusammen
+func (h *PaymentHTTPHandler) handleCreateSessionPaymentLink(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("sessionID")
+
+	orders, err := h.ordersGateway.GetOrdersBySession(r.Context(), sessionID)
+	if err != nil {
+		log.Printf("Error loading orders of session %s: %v", sessionID, err)
+		http.Error(w, "Failed to get session orders", http.StatusBadGateway)
+		return
+	}
+
+	// Gleiche Regel wie beim Einzel-Link: Bezahlte Orders NIE nochmal abrechnen
+	var unpaid []*pb.Order
+	for _, order := range orders {
+		if order.Status != orderstatus.StatusPending && order.Status != orderstatus.StatusWaitingPayment {
+			continue
+		}
+		if len(order.Items) == 0 {
+			http.Error(w, fmt.Sprintf("order %s has no items", order.Id), http.StatusBadRequest)
+			return
+		}
+		unpaid = append(unpaid, order)
+	}
+
+	if len(unpaid) == 0 {
+		http.Error(w, fmt.Sprintf("session %s has no unpaid orders", sessionID), http.StatusConflict)
+		return
+	}
+
+	paymentLink, err := h.service.CreateSessionPayment(r.Context(), sessionID, unpaid)
+	if err != nil {
+		log.Printf("Error creating session payment link for session %s: %v", sessionID, err)
+		http.Error(w, "Failed to create payment link", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"payment_link": paymentLink})
+}
+
+// handleCheckoutWebhook: Stripe Webhook für Checkouts auf dem Plattform Account
 func (h *PaymentHTTPHandler) handleCheckoutWebhook(w http.ResponseWriter, r *http.Request) {
+	h.handleStripeWebhook(w, r, endpointStripeSecret)
+}
+
+// handleConnectWebhook: Stripe Webhook für Checkouts auf Connect Accounts der Restaurants
+// Warum eigener Endpoint?
+// → Events von Connect Accounts gehen NIE an den Plattform Endpoint, nur an einen "Connect" Endpoint
+// → Eigenes Signing Secret (STRIPE_CONNECT_ENDPOINT_SECRET), event.Account = Account des Restaurants
+// → Secret nicht gesetzt → 404, und CreatePayment lehnt Connect Orders ab (checkConnectSupported)
+func (h *PaymentHTTPHandler) handleConnectWebhook(w http.ResponseWriter, r *http.Request) {
+	if connectEndpointStripeSecret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	h.handleStripeWebhook(w, r, connectEndpointStripeSecret)
+}
+
+// handleStripeWebhook: Signatur mit secret prüfen → checkout.session.completed verarbeiten
+func (h *PaymentHTTPHandler) handleStripeWebhook(w http.ResponseWriter, r *http.Request, secret string) {
 	const MaxBodyBytes = int64(65536)
 	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
 
@@ -53,7 +209,7 @@ func (h *PaymentHTTPHandler) handleCheckoutWebhook(w http.ResponseWriter, r *htt
 	event, err := webhook.ConstructEventWithOptions(
 		body,
 		r.Header.Get("Stripe-Signature"),
-		endpointStripeSecret,
+		secret,
 		webhook.ConstructEventOptions{
 			IgnoreAPIVersionMismatch: true,
 		},
@@ -74,55 +230,173 @@ func (h *PaymentHTTPHandler) handleCheckoutWebhook(w http.ResponseWriter, r *htt
 			return
 		}
 
-		if session.PaymentStatus == "paid" {
+		if session.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid {
 			log.Printf("Payment for Checkout Session %v succeeded!", session.ID)
 
-			orderID := session.Metadata["orderID"]
-			customerID := session.Metadata["customerID"]
-
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 
-			// ⭐ STEP 1: Update Order Status to "paid" in MongoDB FIRST!
-			// → Warum ZUERST?
-			// → Kitchen Service subscribt "order.paid" Event und updated Status zu "preparing"
-			// → Wenn wir NICHT zuerst "paid" in DB schreiben, siehst du NIE "paid" Status!
-			// → Flow MUSS sein: pending → waiting_payment → paid → preparing
-			err = h.ordersGateway.UpdateOrderStatus(ctx, orderID, customerID, "paid")
-			if err != nil {
-				log.Printf("Error updating order status to paid: %v", err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			log.Printf("Order %s status updated to 'paid' in database", orderID)
+			// Kombinierter Checkout (Tisch/Session) → ALLE Orders der Session sind bezahlt
+			if sessionID := session.Metadata[processor.MetadataSessionID]; sessionID != "" {
+				orderIDs := strings.Split(session.Metadata[processor.MetadataOrderIDs], ",")
+				customerIDs := strings.Split(session.Metadata[processor.MetadataCustomerIDs], ",")
 
-			o := &pb.Order{
-				Id:         orderID,
-				CustomerId: customerID,
-				Status:     "paid",
-			}
+				for i, orderID := range orderIDs {
+					var customerID string
+					if i < len(customerIDs) {
+						customerID = customerIDs[i]
+					}
+					// Ledger pro Order auf "completed" → Storno erkennt die Zahlung und dead-lettert (manueller Refund)
+					// → Amount bleibt leer: Anteil der Order am gemeinsamen Checkout ist unbekannt
+					record := &PaymentRecord{
+						OrderID:       orderID,
+						SessionID:     session.ID,
+						StripeAccount: event.Account,
+						Currency:      string(session.Currency),
+					}
+					if session.PaymentIntent != nil {
+						record.PaymentIntentID = session.PaymentIntent.ID
+					}
+					if err := h.ledger.CompletePayment(ctx, record); err != nil {
+						log.Printf("Error completing payment %s for order %s in ledger: %v", session.ID, orderID, err)
+					}
 
-			marshalledOrder, err := json.Marshal(o)
-			if err != nil {
-				log.Fatal(err.Error())
-			}
-
-			// ⭐ STEP 2: NOW publish event to RabbitMQ
-			// → Kitchen Service empfängt Event und updated Status zu "preparing"
-			// → Aber "paid" Status ist BEREITS in MongoDB gespeichert!
-			err = h.channel.PublishWithContext(ctx, broker.OrderPaidEvent, "", false, false, amqp.Publishing{
-				ContentType:  "application/json",
-				Body:         marshalledOrder,
-				DeliveryMode: amqp.Persistent,
-			})
-
-			if err != nil {
-				log.Printf("Error publishing message: %v", err)
+					// Fehler → 500 → Stripe schickt den Webhook nochmal
+					// → Bereits bezahlte Orders: paid → paid ist ein No-Op im Orders Service
+					err := h.markOrderPaid(ctx, orderID, customerID)
+					var closed *closedOrderError
+					if errors.As(err, &closed) {
+						_, err = h.service.RefundLatePayment(ctx, closed.order, record)
+					}
+					if err != nil {
+						log.Printf("Error marking order %s of session %s as paid: %v", orderID, sessionID, err)
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+				}
 			} else {
-				log.Println("Message published order.paid")
+				orderID := session.Metadata["orderID"]
+
+				// Ledger Eintrag auf "completed" → order.cancelled Consumer kann später erstatten
+				// Warum VOR markOrderPaid?
+				// → Storno direkt nach "paid" findet die Zahlung sonst (noch) nicht → kein Refund
+				// → Fehler → NUR loggen: Zahlung ist trotzdem gültig, Refund dann manuell
+				record := &PaymentRecord{
+					OrderID:       orderID,
+					SessionID:     session.ID,
+					StripeAccount: event.Account, // Connect Webhook → Refunds laufen im Account des Restaurants
+					Amount:        session.AmountTotal,
+					Currency:      string(session.Currency),
+				}
+				if session.PaymentIntent != nil {
+					record.PaymentIntentID = session.PaymentIntent.ID
+				}
+				if err := h.ledger.CompletePayment(ctx, record); err != nil {
+					log.Printf("Error completing payment %s for order %s in ledger: %v", session.ID, orderID, err)
+				}
+
+				err := h.markOrderPaid(ctx, orderID, session.Metadata["customerID"])
+				var closed *closedOrderError
+				if errors.As(err, &closed) {
+					// Zu spät bezahlt → Geld zurück, erst danach 200 (Fehler → 500 → Stripe Retry, gleicher Key)
+					if _, err := h.service.RefundLatePayment(ctx, closed.order, record); err != nil {
+						log.Printf("Error refunding late payment for order %s: %v", orderID, err)
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				if err != nil {
+					log.Printf("Error updating order status to paid: %v", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
 			}
+
+			// Erst NACH erfolgreichem Update → ein 500 + Stripe Retry zählt nicht doppelt
+			h.revenue.RecordRevenue(string(session.Currency), session.AmountTotal)
 		}
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// closedOrderError: Order ist storniert/abgelaufen → die Zahlung muss erstattet werden
+type closedOrderError struct {
+	order *pb.Order
+}
+
+func (e *closedOrderError) Error() string {
+	return fmt.Sprintf("order %s is %s, payment must be refunded", e.order.Id, e.order.Status)
+}
+
+// markOrderPaid: Order auf "paid" setzen und "order.paid" publishen
+// → *closedOrderError wenn die Order nicht mehr bezahlt werden kann (Caller erstattet)
+func (h *PaymentHTTPHandler) markOrderPaid(ctx context.Context, orderID, customerID string) error {
+	// Stripe Webhook hat keine Baggage → Customer ID aus den Session Metadata für Orders/Kitchen/Stock
+	ctx = telemetry.WithCustomerID(ctx, customerID)
+
+	// ⭐ STEP 1: Update Order Status to "paid" in MongoDB FIRST!
+	// → Warum ZUERST?
+	// → Kitchen Service subscribt "order.paid" Event und updated Status zu "preparing"
+	// → Wenn wir NICHT zuerst "paid" in DB schreiben, siehst du NIE "paid" Status!
+	// → Flow MUSS sein: pending → waiting_payment → paid → preparing
+	// Orders lehnt "paid" ab (FailedPrecondition) → WARUM entscheidet der aktuelle Status:
+	// → Webhook Retry, Order schon weiter (preparing, ready, ...) → nichts zu tun, kein 500 (sonst retried Stripe tagelang)
+	// → Order storniert/abgelaufen → Kunde hat zu spät bezahlt → closedOrderError, Caller erstattet
+	if err := h.ordersGateway.UpdateOrderStatus(ctx, orderID, customerID, orderstatus.StatusPaid); err != nil {
+		if status.Code(err) != codes.FailedPrecondition {
+			return err
+		}
+		order, getErr := h.ordersGateway.GetOrder(ctx, orderID)
+		if getErr != nil {
+			return fmt.Errorf("order %s can't be marked as paid, loading it failed: %w", orderID, getErr)
+		}
+		if order.Status == orderstatus.StatusCancelled || order.Status == orderstatus.StatusExpired {
+			return &closedOrderError{order: order}
+		}
+		log.Printf("Order %s can't be marked as paid, skipping: %v", orderID, err)
+		return nil
+	}
+	log.Printf("Order %s status updated to 'paid' in database", orderID)
+
+	// ⭐ STEP 1b: Reservation direkt im Stock Service bestätigen
+	// → Warum nicht nur über order.paid?
+	// → Event hängt in der DLQ / Broker down → Reservation läuft ab → Verkauf still verloren
+	// → Fehler hier → NUR loggen: Order ist bezahlt, der order.paid Consumer ist der Backup Pfad
+	if err := h.stockGateway.ConfirmReservation(ctx, orderID); err != nil {
+		log.Printf("Error confirming stock reservation for order %s (order.paid consumer will retry): %v", orderID, err)
+	}
+
+	o := &pb.Order{
+		Id:         orderID,
+		CustomerId: customerID,
+		Status:     orderstatus.StatusPaid,
+	}
+
+	marshalledOrder, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+
+	// ⭐ STEP 2: NOW publish event to RabbitMQ
+	// → Kitchen Service empfängt Event und updated Status zu "preparing"
+	// → Aber "paid" Status ist BEREITS in MongoDB gespeichert!
+	err = h.channel.PublishWithContext(ctx, broker.OrderPaidEvent, "", false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		Body:         marshalledOrder,
+		DeliveryMode: amqp.Persistent,
+		Headers:      broker.InjectTraceContext(ctx), // Trace + Baggage (customer.id)
+		// Stripe retried den Webhook → gleiche MessageId → Consumer verarbeiten order.paid nur einmal
+		MessageId: broker.MessageID(broker.OrderPaidEvent, o.Id),
+	})
+
+	if err != nil {
+		log.Printf("Error publishing message: %v", err)
+	} else {
+		log.Println("Message published order.paid")
+	}
+
+	return nil
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stripe/stripe-go/v78/webhook"
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+	"github.com/timour/order-microservices/common/config"
+	orderstatus "github.com/timour/order-microservices/common/order"
+	"github.com/timour/order-microservices/payments/gateway"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// paidOrders: Orders Gateway, UpdateOrderStatus klappt immer
+type paidOrders struct {
+	gateway.OrdersGateway
+}
+
+func (paidOrders) UpdateOrderStatus(context.Context, string, string, string) error { return nil }
+
+// confirmedStock: Stock Gateway, ConfirmReservation klappt immer
+type confirmedStock struct {
+	gateway.StockGateway
+}
+
+func (confirmedStock) ConfirmReservation(context.Context, string) error { return nil }
+
+// Webhook Publish geht über den order.paid Exchange → jede gebundene Queue bekommt es (wie beim Publish aus Orders)
+func TestMarkOrderPaidPublishesOnExchange(t *testing.T) {
+	b := brokertest.New()
+	queues := []string{"kitchen.order.paid", "stock.order.paid"}
+	for _, q := range queues {
+		if _, err := b.QueueDeclare(q, true, false, false, false, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.QueueBind(q, "", broker.OrderPaidEvent, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewPaymentHTTPHandler(b, paidOrders{}, confirmedStock{}, "", nil, nil, nil, "")
+	if err := h.markOrderPaid(context.Background(), "o1", "c1"); err != nil {
+		t.Fatalf("markOrderPaid: %v", err)
+	}
+
+	for _, q := range queues {
+		d, ok, err := b.Get(q, true)
+		if err != nil || !ok {
+			t.Fatalf("queue %s: no message (%v)", q, err)
+		}
+		if d.Exchange != broker.OrderPaidEvent || d.MessageId != broker.MessageID(broker.OrderPaidEvent, "o1") {
+			t.Errorf("queue %s: exchange/message id = %q/%q; want %q/%q", q, d.Exchange, d.MessageId, broker.OrderPaidEvent, broker.MessageID(broker.OrderPaidEvent, "o1"))
+		}
+		var o pb.Order
+		if err := json.Unmarshal(d.Body, &o); err != nil || o.Id != "o1" || o.CustomerId != "c1" || o.Status != orderstatus.StatusPaid {
+			t.Errorf("queue %s: order = %+v (%v); want o1/c1 paid", q, &o, err)
+		}
+	}
+}
+
+// storedOrders: Orders Gateway mit festen Orders (GetOrder, GetOrdersBySession), unbekannte ID → NotFound
+type storedOrders struct {
+	gateway.OrdersGateway
+	orders map[string]*pb.Order
+}
+
+func (s storedOrders) GetOrdersBySession(_ context.Context, sessionID string) ([]*pb.Order, error) {
+	var orders []*pb.Order
+	for _, order := range s.orders {
+		if order.SessionId == sessionID {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (s storedOrders) GetOrder(_ context.Context, orderID string) (*pb.Order, error) {
+	order, ok := s.orders[orderID]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return order, nil
+}
+
+// linkService: PaymentService der sich die Orders der Payment Link Calls merkt
+type linkService struct {
+	PaymentService
+	created []*pb.Order
+}
+
+func (s *linkService) CreateSessionPayment(_ context.Context, sessionID string, orders []*pb.Order) (string, error) {
+	s.created = append(s.created, orders...)
+	return "https://checkout.stripe.com/" + sessionID, nil
+}
+
+func (s *linkService) CreatePayment(_ context.Context, order *pb.Order) (string, error) {
+	s.created = append(s.created, order)
+	return "https://checkout.stripe.com/" + order.Id, nil
+}
+
+const testInternalToken = "internal-secret"
+
+// servePayments: Request durch die echten Routes, token != "" → Authorization Header
+func servePayments(h *PaymentHTTPHandler, method, path, body, token string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.registerRoutes(mux)
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+// Body wird ignoriert → Items und Status kommen aus Orders, nicht vom Caller
+func TestCreatePaymentLinkLoadsOrderFromOrders(t *testing.T) {
+	stored := &pb.Order{Id: "o1", CustomerId: "c1", Status: orderstatus.StatusWaitingPayment, Items: []*pb.Item{{ID: "1", PriceID: "price_burger", Quantity: 1}}}
+	svc := &linkService{}
+	h := NewPaymentHTTPHandler(nil, storedOrders{orders: map[string]*pb.Order{"o1": stored}}, nil, "", svc, nil, nil, testInternalToken)
+
+	forged := `{"id":"o1","status":"pending","items":[{"ID":"1","PriceID":"price_free","Quantity":99}]}`
+	w := servePayments(h, "POST", "/orders/o1/payment-link", forged, testInternalToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+	if len(svc.created) != 1 || svc.created[0] != stored {
+		t.Errorf("CreatePayment orders = %+v; want the stored order", svc.created)
+	}
+}
+
+func TestCreatePaymentLinkRejectsPaidOrUnknownOrder(t *testing.T) {
+	paid := &pb.Order{Id: "o1", Status: orderstatus.StatusPaid, Items: []*pb.Item{{ID: "1", Quantity: 1}}}
+	svc := &linkService{}
+	h := NewPaymentHTTPHandler(nil, storedOrders{orders: map[string]*pb.Order{"o1": paid}}, nil, "", svc, nil, nil, testInternalToken)
+
+	if w := servePayments(h, "POST", "/orders/o1/payment-link", "", testInternalToken); w.Code != http.StatusConflict {
+		t.Errorf("paid order: status = %d; want 409", w.Code)
+	}
+	if w := servePayments(h, "POST", "/orders/o2/payment-link", "", testInternalToken); w.Code != http.StatusNotFound {
+		t.Errorf("unknown order: status = %d; want 404", w.Code)
+	}
+	if len(svc.created) != 0 {
+		t.Errorf("CreatePayment calls = %d; want 0", len(svc.created))
+	}
+}
+
+// Session Checkout: Orders kommen aus Orders, bezahlte Orders des Tisches bleiben draußen
+func TestCreateSessionPaymentLinkLoadsUnpaidOrders(t *testing.T) {
+	items := []*pb.Item{{ID: "1", PriceID: "price_burger", Quantity: 1}}
+	svc := &linkService{}
+	h := NewPaymentHTTPHandler(nil, storedOrders{orders: map[string]*pb.Order{
+		"o1": {Id: "o1", SessionId: "t7", Status: orderstatus.StatusWaitingPayment, Items: items},
+		"o2": {Id: "o2", SessionId: "t7", Status: orderstatus.StatusPaid, Items: items},
+		"o3": {Id: "o3", SessionId: "t8", Status: orderstatus.StatusPending, Items: items},
+	}}, nil, "", svc, nil, nil, testInternalToken)
+
+	forged := `{"orders":[{"id":"o2","session_id":"t7","status":"pending","items":[{"ID":"1","Quantity":1}]}]}`
+	w := servePayments(h, "POST", "/sessions/t7/payment-link", forged, testInternalToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+	if len(svc.created) != 1 || svc.created[0].Id != "o1" {
+		t.Errorf("CreateSessionPayment orders = %+v; want only o1", svc.created)
+	}
+
+	if w := servePayments(h, "POST", "/sessions/t9/payment-link", "", testInternalToken); w.Code != http.StatusConflict {
+		t.Errorf("session without unpaid orders: status = %d; want 409", w.Code)
+	}
+}
+
+// paymentLookup: PaymentService mit EINER Zahlung im Ledger
+type paymentLookup struct {
+	PaymentService
+	record *PaymentRecord
+}
+
+func (s paymentLookup) GetPaymentByOrder(_ context.Context, orderID string) (*PaymentRecord, error) {
+	if s.record == nil || s.record.OrderID != orderID {
+		return nil, ErrPaymentNotFound
+	}
+	return s.record, nil
+}
+
+// Ledger Abfrage mit Token → Zahlung, unbekannte Order → 404
+func TestGetPaymentByOrderWithToken(t *testing.T) {
+	svc := paymentLookup{record: &PaymentRecord{OrderID: "o1", SessionID: "cs_1", Amount: 1200}}
+	h := NewPaymentHTTPHandler(nil, storedOrders{}, nil, "", svc, nil, nil, testInternalToken)
+
+	w := servePayments(h, "GET", "/orders/o1/payment", "", testInternalToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s); want 200", w.Code, w.Body.String())
+	}
+	var record PaymentRecord
+	if err := json.NewDecoder(w.Body).Decode(&record); err != nil || record.SessionID != "cs_1" {
+		t.Errorf("payment = %+v (%v); want session cs_1", record, err)
+	}
+
+	if w := servePayments(h, "GET", "/orders/o2/payment", "", testInternalToken); w.Code != http.StatusNotFound {
+		t.Errorf("unknown order: status = %d; want 404", w.Code)
+	}
+}
+
+// Interne Endpoints: ohne/falscher Token → 401, Token nicht konfiguriert → 403
+func TestInternalRoutesRequireToken(t *testing.T) {
+	routes := []struct{ method, path string }{
+		{"POST", "/orders/o1/payment-link"},
+		{"POST", "/sessions/t7/payment-link"},
+		{"GET", "/orders/o1/payment"},
+	}
+
+	h := NewPaymentHTTPHandler(nil, storedOrders{}, nil, "", &linkService{}, nil, nil, testInternalToken)
+	disabled := NewPaymentHTTPHandler(nil, storedOrders{}, nil, "", &linkService{}, nil, nil, "")
+
+	for _, route := range routes {
+		if w := servePayments(h, route.method, route.path, "", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without token: status = %d; want 401", route.method, route.path, w.Code)
+		}
+		if w := servePayments(h, route.method, route.path, "", "wrong"); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s with wrong token: status = %d; want 401", route.method, route.path, w.Code)
+		}
+		if w := servePayments(disabled, route.method, route.path, "", testInternalToken); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s without configured token: status = %d; want 403", route.method, route.path, w.Code)
+		}
+	}
+}
+
+// Connect Webhook: Eigenes Secret, Account aus dem Event landet im Ledger (→ Refund im richtigen Account)
+func TestConnectWebhookRecordsStripeAccount(t *testing.T) {
+	ledger := NewMemoryLedger()
+	h := NewPaymentHTTPHandler(brokertest.New(), paidOrders{}, confirmedStock{}, "", nil, ledger, nil, "")
+	mux := http.NewServeMux()
+	h.registerRoutes(mux)
+
+	payload := []byte(`{"id":"evt_1","object":"event","type":"checkout.session.completed","account":"acct_roma","data":{"object":` +
+		`{"id":"cs_1","object":"checkout.session","payment_status":"paid","amount_total":1200,"currency":"eur","metadata":{"orderID":"o1","customerID":"c1"}}}}`)
+	post := func(path, secret string) int {
+		signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: secret})
+		r := httptest.NewRequest("POST", path, strings.NewReader(string(payload)))
+		r.Header.Set("Stripe-Signature", signed.Header)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Nicht konfiguriert → Endpoint gibt es nicht
+	if code := post("/webhook/connect", "whsec_connect"); code != http.StatusNotFound {
+		t.Fatalf("without connect secret: status = %d; want 404", code)
+	}
+
+	setConnectSecret(t, "whsec_connect")
+	// Plattform Endpoint akzeptiert die Connect Signatur nicht
+	if code := post("/webhook", "whsec_connect"); code != http.StatusBadRequest {
+		t.Errorf("platform endpoint with connect signature: status = %d; want 400", code)
+	}
+	if code := post("/webhook/connect", "whsec_connect"); code != http.StatusOK {
+		t.Fatalf("connect webhook: status = %d; want 200", code)
+	}
+
+	payment, err := ledger.GetPaymentByOrder(context.Background(), "o1")
+	if err != nil {
+		t.Fatalf("GetPaymentByOrder: %v", err)
+	}
+	if payment.Status != PaymentStatusCompleted || payment.StripeAccount != "acct_roma" {
+		t.Errorf("payment = %+v; want completed on acct_roma", payment)
+	}
+}
+
+// closedOrders: Orders lehnt "paid" ab (FailedPrecondition), GetOrder liefert den aktuellen Status
+type closedOrders struct {
+	gateway.OrdersGateway
+	order *pb.Order
+}
+
+func (c closedOrders) UpdateOrderStatus(context.Context, string, string, string) error {
+	return status.Error(codes.FailedPrecondition, "invalid status transition")
+}
+
+func (c closedOrders) GetOrder(context.Context, string) (*pb.Order, error) {
+	return c.order, nil
+}
+
+// postCheckoutWebhook: Signierter checkout.session.completed (paid) für Order o1 an /webhook
+func postCheckoutWebhook(t *testing.T, h *PaymentHTTPHandler) int {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.registerRoutes(mux)
+
+	payload := []byte(`{"id":"evt_1","object":"event","type":"checkout.session.completed","data":{"object":` +
+		`{"id":"cs_1","object":"checkout.session","payment_status":"paid","payment_intent":"pi_1","amount_total":1200,"currency":"eur","metadata":{"orderID":"o1","customerID":"c1"}}}}`)
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: endpointStripeSecret})
+	r := httptest.NewRequest("POST", "/webhook", strings.NewReader(string(payload)))
+	r.Header.Set("Stripe-Signature", signed.Header)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	return w.Code
+}
+
+// Kunde zahlt eine stornierte Order → voller Refund (Key late-o1), Ledger "refunded", erst dann 200
+func TestCheckoutWebhookRefundsCancelledOrder(t *testing.T) {
+	svc, log := newTestService(config.ReservationOnCreate, nil)
+	h := NewPaymentHTTPHandler(brokertest.New(), closedOrders{order: &pb.Order{Id: "o1", Status: orderstatus.StatusCancelled}}, confirmedStock{}, "", svc, svc.ledger, nil, "")
+
+	if code := postCheckoutWebhook(t, h); code != http.StatusOK {
+		t.Fatalf("status = %d; want 200", code)
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"refund-payment:pi_1//late-o1"}) {
+		t.Errorf("calls = %v; want one refund of pi_1 with key late-o1", got)
+	}
+	payment, err := svc.ledger.GetPaymentByOrder(context.Background(), "o1")
+	if err != nil || payment.Status != PaymentStatusRefunded || payment.RefundID != "re_pi_1" {
+		t.Errorf("ledger = %+v (%v); want refunded with re_pi_1", payment, err)
+	}
+}
+
+// Webhook Retry für eine Order die schon in der Küche ist → kein Refund
+func TestCheckoutWebhookSkipsOrderAlreadyPastPaid(t *testing.T) {
+	svc, log := newTestService(config.ReservationOnCreate, nil)
+	h := NewPaymentHTTPHandler(brokertest.New(), closedOrders{order: &pb.Order{Id: "o1", Status: orderstatus.StatusPreparing}}, confirmedStock{}, "", svc, svc.ledger, nil, "")
+
+	if code := postCheckoutWebhook(t, h); code != http.StatusOK {
+		t.Fatalf("status = %d; want 200", code)
+	}
+	if got := log.Calls(); len(got) != 0 {
+		t.Errorf("calls = %v; want no refund", got)
+	}
+}
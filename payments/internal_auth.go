@@ -0,0 +1,35 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// requireInternal: Interne Endpoints nur mit "Authorization: Bearer <PAYMENTS_INTERNAL_TOKEN>"
+// Warum?
+// → Der HTTP Server ist gleichzeitig der öffentliche Stripe Webhook
+// → Ohne Token könnte jeder Payment Links erzeugen (Stock Reservation + Stripe Session)
+// → Oder Zahlungen anderer Kunden auslesen (Ledger)
+// Warum Fail-Closed?
+// → PAYMENTS_INTERNAL_TOKEN nicht gesetzt → Endpoint ist aus (403) statt offen für jeden
+func (h *PaymentHTTPHandler) requireInternal(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.internalToken == "" {
+			http.Error(w, "internal endpoints are disabled (PAYMENTS_INTERNAL_TOKEN not set)", http.StatusForbidden)
+			return
+		}
+
+		// subtle.ConstantTimeCompare → Token nicht per Timing erratbar
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.internalToken)) != 1 {
+			log.Printf("Unauthorized internal request: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="payments"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
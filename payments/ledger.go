@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPaymentNotFound: Für die Order wurde (noch) keine Checkout Session angelegt
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// Payment Ledger Status
+const (
+	PaymentStatusOpen      = "open"      // Checkout Session erstellt, Kunde hat noch nicht bezahlt
+	PaymentStatusCompleted = "completed" // Webhook checkout.session.completed (paid)
+	PaymentStatusRefunded  = "refunded"  // Zahlung kam für eine stornierte/abgelaufene Order → erstattet
+)
+
+// PaymentRecord: Ein Eintrag im Payment Ledger (EINER pro Order)
+// Warum eigener Ledger statt nur Stripe Metadata?
+// → Metadata kennt nur orderID/customerID → von der Order aus findet man die Session nicht
+// → Refunds, Belege und Abgleich mit Stripe brauchen Session + PaymentIntent pro Order
+type PaymentRecord struct {
+	OrderID         string    `bson:"_id" json:"order_id"`
+	SessionID       string    `bson:"sessionID" json:"session_id"`
+	PaymentIntentID string    `bson:"paymentIntentID,omitempty" json:"payment_intent_id,omitempty"` // Erst nach der Zahlung bekannt
+	StripeAccount   string    `bson:"stripeAccount,omitempty" json:"stripe_account,omitempty"`      // Connect Account des Restaurants (leer = Plattform)
+	Amount          int64     `bson:"amount" json:"amount"`                                         // Kleinste Währungseinheit (Cent)
+	Currency        string    `bson:"currency" json:"currency"`
+	Status          string    `bson:"status" json:"status"`
+	CreatedAt       time.Time `bson:"createdAt" json:"created_at"`
+	CompletedAt     time.Time `bson:"completedAt,omitempty" json:"completed_at,omitempty"`
+	RefundID        string    `bson:"refundID,omitempty" json:"refund_id,omitempty"` // Leer bei anteiligem Refund (Session Checkout)
+	RefundedAt      time.Time `bson:"refundedAt,omitempty" json:"refunded_at,omitempty"`
+}
+
+// PaymentLedger: Auditierbare Zahlungen unabhängig von Stripe
+type PaymentLedger interface {
+	// RecordCheckout: Neue Checkout Session für die Order (Re-Issue ersetzt die offene Session)
+	RecordCheckout(ctx context.Context, record *PaymentRecord) error
+	// CompletePayment: Order bezahlt → Status "completed" + PaymentIntent (legt den Eintrag an falls er fehlt)
+	CompletePayment(ctx context.Context, record *PaymentRecord) error
+	// RecordRefund: Zahlung erstattet → Status "refunded" + Refund ID (legt den Eintrag an falls er fehlt)
+	RecordRefund(ctx context.Context, record *PaymentRecord) error
+	// GetPaymentByOrder: ErrPaymentNotFound wenn die Order nie einen Checkout hatte
+	GetPaymentByOrder(ctx context.Context, orderID string) (*PaymentRecord, error)
+}
+
+// memoryLedger: Pro Prozess → nur für lokale Entwicklung / eine Instanz
+// ⚠️ Restart vergisst alle Zahlungen → Storno danach erstattet nicht automatisch
+type memoryLedger struct {
+	mu       sync.RWMutex
+	payments map[string]PaymentRecord
+}
+
+func NewMemoryLedger() PaymentLedger {
+	return &memoryLedger{payments: make(map[string]PaymentRecord)}
+}
+
+func (l *memoryLedger) RecordCheckout(_ context.Context, record *PaymentRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Bezahlte Order bekommt keinen neuen Checkout → Eintrag nicht überschreiben
+	if existing, ok := l.payments[record.OrderID]; ok && existing.Status == PaymentStatusCompleted {
+		return nil
+	}
+
+	r := *record
+	r.Status = PaymentStatusOpen
+	l.payments[r.OrderID] = r
+	return nil
+}
+
+func (l *memoryLedger) CompletePayment(_ context.Context, record *PaymentRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := *record
+	if existing, ok := l.payments[r.OrderID]; ok && r.CreatedAt.IsZero() {
+		r.CreatedAt = existing.CreatedAt
+	}
+	r.Status = PaymentStatusCompleted
+	l.payments[r.OrderID] = r
+	return nil
+}
+
+func (l *memoryLedger) RecordRefund(_ context.Context, record *PaymentRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := *record
+	if existing, ok := l.payments[r.OrderID]; ok && r.CreatedAt.IsZero() {
+		r.CreatedAt = existing.CreatedAt
+	}
+	if r.RefundedAt.IsZero() {
+		r.RefundedAt = time.Now().UTC()
+	}
+	r.Status = PaymentStatusRefunded
+	l.payments[r.OrderID] = r
+	return nil
+}
+
+func (l *memoryLedger) GetPaymentByOrder(_ context.Context, orderID string) (*PaymentRecord, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	r, ok := l.payments[orderID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	return &r, nil
+}
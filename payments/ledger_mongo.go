@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoLedger: Payment Ledger in MongoDB (Database "payments", Collection "payments")
+// Warum _id = orderID?
+// → EIN Eintrag pro Order, Lookup per Primary Key → kein eigener Index nötig
+type mongoLedger struct {
+	collection *mongo.Collection
+}
+
+func NewMongoLedger(client *mongo.Client) PaymentLedger {
+	return &mongoLedger{
+		collection: client.Database("payments").Collection("payments"),
+	}
+}
+
+// RecordCheckout: Upsert nur solange die Order nicht bezahlt ist
+// → Bezahlter Eintrag matcht den Filter nicht → Upsert scheitert am _id → Duplicate Key = nichts zu tun
+func (l *mongoLedger) RecordCheckout(ctx context.Context, record *PaymentRecord) error {
+	createdAt := record.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+
+	_, err := l.collection.UpdateOne(ctx,
+		bson.M{"_id": record.OrderID, "status": bson.M{"$ne": PaymentStatusCompleted}},
+		bson.M{"$set": bson.M{
+			"sessionID":     record.SessionID,
+			"stripeAccount": record.StripeAccount,
+			"amount":        record.Amount,
+			"currency":      record.Currency,
+			"status":        PaymentStatusOpen,
+			"createdAt":     createdAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record checkout for order %s: %w", record.OrderID, err)
+	}
+	return nil
+}
+
+// CompletePayment: Webhook Retry setzt dieselben Werte nochmal → idempotent
+func (l *mongoLedger) CompletePayment(ctx context.Context, record *PaymentRecord) error {
+	completedAt := record.CompletedAt
+	if completedAt.IsZero() {
+		completedAt = time.Now().UTC()
+	}
+
+	_, err := l.collection.UpdateOne(ctx,
+		bson.M{"_id": record.OrderID},
+		bson.M{
+			"$set": bson.M{
+				"sessionID":       record.SessionID,
+				"paymentIntentID": record.PaymentIntentID,
+				"stripeAccount":   record.StripeAccount,
+				"amount":          record.Amount,
+				"currency":        record.Currency,
+				"status":          PaymentStatusCompleted,
+				"completedAt":     completedAt,
+			},
+			// Kein Checkout Eintrag (z.B. Ledger neu, Session vor dem Deploy erstellt) → trotzdem auditierbar
+			"$setOnInsert": bson.M{"createdAt": completedAt},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete payment for order %s: %w", record.OrderID, err)
+	}
+	return nil
+}
+
+// RecordRefund: Webhook Retry setzt dieselben Werte nochmal → idempotent
+func (l *mongoLedger) RecordRefund(ctx context.Context, record *PaymentRecord) error {
+	refundedAt := record.RefundedAt
+	if refundedAt.IsZero() {
+		refundedAt = time.Now().UTC()
+	}
+
+	_, err := l.collection.UpdateOne(ctx,
+		bson.M{"_id": record.OrderID},
+		bson.M{
+			"$set": bson.M{
+				"sessionID":       record.SessionID,
+				"paymentIntentID": record.PaymentIntentID,
+				"stripeAccount":   record.StripeAccount,
+				"refundID":        record.RefundID,
+				"status":          PaymentStatusRefunded,
+				"refundedAt":      refundedAt,
+			},
+			"$setOnInsert": bson.M{"createdAt": refundedAt},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record refund for order %s: %w", record.OrderID, err)
+	}
+	return nil
+}
+
+func (l *mongoLedger) GetPaymentByOrder(ctx context.Context, orderID string) (*PaymentRecord, error) {
+	var record PaymentRecord
+	err := l.collection.FindOne(ctx, bson.M{"_id": orderID}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment for order %s: %w", orderID, err)
+	}
+	return &record, nil
+}
+
+// connectToMongoDB: Verbindet und prüft die Verbindung (Ping)
+func connectToMongoDB(uri string) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to ping mongodb: %w", err)
+	}
+
+	return client, nil
+}
@@ -6,17 +6,24 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/timour/order-microservices/common/config"
 	"github.com/timour/order-microservices/common/logger"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/startup"
 	"github.com/timour/order-microservices/common/tracing"
 	"github.com/timour/order-microservices/payments/gateway"
+	"github.com/timour/order-microservices/payments/processor"
 )
 
 var (
 	endpointStripeSecret = config.GetEnv("STRIPE_ENDPOINT_SECRET", "whsec_...")
+	// Connect Webhook Endpoint (eigenes Secret im Dashboard) → "" = keine Checkouts auf Connect Accounts
+	connectEndpointStripeSecret = config.GetEnv("STRIPE_CONNECT_ENDPOINT_SECRET", "")
 )
 
 func main() {
@@ -27,11 +34,13 @@ func main() {
 		ConsulAddr:  config.GetEnv("CONSUL_ADDR", "localhost:8500"),
 		AMQPUser:    config.GetEnv("AMQP_USER", "guest"),
 		AMQPPass:    config.GetEnv("AMQP_PASS", "guest"),
-		AMQPHost:    config.GetEnv("AMQP_HOST", "localhost"),
+		AMQPHost:    config.GetEnv("AMQP_HOST", "localhost"), // Komma-separiert für Cluster Nodes ("rabbit-1,rabbit-2:5673")
 		AMQPPort:    config.GetEnv("AMQP_PORT", "5672"),
 		StripeKey:   config.GetEnv("STRIPE_SECRET_KEY", ""),
 		HTTPAddr:    config.GetEnv("HTTP_ADDR", "localhost:8082"),
 		OrdersAddr:  config.GetEnv("ORDERS_GRPC_ADDR", "localhost:9000"),
+
+		InternalToken: config.GetEnv("PAYMENTS_INTERNAL_TOKEN", ""),
 	}
 
 	log := logger.NewLogger(cfg.ServiceName)
@@ -39,17 +48,91 @@ func main() {
 		slog.String("instance_id", cfg.InstanceID),
 	)
 
-	// ⭐ Initialize OpenTelemetry Tracing
-	shutdown, err := tracing.InitTracer(cfg.ServiceName)
+	// PAYMENT_LINK_TTL: Muss zur Stock Reservation TTL passen (Default 15m)
+	cfg.PaymentLinkTTL = processor.DefaultReservationTTL
+	if v := config.GetEnv("PAYMENT_LINK_TTL", ""); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Error("invalid PAYMENT_LINK_TTL", slog.String("value", v), slog.Any("error", err))
+			os.Exit(1)
+		}
+		cfg.PaymentLinkTTL = ttl
+	}
+
+	// ORDERS_UPDATE_ATTEMPTS: Wie oft das Payment Link Update an Orders versucht wird (Default 3)
+	cfg.OrdersUpdateAttempts = gateway.DefaultUpdateAttempts
+	if v := config.GetEnv("ORDERS_UPDATE_ATTEMPTS", ""); v != "" {
+		attempts, err := strconv.Atoi(v)
+		if err != nil || attempts <= 0 {
+			log.Error("invalid ORDERS_UPDATE_ATTEMPTS", slog.String("value", v))
+			os.Exit(1)
+		}
+		cfg.OrdersUpdateAttempts = attempts
+	}
+
+	// SHUTDOWN_DRAIN_TIMEOUT: Wie lange Shutdown auf die laufende order.created Message wartet (Default 30s)
+	cfg.ShutdownDrainTimeout = DefaultShutdownDrainTimeout
+	if v := config.GetEnv("SHUTDOWN_DRAIN_TIMEOUT", ""); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Error("invalid SHUTDOWN_DRAIN_TIMEOUT", slog.String("value", v), slog.Any("error", err))
+			os.Exit(1)
+		}
+		cfg.ShutdownDrainTimeout = d
+	}
+
+	// STRIPE_MAX_CONCURRENCY: Max. gleichzeitige Stripe API Calls dieser Instanz (Default 10, 0 = unbegrenzt)
+	// → Gilt für Consumer UND HTTP Service zusammen → Bursts drosseln wir selbst statt in Stripes 429 zu laufen
+	cfg.StripeMaxConcurrency = processor.DefaultStripeMaxConcurrency
+	if v := config.GetEnv("STRIPE_MAX_CONCURRENCY", ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Error("invalid STRIPE_MAX_CONCURRENCY", slog.String("value", v))
+			os.Exit(1)
+		}
+		cfg.StripeMaxConcurrency = n
+	}
+
+	// RESERVATION_MODE: on_create (Default, Orders reserviert) | on_pay (Payments reserviert)
+	mode, err := config.ParseReservationMode(config.GetEnv("RESERVATION_MODE", ""))
 	if err != nil {
-		log.Error("failed to initialize tracer", slog.Any("error", err))
+		log.Error("invalid RESERVATION_MODE", slog.Any("error", err))
+		os.Exit(1)
+	}
+	cfg.ReservationMode = mode
+
+	// Warum Warnung?
+	// → Stripe Sessions leben mindestens 30 Minuten → bei kürzerer Reservation bleibt ein Fenster
+	//   in dem der Kunde nach Ablauf der Reservation noch zahlen kann
+	if processor.SessionTTL(cfg.PaymentLinkTTL) > cfg.PaymentLinkTTL {
+		log.Warn("payment link ttl below stripe minimum, checkout sessions outlive the reservation",
+			slog.Duration("reservation_ttl", cfg.PaymentLinkTTL),
+			slog.Duration("session_ttl", processor.SessionTTL(cfg.PaymentLinkTTL)),
+		)
+	}
+
+	// ⭐ Startup Report: Tracer, Consul, RabbitMQ → EINE Zusammenfassung am Ende von init
+	report := startup.NewReport(cfg.ServiceName)
+
+	// ⭐ Initialize OpenTelemetry Tracing
+	var shutdown func()
+	if err := report.Check("tracer", true, func() (err error) {
+		shutdown, err = tracing.InitTracer(cfg.ServiceName)
+		return err
+	}); err != nil {
+		report.Log(log)
 		os.Exit(1)
 	}
 	defer shutdown()
 
-	app, err := NewApp(cfg)
+	app, err := NewApp(cfg, report)
 	if err != nil {
-		log.Error("failed to create app", slog.Any("error", err))
+		report.Log(log)
+		os.Exit(1)
+	}
+
+	// Required Dependency down → fail-fast statt halb initialisiertem Service
+	if err := report.Log(log); err != nil {
 		os.Exit(1)
 	}
 
@@ -69,8 +152,30 @@ func main() {
 	}()
 
 	// Initialize OrdersGateway BEFORE creating HTTP handler (CRITICAL for webhook handler!)
-	app.ordersGateway = gateway.NewOrdersGateway(cfg.OrdersAddr)
-	log.Info("orders gateway initialized", slog.String("orders_addr", cfg.OrdersAddr))
+	// ⭐ Retry + order.payment_link Fallback → Payment Creation hängt nicht an Orders Verfügbarkeit
+	app.ordersGateway = gateway.NewRetryingOrdersGateway(
+		gateway.NewOrdersGateway(cfg.OrdersAddr, app.upstream),
+		gateway.NewPaymentLinkPublisher(app.channel),
+		cfg.OrdersUpdateAttempts,
+	)
+	log.Info("orders gateway initialized",
+		slog.String("orders_addr", cfg.OrdersAddr),
+		slog.Int("update_attempts", cfg.OrdersUpdateAttempts),
+	)
+
+	// MONGO_URI: Payment Ledger in MongoDB (geteilt zwischen Instanzen, überlebt Restarts)
+	// → Nicht gesetzt / nicht erreichbar → In-Memory (Refund bei Storno nur auf derselben Instanz)
+	app.ledger = NewMemoryLedger()
+	if uri := config.GetEnv("MONGO_URI", ""); uri != "" {
+		client, err := connectToMongoDB(uri)
+		if err != nil {
+			log.Warn("payment ledger unavailable, falling back to in-memory", slog.Any("error", err))
+		} else {
+			defer client.Disconnect(context.Background())
+			app.ledger = NewMongoLedger(client)
+			log.Info("payment ledger backed by mongodb")
+		}
+	}
 
 	// Start RabbitMQ Consumer in background
 	go func() {
@@ -82,7 +187,11 @@ func main() {
 
 	// Start HTTP Server for Stripe Webhooks in background
 	mux := http.NewServeMux()
-	httpServer := NewPaymentHTTPHandler(app.channel, app.ordersGateway, cfg.OrdersAddr)
+	// Warum eigener Service für HTTP?
+	// → Re-Issue Endpoint erstellt Payment Links synchron (gleiche Logik wie der Consumer)
+	stockGateway := gateway.NewStockGateway(app.registry, app.upstream)
+	paymentService := NewService(processor.NewStripeProcessor(cfg.StripeKey, cfg.PaymentLinkTTL, app.business, app.stripeLimiter), app.ordersGateway, stockGateway, app.ledger, cfg.ReservationMode, app.business, log)
+	httpServer := NewPaymentHTTPHandler(app.channel, app.ordersGateway, stockGateway, cfg.OrdersAddr, paymentService, app.ledger, metrics.NewRevenueMetrics(cfg.ServiceName), cfg.InternalToken)
 	httpServer.registerRoutes(mux)
 
 	go func() {
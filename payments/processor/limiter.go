@@ -0,0 +1,33 @@
+package processor
+
+// DefaultStripeMaxConcurrency: Max. gleichzeitige Stripe API Calls pro Payments Instanz
+// → Stripe Live Limit liegt bei ~100 Requests/s → 10 parallele Calls à ~300ms bleiben weit darunter
+const DefaultStripeMaxConcurrency = 10
+
+// Limiter: Semaphore für Stripe API Calls, geteilt von ALLEN Stripe Processoren einer Instanz
+// Warum?
+// → Burst an order.created + Refunds + HTTP Session Links → alles gleichzeitig zu Stripe → 429
+// → 429 landet über HandleRetry nach 3 Versuchen in der DLQ, obwohl Stripe nur kurz gedrosselt hat
+// → Lieber selbst drosseln: Calls über dem Limit warten auf einen freien Slot
+// nil = unbegrenzt (Acquire ist nil-safe)
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter: max <= 0 → nil (kein Limit)
+func NewLimiter(max int) *Limiter {
+	if max <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, max)}
+}
+
+// Acquire: Blockiert bis ein Slot frei ist, Rückgabe gibt ihn wieder frei
+// Usage: defer s.limiter.Acquire()()
+func (l *Limiter) Acquire() func() {
+	if l == nil {
+		return func() {}
+	}
+	l.slots <- struct{}{}
+	return func() { <-l.slots }
+}
@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+// concurrency: Zählt gleichzeitig laufende Abschnitte und merkt sich das Maximum
+type concurrency struct {
+	current atomic.Int32
+	max     atomic.Int32
+}
+
+func (c *concurrency) enter() {
+	n := c.current.Add(1)
+	for {
+		m := c.max.Load()
+		if n <= m || c.max.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
+
+func (c *concurrency) leave() { c.current.Add(-1) }
+
+// 20 Goroutines, Limit 3 → nie mehr als 3 gleichzeitig, aber das Limit wird auch ausgeschöpft
+func TestLimiterCapsConcurrency(t *testing.T) {
+	l := NewLimiter(3)
+	var c concurrency
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer l.Acquire()()
+			c.enter()
+			time.Sleep(5 * time.Millisecond)
+			c.leave()
+		}()
+	}
+	wg.Wait()
+
+	if got := c.max.Load(); got != 3 {
+		t.Fatalf("max concurrent = %d; want 3", got)
+	}
+}
+
+// max <= 0 → kein Limiter, Acquire auf nil blockiert nie
+func TestNewLimiterUnlimited(t *testing.T) {
+	for _, max := range []int{0, -1} {
+		if l := NewLimiter(max); l != nil {
+			t.Errorf("NewLimiter(%d) = %v; want nil", max, l)
+		}
+	}
+	var l *Limiter
+	release := l.Acquire()
+	release()
+}
+
+// Zwei Processoren teilen EINEN Limiter → gemeinsam nie mehr als 2 Requests bei Stripe
+func TestStripeProcessorsShareLimiter(t *testing.T) {
+	var c concurrency
+	stubStripeHandler(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.enter()
+		defer c.leave()
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"cs_1","object":"checkout.session","url":"https://checkout.stripe.com/c/pay/cs_1"}`)
+	}))
+
+	limiter := NewLimiter(2)
+	processors := []*Stripe{
+		NewStripeProcessor("sk_test_stub", 15*time.Minute, nil, limiter),
+		NewStripeProcessor("sk_test_stub", 15*time.Minute, nil, limiter),
+	}
+	order := &pb.Order{Id: "o1", CustomerId: "c1", Items: []*pb.Item{{ID: "1", PriceID: "price_1", Quantity: 1}}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(p *Stripe) {
+			defer wg.Done()
+			if _, err := p.CreatePaymentLink(order); err != nil {
+				t.Errorf("CreatePaymentLink: %v", err)
+			}
+		}(processors[i%2])
+	}
+	wg.Wait()
+
+	if got := c.max.Load(); got > 2 {
+		t.Fatalf("max concurrent Stripe requests = %d; want at most 2", got)
+	}
+}
@@ -1,9 +1,38 @@
 package processor
 
 import (
+	"time"
+
 	pb "github.com/timour/order-microservices/common/api"
 )
 
+// CheckoutSession: Ergebnis von CreatePaymentLink → URL für den Kunden + Daten fürs Payment Ledger
+type CheckoutSession struct {
+	ID          string // Stripe Checkout Session ID ("cs_...")
+	URL         string
+	AmountTotal int64  // Kleinste Währungseinheit (Cent)
+	Currency    string // ISO Code, lowercase ("eur")
+	// ExpiresAt: Stripe schließt die Session danach (SessionTTL) → Countdown für den Kunden
+	ExpiresAt time.Time
+}
+
+// PaidCheckout: Bezahlte Checkout Session einer Order (aus dem Payment Ledger)
+type PaidCheckout struct {
+	OrderID         string
+	SessionID       string // "cs_..." → Line Items mit den tatsächlich gezahlten Beträgen
+	PaymentIntentID string // Leer → wird über die Session aufgelöst
+	StripeAccount   string // Connect Account der Session (leer = Plattform) → Refund im selben Account
+}
+
 type PaymentProcessor interface {
-	CreatePaymentLink(*pb.Order) (string, error)
+	CreatePaymentLink(*pb.Order) (*CheckoutSession, error)
+	// CreateSessionPaymentLink: EIN Checkout für alle unbezahlten Orders eines Tisches/einer Session
+	CreateSessionPaymentLink(sessionID string, orders []*pb.Order) (*CheckoutSession, error)
+	// RefundItems: Anteilige Rückerstattung für entfernte Items → erstatteter Betrag (kleinste Währungseinheit)
+	// → Betrag aus dem was in checkout tatsächlich gezahlt wurde, nicht aus dem heutigen Preis
+	RefundItems(checkout PaidCheckout, idempotencyKey string, items []*pb.Item) (int64, error)
+	// RefundPayment: Volle Rückerstattung einer Checkout Session/eines PaymentIntents → Stripe Refund ID
+	RefundPayment(paymentID, stripeAccount, idempotencyKey string) (string, error)
+	// ExpireSession: Offene Checkout Session schließen (Re-Issue) → ErrSessionCompleted wenn schon bezahlt
+	ExpireSession(sessionID, stripeAccount string) error
 }
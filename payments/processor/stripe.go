@@ -1,42 +1,92 @@
 package processor
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
 	"github.com/stripe/stripe-go/v78"
 	"github.com/stripe/stripe-go/v78/checkout/session"
+	"github.com/stripe/stripe-go/v78/refund"
 )
 
+// DefaultReservationTTL: Muss zu stock.ReservationTTL passen
+// → Solange hält Stock die Items für eine unbezahlte Order
+const DefaultReservationTTL = 15 * time.Minute
+
+// Stripe akzeptiert expires_at nur zwischen 30 Minuten und 24 Stunden in der Zukunft
+const (
+	StripeMinSessionTTL = 30 * time.Minute
+	StripeMaxSessionTTL = 24 * time.Hour
+)
+
+// sessionExpirySafetyMargin: Session läuft etwas VOR der Reservation ab
+// → Kein Zahlungsfenster in dem der Cleanup Job die Items schon freigegeben hat
+const sessionExpirySafetyMargin = time.Minute
+
 // Warum Stripe struct?
 // → Kapselt Stripe API Key
 // → Könnte später erweitert werden (Mock für Tests, etc.)
 type Stripe struct {
-	apiKey string
+	apiKey  string
+	linkTTL time.Duration            // Gültigkeit eines Payment Links (≈ Reservation TTL)
+	metrics *metrics.BusinessMetrics // Stripe API Latenz (nil = aus)
+	limiter *Limiter                 // Max. parallele Stripe Calls, geteilt mit den anderen Processoren (nil = unbegrenzt)
 }
 
 // Warum stripe.Key = apiKey?
 // → Setzt GLOBALEN API Key für Stripe SDK
 // → Alle Stripe API Calls nutzen diesen Key
-func NewStripeProcessor(apiKey string) *Stripe {
+func NewStripeProcessor(apiKey string, linkTTL time.Duration, businessMetrics *metrics.BusinessMetrics, limiter *Limiter) *Stripe {
 	stripe.Key = apiKey
 	return &Stripe{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		linkTTL: linkTTL,
+		metrics: businessMetrics,
+		limiter: limiter,
 	}
 }
 
+// newSession: session.New mit Latenz Messung
+// → Auch Fehler werden gemessen → langsames Stripe sieht man auch wenn es am Ende 500 liefert
+// → Wartezeit auf einen Limiter Slot zählt NICHT zur Stripe Latenz
+func (s *Stripe) newSession(params *stripe.CheckoutSessionParams) (*stripe.CheckoutSession, error) {
+	defer s.limiter.Acquire()()
+
+	start := time.Now()
+	defer func() { s.metrics.ObserveStripeAPI(time.Since(start)) }()
+	return session.New(params)
+}
+
+// SessionTTL: Wie lange eine Checkout Session für eine Reservation TTL gültig ist
+// Warum nicht einfach 24h (Stripe Default)?
+// → Reservation wird nach TTL freigegeben und evtl. weiterverkauft
+// → Kunde könnte sonst für Stock zahlen den es nicht mehr gibt
+//
+// ⚠️ Stripe Minimum ist 30 Minuten → kürzere TTLs werden darauf angehoben
+// → Session lebt dann länger als die Order → order.expired/order.cancelled schließen sie per ExpireSession
+func SessionTTL(reservationTTL time.Duration) time.Duration {
+	ttl := reservationTTL - sessionExpirySafetyMargin
+	return min(max(ttl, StripeMinSessionTTL), StripeMaxSessionTTL)
+}
+
 // CreatePaymentLink: Erstellt Stripe Checkout Session
 // Warum Checkout Session (nicht Payment Intent)?
 // → Checkout Session = Hosted Payment Page von Stripe
 // → User wird auf Stripe Website redirected → einfacher!
 // → Payment Intent = Für eigene Payment UI (komplexer)
-func (s *Stripe) CreatePaymentLink(o *pb.Order) (string, error) {
+func (s *Stripe) CreatePaymentLink(o *pb.Order) (*CheckoutSession, error) {
 	log.Printf("Creating payment link for order ID:%q customerID:%q Status:%q Items:%+v",
 		o.Id, o.CustomerId, o.Status, o.Items)
 
 	if o == nil {
-		return "", fmt.Errorf("order is nil")
+		return nil, fmt.Errorf("order is nil")
 	}
 
 	// Warum lineItems aus Order.Items bauen?
@@ -51,6 +101,29 @@ func (s *Stripe) CreatePaymentLink(o *pb.Order) (string, error) {
 		})
 	}
 
+	params := newCheckoutSessionParams(o, lineItems, time.Now().Add(SessionTTL(s.linkTTL)))
+
+	// Warum session.New?
+	// → Ruft Stripe API: POST /v1/checkout/sessions
+	// → Gibt CheckoutSession zurück mit URL (z.B. "https://checkout.stripe.com/c/pay/cs_test_...")
+	result, err := s.newSession(params)
+	if err != nil {
+		log.Printf("[ERROR] Request error from Stripe (status 400): %v", err)
+		return nil, fmt.Errorf("failed to create stripe session: %w", err)
+	}
+
+	log.Printf("Payment link created: %s", result.URL)
+	return &CheckoutSession{
+		ID:          result.ID,
+		URL:         result.URL, // URL: User kann auf diesen Link klicken!
+		AmountTotal: result.AmountTotal,
+		Currency:    string(result.Currency),
+		ExpiresAt:   time.Unix(result.ExpiresAt, 0),
+	}, nil
+}
+
+// newCheckoutSessionParams: Baut die Stripe Session Params (ohne API Call → testbar)
+func newCheckoutSessionParams(o *pb.Order, lineItems []*stripe.CheckoutSessionLineItemParams, expiresAt time.Time) *stripe.CheckoutSessionParams {
 	// Warum SuccessURL + CancelURL?
 	// → SuccessURL: Wohin nach erfolgreicher Payment? → Gateway success.html
 	// → CancelURL: User klickt "Zurück" → Gateway cancel.html
@@ -66,21 +139,381 @@ func (s *Stripe) CreatePaymentLink(o *pb.Order) (string, error) {
 			"orderID":    o.Id,
 			"customerID": o.CustomerId,
 		},
+		// Warum Metadata AUCH am PaymentIntent?
+		// → Refunds brauchen den PaymentIntent → Suche per metadata['orderID']
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: map[string]string{
+				"orderID": o.Id,
+			},
+		},
 		LineItems:  lineItems,
 		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),  // "payment" (einmalig, nicht subscription)
 		SuccessURL: stripe.String(gatewaySuccessURL),
 		CancelURL:  stripe.String(gatewayCancelURL),
+		// ⭐ Session läuft mit der Reservation ab (nicht nach Stripes 24h Default)
+		ExpiresAt: stripe.Int64(expiresAt.Unix()),
 	}
+	setStripeAccount(&params.Params, o.StripeAccount)
+	return params
+}
 
-	// Warum session.New?
-	// → Ruft Stripe API: POST /v1/checkout/sessions
-	// → Gibt CheckoutSession zurück mit URL (z.B. "https://checkout.stripe.com/c/pay/cs_test_...")
-	result, err := session.New(params)
+// setStripeAccount: Stripe Connect → Request läuft im Account des Restaurants ("Stripe-Account" Header)
+// Warum optional?
+// → Leer = Plattform Account (bisheriges Verhalten, Single-Tenant)
+// ⚠️ PriceIDs der Items müssen dann im Connected Account existieren
+func setStripeAccount(params *stripe.Params, account string) {
+	if account != "" {
+		params.SetStripeAccount(account)
+	}
+}
+
+// sessionStripeAccount: Gemeinsamer Connect Account aller Orders einer Session
+// → EINE Checkout Session kann nur an EINEN Account gehen
+func sessionStripeAccount(orders []*pb.Order) (string, error) {
+	account := orders[0].StripeAccount
+	for _, o := range orders[1:] {
+		if o.StripeAccount != account {
+			return "", fmt.Errorf("orders %s and %s belong to different stripe accounts", orders[0].Id, o.Id)
+		}
+	}
+	return account, nil
+}
+
+// CreateSessionPaymentLink: Gemeinsamer Checkout für mehrere Orders (Dine-In Tisch)
+// Warum EINE Session statt N Links?
+// → Tisch zahlt einmal, nicht jede Bestellung einzeln
+// → Webhook bekommt alle Order IDs über die Metadata zurück → markiert jede Order als "paid"
+func (s *Stripe) CreateSessionPaymentLink(sessionID string, orders []*pb.Order) (*CheckoutSession, error) {
+	if sessionID == "" || len(orders) == 0 {
+		return nil, fmt.Errorf("session %q without orders", sessionID)
+	}
+
+	account, err := sessionStripeAccount(orders)
 	if err != nil {
-		log.Printf("[ERROR] Request error from Stripe (status 400): %v", err)
-		return "", fmt.Errorf("failed to create stripe session: %w", err)
+		return nil, err
 	}
 
-	log.Printf("Payment link created: %s", result.URL)
-	return result.URL, nil  // URL: User kann auf diesen Link klicken!
+	log.Printf("Creating combined payment link for session %q (%d orders)", sessionID, len(orders))
+
+	params := newSessionCheckoutParams(sessionID, orders, time.Now().Add(SessionTTL(s.linkTTL)))
+	setStripeAccount(&params.Params, account)
+
+	result, err := s.newSession(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stripe session: %w", err)
+	}
+
+	log.Printf("Combined payment link created for session %q: %s", sessionID, result.URL)
+	return &CheckoutSession{
+		ID:          result.ID,
+		URL:         result.URL,
+		AmountTotal: result.AmountTotal,
+		Currency:    string(result.Currency),
+		ExpiresAt:   time.Unix(result.ExpiresAt, 0),
+	}, nil
+}
+
+// SessionLineItems: Fasst die Items aller Orders pro PriceID zusammen
+// → 2 Orders mit je 1x Burger = EINE Zeile "Burger x2" im Checkout
+// → Reihenfolge = erstes Vorkommen (stabil für den Kunden)
+func SessionLineItems(orders []*pb.Order) []*stripe.CheckoutSessionLineItemParams {
+	var lineItems []*stripe.CheckoutSessionLineItemParams
+	byPrice := make(map[string]*stripe.CheckoutSessionLineItemParams)
+
+	for _, o := range orders {
+		for _, item := range o.Items {
+			if existing, ok := byPrice[item.PriceID]; ok {
+				*existing.Quantity += int64(item.Quantity)
+				continue
+			}
+			lineItem := &stripe.CheckoutSessionLineItemParams{
+				Price:    stripe.String(item.PriceID),
+				Quantity: stripe.Int64(int64(item.Quantity)),
+			}
+			byPrice[item.PriceID] = lineItem
+			lineItems = append(lineItems, lineItem)
+		}
+	}
+
+	return lineItems
+}
+
+// Metadata Keys des kombinierten Checkouts
+// → orderIDs/customerIDs komma-getrennt, gleiche Reihenfolge (Index i = Order i)
+// ⚠️ Stripe Metadata Values max. 500 Zeichen → ca. 20 Orders pro Session
+const (
+	MetadataSessionID   = "sessionID"
+	MetadataOrderIDs    = "orderIDs"
+	MetadataCustomerIDs = "customerIDs"
+)
+
+func newSessionCheckoutParams(sessionID string, orders []*pb.Order, expiresAt time.Time) *stripe.CheckoutSessionParams {
+	orderIDs := make([]string, 0, len(orders))
+	customerIDs := make([]string, 0, len(orders))
+	for _, o := range orders {
+		orderIDs = append(orderIDs, o.Id)
+		customerIDs = append(customerIDs, o.CustomerId)
+	}
+
+	successURL := fmt.Sprintf("http://localhost:8081/success.html?sessionID=%s", sessionID)
+	cancelURL := "http://localhost:8081/cancel.html"
+
+	return &stripe.CheckoutSessionParams{
+		Metadata: map[string]string{
+			MetadataSessionID:   sessionID,
+			MetadataOrderIDs:    strings.Join(orderIDs, ","),
+			MetadataCustomerIDs: strings.Join(customerIDs, ","),
+		},
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: map[string]string{
+				MetadataSessionID: sessionID,
+			},
+		},
+		LineItems:  SessionLineItems(orders),
+		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),
+		SuccessURL: stripe.String(successURL),
+		CancelURL:  stripe.String(cancelURL),
+		ExpiresAt:  stripe.Int64(expiresAt.Unix()),
+	}
+}
+
+// RefundItems: Erstellt eine anteilige Stripe Rückerstattung für entfernte Items
+// Warum Beträge aus den Line Items der Checkout Session statt price.Get?
+// → price.Get liefert den HEUTIGEN Preis → Preisänderung seit der Zahlung = falscher Refund
+// → Line Items enthalten was der Kunde wirklich gezahlt hat (inkl. Rabatte/Steuern)
+// Warum idempotencyKey?
+// → Consumer Retry nach Timeout darf NICHT doppelt erstatten
+func (s *Stripe) RefundItems(checkout PaidCheckout, idempotencyKey string, items []*pb.Item) (int64, error) {
+	paid, err := s.paidLines(checkout.SessionID, checkout.StripeAccount)
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := RefundAmount(items, paid)
+	if err != nil {
+		return 0, err
+	}
+	if amount == 0 {
+		return 0, nil
+	}
+
+	paymentIntentID := checkout.PaymentIntentID
+	if paymentIntentID == "" {
+		if paymentIntentID, err = s.sessionPaymentIntent(checkout.SessionID, checkout.StripeAccount); err != nil {
+			return 0, err
+		}
+	}
+
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(paymentIntentID),
+		Amount:        stripe.Int64(amount),
+		Reason:        stripe.String(string(stripe.RefundReasonRequestedByCustomer)),
+		Metadata: map[string]string{
+			"orderID": checkout.OrderID,
+		},
+	}
+	params.SetIdempotencyKey(idempotencyKey)
+	setStripeAccount(&params.Params, checkout.StripeAccount)
+
+	release := s.limiter.Acquire()
+	result, err := refund.New(params)
+	release()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create stripe refund: %w", err)
+	}
+
+	log.Printf("Refund %s created for order %s: %d", result.ID, checkout.OrderID, amount)
+	return amount, nil
+}
+
+// PaidLine: Was für eine Price ID in der Checkout Session gezahlt wurde (alle Line Items dieser Price ID)
+type PaidLine struct {
+	Amount   int64 // AmountTotal: nach Rabatten + Steuern, kleinste Währungseinheit
+	Quantity int64
+}
+
+// paidLines: Line Items der Checkout Session → PaidLine pro Price ID
+func (s *Stripe) paidLines(sessionID, stripeAccount string) (map[string]PaidLine, error) {
+	defer s.limiter.Acquire()() // Iterator ruft Stripe erst in Next() → Slot über die ganze Liste halten
+
+	paid := make(map[string]PaidLine)
+	params := &stripe.CheckoutSessionListLineItemsParams{Session: stripe.String(sessionID)}
+	if stripeAccount != "" {
+		params.SetStripeAccount(stripeAccount) // Session eines Connect Accounts gibt es nur dort
+	}
+	iter := session.ListLineItems(params)
+	for iter.Next() {
+		item := iter.LineItem()
+		if item.Price == nil {
+			continue
+		}
+		line := paid[item.Price.ID]
+		line.Amount += item.AmountTotal
+		line.Quantity += item.Quantity
+		paid[item.Price.ID] = line
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list line items of checkout session %s: %w", sessionID, err)
+	}
+	return paid, nil
+}
+
+// RefundAmount: Anteil des gezahlten Betrags für die entfernte Menge (kleinste Währungseinheit, z.B. Cent)
+// → Pro Price ID: Amount × entfernt / gezahlt (abgerundet → nie mehr erstatten als gezahlt)
+// → Pure Function: Kein Stripe Call, leicht nachzurechnen
+func RefundAmount(items []*pb.Item, paid map[string]PaidLine) (int64, error) {
+	removed := make(map[string]int64, len(items))
+	var order []string
+	for _, item := range items {
+		if _, ok := paid[item.PriceID]; !ok {
+			return 0, fmt.Errorf("price %s (item %s) was not paid in this checkout", item.PriceID, item.ID)
+		}
+		if _, seen := removed[item.PriceID]; !seen {
+			order = append(order, item.PriceID)
+		}
+		removed[item.PriceID] += int64(item.Quantity)
+	}
+
+	var total int64
+	for _, priceID := range order {
+		line := paid[priceID]
+		if removed[priceID] > line.Quantity {
+			return 0, fmt.Errorf("cannot refund %d of price %s, only %d were paid", removed[priceID], priceID, line.Quantity)
+		}
+		total += line.Amount * removed[priceID] / line.Quantity
+	}
+	return total, nil
+}
+
+// RateLimited: Hat Stripe mit 429 / "rate_limit" geantwortet? → (Retry-After, true)
+// → errors.As: Funktioniert auch durch fmt.Errorf("...: %w") Wrapping im Service
+// → Retry-After fehlt (Stripe schickt ihn nicht immer) → 0, der Caller nutzt seinen eigenen Backoff
+func RateLimited(err error) (time.Duration, bool) {
+	var stripeErr *stripe.Error
+	if !errors.As(err, &stripeErr) {
+		return 0, false
+	}
+	if stripeErr.HTTPStatusCode != http.StatusTooManyRequests && stripeErr.Code != stripe.ErrorCodeRateLimit {
+		return 0, false
+	}
+	if stripeErr.LastResponse == nil {
+		return 0, true
+	}
+	return parseRetryAfter(stripeErr.LastResponse.Header.Get("Retry-After")), true
+}
+
+// parseRetryAfter: Sekunden ("30") oder HTTP-Date → Wartezeit (ungültig/leer → 0)
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// ErrAlreadyRefunded: Zahlung wurde schon (vollständig) erstattet → für den Caller ein Erfolg
+var ErrAlreadyRefunded = errors.New("payment already refunded")
+
+// RefundPayment: Volle Rückerstattung einer Zahlung (stornierte, bezahlte Order)
+// paymentID: Checkout Session ("cs_...") ODER PaymentIntent ("pi_...")
+// → Session wird über Stripe zum PaymentIntent aufgelöst (Refunds gehen nur auf PaymentIntents/Charges)
+// Warum idempotencyKey?
+// → Consumer Retry nach Timeout → Stripe liefert den ERSTEN Refund zurück statt einen zweiten anzulegen
+// Returns: Stripe Refund ID | ErrAlreadyRefunded wenn schon (z.B. manuell im Dashboard) erstattet
+func (s *Stripe) RefundPayment(paymentID, stripeAccount, idempotencyKey string) (string, error) {
+	paymentIntentID := paymentID
+	if strings.HasPrefix(paymentID, "cs_") {
+		var err error
+		if paymentIntentID, err = s.sessionPaymentIntent(paymentID, stripeAccount); err != nil {
+			return "", err
+		}
+	}
+
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(paymentIntentID),
+		Reason:        stripe.String(string(stripe.RefundReasonRequestedByCustomer)),
+	}
+	setStripeAccount(&params.Params, stripeAccount)
+	params.SetIdempotencyKey(idempotencyKey)
+
+	release := s.limiter.Acquire()
+	result, err := refund.New(params)
+	release()
+	if err != nil {
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) && stripeErr.Code == stripe.ErrorCodeChargeAlreadyRefunded {
+			return "", ErrAlreadyRefunded
+		}
+		return "", fmt.Errorf("failed to create stripe refund for %s: %w", paymentIntentID, err)
+	}
+
+	log.Printf("Refund %s created for payment intent %s", result.ID, paymentIntentID)
+	return result.ID, nil
+}
+
+// sessionPaymentIntent: Checkout Session → PaymentIntent (Refunds gehen nur auf PaymentIntents/Charges)
+func (s *Stripe) sessionPaymentIntent(sessionID, stripeAccount string) (string, error) {
+	params := &stripe.CheckoutSessionParams{}
+	setStripeAccount(&params.Params, stripeAccount)
+
+	release := s.limiter.Acquire()
+	cs, err := session.Get(sessionID, params)
+	release()
+	if err != nil {
+		return "", fmt.Errorf("failed to get checkout session %s: %w", sessionID, err)
+	}
+	if cs.PaymentIntent == nil {
+		return "", fmt.Errorf("checkout session %s has no payment intent", sessionID)
+	}
+	return cs.PaymentIntent.ID, nil
+}
+
+// ErrSessionCompleted: Checkout Session ist schon bezahlt → lässt sich nicht mehr schließen
+var ErrSessionCompleted = errors.New("checkout session already completed")
+
+// ExpireSession: Offene Checkout Session schließen → der alte Link nimmt keine Zahlung mehr an
+// Warum?
+// → Neuer Link (Re-Issue) ohne Expire: Alte Session bleibt bis zu SessionTTL offen → Kunde zahlt doppelt
+// → Stripe expired nur "open" Sessions → bei 400 den Status nachschlagen statt den Fehlertext zu parsen
+// Returns: nil auch wenn die Session schon abgelaufen ist | ErrSessionCompleted wenn schon bezahlt
+func (s *Stripe) ExpireSession(sessionID, stripeAccount string) error {
+	params := &stripe.CheckoutSessionExpireParams{}
+	setStripeAccount(&params.Params, stripeAccount)
+
+	release := s.limiter.Acquire()
+	_, err := session.Expire(sessionID, params)
+	release()
+	if err == nil {
+		log.Printf("Checkout session %s expired", sessionID)
+		return nil
+	}
+
+	var stripeErr *stripe.Error
+	if !errors.As(err, &stripeErr) || stripeErr.HTTPStatusCode != http.StatusBadRequest {
+		return fmt.Errorf("failed to expire checkout session %s: %w", sessionID, err)
+	}
+
+	getParams := &stripe.CheckoutSessionParams{}
+	setStripeAccount(&getParams.Params, stripeAccount)
+
+	release = s.limiter.Acquire()
+	cs, getErr := session.Get(sessionID, getParams)
+	release()
+	if getErr != nil {
+		return fmt.Errorf("failed to expire checkout session %s: %w", sessionID, err)
+	}
+	switch cs.Status {
+	case stripe.CheckoutSessionStatusExpired:
+		return nil
+	case stripe.CheckoutSessionStatusComplete:
+		return ErrSessionCompleted
+	}
+	return fmt.Errorf("failed to expire checkout session %s (status %q): %w", sessionID, cs.Status, err)
 }
@@ -0,0 +1,310 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stripe/stripe-go/v78"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+func TestRefundAmount(t *testing.T) {
+	// Gezahlt laut Checkout Session (nicht der heutige Preis)
+	paid := map[string]PaidLine{
+		"price_burger": {Amount: 3 * 899, Quantity: 3}, // 8,99 €
+		"price_pommes": {Amount: 349, Quantity: 1},
+	}
+	removed := []*pb.Item{
+		{ID: "1", PriceID: "price_burger", Quantity: 2},
+		{ID: "2", PriceID: "price_pommes", Quantity: 1},
+	}
+
+	amount, err := RefundAmount(removed, paid)
+	if err != nil {
+		t.Fatalf("RefundAmount: %v", err)
+	}
+	if amount != 2*899+349 {
+		t.Fatalf("RefundAmount = %d; want %d", amount, 2*899+349)
+	}
+}
+
+// Rabatt auf die Line Item Summe → anteilig vom GEZAHLTEN Betrag, abgerundet
+func TestRefundAmountUsesPaidTotal(t *testing.T) {
+	paid := map[string]PaidLine{"price_burger": {Amount: 1000, Quantity: 3}} // 3 Burger für 10,00 € statt 26,97 €
+
+	amount, err := RefundAmount([]*pb.Item{{ID: "1", PriceID: "price_burger", Quantity: 1}}, paid)
+	if err != nil {
+		t.Fatalf("RefundAmount: %v", err)
+	}
+	if amount != 333 {
+		t.Fatalf("RefundAmount = %d; want 333", amount)
+	}
+}
+
+// Unbekannter Preis oder mehr als gezahlt → lieber gar nicht erstatten als einen falschen Betrag
+func TestRefundAmountRejectsUnpaidItems(t *testing.T) {
+	paid := map[string]PaidLine{"price_burger": {Amount: 899, Quantity: 1}}
+
+	tests := map[string][]*pb.Item{
+		"unknown price": {{ID: "9", PriceID: "price_unknown", Quantity: 1}},
+		"more than paid": {
+			{ID: "1", PriceID: "price_burger", Quantity: 1},
+			{ID: "1", PriceID: "price_burger", Quantity: 1},
+		},
+	}
+	for name, items := range tests {
+		if _, err := RefundAmount(items, paid); err == nil {
+			t.Errorf("%s: RefundAmount succeeded; want error", name)
+		}
+	}
+}
+
+func TestSessionTTL(t *testing.T) {
+	tests := []struct {
+		name           string
+		reservationTTL time.Duration
+		want           time.Duration
+	}{
+		{"safety margin before reservation expiry", 45 * time.Minute, 44 * time.Minute},
+		{"raised to stripe minimum", DefaultReservationTTL, StripeMinSessionTTL},
+		{"capped at stripe maximum", 48 * time.Hour, StripeMaxSessionTTL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SessionTTL(tt.reservationTTL); got != tt.want {
+				t.Fatalf("SessionTTL(%s) = %s; want %s", tt.reservationTTL, got, tt.want)
+			}
+		})
+	}
+}
+
+// Session Params tragen die aus der Reservation TTL abgeleitete Ablaufzeit
+func TestCheckoutSessionParamsExpiresAt(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(SessionTTL(45 * time.Minute))
+
+	params := newCheckoutSessionParams(&pb.Order{Id: "o1", CustomerId: "c1"}, nil, expiresAt)
+
+	if params.ExpiresAt == nil {
+		t.Fatal("ExpiresAt not set; stripe would default to 24h")
+	}
+	if want := now.Add(44 * time.Minute).Unix(); *params.ExpiresAt != want {
+		t.Fatalf("ExpiresAt = %d; want %d (reservation TTL minus safety margin)", *params.ExpiresAt, want)
+	}
+	if params.Metadata["orderID"] != "o1" || params.Metadata["customerID"] != "c1" {
+		t.Errorf("Metadata = %v; want orderID o1, customerID c1", params.Metadata)
+	}
+}
+
+// Gleiche PriceID über mehrere Orders → EINE Zeile mit summierter Menge, Reihenfolge = erstes Vorkommen
+func TestSessionLineItems(t *testing.T) {
+	orders := []*pb.Order{
+		{Id: "o1", Items: []*pb.Item{{PriceID: "price_burger", Quantity: 1}, {PriceID: "price_pommes", Quantity: 1}}},
+		{Id: "o2", Items: []*pb.Item{{PriceID: "price_burger", Quantity: 2}}},
+	}
+
+	lineItems := SessionLineItems(orders)
+	if len(lineItems) != 2 {
+		t.Fatalf("line items = %d; want 2", len(lineItems))
+	}
+	if *lineItems[0].Price != "price_burger" || *lineItems[0].Quantity != 3 {
+		t.Errorf("line 0 = %s x%d; want price_burger x3", *lineItems[0].Price, *lineItems[0].Quantity)
+	}
+	if *lineItems[1].Price != "price_pommes" || *lineItems[1].Quantity != 1 {
+		t.Errorf("line 1 = %s x%d; want price_pommes x1", *lineItems[1].Price, *lineItems[1].Quantity)
+	}
+}
+
+// Webhook braucht alle Order IDs der Session zurück → Metadata in Order Reihenfolge
+func TestSessionCheckoutParams(t *testing.T) {
+	orders := []*pb.Order{
+		{Id: "o1", CustomerId: "c1", Items: []*pb.Item{{PriceID: "price_burger", Quantity: 1}}},
+		{Id: "o2", CustomerId: "c2", Items: []*pb.Item{{PriceID: "price_burger", Quantity: 1}}},
+	}
+	expiresAt := time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	params := newSessionCheckoutParams("t7", orders, expiresAt)
+
+	want := map[string]string{
+		MetadataSessionID:   "t7",
+		MetadataOrderIDs:    "o1,o2",
+		MetadataCustomerIDs: "c1,c2",
+	}
+	for k, v := range want {
+		if params.Metadata[k] != v {
+			t.Errorf("Metadata[%s] = %q; want %q", k, params.Metadata[k], v)
+		}
+	}
+	if len(params.LineItems) != 1 || *params.LineItems[0].Quantity != 2 {
+		t.Errorf("line items = %+v; want one combined burger line x2", params.LineItems)
+	}
+	if params.ExpiresAt == nil || *params.ExpiresAt != expiresAt.Unix() {
+		t.Errorf("ExpiresAt = %v; want %d", params.ExpiresAt, expiresAt.Unix())
+	}
+}
+
+// Connect Account der Order → "Stripe-Account" Header, leer → Plattform Account
+func TestCheckoutSessionParamsStripeAccount(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	params := newCheckoutSessionParams(&pb.Order{Id: "o1", StripeAccount: "acct_123"}, nil, expiresAt)
+	if params.StripeAccount == nil || *params.StripeAccount != "acct_123" {
+		t.Fatalf("StripeAccount = %v; want acct_123", params.StripeAccount)
+	}
+
+	params = newCheckoutSessionParams(&pb.Order{Id: "o2"}, nil, expiresAt)
+	if params.StripeAccount != nil {
+		t.Fatalf("StripeAccount = %q; want unset (platform account)", *params.StripeAccount)
+	}
+}
+
+func TestSessionStripeAccount(t *testing.T) {
+	account, err := sessionStripeAccount([]*pb.Order{
+		{Id: "o1", StripeAccount: "acct_123"},
+		{Id: "o2", StripeAccount: "acct_123"},
+	})
+	if err != nil || account != "acct_123" {
+		t.Fatalf("sessionStripeAccount = %q, %v; want acct_123", account, err)
+	}
+
+	// EINE Checkout Session kann nicht an zwei Restaurants gehen
+	if _, err := sessionStripeAccount([]*pb.Order{
+		{Id: "o1", StripeAccount: "acct_123"},
+		{Id: "o2"},
+	}); err == nil {
+		t.Fatal("sessionStripeAccount with mixed accounts succeeded")
+	}
+}
+
+// stubStripe: Stripe API Stub, antwortet mit status + body → stripe-go nutzt globale Backends
+func stubStripe(t *testing.T, status int, body string) {
+	t.Helper()
+	stubStripeHandler(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+}
+
+// stubStripeHandler: Wie stubStripe, aber mit eigenem Handler (z.B. langsame Antworten)
+func stubStripeHandler(t *testing.T, h http.Handler) {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+
+	prev := stripe.GetBackend(stripe.APIBackend)
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL:               stripe.String(srv.URL),
+		MaxNetworkRetries: stripe.Int64(0),
+		LeveledLogger:     &stripe.LeveledLogger{Level: stripe.LevelNull},
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		stripe.SetBackend(stripe.APIBackend, prev)
+	})
+}
+
+func stripeCalls(t *testing.T, m *metrics.BusinessMetrics) uint64 {
+	t.Helper()
+	var out dto.Metric
+	if err := m.StripeAPIDuration.Write(&out); err != nil {
+		t.Fatalf("write histogram: %v", err)
+	}
+	return out.GetHistogram().GetSampleCount()
+}
+
+// Jeder session.New Call wird gemessen → auch der fehlgeschlagene (langsames Stripe das am Ende 500 liefert)
+func TestCreatePaymentLinkObservesStripeLatency(t *testing.T) {
+	m := metrics.NewBusinessMetrics("stripe_latency_test")
+	s := NewStripeProcessor("sk_test_stub", 15*time.Minute, m, nil)
+	order := &pb.Order{Id: "o1", CustomerId: "c1", Items: []*pb.Item{{ID: "1", PriceID: "price_1", Quantity: 1}}}
+
+	stubStripe(t, http.StatusOK, `{"id":"cs_1","object":"checkout.session","url":"https://checkout.stripe.com/c/pay/cs_1"}`)
+	checkout, err := s.CreatePaymentLink(order)
+	if err != nil {
+		t.Fatalf("CreatePaymentLink: %v", err)
+	}
+	if checkout.URL != "https://checkout.stripe.com/c/pay/cs_1" {
+		t.Errorf("URL = %q; want the stub session URL", checkout.URL)
+	}
+	if n := stripeCalls(t, m); n != 1 {
+		t.Fatalf("stripe calls observed = %d; want 1", n)
+	}
+
+	stubStripe(t, http.StatusInternalServerError, `{"error":{"type":"api_error","message":"boom"}}`)
+	if _, err := s.CreatePaymentLink(order); err == nil {
+		t.Fatal("CreatePaymentLink succeeded; want Stripe error")
+	}
+	if n := stripeCalls(t, m); n != 2 {
+		t.Errorf("stripe calls observed = %d; want 2 (failed call counts too)", n)
+	}
+}
+
+// Stripe 429 (mit Retry-After) → RateLimited erkennt es, auch durch Wrapping im Service
+// Andere Stripe Fehler und Nicht-Stripe Fehler → kein Rate Limit
+func TestRateLimited(t *testing.T) {
+	s := NewStripeProcessor("sk_test_stub", 15*time.Minute, nil, nil)
+	order := &pb.Order{Id: "o1", CustomerId: "c1", Items: []*pb.Item{{ID: "1", PriceID: "price_1", Quantity: 1}}}
+
+	stubStripeHandler(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"error":{"type":"invalid_request_error","code":"rate_limit","message":"Too many requests"}}`)
+	}))
+	_, err := s.CreatePaymentLink(order)
+	if retryAfter, ok := RateLimited(fmt.Errorf("create payment: %w", err)); !ok || retryAfter != 30*time.Second {
+		t.Errorf("RateLimited(429) = %s, %v; want 30s, true", retryAfter, ok)
+	}
+
+	stubStripe(t, http.StatusBadRequest, `{"error":{"type":"invalid_request_error","message":"No such price"}}`)
+	_, err = s.CreatePaymentLink(order)
+	if _, ok := RateLimited(err); err == nil || ok {
+		t.Errorf("RateLimited(400) = %v (err %v); want false", ok, err)
+	}
+	if _, ok := RateLimited(errors.New("connection reset")); ok {
+		t.Error("RateLimited(non-Stripe error) = true; want false")
+	}
+}
+
+// Expire auf eine nicht mehr offene Session → Stripe 400 → Status entscheidet
+// → expired: Ziel schon erreicht (nil) | complete: bezahlt → ErrSessionCompleted
+func TestExpireSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		expire  int
+		status  string
+		wantErr error
+	}{
+		{"open session", http.StatusOK, "expired", nil},
+		{"already expired", http.StatusBadRequest, "expired", nil},
+		{"already paid", http.StatusBadRequest, "complete", ErrSessionCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStripeProcessor("sk_test_stub", 15*time.Minute, nil, nil)
+			stubStripeHandler(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/expire") && tt.expire != http.StatusOK {
+					w.WriteHeader(tt.expire)
+					io.WriteString(w, `{"error":{"type":"invalid_request_error","message":"Only Checkout Sessions with a status in [\"open\"] can be expired."}}`)
+					return
+				}
+				fmt.Fprintf(w, `{"id":"cs_1","object":"checkout.session","status":%q}`, tt.status)
+			}))
+
+			if err := s.ExpireSession("cs_1", ""); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExpireSession = %v; want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
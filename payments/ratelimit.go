@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/payments/processor"
+)
+
+// retryRateLimited: Stripe 429 → längerer Backoff mit Jitter, OHNE das normale Retry Budget zu verbrauchen
+// Returns: true = Delivery ist erledigt (in TTL Queue + geackt), false = kein Rate Limit bzw. Budget leer → normaler Retry
+// queue: Consumer Queue → nur dieser Consumer bekommt die Message nach dem Backoff wieder
+func retryRateLimited(ch broker.Channel, d *amqp.Delivery, queue string, err error, logger *slog.Logger) bool {
+	retryAfter, ok := processor.RateLimited(err)
+	if !ok {
+		return false
+	}
+
+	logger.Warn("stripe rate limit hit, backing off",
+		slog.String("queue", queue),
+		slog.Duration("retry_after", retryAfter),
+	)
+	handled, err := broker.HandleRateLimit(ch, d, queue, retryAfter)
+	if err != nil {
+		logger.Error("error handling rate limit retry", slog.Any("error", err))
+	}
+	return handled
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/telemetry"
+)
+
+// refundConsumer: Konsumiert "order.items_adjusted" → anteilige Rückerstattung
+// Warum eigener Consumer?
+// → Andere Queue, anderer Payload (OrderItemsAdjusted statt Order)
+// → Refund-Fehler blockieren NICHT die Payment Link Erstellung
+type refundConsumer struct {
+	service PaymentService
+	dedup   *broker.Deduplicator
+	metrics *metrics.ConsumerMetrics // nil = aus
+	logger  *slog.Logger
+}
+
+func NewRefundConsumer(service PaymentService, dedup *broker.Deduplicator, consumerMetrics *metrics.ConsumerMetrics, logger *slog.Logger) *refundConsumer {
+	return &refundConsumer{
+		service: service,
+		dedup:   dedup,
+		metrics: consumerMetrics,
+		logger:  logger,
+	}
+}
+
+// Listen: Blockiert solange der Delivery Channel offen ist
+// → broker.Channel statt *amqp.Channel → testbar mit brokertest.Broker
+func (c *refundConsumer) Listen(ch broker.Channel) {
+	q, err := ch.QueueDeclare(
+		broker.OrderItemsAdjustedEvent, // queue name: "order.items_adjusted"
+		true,                           // durable
+		false,                          // delete when unused
+		false,                          // exclusive
+		false,                          // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange": broker.DLX, // Failed refunds → order.items_adjusted.dlq
+		},
+	)
+	if err != nil {
+		c.logger.Error("failed to declare queue", slog.Any("error", err))
+		return
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		c.logger.Error("failed to start consuming", slog.Any("error", err))
+		return
+	}
+
+	c.logger.Info("refund consumer started",
+		slog.String("queue", broker.OrderItemsAdjustedEvent),
+	)
+
+	for d := range msgs {
+		broker.Process(c.metrics, ch, &d, broker.OrderItemsAdjustedEvent, func() broker.Outcome {
+			return c.handle(ch, d)
+		})
+	}
+}
+
+// handle: Verarbeitet EINE order.items_adjusted Delivery (Ack/Nack passiert hier drin)
+func (c *refundConsumer) handle(ch broker.Channel, d amqp.Delivery) broker.Outcome {
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+	ctx, span := otel.Tracer("payment").Start(ctx, "AMQP - consume - "+broker.OrderItemsAdjustedEvent)
+	telemetry.TagSpan(ctx)
+
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderItemsAdjustedEvent) {
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+	if c.dedup.Duplicate(&d) {
+		span.End()
+		return broker.OutcomeDuplicate
+	}
+
+	adjustment := &pb.OrderItemsAdjusted{}
+	if err := json.Unmarshal(d.Body, adjustment); err != nil {
+		// Kaputtes JSON wird durch Retry nicht besser → direkt in die DLQ
+		c.logger.Error("failed to unmarshal adjustment", slog.Any("error", err))
+		d.Nack(false, false)
+		span.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	amount, err := c.service.RefundAdjustment(ctx, adjustment)
+	if err != nil {
+		c.logger.Error("failed to refund adjustment",
+			slog.String("order_id", adjustment.OrderId),
+			slog.Any("error", err),
+		)
+		if retryRateLimited(ch, &d, broker.OrderItemsAdjustedEvent, err, c.logger) {
+			span.End()
+			return broker.OutcomeRetry
+		}
+		// Retry ist sicher: adjustment_id = Stripe Idempotency Key
+		if err := broker.HandleRetry(ch, &d, broker.OrderItemsAdjustedEvent, err); err != nil {
+			c.logger.Error("error handling retry", slog.Any("error", err))
+		}
+		span.End()
+		return broker.OutcomeRetry
+	}
+
+	d.Ack(false)
+	c.dedup.Done(&d)
+
+	c.logger.Info("adjustment refunded",
+		slog.String("order_id", adjustment.OrderId),
+		slog.Int64("amount", amount),
+	)
+	span.End()
+	return broker.OutcomeSuccess
+}
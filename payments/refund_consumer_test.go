@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+// fakeAdjustments: PaymentService, nur RefundAdjustment wird vom refundConsumer genutzt
+type fakeAdjustments struct {
+	PaymentService
+	err error
+
+	mu       sync.Mutex
+	refunded []string
+}
+
+func (f *fakeAdjustments) RefundAdjustment(_ context.Context, a *pb.OrderItemsAdjusted) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refunded = append(f.refunded, a.AdjustmentId)
+	return 500, f.err
+}
+
+func (f *fakeAdjustments) Refunded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.refunded...)
+}
+
+// startRefundConsumer: Consumer auf dem In-Memory Broker starten und EIN Adjustment publizieren
+func startRefundConsumer(t *testing.T, service *fakeAdjustments) *brokertest.Broker {
+	t.Helper()
+
+	b := brokertest.New()
+	c := NewRefundConsumer(service, broker.NewDeduplicator(100, time.Minute), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	go c.Listen(b)
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForQueue(broker.OrderItemsAdjustedEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	publishAdjustment(t, b)
+	return b
+}
+
+// publishAdjustment: Adjustment "adj-1" mit deterministischer MessageId → zweiter Aufruf = Redelivery
+func publishAdjustment(t *testing.T, b *brokertest.Broker) {
+	t.Helper()
+
+	body, _ := json.Marshal(&pb.OrderItemsAdjusted{AdjustmentId: "adj-1", OrderId: "o1"})
+	err := b.PublishWithContext(context.Background(), "", broker.OrderItemsAdjustedEvent, false, false, amqp.Publishing{
+		MessageId: broker.MessageID(broker.OrderItemsAdjustedEvent, "adj-1"),
+		Body:      body,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefundConsumerAcksRefundedAdjustment(t *testing.T) {
+	service := &fakeAdjustments{}
+	b := startRefundConsumer(t, service)
+
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := service.Refunded(); len(got) != 1 || got[0] != "adj-1" {
+		t.Fatalf("refunded = %v; want [adj-1]", got)
+	}
+	if n := len(b.Nacked()); n != 0 {
+		t.Errorf("nacked = %d; want 0", n)
+	}
+}
+
+// Gleiche MessageId zweimal → Handler (= Stripe Refund) läuft nur einmal, die Kopie wird trotzdem geackt
+func TestRefundConsumerSkipsDuplicateMessageID(t *testing.T) {
+	service := &fakeAdjustments{}
+	b := startRefundConsumer(t, service)
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	publishAdjustment(t, b)
+	if err := b.WaitForAcks(2, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := service.Refunded(); len(got) != 1 {
+		t.Fatalf("refunded = %v; want exactly one refund", got)
+	}
+}
+
+// Stripe Fehler → Retry über die TTL Queue, Original geackt (kein Nack → keine Kopie in der DLQ)
+func TestRefundConsumerRetriesFailedRefund(t *testing.T) {
+	service := &fakeAdjustments{err: errors.New("stripe down")}
+	b := startRefundConsumer(t, service)
+
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(b.Nacked()); n != 0 {
+		t.Errorf("nacked = %d; want 0", n)
+	}
+
+	retryQueue := broker.RetryQueueName(broker.OrderItemsAdjustedEvent, 1)
+	retried := false
+	for _, m := range b.Published() {
+		if m.Exchange == broker.DLX {
+			t.Fatalf("non-final retry dead-lettered: %+v", m)
+		}
+		if m.RoutingKey == retryQueue {
+			retried = true
+		}
+	}
+	if !retried {
+		t.Fatalf("no message published to %s; published = %+v", retryQueue, b.Published())
+	}
+}
+
+// Übergroße Delivery → DLQ, OHNE Unmarshal (gültiges JSON, nur mit Padding aufgebläht)
+func TestRefundConsumerDeadLettersOversizedDelivery(t *testing.T) {
+	service := &fakeAdjustments{}
+	b := brokertest.New()
+	c := NewRefundConsumer(service, broker.NewDeduplicator(100, time.Minute), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	go c.Listen(b)
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForQueue(broker.OrderItemsAdjustedEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(&pb.OrderItemsAdjusted{AdjustmentId: "adj-1", OrderId: "o1"})
+	body = append(body, bytes.Repeat([]byte(" "), broker.MaxBodySize())...)
+	err := b.PublishWithContext(context.Background(), "", broker.OrderItemsAdjustedEvent, false, false, amqp.Publishing{Body: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := service.Refunded(); len(got) != 0 {
+		t.Fatalf("refunded = %v; oversized delivery must not reach the handler", got)
+	}
+
+	var deadLettered bool
+	for _, m := range b.Published() {
+		if m.Exchange == broker.DLX && m.RoutingKey == broker.OrderItemsAdjustedEvent {
+			deadLettered = true
+		}
+	}
+	if !deadLettered {
+		t.Fatal("oversized delivery was not dead-lettered")
+	}
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/config"
+	"github.com/timour/order-microservices/common/metrics"
 	"github.com/timour/order-microservices/payments/gateway"
 	"github.com/timour/order-microservices/payments/processor"
 )
@@ -17,15 +20,57 @@ import (
 type service struct {
 	processor processor.PaymentProcessor
 	gateway   gateway.OrdersGateway
+	stock     gateway.StockGateway // on_pay Reservation + Release bei Storno
+	ledger    PaymentLedger        // orderID → Checkout Session, PaymentIntent, Betrag
+	metrics   *metrics.BusinessMetrics
 	logger    *slog.Logger
+
+	reservationMode config.ReservationMode
 }
 
-func NewService(processor processor.PaymentProcessor, gateway gateway.OrdersGateway, logger *slog.Logger) *service {
+func NewService(processor processor.PaymentProcessor, gateway gateway.OrdersGateway, stock gateway.StockGateway, ledger PaymentLedger, reservationMode config.ReservationMode, businessMetrics *metrics.BusinessMetrics, logger *slog.Logger) *service {
 	return &service{
 		processor: processor,
 		gateway:   gateway,
+		stock:     stock,
+		ledger:    ledger,
+		metrics:   businessMetrics,
 		logger:    logger,
+
+		reservationMode: reservationMode,
+	}
+}
+
+// reserveBeforeCheckout: on_pay Mode → Stock JETZT reservieren, vor der Stripe Session
+// Warum vor Stripe?
+// → Reservation schlägt fehl (ausverkauft) → KEIN Checkout für Stock den es nicht gibt
+// → on_create Mode: Orders hat schon reserviert → nichts zu tun
+func (s *service) reserveBeforeCheckout(ctx context.Context, order *pb.Order) error {
+	if s.reservationMode != config.ReservationOnPay {
+		return nil
+	}
+
+	expiresAt, err := s.stock.ReserveForOrder(ctx, order)
+	if err != nil {
+		return fmt.Errorf("failed to reserve stock for order %s: %w", order.Id, err)
+	}
+
+	s.logger.Info("stock reserved before checkout",
+		slog.String("order_id", order.Id),
+		slog.String("expires_at", expiresAt),
+	)
+	return nil
+}
+
+// checkConnectSupported: Checkout auf einem Connect Account nur wenn dessen Webhooks ankommen
+// Warum?
+// → checkout.session.completed eines Connect Accounts geht NUR an einen Connect Webhook Endpoint
+// → Ohne STRIPE_CONNECT_ENDPOINT_SECRET zahlt der Kunde, aber die Order wird nie "paid"
+func checkConnectSupported(order *pb.Order) error {
+	if order.StripeAccount != "" && connectEndpointStripeSecret == "" {
+		return fmt.Errorf("%w: order %s uses connect account %s but STRIPE_CONNECT_ENDPOINT_SECRET is not set", ErrInvalidOrder, order.Id, order.StripeAccount)
 	}
+	return nil
 }
 
 // CreatePayment: Business Logic für Payment Creation
@@ -36,22 +81,59 @@ func NewService(processor processor.PaymentProcessor, gateway gateway.OrdersGate
 // 4. Später: Stripe Webhook → publishes "order.paid" Event
 func (s *service) CreatePayment(ctx context.Context, order *pb.Order) (string, error) {
 	if order == nil {
-		return "", fmt.Errorf("order is nil")
+		return "", fmt.Errorf("%w: order is nil", ErrInvalidOrder)
+	}
+
+	// Keine Items → Stripe Session ohne Line Items → Stripe antwortet 400, bei jedem Retry wieder
+	// → VOR der Reservation prüfen: Nichts zu reservieren, nichts zu bezahlen
+	if len(order.Items) == 0 {
+		return "", fmt.Errorf("%w: order %s has no items", ErrInvalidOrder, order.Id)
+	}
+
+	if err := checkConnectSupported(order); err != nil {
+		return "", err
+	}
+
+	// Re-Issue: Alte Session ZUERST schließen → sonst sind zwei Links gleichzeitig bezahlbar
+	if err := s.expireOpenCheckout(ctx, order.Id); err != nil {
+		return "", err
+	}
+
+	if err := s.reserveBeforeCheckout(ctx, order); err != nil {
+		return "", err
 	}
 
 	// Warum processor.CreatePaymentLink?
 	// → Ruft Stripe API: Erstellt Checkout Session
 	// → Gibt Payment Link zurück (z.B. "https://checkout.stripe.com/...")
-	paymentLink, err := s.processor.CreatePaymentLink(order)
+	checkout, err := s.processor.CreatePaymentLink(order)
 	if err != nil {
 		return "", fmt.Errorf("failed to create payment link: %w", err)
 	}
+	paymentLink := checkout.URL
+	s.metrics.RecordPaymentLinkCreated()
+
+	// Payment Ledger: Session der Order merken (Refund, Belege, Abgleich mit Stripe)
+	// → Fehler → NUR loggen: Der Kunde soll trotzdem bezahlen können, der Webhook legt den Eintrag notfalls an
+	if err := s.ledger.RecordCheckout(ctx, &PaymentRecord{
+		OrderID:       order.Id,
+		SessionID:     checkout.ID,
+		StripeAccount: order.StripeAccount,
+		Amount:        checkout.AmountTotal,
+		Currency:      checkout.Currency,
+	}); err != nil {
+		s.logger.Warn("failed to record checkout in payment ledger",
+			slog.String("order_id", order.Id),
+			slog.String("checkout_session", checkout.ID),
+			slog.Any("error", err),
+		)
+	}
 
 	// ⭐ FANNING OUT PATTERN: gRPC call back to Orders Service
 	// → Update Order with payment_link and status "waiting_payment"
 	// → Synchronous request-response (not Event!)
 	// → Stripe Webhook wird später "order.paid" Event publishen!
-	err = s.gateway.UpdateOrderAfterPaymentLink(ctx, order.Id, paymentLink)
+	err = s.gateway.UpdateOrderAfterPaymentLink(ctx, order.Id, paymentLink, checkout.ExpiresAt)
 	if err != nil {
 		return "", fmt.Errorf("failed to update order via gRPC: %w", err)
 	}
@@ -63,4 +145,278 @@ func (s *service) CreatePayment(ctx context.Context, order *pb.Order) (string, e
 
 	return paymentLink, nil
 }
+
+// expireOpenCheckout: Offene Checkout Session der Order (laut Ledger) bei Stripe schließen
+// Warum Fehler statt nur loggen?
+// → Ohne Expire bleibt der alte Link bis zu SessionTTL bezahlbar → neuer Link = mögliche Doppelzahlung
+// → Bezahlt (Ledger oder Stripe) → ErrAlreadyPaid, der Caller gibt keinen neuen Link raus
+func (s *service) expireOpenCheckout(ctx context.Context, orderID string) error {
+	payment, err := s.ledger.GetPaymentByOrder(ctx, orderID)
+	if errors.Is(err, ErrPaymentNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load previous checkout of order %s: %w", orderID, err)
+	}
+	if payment.Status != PaymentStatusOpen {
+		return fmt.Errorf("%w: order %s has payment status %q", ErrAlreadyPaid, orderID, payment.Status)
+	}
+	if payment.SessionID == "" {
+		return nil
+	}
+
+	err = s.processor.ExpireSession(payment.SessionID, payment.StripeAccount)
+	if errors.Is(err, processor.ErrSessionCompleted) {
+		return fmt.Errorf("%w: checkout session %s of order %s is completed", ErrAlreadyPaid, payment.SessionID, orderID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to expire previous checkout session of order %s: %w", orderID, err)
+	}
+
+	s.logger.Info("previous checkout session expired",
+		slog.String("order_id", orderID),
+		slog.String("checkout_session", payment.SessionID),
+	)
+	return nil
+}
+
+// ExpireCheckout: Order storniert/abgelaufen → offene Stripe Session schließen
+// Warum?
+// → Session lebt mind. 30 Minuten (Stripe Minimum), die Order läuft schon nach 15 ab
+// → Ohne Expire kann der Kunde weiter zahlen → Geld für eine tote Order, Refund erst im Webhook
+// Schon bezahlt (Webhook unterwegs) → kein Fehler: Der Webhook erstattet die Zahlung (RefundLatePayment)
+// Session Checkout (Tisch) → bleibt offen, die anderen Orders des Tisches sollen weiter zahlen können
+func (s *service) ExpireCheckout(ctx context.Context, order *pb.Order) error {
+	if order == nil || order.Id == "" {
+		return fmt.Errorf("%w: order without id", ErrInvalidOrder)
+	}
+	if order.SessionId != "" {
+		return nil
+	}
+
+	err := s.expireOpenCheckout(ctx, order.Id)
+	if errors.Is(err, ErrAlreadyPaid) {
+		s.logger.Warn("closed order was paid in the meantime, the webhook refunds it",
+			slog.String("order_id", order.Id),
+			slog.Any("error", err),
+		)
+		return nil
+	}
+	return err
+}
+
+// CreateSessionPayment: Gemeinsamer Payment Link für alle übergebenen Orders eines Tisches
+// Flow:
+// 1. Gateway sammelt unbezahlte Orders der Session (GetOrdersBySession)
+// 2. processor.CreateSessionPaymentLink → EINE Stripe Session mit allen Line Items
+// 3. Jede Order bekommt den gleichen Link + Status "waiting_payment"
+func (s *service) CreateSessionPayment(ctx context.Context, sessionID string, orders []*pb.Order) (string, error) {
+	if len(orders) == 0 {
+		return "", fmt.Errorf("session %q has no orders to pay", sessionID)
+	}
+
+	for _, order := range orders {
+		if err := checkConnectSupported(order); err != nil {
+			return "", err
+		}
+	}
+
+	for _, order := range orders {
+		if err := s.reserveBeforeCheckout(ctx, order); err != nil {
+			return "", err
+		}
+	}
+
+	checkout, err := s.processor.CreateSessionPaymentLink(sessionID, orders)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session payment link: %w", err)
+	}
+	paymentLink := checkout.URL
+	s.metrics.RecordPaymentLinkCreated()
+
+	for _, order := range orders {
+		if err := s.gateway.UpdateOrderAfterPaymentLink(ctx, order.Id, paymentLink, checkout.ExpiresAt); err != nil {
+			return "", fmt.Errorf("failed to update order %s via gRPC: %w", order.Id, err)
+		}
+	}
+
+	s.logger.Info("session payment link created and orders updated",
+		slog.String("session_id", sessionID),
+		slog.Int("orders", len(orders)),
+		slog.String("payment_link", paymentLink),
+	)
+
+	return paymentLink, nil
+}
+
+// RefundAdjustment: Anteilige Rückerstattung nach AdjustOrderItems
+// Flow:
+// 1. Orders Service reduziert Mengen → publisht "order.items_adjusted"
+// 2. Refund Consumer → RefundAdjustment
+// 3. Bezahlter Ledger Eintrag → Checkout Session + PaymentIntent der Order
+// 4. processor.RefundItems → Stripe Refund über den gezahlten Betrag (Idempotency Key = adjustment_id)
+func (s *service) RefundAdjustment(ctx context.Context, adjustment *pb.OrderItemsAdjusted) (int64, error) {
+	if adjustment == nil || adjustment.OrderId == "" {
+		return 0, fmt.Errorf("adjustment without order id")
+	}
+
+	// Nur bezahlte Orders werden angepasst → fehlt der Eintrag, kam der Webhook (noch) nicht an → Retry
+	payment, err := s.ledger.GetPaymentByOrder(ctx, adjustment.OrderId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load payment for order %s: %w", adjustment.OrderId, err)
+	}
+	if payment.Status != PaymentStatusCompleted {
+		return 0, fmt.Errorf("payment for order %s is %q, not completed", adjustment.OrderId, payment.Status)
+	}
+
+	amount, err := s.processor.RefundItems(processor.PaidCheckout{
+		OrderID:         adjustment.OrderId,
+		SessionID:       payment.SessionID,
+		PaymentIntentID: payment.PaymentIntentID,
+		StripeAccount:   payment.StripeAccount,
+	}, adjustment.AdjustmentId, adjustment.RemovedItems)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refund items: %w", err)
+	}
+
+	s.logger.Info("partial refund created",
+		slog.String("order_id", adjustment.OrderId),
+		slog.String("adjustment_id", adjustment.AdjustmentId),
+		slog.Int64("amount", amount),
+	)
+
+	return amount, nil
+}
+
+// RefundCancelledOrder: Volle Rückerstattung einer stornierten, bezahlten Order
+// Flow:
+// 1. Orders Service storniert → publisht "order.cancelled"
+// 2. Cancel Consumer → RefundCancelledOrder
+// 3. Bezahlter Ledger Eintrag → processor.RefundPayment (Idempotency Key = "cancel-" + orderID)
+// 4. Reservation freigeben (Backup zum Release in Orders CancelOrder)
+// Returns: Stripe Refund ID, leer wenn nichts zu erstatten war
+func (s *service) RefundCancelledOrder(ctx context.Context, order *pb.Order) (string, error) {
+	if order == nil || order.Id == "" {
+		return "", fmt.Errorf("%w: cancelled order without id", ErrInvalidOrder)
+	}
+
+	payment, err := s.ledger.GetPaymentByOrder(ctx, order.Id)
+	if err != nil && !errors.Is(err, ErrPaymentNotFound) {
+		return "", err
+	}
+
+	// Tisch/Session: EINE Checkout Session für mehrere Orders → voller Refund würde alle erstatten
+	// → Anteiliger Refund pro Order ist (noch) nicht implementiert → manuell im Stripe Dashboard
+	// → Bezahlt → ErrManualRefund: Consumer dead-lettert, die Zahlung geht nicht im Log unter
+	if order.SessionId != "" {
+		if err := s.stock.ReleaseReservation(ctx, order.Id); err != nil {
+			return "", fmt.Errorf("failed to release reservation for order %s: %w", order.Id, err)
+		}
+		if err == nil && payment.Status == PaymentStatusCompleted {
+			return "", fmt.Errorf("%w: order %s of shared checkout %s was paid (payment %s, account %q)",
+				ErrManualRefund, order.Id, order.SessionId, payment.SessionID, payment.StripeAccount)
+		}
+		return "", nil
+	}
+
+	var refundID string
+	switch {
+	case errors.Is(err, ErrPaymentNotFound) || (err == nil && payment.Status != PaymentStatusCompleted):
+		// Unbezahlt storniert → nichts zu erstatten, aber der Link darf nicht mehr bezahlbar sein
+		s.logger.Info("cancelled order was not paid, nothing to refund",
+			slog.String("order_id", order.Id),
+		)
+		if err := s.ExpireCheckout(ctx, order); err != nil {
+			return "", err
+		}
+	case err != nil:
+		return "", err
+	default:
+		// PaymentIntent direkt → spart den Session Lookup bei Stripe
+		paymentID := payment.PaymentIntentID
+		if paymentID == "" {
+			paymentID = payment.SessionID
+		}
+
+		refundID, err = s.processor.RefundPayment(paymentID, payment.StripeAccount, "cancel-"+order.Id)
+		if errors.Is(err, processor.ErrAlreadyRefunded) {
+			// z.B. manuell im Dashboard erstattet → Ziel erreicht, kein Retry
+			s.logger.Info("order already refunded",
+				slog.String("order_id", order.Id),
+				slog.String("payment_id", paymentID),
+			)
+		} else if err != nil {
+			return "", fmt.Errorf("failed to refund order %s: %w", order.Id, err)
+		} else {
+			s.logger.Info("cancelled order refunded",
+				slog.String("order_id", order.Id),
+				slog.String("payment_id", paymentID),
+				slog.String("refund_id", refundID),
+			)
+		}
+	}
+
+	// ⚠️ Bezahlte Order: Reservation ist schon bestätigt (quantity abgezogen) → Release ist ein No-Op
+	// → Ware ist verkauft/verbraucht, Restock läuft bewusst manuell über RestockItems
+	if err := s.stock.ReleaseReservation(ctx, order.Id); err != nil {
+		return refundID, fmt.Errorf("failed to release reservation for order %s: %w", order.Id, err)
+	}
+
+	return refundID, nil
+}
+
+// RefundLatePayment: Zahlung kam an, aber die Order ist schon storniert/abgelaufen
+// Warum?
+// → Stripe Sessions leben länger als die Reservation → Kunde kann nach dem Ablauf noch zahlen
+// → Orders lehnt "paid" ab (FailedPrecondition) → ohne Refund behält Stripe das Geld still
+// Idempotency Key "late-" + orderID → Webhook Retry erstattet nicht doppelt
+// Session Checkout → nur die Items dieser Order (die anderen Orders des Tisches sind gültig bezahlt)
+// Returns: Stripe Refund ID, leer beim anteiligen Refund
+func (s *service) RefundLatePayment(ctx context.Context, order *pb.Order, payment *PaymentRecord) (string, error) {
+	key := "late-" + order.Id
+
+	var refundID string
+	if order.SessionId != "" {
+		amount, err := s.processor.RefundItems(processor.PaidCheckout{
+			OrderID:         order.Id,
+			SessionID:       payment.SessionID,
+			PaymentIntentID: payment.PaymentIntentID,
+			StripeAccount:   payment.StripeAccount,
+		}, key, order.Items)
+		if err != nil {
+			return "", fmt.Errorf("failed to refund late payment for order %s: %w", order.Id, err)
+		}
+		payment.Amount = amount
+	} else {
+		paymentID := payment.PaymentIntentID
+		if paymentID == "" {
+			paymentID = payment.SessionID
+		}
+
+		var err error
+		refundID, err = s.processor.RefundPayment(paymentID, payment.StripeAccount, key)
+		if err != nil && !errors.Is(err, processor.ErrAlreadyRefunded) {
+			return "", fmt.Errorf("failed to refund late payment for order %s: %w", order.Id, err)
+		}
+	}
+
+	payment.OrderID = order.Id
+	payment.RefundID = refundID
+	if err := s.ledger.RecordRefund(ctx, payment); err != nil {
+		return refundID, err
+	}
+
+	s.logger.Warn("late payment refunded",
+		slog.String("order_id", order.Id),
+		slog.String("status", order.Status),
+		slog.String("refund_id", refundID),
+	)
+	return refundID, nil
+}
+
+// GetPaymentByOrder: Ledger Eintrag der Order (ErrPaymentNotFound → nie ein Checkout)
+func (s *service) GetPaymentByOrder(ctx context.Context, orderID string) (*PaymentRecord, error) {
+	return s.ledger.GetPaymentByOrder(ctx, orderID)
+}
+
 // rebuild trigger
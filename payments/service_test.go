@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/config"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/payments/gateway"
+	"github.com/timour/order-microservices/payments/processor"
+)
+
+// callLog: Reihenfolge der Calls über alle Fakes hinweg ("reserve:o1", "link:o1", ...)
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *callLog) Calls() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+// fakeProcessor: Stripe ohne Netzwerk, Link = "https://pay.example/<id>"
+type fakeProcessor struct {
+	processor.PaymentProcessor
+	log       *callLog
+	expireErr error // ExpireSession Ergebnis (z.B. processor.ErrSessionCompleted)
+}
+
+func (p *fakeProcessor) CreatePaymentLink(o *pb.Order) (*processor.CheckoutSession, error) {
+	p.log.add("link:" + o.Id)
+	return &processor.CheckoutSession{ID: "cs_" + o.Id, URL: "https://pay.example/" + o.Id}, nil
+}
+
+func (p *fakeProcessor) RefundItems(checkout processor.PaidCheckout, idempotencyKey string, _ []*pb.Item) (int64, error) {
+	call := "refund:" + checkout.SessionID + "/" + checkout.PaymentIntentID + "/" + idempotencyKey
+	if checkout.StripeAccount != "" {
+		call += "@" + checkout.StripeAccount
+	}
+	p.log.add(call)
+	return 100, nil
+}
+
+func (p *fakeProcessor) RefundPayment(paymentID, stripeAccount, idempotencyKey string) (string, error) {
+	p.log.add("refund-payment:" + paymentID + "/" + stripeAccount + "/" + idempotencyKey)
+	return "re_" + paymentID, nil
+}
+
+func (p *fakeProcessor) ExpireSession(sessionID, stripeAccount string) error {
+	p.log.add("expire:" + sessionID + "/" + stripeAccount)
+	return p.expireErr
+}
+
+func (p *fakeProcessor) CreateSessionPaymentLink(sessionID string, _ []*pb.Order) (*processor.CheckoutSession, error) {
+	p.log.add("link:" + sessionID)
+	return &processor.CheckoutSession{ID: "cs_" + sessionID, URL: "https://pay.example/" + sessionID}, nil
+}
+
+// fakeStock: Stock Gateway, err != nil → Reservation schlägt fehl (ausverkauft)
+type fakeStock struct {
+	gateway.StockGateway
+	log *callLog
+	err error
+}
+
+func (s *fakeStock) ReserveForOrder(_ context.Context, o *pb.Order) (string, error) {
+	s.log.add("reserve:" + o.Id)
+	return "2026-01-01T12:15:00Z", s.err
+}
+
+func (s *fakeStock) ReleaseReservation(_ context.Context, orderID string) error {
+	s.log.add("release:" + orderID)
+	return nil
+}
+
+// fakeOrderUpdates: Orders Gateway, nur UpdateOrderAfterPaymentLink wird von CreatePayment genutzt
+type fakeOrderUpdates struct {
+	gateway.OrdersGateway
+	log *callLog
+}
+
+func (g *fakeOrderUpdates) UpdateOrderAfterPaymentLink(_ context.Context, orderID, _ string, _ time.Time) error {
+	g.log.add("update:" + orderID)
+	return nil
+}
+
+func newTestService(mode config.ReservationMode, stockErr error) (*service, *callLog) {
+	log := &callLog{}
+	s := NewService(
+		&fakeProcessor{log: log},
+		&fakeOrderUpdates{log: log},
+		&fakeStock{log: log, err: stockErr},
+		NewMemoryLedger(),
+		mode,
+		nil,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	return s, log
+}
+
+// on_create: Orders hat schon reserviert → Payments reserviert NICHT nochmal
+// on_pay: Reservation direkt VOR der Stripe Session
+func TestCreatePaymentReservationMode(t *testing.T) {
+	tests := []struct {
+		mode config.ReservationMode
+		want []string
+	}{
+		{config.ReservationOnCreate, []string{"link:o1", "update:o1"}},
+		{config.ReservationOnPay, []string{"reserve:o1", "link:o1", "update:o1"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			s, log := newTestService(tt.mode, nil)
+
+			link, err := s.CreatePayment(context.Background(), &pb.Order{Id: "o1", Items: []*pb.Item{{ID: "1", Quantity: 1}}})
+			if err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+			if link != "https://pay.example/o1" {
+				t.Errorf("link = %q; want https://pay.example/o1", link)
+			}
+			if got := log.Calls(); !slices.Equal(got, tt.want) {
+				t.Fatalf("calls = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Link an den Kunden → PaymentLinksCreated; fehlgeschlagene Reservation → kein Link, kein Zähler
+func TestCreatePaymentRecordsPaymentLink(t *testing.T) {
+	s, _ := newTestService(config.ReservationOnPay, nil)
+	s.metrics = metrics.NewBusinessMetrics("payments_links_test")
+	order := &pb.Order{Id: "o1", Items: []*pb.Item{{ID: "1", Quantity: 1}}}
+
+	if _, err := s.CreatePayment(context.Background(), order); err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	s.stock = &fakeStock{log: &callLog{}, err: errors.New("insufficient stock")}
+	if _, err := s.CreatePayment(context.Background(), order); err == nil {
+		t.Fatal("CreatePayment succeeded; want reservation error")
+	}
+
+	if got := testutil.ToFloat64(s.metrics.PaymentLinksCreated); got != 1 {
+		t.Errorf("payment links created = %v; want 1", got)
+	}
+}
+
+// Ausverkauft beim Bezahlen → KEIN Checkout für Stock den es nicht gibt
+func TestCreatePaymentOnPayReservationFails(t *testing.T) {
+	s, log := newTestService(config.ReservationOnPay, errors.New("insufficient stock"))
+
+	if _, err := s.CreatePayment(context.Background(), &pb.Order{Id: "o1", Items: []*pb.Item{{ID: "1", Quantity: 1}}}); err == nil {
+		t.Fatal("CreatePayment succeeded; want reservation error")
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"reserve:o1"}) {
+		t.Fatalf("calls = %v; want only the failed reservation", got)
+	}
+}
+
+// Order ohne Items → ErrInvalidOrder, weder Reservation noch Stripe Session
+func TestCreatePaymentRejectsEmptyOrder(t *testing.T) {
+	s, log := newTestService(config.ReservationOnPay, nil)
+
+	for _, items := range [][]*pb.Item{nil, {}} {
+		_, err := s.CreatePayment(context.Background(), &pb.Order{Id: "o1", Items: items})
+		if !errors.Is(err, ErrInvalidOrder) {
+			t.Fatalf("CreatePayment(%v items) = %v; want ErrInvalidOrder", items, err)
+		}
+	}
+	if got := log.Calls(); len(got) != 0 {
+		t.Fatalf("calls = %v; want none", got)
+	}
+}
+
+// Re-Issue: Offene Session aus dem Ledger wird VOR dem neuen Link geschlossen → nur ein Link bezahlbar
+// Alte Session schon bezahlt → ErrAlreadyPaid, kein neuer Link
+func TestCreatePaymentExpiresPreviousSession(t *testing.T) {
+	order := &pb.Order{Id: "o1", Items: []*pb.Item{{ID: "1", Quantity: 1}}}
+
+	s, log := newTestService(config.ReservationOnCreate, nil)
+	for range 2 {
+		if _, err := s.CreatePayment(context.Background(), order); err != nil {
+			t.Fatalf("CreatePayment: %v", err)
+		}
+	}
+	want := []string{"link:o1", "update:o1", "expire:cs_o1/", "link:o1", "update:o1"}
+	if got := log.Calls(); !slices.Equal(got, want) {
+		t.Fatalf("calls = %v; want %v", got, want)
+	}
+
+	s, log = newTestService(config.ReservationOnCreate, nil)
+	if _, err := s.CreatePayment(context.Background(), order); err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	s.processor.(*fakeProcessor).expireErr = processor.ErrSessionCompleted
+	if _, err := s.CreatePayment(context.Background(), order); !errors.Is(err, ErrAlreadyPaid) {
+		t.Fatalf("CreatePayment after payment = %v; want ErrAlreadyPaid", err)
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"link:o1", "update:o1", "expire:cs_o1/"}) {
+		t.Fatalf("calls = %v; want no second link", got)
+	}
+}
+
+// Storno/Ablauf einer unbezahlten Order → offene Session wird geschlossen, kein Refund
+// Inzwischen bezahlt → kein Fehler (der Webhook erstattet), Tisch-Orders → Session bleibt offen
+func TestExpireCheckoutOnClosedOrder(t *testing.T) {
+	ctx := context.Background()
+	order := &pb.Order{Id: "o1", Items: []*pb.Item{{ID: "1", Quantity: 1}}}
+
+	s, log := newTestService(config.ReservationOnCreate, nil)
+	if err := s.ledger.RecordCheckout(ctx, &PaymentRecord{OrderID: "o1", SessionID: "cs_o1", StripeAccount: "acct_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ExpireCheckout(ctx, order); err != nil {
+		t.Fatalf("ExpireCheckout: %v", err)
+	}
+	if _, err := s.RefundCancelledOrder(ctx, order); err != nil {
+		t.Fatalf("RefundCancelledOrder: %v", err)
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"expire:cs_o1/acct_1", "expire:cs_o1/acct_1", "release:o1"}) {
+		t.Fatalf("calls = %v; want the session expired, no refund", got)
+	}
+
+	s.processor.(*fakeProcessor).expireErr = processor.ErrSessionCompleted
+	if err := s.ExpireCheckout(ctx, order); err != nil {
+		t.Fatalf("ExpireCheckout after payment = %v; want nil", err)
+	}
+
+	s, log = newTestService(config.ReservationOnCreate, nil)
+	if err := s.ExpireCheckout(ctx, &pb.Order{Id: "o2", SessionId: "t7"}); err != nil {
+		t.Fatalf("ExpireCheckout session order: %v", err)
+	}
+	if got := log.Calls(); len(got) != 0 {
+		t.Fatalf("calls = %v; want the shared session left open", got)
+	}
+}
+
+// Bezahlte Order storniert → Refund im Connect Account aus dem Ledger (Order trägt ihn evtl. nicht)
+// Tisch-Order bezahlt → ErrManualRefund statt nur Log, unbezahlt → nur Release
+func TestRefundCancelledOrderUsesLedger(t *testing.T) {
+	ctx := context.Background()
+
+	s, log := newTestService(config.ReservationOnCreate, nil)
+	if err := s.ledger.CompletePayment(ctx, &PaymentRecord{OrderID: "o1", SessionID: "cs_o1", PaymentIntentID: "pi_o1", StripeAccount: "acct_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RefundCancelledOrder(ctx, &pb.Order{Id: "o1"}); err != nil {
+		t.Fatalf("RefundCancelledOrder: %v", err)
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"refund-payment:pi_o1/acct_1/cancel-o1", "release:o1"}) {
+		t.Fatalf("calls = %v; want a refund on the ledger's account", got)
+	}
+
+	s, log = newTestService(config.ReservationOnCreate, nil)
+	if _, err := s.RefundCancelledOrder(ctx, &pb.Order{Id: "o2", SessionId: "t7"}); err != nil {
+		t.Fatalf("RefundCancelledOrder unpaid session order: %v", err)
+	}
+	if err := s.ledger.CompletePayment(ctx, &PaymentRecord{OrderID: "o3", SessionID: "cs_t7", StripeAccount: "acct_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RefundCancelledOrder(ctx, &pb.Order{Id: "o3", SessionId: "t7"}); !errors.Is(err, ErrManualRefund) {
+		t.Fatalf("RefundCancelledOrder paid session order = %v; want ErrManualRefund", err)
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"release:o2", "release:o3"}) {
+		t.Fatalf("calls = %v; want only releases", got)
+	}
+}
+
+// Session (Dine-In): on_pay reserviert JEDE Order vor der gemeinsamen Stripe Session
+func TestCreateSessionPaymentOnPayReservesEachOrder(t *testing.T) {
+	s, log := newTestService(config.ReservationOnPay, nil)
+
+	if _, err := s.CreateSessionPayment(context.Background(), "t7", []*pb.Order{{Id: "o1"}, {Id: "o2"}}); err != nil {
+		t.Fatalf("CreateSessionPayment: %v", err)
+	}
+	want := []string{"reserve:o1", "reserve:o2", "link:t7", "update:o1", "update:o2"}
+	if got := log.Calls(); !slices.Equal(got, want) {
+		t.Fatalf("calls = %v; want %v", got, want)
+	}
+}
+
+// Anteiliger Refund über die bezahlte Session aus dem Ledger (dort stehen die gezahlten Beträge)
+func TestRefundAdjustmentUsesLedgerCheckout(t *testing.T) {
+	s, log := newTestService(config.ReservationOnCreate, nil)
+	ctx := context.Background()
+	if err := s.ledger.CompletePayment(ctx, &PaymentRecord{OrderID: "o1", SessionID: "cs_o1", PaymentIntentID: "pi_o1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RefundAdjustment(ctx, &pb.OrderItemsAdjusted{AdjustmentId: "adj-1", OrderId: "o1"}); err != nil {
+		t.Fatalf("RefundAdjustment: %v", err)
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"refund:cs_o1/pi_o1/adj-1"}) {
+		t.Errorf("calls = %v; want refund against cs_o1/pi_o1", got)
+	}
+}
+
+// Kein bezahlter Ledger Eintrag → kein Refund (Retry bis der Webhook da ist)
+func TestRefundAdjustmentRequiresCompletedPayment(t *testing.T) {
+	s, log := newTestService(config.ReservationOnCreate, nil)
+	ctx := context.Background()
+	if err := s.ledger.RecordCheckout(ctx, &PaymentRecord{OrderID: "o2", SessionID: "cs_o2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, orderID := range []string{"o1", "o2"} {
+		if _, err := s.RefundAdjustment(ctx, &pb.OrderItemsAdjusted{AdjustmentId: "adj-1", OrderId: orderID}); err == nil {
+			t.Errorf("%s: RefundAdjustment succeeded; want error", orderID)
+		}
+	}
+	if got := log.Calls(); len(got) != 0 {
+		t.Errorf("calls = %v; want no refund", got)
+	}
+}
+
+// Connect Account ohne Connect Webhook → ErrInvalidOrder, sonst zahlt der Kunde und die Order wird nie "paid"
+func TestCreatePaymentRejectsConnectWithoutWebhook(t *testing.T) {
+	s, log := newTestService(config.ReservationOnPay, nil)
+	order := &pb.Order{Id: "o1", StripeAccount: "acct_roma", Items: []*pb.Item{{ID: "1", Quantity: 1}}}
+
+	if _, err := s.CreatePayment(context.Background(), order); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("CreatePayment = %v; want ErrInvalidOrder", err)
+	}
+	if _, err := s.CreateSessionPayment(context.Background(), "t7", []*pb.Order{order}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("CreateSessionPayment = %v; want ErrInvalidOrder", err)
+	}
+	if got := log.Calls(); len(got) != 0 {
+		t.Fatalf("calls = %v; want none", got)
+	}
+
+	setConnectSecret(t, "whsec_connect")
+	if _, err := s.CreatePayment(context.Background(), order); err != nil {
+		t.Fatalf("CreatePayment with connect webhook: %v", err)
+	}
+	payment, err := s.ledger.GetPaymentByOrder(context.Background(), "o1")
+	if err != nil || payment.StripeAccount != "acct_roma" {
+		t.Errorf("ledger = %+v (%v); want stripe account acct_roma", payment, err)
+	}
+}
+
+// Refund einer Connect Zahlung läuft im Account des Restaurants (dort liegen Session + PaymentIntent)
+func TestRefundAdjustmentUsesConnectAccount(t *testing.T) {
+	s, log := newTestService(config.ReservationOnCreate, nil)
+	ctx := context.Background()
+	if err := s.ledger.CompletePayment(ctx, &PaymentRecord{OrderID: "o1", SessionID: "cs_o1", PaymentIntentID: "pi_o1", StripeAccount: "acct_roma"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.RefundAdjustment(ctx, &pb.OrderItemsAdjusted{AdjustmentId: "adj-1", OrderId: "o1"}); err != nil {
+		t.Fatalf("RefundAdjustment: %v", err)
+	}
+	if got := log.Calls(); !slices.Equal(got, []string{"refund:cs_o1/pi_o1/adj-1@acct_roma"}) {
+		t.Errorf("calls = %v; want refund on acct_roma", got)
+	}
+}
+
+// setConnectSecret: STRIPE_CONNECT_ENDPOINT_SECRET für einen Test setzen
+func setConnectSecret(t *testing.T, secret string) {
+	t.Helper()
+	previous := connectEndpointStripeSecret
+	connectEndpointStripeSecret = secret
+	t.Cleanup(func() { connectEndpointStripeSecret = previous })
+}
@@ -2,11 +2,33 @@ package main
 
 import (
 	"context"
+	"errors"
 
 	pb "github.com/timour/order-microservices/common/api"
 )
 
+// ErrInvalidOrder: Order kann NIE bezahlt werden (z.B. keine Items) → permanenter Fehler
+// Warum eigener Fehler?
+// → Retry ändert nichts an der Order → Consumer dead-lettert sofort statt 3x Stripe zu fragen
+// → Caller prüfen mit errors.Is statt Fehlertext zu parsen
+var ErrInvalidOrder = errors.New("invalid order")
+
+// ErrAlreadyPaid: Die bisherige Checkout Session der Order ist schon bezahlt → KEIN neuer Link
+// → Webhook ist evtl. noch unterwegs, Orders steht dann noch auf waiting_payment
+var ErrAlreadyPaid = errors.New("order already paid")
+
+// ErrManualRefund: Bezahlte Order eines gemeinsamen Checkouts (Tisch/Session) wurde storniert
+// → Anteiliger Refund ist nicht implementiert → Consumer dead-lettert, DLQ = Liste der offenen Refunds
+// → Sonst würde die Zahlung nur geloggt und ginge unter
+var ErrManualRefund = errors.New("manual refund required")
+
 // PaymentService defines the business logic interface
 type PaymentService interface {
 	CreatePayment(context.Context, *pb.Order) (string, error)
+	CreateSessionPayment(ctx context.Context, sessionID string, orders []*pb.Order) (string, error)
+	RefundAdjustment(context.Context, *pb.OrderItemsAdjusted) (int64, error)
+	RefundCancelledOrder(context.Context, *pb.Order) (string, error)
+	RefundLatePayment(ctx context.Context, order *pb.Order, payment *PaymentRecord) (string, error)
+	ExpireCheckout(context.Context, *pb.Order) error
+	GetPaymentByOrder(ctx context.Context, orderID string) (*PaymentRecord, error)
 }
@@ -9,20 +9,26 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	pb "github.com/timour/order-microservices/common/api"
 	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/metrics"
+	"github.com/timour/order-microservices/common/telemetry"
 	"go.opentelemetry.io/otel"
 )
 
 type Consumer struct {
-	store StockStore
+	store   StockStore
+	dedup   *broker.Deduplicator     // Redeliveries (gleiche MessageId) überspringen, nil = aus
+	metrics *metrics.ConsumerMetrics // Processing Dauer + Outcome, nil = aus
 }
 
-func NewConsumer(store StockStore) *Consumer {
+func NewConsumer(store StockStore, dedup *broker.Deduplicator, consumerMetrics *metrics.ConsumerMetrics) *Consumer {
 	return &Consumer{
-		store: store,
+		store:   store,
+		dedup:   dedup,
+		metrics: consumerMetrics,
 	}
 }
 
-func (c *Consumer) Listen(ch *amqp.Channel) {
+func (c *Consumer) Listen(ch broker.Channel) {
 	q, err := ch.QueueDeclare(
 		"",    // name
 		true,  // durable
@@ -55,53 +61,250 @@ func (c *Consumer) Listen(ch *amqp.Channel) {
 
 	go func() {
 		for d := range msgs {
-			// Extract headers
-			ctx := broker.ExtractAMQPHeader(context.Background(), d.Headers)
-
-			// Create a new span
-			tr := otel.Tracer("amqp")
-			_, messageSpan := tr.Start(ctx, fmt.Sprintf("AMQP - consume - %s", q.Name))
-
-			log.Printf("Received order.paid message: %s", d.Body)
-
-			// Parse order from JSON
-			var order pb.Order
-			if err := json.Unmarshal(d.Body, &order); err != nil {
-				log.Printf("ERROR: Failed to unmarshal order: %v", err)
-				d.Nack(false, false)
-				messageSpan.End()
-				continue
-			}
-
-			log.Printf("Processing paid order %s - Confirming stock reservation", order.Id)
-
-			// ⭐ Confirm Stock Reservation (NEW!)
-			// Warum ConfirmReservation statt DecrementQuantity?
-			// → Order wurde bereits bei CreateOrder reserviert (reserved_quantity++)
-			// → Jetzt: Payment erfolgreich → Reservation bestätigen!
-			// → ConfirmReservation macht:
-			//   1. Decrement quantity (actual stock removal)
-			//   2. Decrement reserved_quantity (release reservation)
-			//   3. Update reservation status = 'confirmed'
-			// → Alles in EINER Transaktion - ACID garantiert!
-			err = c.store.ConfirmReservation(ctx, order.Id)
-			if err != nil {
-				log.Printf("ERROR: Failed to confirm reservation for order %s: %v", order.Id, err)
-				// NACK message → goes to DLQ for retry
-				d.Nack(false, false)
-				messageSpan.End()
-				log.Printf("❌ Reservation confirmation failed - Message sent to DLQ: %s", order.Id)
-				continue
-			}
-
-			log.Printf("✅ Stock reservation confirmed for order: %s (%d items)", order.Id, len(order.Items))
-
-			d.Ack(false)
-			messageSpan.End()
-			log.Printf("✅ Stock update completed for order: %s", order.Id)
+			broker.Process(c.metrics, ch, &d, broker.OrderPaidEvent, func() broker.Outcome {
+				return c.handlePaid(ch, q.Name, d)
+			})
 		}
 	}()
 
 	log.Printf("AMQP Listening. To exit press CTRL+C")
 	<-forever
 }
+
+// handlePaid: Verarbeitet EINE order.paid Delivery (Ack/Nack passiert hier drin)
+// Returns: Outcome für die Processing Metrik (broker.Process misst die Dauer)
+func (c *Consumer) handlePaid(ch broker.Channel, queue string, d amqp.Delivery) broker.Outcome {
+	// Extract headers
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+
+	// Create a new span
+	tr := otel.Tracer("amqp")
+	spanCtx, messageSpan := tr.Start(ctx, fmt.Sprintf("AMQP - consume - %s", queue))
+	telemetry.TagSpan(spanCtx) // customer.id aus der Baggage
+
+	// Zu groß? → DLQ ohne Unmarshal (Body wird NICHT geloggt)
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderPaidEvent) {
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+	if c.dedup.Duplicate(&d) {
+		messageSpan.End()
+		return broker.OutcomeDuplicate
+	}
+
+	log.Printf("Received order.paid message: %s", d.Body)
+
+	// Parse order from JSON
+	var order pb.Order
+	if err := json.Unmarshal(d.Body, &order); err != nil {
+		log.Printf("ERROR: Failed to unmarshal order: %v", err)
+		d.Nack(false, false)
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	log.Printf("Processing paid order %s - Confirming stock reservation", order.Id)
+
+	// ⭐ Confirm Stock Reservation (NEW!)
+	// Warum ConfirmReservation statt DecrementQuantity?
+	// → Order wurde bereits bei CreateOrder reserviert (reserved_quantity++)
+	// → Jetzt: Payment erfolgreich → Reservation bestätigen!
+	// → ConfirmReservation macht:
+	//   1. Decrement quantity (actual stock removal)
+	//   2. Decrement reserved_quantity (release reservation)
+	//   3. Update reservation status = 'confirmed'
+	// → Alles in EINER Transaktion - ACID garantiert!
+	err := c.store.ConfirmReservation(ctx, order.Id)
+	if err != nil {
+		log.Printf("ERROR: Failed to confirm reservation for order %s: %v", order.Id, err)
+		// NACK message → goes to DLQ for retry
+		d.Nack(false, false)
+		messageSpan.End()
+		log.Printf("❌ Reservation confirmation failed - Message sent to DLQ: %s", order.Id)
+		return broker.OutcomeDeadLetter
+	}
+
+	log.Printf("✅ Stock reservation confirmed for order: %s (%d items)", order.Id, len(order.Items))
+
+	d.Ack(false)
+	c.dedup.Done(&d)
+	messageSpan.End()
+	log.Printf("✅ Stock update completed for order: %s", order.Id)
+	return broker.OutcomeSuccess
+}
+
+// ListenPreparing: order.preparing → "Fulfillment gestartet" an der Reservierung festhalten
+// → Orders Service publiziert order.preparing auf den gleichnamigen Exchange
+// → Durable Queue "order.preparing" daran gebunden → Events überleben einen Stock Restart
+func (c *Consumer) ListenPreparing(ch broker.Channel) {
+	q, err := ch.QueueDeclare(
+		broker.OrderPreparingEvent, // name
+		true,                       // durable
+		false,                      // delete when unused
+		false,                      // exclusive
+		false,                      // no-wait
+		nil,                        // arguments
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ch.QueueBind(
+		q.Name,                     // queue name
+		"",                         // routing key
+		broker.OrderPreparingEvent, // exchange
+		false,                      // no-wait
+		nil,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for d := range msgs {
+		broker.Process(c.metrics, ch, &d, broker.OrderPreparingEvent, func() broker.Outcome {
+			return c.handlePreparing(ch, q.Name, d)
+		})
+	}
+}
+
+// handlePreparing: Verarbeitet EINE order.preparing Delivery (Ack/Nack passiert hier drin)
+func (c *Consumer) handlePreparing(ch broker.Channel, queue string, d amqp.Delivery) broker.Outcome {
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+
+	tr := otel.Tracer("amqp")
+	spanCtx, messageSpan := tr.Start(ctx, fmt.Sprintf("AMQP - consume - %s", queue))
+	telemetry.TagSpan(spanCtx)
+
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderPreparingEvent) {
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+	if c.dedup.Duplicate(&d) {
+		messageSpan.End()
+		return broker.OutcomeDuplicate
+	}
+
+	var order pb.Order
+	if err := json.Unmarshal(d.Body, &order); err != nil {
+		log.Printf("ERROR: Failed to unmarshal order: %v", err)
+		d.Nack(false, false)
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	if err := c.recordFulfillmentStarted(ctx, order.Id); err != nil {
+		log.Printf("ERROR: %v", err)
+		d.Nack(false, false)
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	d.Ack(false)
+	c.dedup.Done(&d)
+	messageSpan.End()
+	return broker.OutcomeSuccess
+}
+
+// recordFulfillmentStarted: Timestamp an den bestätigten Reservierungen der Order setzen
+// → 0 Zeilen ist KEIN Fehler: Redelivery (schon gesetzt) oder Order ohne Reservierung (Legacy)
+func (c *Consumer) recordFulfillmentStarted(ctx context.Context, orderID string) error {
+	marked, err := c.store.MarkFulfillmentStarted(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to record fulfillment start for order %s: %w", orderID, err)
+	}
+
+	if marked == 0 {
+		log.Printf("No confirmed reservation without fulfillment timestamp for order %s - nothing to record", orderID)
+		return nil
+	}
+
+	log.Printf("✅ Fulfillment started for order %s (%d reservation rows)", orderID, marked)
+	return nil
+}
+
+// ListenExpired: order.expired → Reservation der unbezahlten Order sofort freigeben
+// → Orders Expiry Job publiziert an den Exchange "order.expired" → Queue gleichen Namens hier binden
+// → Queue Name = Event Name → DLQ "order.expired.dlq" + Retry Queues "order.expired.retry.<n>" passen
+func (c *Consumer) ListenExpired(ch broker.Channel) {
+	q, err := ch.QueueDeclare(
+		broker.OrderExpiredEvent, // name
+		true,                     // durable
+		false,                    // delete when unused
+		false,                    // exclusive
+		false,                    // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange": broker.DLX,
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ch.QueueBind(
+		q.Name,                   // queue name: "order.expired"
+		"",                       // routing key
+		broker.OrderExpiredEvent, // exchange
+		false,                    // no-wait
+		nil,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for d := range msgs {
+		broker.Process(c.metrics, ch, &d, broker.OrderExpiredEvent, func() broker.Outcome {
+			return c.handleExpired(ch, q.Name, d)
+		})
+	}
+}
+
+// handleExpired: Verarbeitet EINE order.expired Delivery (Ack/Nack passiert hier drin)
+// → ReleaseReservation ist idempotent: Schon abgelaufen/freigegeben → nil
+func (c *Consumer) handleExpired(ch broker.Channel, queue string, d amqp.Delivery) broker.Outcome {
+	ctx := broker.ExtractTraceContext(context.Background(), d.Headers)
+
+	tr := otel.Tracer("amqp")
+	spanCtx, messageSpan := tr.Start(ctx, fmt.Sprintf("AMQP - consume - %s", queue))
+	telemetry.TagSpan(spanCtx)
+
+	if broker.RejectOversized(ctx, ch, &d, broker.OrderExpiredEvent) {
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+	if c.dedup.Duplicate(&d) {
+		messageSpan.End()
+		return broker.OutcomeDuplicate
+	}
+
+	var order pb.Order
+	if err := json.Unmarshal(d.Body, &order); err != nil {
+		log.Printf("ERROR: Failed to unmarshal order: %v", err)
+		d.Nack(false, false)
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	if err := c.store.ReleaseReservation(ctx, order.Id, order.ReservationId); err != nil {
+		log.Printf("ERROR: Failed to release reservation for expired order %s: %v", order.Id, err)
+		d.Nack(false, false)
+		messageSpan.End()
+		return broker.OutcomeDeadLetter
+	}
+
+	log.Printf("✅ Reservation released for expired order: %s", order.Id)
+
+	d.Ack(false)
+	c.dedup.Done(&d)
+	messageSpan.End()
+	return broker.OutcomeSuccess
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/broker"
+	"github.com/timour/order-microservices/common/broker/brokertest"
+)
+
+// startPreparingConsumer: ListenPreparing auf dem In-Memory Broker, Store mit Fake Clock
+func startPreparingConsumer(t *testing.T) (*brokertest.Broker, *MemoryStore, *fakeClock) {
+	t.Helper()
+
+	store, clock := newTestMemoryStore(nil, 0)
+	b := brokertest.New()
+	go NewConsumer(store, nil, nil).ListenPreparing(b)
+	t.Cleanup(func() { b.Close() })
+
+	if err := b.WaitForBinding(broker.OrderPreparingEvent, broker.OrderPreparingEvent, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	return b, store, clock
+}
+
+func publishPreparing(t *testing.T, b *brokertest.Broker, orderID string) {
+	t.Helper()
+	body, _ := json.Marshal(&pb.Order{Id: orderID, Status: "preparing"})
+	if err := b.PublishWithContext(context.Background(), broker.OrderPreparingEvent, "", false, false, amqp.Publishing{Body: body}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// order.preparing für eine bezahlte Order → Timestamp an der Reservierung, Redelivery überschreibt ihn nicht
+func TestListenPreparingRecordsFulfillmentStart(t *testing.T) {
+	b, store, clock := startPreparingConsumer(t)
+	ctx := context.Background()
+
+	if _, err := store.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 2}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	if err := store.ConfirmReservation(ctx, "o1"); err != nil {
+		t.Fatalf("ConfirmReservation: %v", err)
+	}
+	startedAt := clock.Now()
+
+	publishPreparing(t, b, "o1")
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := store.GetReservation(ctx, "o1")
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	if len(lines) != 1 || !lines[0].FulfillmentStartedAt.Equal(startedAt) {
+		t.Fatalf("reservation = %+v; want FulfillmentStartedAt %s", lines, startedAt)
+	}
+
+	// Redelivery später → erster Timestamp bleibt
+	clock.Advance(time.Minute)
+	publishPreparing(t, b, "o1")
+	if err := b.WaitForAcks(2, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	lines, _ = store.GetReservation(ctx, "o1")
+	if len(lines) != 1 || !lines[0].FulfillmentStartedAt.Equal(startedAt) {
+		t.Fatalf("reservation after redelivery = %+v; want FulfillmentStartedAt still %s", lines, startedAt)
+	}
+}
+
+// Unbezahlte Reservierung (noch "reserved") → kein Timestamp, trotzdem Ack (kein DLQ Rauschen)
+func TestListenPreparingSkipsUnconfirmedReservation(t *testing.T) {
+	b, store, _ := startPreparingConsumer(t)
+	ctx := context.Background()
+
+	if _, err := store.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 2}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+
+	publishPreparing(t, b, "o1")
+	if err := b.WaitForAcks(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(b.Nacked()); n != 0 {
+		t.Errorf("nacked = %d; want 0", n)
+	}
+
+	lines, err := store.GetReservation(ctx, "o1")
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	if len(lines) != 1 || !lines[0].FulfillmentStartedAt.IsZero() {
+		t.Fatalf("reservation = %+v; want no fulfillment timestamp", lines)
+	}
+}
@@ -123,3 +123,43 @@ func (c *ItemCache) InvalidateItem(ctx context.Context, id string) error {
 	key := fmt.Sprintf("item:%s", id)
 	return c.client.Del(ctx, key).Err()
 }
+
+// allItemsKey: EIN Key für die komplette Item Liste (GetItems ohne IDs → Menu)
+const allItemsKey = "all_items"
+
+// GetAllItems retrieves the full item list from cache (nil, nil = cache miss)
+func (c *ItemCache) GetAllItems(ctx context.Context) ([]*pb.Item, error) {
+	data, err := c.client.Get(ctx, allItemsKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis get error: %w", err)
+	}
+
+	var items []*pb.Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal items: %w", err)
+	}
+
+	return items, nil
+}
+
+// SetAllItems stores the full item list with its own (short) TTL
+func (c *ItemCache) SetAllItems(ctx context.Context, items []*pb.Item, ttl time.Duration) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to marshal items: %w", err)
+	}
+
+	if err := c.client.Set(ctx, allItemsKey, data, ttl).Err(); err != nil {
+		return fmt.Errorf("redis set error: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateAllItems removes the full item list from cache
+func (c *ItemCache) InvalidateAllItems(ctx context.Context) error {
+	return c.client.Del(ctx, allItemsKey).Err()
+}
@@ -2,12 +2,33 @@ package main
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	pb "github.com/timour/order-microservices/common/api"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// reservationError: Typisierte Store Fehler → gRPC Codes
+// → Unbekanntes Item = Client Fehler (InvalidArgument → HTTP 400)
+// → Zu wenig Bestand / Item deaktiviert = Zustand (FailedPrecondition → HTTP 409)
+// → Keine bestätigbare Reservation = Zustand (FailedPrecondition)
+func reservationError(err error) error {
+	switch {
+	case errors.Is(err, ErrItemNotFound):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrInsufficientStock), errors.Is(err, ErrItemUnavailable), errors.Is(err, ErrNoActiveReservation):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrReservationConflict):
+		return status.Error(codes.Aborted, err.Error()) // Retry kann klappen
+	default:
+		return err
+	}
+}
+
 type StockGrpcHandler struct {
 	pb.UnimplementedStockServiceServer
 
@@ -29,14 +50,15 @@ func NewGRPCHandler(
 }
 
 func (s *StockGrpcHandler) CheckIfItemIsInStock(ctx context.Context, p *pb.CheckIfItemIsInStockRequest) (*pb.CheckIfItemIsInStockResponse, error) {
-	inStock, items, err := s.service.CheckIfItemAreInStock(ctx, p.Items)
+	inStock, items, outOfStock, err := s.service.CheckIfItemAreInStock(ctx, p.Items)
 	if err != nil {
 		return nil, err
 	}
 
 	return &pb.CheckIfItemIsInStockResponse{
-		InStock: inStock,
-		Items:   items,
+		InStock:         inStock,
+		Items:           items,
+		OutOfStockItems: outOfStock,
 	}, nil
 }
 
@@ -52,12 +74,159 @@ func (s *StockGrpcHandler) GetItems(ctx context.Context, payload *pb.GetItemsReq
 }
 
 func (s *StockGrpcHandler) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
-	reservationID, err := s.service.ReserveStock(ctx, req.OrderID, req.Items)
+	reservation, err := s.service.ReserveStock(ctx, req.OrderID, req.Items)
 	if err != nil {
-		return nil, err
+		return nil, reservationError(err)
+	}
+
+	return reservationResponse(reservation), nil
+}
+
+func (s *StockGrpcHandler) RenewReservation(ctx context.Context, req *pb.RenewReservationRequest) (*pb.ReserveStockResponse, error) {
+	reservation, err := s.service.RenewReservation(ctx, req.OrderID, req.Items)
+	if err != nil {
+		return nil, reservationError(err)
 	}
 
+	return reservationResponse(reservation), nil
+}
+
+// reservationResponse: ExpiresAt als RFC3339 (UTC) → gleiches Format wie Order.created_at
+func reservationResponse(r Reservation) *pb.ReserveStockResponse {
 	return &pb.ReserveStockResponse{
-		ReservationID: reservationID,
+		ReservationID: r.ID,
+		ExpiresAt:     r.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (s *StockGrpcHandler) RestockItems(ctx context.Context, req *pb.RestockItemsRequest) (*pb.RestockItemsResponse, error) {
+	if err := s.service.RestockItems(ctx, req.OrderID, req.Items); err != nil {
+		return nil, reservationError(err)
+	}
+
+	return &pb.RestockItemsResponse{}, nil
+}
+
+// ToggleItemAvailability: Item vorübergehend aus dem Verkauf nehmen / wieder freigeben (Admin)
+func (s *StockGrpcHandler) ToggleItemAvailability(ctx context.Context, req *pb.ToggleItemAvailabilityRequest) (*pb.Item, error) {
+	item, err := s.service.SetItemAvailability(ctx, req.ID, req.Available)
+	if errors.Is(err, ErrItemNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ReleaseReservation: Reservation einer stornierten Order freigeben
+// → Keine aktive Reservation (schon freigegeben, bestätigt oder abgelaufen) → Erfolg, nichts zu tun
+// → ReservationID gesetzt → nur diese Reservation (leer = alle aktiven der Order)
+func (s *StockGrpcHandler) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	if err := s.service.ReleaseReservation(ctx, req.OrderID, req.ReservationID); err != nil {
+		return nil, err
+	}
+
+	return &pb.ReleaseReservationResponse{}, nil
+}
+
+// ConfirmReservation: Reservation einer bezahlten Order bestätigen (Payments Webhook)
+// → Schon bestätigt (order.paid Consumer war schneller) → Erfolg, nichts zu tun
+func (s *StockGrpcHandler) ConfirmReservation(ctx context.Context, req *pb.ConfirmReservationRequest) (*pb.ConfirmReservationResponse, error) {
+	if err := s.service.ConfirmReservation(ctx, req.OrderID); err != nil {
+		return nil, reservationError(err)
+	}
+
+	return &pb.ConfirmReservationResponse{}, nil
+}
+
+// CleanupExpiredReservations: Sweep auf Knopfdruck (Admin, z.B. während eines Incidents)
+// → Gleiche Store Methode wie der Minuten-Job → läuft beides gleichzeitig, gibt FOR UPDATE jede Zeile nur einmal frei
+func (s *StockGrpcHandler) CleanupExpiredReservations(ctx context.Context, req *pb.CleanupExpiredReservationsRequest) (*pb.CleanupExpiredReservationsResponse, error) {
+	released, err := s.service.CleanupExpiredReservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CleanupExpiredReservationsResponse{Released: int32(released)}, nil
+}
+
+// GetReservation: Alle Reservierungszeilen einer Order (jeder Status) → "Ist mein Stock noch reserviert?"
+// → Leere Liste statt NotFound: Order ohne Reservierung ist eine gültige Antwort beim Debugging
+func (s *StockGrpcHandler) GetReservation(ctx context.Context, req *pb.GetReservationRequest) (*pb.GetReservationResponse, error) {
+	lines, err := s.service.GetReservation(ctx, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetReservationResponse{
+		OrderID: req.OrderID,
+		Items:   make([]*pb.ReservationItem, 0, len(lines)),
+	}
+	for _, line := range lines {
+		item := &pb.ReservationItem{
+			ItemID:    line.ItemID,
+			Quantity:  line.Quantity,
+			Status:    line.Status,
+			ExpiresAt: line.ExpiresAt.UTC().Format(time.RFC3339),
+		}
+		if !line.FulfillmentStartedAt.IsZero() {
+			item.FulfillmentStartedAt = line.FulfillmentStartedAt.UTC().Format(time.RFC3339)
+		}
+		resp.Items = append(resp.Items, item)
+	}
+
+	return resp, nil
+}
+
+// BulkCreateItems: Menü Import (Admin)
+// Warum Zeilenfehler in der Response statt status.Error?
+// → Gateway braucht ALLE fehlerhaften Zeilen, ein gRPC Status trägt nur EINE Message
+func (s *StockGrpcHandler) BulkCreateItems(ctx context.Context, req *pb.BulkCreateItemsRequest) (*pb.BulkCreateItemsResponse, error) {
+	created, rowErrors, err := s.service.BulkCreateItems(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BulkCreateItemsResponse{
+		Created: int32(created),
+		Errors:  rowErrors,
 	}, nil
 }
+
+// GetInventorySummary: Bestand pro Item + Summen für das Admin Dashboard
+func (s *StockGrpcHandler) GetInventorySummary(ctx context.Context, req *pb.GetInventorySummaryRequest) (*pb.GetInventorySummaryResponse, error) {
+	threshold := req.LowStockThreshold
+	if threshold == 0 {
+		threshold = DefaultLowStockThreshold
+	}
+
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = "id"
+	}
+
+	items, err := s.service.GetInventorySummary(ctx, InventoryQuery{
+		LowStockThreshold: threshold,
+		SortBy:            sortBy,
+		LowStockOnly:      req.LowStockOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetInventorySummaryResponse{
+		Items:             items,
+		LowStockThreshold: threshold,
+		SortBy:            sortBy,
+		LowStockOnly:      req.LowStockOnly,
+	}
+	for _, item := range items {
+		resp.TotalQuantity += item.Quantity
+		resp.TotalReserved += item.ReservedQuantity
+		resp.TotalAvailable += item.Available
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+// ExpiresAt = Clock + ReservationTTL als RFC3339 → Orders/Gateway zeigen dem Kunden die echte Frist
+func TestReserveStockReturnsExpiry(t *testing.T) {
+	store, clock := newTestMemoryStore(nil, 0)
+	h := &StockGrpcHandler{service: NewService(store)}
+	ctx := context.Background()
+	items := []*pb.Item{{ID: "1", Quantity: 1}}
+
+	resp, err := h.ReserveStock(ctx, &pb.ReserveStockRequest{OrderID: "o1", Items: items})
+	if err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	if resp.ReservationID == "" {
+		t.Error("ReservationID empty")
+	}
+	if want := clock.Now().Add(ReservationTTL).Format(time.RFC3339); resp.ExpiresAt != want {
+		t.Fatalf("ExpiresAt = %q; want %q", resp.ExpiresAt, want)
+	}
+
+	// Verlängern → neue Frist ab "jetzt"
+	clock.Advance(5 * time.Minute)
+	resp, err = h.RenewReservation(ctx, &pb.RenewReservationRequest{OrderID: "o1", Items: items})
+	if err != nil {
+		t.Fatalf("RenewReservation: %v", err)
+	}
+	if want := clock.Now().Add(ReservationTTL).Format(time.RFC3339); resp.ExpiresAt != want {
+		t.Fatalf("renewed ExpiresAt = %q; want %q", resp.ExpiresAt, want)
+	}
+}
+
+// Admin Sweep → Released = Anzahl freigegebener Reservierungszeilen, zweiter Sweep findet nichts mehr
+func TestCleanupExpiredReservationsReturnsCount(t *testing.T) {
+	store, clock := newTestMemoryStore(nil, 0)
+	h := &StockGrpcHandler{service: NewService(store)}
+	ctx := context.Background()
+
+	if _, err := store.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 1}, {ID: "2", Quantity: 1}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	clock.Advance(ReservationTTL + time.Second)
+
+	resp, err := h.CleanupExpiredReservations(ctx, &pb.CleanupExpiredReservationsRequest{})
+	if err != nil {
+		t.Fatalf("CleanupExpiredReservations: %v", err)
+	}
+	if resp.Released != 2 {
+		t.Fatalf("Released = %d; want 2", resp.Released)
+	}
+
+	resp, err = h.CleanupExpiredReservations(ctx, &pb.CleanupExpiredReservationsRequest{})
+	if err != nil || resp.Released != 0 {
+		t.Fatalf("second sweep = %v, %v; want 0", resp, err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+// seedInventory: Burger 3x und Pommes 12x reserviert → Pommes unter dem Default Threshold
+func seedInventory(t *testing.T, store StockStore) {
+	t.Helper()
+
+	_, err := store.ReserveStock(context.Background(), "o1", []*pb.Item{
+		{ID: "1", Quantity: 3},
+		{ID: "2", Quantity: 12},
+	})
+	if err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+}
+
+func TestGetInventorySummary(t *testing.T) {
+	store := NewMemoryStore(nil, 0)
+	seedInventory(t, store)
+	h := &StockGrpcHandler{service: NewService(store)}
+
+	resp, err := h.GetInventorySummary(context.Background(), &pb.GetInventorySummaryRequest{})
+	if err != nil {
+		t.Fatalf("GetInventorySummary: %v", err)
+	}
+
+	want := []*pb.InventoryItem{
+		{ID: "1", Name: "Burger", Quantity: 20, ReservedQuantity: 3, Available: 17, LowStock: false},
+		{ID: "2", Name: "Pommes", Quantity: 15, ReservedQuantity: 12, Available: 3, LowStock: true},
+	}
+	if len(resp.Items) != len(want) {
+		t.Fatalf("items = %d; want %d", len(resp.Items), len(want))
+	}
+	for i, w := range want {
+		got := resp.Items[i]
+		if got.ID != w.ID || got.Name != w.Name || got.Quantity != w.Quantity ||
+			got.ReservedQuantity != w.ReservedQuantity || got.Available != w.Available || got.LowStock != w.LowStock {
+			t.Errorf("item %d = %+v; want %+v", i, got, w)
+		}
+	}
+
+	if resp.TotalQuantity != 35 || resp.TotalReserved != 15 || resp.TotalAvailable != 20 {
+		t.Errorf("totals = %d/%d/%d; want 35/15/20", resp.TotalQuantity, resp.TotalReserved, resp.TotalAvailable)
+	}
+	if resp.LowStockThreshold != DefaultLowStockThreshold || resp.SortBy != "id" {
+		t.Errorf("threshold/sort = %d/%q; want defaults %d/id", resp.LowStockThreshold, resp.SortBy, DefaultLowStockThreshold)
+	}
+}
+
+func TestGetInventorySummaryLowStockOnly(t *testing.T) {
+	store := NewMemoryStore(nil, 0)
+	seedInventory(t, store)
+	h := &StockGrpcHandler{service: NewService(store)}
+
+	resp, err := h.GetInventorySummary(context.Background(), &pb.GetInventorySummaryRequest{
+		LowStockOnly: true,
+		SortBy:       "available_desc",
+	})
+	if err != nil {
+		t.Fatalf("GetInventorySummary: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].ID != "2" {
+		t.Fatalf("items = %+v; want only pommes", resp.Items)
+	}
+	// Summen beziehen sich auf die gefilterten Items
+	if resp.TotalReserved != 12 || resp.TotalAvailable != 3 {
+		t.Errorf("totals reserved/available = %d/%d; want 12/3", resp.TotalReserved, resp.TotalAvailable)
+	}
+}
+
+// Gleiche Zahlen aus der EINEN Postgres Query (STOCK_TEST_DSN)
+func TestPostgresGetInventorySummary(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 2)
+	ctx := context.Background()
+
+	if _, err := store.ReserveStock(ctx, ids[0]+"-order", []*pb.Item{{ID: ids[0], Quantity: 95}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+
+	items, err := store.GetInventorySummary(ctx, InventoryQuery{LowStockThreshold: DefaultLowStockThreshold, SortBy: "id"})
+	if err != nil {
+		t.Fatalf("GetInventorySummary: %v", err)
+	}
+
+	byID := make(map[string]*pb.InventoryItem)
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	reserved, free := byID[ids[0]], byID[ids[1]]
+	if reserved == nil || free == nil {
+		t.Fatalf("seeded items missing from summary")
+	}
+	if reserved.Quantity != 100 || reserved.ReservedQuantity != 95 || reserved.Available != 5 || !reserved.LowStock {
+		t.Errorf("reserved item = %+v; want 100/95/5 low stock", reserved)
+	}
+	if free.Quantity != 100 || free.ReservedQuantity != 0 || free.Available != 100 || free.LowStock {
+		t.Errorf("free item = %+v; want 100/0/100 not low stock", free)
+	}
+}
+
+// Sortierung pro SortBy → Burger (17 verfügbar) vs. Pommes (3 verfügbar)
+func TestGetInventorySummarySort(t *testing.T) {
+	tests := []struct {
+		sortBy string
+		want   []string
+	}{
+		{"id", []string{"1", "2"}},
+		{"name", []string{"1", "2"}}, // Burger < Pommes
+		{"available", []string{"2", "1"}},
+		{"available_desc", []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			store := NewMemoryStore(nil, 0)
+			seedInventory(t, store)
+
+			items, err := store.GetInventorySummary(context.Background(), InventoryQuery{LowStockThreshold: DefaultLowStockThreshold, SortBy: tt.sortBy})
+			if err != nil {
+				t.Fatalf("GetInventorySummary: %v", err)
+			}
+			if got := inventoryIDs(items); !slices.Equal(got, tt.want) {
+				t.Fatalf("order = %v; want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewMemoryStore(nil, 0).GetInventorySummary(context.Background(), InventoryQuery{SortBy: "price"}); err == nil {
+		t.Fatal("unknown sort accepted")
+	}
+}
+
+func inventoryIDs(items []*pb.InventoryItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+// Filter + ORDER BY laufen in SQL → Reihenfolge und Auswahl kommen direkt aus Postgres
+func TestPostgresGetInventorySummarySortedLowStockOnly(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 3)
+	ctx := context.Background()
+
+	// Verfügbar: ids[0] = 8, ids[1] = 100, ids[2] = 2 → nur 0 und 2 unter dem Threshold
+	reserve := map[string]int32{ids[0]: 92, ids[2]: 98}
+	for id, qty := range reserve {
+		if _, err := store.ReserveStock(ctx, id+"-order", []*pb.Item{{ID: id, Quantity: qty}}); err != nil {
+			t.Fatalf("ReserveStock %s: %v", id, err)
+		}
+	}
+
+	items, err := store.GetInventorySummary(ctx, InventoryQuery{LowStockThreshold: 10, SortBy: "available", LowStockOnly: true})
+	if err != nil {
+		t.Fatalf("GetInventorySummary: %v", err)
+	}
+
+	// Andere Items in der Test DB ignorieren → nur die eigenen, in Ergebnis-Reihenfolge
+	seeded := map[string]bool{ids[0]: true, ids[1]: true, ids[2]: true}
+	var got []string
+	for _, item := range items {
+		if !item.LowStock {
+			t.Errorf("item %s (available %d) returned with LowStockOnly", item.ID, item.Available)
+		}
+		if seeded[item.ID] {
+			got = append(got, item.ID)
+		}
+	}
+	if want := []string{ids[2], ids[0]}; !slices.Equal(got, want) {
+		t.Fatalf("low stock items = %v; want %v (ascending by available)", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Warum embed?
+// → SQL Files landen im Binary → kein Volume/Mount nötig, frischer Deploy hat sofort ein Schema
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID: Postgres Advisory Lock Key (beliebig, aber fix)
+// → Mehrere Stock Instanzen starten gleichzeitig → nur EINE migriert
+const migrationLockID = 727272
+
+// migration: Eine versionierte SQL Datei (z.B. "001_init.sql" → Version "001_init")
+type migration struct {
+	version string
+	sql     string
+}
+
+// loadMigrations liest alle eingebetteten Migrationen, sortiert nach Dateiname
+func loadMigrations() ([]migration, error) {
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	migrations := make([]migration, 0, len(names))
+	for _, name := range names {
+		content, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+		migrations = append(migrations, migration{version: version, sql: string(content)})
+	}
+
+	return migrations, nil
+}
+
+// migrate wendet alle noch nicht angewendeten Migrationen an
+// Flow:
+// 1. Advisory Lock holen (parallele Instanzen warten)
+// 2. schema_migrations anlegen falls nicht vorhanden
+// 3. Pro fehlender Version: SQL + Eintrag in schema_migrations in EINER Transaktion
+//
+// Returns: Versionen die in diesem Lauf angewendet wurden (leer = Schema war aktuell)
+func migrate(ctx context.Context, db *sql.DB) ([]string, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// Warum eigene Connection?
+	// → Advisory Locks gehören zur Session → Lock + Unlock müssen auf derselben Connection laufen
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	createTable := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied := make(map[string]bool)
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	var ran []string
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return ran, err
+		}
+		ran = append(ran, m.version)
+	}
+
+	return ran, nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.version, err)
+	}
+	defer tx.Rollback()
+
+	// Ohne Args → Simple Query Protocol → mehrere Statements pro Datei erlaubt
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m.version, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, m.version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.version, err)
+	}
+
+	return nil
+}
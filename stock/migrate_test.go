@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	// Reihenfolge = Dateiname, jede Version genau einmal, nie leer
+	for i, m := range migrations {
+		if want := fmt.Sprintf("%03d_", i+1); !strings.HasPrefix(m.version, want) {
+			t.Errorf("migration %d = %s; want prefix %s (sorted, no gaps)", i, m.version, want)
+		}
+		if strings.TrimSpace(m.sql) == "" {
+			t.Errorf("migration %s is empty", m.version)
+		}
+	}
+}
+
+// openEmptySchema: Eigenes, leeres Schema in der Test DB (STOCK_TEST_DSN) → "frischer Deploy"
+// → search_path zeigt nur auf das neue Schema, bestehende Tabellen sind unsichtbar
+func openEmptySchema(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("STOCK_TEST_DSN")
+	if dsn == "" {
+		t.Skip("STOCK_TEST_DSN not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		admin.Close()
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		admin.Close()
+	})
+
+	// lib/pq reicht unbekannte DSN Parameter als Runtime Parameter durch
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			t.Fatalf("parse dsn: %v", err)
+		}
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		dsn = u.String()
+	} else {
+		dsn += " search_path=" + schema
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateEmptyDatabaseIsIdempotent(t *testing.T) {
+	db := openEmptySchema(t)
+	ctx := context.Background()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	var all []string
+	for _, m := range migrations {
+		all = append(all, m.version)
+	}
+
+	ran, err := migrate(ctx, db)
+	if err != nil {
+		t.Fatalf("first migrate: %v", err)
+	}
+	if !slices.Equal(ran, all) {
+		t.Fatalf("first run applied %v; want %v", ran, all)
+	}
+
+	for _, table := range []string{"items", "stock_reservations"} {
+		var exists bool
+		if err := db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+			t.Fatalf("check %s: %v", table, err)
+		}
+		if !exists {
+			t.Errorf("table %s missing after migrate", table)
+		}
+	}
+
+	// Zweiter Start (z.B. Restart, zweite Instanz) → nichts mehr zu tun, kein Fehler
+	ran, err = migrate(ctx, db)
+	if err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	if len(ran) != 0 {
+		t.Fatalf("second run applied %v; want nothing", ran)
+	}
+}
+
+// "In stock" Queries brauchen Index Support → Indexes + Generated Column müssen nach migrate da sein
+func TestMigrateCreatesAvailabilityIndexes(t *testing.T) {
+	db := openEmptySchema(t)
+	ctx := context.Background()
+
+	if _, err := migrate(ctx, db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	for _, index := range []string{"idx_items_reserved_quantity", "idx_items_in_stock"} {
+		var exists bool
+		err := db.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM pg_indexes
+				WHERE schemaname = current_schema() AND tablename = 'items' AND indexname = $1
+			)
+		`, index).Scan(&exists)
+		if err != nil {
+			t.Fatalf("check %s: %v", index, err)
+		}
+		if !exists {
+			t.Errorf("index %s missing after migrate", index)
+		}
+	}
+
+	var generated string
+	err := db.QueryRowContext(ctx, `
+		SELECT is_generated FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'items' AND column_name = 'available_quantity'
+	`).Scan(&generated)
+	if err != nil {
+		t.Fatalf("available_quantity column: %v", err)
+	}
+	if generated != "ALWAYS" {
+		t.Errorf("available_quantity is_generated = %q; want ALWAYS", generated)
+	}
+}
@@ -1,14 +1,21 @@
 
 ALTER TABLE items
-ADD COLUMN reserved_quantity INTEGER NOT NULL DEFAULT 0;
+ADD COLUMN IF NOT EXISTS reserved_quantity INTEGER NOT NULL DEFAULT 0;
 
-ALTER TABLE items
-ADD CONSTRAINT items_reserved_check CHECK (reserved_quantity >= 0 AND reserved_quantity <= quantity);
+-- ADD CONSTRAINT kennt kein IF NOT EXISTS → über pg_constraint prüfen
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'items_reserved_check') THEN
+        ALTER TABLE items
+        ADD CONSTRAINT items_reserved_check CHECK (reserved_quantity >= 0 AND reserved_quantity <= quantity);
+    END IF;
+END
+$$;
 
-CREATE INDEX idx_items_available_stock ON items((quantity - reserved_quantity));
+CREATE INDEX IF NOT EXISTS idx_items_available_stock ON items((quantity - reserved_quantity));
 
 
-CREATE TABLE stock_reservations (
+CREATE TABLE IF NOT EXISTS stock_reservations (
     id SERIAL PRIMARY KEY,
     reservation_id VARCHAR(255) UNIQUE NOT NULL,  -- UUID for idempotency
     order_id VARCHAR(255) NOT NULL,               -- Link to order
@@ -28,19 +35,20 @@ CREATE TABLE stock_reservations (
 -- =====================================================
 
 -- Index for finding reservations by order (for confirm/release)
-CREATE INDEX idx_reservations_order_id ON stock_reservations(order_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_order_id ON stock_reservations(order_id);
 
 -- Index for finding expired reservations (for background cleanup job)
-CREATE INDEX idx_reservations_expires ON stock_reservations(expires_at, status)
+CREATE INDEX IF NOT EXISTS idx_reservations_expires ON stock_reservations(expires_at, status)
 WHERE status = 'reserved';
 
 -- Index for finding active reservations by item
-CREATE INDEX idx_reservations_item_status ON stock_reservations(item_id, status);
+CREATE INDEX IF NOT EXISTS idx_reservations_item_status ON stock_reservations(item_id, status);
 
 -- =====================================================
 -- PART 4: Trigger for updated_at
 -- =====================================================
 
+DROP TRIGGER IF EXISTS update_stock_reservations_updated_at ON stock_reservations;
 CREATE TRIGGER update_stock_reservations_updated_at
     BEFORE UPDATE ON stock_reservations
     FOR EACH ROW
@@ -50,7 +58,7 @@ CREATE TRIGGER update_stock_reservations_updated_at
 -- PART 5: Helper View - Available Stock
 -- =====================================================
 
-CREATE VIEW items_available_stock AS
+CREATE OR REPLACE VIEW items_available_stock AS
 SELECT
     id,
     name,
@@ -0,0 +1,19 @@
+-- =====================================================
+-- Available Stock als Generated Column + Indexes
+-- =====================================================
+-- Warum Generated Column?
+-- → (quantity - reserved_quantity) wurde pro Row in jeder Query berechnet
+-- → STORED: Postgres hält den Wert bei jedem UPDATE aktuell → direkt indexierbar
+-- → "Welche Items sind auf Lager?" = Index Scan statt Full Table Scan
+
+ALTER TABLE items
+ADD COLUMN IF NOT EXISTS available_quantity INTEGER
+    GENERATED ALWAYS AS (quantity - reserved_quantity) STORED;
+
+-- Index für Reservation-Lastabfragen (z.B. "Items mit offenen Reservierungen")
+CREATE INDEX IF NOT EXISTS idx_items_reserved_quantity ON items(reserved_quantity);
+
+-- Partial Index: Nur Items die tatsächlich verfügbar sind
+-- → Menu / "in stock" Queries: WHERE available_quantity > 0
+CREATE INDEX IF NOT EXISTS idx_items_in_stock ON items(available_quantity)
+WHERE available_quantity > 0;
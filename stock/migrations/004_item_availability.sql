@@ -0,0 +1,10 @@
+-- =====================================================
+-- Item Availability (saisonal / "heute aus")
+-- =====================================================
+-- Warum eigene Spalte statt quantity = 0 oder DELETE?
+-- → quantity = 0 verliert den echten Bestand, DELETE verliert Item + Reservierungs-Historie
+-- → is_available = FALSE: Item bleibt komplett erhalten, wird nur nicht verkauft
+-- → DEFAULT TRUE: Bestehende Items bleiben verfügbar
+
+ALTER TABLE items
+ADD COLUMN IF NOT EXISTS is_available BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,9 @@
+-- =====================================================
+-- Fulfillment Tracking (order.preparing)
+-- =====================================================
+-- Warum auf stock_reservations?
+-- → Reservierung ist der Lebenslauf des Stocks einer Order: reserved → confirmed → Küche startet
+-- → NULL = Küche hat (noch) nicht angefangen, alte Reservierungen bleiben unverändert
+
+ALTER TABLE stock_reservations
+ADD COLUMN IF NOT EXISTS fulfillment_started_at TIMESTAMP;
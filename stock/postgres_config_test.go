@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// connParams: Query Parameter des gebauten Connection Strings
+func connParams(t *testing.T, c PostgresConfig) url.Values {
+	t.Helper()
+	u, err := url.Parse(c.ConnString())
+	if err != nil {
+		t.Fatalf("parse %q: %v", c.ConnString(), err)
+	}
+	return u.Query()
+}
+
+func TestPostgresConfigSSLMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		sslMode string
+		want    string
+	}{
+		{"localhost defaults to disable", "localhost", "", "disable"},
+		{"remote host defaults to require", "db.internal", "", "require"},
+		{"explicit mode wins on localhost", "localhost", "verify-full", "verify-full"},
+		{"explicit disable on remote host", "db.internal", "disable", "disable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := PostgresConfig{Host: tt.host, Port: "5432", User: "u", Password: "p", Database: "stock", SSLMode: tt.sslMode}
+			if got := connParams(t, c).Get("sslmode"); got != tt.want {
+				t.Fatalf("sslmode = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// application_name + connect_timeout (ganze Sekunden) landen im String, Params überschreiben sie nicht
+func TestPostgresConfigParams(t *testing.T) {
+	c := PostgresConfig{
+		Host: "db.internal", Port: "5432", User: "u", Password: "p", Database: "stock",
+		SSLMode:         "require",
+		ConnectTimeout:  2500 * time.Millisecond,
+		ApplicationName: "stock",
+		Params:          map[string]string{"sslrootcert": "/etc/ssl/pg-ca.pem", "application_name": "other", "sslmode": "disable"},
+	}
+	params := connParams(t, c)
+
+	want := map[string]string{
+		"sslmode":          "require",
+		"connect_timeout":  "3",
+		"application_name": "stock",
+		"sslrootcert":      "/etc/ssl/pg-ca.pem",
+	}
+	for k, v := range want {
+		if got := params.Get(k); got != v {
+			t.Errorf("%s = %q; want %q", k, got, v)
+		}
+	}
+
+	// Unter einer Sekunde → 1 (0 hieße bei lib/pq "unbegrenzt")
+	c.ConnectTimeout = 200 * time.Millisecond
+	if got := connParams(t, c).Get("connect_timeout"); got != "1" {
+		t.Errorf("connect_timeout = %q; want 1", got)
+	}
+}
+
+// Sonderzeichen im Passwort werden escaped, Redacted zeigt es nie
+func TestPostgresConfigEscapesAndRedacts(t *testing.T) {
+	c := PostgresConfig{Host: "localhost", Port: "5432", User: "stock", Password: "p@ss/word", Database: "stock"}
+
+	u, err := url.Parse(c.ConnString())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if pass, _ := u.User.Password(); pass != "p@ss/word" || u.Host != "localhost:5432" || u.Path != "/stock" {
+		t.Fatalf("parsed = %q@%s%s; want the original password, host and database", pass, u.Host, u.Path)
+	}
+	if r := c.Redacted(); strings.Contains(r, "p@ss") || strings.Contains(r, "p%40ss") {
+		t.Errorf("Redacted() = %q; leaks the password", r)
+	}
+}
+
+// Ohne POSTGRES_APPLICATION_NAME → application_name=stock (pg_stat_activity ordnet Queries dem Service zu)
+// → POSTGRES_PARAMS kann den Namen nicht versehentlich überschreiben
+func TestPostgresConfigFromEnvApplicationName(t *testing.T) {
+	if postgresAppName != serviceName {
+		t.Skipf("POSTGRES_APPLICATION_NAME overrides the default (%q)", postgresAppName)
+	}
+	t.Setenv("POSTGRES_PARAMS", "application_name=other&sslrootcert=/etc/ssl/pg-ca.pem")
+	t.Setenv("POSTGRES_CONNECT_TIMEOUT", "5s")
+
+	c, err := postgresConfigFromEnv()
+	if err != nil {
+		t.Fatalf("postgresConfigFromEnv: %v", err)
+	}
+	params := connParams(t, c)
+	if got := params.Get("application_name"); got != "stock" {
+		t.Errorf("application_name = %q; want stock", got)
+	}
+	if got := params.Get("connect_timeout"); got != "5" {
+		t.Errorf("connect_timeout = %q; want 5", got)
+	}
+	if got := params.Get("sslrootcert"); got != "/etc/ssl/pg-ca.pem" {
+		t.Errorf("sslrootcert = %q; want /etc/ssl/pg-ca.pem", got)
+	}
+}
+
+func TestPostgresConfigFromEnvInvalidTimeout(t *testing.T) {
+	t.Setenv("POSTGRES_CONNECT_TIMEOUT", "five")
+	if _, err := postgresConfigFromEnv(); err == nil {
+		t.Fatal("postgresConfigFromEnv succeeded; want error for an invalid timeout")
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	pb "github.com/timour/order-microservices/common/api"
 )
@@ -14,7 +16,9 @@ func NewService(store StockStore) *Service {
 	return &Service{store}
 }
 
-func (s *Service) CheckIfItemAreInStock(ctx context.Context, p []*pb.ItemsWithQuantity) (bool, []*pb.Item, error) {
+// CheckIfItemAreInStock: Sind alle angefragten Items in der Menge verfügbar?
+// Returns: InStock, Items (mit Preisen), OutOfStockItems (welche fehlen + wie viele noch da sind)
+func (s *Service) CheckIfItemAreInStock(ctx context.Context, p []*pb.ItemsWithQuantity) (bool, []*pb.Item, []*pb.OutOfStockItem, error) {
 	itemIDs := make([]string, 0)
 	for _, item := range p {
 		itemIDs = append(itemIDs, item.ID)
@@ -22,16 +26,15 @@ func (s *Service) CheckIfItemAreInStock(ctx context.Context, p []*pb.ItemsWithQu
 
 	itemsInStock, err := s.store.GetItems(ctx, itemIDs)
 	if err != nil {
-		return false, nil, err
+		return false, nil, nil, err
 	}
 
-	// Check if all items are in stock
-	for _, stockItem := range itemsInStock {
-		for _, reqItem := range p {
-			if stockItem.ID == reqItem.ID && stockItem.Quantity < reqItem.Quantity {
-				return false, itemsInStock, nil
-			}
-		}
+	outOfStock, err := s.outOfStockItems(ctx, itemsInStock, p)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	if len(outOfStock) > 0 {
+		return false, itemsInStock, outOfStock, nil
 	}
 
 	// create items with prices from stock
@@ -49,13 +52,151 @@ func (s *Service) CheckIfItemAreInStock(ctx context.Context, p []*pb.ItemsWithQu
 		}
 	}
 
-	return true, items, nil
+	return true, items, nil, nil
+}
+
+// outOfStockItems: Items deren verfügbare Menge (quantity - reserved) nicht reicht
+// Warum GetAvailableQuantities statt item.Quantity?
+// → Quantity zählt reservierte Einheiten mit → Check sagt "ok", ReserveStock scheitert danach trotzdem
+// → Vorübergehend deaktivierte Items (Unavailable) → Available 0, egal wie viel Bestand da ist
+// → Unbekannte Items fehlen hier → Orders lehnt sie als InvalidArgument ab (kein Bestandsproblem)
+func (s *Service) outOfStockItems(ctx context.Context, stockItems []*pb.Item, p []*pb.ItemsWithQuantity) ([]*pb.OutOfStockItem, error) {
+	// Gleiche ID in mehreren Zeilen → Orders fasst sie zusammen → gegen die Summe prüfen
+	requested := make(map[string]int32, len(p))
+	for _, reqItem := range p {
+		requested[reqItem.ID] += reqItem.Quantity
+	}
+
+	// EINE Query für den ganzen Warenkorb statt ein Round Trip pro Item
+	ids := make([]string, 0, len(stockItems))
+	for _, stockItem := range stockItems {
+		ids = append(ids, stockItem.ID)
+	}
+	availableByID, err := s.store.GetAvailableQuantities(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var outOfStock []*pb.OutOfStockItem
+	for _, stockItem := range stockItems {
+		want, ok := requested[stockItem.ID]
+		if !ok {
+			continue
+		}
+
+		var available int32
+		if !stockItem.Unavailable {
+			available = availableByID[stockItem.ID]
+		}
+
+		if available < want {
+			outOfStock = append(outOfStock, &pb.OutOfStockItem{
+				ItemID:    stockItem.ID,
+				Requested: want,
+				Available: max(available, 0),
+			})
+		}
+	}
+
+	return outOfStock, nil
 }
 
 func (s *Service) GetItems(ctx context.Context, ids []string) ([]*pb.Item, error) {
 	return s.store.GetItems(ctx, ids)
 }
 
-func (s *Service) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (string, error) {
+func (s *Service) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
 	return s.store.ReserveStock(ctx, orderID, items)
 }
+
+func (s *Service) RenewReservation(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
+	return s.store.RenewReservation(ctx, orderID, items)
+}
+
+// DefaultLowStockThreshold: Ab dieser Verfügbarkeit gilt ein Item als "low stock"
+const DefaultLowStockThreshold = 10
+
+func (s *Service) GetInventorySummary(ctx context.Context, query InventoryQuery) ([]*pb.InventoryItem, error) {
+	return s.store.GetInventorySummary(ctx, query)
+}
+
+// BulkCreateItems: Validiert ALLE Zeilen, importiert nur wenn keine fehlerhaft ist
+// Returns: Anzahl angelegter Items ODER Fehler pro Zeile (dann wurde nichts angelegt)
+func (s *Service) BulkCreateItems(ctx context.Context, items []*pb.Item) (int, []*pb.ItemRowError, error) {
+	// Warum erst alles validieren?
+	// → Operator sieht ALLE kaputten Zeilen auf einmal, nicht nur die erste
+	if rowErrors := validateNewItems(items); len(rowErrors) > 0 {
+		return 0, rowErrors, nil
+	}
+
+	if err := s.store.BulkCreateItems(ctx, items); err != nil {
+		var rowErr *ItemRowError
+		if errors.As(err, &rowErr) {
+			return 0, []*pb.ItemRowError{{Row: int32(rowErr.Row), ID: rowErr.ID, Error: rowErr.Err.Error()}}, nil
+		}
+		return 0, nil, err
+	}
+
+	return len(items), nil, nil
+}
+
+// validateNewItems: Pflichtfelder, Spaltenlängen (siehe migrations/001_init.sql) und doppelte IDs im Import
+func validateNewItems(items []*pb.Item) []*pb.ItemRowError {
+	var rowErrors []*pb.ItemRowError
+	seen := make(map[string]int, len(items))
+
+	for i, item := range items {
+		var problem string
+		switch {
+		case item.ID == "":
+			problem = "ID is required"
+		case len(item.ID) > 50:
+			problem = "ID must be at most 50 characters"
+		case item.Name == "":
+			problem = "Name is required"
+		case len(item.Name) > 100:
+			problem = "Name must be at most 100 characters"
+		case item.PriceID == "":
+			problem = "PriceID is required"
+		case len(item.PriceID) > 100:
+			problem = "PriceID must be at most 100 characters"
+		case item.Quantity < 0:
+			problem = fmt.Sprintf("Quantity must not be negative, got %d", item.Quantity)
+		}
+		if first, ok := seen[item.ID]; ok && problem == "" {
+			problem = fmt.Sprintf("duplicate ID, already used in row %d", first)
+		}
+
+		if problem != "" {
+			rowErrors = append(rowErrors, &pb.ItemRowError{Row: int32(i), ID: item.ID, Error: problem})
+			continue
+		}
+		seen[item.ID] = i
+	}
+
+	return rowErrors
+}
+
+func (s *Service) RestockItems(ctx context.Context, orderID string, items []*pb.ItemsWithQuantity) error {
+	return s.store.RestockItems(ctx, orderID, items)
+}
+
+func (s *Service) SetItemAvailability(ctx context.Context, id string, available bool) (*pb.Item, error) {
+	return s.store.SetItemAvailability(ctx, id, available)
+}
+
+func (s *Service) ConfirmReservation(ctx context.Context, orderID string) error {
+	return s.store.ConfirmReservation(ctx, orderID)
+}
+
+func (s *Service) ReleaseReservation(ctx context.Context, orderID, reservationID string) error {
+	return s.store.ReleaseReservation(ctx, orderID, reservationID)
+}
+
+func (s *Service) CleanupExpiredReservations(ctx context.Context) (int, error) {
+	return s.store.CleanupExpiredReservations(ctx)
+}
+
+func (s *Service) GetReservation(ctx context.Context, orderID string) ([]ReservationLine, error) {
+	return s.store.GetReservation(ctx, orderID)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	pb "github.com/timour/order-microservices/common/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Vorübergehend deaktiviertes Item mit vollem Bestand → trotzdem nicht verfügbar
+func TestCheckIfItemAreInStockUnavailableItem(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(nil, 0)
+	if _, err := store.SetItemAvailability(ctx, "1", false); err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(store)
+
+	inStock, _, outOfStock, err := s.CheckIfItemAreInStock(ctx, []*pb.ItemsWithQuantity{
+		{ID: "1", Quantity: 1}, // Burger: 20 auf Lager, aber deaktiviert
+		{ID: "2", Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("CheckIfItemAreInStock: %v", err)
+	}
+	if inStock {
+		t.Fatal("unavailable item reported in stock")
+	}
+	if len(outOfStock) != 1 || outOfStock[0].ItemID != "1" || outOfStock[0].Available != 0 {
+		t.Fatalf("outOfStock = %v; want item 1 with 0 available", outOfStock)
+	}
+}
+
+func TestReserveStockRejectsUnavailableItem(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(nil, 0)
+	if _, err := store.SetItemAvailability(ctx, "1", false); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := store.ReserveStock(ctx, "o1", []*pb.Item{{ID: "2", Quantity: 1}, {ID: "1", Quantity: 1}})
+	if !errors.Is(err, ErrItemUnavailable) {
+		t.Fatalf("ReserveStock = %v; want ErrItemUnavailable", err)
+	}
+	// Alles oder nichts: Pommes dürfen nicht reserviert bleiben
+	available, _ := store.GetAvailableQuantities(ctx, []string{"2"})
+	if available["2"] != 15 {
+		t.Fatalf("available pommes = %d; want 15 (nothing reserved)", available["2"])
+	}
+
+	if _, err := store.SetItemAvailability(ctx, "1", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 1}}); err != nil {
+		t.Fatalf("ReserveStock after re-enabling: %v", err)
+	}
+}
+
+func TestReservationErrorCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{fmt.Errorf("%w: 1", ErrItemUnavailable), codes.FailedPrecondition},
+		{fmt.Errorf("%w for item 1", ErrInsufficientStock), codes.FailedPrecondition},
+		{fmt.Errorf("%w: 9", ErrItemNotFound), codes.InvalidArgument},
+		{ErrReservationConflict, codes.Aborted},
+	}
+	for _, tt := range tests {
+		if got := status.Code(reservationError(tt.err)); got != tt.want {
+			t.Errorf("reservationError(%v) = %s; want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBulkCreateItems(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(NewMemoryStore(nil, 0))
+
+	created, rowErrors, err := s.BulkCreateItems(ctx, []*pb.Item{
+		{ID: "10", Name: "Cola", PriceID: "price_cola", Quantity: 200},
+		{ID: "11", Name: "Wasser", PriceID: "price_wasser", Quantity: 0},
+	})
+	if err != nil || len(rowErrors) > 0 {
+		t.Fatalf("BulkCreateItems = %v, %v; want no errors", rowErrors, err)
+	}
+	if created != 2 {
+		t.Fatalf("created = %d; want 2", created)
+	}
+	item, err := s.store.GetItem(ctx, "10")
+	if err != nil || item.Quantity != 200 {
+		t.Fatalf("GetItem(10) = %v, %v; want Cola with 200", item, err)
+	}
+}
+
+// Eine kaputte Zeile → ALLE Zeilenfehler zurück, nichts angelegt
+func TestBulkCreateItemsInvalidRowRollsBack(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []*pb.Item
+		wantRows []int32
+	}{
+		{
+			name: "validation",
+			items: []*pb.Item{
+				{ID: "10", Name: "Cola", PriceID: "price_cola", Quantity: 200},
+				{ID: "11", Name: "", PriceID: "price_wasser", Quantity: 5},
+				{ID: "10", Name: "Cola", PriceID: "price_cola", Quantity: 1},
+			},
+			wantRows: []int32{1, 2},
+		},
+		{
+			// Erst der Store merkt es: Burger "1" gibt es schon
+			name: "existing id",
+			items: []*pb.Item{
+				{ID: "10", Name: "Cola", PriceID: "price_cola", Quantity: 200},
+				{ID: "1", Name: "Burger", PriceID: "price_burger", Quantity: 5},
+			},
+			wantRows: []int32{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewService(NewMemoryStore(nil, 0))
+
+			created, rowErrors, err := s.BulkCreateItems(ctx, tt.items)
+			if err != nil {
+				t.Fatalf("BulkCreateItems: %v", err)
+			}
+			if created != 0 {
+				t.Errorf("created = %d; want 0", created)
+			}
+			if len(rowErrors) != len(tt.wantRows) {
+				t.Fatalf("rowErrors = %v; want rows %v", rowErrors, tt.wantRows)
+			}
+			for i, row := range tt.wantRows {
+				if rowErrors[i].Row != row {
+					t.Errorf("rowErrors[%d].Row = %d; want %d", i, rowErrors[i].Row, row)
+				}
+			}
+			if _, err := s.store.GetItem(ctx, "10"); !errors.Is(err, ErrItemNotFound) {
+				t.Errorf("GetItem(10) = %v; want ErrItemNotFound (batch rolled back)", err)
+			}
+		})
+	}
+}
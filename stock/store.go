@@ -1,52 +1,51 @@
 package main
 
 import (
-	"context"
 	"fmt"
+	"time"
 
-	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
 )
 
-type Store struct {
-	stock map[string]*pb.Item
-}
+// Store Backends (STOCK_BACKEND)
+const (
+	StoreBackendPostgres = "postgres" // Default: Produktion
+	StoreBackendMemory   = "memory"   // Lokal ohne Postgres, Bestand geht beim Neustart verloren
+)
 
-func NewStore() *Store {
-	return &Store{
-		stock: map[string]*pb.Item{
-			"1": {
-				ID:       "1",
-				Name:     "Burger",
-				PriceID:  "price_1SQYsL3th7a1Jo3bsOVNnRpm",
-				Quantity: 20,
-			},
-			"2": {
-				ID:       "2",
-				Name:     "Pommes",
-				PriceID:  "price_POMMES_TODO",  // TODO: Erstelle price ID in Stripe für Pommes
-				Quantity: 15,
-			},
-		},
-	}
+// Store: StockStore + Verbindung schließen → Rückgabe von NewStore
+// Warum nicht einfach *PostgresStore?
+// → main/CachedStore kennen nur das Interface → Backend per ENV tauschbar
+type Store interface {
+	StockStore
+	Close() error
 }
 
-func (s *Store) GetItem(ctx context.Context, id string) (*pb.Item, error) {
-	for _, item := range s.stock {
-		if item.ID == id {
-			return item, nil
-		}
-	}
+// StoreConfig: Alles was NewStore für beide Backends braucht
+type StoreConfig struct {
+	Backend string // StoreBackendPostgres | StoreBackendMemory ("" → Postgres)
 
-	return nil, fmt.Errorf("item not found")
+	// ConnString: Nur für Postgres (siehe PostgresConfig.ConnString)
+	ConnString string
+	// Metrics darf nil sein
+	Metrics *metrics.StockMetrics
+	// ConfirmGrace: siehe DefaultConfirmGrace (<= 0 = aus)
+	ConfirmGrace time.Duration
 }
 
-func (s *Store) GetItems(ctx context.Context, ids []string) ([]*pb.Item, error) {
-	var res []*pb.Item
-	for _, id := range ids {
-		if i, ok := s.stock[id]; ok {
-			res = append(res, i)
+// NewStore: Wählt das Backend aus cfg.Backend
+// Unbekanntes Backend → Fehler statt stillem Fallback (Tippfehler soll den Start abbrechen)
+func NewStore(cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", StoreBackendPostgres:
+		store, err := NewPostgresStore(cfg.ConnString, cfg.Metrics, cfg.ConfirmGrace)
+		if err != nil {
+			return nil, err
 		}
+		return store, nil
+	case StoreBackendMemory:
+		return NewMemoryStore(cfg.Metrics, cfg.ConfirmGrace), nil
+	default:
+		return nil, fmt.Errorf("unknown stock backend %q (want %q or %q)", cfg.Backend, StoreBackendPostgres, StoreBackendMemory)
 	}
-
-	return res, nil
 }
@@ -3,21 +3,29 @@ package main
 import (
 	"context"
 	"log"
+	"time"
 
 	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
 )
 
-// CachedStore wraps PostgresStore with Redis Cache-Aside pattern
+// CachedStore wraps a StockStore (Postgres or in-memory) with Redis Cache-Aside pattern
 type CachedStore struct {
-	store *PostgresStore
+	store StockStore
 	cache *ItemCache
+
+	allItemsTTL time.Duration         // 0 = "get all" umgeht den Cache (altes Verhalten)
+	metrics     *metrics.StockMetrics // darf nil sein
 }
 
 // NewCachedStore creates a new cached store
-func NewCachedStore(store *PostgresStore, cache *ItemCache) *CachedStore {
+// allItemsTTL: TTL des "all_items" Keys (GetItems ohne IDs), <= 0 → nicht cachen
+func NewCachedStore(store StockStore, cache *ItemCache, allItemsTTL time.Duration, m *metrics.StockMetrics) *CachedStore {
 	return &CachedStore{
-		store: store,
-		cache: cache,
+		store:       store,
+		cache:       cache,
+		allItemsTTL: allItemsTTL,
+		metrics:     m,
 	}
 }
 
@@ -51,12 +59,10 @@ func (s *CachedStore) GetItem(ctx context.Context, id string) (*pb.Item, error)
 }
 
 // GetItems implements Cache-Aside pattern for batch retrieval
-// Wenn ids leer ist, werden ALLE Items zurückgegeben (no caching for "get all")
+// Wenn ids leer ist, werden ALLE Items zurückgegeben (→ getAllItems, "all_items" Key)
 func (s *CachedStore) GetItems(ctx context.Context, ids []string) ([]*pb.Item, error) {
-	// If no IDs specified, bypass cache and return ALL items from DB
 	if len(ids) == 0 {
-		log.Printf("📋 GetItems: No IDs specified, fetching ALL items from DB (bypassing cache)")
-		return s.store.GetItems(ctx, ids)
+		return s.getAllItems(ctx)
 	}
 
 	// 1. Try to get all items from cache using batch MGET
@@ -123,6 +129,51 @@ func (s *CachedStore) GetItems(ctx context.Context, ids []string) ([]*pb.Item, e
 	return allItems, nil
 }
 
+// getAllItems: Komplette Item Liste (Menu) über EINEN Redis Key mit kurzer TTL
+// Warum kurze TTL UND Invalidierung?
+// → Invalidierung deckt unsere eigenen Mutationen ab (Decrement, Restock, Confirm, BulkCreate)
+// → TTL begrenzt Staleness bei Änderungen an der DB vorbei (z.B. manuelles SQL)
+func (s *CachedStore) getAllItems(ctx context.Context) ([]*pb.Item, error) {
+	if s.allItemsTTL <= 0 {
+		log.Printf("📋 GetItems: No IDs specified, fetching ALL items from DB (bypassing cache)")
+		s.metrics.RecordAllItemsCache("bypass")
+		return s.store.GetItems(ctx, nil)
+	}
+
+	cachedItems, err := s.cache.GetAllItems(ctx)
+	if err != nil {
+		log.Printf("⚠️  Cache error (will query DB): %v", err)
+	} else if cachedItems != nil {
+		log.Printf("🎯 Cache HIT: All items (%d)", len(cachedItems))
+		s.metrics.RecordAllItemsCache("hit")
+		return cachedItems, nil
+	}
+
+	log.Printf("❌ Cache MISS: All items - Querying PostgreSQL")
+	s.metrics.RecordAllItemsCache("miss")
+
+	items, err := s.store.GetItems(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.SetAllItems(ctx, items, s.allItemsTTL); err != nil {
+		log.Printf("⚠️  Failed to populate all items cache: %v", err)
+	}
+
+	return items, nil
+}
+
+// invalidateAllItems: Nach JEDER Item Mutation → nächster Menu Call liest frisch aus PostgreSQL (best-effort)
+func (s *CachedStore) invalidateAllItems(ctx context.Context) {
+	if s.allItemsTTL <= 0 {
+		return
+	}
+	if err := s.cache.InvalidateAllItems(ctx); err != nil {
+		log.Printf("⚠️  Failed to invalidate all items cache: %v", err)
+	}
+}
+
 // DecrementQuantity updates PostgreSQL and invalidates cache
 func (s *CachedStore) DecrementQuantity(ctx context.Context, id string, amount int32) error {
 	// 1. Update PostgreSQL first
@@ -136,23 +187,98 @@ func (s *CachedStore) DecrementQuantity(ctx context.Context, id string, amount i
 	} else {
 		log.Printf("🗑️  Cache invalidated: Item %s (quantity changed)", id)
 	}
+	s.invalidateAllItems(ctx)
 
 	return nil
 }
 
+// SetItemAvailability updates PostgreSQL and invalidates the item + the cached item list
+// → Sonst zeigt das Menu ein ausgeschaltetes Item bis zur TTL weiter an
+func (s *CachedStore) SetItemAvailability(ctx context.Context, id string, available bool) (*pb.Item, error) {
+	item, err := s.store.SetItemAvailability(ctx, id, available)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.InvalidateItem(ctx, id); err != nil {
+		log.Printf("⚠️  Failed to invalidate cache for item %s: %v", id, err)
+	}
+	s.invalidateAllItems(ctx)
+
+	return item, nil
+}
+
 // =========================================================
 // Reservation Methods - Delegate to underlying store
 // Reservations don't benefit from caching
 // =========================================================
 
-func (s *CachedStore) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (string, error) {
+func (s *CachedStore) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
 	return s.store.ReserveStock(ctx, orderID, items)
 }
 
+// ConfirmReservation decrements quantity → the cached item list is stale afterwards
 func (s *CachedStore) ConfirmReservation(ctx context.Context, orderID string) error {
-	return s.store.ConfirmReservation(ctx, orderID)
+	if err := s.store.ConfirmReservation(ctx, orderID); err != nil {
+		return err
+	}
+	s.invalidateAllItems(ctx)
+	return nil
+}
+
+func (s *CachedStore) ReleaseReservation(ctx context.Context, orderID, reservationID string) error {
+	return s.store.ReleaseReservation(ctx, orderID, reservationID)
+}
+
+// GetAvailableQuantities bypasses the cache - reserved_quantity changes with every order
+func (s *CachedStore) GetAvailableQuantities(ctx context.Context, ids []string) (map[string]int32, error) {
+	return s.store.GetAvailableQuantities(ctx, ids)
+}
+
+func (s *CachedStore) CleanupExpiredReservations(ctx context.Context) (int, error) {
+	return s.store.CleanupExpiredReservations(ctx)
+}
+
+// MarkFulfillmentStarted only touches stock_reservations → nothing cached to invalidate
+func (s *CachedStore) MarkFulfillmentStarted(ctx context.Context, orderID string) (int, error) {
+	return s.store.MarkFulfillmentStarted(ctx, orderID)
+}
+
+// GetReservation bypasses the cache - support needs the live reservation status
+func (s *CachedStore) GetReservation(ctx context.Context, orderID string) ([]ReservationLine, error) {
+	return s.store.GetReservation(ctx, orderID)
 }
 
-func (s *CachedStore) ReleaseReservation(ctx context.Context, orderID string) error {
-	return s.store.ReleaseReservation(ctx, orderID)
+func (s *CachedStore) RenewReservation(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
+	return s.store.RenewReservation(ctx, orderID, items)
+}
+
+// RestockItems updates PostgreSQL and invalidates the cache of every restocked item
+func (s *CachedStore) RestockItems(ctx context.Context, orderID string, items []*pb.ItemsWithQuantity) error {
+	if err := s.store.RestockItems(ctx, orderID, items); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := s.cache.InvalidateItem(ctx, item.ID); err != nil {
+			log.Printf("⚠️  Failed to invalidate cache for item %s: %v", item.ID, err)
+		}
+	}
+	s.invalidateAllItems(ctx)
+
+	return nil
+}
+
+// GetInventorySummary bypasses the cache - the admin dashboard needs live reservation numbers
+func (s *CachedStore) GetInventorySummary(ctx context.Context, query InventoryQuery) ([]*pb.InventoryItem, error) {
+	return s.store.GetInventorySummary(ctx, query)
+}
+
+// BulkCreateItems: New items have no per-item cache entries, but the cached item list misses them
+func (s *CachedStore) BulkCreateItems(ctx context.Context, items []*pb.Item) error {
+	if err := s.store.BulkCreateItems(ctx, items); err != nil {
+		return err
+	}
+	s.invalidateAllItems(ctx)
+	return nil
 }
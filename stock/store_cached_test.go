@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// countingStore: Zählt wie oft GetItems bis zur DB durchkommt
+type countingStore struct {
+	StockStore
+	getItems atomic.Int32
+}
+
+func (s *countingStore) GetItems(ctx context.Context, ids []string) ([]*pb.Item, error) {
+	s.getItems.Add(1)
+	return s.StockStore.GetItems(ctx, ids)
+}
+
+// newRedisTestCache: Echtes Redis (STOCK_TEST_REDIS_ADDR, z.B. die docker-compose Instanz), sonst Skip
+func newRedisTestCache(t *testing.T) *ItemCache {
+	t.Helper()
+
+	addr := os.Getenv("STOCK_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("STOCK_TEST_REDIS_ADDR not set")
+	}
+	cache, err := NewItemCache(addr, time.Minute)
+	if err != nil {
+		t.Fatalf("NewItemCache: %v", err)
+	}
+	// Frisch starten und aufräumen → kein all_items Key aus einem anderen Lauf
+	cache.InvalidateAllItems(context.Background())
+	t.Cleanup(func() {
+		cache.InvalidateAllItems(context.Background())
+		cache.InvalidateItem(context.Background(), "1")
+		cache.Close()
+	})
+	return cache
+}
+
+func findItem(items []*pb.Item, id string) *pb.Item {
+	for _, item := range items {
+		if item.ID == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// Zweiter Menu Call kommt aus dem "all_items" Key → nur EIN DB Zugriff
+func TestCachedStoreAllItemsHit(t *testing.T) {
+	m := metrics.NewStockMetrics("stock_all_items_hit_test")
+	db := &countingStore{StockStore: NewMemoryStore(nil, 0)}
+	s := NewCachedStore(db, newRedisTestCache(t), time.Minute, m)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		items, err := s.GetItems(ctx, nil)
+		if err != nil {
+			t.Fatalf("GetItems #%d: %v", i+1, err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("GetItems #%d = %d items; want 2", i+1, len(items))
+		}
+	}
+
+	if n := db.getItems.Load(); n != 1 {
+		t.Errorf("DB GetItems calls = %d; want 1", n)
+	}
+	if got := testutil.ToFloat64(m.AllItemsCache.WithLabelValues("miss")); got != 1 {
+		t.Errorf("misses = %v; want 1", got)
+	}
+	if got := testutil.ToFloat64(m.AllItemsCache.WithLabelValues("hit")); got != 1 {
+		t.Errorf("hits = %v; want 1", got)
+	}
+}
+
+// DecrementQuantity invalidiert "all_items" → nächster Menu Call sieht die neue Menge
+func TestCachedStoreAllItemsInvalidatedOnDecrement(t *testing.T) {
+	db := &countingStore{StockStore: NewMemoryStore(nil, 0)}
+	s := NewCachedStore(db, newRedisTestCache(t), time.Minute, nil)
+	ctx := context.Background()
+
+	if _, err := s.GetItems(ctx, nil); err != nil {
+		t.Fatalf("GetItems: %v", err)
+	}
+	if err := s.DecrementQuantity(ctx, "1", 3); err != nil {
+		t.Fatalf("DecrementQuantity: %v", err)
+	}
+
+	items, err := s.GetItems(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetItems after decrement: %v", err)
+	}
+	if n := db.getItems.Load(); n != 2 {
+		t.Errorf("DB GetItems calls = %d; want 2 (cache invalidated)", n)
+	}
+	if item := findItem(items, "1"); item == nil || item.Quantity != 17 {
+		t.Fatalf("item 1 = %+v; want quantity 17", item)
+	}
+}
+
+// allItemsTTL 0 → altes Verhalten, jeder Call geht an die DB und zählt als Bypass
+func TestCachedStoreAllItemsBypass(t *testing.T) {
+	m := metrics.NewStockMetrics("stock_all_items_bypass_test")
+	db := &countingStore{StockStore: NewMemoryStore(nil, 0)}
+	s := NewCachedStore(db, nil, 0, m) // Bypass fasst den Cache nicht an
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.GetItems(ctx, nil); err != nil {
+			t.Fatalf("GetItems #%d: %v", i+1, err)
+		}
+	}
+
+	if n := db.getItems.Load(); n != 2 {
+		t.Errorf("DB GetItems calls = %d; want 2", n)
+	}
+	if got := testutil.ToFloat64(m.AllItemsCache.WithLabelValues("bypass")); got != 2 {
+		t.Errorf("bypasses = %v; want 2", got)
+	}
+}
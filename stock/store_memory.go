@@ -0,0 +1,499 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// MemoryStore: StockStore komplett im Speicher (STOCK_BACKEND=memory)
+// Warum überhaupt?
+// → Lokal ohne Postgres starten (nur Redis + RabbitMQ nötig)
+// → Gleiche Semantik wie PostgresStore (Reservierungen, Grace, Idempotenz) → Service merkt keinen Unterschied
+// Achtung: Bestand lebt pro Prozess → Neustart = Seed Daten, mehrere Replicas = getrennte Bestände
+type MemoryStore struct {
+	mu           sync.Mutex
+	stock        map[string]*pb.Item
+	reserved     map[string]int32 // item_id → reserved_quantity
+	reservations []*memoryReservation
+
+	metrics *metrics.StockMetrics
+	clock   Clock
+
+	// confirmGrace: wie PostgresStore.confirmGrace (0 = aus)
+	confirmGrace time.Duration
+}
+
+// memoryReservation: Eine Zeile aus stock_reservations (ein Item einer Order)
+type memoryReservation struct {
+	reservationID        string
+	orderID              string
+	itemID               string
+	quantity             int32
+	status               string // reserved | confirmed | released | expired
+	expiresAt            time.Time
+	createdAt            time.Time
+	fulfillmentStartedAt time.Time
+}
+
+// NewMemoryStore erstellt einen In-Memory Store mit Burger + Pommes als Seed
+// metrics darf nil sein, confirmGrace <= 0 → nur aktive Reservierungen sind bestätigbar
+func NewMemoryStore(metrics *metrics.StockMetrics, confirmGrace time.Duration) *MemoryStore {
+	return &MemoryStore{
+		stock: map[string]*pb.Item{
+			"1": {
+				ID:       "1",
+				Name:     "Burger",
+				PriceID:  "price_1SQYsL3th7a1Jo3bsOVNnRpm",
+				Quantity: 20,
+			},
+			"2": {
+				ID:       "2",
+				Name:     "Pommes",
+				PriceID:  "price_POMMES_TODO", // TODO: Erstelle price ID in Stripe für Pommes
+				Quantity: 15,
+			},
+		},
+		reserved:     make(map[string]int32),
+		metrics:      metrics,
+		clock:        realClock{},
+		confirmGrace: confirmGrace,
+	}
+}
+
+// Close: Nichts freizugeben → erfüllt das Store Interface
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// copyItem: Caller bekommen Kopien → Änderungen am Ergebnis landen nicht im Bestand
+func copyItem(item *pb.Item) *pb.Item {
+	return &pb.Item{
+		ID:          item.ID,
+		Name:        item.Name,
+		PriceID:     item.PriceID,
+		Quantity:    item.Quantity,
+		Unavailable: item.Unavailable,
+	}
+}
+
+func (s *MemoryStore) GetItem(ctx context.Context, id string) (*pb.Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.stock[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	return copyItem(item), nil
+}
+
+// GetItems: Wie PostgresStore → leere ids = ALLE Items (sortiert nach ID), unbekannte IDs fehlen im Ergebnis
+func (s *MemoryStore) GetItems(ctx context.Context, ids []string) ([]*pb.Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(ids) == 0 {
+		ids = s.sortedIDs()
+	}
+
+	var res []*pb.Item
+	for _, id := range ids {
+		if i, ok := s.stock[id]; ok {
+			res = append(res, copyItem(i))
+		}
+	}
+
+	return res, nil
+}
+
+// sortedIDs: Alle Item IDs in stabiler Reihenfolge (ORDER BY id), Caller hält s.mu
+func (s *MemoryStore) sortedIDs() []string {
+	ids := make([]string, 0, len(s.stock))
+	for id := range s.stock {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// GetAvailableQuantities: quantity - reserved_quantity, unbekannte IDs → 0
+func (s *MemoryStore) GetAvailableQuantities(ctx context.Context, ids []string) (map[string]int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	available := make(map[string]int32, len(ids))
+	for _, id := range ids {
+		available[id] = 0
+		if item, ok := s.stock[id]; ok {
+			available[id] = item.Quantity - s.reserved[id]
+		}
+	}
+
+	return available, nil
+}
+
+func (s *MemoryStore) DecrementQuantity(ctx context.Context, id string, amount int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.stock[id]
+	if !ok || item.Quantity < amount || item.Quantity-amount < s.reserved[id] {
+		return fmt.Errorf("insufficient stock or item not found")
+	}
+	item.Quantity -= amount
+
+	return nil
+}
+
+func (s *MemoryStore) SetItemAvailability(ctx context.Context, id string, available bool) (*pb.Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.stock[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	item.Unavailable = !available
+
+	return copyItem(item), nil
+}
+
+// RestockItems: Alle Items oder keins (wie die Postgres Transaktion) → erst prüfen, dann buchen
+func (s *MemoryStore) RestockItems(ctx context.Context, orderID string, items []*pb.ItemsWithQuantity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("invalid restock quantity %d for item %s", item.Quantity, item.ID)
+		}
+		if _, ok := s.stock[item.ID]; !ok {
+			return fmt.Errorf("%w: %s", ErrItemNotFound, item.ID)
+		}
+	}
+
+	for _, item := range items {
+		s.stock[item.ID].Quantity += item.Quantity
+	}
+
+	return nil
+}
+
+// ReserveStock: Alle Items oder keins → Bedarf pro Item summieren, prüfen, dann reservieren
+func (s *MemoryStore) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reserveLocked(orderID, items)
+}
+
+// reserveLocked: ReserveStock ohne Lock → RenewReservation reserviert neu, ohne s.mu loszulassen
+func (s *MemoryStore) reserveLocked(orderID string, items []*pb.Item) (Reservation, error) {
+	requested := make(map[string]int32, len(items))
+	for _, item := range items {
+		stock, ok := s.stock[item.ID]
+		if !ok {
+			return Reservation{}, fmt.Errorf("%w: %s", ErrItemNotFound, item.ID)
+		}
+		if stock.Unavailable {
+			return Reservation{}, fmt.Errorf("%w: %s", ErrItemUnavailable, item.ID)
+		}
+		requested[item.ID] += item.Quantity
+		if stock.Quantity-s.reserved[item.ID] < requested[item.ID] {
+			return Reservation{}, fmt.Errorf("%w for item %s (requested: %d)", ErrInsufficientStock, item.ID, item.Quantity)
+		}
+	}
+
+	now := s.clock.Now()
+	reservation := Reservation{ID: uuid.New().String(), ExpiresAt: now.Add(ReservationTTL)}
+	for _, item := range items {
+		s.reserved[item.ID] += item.Quantity
+		s.reservations = append(s.reservations, &memoryReservation{
+			reservationID: reservation.ID,
+			orderID:       orderID,
+			itemID:        item.ID,
+			quantity:      item.Quantity,
+			status:        "reserved",
+			expiresAt:     reservation.ExpiresAt,
+			createdAt:     now,
+		})
+	}
+
+	return reservation, nil
+}
+
+// RenewReservation: Aktive Reservierung verlängern, sonst neu reservieren (wie PostgresStore)
+func (s *MemoryStore) RenewReservation(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := s.clock.Now().Add(ReservationTTL)
+
+	var reservationID string
+	for _, r := range s.reservations {
+		if r.orderID == orderID && r.status == "reserved" {
+			r.expiresAt = expiresAt
+			reservationID = r.reservationID
+		}
+	}
+	if reservationID != "" {
+		return Reservation{ID: reservationID, ExpiresAt: expiresAt}, nil
+	}
+
+	// Reservation is gone → hold the stock again
+	return s.reserveLocked(orderID, items)
+}
+
+// ConfirmReservation: Reservierung → echter Abzug (Payment erfolgreich)
+// Grace + Idempotenz wie PostgresStore.ConfirmReservation
+// → Erst alle Zeilen prüfen, dann buchen → kein halb bestätigter Zustand
+func (s *MemoryStore) ConfirmReservation(ctx context.Context, orderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	graceCutoff := s.clock.Now().Add(-s.confirmGrace)
+
+	var confirmable, expired []*memoryReservation
+	confirmed := false
+	for _, r := range s.reservations {
+		if r.orderID != orderID {
+			continue
+		}
+		switch {
+		case r.status == "reserved":
+			confirmable = append(confirmable, r)
+		case r.status == "expired" && s.confirmGrace > 0 && !r.expiresAt.Before(graceCutoff):
+			expired = append(expired, r)
+		case r.status == "confirmed":
+			confirmed = true
+		}
+	}
+	// Expired Zeilen nur als Fallback, wenn die Order nichts Aktives/Bestätigtes hat
+	// Warum?
+	// → Nach RenewReservation liegen alte 'expired' und neue 'reserved' Zeilen nebeneinander → beide bestätigen = doppelt abgebucht
+	if len(confirmable) == 0 && !confirmed {
+		confirmable = expired
+	}
+
+	if len(confirmable) == 0 {
+		if confirmed {
+			log.Printf("Reservation for order %s already confirmed, nothing to do", orderID)
+			return nil
+		}
+		return fmt.Errorf("%w for order %s", ErrNoActiveReservation, orderID)
+	}
+
+	// quantity und reserved_quantity NACH der Bestätigung
+	// → 'expired' Zeilen haben reserved_quantity schon zurückgegeben → nur quantity sinkt
+	quantity := make(map[string]int32)
+	reserved := make(map[string]int32)
+	for _, r := range confirmable {
+		if _, ok := quantity[r.itemID]; !ok {
+			item, ok := s.stock[r.itemID]
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrItemNotFound, r.itemID)
+			}
+			quantity[r.itemID] = item.Quantity
+			reserved[r.itemID] = s.reserved[r.itemID]
+		}
+		quantity[r.itemID] -= r.quantity
+		if r.status == "reserved" {
+			reserved[r.itemID] -= r.quantity
+		}
+	}
+	for itemID := range quantity {
+		if reserved[itemID] < 0 {
+			return fmt.Errorf("reservation mismatch for item %s (possibly already confirmed or released)", itemID)
+		}
+		// Expired Einheiten inzwischen von einer anderen Order reserviert → kein Over-Selling
+		if quantity[itemID] < reserved[itemID] {
+			return fmt.Errorf("%w: item %s was re-reserved after the reservation of order %s expired", ErrInsufficientStock, itemID, orderID)
+		}
+	}
+
+	for itemID := range quantity {
+		s.stock[itemID].Quantity = quantity[itemID]
+		s.reserved[itemID] = reserved[itemID]
+	}
+	for _, r := range confirmable {
+		r.status = "confirmed"
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecordReservationConfirmed(s.clock.Now().Sub(confirmable[0].createdAt))
+	}
+
+	return nil
+}
+
+// ReleaseReservation: reserved_quantity zurückgeben (Payment fehlgeschlagen, Storno)
+// reservationID wie bei PostgresStore: "" oder veraltet → alle aktiven Reservierungen der Order
+func (s *MemoryStore) ReleaseReservation(ctx context.Context, orderID, reservationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reservationID != "" && !s.hasActiveReservation(orderID, reservationID) {
+		log.Printf("Reservation %s of order %s is not active, releasing all active reservations of the order", reservationID, orderID)
+		reservationID = ""
+	}
+
+	for _, r := range s.reservations {
+		if r.orderID != orderID || r.status != "reserved" {
+			continue
+		}
+		if reservationID != "" && r.reservationID != reservationID {
+			continue
+		}
+		s.reserved[r.itemID] -= r.quantity
+		r.status = "released"
+	}
+
+	return nil
+}
+
+// hasActiveReservation: Ist reservationID der Order noch 'reserved'? Caller hält s.mu
+func (s *MemoryStore) hasActiveReservation(orderID, reservationID string) bool {
+	for _, r := range s.reservations {
+		if r.orderID == orderID && r.reservationID == reservationID && r.status == "reserved" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetReservation: Alle Zeilen einer Order (jeder Status), sortiert nach item_id
+func (s *MemoryStore) GetReservation(ctx context.Context, orderID string) ([]ReservationLine, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lines []ReservationLine
+	for _, r := range s.reservations {
+		if r.orderID != orderID {
+			continue
+		}
+		lines = append(lines, ReservationLine{
+			ItemID:               r.itemID,
+			Quantity:             r.quantity,
+			Status:               r.status,
+			ExpiresAt:            r.expiresAt,
+			FulfillmentStartedAt: r.fulfillmentStartedAt,
+		})
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].ItemID < lines[j].ItemID })
+
+	return lines, nil
+}
+
+// MarkFulfillmentStarted: Nur bestätigte Zeilen, erster Timestamp gewinnt (Redelivery überschreibt nicht)
+func (s *MemoryStore) MarkFulfillmentStarted(ctx context.Context, orderID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	marked := 0
+	for _, r := range s.reservations {
+		if r.orderID == orderID && r.status == "confirmed" && r.fulfillmentStartedAt.IsZero() {
+			r.fulfillmentStartedAt = now
+			marked++
+		}
+	}
+
+	return marked, nil
+}
+
+// CleanupExpiredReservations: Abgelaufene 'reserved' Zeilen → 'expired', reserved_quantity zurück
+func (s *MemoryStore) CleanupExpiredReservations(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.clock.Now()
+	count := 0
+	for _, r := range s.reservations {
+		if r.status != "reserved" || !r.expiresAt.Before(cutoff) {
+			continue
+		}
+		if s.reserved[r.itemID] >= r.quantity {
+			s.reserved[r.itemID] -= r.quantity
+		}
+		r.status = "expired"
+		count++
+	}
+
+	return count, nil
+}
+
+// GetInventorySummary: Gleiche Filter + Sortierung wie PostgresStore (inventoryOrderBy = Whitelist)
+func (s *MemoryStore) GetInventorySummary(ctx context.Context, q InventoryQuery) ([]*pb.InventoryItem, error) {
+	if _, ok := inventoryOrderBy[q.SortBy]; !ok {
+		return nil, fmt.Errorf("unknown inventory sort %q", q.SortBy)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []*pb.InventoryItem
+	for _, id := range s.sortedIDs() {
+		item := s.stock[id]
+		available := item.Quantity - s.reserved[id]
+		lowStock := available <= q.LowStockThreshold
+		if q.LowStockOnly && !lowStock {
+			continue
+		}
+		items = append(items, &pb.InventoryItem{
+			ID:               item.ID,
+			Name:             item.Name,
+			Quantity:         item.Quantity,
+			ReservedQuantity: s.reserved[id],
+			Available:        available,
+			LowStock:         lowStock,
+		})
+	}
+
+	// Schon nach ID sortiert → SliceStable hält die ID als Tie-Breaker
+	switch q.SortBy {
+	case "name":
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	case "available":
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Available < items[j].Available })
+	case "available_desc":
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Available > items[j].Available })
+	}
+
+	return items, nil
+}
+
+// BulkCreateItems: Alles oder nichts, Fehler pro Zeile als *ItemRowError (wie PostgresStore)
+func (s *MemoryStore) BulkCreateItems(ctx context.Context, items []*pb.Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if _, ok := s.stock[item.ID]; ok || seen[item.ID] {
+			return &ItemRowError{Row: i, ID: item.ID, Err: ErrItemExists}
+		}
+		if item.Quantity < 0 {
+			return &ItemRowError{Row: i, ID: item.ID, Err: fmt.Errorf("%w for item %s (requested: %d)", ErrInsufficientStock, item.ID, item.Quantity)}
+		}
+		seen[item.ID] = true
+	}
+
+	for _, item := range items {
+		s.stock[item.ID] = &pb.Item{
+			ID:       item.ID,
+			Name:     item.Name,
+			PriceID:  item.PriceID,
+			Quantity: item.Quantity,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
+)
+
+// fakeClock: Clock die nur per Advance weiterläuft → TTL/Grace ohne Sleep testen
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestMemoryStore(m *metrics.StockMetrics, confirmGrace time.Duration) (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	s := NewMemoryStore(m, confirmGrace)
+	s.clock = clock
+	return s, clock
+}
+
+func TestConfirmReservationRecordsLatency(t *testing.T) {
+	m := metrics.NewStockMetrics("stock_confirm_latency_test")
+	s, clock := newTestMemoryStore(m, 0)
+	ctx := context.Background()
+
+	if _, err := s.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 2}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	clock.Advance(42 * time.Second)
+	if err := s.ConfirmReservation(ctx, "o1"); err != nil {
+		t.Fatalf("ConfirmReservation: %v", err)
+	}
+
+	if n := testutil.CollectAndCount(m.ReservationConfirmLatency); n != 1 {
+		t.Fatalf("histogram series = %d; want 1", n)
+	}
+	want := `
+# HELP stock_confirm_latency_test_reservation_confirm_latency_seconds Time between reserving stock (order created) and confirming it (payment completed)
+# TYPE stock_confirm_latency_test_reservation_confirm_latency_seconds histogram
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="5"} 0
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="15"} 0
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="30"} 0
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="60"} 1
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="120"} 1
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="300"} 1
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="600"} 1
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="900"} 1
+stock_confirm_latency_test_reservation_confirm_latency_seconds_bucket{le="+Inf"} 1
+stock_confirm_latency_test_reservation_confirm_latency_seconds_sum 42
+stock_confirm_latency_test_reservation_confirm_latency_seconds_count 1
+`
+	if err := testutil.CollectAndCompare(m.ReservationConfirmLatency, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Redelivery von order.paid → schon bestätigt, KEINE zweite Beobachtung
+	if err := s.ConfirmReservation(ctx, "o1"); err != nil {
+		t.Fatalf("second ConfirmReservation: %v", err)
+	}
+	if err := testutil.CollectAndCompare(m.ReservationConfirmLatency, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Reduzierte Order Menge → genau die entfernte Menge kommt zurück in den Bestand
+func TestReserveStockUnknownVsInsufficient(t *testing.T) {
+	s, _ := newTestMemoryStore(nil, 0)
+	ctx := context.Background()
+
+	_, err := s.ReserveStock(ctx, "o1", []*pb.Item{{ID: "does-not-exist", Quantity: 1}})
+	if !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("unknown item: ReserveStock = %v; want ErrItemNotFound", err)
+	}
+
+	// Burger: 20 auf Lager
+	_, err = s.ReserveStock(ctx, "o2", []*pb.Item{{ID: "1", Quantity: 21}})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("short item: ReserveStock = %v; want ErrInsufficientStock", err)
+	}
+}
+
+func TestRestockItems(t *testing.T) {
+	s, _ := newTestMemoryStore(nil, 0)
+	ctx := context.Background()
+
+	if err := s.RestockItems(ctx, "o1", []*pb.ItemsWithQuantity{{ID: "1", Quantity: 2}, {ID: "2", Quantity: 1}}); err != nil {
+		t.Fatalf("RestockItems: %v", err)
+	}
+	available, _ := s.GetAvailableQuantities(ctx, []string{"1", "2"})
+	if available["1"] != 22 || available["2"] != 16 {
+		t.Fatalf("available = %v; want 1:22 2:16", available)
+	}
+
+	// Alles oder nichts: unbekanntes Item → auch Item 1 wird nicht aufgestockt
+	if err := s.RestockItems(ctx, "o2", []*pb.ItemsWithQuantity{{ID: "1", Quantity: 1}, {ID: "9", Quantity: 1}}); err == nil {
+		t.Fatal("RestockItems accepted an unknown item")
+	}
+	if available, _ := s.GetAvailableQuantities(ctx, []string{"1"}); available["1"] != 22 {
+		t.Fatalf("available burger = %d after failed restock; want 22", available["1"])
+	}
+}
+
+// Cleanup räumt genau die Reservations ab, deren expires_at vor der Fake Clock liegt
+func TestCleanupExpiredReservationsUsesClock(t *testing.T) {
+	s, clock := newTestMemoryStore(nil, 0)
+	ctx := context.Background()
+
+	if _, err := s.ReserveStock(ctx, "early", []*pb.Item{{ID: "1", Quantity: 2}}); err != nil {
+		t.Fatalf("ReserveStock early: %v", err)
+	}
+	clock.Advance(ReservationTTL / 2)
+	if _, err := s.ReserveStock(ctx, "late", []*pb.Item{{ID: "2", Quantity: 1}}); err != nil {
+		t.Fatalf("ReserveStock late: %v", err)
+	}
+
+	// Genau auf expires_at → noch nicht abgelaufen (expires_at < cutoff)
+	clock.Advance(ReservationTTL / 2)
+	if n, _ := s.CleanupExpiredReservations(ctx); n != 0 {
+		t.Fatalf("cleaned at expiry = %d; want 0", n)
+	}
+
+	clock.Advance(time.Second)
+	n, err := s.CleanupExpiredReservations(ctx)
+	if err != nil {
+		t.Fatalf("CleanupExpiredReservations: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("cleaned = %d; want 1", n)
+	}
+
+	for orderID, want := range map[string]string{"early": "expired", "late": "reserved"} {
+		lines, _ := s.GetReservation(ctx, orderID)
+		if len(lines) != 1 || lines[0].Status != want {
+			t.Errorf("%s lines = %+v; want one %s line", orderID, lines, want)
+		}
+	}
+	available, _ := s.GetAvailableQuantities(ctx, []string{"1", "2"})
+	if available["1"] != 20 || available["2"] != 14 {
+		t.Errorf("available = %v; want 1:20 (released) 2:14 (still reserved)", available)
+	}
+}
+
+// order.cancelled doppelt zugestellt → zweites Release ist ein No-Op, Bestand wird nur EINMAL freigegeben
+func TestReleaseReservationTwice(t *testing.T) {
+	s, _ := newTestMemoryStore(nil, 0)
+	ctx := context.Background()
+
+	if _, err := s.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 5}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := s.ReleaseReservation(ctx, "o1", ""); err != nil {
+			t.Fatalf("ReleaseReservation #%d: %v", i, err)
+		}
+	}
+
+	available, err := s.GetAvailableQuantities(ctx, []string{"1"})
+	if err != nil {
+		t.Fatalf("GetAvailableQuantities: %v", err)
+	}
+	if available["1"] != 20 {
+		t.Fatalf("available = %d; want 20 (released exactly once)", available["1"])
+	}
+}
+
+// reserveAndExpire: o1 reserviert qty Burger, Clock läuft um pastExpiry hinter expires_at, Cleanup markiert 'expired'
+func reserveAndExpire(t *testing.T, s *MemoryStore, clock *fakeClock, qty int32, pastExpiry time.Duration) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := s.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: qty}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	clock.Advance(ReservationTTL + pastExpiry)
+	if n, err := s.CleanupExpiredReservations(ctx); err != nil || n != 1 {
+		t.Fatalf("CleanupExpiredReservations = %d, %v; want 1 expired", n, err)
+	}
+}
+
+func burgerStock(t *testing.T, s *MemoryStore) (quantity, available int32) {
+	t.Helper()
+	items, err := s.GetItems(context.Background(), []string{"1"})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("GetItems = %v, %v", items, err)
+	}
+	avail, err := s.GetAvailableQuantities(context.Background(), []string{"1"})
+	if err != nil {
+		t.Fatalf("GetAvailableQuantities: %v", err)
+	}
+	return items[0].Quantity, avail["1"]
+}
+
+// order.paid kommt kurz nach dem Ablauf → innerhalb der Grace trotzdem bestätigt, außerhalb nicht
+func TestConfirmReservationGraceWindow(t *testing.T) {
+	tests := []struct {
+		name         string
+		grace        time.Duration
+		pastExpiry   time.Duration
+		wantErr      error
+		wantQuantity int32
+	}{
+		{"within grace", 2 * time.Minute, 30 * time.Second, nil, 15},
+		{"outside grace", 2 * time.Minute, 3 * time.Minute, ErrNoActiveReservation, 20},
+		{"grace disabled", 0, time.Second, ErrNoActiveReservation, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, clock := newTestMemoryStore(nil, tt.grace)
+			reserveAndExpire(t, s, clock, 5, tt.pastExpiry)
+
+			err := s.ConfirmReservation(context.Background(), "o1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmReservation = %v; want %v", err, tt.wantErr)
+			}
+
+			quantity, available := burgerStock(t, s)
+			if quantity != tt.wantQuantity || available != tt.wantQuantity {
+				t.Fatalf("quantity/available = %d/%d; want %d/%d", quantity, available, tt.wantQuantity, tt.wantQuantity)
+			}
+		})
+	}
+}
+
+// Abgelaufene Einheiten inzwischen an o2 vergeben → Grace Confirm darf NICHT über-verkaufen
+func TestConfirmReservationGraceDoesNotOversell(t *testing.T) {
+	s, clock := newTestMemoryStore(nil, 2*time.Minute)
+	reserveAndExpire(t, s, clock, 5, 30*time.Second)
+	ctx := context.Background()
+
+	// 20 Burger, 0 reserviert → o2 nimmt 18 → nur noch 2 frei, o1 bräuchte 5
+	if _, err := s.ReserveStock(ctx, "o2", []*pb.Item{{ID: "1", Quantity: 18}}); err != nil {
+		t.Fatalf("ReserveStock o2: %v", err)
+	}
+
+	if err := s.ConfirmReservation(ctx, "o1"); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("ConfirmReservation o1 = %v; want ErrInsufficientStock", err)
+	}
+	if quantity, available := burgerStock(t, s); quantity != 20 || available != 2 {
+		t.Fatalf("quantity/available = %d/%d; want 20/2 (unchanged)", quantity, available)
+	}
+
+	// o2 bekommt seine Einheiten weiterhin
+	if err := s.ConfirmReservation(ctx, "o2"); err != nil {
+		t.Fatalf("ConfirmReservation o2: %v", err)
+	}
+}
+
+// Abgelaufen → erneuert → bestätigt: nur die neue Reservation wird abgebucht, nicht zusätzlich die alte (20 - 2 = 18)
+func TestConfirmReservationAfterRenewConfirmsOnce(t *testing.T) {
+	s, clock := newTestMemoryStore(nil, 2*time.Minute)
+	reserveAndExpire(t, s, clock, 2, 30*time.Second)
+	ctx := context.Background()
+
+	if _, err := s.RenewReservation(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 2}}); err != nil {
+		t.Fatalf("RenewReservation: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.ConfirmReservation(ctx, "o1"); err != nil {
+			t.Fatalf("ConfirmReservation #%d: %v", i+1, err)
+		}
+		if quantity, available := burgerStock(t, s); quantity != 18 || available != 18 {
+			t.Fatalf("after confirm #%d quantity/available = %d/%d; want 18/18", i+1, quantity, available)
+		}
+	}
+}
+
+// Release mit Reservation ID → nur DIESE Reservation; unbekannte/abgelaufene ID → alle der Order
+func TestReleaseReservationByID(t *testing.T) {
+	s, _ := newTestMemoryStore(nil, 0)
+	ctx := context.Background()
+
+	first, err := s.ReserveStock(ctx, "o1", []*pb.Item{{ID: "1", Quantity: 2}})
+	if err != nil {
+		t.Fatalf("ReserveStock first: %v", err)
+	}
+	second, err := s.ReserveStock(ctx, "o1", []*pb.Item{{ID: "2", Quantity: 1}})
+	if err != nil {
+		t.Fatalf("ReserveStock second: %v", err)
+	}
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("reservation ids = %q, %q; want two distinct ids", first.ID, second.ID)
+	}
+
+	if err := s.ReleaseReservation(ctx, "o1", first.ID); err != nil {
+		t.Fatalf("ReleaseReservation: %v", err)
+	}
+	available, _ := s.GetAvailableQuantities(ctx, []string{"1", "2"})
+	if available["1"] != 20 || available["2"] != 14 {
+		t.Fatalf("available = %v; want 1:20 (released) 2:14 (second reservation untouched)", available)
+	}
+
+	if err := s.ReleaseReservation(ctx, "o1", first.ID); err != nil {
+		t.Fatalf("ReleaseReservation stale id: %v", err)
+	}
+	available, _ = s.GetAvailableQuantities(ctx, []string{"1", "2"})
+	if available["1"] != 20 || available["2"] != 15 {
+		t.Fatalf("available after stale id = %v; want everything released", available)
+	}
+}
@@ -3,19 +3,103 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/lib/pq"
 	pb "github.com/timour/order-microservices/common/api"
+	"github.com/timour/order-microservices/common/metrics"
 )
 
+// Clock liefert die aktuelle Zeit
+// Warum Interface statt time.Now()?
+// → Reservation TTL / Cleanup hängen an der Zeit
+// → Tests setzen eine Fake Clock und "spulen vor" statt 15 Minuten zu schlafen
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock: Default Clock → echte Systemzeit
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// ErrInsufficientStock: Nicht genug (verfügbarer) Bestand für die angefragte Menge
+// → Caller prüfen mit errors.Is statt Fehlertext zu parsen
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ErrItemNotFound: Item existiert nicht im Katalog (Client Fehler, kein Bestandsproblem)
+var ErrItemNotFound = errors.New("item not found")
+
+// ErrItemUnavailable: Item ist vorübergehend deaktiviert (is_available = false) → nicht reservierbar, egal wie viel Bestand
+var ErrItemUnavailable = errors.New("item unavailable")
+
+// ErrNoActiveReservation: Order hat keine bestätigbare Reservierung (nie reserviert, freigegeben oder zu lange abgelaufen)
+var ErrNoActiveReservation = errors.New("no active reservation")
+
+// ErrReservationConflict: Parallele Reservierung derselben Items (Deadlock/Serialization) → Caller soll es nochmal versuchen
+var ErrReservationConflict = errors.New("concurrent stock update")
+
+// ErrItemExists: Item ID ist schon im Katalog (Bulk Import)
+var ErrItemExists = errors.New("item already exists")
+
+// ItemRowError: Fehler einer bestimmten Zeile eines Bulk Imports
+type ItemRowError struct {
+	Row int // 0-basierter Index im Import
+	ID  string
+	Err error
+}
+
+func (e *ItemRowError) Error() string {
+	return fmt.Sprintf("row %d (item %q): %v", e.Row, e.ID, e.Err)
+}
+
+func (e *ItemRowError) Unwrap() error { return e.Err }
+
+// Postgres SQLSTATEs
+const (
+	pqCheckViolation  = "23514" // verletzter CHECK Constraint
+	pqUniqueViolation = "23505" // doppelter Primary Key / UNIQUE
+
+	pqSerializationFailure = "40001" // Transaktion kollidiert mit einer parallelen
+	pqDeadlockDetected     = "40P01" // Zwei Reservierungen sperren dieselben Items in anderer Reihenfolge
+)
+
+// stockError: Übersetzt verletzte CHECK Constraints in ErrInsufficientStock
+// Warum?
+// → Die WHERE Guards (quantity >= $1) sind die erste Verteidigung
+// → Greift stattdessen der CHECK (quantity >= 0, reserved_quantity <= quantity), kam bisher ein roher pq Error raus
+// → Für den Caller ist es derselbe Fall: Bestand reicht nicht
+// Deadlock/Serialization → ErrReservationConflict: Postgres hat die Transaktion abgebrochen, ein Retry kann klappen
+func stockError(err error, itemID string, quantity int32) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case pqCheckViolation:
+		return fmt.Errorf("%w for item %s (requested: %d, constraint: %s)", ErrInsufficientStock, itemID, quantity, pqErr.Constraint)
+	case pqSerializationFailure, pqDeadlockDetected:
+		return fmt.Errorf("%w for item %s: %v", ErrReservationConflict, itemID, err)
+	}
+	return err
+}
+
 // PostgresStore implementiert Store Interface mit PostgreSQL
 type PostgresStore struct {
-	db *sql.DB
+	db      *sql.DB
+	metrics *metrics.StockMetrics
+	clock   Clock
+
+	// confirmGrace: 'expired' Reservierungen dürfen so lange nach expires_at noch bestätigt werden (0 = aus)
+	confirmGrace time.Duration
 }
 
 // NewPostgresStore erstellt eine neue PostgreSQL Store Instanz
-func NewPostgresStore(connectionString string) (*PostgresStore, error) {
+// metrics darf nil sein → dann werden keine Reservation-Metriken aufgezeichnet
+// confirmGrace <= 0 → ConfirmReservation bestätigt nur aktive ('reserved') Reservierungen
+func NewPostgresStore(connectionString string, metrics *metrics.StockMetrics, confirmGrace time.Duration) (*PostgresStore, error) {
 	db, err := sql.Open("postgres", connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -26,7 +110,13 @@ func NewPostgresStore(connectionString string) (*PostgresStore, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PostgresStore{db: db}, nil
+	// ⭐ Schema anlegen/aktualisieren (idempotent, versioniert via schema_migrations)
+	if _, err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return &PostgresStore{db: db, metrics: metrics, clock: realClock{}, confirmGrace: confirmGrace}, nil
 }
 
 // Close schließt die Datenbankverbindung
@@ -38,20 +128,23 @@ func (s *PostgresStore) Close() error {
 func (s *PostgresStore) GetItem(ctx context.Context, id string) (*pb.Item, error) {
 	var item pb.Item
 
-	query := `SELECT id, name, price_id, quantity FROM items WHERE id = $1`
+	var available bool
+	query := `SELECT id, name, price_id, quantity, is_available FROM items WHERE id = $1`
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&item.ID,
 		&item.Name,
 		&item.PriceID,
 		&item.Quantity,
+		&available,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("item not found")
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get item: %w", err)
 	}
+	item.Unavailable = !available
 
 	return &item, nil
 }
@@ -64,11 +157,11 @@ func (s *PostgresStore) GetItems(ctx context.Context, ids []string) ([]*pb.Item,
 
 	// If no IDs specified, return ALL items
 	if len(ids) == 0 {
-		query := `SELECT id, name, price_id, quantity FROM items ORDER BY id`
+		query := `SELECT id, name, price_id, quantity, is_available FROM items ORDER BY id`
 		rows, err = s.db.QueryContext(ctx, query)
 	} else {
 		// Build query with placeholders for specific IDs
-		query := `SELECT id, name, price_id, quantity FROM items WHERE id = ANY($1)`
+		query := `SELECT id, name, price_id, quantity, is_available FROM items WHERE id = ANY($1)`
 		rows, err = s.db.QueryContext(ctx, query, pq.Array(ids))
 	}
 
@@ -80,9 +173,11 @@ func (s *PostgresStore) GetItems(ctx context.Context, ids []string) ([]*pb.Item,
 	var items []*pb.Item
 	for rows.Next() {
 		var item pb.Item
-		if err := rows.Scan(&item.ID, &item.Name, &item.PriceID, &item.Quantity); err != nil {
+		var available bool
+		if err := rows.Scan(&item.ID, &item.Name, &item.PriceID, &item.Quantity, &available); err != nil {
 			return nil, fmt.Errorf("failed to scan item: %w", err)
 		}
+		item.Unavailable = !available
 		items = append(items, &item)
 	}
 
@@ -93,12 +188,40 @@ func (s *PostgresStore) GetItems(ctx context.Context, ids []string) ([]*pb.Item,
 	return items, nil
 }
 
+// SetItemAvailability nimmt ein Item aus dem Verkauf (available=false) oder gibt es wieder frei
+// → quantity/reserved_quantity bleiben unverändert, offene Reservierungen laufen normal weiter
+func (s *PostgresStore) SetItemAvailability(ctx context.Context, id string, available bool) (*pb.Item, error) {
+	var item pb.Item
+	query := `
+		UPDATE items
+		SET is_available = $1,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING id, name, price_id, quantity
+	`
+	err := s.db.QueryRowContext(ctx, query, available, id).Scan(
+		&item.ID,
+		&item.Name,
+		&item.PriceID,
+		&item.Quantity,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set item availability: %w", err)
+	}
+	item.Unavailable = !available
+
+	return &item, nil
+}
+
 // UpdateQuantity aktualisiert die Quantity eines Items (für spätere Features)
 func (s *PostgresStore) UpdateQuantity(ctx context.Context, id string, quantity int32) error {
 	query := `UPDATE items SET quantity = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
 	result, err := s.db.ExecContext(ctx, query, quantity, id)
 	if err != nil {
-		return fmt.Errorf("failed to update quantity: %w", err)
+		return fmt.Errorf("failed to update quantity: %w", stockError(err, id, quantity))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -107,7 +230,49 @@ func (s *PostgresStore) UpdateQuantity(ctx context.Context, id string, quantity
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("item not found")
+		return fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+
+	return nil
+}
+
+// RestockItems bucht bereits abgebuchten Stock zurück (Order nachträglich reduziert)
+// Warum Transaktion?
+// → Alle Items oder keins: Halb zurückgebuchte Orders wären nicht nachvollziehbar
+func (s *PostgresStore) RestockItems(ctx context.Context, orderID string, items []*pb.ItemsWithQuantity) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE items
+		SET quantity = quantity + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("invalid restock quantity %d for item %s", item.Quantity, item.ID)
+		}
+
+		result, err := tx.ExecContext(ctx, query, item.Quantity, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to restock item %s: %w", item.ID, err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("%w: %s", ErrItemNotFound, item.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit restock transaction for order %s: %w", orderID, err)
 	}
 
 	return nil
@@ -122,7 +287,7 @@ func (s *PostgresStore) DecrementQuantity(ctx context.Context, id string, amount
 	`
 	result, err := s.db.ExecContext(ctx, query, amount, id)
 	if err != nil {
-		return fmt.Errorf("failed to decrement quantity: %w", err)
+		return fmt.Errorf("failed to decrement quantity: %w", stockError(err, id, amount))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -136,3 +301,86 @@ func (s *PostgresStore) DecrementQuantity(ctx context.Context, id string, amount
 
 	return nil
 }
+
+// inventoryOrderBy: SortBy → ORDER BY Klausel (Whitelist, nie Client-Strings in SQL)
+// → id als Tie-Breaker → stabile Reihenfolge bei gleicher Verfügbarkeit/gleichem Namen
+var inventoryOrderBy = map[string]string{
+	"":               "id",
+	"id":             "id",
+	"name":           "name, id",
+	"available":      "available_quantity ASC, id",
+	"available_desc": "available_quantity DESC, id",
+}
+
+// GetInventorySummary liefert Bestand, Reservierungen und Verfügbarkeit aller Items in EINER Query
+// Warum kein Cache?
+// → Admin Dashboard will den echten Stand, Reservierungen ändern sich ständig
+// → Filter + Sortierung in SQL statt im Handler → Postgres nutzt Indizes, nichts wird unnötig gescannt
+func (s *PostgresStore) GetInventorySummary(ctx context.Context, q InventoryQuery) ([]*pb.InventoryItem, error) {
+	orderBy, ok := inventoryOrderBy[q.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown inventory sort %q", q.SortBy)
+	}
+
+	// $2 = LowStockOnly → Bedingung ist Teil der Query, kein String-Bauen für den Filter
+	query := `
+		SELECT id, name, quantity, reserved_quantity, available_quantity,
+		       available_quantity <= $1 AS low_stock
+		FROM items
+		WHERE NOT $2 OR available_quantity <= $1
+		ORDER BY ` + orderBy
+	rows, err := s.db.QueryContext(ctx, query, q.LowStockThreshold, q.LowStockOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inventory summary: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*pb.InventoryItem
+	for rows.Next() {
+		var item pb.InventoryItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.Quantity,
+			&item.ReservedQuantity,
+			&item.Available,
+			&item.LowStock,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan inventory item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read inventory summary: %w", err)
+	}
+
+	return items, nil
+}
+
+// BulkCreateItems legt alle Items in EINER Transaktion an (alles oder nichts)
+// Returns: *ItemRowError wenn eine Zeile am Schema scheitert (z.B. ID existiert schon)
+func (s *PostgresStore) BulkCreateItems(ctx context.Context, items []*pb.Item) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO items (id, name, price_id, quantity) VALUES ($1, $2, $3, $4)`
+	for i, item := range items {
+		if _, err := tx.ExecContext(ctx, query, item.ID, item.Name, item.PriceID, item.Quantity); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+				return &ItemRowError{Row: i, ID: item.ID, Err: ErrItemExists}
+			}
+			return &ItemRowError{Row: i, ID: item.ID, Err: stockError(err, item.ID, item.Quantity)}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit item import: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+func TestStockErrorMapsCheckViolation(t *testing.T) {
+	checkErr := &pq.Error{Code: pqCheckViolation, Constraint: "items_reserved_check"}
+
+	err := stockError(fmt.Errorf("exec: %w", checkErr), "1", 5)
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("stockError = %v; want ErrInsufficientStock", err)
+	}
+
+	// Andere Postgres Fehler (hier: unique_violation) bleiben wie sie sind
+	other := &pq.Error{Code: "23505"}
+	if err := stockError(other, "1", 5); err != other {
+		t.Fatalf("stockError(unique violation) = %v; want the original error", err)
+	}
+	if err := stockError(nil, "1", 5); err != nil {
+		t.Fatalf("stockError(nil) = %v; want nil", err)
+	}
+}
+
+// Deadlock/Serialization Failure → ErrReservationConflict (gRPC Aborted, Orders: "please retry")
+func TestStockErrorMapsConflicts(t *testing.T) {
+	for _, code := range []pq.ErrorCode{pqSerializationFailure, pqDeadlockDetected} {
+		err := stockError(fmt.Errorf("exec: %w", &pq.Error{Code: code}), "1", 5)
+		if !errors.Is(err, ErrReservationConflict) {
+			t.Errorf("stockError(%s) = %v; want ErrReservationConflict", code, err)
+		}
+	}
+}
+
+// Echter CHECK aus den Migrationen (quantity >= 0) → ErrInsufficientStock statt rohem pq Error
+func TestPostgresCheckViolationIsInsufficientStock(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 1)
+
+	// Ohne WHERE Guard → nur der CHECK Constraint hält das Update auf
+	_, err := store.db.ExecContext(context.Background(),
+		`UPDATE items SET quantity = quantity - $1 WHERE id = $2`, 101, ids[0])
+	if err == nil {
+		t.Fatal("update below zero succeeded; CHECK constraint missing")
+	}
+
+	if mapped := stockError(err, ids[0], 101); !errors.Is(mapped, ErrInsufficientStock) {
+		t.Fatalf("stockError = %v; want ErrInsufficientStock", mapped)
+	}
+}
+
+// Doppelte ID in Zeile 1 → Transaktion rollt auch Zeile 0 zurück
+func TestPostgresBulkCreateItemsRollsBack(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 1)
+	ctx := context.Background()
+	fresh := ids[0] + "-new"
+
+	err := store.BulkCreateItems(ctx, []*pb.Item{
+		{ID: fresh, Name: "Cola", PriceID: "price_test", Quantity: 5},
+		{ID: ids[0], Name: "Duplicate", PriceID: "price_test", Quantity: 5},
+	})
+	var rowErr *ItemRowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 1 || !errors.Is(err, ErrItemExists) {
+		t.Fatalf("BulkCreateItems = %v; want ItemRowError for row 1 wrapping ErrItemExists", err)
+	}
+	if _, err := store.GetItem(ctx, fresh); !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("GetItem(%s) = %v; want ErrItemNotFound (batch rolled back)", fresh, err)
+	}
+}
@@ -4,29 +4,36 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	pb "github.com/timour/order-microservices/common/api"
 )
 
 // ReservationTTL defines how long a reservation stays active before expiring
 const ReservationTTL = 15 * time.Minute
 
+// DefaultConfirmGrace: How long past expires_at an 'expired' reservation may still be confirmed
+// Covers the race "payment succeeded seconds before expiry, order.paid arrives after the cleanup job"
+const DefaultConfirmGrace = 2 * time.Minute
+
 // =====================================================
 // Inventory Reservation Methods
 // =====================================================
 
 // GetAvailableQuantity returns the available stock for an item
 // Available = Total Quantity - Reserved Quantity
+// → Generated Column available_quantity (Migration 003), von Postgres bei jedem UPDATE gepflegt
 func (s *PostgresStore) GetAvailableQuantity(ctx context.Context, itemID string) (int32, error) {
 	var availableQuantity int32
 
-	query := `SELECT (quantity - reserved_quantity) AS available FROM items WHERE id = $1`
+	query := `SELECT available_quantity FROM items WHERE id = $1`
 	err := s.db.QueryRowContext(ctx, query, itemID).Scan(&availableQuantity)
 
 	if err == sql.ErrNoRows {
-		return 0, fmt.Errorf("item not found: %s", itemID)
+		return 0, fmt.Errorf("%w: %s", ErrItemNotFound, itemID)
 	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to get available quantity: %w", err)
@@ -35,25 +42,60 @@ func (s *PostgresStore) GetAvailableQuantity(ctx context.Context, itemID string)
 	return availableQuantity, nil
 }
 
+// GetAvailableQuantities returns the available stock for several items in ONE query
+// → A cart of 20 items = 1 round trip instead of 20 (GetAvailableQuantity per item)
+// → IDs that don't exist are reported as 0 available
+func (s *PostgresStore) GetAvailableQuantities(ctx context.Context, ids []string) (map[string]int32, error) {
+	available := make(map[string]int32, len(ids))
+	for _, id := range ids {
+		available[id] = 0
+	}
+	if len(ids) == 0 {
+		return available, nil
+	}
+
+	query := `SELECT id, quantity - reserved_quantity FROM items WHERE id = ANY($1)`
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available quantities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var quantity int32
+		if err := rows.Scan(&id, &quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan available quantity: %w", err)
+		}
+		available[id] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return available, nil
+}
+
 // ReserveStock creates a reservation for multiple items (ACID transaction)
 // This is called when an order is created (BEFORE payment)
 //
 // Flow:
-// 1. Check if enough stock is available (quantity - reserved_quantity >= requested)
+// 1. Check if the item is available and enough stock is left (quantity - reserved_quantity >= requested)
 // 2. Increment reserved_quantity for each item
 // 3. Insert reservation records into stock_reservations table
 // 4. Set expiration time (NOW + 15 minutes)
 //
-// Returns: reservation_id (UUID) for tracking
-func (s *PostgresStore) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (string, error) {
+// Returns: reservation_id (UUID) for tracking + expires_at
+func (s *PostgresStore) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
 	// Generate unique reservation ID
 	reservationID := uuid.New().String()
-	expiresAt := time.Now().Add(ReservationTTL)
+	expiresAt := s.clock.Now().Add(ReservationTTL)
 
 	// Start transaction
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to begin transaction: %w", err)
+		return Reservation{}, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
@@ -65,21 +107,35 @@ func (s *PostgresStore) ReserveStock(ctx context.Context, orderID string, items
 			SET reserved_quantity = reserved_quantity + $1,
 			    updated_at = CURRENT_TIMESTAMP
 			WHERE id = $2
+			  AND is_available
 			  AND (quantity - reserved_quantity) >= $1
 		`
 		result, err := tx.ExecContext(ctx, query, item.Quantity, item.ID)
 		if err != nil {
-			return "", fmt.Errorf("failed to reserve stock for item %s: %w", item.ID, err)
+			return Reservation{}, fmt.Errorf("failed to reserve stock for item %s: %w", item.ID, stockError(err, item.ID, item.Quantity))
 		}
 
 		rowsAffected, err := result.RowsAffected()
 		if err != nil {
-			return "", fmt.Errorf("failed to get rows affected: %w", err)
+			return Reservation{}, fmt.Errorf("failed to get rows affected: %w", err)
 		}
 
-		// If no rows were updated, insufficient stock
+		// No rows updated → unknown item, deactivated item OR not enough stock
+		// Warum nachträglich prüfen statt vorher?
+		// → Happy Path bleibt EINE Query, der Existenz-Check läuft nur im Fehlerfall
 		if rowsAffected == 0 {
-			return "", fmt.Errorf("insufficient stock for item %s (requested: %d)", item.ID, item.Quantity)
+			var available bool
+			err := tx.QueryRowContext(ctx, `SELECT is_available FROM items WHERE id = $1`, item.ID).Scan(&available)
+			if err == sql.ErrNoRows {
+				return Reservation{}, fmt.Errorf("%w: %s", ErrItemNotFound, item.ID)
+			}
+			if err != nil {
+				return Reservation{}, fmt.Errorf("failed to check item %s: %w", item.ID, err)
+			}
+			if !available {
+				return Reservation{}, fmt.Errorf("%w: %s", ErrItemUnavailable, item.ID)
+			}
+			return Reservation{}, fmt.Errorf("%w for item %s (requested: %d)", ErrInsufficientStock, item.ID, item.Quantity)
 		}
 
 		// 2. Insert reservation record
@@ -90,16 +146,47 @@ func (s *PostgresStore) ReserveStock(ctx context.Context, orderID string, items
 		`
 		_, err = tx.ExecContext(ctx, insertQuery, reservationID, orderID, item.ID, item.Quantity, expiresAt)
 		if err != nil {
-			return "", fmt.Errorf("failed to insert reservation for item %s: %w", item.ID, err)
+			return Reservation{}, fmt.Errorf("failed to insert reservation for item %s: %w", item.ID, err)
 		}
 	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		return "", fmt.Errorf("failed to commit reservation transaction: %w", err)
+		return Reservation{}, fmt.Errorf("failed to commit reservation transaction: %w", err)
+	}
+
+	return Reservation{ID: reservationID, ExpiresAt: expiresAt}, nil
+}
+
+// RenewReservation extends an active reservation or re-reserves the items if it already expired
+// This is called when a customer requests a fresh payment link
+//
+// Flow:
+// 1. Active ('reserved') rows for the order? → push expires_at to NOW + ReservationTTL
+// 2. None left (expired/released by cleanup) → ReserveStock again (may fail: insufficient stock)
+//
+// Returns: reservation_id (existing or new) + the new expires_at
+func (s *PostgresStore) RenewReservation(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
+	expiresAt := s.clock.Now().Add(ReservationTTL)
+
+	var reservationID string
+	query := `
+		UPDATE stock_reservations
+		SET expires_at = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE order_id = $1 AND status = 'reserved'
+		RETURNING reservation_id
+	`
+	err := s.db.QueryRowContext(ctx, query, orderID, expiresAt).Scan(&reservationID)
+	if err == nil {
+		return Reservation{ID: reservationID, ExpiresAt: expiresAt}, nil
+	}
+	if err != sql.ErrNoRows {
+		return Reservation{}, fmt.Errorf("failed to extend reservation: %w", err)
 	}
 
-	return reservationID, nil
+	// Reservation is gone → hold the stock again
+	return s.ReserveStock(ctx, orderID, items)
 }
 
 // ConfirmReservation converts a reservation into actual stock decrement (on payment success)
@@ -111,6 +198,16 @@ func (s *PostgresStore) ReserveStock(ctx context.Context, orderID string, items
 // 4. Mark reservations as 'confirmed'
 //
 // This is called when payment is successful
+// Records the reservation → confirmation latency (created_at of the reservation)
+//
+// Grace: 'expired' rows within confirmGrace past expires_at are confirmed too
+// → The cleanup job already gave reserved_quantity back → only quantity is decremented
+// → Only if the units are still available (quantity - reserved_quantity) → no over-selling
+// → Re-reserved by another order in the meantime → ErrInsufficientStock, nothing is confirmed
+//
+// Idempotent: the payments webhook AND the order.paid consumer both confirm
+// → FOR UPDATE: the second transaction waits, then sees no 'reserved' rows
+// → Rows already 'confirmed' → nil, quantity is only decremented once
 func (s *PostgresStore) ConfirmReservation(ctx context.Context, orderID string) error {
 	// Start transaction
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -119,38 +216,69 @@ func (s *PostgresStore) ConfirmReservation(ctx context.Context, orderID string)
 	}
 	defer tx.Rollback()
 
-	// 1. Get all reserved items for this order
+	// 1. Get (and lock) all confirmable items for this order: 'reserved' or just expired (grace)
+	// Expired nur, wenn die Order keine 'reserved'/'confirmed' Zeilen hat
+	// → nach RenewReservation würden sonst alte + neue Zeilen bestätigt (doppelt abgebucht)
+	graceEnabled := s.confirmGrace > 0
+	graceCutoff := s.clock.Now().Add(-s.confirmGrace)
 	reservationsQuery := `
-		SELECT item_id, quantity
+		SELECT item_id, quantity, status, created_at
 		FROM stock_reservations
-		WHERE order_id = $1 AND status = 'reserved'
+		WHERE order_id = $1
+		  AND (status = 'reserved' OR ($2 AND status = 'expired' AND expires_at >= $3
+		       AND NOT EXISTS (
+		           SELECT 1 FROM stock_reservations active
+		           WHERE active.order_id = $1 AND active.status IN ('reserved', 'confirmed')
+		       )))
+		ORDER BY item_id
+		FOR UPDATE
 	`
-	rows, err := tx.QueryContext(ctx, reservationsQuery, orderID)
+	rows, err := tx.QueryContext(ctx, reservationsQuery, orderID, graceEnabled, graceCutoff)
 	if err != nil {
 		return fmt.Errorf("failed to query reservations: %w", err)
 	}
 	defer rows.Close()
 
 	type reservation struct {
-		itemID   string
-		quantity int32
+		itemID    string
+		quantity  int32
+		status    string
+		createdAt time.Time
 	}
 
 	var reservations []reservation
 	for rows.Next() {
 		var r reservation
-		if err := rows.Scan(&r.itemID, &r.quantity); err != nil {
+		if err := rows.Scan(&r.itemID, &r.quantity, &r.status, &r.createdAt); err != nil {
 			return fmt.Errorf("failed to scan reservation: %w", err)
 		}
 		reservations = append(reservations, r)
 	}
 
 	if len(reservations) == 0 {
-		return fmt.Errorf("no active reservations found for order %s", orderID)
+		confirmed, err := reservationConfirmed(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if confirmed {
+			log.Printf("Reservation for order %s already confirmed, nothing to do", orderID)
+			return nil
+		}
+		return fmt.Errorf("%w for order %s", ErrNoActiveReservation, orderID)
 	}
 
+	// All rows of one order are inserted in the same transaction → same created_at
+	reservedAt := reservations[0].createdAt
+
 	// 2. Confirm each reservation
 	for _, r := range reservations {
+		if r.status == "expired" {
+			if err := confirmExpiredReservation(ctx, tx, orderID, r.itemID, r.quantity); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// Update items: decrement both quantity and reserved_quantity
 		updateItemsQuery := `
 			UPDATE items
@@ -163,7 +291,7 @@ func (s *PostgresStore) ConfirmReservation(ctx context.Context, orderID string)
 		`
 		result, err := tx.ExecContext(ctx, updateItemsQuery, r.quantity, r.itemID)
 		if err != nil {
-			return fmt.Errorf("failed to confirm reservation for item %s: %w", r.itemID, err)
+			return fmt.Errorf("failed to confirm reservation for item %s: %w", r.itemID, stockError(err, r.itemID, r.quantity))
 		}
 
 		rowsAffected, err := result.RowsAffected()
@@ -181,9 +309,10 @@ func (s *PostgresStore) ConfirmReservation(ctx context.Context, orderID string)
 		UPDATE stock_reservations
 		SET status = 'confirmed',
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE order_id = $1 AND status = 'reserved'
+		WHERE order_id = $1
+		  AND (status = 'reserved' OR ($2 AND status = 'expired' AND expires_at >= $3))
 	`
-	_, err = tx.ExecContext(ctx, updateReservationsQuery, orderID)
+	_, err = tx.ExecContext(ctx, updateReservationsQuery, orderID, graceEnabled, graceCutoff)
 	if err != nil {
 		return fmt.Errorf("failed to update reservations status: %w", err)
 	}
@@ -193,6 +322,54 @@ func (s *PostgresStore) ConfirmReservation(ctx context.Context, orderID string)
 		return fmt.Errorf("failed to commit confirmation transaction: %w", err)
 	}
 
+	// Only record after commit → rolled back confirmations don't skew the funnel
+	if s.metrics != nil {
+		s.metrics.RecordReservationConfirmed(s.clock.Now().Sub(reservedAt))
+	}
+
+	return nil
+}
+
+// reservationConfirmed reports whether the order has confirmed reservation rows (confirmed by an earlier call)
+func reservationConfirmed(ctx context.Context, tx *sql.Tx, orderID string) (bool, error) {
+	var confirmed bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM stock_reservations
+			WHERE order_id = $1 AND status = 'confirmed'
+		)
+	`, orderID).Scan(&confirmed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check confirmed reservations: %w", err)
+	}
+	return confirmed, nil
+}
+
+// confirmExpiredReservation decrements quantity for a reservation the cleanup job already expired
+// reserved_quantity was given back on expiry → the units must still be available, otherwise
+// another order holds them now and confirming would over-sell
+func confirmExpiredReservation(ctx context.Context, tx *sql.Tx, orderID, itemID string, quantity int32) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE items
+		SET quantity = quantity - $1,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		  AND quantity - reserved_quantity >= $1
+	`, quantity, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm expired reservation for item %s: %w", itemID, stockError(err, itemID, quantity))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: item %s was re-reserved after the reservation of order %s expired", ErrInsufficientStock, itemID, orderID)
+	}
+
+	log.Printf("Confirmed just-expired reservation for order %s item %s (within confirm grace)", orderID, itemID)
 	return nil
 }
 
@@ -207,7 +384,15 @@ func (s *PostgresStore) ConfirmReservation(ctx context.Context, orderID string)
 // - Payment fails
 // - Order is cancelled
 // - Reservation expires (background job)
-func (s *PostgresStore) ReleaseReservation(ctx context.Context, orderID string) error {
+//
+// Idempotent: order.cancelled may be delivered twice (or two releases race)
+// → FOR UPDATE: the second transaction waits, then sees no 'reserved' rows → nil
+// → Item update hits 0 rows but the reservation is already 'released' → skip instead of erroring
+//
+// reservationID: only release this reservation ("" = every active reservation of the order)
+// → Stale ID (RenewReservation re-reserved after expiry → new ID) → falls back to the whole order
+// → Otherwise the new reservation would block the stock until its TTL runs out
+func (s *PostgresStore) ReleaseReservation(ctx context.Context, orderID, reservationID string) error {
 	// Start transaction
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -215,13 +400,33 @@ func (s *PostgresStore) ReleaseReservation(ctx context.Context, orderID string)
 	}
 	defer tx.Rollback()
 
-	// 1. Get all reserved items for this order
+	if reservationID != "" {
+		var active bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM stock_reservations
+				WHERE order_id = $1 AND reservation_id = $2 AND status = 'reserved'
+			)
+		`, orderID, reservationID).Scan(&active)
+		if err != nil {
+			return fmt.Errorf("failed to check reservation %s: %w", reservationID, err)
+		}
+		if !active {
+			log.Printf("Reservation %s of order %s is not active, releasing all active reservations of the order", reservationID, orderID)
+			reservationID = ""
+		}
+	}
+
+	// 1. Get (and lock) the reserved items for this order
 	reservationsQuery := `
 		SELECT item_id, quantity
 		FROM stock_reservations
 		WHERE order_id = $1 AND status = 'reserved'
+		  AND ($2::text = '' OR reservation_id = $2)
+		ORDER BY item_id
+		FOR UPDATE
 	`
-	rows, err := tx.QueryContext(ctx, reservationsQuery, orderID)
+	rows, err := tx.QueryContext(ctx, reservationsQuery, orderID, reservationID)
 	if err != nil {
 		return fmt.Errorf("failed to query reservations: %w", err)
 	}
@@ -266,18 +471,26 @@ func (s *PostgresStore) ReleaseReservation(ctx context.Context, orderID string)
 		}
 
 		if rowsAffected == 0 {
-			return fmt.Errorf("reservation mismatch for item %s (possibly already released)", r.itemID)
+			released, err := reservationReleased(ctx, tx, orderID, r.itemID)
+			if err != nil {
+				return err
+			}
+			if !released {
+				return fmt.Errorf("reservation mismatch for item %s", r.itemID)
+			}
+			log.Printf("Reservation for order %s item %s already released, skipping", orderID, r.itemID)
 		}
 	}
 
-	// 3. Mark all reservations as released
+	// 3. Mark the reservations as released
 	updateReservationsQuery := `
 		UPDATE stock_reservations
 		SET status = 'released',
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE order_id = $1 AND status = 'reserved'
+		  AND ($2::text = '' OR reservation_id = $2)
 	`
-	_, err = tx.ExecContext(ctx, updateReservationsQuery, orderID)
+	_, err = tx.ExecContext(ctx, updateReservationsQuery, orderID, reservationID)
 	if err != nil {
 		return fmt.Errorf("failed to update reservations status: %w", err)
 	}
@@ -290,11 +503,95 @@ func (s *PostgresStore) ReleaseReservation(ctx context.Context, orderID string)
 	return nil
 }
 
+// reservationReleased reports whether a (concurrent) release already marked the order's item as 'released'
+func reservationReleased(ctx context.Context, tx *sql.Tx, orderID, itemID string) (bool, error) {
+	var released bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM stock_reservations
+			WHERE order_id = $1 AND item_id = $2 AND status = 'released'
+		)
+	`, orderID, itemID).Scan(&released)
+	if err != nil {
+		return false, fmt.Errorf("failed to check reservation status for item %s: %w", itemID, err)
+	}
+	return released, nil
+}
+
+// GetReservation returns every reservation row of an order (any status)
+// Read-only → no transaction, used by support to debug stuck orders
+func (s *PostgresStore) GetReservation(ctx context.Context, orderID string) ([]ReservationLine, error) {
+	query := `
+		SELECT item_id, quantity, status, expires_at, fulfillment_started_at
+		FROM stock_reservations
+		WHERE order_id = $1
+		ORDER BY item_id
+	`
+	rows, err := s.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reservations: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []ReservationLine
+	for rows.Next() {
+		var line ReservationLine
+		var fulfillmentStartedAt sql.NullTime
+		if err := rows.Scan(&line.ItemID, &line.Quantity, &line.Status, &line.ExpiresAt, &fulfillmentStartedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		if fulfillmentStartedAt.Valid {
+			line.FulfillmentStartedAt = fulfillmentStartedAt.Time
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reservations: %w", err)
+	}
+
+	return lines, nil
+}
+
+// MarkFulfillmentStarted records when the kitchen started preparing an order (order.preparing)
+// Only confirmed (= paid) reservations, IS NULL keeps the FIRST timestamp → redeliveries don't overwrite it
+// Returns the number of rows that got a timestamp (0 = no confirmed reservation or already recorded)
+func (s *PostgresStore) MarkFulfillmentStarted(ctx context.Context, orderID string) (int, error) {
+	query := `
+		UPDATE stock_reservations
+		SET fulfillment_started_at = CURRENT_TIMESTAMP,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE order_id = $1
+		  AND status = 'confirmed'
+		  AND fulfillment_started_at IS NULL
+	`
+	result, err := s.db.ExecContext(ctx, query, orderID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark fulfillment started for order %s: %w", orderID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
 // CleanupExpiredReservations releases all reservations that have expired
 // This is called by a background job every minute
 //
 // Returns: number of reservations cleaned up
+//
+// Concurrency: the timer and the admin endpoint (or two replicas) may sweep at the same time
+// → FOR UPDATE: the second sweep waits, then no longer sees the rows as 'reserved'
+// → reserved_quantity is only given back once per reservation
 func (s *PostgresStore) CleanupExpiredReservations(ctx context.Context) (int, error) {
+	// Warum Cutoff in Go statt NOW() in SQL?
+	// → NOW() ist DB-Zeit → Fake Clock in Tests hätte keinen Effekt
+	// → EIN Cutoff für SELECT und UPDATE → beide sehen exakt dieselben Rows
+	cutoff := s.clock.Now()
+
 	// Start transaction
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -307,9 +604,11 @@ func (s *PostgresStore) CleanupExpiredReservations(ctx context.Context) (int, er
 		SELECT order_id, item_id, quantity
 		FROM stock_reservations
 		WHERE status = 'reserved'
-		  AND expires_at < NOW()
+		  AND expires_at < $1
+		ORDER BY order_id, item_id
+		FOR UPDATE
 	`
-	rows, err := tx.QueryContext(ctx, reservationsQuery)
+	rows, err := tx.QueryContext(ctx, reservationsQuery, cutoff)
 	if err != nil {
 		return 0, fmt.Errorf("failed to query expired reservations: %w", err)
 	}
@@ -355,9 +654,9 @@ func (s *PostgresStore) CleanupExpiredReservations(ctx context.Context) (int, er
 		SET status = 'expired',
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE status = 'reserved'
-		  AND expires_at < NOW()
+		  AND expires_at < $1
 	`
-	result, err := tx.ExecContext(ctx, updateReservationsQuery)
+	result, err := tx.ExecContext(ctx, updateReservationsQuery, cutoff)
 	if err != nil {
 		return 0, fmt.Errorf("failed to update expired reservations: %w", err)
 	}
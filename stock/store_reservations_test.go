@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+// benchCartSize: Großer Warenkorb → genau der Fall, in dem N Round Trips wehtun
+const benchCartSize = 20
+
+// newPostgresTestStore: Echte PostgreSQL (STOCK_TEST_DSN, z.B. die docker-compose DB), sonst Skip
+// Warum keine Fake DB?
+// → Gemessen werden soll der Round Trip pro Query, den gibt es nur mit echtem Netzwerk + Postgres
+// → Cleanup/TTL hängen an echtem SQL (expires_at < $1), nicht an einer Go Nachbildung
+//
+// Legt n Items mit eindeutigem Prefix an und räumt sie (+ ihre Reservations) danach wieder weg
+func newPostgresTestStore(tb testing.TB, n int) (*PostgresStore, []string) {
+	tb.Helper()
+
+	dsn := os.Getenv("STOCK_TEST_DSN")
+	if dsn == "" {
+		tb.Skip("STOCK_TEST_DSN not set")
+	}
+	store, err := NewPostgresStore(dsn, nil, 0)
+	if err != nil {
+		tb.Fatalf("NewPostgresStore: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test-%d-", time.Now().UnixNano())
+	items := make([]*pb.Item, n)
+	ids := make([]string, n)
+	for i := range items {
+		ids[i] = fmt.Sprintf("%s%d", prefix, i)
+		items[i] = &pb.Item{ID: ids[i], Name: ids[i], PriceID: "price_test", Quantity: 100}
+	}
+	if err := store.BulkCreateItems(context.Background(), items); err != nil {
+		store.Close()
+		tb.Fatalf("BulkCreateItems: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		store.db.Exec(`DELETE FROM stock_reservations WHERE item_id LIKE $1`, prefix+"%")
+		store.db.Exec(`DELETE FROM items WHERE id LIKE $1`, prefix+"%")
+		store.Close()
+	})
+	return store, ids
+}
+
+// Cleanup Cutoff kommt aus der Clock, nicht aus NOW()
+// → Fake Clock vorspulen reicht, um genau die Reservations hinter "jetzt" abzuräumen
+func TestPostgresCleanupExpiredReservationsUsesClock(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 2)
+	clock := &fakeClock{now: time.Now()}
+	store.clock = clock
+	ctx := context.Background()
+
+	early, late := ids[0]+"-order", ids[1]+"-order"
+	if _, err := store.ReserveStock(ctx, early, []*pb.Item{{ID: ids[0], Quantity: 1}}); err != nil {
+		t.Fatalf("ReserveStock early: %v", err)
+	}
+	clock.Advance(ReservationTTL / 2)
+	if _, err := store.ReserveStock(ctx, late, []*pb.Item{{ID: ids[1], Quantity: 1}}); err != nil {
+		t.Fatalf("ReserveStock late: %v", err)
+	}
+
+	// Nur "early" ist abgelaufen, "late" hat noch die halbe TTL
+	clock.Advance(ReservationTTL/2 + time.Second)
+	if _, err := store.CleanupExpiredReservations(ctx); err != nil {
+		t.Fatalf("CleanupExpiredReservations: %v", err)
+	}
+
+	for orderID, want := range map[string]string{early: "expired", late: "reserved"} {
+		lines, err := store.GetReservation(ctx, orderID)
+		if err != nil {
+			t.Fatalf("GetReservation %s: %v", orderID, err)
+		}
+		if len(lines) != 1 || lines[0].Status != want {
+			t.Errorf("%s lines = %+v; want one %s line", orderID, lines, want)
+		}
+	}
+}
+
+// Leere Trefferzahl beim UPDATE → unbekanntes Item (400) vs. zu wenig Bestand (409) unterscheiden
+func TestPostgresReserveStockUnknownVsInsufficient(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 1)
+	ctx := context.Background()
+
+	_, err := store.ReserveStock(ctx, ids[0]+"-order-a", []*pb.Item{{ID: ids[0] + "-missing", Quantity: 1}})
+	if !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("unknown item: ReserveStock = %v; want ErrItemNotFound", err)
+	}
+
+	_, err = store.ReserveStock(ctx, ids[0]+"-order-b", []*pb.Item{{ID: ids[0], Quantity: 101}})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("short item: ReserveStock = %v; want ErrInsufficientStock", err)
+	}
+}
+
+// BenchmarkAvailability: Alter Pfad (GetAvailableQuantity pro Item) gegen EINE Query für den Warenkorb
+// go test -bench Availability -run ^$ (mit STOCK_TEST_DSN)
+func BenchmarkAvailability(b *testing.B) {
+	store, ids := newPostgresTestStore(b, benchCartSize)
+	ctx := context.Background()
+
+	b.Run("per-item", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, id := range ids {
+				if _, err := store.GetAvailableQuantity(ctx, id); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := store.GetAvailableQuantities(ctx, ids); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Nur bestätigte Zeilen bekommen den Timestamp, der zweite Aufruf (Redelivery) ändert nichts mehr
+func TestPostgresMarkFulfillmentStarted(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 1)
+	ctx := context.Background()
+	orderID := ids[0] + "-order"
+
+	if _, err := store.ReserveStock(ctx, orderID, []*pb.Item{{ID: ids[0], Quantity: 1}}); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	if marked, err := store.MarkFulfillmentStarted(ctx, orderID); err != nil || marked != 0 {
+		t.Fatalf("MarkFulfillmentStarted before confirm = %d, %v; want 0, nil", marked, err)
+	}
+
+	if err := store.ConfirmReservation(ctx, orderID); err != nil {
+		t.Fatalf("ConfirmReservation: %v", err)
+	}
+	if marked, err := store.MarkFulfillmentStarted(ctx, orderID); err != nil || marked != 1 {
+		t.Fatalf("MarkFulfillmentStarted = %d, %v; want 1, nil", marked, err)
+	}
+	if marked, err := store.MarkFulfillmentStarted(ctx, orderID); err != nil || marked != 0 {
+		t.Fatalf("MarkFulfillmentStarted redelivery = %d, %v; want 0, nil", marked, err)
+	}
+
+	lines, err := store.GetReservation(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	if len(lines) != 1 || lines[0].FulfillmentStartedAt.IsZero() {
+		t.Fatalf("reservation = %+v; want FulfillmentStartedAt set", lines)
+	}
+}
+
+// Doppeltes order.cancelled (nacheinander UND gleichzeitig) → beide Releases ohne Fehler, Bestand nur einmal zurück
+func TestPostgresReleaseReservationIdempotent(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 2)
+	ctx := context.Background()
+
+	t.Run("sequential", func(t *testing.T) {
+		orderID := ids[0] + "-order"
+		if _, err := store.ReserveStock(ctx, orderID, []*pb.Item{{ID: ids[0], Quantity: 5}}); err != nil {
+			t.Fatalf("ReserveStock: %v", err)
+		}
+		for i := 1; i <= 2; i++ {
+			if err := store.ReleaseReservation(ctx, orderID, ""); err != nil {
+				t.Fatalf("ReleaseReservation #%d: %v", i, err)
+			}
+		}
+		assertAvailable(t, store, ids[0], 100)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		orderID := ids[1] + "-order"
+		if _, err := store.ReserveStock(ctx, orderID, []*pb.Item{{ID: ids[1], Quantity: 5}}); err != nil {
+			t.Fatalf("ReserveStock: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = store.ReleaseReservation(ctx, orderID, "")
+			}()
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("ReleaseReservation #%d: %v", i+1, err)
+			}
+		}
+		assertAvailable(t, store, ids[1], 100)
+	})
+}
+
+func assertAvailable(t *testing.T, store *PostgresStore, id string, want int32) {
+	t.Helper()
+
+	available, err := store.GetAvailableQuantities(context.Background(), []string{id})
+	if err != nil {
+		t.Fatalf("GetAvailableQuantities: %v", err)
+	}
+	if available[id] != want {
+		t.Fatalf("available %s = %d; want %d", id, available[id], want)
+	}
+}
+
+// Gleiche Grace Regeln in SQL: innerhalb bestätigt, außerhalb ErrNoActiveReservation, re-reserviert → kein Over-Selling
+func TestPostgresConfirmReservationGraceWindow(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 3)
+	store.confirmGrace = 2 * time.Minute
+	clock := &fakeClock{now: time.Now()}
+	store.clock = clock
+	ctx := context.Background()
+
+	within, outside, resold := ids[0], ids[1], ids[2]
+	for _, id := range ids {
+		qty := int32(5)
+		if id == resold {
+			qty = 50
+		}
+		if _, err := store.ReserveStock(ctx, id+"-order", []*pb.Item{{ID: id, Quantity: qty}}); err != nil {
+			t.Fatalf("ReserveStock %s: %v", id, err)
+		}
+	}
+	clock.Advance(ReservationTTL + 30*time.Second)
+	if _, err := store.CleanupExpiredReservations(ctx); err != nil {
+		t.Fatalf("CleanupExpiredReservations: %v", err)
+	}
+
+	// resold: 100 Stück, abgelaufene 50 gehen an eine andere Order (60) → nur noch 40 frei
+	if _, err := store.ReserveStock(ctx, resold+"-other", []*pb.Item{{ID: resold, Quantity: 60}}); err != nil {
+		t.Fatalf("ReserveStock other: %v", err)
+	}
+
+	if err := store.ConfirmReservation(ctx, within+"-order"); err != nil {
+		t.Fatalf("ConfirmReservation within grace: %v", err)
+	}
+	assertAvailable(t, store, within, 95)
+
+	if err := store.ConfirmReservation(ctx, resold+"-order"); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("ConfirmReservation re-reserved = %v; want ErrInsufficientStock", err)
+	}
+	assertAvailable(t, store, resold, 40)
+
+	clock.Advance(3 * time.Minute)
+	if err := store.ConfirmReservation(ctx, outside+"-order"); !errors.Is(err, ErrNoActiveReservation) {
+		t.Fatalf("ConfirmReservation outside grace = %v; want ErrNoActiveReservation", err)
+	}
+	assertAvailable(t, store, outside, 100)
+}
+
+// Renew nach Ablauf legt neue 'reserved' Zeilen an → Confirm bucht nur die ab, die alten 'expired' nicht zusätzlich
+func TestPostgresConfirmReservationAfterRenewConfirmsOnce(t *testing.T) {
+	store, ids := newPostgresTestStore(t, 1)
+	store.confirmGrace = 2 * time.Minute
+	clock := &fakeClock{now: time.Now()}
+	store.clock = clock
+	ctx := context.Background()
+
+	id := ids[0]
+	items := []*pb.Item{{ID: id, Quantity: 2}}
+	if _, err := store.ReserveStock(ctx, id+"-order", items); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	clock.Advance(ReservationTTL + 30*time.Second)
+	if _, err := store.CleanupExpiredReservations(ctx); err != nil {
+		t.Fatalf("CleanupExpiredReservations: %v", err)
+	}
+	if _, err := store.RenewReservation(ctx, id+"-order", items); err != nil {
+		t.Fatalf("RenewReservation: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.ConfirmReservation(ctx, id+"-order"); err != nil {
+			t.Fatalf("ConfirmReservation #%d: %v", i+1, err)
+		}
+		assertAvailable(t, store, id, 98)
+	}
+}
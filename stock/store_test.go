@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/timour/order-microservices/common/api"
+)
+
+// storeBackends: Jeder Test läuft gegen BEIDE Backends über das StockStore Interface
+// → memory immer, postgres nur mit STOCK_TEST_DSN (sonst Skip)
+// → Items mit Quantity 100 und frischen IDs → Tests sehen keine fremden Reservierungen
+var storeBackends = map[string]func(t *testing.T, n int) (StockStore, []string){
+	StoreBackendMemory: func(t *testing.T, n int) (StockStore, []string) {
+		store, err := NewStore(StoreConfig{Backend: StoreBackendMemory})
+		if err != nil {
+			t.Fatalf("NewStore: %v", err)
+		}
+		ids := make([]string, n)
+		items := make([]*pb.Item, n)
+		for i := range items {
+			ids[i] = fmt.Sprintf("contract-%d", i)
+			items[i] = &pb.Item{ID: ids[i], Name: ids[i], PriceID: "price_test", Quantity: 100}
+		}
+		if err := store.BulkCreateItems(context.Background(), items); err != nil {
+			t.Fatalf("BulkCreateItems: %v", err)
+		}
+		return store, ids
+	},
+	StoreBackendPostgres: func(t *testing.T, n int) (StockStore, []string) {
+		return newPostgresTestStore(t, n)
+	},
+}
+
+func forEachBackend(t *testing.T, n int, test func(t *testing.T, s StockStore, ids []string)) {
+	for name, newStore := range storeBackends {
+		t.Run(name, func(t *testing.T) {
+			s, ids := newStore(t, n)
+			test(t, s, ids)
+		})
+	}
+}
+
+func available(t *testing.T, s StockStore, id string) int32 {
+	t.Helper()
+	quantities, err := s.GetAvailableQuantities(context.Background(), []string{id})
+	if err != nil {
+		t.Fatalf("GetAvailableQuantities: %v", err)
+	}
+	return quantities[id]
+}
+
+// Reserve → Confirm: Reservierung blockiert, Bestätigung zieht den Bestand wirklich ab
+func TestStoreReserveAndConfirm(t *testing.T) {
+	forEachBackend(t, 2, func(t *testing.T, s StockStore, ids []string) {
+		ctx := context.Background()
+		orderID := ids[0] + "-order"
+
+		res, err := s.ReserveStock(ctx, orderID, []*pb.Item{{ID: ids[0], Quantity: 3}, {ID: ids[1], Quantity: 1}})
+		if err != nil {
+			t.Fatalf("ReserveStock: %v", err)
+		}
+		if res.ID == "" || !res.ExpiresAt.After(time.Now().Add(-time.Minute)) {
+			t.Errorf("reservation = %+v; want an id and a future expiry", res)
+		}
+		if got := available(t, s, ids[0]); got != 97 {
+			t.Errorf("available after reserve = %d; want 97", got)
+		}
+
+		if err := s.ConfirmReservation(ctx, orderID); err != nil {
+			t.Fatalf("ConfirmReservation: %v", err)
+		}
+		if got := available(t, s, ids[0]); got != 97 {
+			t.Errorf("available after confirm = %d; want 97", got)
+		}
+		item, err := s.GetItem(ctx, ids[0])
+		if err != nil {
+			t.Fatalf("GetItem: %v", err)
+		}
+		if item.Quantity != 97 {
+			t.Errorf("quantity after confirm = %d; want 97", item.Quantity)
+		}
+
+		lines, err := s.GetReservation(ctx, orderID)
+		if err != nil {
+			t.Fatalf("GetReservation: %v", err)
+		}
+		if len(lines) != 2 {
+			t.Fatalf("reservation lines = %+v; want 2", lines)
+		}
+		for _, l := range lines {
+			if l.Status != "confirmed" {
+				t.Errorf("line %s status = %q; want confirmed", l.ItemID, l.Status)
+			}
+		}
+	})
+}
+
+// Reserve → Release (zweimal): Bestand genau einmal zurück
+func TestStoreReserveAndRelease(t *testing.T) {
+	forEachBackend(t, 1, func(t *testing.T, s StockStore, ids []string) {
+		ctx := context.Background()
+		orderID := ids[0] + "-order"
+
+		res, err := s.ReserveStock(ctx, orderID, []*pb.Item{{ID: ids[0], Quantity: 5}})
+		if err != nil {
+			t.Fatalf("ReserveStock: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := s.ReleaseReservation(ctx, orderID, res.ID); err != nil {
+				t.Fatalf("ReleaseReservation #%d: %v", i+1, err)
+			}
+		}
+		if got := available(t, s, ids[0]); got != 100 {
+			t.Errorf("available after release = %d; want 100", got)
+		}
+		if err := s.ConfirmReservation(ctx, orderID); !errors.Is(err, ErrNoActiveReservation) {
+			t.Errorf("ConfirmReservation after release = %v; want ErrNoActiveReservation", err)
+		}
+	})
+}
+
+// Gleiche Fehler in beiden Backends → Service/gRPC Handler mappen sie einheitlich
+func TestStoreReserveErrors(t *testing.T) {
+	forEachBackend(t, 1, func(t *testing.T, s StockStore, ids []string) {
+		ctx := context.Background()
+
+		_, err := s.ReserveStock(ctx, ids[0]+"-o1", []*pb.Item{{ID: ids[0] + "-missing", Quantity: 1}})
+		if !errors.Is(err, ErrItemNotFound) {
+			t.Errorf("unknown item: ReserveStock = %v; want ErrItemNotFound", err)
+		}
+		_, err = s.ReserveStock(ctx, ids[0]+"-o2", []*pb.Item{{ID: ids[0], Quantity: 101}})
+		if !errors.Is(err, ErrInsufficientStock) {
+			t.Errorf("short item: ReserveStock = %v; want ErrInsufficientStock", err)
+		}
+		if got := available(t, s, ids[0]); got != 100 {
+			t.Errorf("available after failed reservations = %d; want 100", got)
+		}
+	})
+}
+
+func TestNewStoreUnknownBackend(t *testing.T) {
+	if _, err := NewStore(StoreConfig{Backend: "mongo"}); err == nil {
+		t.Fatal("NewStore(mongo) succeeded; want error")
+	}
+}
@@ -23,16 +23,80 @@ func (s *TelemetryMiddleware) GetItems(ctx context.Context, ids []string) ([]*pb
 	return s.next.GetItems(ctx, ids)
 }
 
-func (s *TelemetryMiddleware) CheckIfItemAreInStock(ctx context.Context, p []*pb.ItemsWithQuantity) (bool, []*pb.Item, error) {
+func (s *TelemetryMiddleware) CheckIfItemAreInStock(ctx context.Context, p []*pb.ItemsWithQuantity) (bool, []*pb.Item, []*pb.OutOfStockItem, error) {
 	span := trace.SpanFromContext(ctx)
 	span.AddEvent(fmt.Sprintf("CheckIfItemAreInStock: %v", p))
 
 	return s.next.CheckIfItemAreInStock(ctx, p)
 }
 
-func (s *TelemetryMiddleware) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (string, error) {
+func (s *TelemetryMiddleware) ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
 	span := trace.SpanFromContext(ctx)
 	span.AddEvent(fmt.Sprintf("ReserveStock: orderID=%s, items=%d", orderID, len(items)))
 
 	return s.next.ReserveStock(ctx, orderID, items)
 }
+
+func (s *TelemetryMiddleware) RenewReservation(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("RenewReservation: orderID=%s, items=%d", orderID, len(items)))
+
+	return s.next.RenewReservation(ctx, orderID, items)
+}
+
+func (s *TelemetryMiddleware) RestockItems(ctx context.Context, orderID string, items []*pb.ItemsWithQuantity) error {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("RestockItems: orderID=%s, items=%d", orderID, len(items)))
+
+	return s.next.RestockItems(ctx, orderID, items)
+}
+
+func (s *TelemetryMiddleware) SetItemAvailability(ctx context.Context, id string, available bool) (*pb.Item, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("SetItemAvailability: id=%s, available=%t", id, available))
+
+	return s.next.SetItemAvailability(ctx, id, available)
+}
+
+func (s *TelemetryMiddleware) ConfirmReservation(ctx context.Context, orderID string) error {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("ConfirmReservation: orderID=%s", orderID))
+
+	return s.next.ConfirmReservation(ctx, orderID)
+}
+
+func (s *TelemetryMiddleware) ReleaseReservation(ctx context.Context, orderID, reservationID string) error {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("ReleaseReservation: orderID=%s reservationID=%s", orderID, reservationID))
+
+	return s.next.ReleaseReservation(ctx, orderID, reservationID)
+}
+
+func (s *TelemetryMiddleware) CleanupExpiredReservations(ctx context.Context) (int, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("CleanupExpiredReservations")
+
+	return s.next.CleanupExpiredReservations(ctx)
+}
+
+func (s *TelemetryMiddleware) GetReservation(ctx context.Context, orderID string) ([]ReservationLine, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("GetReservation: orderID=%s", orderID))
+
+	return s.next.GetReservation(ctx, orderID)
+}
+
+func (s *TelemetryMiddleware) GetInventorySummary(ctx context.Context, query InventoryQuery) ([]*pb.InventoryItem, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("GetInventorySummary: lowStockThreshold=%d sortBy=%s lowStockOnly=%t",
+		query.LowStockThreshold, query.SortBy, query.LowStockOnly))
+
+	return s.next.GetInventorySummary(ctx, query)
+}
+
+func (s *TelemetryMiddleware) BulkCreateItems(ctx context.Context, items []*pb.Item) (int, []*pb.ItemRowError, error) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(fmt.Sprintf("BulkCreateItems: %d items", len(items)))
+
+	return s.next.BulkCreateItems(ctx, items)
+}
@@ -2,22 +2,67 @@ package main
 
 import (
 	"context"
+	"time"
 
 	pb "github.com/timour/order-microservices/common/api"
 )
 
+// Reservation: Ergebnis von ReserveStock/RenewReservation
+// Warum ExpiresAt mitgeben?
+// → TTL lebt nur im Stock Service → Orders/Gateway können dem Kunden sonst nicht sagen wie lange er zahlen kann
+type Reservation struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+// ReservationLine: Eine Zeile aus stock_reservations (ein Item einer Order)
+type ReservationLine struct {
+	ItemID    string
+	Quantity  int32
+	Status    string // reserved | confirmed | released | expired
+	ExpiresAt time.Time
+	// FulfillmentStartedAt: order.preparing empfangen (Zero = Küche hat noch nicht angefangen)
+	FulfillmentStartedAt time.Time
+}
+
+// InventoryQuery: Filter + Sortierung für GetInventorySummary
+// → SortBy ist bereits vom Validation Interceptor gegen die Whitelist geprüft
+type InventoryQuery struct {
+	LowStockThreshold int32
+	SortBy            string // "id" | "name" | "available" | "available_desc"
+	LowStockOnly      bool   // Nur Items mit available_quantity <= LowStockThreshold
+}
+
 type StockService interface {
-	CheckIfItemAreInStock(context.Context, []*pb.ItemsWithQuantity) (bool, []*pb.Item, error)
+	CheckIfItemAreInStock(context.Context, []*pb.ItemsWithQuantity) (bool, []*pb.Item, []*pb.OutOfStockItem, error)
 	GetItems(ctx context.Context, ids []string) ([]*pb.Item, error)
-	ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (string, error)
+	ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error)
+	RestockItems(ctx context.Context, orderID string, items []*pb.ItemsWithQuantity) error
+	RenewReservation(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error)
+	ConfirmReservation(ctx context.Context, orderID string) error
+	ReleaseReservation(ctx context.Context, orderID, reservationID string) error
+	GetReservation(ctx context.Context, orderID string) ([]ReservationLine, error)
+	CleanupExpiredReservations(ctx context.Context) (int, error)
+	SetItemAvailability(ctx context.Context, id string, available bool) (*pb.Item, error)
+	GetInventorySummary(ctx context.Context, query InventoryQuery) ([]*pb.InventoryItem, error)
+	BulkCreateItems(ctx context.Context, items []*pb.Item) (int, []*pb.ItemRowError, error)
 }
 
 type StockStore interface {
 	GetItem(ctx context.Context, id string) (*pb.Item, error)
 	GetItems(ctx context.Context, ids []string) ([]*pb.Item, error)
+	GetAvailableQuantities(ctx context.Context, ids []string) (map[string]int32, error)
 	DecrementQuantity(ctx context.Context, id string, amount int32) error
+	SetItemAvailability(ctx context.Context, id string, available bool) (*pb.Item, error)
 	// Reservation methods
-	ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (string, error)
+	ReserveStock(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error)
 	ConfirmReservation(ctx context.Context, orderID string) error
-	ReleaseReservation(ctx context.Context, orderID string) error
+	ReleaseReservation(ctx context.Context, orderID, reservationID string) error
+	GetReservation(ctx context.Context, orderID string) ([]ReservationLine, error)
+	MarkFulfillmentStarted(ctx context.Context, orderID string) (int, error)
+	CleanupExpiredReservations(ctx context.Context) (int, error)
+	RenewReservation(ctx context.Context, orderID string, items []*pb.Item) (Reservation, error)
+	RestockItems(ctx context.Context, orderID string, items []*pb.ItemsWithQuantity) error
+	GetInventorySummary(ctx context.Context, query InventoryQuery) ([]*pb.InventoryItem, error)
+	BulkCreateItems(ctx context.Context, items []*pb.Item) error
 }